@@ -30,5 +30,8 @@ func (c *CommandAccounts) Command(globalFlags *globalFlags) *cobra.Command {
 	cmd.AddCommand((&CommandAccountsBalances{Context: &c.context}).Command())
 	cmd.AddCommand((&CommandAccountsPortfolio{Context: &c.context}).Command())
 	cmd.AddCommand((&CommandAccountsTransactions{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandAccountsGains{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandAccountsPerformance{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandAccountsPortfolioDiff{Context: &c.context}).Command())
 	return cmd
 }
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+type accountsGainsFlags struct {
+	year   int
+	method enumFlagValue[LotMatchingMethod]
+	lots   []string
+}
+
+type CommandAccountsGains struct {
+	Context *CommandContextWithClient
+	flags   accountsGainsFlags
+}
+
+func (c *CommandAccountsGains) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gains [account ID]",
+		Short: "List realized gains",
+		Long: "List the realized short-term and long-term gain or loss on each lot of each sale in a year, and " +
+			"flag wash sales. Render them for taxes with --format form8949, txf, or scheduleD",
+		Args: cobra.MatchAll(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			method := c.flags.method.Value()
+			if len(c.flags.lots) > 0 && method != LotMatchingSpecific {
+				return errors.New("--lot requires --method specific")
+			}
+			specificLots := make([]SpecificLot, 0, len(c.flags.lots))
+			for _, lot := range c.flags.lots {
+				specificLot, err := ParseSpecificLot(lot)
+				if err != nil {
+					return err
+				}
+				specificLots = append(specificLots, specificLot)
+			}
+			if response, err := GetRealizedGains(
+				c.Context.Client, accountId, c.flags.year, method, specificLots, time.Now(),
+			); err == nil {
+				return c.Context.Renderer.Render(response, realizedGainsDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+
+	// Add Flags
+	cmd.Flags().IntVarP(&c.flags.year, "year", "y", time.Now().Year(), "tax year of the sales")
+	cmd.Flags().StringArrayVar(
+		&c.flags.lots, "lot", nil,
+		"sell a sale's shares from a lot, as SALE_TRANSACTION_ID=LOT_ID[:QUANTITY] (may be repeated)",
+	)
+
+	// Initialize Enum Flag Values
+	c.flags.method = *newEnumFlagValue(lotMatchingMethodMap, LotMatchingFifo)
+
+	// Add Enum Flags
+	cmd.Flags().VarP(
+		&c.flags.method, "method", "m",
+		fmt.Sprintf("lot matching method (%s)", c.flags.method.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"method",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return c.flags.method.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	return cmd
+}
+
+var realizedGainsDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: ".gains",
+		Values: []RenderValue{
+			{Header: "Sale Transaction ID", Path: ".saleTransactionId"},
+			{Header: "Symbol", Path: ".symbol"},
+			{Header: "Lot ID", Path: ".lotId"},
+			{Header: "Quantity", Path: ".quantity"},
+			{Header: "Date Acquired", Path: ".dateAcquired"},
+			{Header: "Date Sold", Path: ".dateSold"},
+			{Header: "Proceeds", Path: ".proceeds"},
+			{Header: "Cost Basis", Path: ".costBasis"},
+			{Header: "Wash Sale", Path: ".washSale"},
+			{Header: "Wash Sale Loss Disallowed", Path: ".washSaleLossDisallowed"},
+			{Header: "Gain", Path: ".gain"},
+			{Header: "Term", Path: ".term"},
+			{Header: "Form 8949 Box", Path: ".form8949Box"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   true,
+	},
+	{
+		ObjectPath: ".totals",
+		Values: []RenderValue{
+			{Header: "Proceeds", Path: ".proceeds"},
+			{Header: "Cost Basis", Path: ".costBasis"},
+			{Header: "Wash Sale Loss Disallowed", Path: ".washSaleLossDisallowed"},
+			{Header: "Short-Term Gain", Path: ".shortTermGain"},
+			{Header: "Long-Term Gain", Path: ".longTermGain"},
+			{Header: "Total Gain", Path: ".totalGain"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
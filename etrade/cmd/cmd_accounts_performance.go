@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+type accountsPerformanceFlags struct {
+	from   string
+	to     string
+	values []string
+	daily  bool
+}
+
+type CommandAccountsPerformance struct {
+	Context *CommandContextWithClient
+	flags   accountsPerformanceFlags
+}
+
+func (c *CommandAccountsPerformance) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "performance [account ID]",
+		Short: "Show account performance",
+		Long: "Show an account's time-weighted and money-weighted (XIRR) returns, net of deposits and " +
+			"withdrawals, and its month, quarter, and year to date and one-year returns",
+		Args: cobra.MatchAll(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			location, err := time.LoadLocation("America/New_York")
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			to := now
+			if c.flags.to != "" {
+				if to, err = time.ParseInLocation("01022006", c.flags.to, location); err != nil {
+					return errors.New("to date must be in format MMDDYYYY")
+				}
+			}
+			from := to.AddDate(-1, 0, 0)
+			if c.flags.from != "" {
+				if from, err = time.ParseInLocation("01022006", c.flags.from, location); err != nil {
+					return errors.New("from date must be in format MMDDYYYY")
+				}
+			}
+			values := make([]AccountValue, 0, len(c.flags.values))
+			for _, value := range c.flags.values {
+				accountValue, err := ParseAccountValue(value, location)
+				if err != nil {
+					return err
+				}
+				values = append(values, accountValue)
+			}
+			store := c.Context.ConfigurationFolder.OpenSnapshotStore(c.Context.Logger)
+			if response, err := GetAccountPerformance(
+				c.Context.Client, store, accountId, from, to, values, c.flags.daily, now,
+			); err == nil {
+				return c.Context.Renderer.Render(response, accountPerformanceDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+
+	// Add Flags
+	cmd.Flags().StringVarP(&c.flags.from, "from", "f", "", "start date (MMDDYYYY, defaults to one year before --to)")
+	cmd.Flags().StringVarP(&c.flags.to, "to", "t", "", "end date (MMDDYYYY, defaults to today)")
+	cmd.Flags().StringArrayVar(
+		&c.flags.values, "value", nil,
+		"the account's total value at the end of a day, as MMDDYYYY=VALUE (e.g. from a statement; replaces the "+
+			"snapshot value for that day; may be repeated)",
+	)
+	cmd.Flags().BoolVarP(&c.flags.daily, "daily", "d", false, "include the reconstructed daily values")
+
+	return cmd
+}
+
+var accountPerformanceDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: ".performance",
+		Values: []RenderValue{
+			{Header: "Start Date", Path: ".startDate"},
+			{Header: "End Date", Path: ".endDate"},
+			{Header: "Start Value", Path: ".startValue"},
+			{Header: "End Value", Path: ".endValue"},
+			{Header: "Deposits", Path: ".deposits"},
+			{Header: "Withdrawals", Path: ".withdrawals"},
+			{Header: "Gain", Path: ".gain"},
+			{Header: "Time-Weighted Return %", Path: ".timeWeightedReturnPct"},
+			{Header: "Annualized Return %", Path: ".annualizedReturnPct"},
+			{Header: "XIRR %", Path: ".xirrPct"},
+			{Header: "MTD Return %", Path: ".mtdReturnPct"},
+			{Header: "QTD Return %", Path: ".qtdReturnPct"},
+			{Header: "YTD Return %", Path: ".ytdReturnPct"},
+			{Header: "1Y Return %", Path: ".oneYearReturnPct"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   true,
+	},
+	{
+		ObjectPath: ".cashFlows",
+		Values: []RenderValue{
+			{Header: "Transaction ID", Path: ".transactionId"},
+			{Header: "Date", Path: ".date"},
+			{Header: "Transaction Type", Path: ".transactionType"},
+			{Header: "Description", Path: ".description"},
+			{Header: "Amount", Path: ".amount"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   true,
+	},
+	{
+		ObjectPath: ".dailyValues",
+		Values: []RenderValue{
+			{Header: "Date", Path: ".date"},
+			{Header: "Value", Path: ".value"},
+			{Header: "Cash Flow", Path: ".cashFlow"},
+			{Header: "Return %", Path: ".returnPct"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
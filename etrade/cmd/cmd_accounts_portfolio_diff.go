@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"time"
+)
+
+type accountsPortfolioDiffFlags struct {
+	from string
+	to   string
+}
+
+type CommandAccountsPortfolioDiff struct {
+	Context *CommandContextWithClient
+	flags   accountsPortfolioDiffFlags
+}
+
+func (c *CommandAccountsPortfolioDiff) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "portfolio-diff [account ID]",
+		Short: "Compare portfolios",
+		Long: "Compare an account's positions and lots in two portfolios (stored snapshots or the current " +
+			"portfolio), and show the added and removed positions, quantity and market value changes, and each " +
+			"position's P&L contribution",
+		Args: cobra.MatchAll(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			store := c.Context.ConfigurationFolder.OpenSnapshotStore(c.Context.Logger)
+			if response, err := GetPortfolioDiff(
+				c.Context.Client, store, accountId, c.flags.from, c.flags.to, time.Now(),
+			); err == nil {
+				return c.Context.Renderer.Render(response, portfolioDiffDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+
+	// Add Flags
+	cmd.Flags().StringVarP(
+		&c.flags.from, "from", "f", "",
+		"earlier portfolio: a snapshot ID, or a date (MMDDYYYY) for the latest snapshot taken by that day",
+	)
+	cmd.Flags().StringVarP(
+		&c.flags.to, "to", "t", portfolioDiffNow,
+		"later portfolio: a snapshot ID, a date (MMDDYYYY), or "+portfolioDiffNow+" for the current portfolio",
+	)
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+var portfolioDiffHoldingValues = []RenderValue{
+	{Header: "Change", Path: ".change"},
+	{Header: "From Quantity", Path: ".fromQuantity"},
+	{Header: "To Quantity", Path: ".toQuantity"},
+	{Header: "Quantity Change", Path: ".quantityChange"},
+	{Header: "From Market Value", Path: ".fromMarketValue"},
+	{Header: "To Market Value", Path: ".toMarketValue"},
+	{Header: "Market Value Change", Path: ".marketValueChange"},
+	{Header: "P&L Contribution", Path: ".pnlContribution"},
+}
+
+var portfolioDiffDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: ".comparison",
+		Values: []RenderValue{
+			{Header: "Account ID", Path: ".accountId"},
+			{Header: "From Snapshot ID", Path: ".fromSnapshotId"},
+			{Header: "From Time", Path: ".fromTime"},
+			{Header: "To Snapshot ID", Path: ".toSnapshotId"},
+			{Header: "To Time", Path: ".toTime"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   true,
+	},
+	{
+		ObjectPath: ".positions",
+		Values: append(
+			[]RenderValue{
+				{Header: "Symbol", Path: ".symbol"},
+				{Header: "Security Type", Path: ".securityType"},
+				{Header: "Position Type", Path: ".positionType"},
+				{Header: "Symbol Description", Path: ".symbolDescription"},
+			}, portfolioDiffHoldingValues...,
+		),
+		SubObjects: []RenderDescriptor{
+			{
+				ObjectPath: ".lots",
+				Values: append(
+					[]RenderValue{
+						{Header: "Position Lot ID", Path: ".positionLotId"},
+						{Header: "Acquired Date", Path: ".acquiredDate", Transformer: dateTransformerMs},
+					}, portfolioDiffHoldingValues...,
+				),
+				DefaultValue: "",
+				SpaceAfter:   true,
+			},
+		},
+		DefaultValue: "",
+		SpaceAfter:   true,
+	},
+	{
+		ObjectPath: ".totals",
+		Values: []RenderValue{
+			{Header: "From Market Value", Path: ".fromMarketValue"},
+			{Header: "To Market Value", Path: ".toMarketValue"},
+			{Header: "Market Value Change", Path: ".marketValueChange"},
+			{Header: "P&L Contribution", Path: ".pnlContribution"},
+			{Header: "Added Positions", Path: ".addedPositions"},
+			{Header: "Removed Positions", Path: ".removedPositions"},
+			{Header: "Changed Positions", Path: ".changedPositions"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
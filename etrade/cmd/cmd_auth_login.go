@@ -27,7 +27,8 @@ func (c *CommandAuthLogin) Command(globalFlags *globalFlags) *cobra.Command {
 
 func (c *CommandAuthLogin) Login(customerId string) error {
 	eTradeClient, err := NewETradeClientForCustomer(
-		customerId, c.Context.ConfigurationFolder, c.Context.CustomerConfigurationStore, c.Context.Logger,
+		customerId, c.Context.ConfigurationFolder, c.Context.CustomerConfigurationStore,
+		c.Context.HttpClientWrapper, c.Context.Logger,
 	)
 	if err != nil {
 		return err
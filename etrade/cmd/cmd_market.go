@@ -28,6 +28,7 @@ func (c *CommandMarket) Command(globalFlags *globalFlags) *cobra.Command {
 	// Add Subcommands
 	cmd.AddCommand((&CommandMarketLookup{Context: &c.context}).Command())
 	cmd.AddCommand((&CommandMarketQuote{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandMarketWatch{Context: &c.context}).Command())
 	cmd.AddCommand((&CommandMarketOptionChains{Context: &c.context}).Command())
 	cmd.AddCommand((&CommandMarketOptionExpire{Context: &c.context}).Command())
 	return cmd
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+type marketWatchFlags struct {
+	interval time.Duration
+}
+
+type CommandMarketWatch struct {
+	Context *CommandContextWithClient
+	flags   marketWatchFlags
+}
+
+func (c *CommandMarketWatch) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch [symbol] ...",
+		Short: "Watch quotes",
+		Long: "Get quotes for one or more symbols on an interval. On a terminal, the quotes are shown in a table " +
+			"that's redrawn in place; otherwise, a CSV line is written for each quote or, with a JSON format, " +
+			"a JSON object for each poll.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.flags.interval <= 0 {
+				return errors.New("--interval must be greater than zero")
+			}
+			symbols := make([]string, 0, len(args))
+			seen := map[string]bool{}
+			for _, symbol := range args {
+				symbol = strings.ToUpper(symbol)
+				if !seen[symbol] {
+					seen[symbol] = true
+					symbols = append(symbols, symbol)
+				}
+			}
+			// CSV output is written by the watcher, so that the header is
+			// only written once, and JSON output is rendered for each poll.
+			var renderer Renderer
+			switch c.Context.Renderer.(type) {
+			case *csvRenderer:
+			case *jsonRenderer:
+				renderer = c.Context.Renderer
+			default:
+				return errors.New("market watch only supports the csv, json, and jsonPretty formats")
+			}
+			outputInfo, err := c.Context.OutputFile.Stat()
+			isTerminal := renderer == nil && err == nil && outputInfo.Mode()&os.ModeCharDevice != 0
+
+			// Stop watching upon receiving an interrupt signal
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			watcher, err := newQuoteWatcher(
+				c.Context.OutputFile, renderer, isTerminal, symbols, c.flags.interval, c.Context.Logger, time.Now,
+			)
+			if err != nil {
+				return err
+			}
+			return watcher.Run(ctx, c.Context.Client)
+		},
+	}
+	// Add Flags
+	cmd.Flags().DurationVarP(&c.flags.interval, "interval", "i", 5*time.Second, "how often to get quotes")
+	return cmd
+}
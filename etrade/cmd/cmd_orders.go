@@ -27,5 +27,12 @@ func (c *CommandOrders) Command(globalFlags *globalFlags) *cobra.Command {
 	}
 	// Add Subcommands
 	cmd.AddCommand((&CommandOrdersList{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersPreview{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersPlace{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersPreviewSpread{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersPreviewChange{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersPlaceChange{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersCancel{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandOrdersWatch{Context: &c.context}).Command(globalFlags))
 	return cmd
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+type CommandOrdersCancel struct {
+	Context *CommandContextWithClient
+}
+
+func (c *CommandOrdersCancel) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel [account ID] [order ID] ...",
+		Short: "Cancel orders",
+		Long:  "Cancel one or more orders by ID",
+		Args:  cobra.MatchAll(cobra.MinimumNArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			orderIds := make([]int64, 0, len(args)-1)
+			for _, arg := range args[1:] {
+				orderId, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid order ID '%s'", arg)
+				}
+				orderIds = append(orderIds, orderId)
+			}
+			// The results are rendered even if the cancels were stopped by
+			// an error, so that it's clear which orders were cancelled.
+			response, err := CancelOrders(c.Context.Client, accountId, orderIds)
+			if response != nil {
+				if renderErr := c.Context.Renderer.Render(response, cancelOrdersDescriptor); err == nil {
+					err = renderErr
+				}
+			}
+			return err
+		},
+	}
+	return cmd
+}
+
+var cancelOrdersDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: ".cancelResults",
+		Values: []RenderValue{
+			{Header: "Order Id", Path: ".orderId"},
+			{Header: "Status", Path: ".status"},
+			{Header: "Cancel Time", Path: ".cancelTime", Transformer: dateTimeTransformerMs},
+			{Header: "Error Message", Path: ".error"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
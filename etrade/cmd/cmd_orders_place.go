@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type ordersPlaceFlags struct {
+	previewId int64
+}
+
+type CommandOrdersPlace struct {
+	Context    *CommandContextWithClient
+	flags      ordersPlaceFlags
+	orderFlags equityOrderFlags
+}
+
+func (c *CommandOrdersPlace) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "place [account ID] [symbol]",
+		Short: "Place an equity order",
+		Long: "Place a previously-previewed equity order. The order flags and client order ID must match the " +
+			"previewed order.",
+		Args: cobra.MatchAll(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			symbol := args[1]
+			request, err := c.orderFlags.createRequest(symbol)
+			if err != nil {
+				return err
+			}
+			if response, err := PlaceOrder(
+				c.Context.Client, accountId, c.flags.previewId, request,
+			); err == nil {
+				return c.Context.Renderer.Render(response, placeOrderDescriptor)
+			} else {
+				return renderOrderError(c.Context.Renderer, err)
+			}
+		},
+	}
+	// Add Flags
+	cmd.Flags().Int64Var(&c.flags.previewId, "preview-id", 0, "preview ID returned by 'orders preview'")
+	_ = cmd.MarkFlagRequired("preview-id")
+	c.orderFlags.addFlags(cmd)
+	_ = cmd.MarkFlagRequired("client-order-id")
+	return cmd
+}
+
+var placeOrderDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: "",
+		Values: []RenderValue{
+			{Header: "Order Id", Path: ".orderIds[0].orderId"},
+			{Header: "Client Order Id", Path: ".clientOrderId"},
+			{Header: "Order Type", Path: ".orderType"},
+			{Header: "Placed Time", Path: ".placedTime", Transformer: dateTimeTransformerMs},
+		},
+		SubObjects:   []RenderDescriptor{orderRequestDetailDescriptor},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
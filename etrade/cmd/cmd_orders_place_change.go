@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+type ordersPlaceChangeFlags struct {
+	previewId int64
+}
+
+type CommandOrdersPlaceChange struct {
+	Context     *CommandContextWithClient
+	flags       ordersPlaceChangeFlags
+	changeFlags changeOrderFlags
+}
+
+func (c *CommandOrdersPlaceChange) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "place-change [account ID] [order ID]",
+		Short: "Place a previewed change to an open order",
+		Long: "Place a change to an open order that was previewed with 'orders preview-change'. " +
+			"The change flags and client order ID must match the preview.",
+		Args: cobra.MatchAll(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			orderId, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid order ID '%s'", args[1])
+			}
+			if response, err := PlaceChangedOrder(
+				c.Context.Client, accountId, orderId, c.flags.previewId, c.changeFlags.clientOrderId,
+				c.changeFlags.changes(),
+			); err == nil {
+				return c.Context.Renderer.Render(response, placeOrderDescriptor)
+			} else {
+				return renderOrderError(c.Context.Renderer, err)
+			}
+		},
+	}
+	// Add Flags
+	cmd.Flags().Int64Var(&c.flags.previewId, "preview-id", 0, "preview ID returned by 'orders preview-change'")
+	_ = cmd.MarkFlagRequired("preview-id")
+	c.changeFlags.addFlags(cmd)
+	_ = cmd.MarkFlagRequired("client-order-id")
+	return cmd
+}
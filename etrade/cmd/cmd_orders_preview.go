@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/spf13/cobra"
+)
+
+type CommandOrdersPreview struct {
+	Context *CommandContextWithClient
+	flags   equityOrderFlags
+}
+
+func (c *CommandOrdersPreview) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview [account ID] [symbol]",
+		Short: "Preview an equity order",
+		Long:  "Preview an equity order. The order can then be placed with the returned preview ID.",
+		Args:  cobra.MatchAll(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			symbol := args[1]
+			// If no client order ID was provided, generate one. The same ID
+			// must be used when placing the order.
+			if c.flags.clientOrderId == "" {
+				var err error
+				if c.flags.clientOrderId, err = etradelib.NewClientOrderId(); err != nil {
+					return err
+				}
+			}
+			request, err := c.flags.createRequest(symbol)
+			if err != nil {
+				return err
+			}
+			if response, err := PreviewOrder(c.Context.Client, accountId, request); err == nil {
+				return c.Context.Renderer.Render(response, previewOrderDescriptor)
+			} else {
+				return renderOrderError(c.Context.Renderer, err)
+			}
+		},
+	}
+	c.flags.addFlags(cmd)
+	return cmd
+}
+
+var orderRequestDetailDescriptor = RenderDescriptor{
+	ObjectPath: ".order",
+	Values: []RenderValue{
+		{Header: "Price Type", Path: ".priceType"},
+		{Header: "Order Term", Path: ".orderTerm"},
+		{Header: "Market Session", Path: ".marketSession"},
+		{Header: "Limit Price", Path: ".limitPrice"},
+		{Header: "Stop Price", Path: ".stopPrice"},
+		{Header: "All Or None", Path: ".allOrNone"},
+		{Header: "Estimated Commission", Path: ".estimatedCommission"},
+		{Header: "Estimated Total Amount", Path: ".estimatedTotalAmount"},
+	},
+	SubObjects: []RenderDescriptor{
+		{
+			ObjectPath: ".instrument",
+			Values: []RenderValue{
+				{Header: "Symbol", Path: ".product.symbol"},
+				{Header: "Security Type", Path: ".product.securityType"},
+				{Header: "Call/Put", Path: ".product.callPut"},
+				{Header: "Strike Price", Path: ".product.strikePrice"},
+				{Header: "Expiry Year", Path: ".product.expiryYear"},
+				{Header: "Expiry Month", Path: ".product.expiryMonth"},
+				{Header: "Expiry Day", Path: ".product.expiryDay"},
+				{Header: "Symbol Description", Path: ".symbolDescription"},
+				{Header: "Order Action", Path: ".orderAction"},
+				{Header: "Quantity Type", Path: ".quantityType"},
+				{Header: "Quantity", Path: ".quantity"},
+			},
+			DefaultValue: "",
+			SpaceAfter:   false,
+		},
+		{
+			ObjectPath: ".messages.message",
+			Values: []RenderValue{
+				{Header: "Message", Path: ".description"},
+				{Header: "Code", Path: ".code"},
+				{Header: "Type", Path: ".type"},
+			},
+			DefaultValue: "",
+			SpaceAfter:   false,
+		},
+	},
+	DefaultValue: "",
+	SpaceAfter:   false,
+}
+
+var previewOrderDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: "",
+		Values: []RenderValue{
+			{Header: "Preview Id", Path: ".previewIds[0].previewId"},
+			{Header: "Client Order Id", Path: ".clientOrderId"},
+			{Header: "Order Type", Path: ".orderType"},
+			{Header: "Preview Time", Path: ".previewTime", Transformer: dateTimeTransformerMs},
+			{Header: "Total Order Value", Path: ".totalOrderValue"},
+			{Header: "Total Commission", Path: ".totalCommission"},
+		},
+		SubObjects:   []RenderDescriptor{orderRequestDetailDescriptor},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+type CommandOrdersPreviewChange struct {
+	Context *CommandContextWithClient
+	flags   changeOrderFlags
+}
+
+func (c *CommandOrdersPreviewChange) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview-change [account ID] [order ID]",
+		Short: "Preview a change to an open order",
+		Long: "Preview a change to the price, quantity, term, or stop of an open order. " +
+			"The change can then be placed with the returned preview ID.",
+		Args: cobra.MatchAll(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			orderId, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid order ID '%s'", args[1])
+			}
+			// If no client order ID was provided, generate one. The same ID
+			// must be used when placing the change.
+			if c.flags.clientOrderId == "" {
+				if c.flags.clientOrderId, err = etradelib.NewClientOrderId(); err != nil {
+					return err
+				}
+			}
+			if response, err := PreviewChangedOrder(
+				c.Context.Client, accountId, orderId, c.flags.clientOrderId, c.flags.changes(),
+			); err == nil {
+				return c.Context.Renderer.Render(response, previewOrderDescriptor)
+			} else {
+				return renderOrderError(c.Context.Renderer, err)
+			}
+		},
+	}
+	c.flags.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/spf13/cobra"
+)
+
+type CommandOrdersPreviewSpread struct {
+	Context *CommandContextWithClient
+	flags   spreadOrderFlags
+}
+
+func (c *CommandOrdersPreviewSpread) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preview-spread [account ID] [leg] ...",
+		Short: "Preview a multi-leg option order",
+		Long: "Preview a multi-leg option order (vertical, calendar, straddle, strangle, butterfly, condor, " +
+			"iron butterfly, or iron condor). Each leg is given as ACTION:QUANTITY:OSIKEY, where ACTION is one of " +
+			"buyOpen, sellOpen, buyClose, or sellClose and OSIKEY is an option symbol as listed by " +
+			"'market optionchains' (e.g. buyOpen:1:ABC---240119C00150000).",
+		Args: cobra.MatchAll(cobra.RangeArgs(2, etradelib.OptionOrderMaxLegs+1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			legStrings := args[1:]
+			// If no client order ID was provided, generate one. The same ID
+			// must be used when placing the order.
+			if c.flags.clientOrderId == "" {
+				var err error
+				if c.flags.clientOrderId, err = etradelib.NewClientOrderId(); err != nil {
+					return err
+				}
+			}
+			request, err := c.flags.createRequest(legStrings)
+			if err != nil {
+				return err
+			}
+			if response, err := PreviewOrder(c.Context.Client, accountId, request); err == nil {
+				return c.Context.Renderer.Render(response, previewOrderDescriptor)
+			} else {
+				return renderOrderError(c.Context.Renderer, err)
+			}
+		},
+	}
+	c.flags.addFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type ordersWatchFlags struct {
+	interval      time.Duration
+	webhooks      []string
+	webhookSecret string
+}
+
+type CommandOrdersWatch struct {
+	Context *CommandContextWithClient
+	flags   ordersWatchFlags
+}
+
+func (c *CommandOrdersWatch) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch [account ID] ...",
+		Short: "Watch orders",
+		Long: "List orders on an interval (in all accounts, or in the accounts given) and report orders that fill, " +
+			"partially fill, or are cancelled, expire, or are rejected. Events are posted to the webhooks, signed " +
+			"with the webhook secret, or written to the output as a line of JSON each.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.flags.interval <= 0 {
+				return errors.New("--interval must be greater than zero")
+			}
+			emitter, err := newOrderEventEmitter(
+				c.flags.webhooks, c.flags.webhookSecret, "webhook-secret", c.Context.OutputFile,
+			)
+			if err != nil {
+				return err
+			}
+
+			// Stop watching upon receiving an interrupt signal, which also
+			// cancels a request that's in progress.
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			eTradeClient := c.Context.Client.WithContext(ctx)
+			getClient := func() (client.ETradeClient, error) {
+				return eTradeClient, nil
+			}
+			watcher := newOrderWatcher(
+				globalFlags.customerId, getClient, args, emitter.Emit, emitter.Retry, time.Now,
+			)
+			watcher.Run(ctx, c.flags.interval, c.Context.Logger)
+			return nil
+		},
+	}
+	// Add Flags
+	cmd.Flags().DurationVarP(&c.flags.interval, "interval", "i", 30*time.Second, "how often to list orders")
+	cmd.Flags().StringArrayVarP(
+		&c.flags.webhooks, "webhook", "w", nil, "URL to post events to (may be repeated)",
+	)
+	cmd.Flags().StringVar(
+		&c.flags.webhookSecret, "webhook-secret", "",
+		"secret to sign webhook events with (defaults to "+orderWatchSecretEnvironmentVariable+")",
+	)
+	return cmd
+}
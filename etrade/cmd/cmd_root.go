@@ -23,6 +23,17 @@ func (c *RootCommand) Command() *cobra.Command {
 	cmd.PersistentFlags().StringVar(
 		&c.globalFlags.outputFileName, "output-file", "", "write output to specified file instead of stdout",
 	)
+	cmd.PersistentFlags().StringVar(
+		&c.globalFlags.recordDir, "record", "", "record ETrade requests and responses to the specified directory",
+	)
+	cmd.PersistentFlags().StringVar(
+		&c.globalFlags.replayDir, "replay", "",
+		"replay ETrade responses from the specified directory instead of sending requests",
+	)
+	cmd.PersistentFlags().DurationVar(
+		&c.globalFlags.timeout, "timeout", 0,
+		"cancel each ETrade request that takes longer than this, including retries (e.g. 30s; 0 means no timeout)",
+	)
 
 	// Initialize Global Enum Flag Values
 	c.globalFlags.outputFormat = *newEnumFlagValue(outputFormatMap, outputFormatCsv)
@@ -47,6 +58,8 @@ func (c *RootCommand) Command() *cobra.Command {
 	cmd.AddCommand((&CommandAuth{}).Command(&c.globalFlags))
 	cmd.AddCommand((&CommandCfg{}).Command(&c.globalFlags))
 	cmd.AddCommand((&CommandServer{}).Command(&c.globalFlags))
+	cmd.AddCommand((&CommandRules{}).Command(&c.globalFlags))
+	cmd.AddCommand((&CommandSnapshot{}).Command(&c.globalFlags))
 
 	return cmd
 }
@@ -55,4 +68,7 @@ var outputFormatMap = enumValueWithHelpMap[outputFormat]{
 	"csv":        {outputFormatCsv, "CSV output"},
 	"json":       {outputFormatJson, "raw JSON output"},
 	"jsonPretty": {outputFormatJsonPretty, "formatted JSON output"},
+	"form8949":   {outputFormatForm8949, "IRS Form 8949 rows as CSV (accounts gains only)"},
+	"txf":        {outputFormatTxf, "TXF file for tax software (accounts gains only)"},
+	"scheduleD":  {outputFormatScheduleD, "IRS Schedule D summary as CSV (accounts gains only)"},
 }
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CommandRules struct {
+	context CommandContextWithStore
+}
+
+func (c *CommandRules) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Local alert rule actions",
+		Long:  "Add, list, remove, or run alert rules that are evaluated locally against quotes and portfolios",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			context, err := NewCommandContextWithStoreFromFlags(globalFlags)
+			if err != nil {
+				return err
+			}
+			c.context = *context
+			return nil
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return c.context.Close()
+		},
+	}
+	// Add Subcommands
+	cmd.AddCommand((&CommandRulesAdd{Context: &c.context}).Command(globalFlags))
+	cmd.AddCommand((&CommandRulesList{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandRulesRemove{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandRulesRun{Context: &c.context}).Command(globalFlags))
+	return cmd
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+type rulesAddFlags struct {
+	symbol      string
+	accountId   string
+	value       float64
+	target      string
+	description string
+	field       enumFlagValue[RuleField]
+	operator    enumFlagValue[RuleOperator]
+	action      enumFlagValue[RuleActionType]
+}
+
+type CommandRulesAdd struct {
+	Context *CommandContextWithStore
+	flags   rulesAddFlags
+}
+
+func (c *CommandRulesAdd) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a rule",
+		Long: "Add a rule for the current Customer ID. Quote fields (lastTrade, changePct, spread, spreadPct) " +
+			"require --symbol. Portfolio fields (gainPct, dayGain, dayGainPct) require --account and test the " +
+			"position in --symbol or, without --symbol, the account's totals.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rule := Rule{
+				CustomerId:  globalFlags.customerId,
+				Description: c.flags.description,
+				Symbol:      c.flags.symbol,
+				AccountId:   c.flags.accountId,
+				Field:       c.flags.field.Value(),
+				Operator:    c.flags.operator.Value(),
+				Value:       c.flags.value,
+				Action:      c.flags.action.Value(),
+				Target:      c.flags.target,
+			}
+			if response, err := AddRule(
+				c.Context.ConfigurationFolder, c.Context.CustomerConfigurationStore, rule, c.Context.Logger,
+			); err == nil {
+				return c.Context.Renderer.Render(response, rulesAddDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+	// Add Flags
+	cmd.Flags().StringVarP(&c.flags.symbol, "symbol", "s", "", "symbol that the rule tests")
+	cmd.Flags().StringVarP(&c.flags.accountId, "account", "a", "", "account ID whose portfolio the rule tests")
+	cmd.Flags().Float64VarP(&c.flags.value, "value", "v", 0, "value that the field is compared to (required)")
+	cmd.Flags().StringVarP(
+		&c.flags.target, "target", "t", "", "webhook URL or shell command (for the webhook and shell actions)",
+	)
+	cmd.Flags().StringVarP(&c.flags.description, "description", "d", "", "description of the rule")
+	_ = cmd.MarkFlagRequired("value")
+
+	// Initialize Enum Flag Values
+	c.flags.field = *newEnumFlagValue(ruleFieldMap, "")
+	c.flags.operator = *newEnumFlagValue(ruleOperatorMap, "")
+	c.flags.action = *newEnumFlagValue(ruleActionMap, RuleActionStdout)
+
+	// Add Enum Flags
+	cmd.Flags().VarP(
+		&c.flags.field, "field", "f",
+		fmt.Sprintf("field that the rule tests (%s) (required)", c.flags.field.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"field",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return c.flags.field.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+	_ = cmd.MarkFlagRequired("field")
+
+	cmd.Flags().VarP(
+		&c.flags.operator, "operator", "o",
+		fmt.Sprintf("how the field is compared (%s) (required)", c.flags.operator.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"operator",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return c.flags.operator.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+	_ = cmd.MarkFlagRequired("operator")
+
+	cmd.Flags().Var(
+		&c.flags.action, "action",
+		fmt.Sprintf("what to do when the rule fires (%s)", c.flags.action.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"action",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return c.flags.action.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+	return cmd
+}
+
+var rulesAddDescriptor = []RenderDescriptor{
+	{
+		ObjectPath:   "",
+		Values:       rulesValues,
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
+
+var rulesValues = []RenderValue{
+	{Header: "Rule Id", Path: ".id"},
+	{Header: "Customer", Path: ".customerId"},
+	{Header: "Description", Path: ".description"},
+	{Header: "Symbol", Path: ".symbol"},
+	{Header: "Account", Path: ".accountId"},
+	{Header: "Field", Path: ".field"},
+	{Header: "Operator", Path: ".operator"},
+	{Header: "Value", Path: ".value"},
+	{Header: "Action", Path: ".action"},
+	{Header: "Target", Path: ".target"},
+	{Header: "Created", Path: ".created"},
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CommandRulesList struct {
+	Context *CommandContextWithStore
+}
+
+func (c *CommandRulesList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List rules",
+		Long:  "List the rules for all customers",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if response, err := ListRules(c.Context.ConfigurationFolder, c.Context.Logger); err == nil {
+				return c.Context.Renderer.Render(response, rulesListDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+	return cmd
+}
+
+var rulesListDescriptor = []RenderDescriptor{
+	{
+		ObjectPath:   ".rules",
+		Values:       rulesValues,
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CommandRulesRemove struct {
+	Context *CommandContextWithStore
+}
+
+func (c *CommandRulesRemove) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove [Rule Id]",
+		Short: "Remove a rule",
+		Long:  "Remove a rule by ID. A running \"rules run\" stops evaluating the rule at its next evaluation.",
+		Args:  cobra.MatchAll(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if response, err := RemoveRule(c.Context.ConfigurationFolder, args[0], c.Context.Logger); err == nil {
+				return c.Context.Renderer.Render(response, rulesRemoveDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+	return cmd
+}
+
+var rulesRemoveDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: "",
+		Values: []RenderValue{
+			{Header: "Status", Path: ".status"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
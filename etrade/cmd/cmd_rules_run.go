@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"time"
+)
+
+type rulesRunFlags struct {
+	interval time.Duration
+	once     bool
+}
+
+type CommandRulesRun struct {
+	Context *CommandContextWithStore
+	flags   rulesRunFlags
+}
+
+func (c *CommandRulesRun) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Run rules",
+		Long: "Evaluate the current Customer ID's rules every interval until interrupted, and take the actions of " +
+			"the rules whose conditions become met. Rules that are added or removed take effect at the next " +
+			"evaluation.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if c.flags.interval <= 0 {
+				return errors.New("--interval must be greater than zero")
+			}
+			eTradeClient, err := NewETradeClientForCustomer(
+				globalFlags.customerId, c.Context.ConfigurationFolder, c.Context.CustomerConfigurationStore,
+				c.Context.HttpClientWrapper, c.Context.Logger,
+			)
+			if err != nil {
+				return err
+			}
+			// The timeout applies to each request rather than to the whole
+			// run.
+			eTradeClient = eTradeClient.WithRequestTimeout(globalFlags.timeout)
+			actionRunner := newRuleActionRunner(c.Context.OutputFile, os.Stderr)
+			engine := newRuleEngine(nil, actionRunner.Fire, time.Now)
+			evaluate := func() error {
+				ruleStore, err := c.Context.ConfigurationFolder.LoadRules(c.Context.Logger)
+				if err != nil {
+					return fmt.Errorf("loading rules failed (%w)", err)
+				}
+				engine.SetRules(ruleStore.GetRulesForCustomer(globalFlags.customerId))
+				_, err = engine.Evaluate(eTradeClient)
+				return err
+			}
+			if c.flags.once {
+				return evaluate()
+			}
+
+			// Stop running upon receiving an interrupt signal
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			ticker := time.NewTicker(c.flags.interval)
+			defer ticker.Stop()
+			for {
+				if err := evaluate(); err != nil {
+					c.Context.Logger.Error(fmt.Errorf("evaluating rules failed (%w)", err).Error())
+				}
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+	// Add Flags
+	cmd.Flags().DurationVarP(&c.flags.interval, "interval", "i", time.Minute, "how often to evaluate the rules")
+	cmd.Flags().BoolVar(
+		&c.flags.once, "once", false,
+		"evaluate the rules once and exit (crossesAbove and crossesBelow rules never fire)",
+	)
+	return cmd
+}
@@ -2,15 +2,33 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/spf13/cobra"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"time"
 )
 
 type commandServerFlags struct {
-	listenAddr string
+	listenAddr     string
+	unixSocketPath string
+	unixSocketMode string
+	tlsCertPath    string
+	tlsKeyPath     string
+	tlsSelfSigned  bool
+	quoteInterval  time.Duration
+
+	watchOrders        []string
+	orderInterval      time.Duration
+	orderWebhooks      []string
+	orderWebhookSecret string
+
+	snapshotCustomers []string
+	snapshotInterval  time.Duration
+	snapshotLots      bool
 }
 
 type CommandServer struct {
@@ -35,12 +53,49 @@ func (c *CommandServer) Command(globalFlags *globalFlags) *cobra.Command {
 			return c.context.Close()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			unixSocketMode, err := strconv.ParseUint(c.flags.unixSocketMode, 8, 32)
+			if err != nil {
+				return fmt.Errorf("invalid unix socket mode %s (%w)", c.flags.unixSocketMode, err)
+			}
+			if c.flags.quoteInterval <= 0 {
+				return fmt.Errorf("invalid quote interval %s (must be greater than zero)", c.flags.quoteInterval)
+			}
+			orderWatch, err := c.getOrderWatch()
+			if err != nil {
+				return err
+			}
+			snapshotSchedule, err := c.getSnapshotSchedule()
+			if err != nil {
+				return err
+			}
+			tlsConfig, err := c.getTlsConfig()
+			if err != nil {
+				return err
+			}
 
-			_, _ = fmt.Fprintf(os.Stderr, "Starting server on: \"%s\"\n", c.flags.listenAddr)
+			// API tokens are required once a token file has been created (by
+			// "server tokens create"), even if all its tokens are revoked.
+			_, err = os.Stat(c.context.ConfigurationFolder.GetServerTokensPath())
+			requireTokens := err == nil
+			if !requireTokens {
+				_, _ = fmt.Fprintln(
+					os.Stderr, "Warning: no API tokens have been created, so the server accepts all requests",
+				)
+			}
+
+			listener, err := NewServerListener(c.flags.listenAddr, c.flags.unixSocketPath, os.FileMode(unixSocketMode))
+			if err != nil {
+				return err
+			}
+			if tlsConfig != nil {
+				listener = tls.NewListener(listener, tlsConfig)
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Starting server on: \"%s\"\n", listener.Addr().String())
 
 			server := NewETradeServer(
 				c.flags.listenAddr, c.context.Logger, c.context.ConfigurationFolder,
-				c.context.CustomerConfigurationStore,
+				c.context.CustomerConfigurationStore, c.context.HttpClientWrapper, globalFlags.timeout, requireTokens,
+				c.flags.quoteInterval, orderWatch, snapshotSchedule,
 			)
 
 			idleConnsClosed := make(chan struct{})
@@ -57,9 +112,9 @@ func (c *CommandServer) Command(globalFlags *globalFlags) *cobra.Command {
 				close(idleConnsClosed)
 			}()
 
-			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+			if err := server.Serve(listener); err != http.ErrServerClosed {
 				// Error starting or closing listener:
-				c.context.Logger.Error(fmt.Errorf("http server ListenAndServe() failed (%w)", err).Error())
+				c.context.Logger.Error(fmt.Errorf("http server Serve() failed (%w)", err).Error())
 				return err
 			}
 
@@ -69,5 +124,126 @@ func (c *CommandServer) Command(globalFlags *globalFlags) *cobra.Command {
 	}
 	// Add Flags
 	cmd.Flags().StringVarP(&c.flags.listenAddr, "addr", "a", ":8888", "server listen address:port")
+	cmd.Flags().StringVar(
+		&c.flags.unixSocketPath, "unix-socket", "", "listen on a Unix domain socket at this path instead of --addr",
+	)
+	cmd.Flags().StringVar(&c.flags.unixSocketMode, "unix-socket-mode", "0600", "Unix domain socket file permissions")
+	cmd.Flags().StringVar(&c.flags.tlsCertPath, "tls-cert", "", "TLS certificate file (requires --tls-key)")
+	cmd.Flags().StringVar(&c.flags.tlsKeyPath, "tls-key", "", "TLS private key file (requires --tls-cert)")
+	cmd.Flags().BoolVar(
+		&c.flags.tlsSelfSigned, "tls-self-signed", false,
+		"serve TLS with a self-signed certificate (generated on first use)",
+	)
+	cmd.Flags().DurationVar(
+		&c.flags.quoteInterval, "quote-interval", 5*time.Second, "how often to poll quotes for quote streams",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.flags.watchOrders, "watch-orders", nil,
+		"watch the orders of these customer IDs and report orders that fill, cancel, expire, or are rejected",
+	)
+	cmd.Flags().DurationVar(
+		&c.flags.orderInterval, "order-interval", 30*time.Second, "how often to list orders for --watch-orders",
+	)
+	cmd.Flags().StringArrayVar(
+		&c.flags.orderWebhooks, "order-webhook", nil,
+		"URL to post order events to (may be repeated; events are written to standard output without one)",
+	)
+	cmd.Flags().StringVar(
+		&c.flags.orderWebhookSecret, "order-webhook-secret", "",
+		"secret to sign order events with (defaults to "+orderWatchSecretEnvironmentVariable+")",
+	)
+	cmd.Flags().StringSliceVar(
+		&c.flags.snapshotCustomers, "snapshot", nil,
+		"take snapshots of the open accounts of these customer IDs (see the snapshot command)",
+	)
+	cmd.Flags().DurationVar(
+		&c.flags.snapshotInterval, "snapshot-interval", 24*time.Hour, "how often to take snapshots for --snapshot",
+	)
+	cmd.Flags().BoolVar(&c.flags.snapshotLots, "snapshot-lots", true, "include the positions' lots in snapshots")
+	cmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+	cmd.MarkFlagsMutuallyExclusive("tls-cert", "tls-self-signed")
+	cmd.MarkFlagsMutuallyExclusive("addr", "unix-socket")
+
+	// Add Subcommands
+	cmd.AddCommand((&CommandServerTokens{}).Command(globalFlags))
 	return cmd
 }
+
+// getOrderWatch returns the order watching requested by the flags, or nil if
+// it wasn't requested.
+func (c *CommandServer) getOrderWatch() (*ServerOrderWatch, error) {
+	if len(c.flags.watchOrders) == 0 {
+		return nil, nil
+	}
+	if c.flags.orderInterval <= 0 {
+		return nil, fmt.Errorf("invalid order interval %s (must be greater than zero)", c.flags.orderInterval)
+	}
+	for _, customerId := range c.flags.watchOrders {
+		if _, err := c.context.CustomerConfigurationStore.GetCustomerConfigurationById(customerId); err != nil {
+			return nil, fmt.Errorf("customer id '%s' not found in config file", customerId)
+		}
+	}
+	emitter, err := newOrderEventEmitter(
+		c.flags.orderWebhooks, c.flags.orderWebhookSecret, "order-webhook-secret", c.context.OutputFile,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerOrderWatch{
+		CustomerIds: c.flags.watchOrders,
+		Interval:    c.flags.orderInterval,
+		Emit:        emitter.Emit,
+		Retry:       emitter.Retry,
+	}, nil
+}
+
+// getSnapshotSchedule returns the scheduled snapshots requested by the flags,
+// or nil if they weren't requested.
+func (c *CommandServer) getSnapshotSchedule() (*ServerSnapshotSchedule, error) {
+	if len(c.flags.snapshotCustomers) == 0 {
+		return nil, nil
+	}
+	if c.flags.snapshotInterval <= 0 {
+		return nil, fmt.Errorf("invalid snapshot interval %s (must be greater than zero)", c.flags.snapshotInterval)
+	}
+	for _, customerId := range c.flags.snapshotCustomers {
+		if _, err := c.context.CustomerConfigurationStore.GetCustomerConfigurationById(customerId); err != nil {
+			return nil, fmt.Errorf("customer id '%s' not found in config file", customerId)
+		}
+	}
+	return &ServerSnapshotSchedule{
+		CustomerIds: c.flags.snapshotCustomers,
+		Interval:    c.flags.snapshotInterval,
+		WithLots:    c.flags.snapshotLots,
+	}, nil
+}
+
+// getTlsConfig returns the TLS configuration requested by the flags, or nil
+// if TLS wasn't requested.
+func (c *CommandServer) getTlsConfig() (*tls.Config, error) {
+	var certificate tls.Certificate
+	var err error
+	switch {
+	case c.flags.tlsCertPath != "":
+		certificate, err = tls.LoadX509KeyPair(c.flags.tlsCertPath, c.flags.tlsKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load TLS certificate (%w)", err)
+		}
+	case c.flags.tlsSelfSigned:
+		certPath, keyPath := c.context.ConfigurationFolder.GetServerCertificatePaths()
+		certificate, err = LoadOrCreateSelfSignedCertificate(certPath, keyPath, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("unable to create self-signed TLS certificate (%w)", err)
+		}
+		_, _ = fmt.Fprintf(
+			os.Stderr, "Using self-signed certificate %s (SHA-256 fingerprint %s)\n", certPath,
+			GetCertificateFingerprint(certificate),
+		)
+	default:
+		return nil, nil
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CommandServerTokens struct {
+	context CommandContextWithStore
+}
+
+func (c *CommandServerTokens) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Server API token actions",
+		Long:  "Create, list, or revoke the API tokens that the server requires",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			context, err := NewCommandContextWithStoreFromFlags(globalFlags)
+			if err != nil {
+				return err
+			}
+			c.context = *context
+			return nil
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return c.context.Close()
+		},
+	}
+	// Add Subcommands
+	cmd.AddCommand((&CommandServerTokensCreate{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandServerTokensList{Context: &c.context}).Command())
+	cmd.AddCommand((&CommandServerTokensRevoke{Context: &c.context}).Command())
+	return cmd
+}
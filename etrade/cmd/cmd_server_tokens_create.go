@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+)
+
+type serverTokensCreateFlags struct {
+	customerIds []string
+	capability  enumFlagValue[ServerTokenCapability]
+	description string
+}
+
+type CommandServerTokensCreate struct {
+	Context *CommandContextWithStore
+	flags   serverTokensCreateFlags
+}
+
+func (c *CommandServerTokensCreate) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an API token",
+		Long: "Create an API token for one or more customers. The token is only displayed once. Once any token has " +
+			"been created, the server requires a token with every request.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if response, err := CreateServerToken(
+				c.Context.ConfigurationFolder, c.Context.CustomerConfigurationStore, c.flags.description,
+				c.flags.customerIds, c.flags.capability.Value(), c.Context.Logger,
+			); err == nil {
+				return c.Context.Renderer.Render(response, serverTokensCreateDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+	// Add Flags
+	cmd.Flags().StringSliceVarP(
+		&c.flags.customerIds, "customers", "c", nil, "IDs of the customers that the token allows (required)",
+	)
+	cmd.Flags().StringVarP(&c.flags.description, "description", "d", "", "description of the token")
+	_ = cmd.MarkFlagRequired("customers")
+
+	// Initialize Enum Flag Values
+	c.flags.capability = *newEnumFlagValue(serverTokenCapabilityMap, ServerTokenCapabilityRead)
+
+	// Add Enum Flags
+	cmd.Flags().Var(
+		&c.flags.capability, "capability",
+		fmt.Sprintf("what the token allows (%s)", c.flags.capability.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"capability",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return c.flags.capability.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+	return cmd
+}
+
+var serverTokensCreateDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: "",
+		Values: []RenderValue{
+			{Header: "Token Id", Path: ".id"},
+			{Header: "Token", Path: ".token"},
+			{Header: "Customers", Path: ".customerIds", Transformer: transformStringList},
+			{Header: "Capability", Path: ".capability"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type CommandServerTokensList struct {
+	Context *CommandContextWithStore
+}
+
+func (c *CommandServerTokensList) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		Long:  "List the server's API tokens (without their secret values)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if response, err := ListServerTokens(c.Context.ConfigurationFolder, c.Context.Logger); err == nil {
+				return c.Context.Renderer.Render(response, serverTokensListDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+	return cmd
+}
+
+var serverTokensListDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: ".tokens",
+		Values: []RenderValue{
+			{Header: "Token Id", Path: ".id"},
+			{Header: "Description", Path: ".description"},
+			{Header: "Customers", Path: ".customerIds", Transformer: transformStringList},
+			{Header: "Capability", Path: ".capability"},
+			{Header: "Created", Path: ".created"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
+
+func transformStringList(value interface{}) interface{} {
+	if valueList, ok := value.(jsonmap.JsonSlice); ok {
+		stringList := make([]string, 0, len(valueList))
+		for _, v := range valueList {
+			if s, ok := v.(string); ok {
+				stringList = append(stringList, s)
+			}
+		}
+		return strings.Join(stringList, ", ")
+	} else {
+		return value
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CommandServerTokensRevoke struct {
+	Context *CommandContextWithStore
+}
+
+func (c *CommandServerTokensRevoke) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke [Token Id]",
+		Short: "Revoke an API token",
+		Long:  "Revoke an API token by ID. A running server rejects the token immediately.",
+		Args:  cobra.MatchAll(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if response, err := RevokeServerToken(
+				c.Context.ConfigurationFolder, args[0], c.Context.Logger,
+			); err == nil {
+				return c.Context.Renderer.Render(response, serverTokensRevokeDescriptor)
+			} else {
+				return err
+			}
+		},
+	}
+	return cmd
+}
+
+var serverTokensRevokeDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: "",
+		Values: []RenderValue{
+			{Header: "Status", Path: ".status"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+type CommandSnapshot struct {
+	context CommandContextWithStore
+}
+
+func (c *CommandSnapshot) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Local account history",
+		Long: "Take and query snapshots of accounts' balances, portfolios, and transactions, which are kept in " +
+			"the configuration folder as a durable record beyond ETrade's two years of history",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			context, err := NewCommandContextWithStoreFromFlags(globalFlags)
+			if err != nil {
+				return err
+			}
+			c.context = *context
+			return nil
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return c.context.Close()
+		},
+	}
+	// Add Subcommands
+	cmd.AddCommand((&CommandSnapshotTake{Context: &c.context}).Command(globalFlags))
+	cmd.AddCommand((&CommandSnapshotQuery{Context: &c.context}).Command())
+	return cmd
+}
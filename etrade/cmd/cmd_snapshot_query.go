@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// snapshotQueryKind is what a snapshot query returns.
+type snapshotQueryKind int
+
+const (
+	// snapshotQueryKindSnapshots is a summary of each of an account's
+	// snapshots.
+	snapshotQueryKindSnapshots snapshotQueryKind = iota
+
+	// snapshotQueryKindPositions is the positions in one of an account's
+	// snapshots.
+	snapshotQueryKindPositions
+
+	// snapshotQueryKindTransactions is an account's stored transactions.
+	snapshotQueryKindTransactions
+)
+
+type snapshotQueryFlags struct {
+	kind       enumFlagValue[snapshotQueryKind]
+	from       string
+	to         string
+	snapshotId string
+}
+
+type CommandSnapshotQuery struct {
+	Context *CommandContextWithStore
+	flags   snapshotQueryFlags
+}
+
+func (c *CommandSnapshotQuery) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "query [account ID]",
+		Short: "Query snapshots",
+		Long: "Query an account's stored snapshots, the positions in one snapshot (the one given with --snapshot " +
+			"or the latest one taken by --to), or its stored transactions",
+		Args: cobra.MatchAll(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			accountId := args[0]
+			location, err := time.LoadLocation("America/New_York")
+			if err != nil {
+				return err
+			}
+			var from *time.Time
+			if c.flags.from != "" {
+				fromDate, err := time.ParseInLocation("01022006", c.flags.from, location)
+				if err != nil {
+					return errors.New("from date must be in format MMDDYYYY")
+				}
+				from = &fromDate
+			}
+			var to *time.Time
+			if c.flags.to != "" {
+				toDate, err := time.ParseInLocation("01022006", c.flags.to, location)
+				if err != nil {
+					return errors.New("to date must be in format MMDDYYYY")
+				}
+				// The to date includes the whole day.
+				toDate = toDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+				to = &toDate
+			}
+
+			store := c.Context.ConfigurationFolder.OpenSnapshotStore(c.Context.Logger)
+			switch c.flags.kind.Value() {
+			case snapshotQueryKindPositions:
+				if response, err := GetSnapshotPortfolio(store, accountId, c.flags.snapshotId, to); err == nil {
+					return c.Context.Renderer.Render(response, GetQuickViewRenderDescriptor(true))
+				} else {
+					return err
+				}
+			case snapshotQueryKindTransactions:
+				if response, err := ListStoredTransactions(store, accountId, from, to); err == nil {
+					return c.Context.Renderer.Render(response, transactionListDescriptor)
+				} else {
+					return err
+				}
+			default:
+				if response, err := ListSnapshots(store, accountId, from, to); err == nil {
+					return c.Context.Renderer.Render(response, snapshotListDescriptor)
+				} else {
+					return err
+				}
+			}
+		},
+	}
+
+	// Add Flags
+	cmd.Flags().StringVarP(&c.flags.from, "from", "f", "", "start date (MMDDYYYY)")
+	cmd.Flags().StringVarP(&c.flags.to, "to", "t", "", "end date (MMDDYYYY)")
+	cmd.Flags().StringVarP(
+		&c.flags.snapshotId, "snapshot", "s", "", "snapshot ID to show the positions of (e.g. 20250131T210000Z)",
+	)
+
+	// Initialize Enum Flag Values
+	c.flags.kind = *newEnumFlagValue(snapshotQueryKindMap, snapshotQueryKindSnapshots)
+
+	// Add Enum Flags
+	cmd.Flags().VarP(
+		&c.flags.kind, "kind", "k",
+		fmt.Sprintf("what to query (%s)", c.flags.kind.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"kind",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return c.flags.kind.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	return cmd
+}
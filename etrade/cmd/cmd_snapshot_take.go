@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"time"
+)
+
+type snapshotTakeFlags struct {
+	withLots bool
+}
+
+type CommandSnapshotTake struct {
+	Context *CommandContextWithStore
+	flags   snapshotTakeFlags
+}
+
+func (c *CommandSnapshotTake) Command(globalFlags *globalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "take [account IDs...]",
+		Short: "Take snapshots",
+		Long: "Store a snapshot of the balances and portfolio of each account (or, if none are given, of all the " +
+			"current Customer ID's open accounts), and store each account's transactions that aren't already " +
+			"stored",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			eTradeClient, err := NewETradeClientForCustomer(
+				globalFlags.customerId, c.Context.ConfigurationFolder, c.Context.CustomerConfigurationStore,
+				c.Context.HttpClientWrapper, c.Context.Logger,
+			)
+			if err != nil {
+				return err
+			}
+			eTradeClient = eTradeClient.WithRequestTimeout(globalFlags.timeout)
+			store := c.Context.ConfigurationFolder.OpenSnapshotStore(c.Context.Logger)
+			response, err := TakeSnapshots(eTradeClient, store, args, c.flags.withLots, time.Now())
+			// Render the snapshots that were stored even if some accounts
+			// failed.
+			if response != nil {
+				if renderErr := c.Context.Renderer.Render(response, snapshotListDescriptor); renderErr != nil {
+					return renderErr
+				}
+			}
+			return err
+		},
+	}
+
+	// Add Flags
+	cmd.Flags().BoolVarP(&c.flags.withLots, "lots", "l", true, "include the positions' lots")
+
+	return cmd
+}
+
+var snapshotListDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: ".snapshots",
+		Values: []RenderValue{
+			{Header: "Snapshot ID", Path: ".id"},
+			{Header: "Account ID", Path: ".accountId"},
+			{Header: "Taken At", Path: ".takenAt"},
+			{Header: "Total Account Value", Path: ".totalAccountValue"},
+			{Header: "Total Market Value", Path: ".totalMarketValue"},
+			{Header: "Cash Balance", Path: ".cashBalance"},
+			{Header: "Positions", Path: ".positionCount"},
+			{Header: "Transactions Added", Path: ".transactionsAdded"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
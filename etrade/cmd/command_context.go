@@ -4,27 +4,35 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/paper"
 	"golang.org/x/exp/slog"
 	"os"
+	"time"
 )
 
 type CommandContext struct {
 	Logger              *slog.Logger
 	Renderer            Renderer
+	OutputFile          *os.File
 	ConfigurationFolder ConfigurationFolder
+	HttpClientWrapper   client.HttpClientWrapper
 }
 
 type CommandContextWithStore struct {
 	Logger                     *slog.Logger
 	Renderer                   Renderer
+	OutputFile                 *os.File
 	ConfigurationFolder        ConfigurationFolder
 	CustomerConfigurationStore *CustomerConfigurationStore
+	HttpClientWrapper          client.HttpClientWrapper
 }
 
 type CommandContextWithClient struct {
-	Logger   *slog.Logger
-	Renderer Renderer
-	Client   client.ETradeClient
+	Logger              *slog.Logger
+	Renderer            Renderer
+	OutputFile          *os.File
+	ConfigurationFolder ConfigurationFolder
+	Client              client.ETradeClient
 }
 
 func NewCommandContextFromFlags(flags *globalFlags) (*CommandContext, error) {
@@ -65,6 +73,24 @@ func NewCommandContextFromFlags(flags *globalFlags) (*CommandContext, error) {
 			outputFile: outputFile,
 			pretty:     true,
 		}
+	case outputFormatForm8949:
+		renderer = &taxRenderer{
+			outputFile: outputFile,
+			format:     taxFormatForm8949,
+			now:        time.Now,
+		}
+	case outputFormatTxf:
+		renderer = &taxRenderer{
+			outputFile: outputFile,
+			format:     taxFormatTxf,
+			now:        time.Now,
+		}
+	case outputFormatScheduleD:
+		renderer = &taxRenderer{
+			outputFile: outputFile,
+			format:     taxFormatScheduleD,
+			now:        time.Now,
+		}
 	default:
 		renderer = &csvRenderer{
 			outputFile: outputFile,
@@ -78,13 +104,44 @@ func NewCommandContextFromFlags(flags *globalFlags) (*CommandContext, error) {
 		return nil, fmt.Errorf("unable to locate the current user's home folder: %w", err)
 	}
 
+	httpClientWrapper, err := newHttpClientWrapper(flags.recordDir, flags.replayDir)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CommandContext{
 		Logger:              logger,
 		Renderer:            renderer,
+		OutputFile:          outputFile,
 		ConfigurationFolder: NewConfigurationFolder(configurationFolder),
+		HttpClientWrapper:   httpClientWrapper,
 	}, nil
 }
 
+// newHttpClientWrapper returns a wrapper that records ETrade requests to a
+// cassette directory or replays them from one. It returns nil if neither is
+// requested.
+func newHttpClientWrapper(recordDir string, replayDir string) (client.HttpClientWrapper, error) {
+	if recordDir != "" && replayDir != "" {
+		return nil, errors.New("--record and --replay can't be used together")
+	}
+	if recordDir != "" {
+		return func(httpClient client.HttpClient) client.HttpClient {
+			return client.CreateRecordingHttpClient(httpClient, recordDir)
+		}, nil
+	}
+	if replayDir != "" {
+		replayingClient, err := client.CreateReplayingHttpClient(replayDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load recordings for replay: %w", err)
+		}
+		return func(_ client.HttpClient) client.HttpClient {
+			return replayingClient
+		}, nil
+	}
+	return nil, nil
+}
+
 func (c *CommandContext) Close() error {
 	return c.Renderer.Close()
 }
@@ -107,8 +164,10 @@ func NewCommandContextWithStoreFromFlags(flags *globalFlags) (*CommandContextWit
 	return &CommandContextWithStore{
 		Logger:                     context.Logger,
 		Renderer:                   context.Renderer,
+		OutputFile:                 context.OutputFile,
 		ConfigurationFolder:        context.ConfigurationFolder,
 		CustomerConfigurationStore: customerConfigurationStore,
+		HttpClientWrapper:          context.HttpClientWrapper,
 	}, nil
 }
 
@@ -123,20 +182,24 @@ func NewCommandContextWithClientFromFlags(flags *globalFlags) (*CommandContextWi
 	}
 
 	eTradeClient, err := NewETradeClientForCustomer(
-		flags.customerId, context.ConfigurationFolder, context.CustomerConfigurationStore, context.Logger,
+		flags.customerId, context.ConfigurationFolder, context.CustomerConfigurationStore,
+		context.HttpClientWrapper, context.Logger,
 	)
 	if err != nil {
 		return nil, err
 	}
 	return &CommandContextWithClient{
-		Logger:   context.Logger,
-		Renderer: context.Renderer,
-		Client:   eTradeClient,
+		Logger:              context.Logger,
+		Renderer:            context.Renderer,
+		OutputFile:          context.OutputFile,
+		ConfigurationFolder: context.ConfigurationFolder,
+		Client:              eTradeClient.WithRequestTimeout(flags.timeout),
 	}, nil
 }
 
 func NewETradeClientForCustomer(
-	customerId string, cfgFolder ConfigurationFolder, cfgStore *CustomerConfigurationStore, logger *slog.Logger,
+	customerId string, cfgFolder ConfigurationFolder, cfgStore *CustomerConfigurationStore,
+	httpClientWrapper client.HttpClientWrapper, logger *slog.Logger,
 ) (client.ETradeClient, error) {
 	if customerId == "" {
 		return nil, errors.New("customer id must be specified with --customer-id flag")
@@ -146,6 +209,27 @@ func NewETradeClientForCustomer(
 		return nil, fmt.Errorf("customer id '%s' not found in config file", customerId)
 	}
 
+	var eTradeClient client.ETradeClient
+	if customerConfig.CustomerPaper {
+		eTradeClient, err = newPaperClientForCustomer(customerId, customerConfig, cfgFolder, logger)
+	} else {
+		eTradeClient, err = newLiveClientForCustomer(customerConfig, cfgFolder, httpClientWrapper, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// If the customer has an order policy, then enforce it on every order
+	// that the client submits.
+	if customerConfig.CustomerOrderPolicy != nil {
+		eTradeClient = newOrderPolicyClient(eTradeClient, customerConfig.CustomerOrderPolicy)
+	}
+	return eTradeClient, nil
+}
+
+func newLiveClientForCustomer(
+	customerConfig *CustomerConfiguration, cfgFolder ConfigurationFolder, httpClientWrapper client.HttpClientWrapper,
+	logger *slog.Logger,
+) (client.ETradeClient, error) {
 	// Try loading cached credentials
 	cachedCredentials, err := cfgFolder.LoadCachedCredentialsFromFile(customerConfig.CustomerConsumerKey, logger)
 	if err != nil {
@@ -155,12 +239,37 @@ func NewETradeClientForCustomer(
 		// customer.
 		cachedCredentials = &CachedCredentials{}
 	}
-	return client.CreateETradeClient(
-		logger, customerConfig.CustomerProduction, customerConfig.CustomerConsumerKey,
+	return client.CreateETradeClientWithHttpClientWrapper(
+		logger, customerConfig.GetEndpointProfile(), customerConfig.CustomerConsumerKey,
 		customerConfig.CustomerConsumerSecret, cachedCredentials.AccessToken, cachedCredentials.AccessSecret,
+		httpClientWrapper,
 	)
 }
 
+// newPaperClientForCustomer creates a client that trades fake money. Its state
+// is kept in the configuration folder and its orders fill against the
+// customer's quote file. Without a quote file, there are no quotes and orders
+// never fill.
+func newPaperClientForCustomer(
+	customerId string, customerConfig *CustomerConfiguration, cfgFolder ConfigurationFolder, logger *slog.Logger,
+) (client.ETradeClient, error) {
+	quotes := paper.NewQuoteSource(nil)
+	if customerConfig.CustomerPaperQuoteFile != "" {
+		var err error
+		quotes, err = paper.LoadQuoteSourceFromFile(cfgFolder.ResolvePath(customerConfig.CustomerPaperQuoteFile))
+		if err != nil {
+			return nil, fmt.Errorf("loading paper trading quotes failed (%w)", err)
+		}
+	}
+	startingCash := customerConfig.CustomerPaperStartingCash
+	if startingCash == 0 {
+		startingCash = paper.DefaultStartingCash
+	}
+	return paper.CreateETradeClient(
+		logger, cfgFolder.GetPaperStatePathForCustomer(customerId), startingCash, quotes,
+	), nil
+}
+
 func (c *CommandContextWithClient) Close() error {
 	return c.Renderer.Close()
 }
@@ -53,3 +53,56 @@ func (f ConfigurationFolder) GetFileCachePathForCustomer(customerConsumerKey str
 	cacheFilePath := filepath.Join(string(f), ".etrade", cacheFileName)
 	return cacheFilePath
 }
+
+func (f ConfigurationFolder) GetPaperStatePathForCustomer(customerId string) string {
+	stateFileName := "paper-" + customerId + ".json"
+	stateFilePath := filepath.Join(string(f), ".etrade", stateFileName)
+	return stateFilePath
+}
+
+// ResolvePath returns a path relative to the configuration folder, unless the
+// path is already absolute.
+func (f ConfigurationFolder) ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(string(f), path)
+}
+
+func (f ConfigurationFolder) LoadServerTokens(logger *slog.Logger) (*ServerTokenStore, error) {
+	return LoadServerTokenStoreFromFile(f.GetServerTokensPath(), logger)
+}
+
+func (f ConfigurationFolder) SaveServerTokens(store *ServerTokenStore, logger *slog.Logger) error {
+	return SaveServerTokenStoreToFile(f.GetServerTokensPath(), store, logger)
+}
+
+func (f ConfigurationFolder) GetServerTokensPath() string {
+	return filepath.Join(string(f), ".etrade", "server-tokens.json")
+}
+
+// GetServerCertificatePaths returns the paths to the certificate and the key
+// that the server generates when it's asked to use a self-signed certificate.
+func (f ConfigurationFolder) GetServerCertificatePaths() (certPath string, keyPath string) {
+	return filepath.Join(string(f), ".etrade", "server-cert.pem"), filepath.Join(string(f), ".etrade", "server-key.pem")
+}
+
+func (f ConfigurationFolder) LoadRules(logger *slog.Logger) (*RuleStore, error) {
+	return LoadRuleStoreFromFile(f.GetRulesPath(), logger)
+}
+
+func (f ConfigurationFolder) SaveRules(store *RuleStore, logger *slog.Logger) error {
+	return SaveRuleStoreToFile(f.GetRulesPath(), store, logger)
+}
+
+func (f ConfigurationFolder) GetRulesPath() string {
+	return filepath.Join(string(f), ".etrade", "rules.json")
+}
+
+func (f ConfigurationFolder) OpenSnapshotStore(logger *slog.Logger) *SnapshotStore {
+	return NewSnapshotStore(f.GetSnapshotsPath(), logger)
+}
+
+func (f ConfigurationFolder) GetSnapshotsPath() string {
+	return filepath.Join(string(f), ".etrade", "snapshots")
+}
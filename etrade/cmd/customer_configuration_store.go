@@ -4,16 +4,37 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
 	"golang.org/x/exp/slog"
 	"io"
 	"os"
 )
 
 type CustomerConfiguration struct {
-	CustomerName           string `json:"customerName"`
-	CustomerProduction     bool   `json:"customerProduction"`
-	CustomerConsumerKey    string `json:"customerConsumerKey"`
-	CustomerConsumerSecret string `json:"customerConsumerSecret"`
+	CustomerName              string                       `json:"customerName"`
+	CustomerProduction        bool                         `json:"customerProduction"`
+	CustomerEnvironment       *client.EndpointProfile      `json:"customerEnvironment,omitempty"`
+	CustomerConsumerKey       string                       `json:"customerConsumerKey"`
+	CustomerConsumerSecret    string                       `json:"customerConsumerSecret"`
+	CustomerOrderPolicy       *etradelib.ETradeOrderPolicy `json:"customerOrderPolicy,omitempty"`
+	CustomerPaper             bool                         `json:"customerPaper,omitempty"`
+	CustomerPaperQuoteFile    string                       `json:"customerPaperQuoteFile,omitempty"`
+	CustomerPaperStartingCash float64                      `json:"customerPaperStartingCash,omitempty"`
+	// CustomerUrlBase is the custom environment's base URL from
+	// configurations written before environment profiles. Loading a
+	// configuration moves it into CustomerEnvironment.
+	CustomerUrlBase string `json:"customerUrlBase,omitempty"`
+}
+
+// GetEndpointProfile returns the customer's environment profile. Without one,
+// the customer uses ETrade's production or sandbox servers, depending on
+// CustomerProduction.
+func (c *CustomerConfiguration) GetEndpointProfile() *client.EndpointProfile {
+	if c.CustomerEnvironment != nil {
+		return c.CustomerEnvironment
+	}
+	return client.NewEndpointProfile(c.CustomerProduction)
 }
 
 type CustomerConfigurationStore struct {
@@ -29,6 +50,22 @@ func LoadCustomerConfigurationStore(reader io.Reader) (*CustomerConfigurationSto
 	if err := json.Unmarshal(bytes, &cc.customerConfigMap); err != nil {
 		return nil, err
 	}
+	for customerId, customerConfig := range cc.customerConfigMap {
+		if customerConfig.CustomerUrlBase == "" {
+			continue
+		}
+		if customerConfig.CustomerEnvironment != nil {
+			return nil, fmt.Errorf(
+				"customer %s has both customerUrlBase and customerEnvironment (move the base URL into "+
+					"customerEnvironment)", customerId,
+			)
+		}
+		customerConfig.CustomerEnvironment = &client.EndpointProfile{
+			Environment: client.EndpointEnvironmentCustom, UrlBase: customerConfig.CustomerUrlBase,
+		}
+		customerConfig.CustomerUrlBase = ""
+		cc.customerConfigMap[customerId] = customerConfig
+	}
 	return &cc, nil
 }
 
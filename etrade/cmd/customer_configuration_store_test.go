@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
 	"github.com/stretchr/testify/assert"
 	"strings"
 	"testing"
@@ -67,6 +68,77 @@ func TestLoadCustomerConfigurationStore(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Can Load Environment Profile",
+			testJson: `{
+  "TestCustomerId": {
+    "customerName": "TestName",
+    "customerEnvironment": {
+      "environment": "custom",
+      "urlBase": "http://127.0.0.1:8080",
+      "authorizeApplicationUrl": "http://127.0.0.1:8080/authorize"
+    },
+    "customerConsumerKey": "TestKey",
+    "customerConsumerSecret": "TestSecret"
+  }
+}`,
+			expectErr: false,
+			expectValue: &CustomerConfigurationStore{
+				customerConfigMap: map[string]CustomerConfiguration{
+					"TestCustomerId": {
+						CustomerName: "TestName",
+						CustomerEnvironment: &client.EndpointProfile{
+							Environment:             client.EndpointEnvironmentCustom,
+							UrlBase:                 "http://127.0.0.1:8080",
+							AuthorizeApplicationUrl: "http://127.0.0.1:8080/authorize",
+						},
+						CustomerConsumerKey:    "TestKey",
+						CustomerConsumerSecret: "TestSecret",
+					},
+				},
+			},
+		},
+		{
+			name: "Moves Base URL Into Environment Profile",
+			testJson: `{
+  "TestCustomerId": {
+    "customerName": "TestName",
+    "customerUrlBase": "http://127.0.0.1:8080",
+    "customerConsumerKey": "TestKey",
+    "customerConsumerSecret": "TestSecret"
+  }
+}`,
+			expectErr: false,
+			expectValue: &CustomerConfigurationStore{
+				customerConfigMap: map[string]CustomerConfiguration{
+					"TestCustomerId": {
+						CustomerName: "TestName",
+						CustomerEnvironment: &client.EndpointProfile{
+							Environment: client.EndpointEnvironmentCustom,
+							UrlBase:     "http://127.0.0.1:8080",
+						},
+						CustomerConsumerKey:    "TestKey",
+						CustomerConsumerSecret: "TestSecret",
+					},
+				},
+			},
+		},
+		{
+			name: "Load Fails With Base URL And Environment Profile",
+			testJson: `{
+  "TestCustomerId": {
+    "customerName": "TestName",
+    "customerUrlBase": "http://127.0.0.1:8080",
+    "customerEnvironment": {
+      "environment": "production"
+    },
+    "customerConsumerKey": "TestKey",
+    "customerConsumerSecret": "TestSecret"
+  }
+}`,
+			expectErr:   true,
+			expectValue: (*CustomerConfigurationStore)(nil),
+		},
 		{
 			name: "Load Fails With Bad JSON",
 			testJson: `{
@@ -179,3 +251,44 @@ func TestCustomerConfigurationStore_GetAllConfigurations(t *testing.T) {
 	actualMap := testStore.GetAllConfigurations()
 	assert.Equal(t, expectedMap, actualMap)
 }
+
+func TestCustomerConfiguration_GetEndpointProfile(t *testing.T) {
+	tests := []struct {
+		name          string
+		testConfig    CustomerConfiguration
+		expectProfile *client.EndpointProfile
+	}{
+		{
+			name:          "Production Without Profile",
+			testConfig:    CustomerConfiguration{CustomerProduction: true},
+			expectProfile: &client.EndpointProfile{Environment: client.EndpointEnvironmentProduction},
+		},
+		{
+			name:          "Sandbox Without Profile",
+			testConfig:    CustomerConfiguration{CustomerProduction: false},
+			expectProfile: &client.EndpointProfile{Environment: client.EndpointEnvironmentSandbox},
+		},
+		{
+			name: "Profile Overrides Production Flag",
+			testConfig: CustomerConfiguration{
+				CustomerProduction: true,
+				CustomerEnvironment: &client.EndpointProfile{
+					Environment: client.EndpointEnvironmentCustom, UrlBase: "http://127.0.0.1:8080",
+				},
+			},
+			expectProfile: &client.EndpointProfile{
+				Environment: client.EndpointEnvironmentCustom, UrlBase: "http://127.0.0.1:8080",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualProfile := tt.testConfig.GetEndpointProfile()
+				assert.Equal(t, tt.expectProfile, actualProfile)
+			},
+		)
+	}
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// These tests run the CLI and the server against a fake ETrade server.
+
+const (
+	endToEndCustomerId     = "fake"
+	endToEndConsumerKey    = "fakeConsumerKey"
+	endToEndConsumerSecret = "fakeConsumerSecret"
+	endToEndAccessToken    = "endToEndAccessToken"
+)
+
+func TestEndToEnd_Commands(t *testing.T) {
+	tests := []struct {
+		name           string
+		testArgs       []string
+		expectErr      bool
+		expectContains []string
+	}{
+		{
+			name:           "Lists Accounts",
+			testArgs:       []string{"accounts", "list"},
+			expectErr:      false,
+			expectContains: []string{`"accountIdKey":"fakeAccountKey1"`, `"accountIdKey":"fakeAccountKey2"`},
+		},
+		{
+			name:           "Gets Balances",
+			testArgs:       []string{"accounts", "balances", "11111111"},
+			expectErr:      false,
+			expectContains: []string{`"cashBalance":5000.25`},
+		},
+		{
+			name:           "Views Portfolio With Lots",
+			testArgs:       []string{"accounts", "portfolio", "11111111", "--with-lots"},
+			expectErr:      false,
+			expectContains: []string{`"symbol":"AAPL"`, `"symbol":"MSFT"`, `"positionLotId":2002`},
+		},
+		{
+			name:           "Lists Transactions",
+			testArgs:       []string{"accounts", "transactions", "list", "11111111"},
+			expectErr:      false,
+			expectContains: []string{`"transactionId":"4001"`, `"transactionId":"4003"`},
+		},
+		{
+			name:           "Lists Orders",
+			testArgs:       []string{"orders", "list", "11111111"},
+			expectErr:      false,
+			expectContains: []string{`"orderId":5001`, `"orderId":5002`},
+		},
+		{
+			name:           "Lists Alerts",
+			testArgs:       []string{"alerts", "list"},
+			expectErr:      false,
+			expectContains: []string{`"id":6001`, `"id":6002`},
+		},
+		{
+			name:           "Gets Quotes",
+			testArgs:       []string{"market", "quote", "AAPL", "MSFT"},
+			expectErr:      false,
+			expectContains: []string{`"lastTrade":190`, `"lastTrade":220`},
+		},
+		{
+			name:           "Gets Option Expire Dates",
+			testArgs:       []string{"market", "optionexpire", "AAPL"},
+			expectErr:      false,
+			expectContains: []string{`"day":22`},
+		},
+		{
+			name:           "Fails With Unknown Account",
+			testArgs:       []string{"accounts", "balances", "99999999"},
+			expectErr:      true,
+			expectContains: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				newEndToEndFakeServer(t)
+				// Call the Method Under Test
+				output, err := runEndToEndCommand(t, tt.testArgs...)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				for _, expected := range tt.expectContains {
+					assert.Contains(t, output, expected)
+				}
+			},
+		)
+	}
+}
+
+func TestEndToEnd_PreviewAndPlaceOrder(t *testing.T) {
+	newEndToEndFakeServer(t)
+	orderArgs := []string{
+		"11111111", "AAPL", "--action", "buy", "--quantity", "5", "--price-type", "limit", "--limit-price", "150",
+		"--client-order-id", "e2e",
+	}
+
+	// Call the Method Under Test
+	output, err := runEndToEndCommand(t, append([]string{"orders", "preview"}, orderArgs...)...)
+	require.Nil(t, err)
+	previewMap, err := jsonmap.NewJsonMapFromJsonString(output)
+	require.Nil(t, err)
+	previewId, err := previewMap.GetIntAtPath(".previewIds[0].previewId")
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	output, err = runEndToEndCommand(
+		t, append([]string{"orders", "place", "--preview-id", fmt.Sprint(previewId)}, orderArgs...)...,
+	)
+	require.Nil(t, err)
+	placeMap, err := jsonmap.NewJsonMapFromJsonString(output)
+	require.Nil(t, err)
+	orderId, err := placeMap.GetIntAtPath(".orderIds[0].orderId")
+	require.Nil(t, err)
+
+	output, err = runEndToEndCommand(t, "orders", "list", "11111111", "--status", "open")
+	require.Nil(t, err)
+	assert.Contains(t, output, fmt.Sprintf(`"orderId":%d`, orderId))
+}
+
+func TestEndToEnd_Login(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	require.Nil(t, cfgFolder.RemoveCachedCredentialsFile(endToEndConsumerKey))
+
+	// Enter the verify code when the login command prompts for it.
+	stdinReader, stdinWriter, err := os.Pipe()
+	require.Nil(t, err)
+	_, err = stdinWriter.WriteString("FAKE1\n")
+	require.Nil(t, err)
+	require.Nil(t, stdinWriter.Close())
+	stdin := os.Stdin
+	os.Stdin = stdinReader
+	defer func() {
+		os.Stdin = stdin
+		_ = stdinReader.Close()
+	}()
+
+	// Call the Method Under Test
+	output, err := runEndToEndCommand(t, "auth", "login")
+	require.Nil(t, err)
+	assert.Contains(t, output, `"status":"success"`)
+	credentials, err := cfgFolder.LoadCachedCredentialsFromFile(
+		endToEndConsumerKey, etradelibtest.CreateNullLogger(),
+	)
+	require.Nil(t, err)
+	assert.NotEqual(t, "", credentials.AccessToken)
+
+	// The new credentials work.
+	_, err = runEndToEndCommand(t, "accounts", "list")
+	assert.Nil(t, err)
+}
+
+func TestEndToEnd_Server(t *testing.T) {
+	tests := []struct {
+		name           string
+		testMethod     string
+		testPath       string
+		expectStatus   int
+		expectContains []string
+	}{
+		{
+			name:           "Lists Accounts",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts",
+			expectStatus:   http.StatusOK,
+			expectContains: []string{`"accountIdKey":"fakeAccountKey1"`},
+		},
+		{
+			name:           "Views Portfolio",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts/11111111/portfolio",
+			expectStatus:   http.StatusOK,
+			expectContains: []string{`"symbol":"AAPL"`, `"totalMarketValue":6000`},
+		},
+		{
+			name:           "Lists Orders",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts/11111111/orders",
+			expectStatus:   http.StatusOK,
+			expectContains: []string{`"orderId":5002`},
+		},
+		{
+			name:           "Lists Orders With Deprecated Path",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts/11111111/transactions/orders",
+			expectStatus:   http.StatusOK,
+			expectContains: []string{`"orderId":5002`},
+		},
+		{
+			name:           "Cancels Order",
+			testMethod:     "DELETE",
+			testPath:       "/customers/fake/accounts/11111111/orders/5002",
+			expectStatus:   http.StatusOK,
+			expectContains: []string{`"orderId":5002`},
+		},
+		{
+			name:           "Gets Quotes",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/market/quote?symbol=AAPL",
+			expectStatus:   http.StatusOK,
+			expectContains: []string{`"lastTrade":190`},
+		},
+		{
+			name:           "Fails With Unknown Account",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts/99999999/balance",
+			expectStatus:   http.StatusInternalServerError,
+			expectContains: []string{`"status":"error"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, cfgFolder := newEndToEndFakeServer(t)
+				cfgStore, err := cfgFolder.LoadCustomerConfiguration(etradelibtest.CreateNullLogger())
+				require.Nil(t, err)
+				server := httptest.NewServer(
+					NewETradeServer(
+						"", etradelibtest.CreateNullLogger(), cfgFolder, cfgStore, nil, 0, false, time.Second, nil, nil,
+					).Handler,
+				)
+				defer server.Close()
+
+				request, err := http.NewRequest(tt.testMethod, server.URL+tt.testPath, nil)
+				require.Nil(t, err)
+				// Write routes require an idempotency key (and read routes
+				// ignore it).
+				request.Header.Set(idempotencyKeyHeader, tt.name)
+				// Call the Method Under Test
+				response, err := http.DefaultClient.Do(request)
+				require.Nil(t, err)
+				body, err := io.ReadAll(response.Body)
+				_ = response.Body.Close()
+				require.Nil(t, err)
+				assert.Equal(t, tt.expectStatus, response.StatusCode)
+				for _, expected := range tt.expectContains {
+					assert.Contains(t, string(body), expected)
+				}
+			},
+		)
+	}
+}
+
+func TestEndToEnd_Timeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+	cfgFolder := newEndToEndConfiguration(
+		t, http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				<-unblock
+			},
+		),
+	)
+
+	// Call the Method Under Test
+	start := time.Now()
+	_, err := runEndToEndCommand(t, "--timeout", "100ms", "accounts", "list")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	cfgStore, err := cfgFolder.LoadCustomerConfiguration(etradelibtest.CreateNullLogger())
+	require.Nil(t, err)
+	server := httptest.NewServer(
+		NewETradeServer(
+			"", etradelibtest.CreateNullLogger(), cfgFolder, cfgStore, nil, 100*time.Millisecond, false,
+			time.Second, nil, nil,
+		).Handler,
+	)
+	defer server.Close()
+
+	// Call the Method Under Test
+	start = time.Now()
+	response, err := http.Get(server.URL + "/customers/fake/accounts")
+	require.Nil(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// newEndToEndFakeServer starts a fake ETrade server with the default fixtures
+// and creates a configuration folder (in a temporary home folder) with a
+// customer that uses it and cached credentials that it accepts.
+func newEndToEndFakeServer(t *testing.T) (*etradelibtest.FakeETradeServer, ConfigurationFolder) {
+	fake := etradelibtest.NewFakeETradeServer(etradelibtest.CreateDefaultFakeETradeFixtures())
+	fake.AddAccessToken(endToEndAccessToken)
+	return fake, newEndToEndConfiguration(t, fake)
+}
+
+// newEndToEndConfiguration starts a server with the handler and creates a
+// configuration folder (in a temporary home folder) with a customer that uses
+// it and cached credentials.
+func newEndToEndConfiguration(t *testing.T, handler http.Handler) ConfigurationFolder {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfgFolder := NewConfigurationFolder(home)
+	cfgStore := &CustomerConfigurationStore{
+		customerConfigMap: map[string]CustomerConfiguration{
+			endToEndCustomerId: {
+				CustomerName: "Fake Customer",
+				CustomerEnvironment: &client.EndpointProfile{
+					Environment: client.EndpointEnvironmentCustom,
+					UrlBase:     server.URL,
+				},
+				CustomerConsumerKey:    endToEndConsumerKey,
+				CustomerConsumerSecret: endToEndConsumerSecret,
+			},
+		},
+	}
+	logger := etradelibtest.CreateNullLogger()
+	require.Nil(t, cfgFolder.SaveCustomerConfiguration(cfgStore, true, logger))
+	require.Nil(
+		t, cfgFolder.SaveCachedCredentialsToFile(
+			endToEndConsumerKey, &CachedCredentials{endToEndAccessToken, "secret", time.Now()}, logger,
+		),
+	)
+	return cfgFolder
+}
+
+// runEndToEndCommand runs the CLI for the fake customer and returns its JSON
+// output.
+func runEndToEndCommand(t *testing.T, args ...string) (string, error) {
+	outputFile := filepath.Join(t.TempDir(), "output.json")
+	rootCmd := (&RootCommand{}).Command()
+	rootCmd.SetArgs(
+		append([]string{"--customer-id", endToEndCustomerId, "--format", "json", "--output-file", outputFile}, args...),
+	)
+	err := rootCmd.Execute()
+	output, readErr := os.ReadFile(outputFile)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		require.Nil(t, readErr)
+	}
+	return strings.TrimSpace(string(output)), err
+}
@@ -139,6 +139,49 @@ var orderTransactionTypeMap = enumValueWithHelpMap[constants.OrderTransactionTyp
 	"mutualFundExchange": {constants.OrderTransactionTypeMutualFundExchange, "only mutual fund exchange orders"},
 }
 
+var orderActionMap = enumValueWithHelpMap[constants.OrderAction]{
+	"buy":        {constants.OrderActionBuy, "buy shares"},
+	"sell":       {constants.OrderActionSell, "sell shares"},
+	"buyToCover": {constants.OrderActionBuyToCover, "buy shares to cover a short position"},
+	"sellShort":  {constants.OrderActionSellShort, "sell shares short"},
+}
+
+var orderPriceTypeMap = enumValueWithHelpMap[constants.OrderPriceType]{
+	"market":    {constants.OrderPriceTypeMarket, "execute at the best available price"},
+	"limit":     {constants.OrderPriceTypeLimit, "execute at the limit price or better"},
+	"stop":      {constants.OrderPriceTypeStop, "become a market order at the stop price"},
+	"stopLimit": {constants.OrderPriceTypeStopLimit, "become a limit order at the stop price"},
+}
+
+var orderTermMap = enumValueWithHelpMap[constants.OrderTerm]{
+	"goodForDay":        {constants.OrderTermGoodForDay, "order is good until the end of the day"},
+	"goodUntilCancel":   {constants.OrderTermGoodUntilCancel, "order is good until canceled"},
+	"immediateOrCancel": {constants.OrderTermImmediateOrCancel, "fill what is possible now and cancel the rest"},
+	"fillOrKill":        {constants.OrderTermFillOrKill, "fill the entire order now or cancel it"},
+}
+
+var optionOrderActionMap = enumValueWithHelpMap[constants.OrderAction]{
+	"buyOpen":   {constants.OrderActionBuyOpen, "buy an option to open a position"},
+	"sellOpen":  {constants.OrderActionSellOpen, "sell (write) an option to open a position"},
+	"buyClose":  {constants.OrderActionBuyClose, "buy an option to close a short position"},
+	"sellClose": {constants.OrderActionSellClose, "sell an option to close a long position"},
+}
+
+var spreadOrderTypeMap = enumValueWithHelpMap[constants.OrderType]{
+	"spreads":       {constants.OrderTypeSpreads, "two-leg spread (vertical, calendar, straddle, strangle)"},
+	"butterfly":     {constants.OrderTypeButterfly, "three-leg butterfly"},
+	"ironButterfly": {constants.OrderTypeIronButterfly, "four-leg iron butterfly"},
+	"condor":        {constants.OrderTypeCondor, "four-leg condor"},
+	"ironCondor":    {constants.OrderTypeIronCondor, "four-leg iron condor"},
+}
+
+var spreadPriceTypeMap = enumValueWithHelpMap[constants.OrderPriceType]{
+	"market":    {constants.OrderPriceTypeMarket, "execute at the best available price"},
+	"netDebit":  {constants.OrderPriceTypeNetDebit, "pay no more than the limit price"},
+	"netCredit": {constants.OrderPriceTypeNetCredit, "receive no less than the limit price"},
+	"even":      {constants.OrderPriceTypeNetEven, "execute for no net debit or credit"},
+}
+
 var alertCategoryMap = enumValueWithHelpMap[constants.AlertCategory]{
 	"stock":   {constants.AlertCategoryStock, "only stock-related alerts"},
 	"account": {constants.AlertCategoryAccount, "only account-related alerts"},
@@ -186,3 +229,50 @@ var optionExpiryTypeMap = enumValueWithHelpMap[constants.OptionExpiryType]{
 	"all":         {constants.OptionExpiryTypeAll, "all expiry types"},
 	"monthEnd":    {constants.OptionExpiryTypeMonthEnd, "month-end expiry type"},
 }
+
+var serverTokenCapabilityMap = enumValueWithHelpMap[ServerTokenCapability]{
+	"read":  {ServerTokenCapabilityRead, "read accounts, orders, alerts, and market data"},
+	"trade": {ServerTokenCapabilityTrade, "also change orders and alerts, and log customers in and out"},
+}
+
+var ruleFieldMap = enumValueWithHelpMap[RuleField]{
+	"lastTrade":  {RuleFieldLastTrade, "the symbol's last trade price"},
+	"changePct":  {RuleFieldChangePct, "the symbol's % change since the previous close"},
+	"spread":     {RuleFieldSpread, "the symbol's ask minus its bid"},
+	"spreadPct":  {RuleFieldSpreadPct, "the symbol's bid/ask spread as a % of the midpoint"},
+	"gainPct":    {RuleFieldGainPct, "the position's (or account's) total gain %"},
+	"dayGain":    {RuleFieldDayGain, "the position's (or account's) gain today"},
+	"dayGainPct": {RuleFieldDayGainPct, "the position's (or account's) gain % today"},
+}
+
+var ruleOperatorMap = enumValueWithHelpMap[RuleOperator]{
+	"above":        {RuleOperatorAbove, "fire when the field goes above the value"},
+	"below":        {RuleOperatorBelow, "fire when the field goes below the value"},
+	"crossesAbove": {RuleOperatorCrossesAbove, "fire when the field rises through the value"},
+	"crossesBelow": {RuleOperatorCrossesBelow, "fire when the field falls through the value"},
+}
+
+var ruleActionMap = enumValueWithHelpMap[RuleActionType]{
+	"stdout":  {RuleActionStdout, "write the event to standard output"},
+	"webhook": {RuleActionWebhook, "post the event to the target URL"},
+	"shell":   {RuleActionShell, "run the target shell command"},
+}
+
+var lotMatchingMethodMap = enumValueWithHelpMap[LotMatchingMethod]{
+	"fifo":     {LotMatchingFifo, "sell the earliest-acquired lots first"},
+	"lifo":     {LotMatchingLifo, "sell the latest-acquired lots first"},
+	"hifo":     {LotMatchingHifo, "sell the lots with the highest cost per share first"},
+	"specific": {LotMatchingSpecific, "sell the lots given with --lot, then the earliest-acquired lots"},
+}
+
+var taxFormatMap = enumValueWithHelpMap[taxFormat]{
+	"form8949":  {taxFormatForm8949, "IRS Form 8949 rows as CSV"},
+	"txf":       {taxFormatTxf, "TXF file for tax software"},
+	"scheduleD": {taxFormatScheduleD, "IRS Schedule D summary as CSV"},
+}
+
+var snapshotQueryKindMap = enumValueWithHelpMap[snapshotQueryKind]{
+	"snapshots":    {snapshotQueryKindSnapshots, "a summary of each snapshot"},
+	"positions":    {snapshotQueryKindPositions, "the positions in one snapshot"},
+	"transactions": {snapshotQueryKindTransactions, "the stored transactions"},
+}
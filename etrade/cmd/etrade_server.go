@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -10,101 +11,462 @@ import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
 	"golang.org/x/exp/slog"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 type eTradeServer struct {
-	logger        *slog.Logger
-	cfgFolder     ConfigurationFolder
-	cfgStore      *CustomerConfigurationStore
+	logger            *slog.Logger
+	cfgFolder         ConfigurationFolder
+	cfgStore          *CustomerConfigurationStore
+	httpClientWrapper client.HttpClientWrapper
+	requestTimeout    time.Duration
+	quoteInterval     time.Duration
+	tokens            *serverTokenSource
+	routes            chi.Routes
+	idempotency       *idempotencyCache
+
+	// clientsMutex guards the client cache, the authentication locks, and
+	// the quote streams, which are used by concurrent requests.
+	clientsMutex  sync.Mutex
 	eTradeClients map[string]client.ETradeClient
+	authMutexes   map[string]*sync.Mutex
+	quoteStreams  map[string]*quoteStream
 }
 
+// NewETradeServer creates the server. ETrade requests made for a server
+// request are canceled if the caller disconnects or, if requestTimeout isn't
+// zero, if the server request takes longer than requestTimeout. If
+// requireTokens is true, every request must include an API token (see
+// ServerTokenStore) that allows it. Streamed quotes are polled every
+// quoteInterval, which must be greater than zero. If orderWatch isn't nil,
+// the server watches its customers' orders until it's shut down. If
+// snapshotSchedule isn't nil, the server takes snapshots of its customers'
+// accounts until it's shut down.
 func NewETradeServer(
 	addr string, logger *slog.Logger, cfgFolder ConfigurationFolder, cfgStore *CustomerConfigurationStore,
+	httpClientWrapper client.HttpClientWrapper, requestTimeout time.Duration, requireTokens bool,
+	quoteInterval time.Duration, orderWatch *ServerOrderWatch, snapshotSchedule *ServerSnapshotSchedule,
 ) *http.Server {
 	server := &eTradeServer{
-		logger:        logger,
-		cfgFolder:     cfgFolder,
-		cfgStore:      cfgStore,
-		eTradeClients: map[string]client.ETradeClient{},
+		logger:            logger,
+		cfgFolder:         cfgFolder,
+		cfgStore:          cfgStore,
+		httpClientWrapper: httpClientWrapper,
+		requestTimeout:    requestTimeout,
+		quoteInterval:     quoteInterval,
+		eTradeClients:     map[string]client.ETradeClient{},
+		authMutexes:       map[string]*sync.Mutex{},
+		quoteStreams:      map[string]*quoteStream{},
+		idempotency:       newIdempotencyCache(time.Now),
+	}
+	if requireTokens {
+		server.tokens = newServerTokenSource(cfgFolder.GetServerTokensPath(), logger)
 	}
 
 	r := chi.NewRouter()
-	r.Get("/customers", server.GetCustomerList)
-	r.Route(
-		"/customers/{customerId}", func(r chi.Router) {
-			r.Use(server.CustomerCtx)
-			r.Post("/auth", server.Login)
-			r.Delete("/auth", server.Logout)
-			r.Get("/accounts", server.ListAccounts)
-			r.Route(
-				"/accounts/{accountId}", func(r chi.Router) {
-					r.Get("/balance", server.GetAccountBalances)
-					r.Get("/portfolio", server.ViewPortfolio)
-					r.Get("/transactions", server.ListTransactions)
-					r.Get("/transactions/{transactionId}", server.ListTransactionDetails)
-					r.Get("/transactions/orders", server.ListOrders)
-				},
-			)
-			r.Get("/alerts", server.ListAlerts)
+	// The API documentation doesn't require an API token.
+	r.Get("/openapi.json", server.GetOpenApiDocument)
+	r.Get("/docs", server.GetDocs)
+	r.Group(
+		func(r chi.Router) {
+			r.Use(server.TokenCtx)
+			r.Get("/customers", server.GetCustomerList)
+			// Quote streams last until the caller disconnects, so they don't
+			// have the request timeout.
+			r.With(server.CustomerCtx).Get("/customers/{customerId}/market/quote/stream", server.StreamQuotes)
 			r.Route(
-				"/alerts/{alertId}", func(r chi.Router) {
-					r.Get("/", server.GetAlertDetails)
-					r.Delete("/", server.DeleteAlert)
+				"/customers/{customerId}", func(r chi.Router) {
+					r.Use(server.CustomerCtx, server.RequestTimeoutCtx)
+					// Logging in or out changes the customer's credentials for
+					// every caller, so it needs a token that allows trading.
+					r.With(server.RequireTrading).Post("/auth", server.Login)
+					r.With(server.RequireTrading).Delete("/auth", server.Logout)
+					r.Get("/accounts", server.ListAccounts)
+					r.Route(
+						"/accounts/{accountId}", func(r chi.Router) {
+							r.Get("/balance", server.GetAccountBalances)
+							r.Get("/portfolio", server.ViewPortfolio)
+							r.Get("/transactions", server.ListTransactions)
+							r.Get("/transactions/{transactionId}", server.ListTransactionDetails)
+							r.Get("/tax/8949", server.GetTaxForm)
+							// Orders were listed under transactions before they had
+							// their own routes.
+							r.With(server.Deprecated).Get("/transactions/orders", server.ListOrders)
+							r.Get("/orders", server.ListOrders)
+							r.Group(
+								func(r chi.Router) {
+									r.Use(server.RequireTrading, server.RequireIdempotencyKey)
+									r.Post("/orders/preview", server.PreviewOrder)
+									r.Post("/orders", server.PlaceOrder)
+									r.Put("/orders/{orderId}/preview", server.PreviewChangedOrder)
+									r.Put("/orders/{orderId}", server.PlaceChangedOrder)
+									r.Delete("/orders/{orderId}", server.CancelOrder)
+								},
+							)
+						},
+					)
+					r.Get("/alerts", server.ListAlerts)
+					r.Route(
+						"/alerts/{alertId}", func(r chi.Router) {
+							r.Get("/", server.GetAlertDetails)
+							r.With(server.RequireTrading).Delete("/", server.DeleteAlert)
+						},
+					)
+					r.Get("/market/lookup", server.Lookup)
+					r.Get("/market/quote", server.GetQuote)
+					r.Get("/market/optionchains", server.GetOptionChains)
+					r.Get("/market/optionexpire", server.GetOptionExpire)
 				},
 			)
-			r.Get("/market/lookup", server.Lookup)
-			r.Get("/market/quote", server.GetQuote)
-			r.Get("/market/optionchains", server.GetOptionChains)
-			r.Get("/market/optionexpire", server.GetOptionExpire)
 		},
 	)
-	return &http.Server{
+	server.routes = r
+	httpServer := &http.Server{
 		Addr:    addr,
 		Handler: r,
 	}
+	if orderWatch != nil {
+		httpServer.RegisterOnShutdown(server.WatchOrders(orderWatch))
+	}
+	if snapshotSchedule != nil {
+		httpServer.RegisterOnShutdown(server.TakeSnapshots(snapshotSchedule))
+	}
+	return httpServer
+}
+
+// ServerOrderWatch configures the server's order watchers.
+type ServerOrderWatch struct {
+	// CustomerIds are the customers whose orders are watched (in all their
+	// accounts).
+	CustomerIds []string
+	// Interval is how often orders are listed.
+	Interval time.Duration
+	// Emit sends an order event.
+	Emit func(event jsonmap.JsonMap) error
+	// Retry, if it isn't nil, retries the order events that Emit queued.
+	Retry func() error
+}
+
+// WatchOrders starts an order watcher for each of the customers. It returns
+// a function that stops the watchers.
+func (s *eTradeServer) WatchOrders(orderWatch *ServerOrderWatch) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	for _, customerId := range orderWatch.CustomerIds {
+		customerId := customerId
+		// Get the client for each poll, so that the watcher uses the current
+		// client after a logout.
+		getClient := func() (client.ETradeClient, error) {
+			eTradeClient, err := s.GetClientForCustomer(customerId)
+			if err != nil {
+				return nil, err
+			}
+			return eTradeClient.WithContext(ctx), nil
+		}
+		watcher := newOrderWatcher(customerId, getClient, nil, orderWatch.Emit, orderWatch.Retry, time.Now)
+		go watcher.Run(ctx, orderWatch.Interval, s.logger)
+	}
+	return cancel
+}
+
+// ServerSnapshotSchedule configures the server's scheduled snapshots.
+type ServerSnapshotSchedule struct {
+	// CustomerIds are the customers whose open accounts are snapshotted.
+	CustomerIds []string
+	// Interval is how often snapshots are taken.
+	Interval time.Duration
+	// WithLots includes the positions' lots in the snapshots.
+	WithLots bool
+}
+
+// TakeSnapshots takes snapshots of the customers' accounts when it's called
+// and then every interval. It returns a function that stops taking them.
+func (s *eTradeServer) TakeSnapshots(snapshotSchedule *ServerSnapshotSchedule) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := s.cfgFolder.OpenSnapshotStore(s.logger)
+	takeSnapshots := func() {
+		for _, customerId := range snapshotSchedule.CustomerIds {
+			// Get the client each time, so that the current client is used
+			// after a logout.
+			eTradeClient, err := s.GetClientForCustomer(customerId)
+			if err == nil {
+				_, err = TakeSnapshots(
+					eTradeClient.WithContext(ctx), store, nil, snapshotSchedule.WithLots, time.Now(),
+				)
+			}
+			if err != nil && ctx.Err() == nil {
+				s.logger.Error(fmt.Errorf("taking snapshots for customer %s failed (%w)", customerId, err).Error())
+			}
+		}
+	}
+	go func() {
+		ticker := time.NewTicker(snapshotSchedule.Interval)
+		defer ticker.Stop()
+		for {
+			takeSnapshots()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return cancel
+}
+
+// TokenCtx authenticates the API token in a request's Authorization header
+// (if the server requires tokens) and adds it to the request's context.
+func (s *eTradeServer) TokenCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if s.tokens == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tokenStore, err := s.tokens.GetStore()
+			if err != nil {
+				s.WriteError(w, err)
+				return
+			}
+			secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			var token *ServerToken
+			if ok {
+				token = tokenStore.Authenticate(secret)
+			}
+			if token == nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				s.WriteErrorWithStatus(w, http.StatusUnauthorized, errors.New("a valid API token is required"))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "serverToken", token)))
+		},
+	)
+}
+
+// RequireTrading rejects requests whose API token doesn't allow trading.
+func (s *eTradeServer) RequireTrading(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if token, ok := r.Context().Value("serverToken").(*ServerToken); ok && !token.AllowsTrading() {
+				s.WriteErrorWithStatus(w, http.StatusForbidden, errors.New("the API token doesn't allow trading"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+// RequireIdempotencyKey requires write requests to include an idempotency
+// key. The response to the first request with a key is replayed to later
+// requests for the customer with the same key, so that retrying a request
+// (e.g. after a timeout) never repeats it.
+func (s *eTradeServer) RequireIdempotencyKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if key == "" || len(key) > idempotencyKeyMaxLength {
+				s.WriteErrorWithStatus(
+					w, http.StatusBadRequest, fmt.Errorf(
+						"an %s header of up to %d characters is required", idempotencyKeyHeader,
+						idempotencyKeyMaxLength,
+					),
+				)
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, idempotencyMaxBodySize))
+			if err != nil {
+				s.WriteErrorWithStatus(w, http.StatusBadRequest, fmt.Errorf("reading request body failed (%w)", err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := getIdempotencyFingerprint(r, body)
+
+			response, isNew := s.idempotency.Begin(chi.URLParam(r, "customerId")+"\x00"+key, fingerprint)
+			if !isNew {
+				if response.fingerprint != fingerprint {
+					s.WriteErrorWithStatus(
+						w, http.StatusUnprocessableEntity,
+						fmt.Errorf("the %s was already used for a different request", idempotencyKeyHeader),
+					)
+					return
+				}
+				// Wait for the first request with the key to finish.
+				select {
+				case <-response.done:
+				case <-r.Context().Done():
+					s.WriteError(w, r.Context().Err())
+					return
+				}
+				for name, values := range response.header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(idempotencyReplayedHeader, "true")
+				w.WriteHeader(response.statusCode)
+				if _, err = w.Write(response.body); err != nil {
+					s.logger.Error(fmt.Errorf("writing replayed response failed (%w)", err).Error())
+				}
+				return
+			}
+
+			recorder := &idempotencyResponseRecorder{ResponseWriter: w}
+			defer func() {
+				statusCode := recorder.statusCode
+				if statusCode == 0 {
+					statusCode = http.StatusOK
+				}
+				s.idempotency.Complete(response, statusCode, w.Header().Clone(), recorder.body.Bytes())
+			}()
+			next.ServeHTTP(recorder, r)
+		},
+	)
+}
+
+// Deprecated marks the responses of deprecated routes with a Deprecation
+// header.
+func (s *eTradeServer) Deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			next.ServeHTTP(w, r)
+		},
+	)
 }
 
 func (s *eTradeServer) CustomerCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
 			customerId := chi.URLParam(r, "customerId")
+			if token, ok := r.Context().Value("serverToken").(*ServerToken); ok && !token.AllowsCustomer(customerId) {
+				s.WriteErrorWithStatus(
+					w, http.StatusForbidden, fmt.Errorf("the API token doesn't allow customer %s", customerId),
+				)
+				return
+			}
 			eTradeClient, err := s.GetClientForCustomer(customerId)
 			if err != nil {
 				http.Error(w, http.StatusText(404), 404)
 				return
 			}
-			ctx := context.WithValue(r.Context(), "eTradeClient", eTradeClient)
+			ctx := context.WithValue(r.Context(), "eTradeClient", eTradeClient.WithContext(r.Context()))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		},
 	)
 }
 
+// RequestTimeoutCtx cancels a request's ETrade requests if the request takes
+// longer than the server's request timeout (if it has one).
+func (s *eTradeServer) RequestTimeoutCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if s.requestTimeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			requestCtx, cancel := context.WithTimeout(r.Context(), s.requestTimeout)
+			defer cancel()
+			if eTradeClient, ok := requestCtx.Value("eTradeClient").(client.ETradeClient); ok {
+				requestCtx = context.WithValue(requestCtx, "eTradeClient", eTradeClient.WithContext(requestCtx))
+			}
+			next.ServeHTTP(w, r.WithContext(requestCtx))
+		},
+	)
+}
+
 func (s *eTradeServer) GetClientForCustomer(customerId string) (client.ETradeClient, error) {
+	// Hold the lock while creating a client so that concurrent requests for a
+	// new customer share one client (and its authentication state).
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
 	// See if there's already a cached client for this customerId
 	if eTradeClient, ok := s.eTradeClients[customerId]; ok {
 		return eTradeClient, nil
 	}
 	// If there's not a cached client, create a new one
-	if eTradeClient, err := NewETradeClientForCustomer(customerId, s.cfgFolder, s.cfgStore, s.logger); err == nil {
-		// Add the new client to the cache and return it
-		s.eTradeClients[customerId] = eTradeClient
-		return eTradeClient, nil
-	} else {
+	eTradeClient, err := NewETradeClientForCustomer(
+		customerId, s.cfgFolder, s.cfgStore, s.httpClientWrapper, s.logger,
+	)
+	if err != nil {
 		return nil, err
 	}
+	// Add the new client to the cache and return it
+	s.eTradeClients[customerId] = eTradeClient
+	return eTradeClient, nil
+}
+
+// GetQuoteStreamForCustomer returns the customer's quote stream, which is
+// shared by all the customer's quote subscribers.
+func (s *eTradeServer) GetQuoteStreamForCustomer(customerId string) *quoteStream {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+	stream, ok := s.quoteStreams[customerId]
+	if !ok {
+		stream = newQuoteStream(
+			func() (client.ETradeClient, error) {
+				return s.GetClientForCustomer(customerId)
+			}, s.quoteInterval, s.logger,
+		)
+		s.quoteStreams[customerId] = stream
+	}
+	return stream
 }
 
 func (s *eTradeServer) RemoveClientForCustomer(customerId string) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
 	delete(s.eTradeClients, customerId)
 }
 
-func (s *eTradeServer) GetCustomerList(w http.ResponseWriter, _ *http.Request) {
+// LockAuthForCustomer serializes the authentication requests (logging in and
+// out) for a customer, so that concurrent requests can't interleave a token
+// exchange with the saving or clearing of the customer's cached credentials.
+// It returns the function that unlocks it.
+func (s *eTradeServer) LockAuthForCustomer(customerId string) func() {
+	s.clientsMutex.Lock()
+	authMutex, ok := s.authMutexes[customerId]
+	if !ok {
+		authMutex = &sync.Mutex{}
+		s.authMutexes[customerId] = authMutex
+	}
+	s.clientsMutex.Unlock()
+	authMutex.Lock()
+	return authMutex.Unlock
+}
+
+func (s *eTradeServer) GetOpenApiDocument(w http.ResponseWriter, _ *http.Request) {
+	if document, err := NewServerOpenApiDocument(s.routes); err == nil {
+		s.WriteJsonMap(w, document)
+	} else {
+		s.WriteError(w, err)
+	}
+}
+
+func (s *eTradeServer) GetDocs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(serverDocsHtml); err != nil {
+		s.logger.Error(fmt.Errorf("writing docs response failed (%w)", err).Error())
+	}
+}
+
+func (s *eTradeServer) GetCustomerList(w http.ResponseWriter, r *http.Request) {
 	responseMap := GetCustomerList(s.cfgStore)
+	// Only list the customers that the request's API token allows.
+	if token, ok := r.Context().Value("serverToken").(*ServerToken); ok {
+		customers, _ := responseMap.GetSliceOfMaps("customers")
+		allowedCustomers := jsonmap.JsonSlice{}
+		for _, customer := range customers {
+			if customerId, _ := customer.GetString("customerId"); token.AllowsCustomer(customerId) {
+				allowedCustomers = append(allowedCustomers, customer)
+			}
+		}
+		responseMap.SetSlice("customers", allowedCustomers)
+	}
 	s.WriteJsonMap(w, responseMap)
 }
 
@@ -119,6 +481,7 @@ func (s *eTradeServer) Login(w http.ResponseWriter, r *http.Request) {
 		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
 		return
 	}
+	defer s.LockAuthForCustomer(chi.URLParam(r, "customerId"))()
 
 	if !r.Form.Has("verifyCode") {
 		// If the form does not include "verifyCode" then begin authentication.
@@ -173,6 +536,7 @@ func (s *eTradeServer) Login(w http.ResponseWriter, r *http.Request) {
 
 func (s *eTradeServer) Logout(w http.ResponseWriter, r *http.Request) {
 	customerId := chi.URLParam(r, "customerId")
+	defer s.LockAuthForCustomer(customerId)()
 	// Remove cached ETradeClient
 	s.RemoveClientForCustomer(customerId)
 	// Remove credential cache
@@ -323,6 +687,76 @@ func (s *eTradeServer) ListTransactionDetails(w http.ResponseWriter, r *http.Req
 	}
 }
 
+// GetTaxForm writes the realized gains in the year as a tax form or file,
+// rather than as JSON.
+func (s *eTradeServer) GetTaxForm(w http.ResponseWriter, r *http.Request) {
+	accountId := chi.URLParam(r, "accountId")
+
+	year, err := getIntWithDefaultFromValues(r.URL.Query(), "year", time.Now().Year())
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+
+	method, err := getEnumFlagWithDefaultFromValues(r.URL.Query(), "method", lotMatchingMethodMap, LotMatchingFifo)
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+
+	lots := r.URL.Query()["lot"]
+	if len(lots) > 0 && method != LotMatchingSpecific {
+		s.WriteError(w, errors.New("lot requires method specific"))
+		return
+	}
+	specificLots := make([]SpecificLot, 0, len(lots))
+	for _, lot := range lots {
+		specificLot, err := ParseSpecificLot(lot)
+		if err != nil {
+			s.WriteError(w, err)
+			return
+		}
+		specificLots = append(specificLots, specificLot)
+	}
+
+	format, err := getEnumFlagWithDefaultFromValues(r.URL.Query(), "format", taxFormatMap, taxFormatForm8949)
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+
+	eTradeClient, ok := r.Context().Value("eTradeClient").(client.ETradeClient)
+	if !ok {
+		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
+		return
+	}
+	response, err := GetRealizedGains(eTradeClient, accountId, year, method, specificLots, time.Now())
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	// The form is rendered before it's written so that a rendering error can
+	// still be written as an error response.
+	var form bytes.Buffer
+	renderer := &taxRenderer{outputFile: &form, format: format, now: time.Now}
+	if err = renderer.Render(response, nil); err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	contentType, fileName := "text/csv; charset=utf-8", fmt.Sprintf("%s-%d-form8949.csv", accountId, year)
+	switch format {
+	case taxFormatTxf:
+		contentType, fileName = "text/plain; charset=utf-8", fmt.Sprintf("%s-%d.txf", accountId, year)
+	case taxFormatScheduleD:
+		fileName = fmt.Sprintf("%s-%d-scheduleD.csv", accountId, year)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	if _, err = w.Write(form.Bytes()); err != nil {
+		s.logger.Error(fmt.Errorf("writing tax form response failed (%w)", err).Error())
+	}
+}
+
 func (s *eTradeServer) ListOrders(w http.ResponseWriter, r *http.Request) {
 	accountId := chi.URLParam(r, "accountId")
 	symbols := r.URL.Query()["symbol"]
@@ -382,6 +816,155 @@ func (s *eTradeServer) ListOrders(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *eTradeServer) PreviewOrder(w http.ResponseWriter, r *http.Request) {
+	accountId := chi.URLParam(r, "accountId")
+	if err := r.ParseForm(); err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	// If no client order ID was provided, generate one. The same ID must be
+	// used when placing the order.
+	if !r.Form.Has("clientOrderId") {
+		clientOrderId, err := etradelib.NewClientOrderId()
+		if err != nil {
+			s.WriteError(w, err)
+			return
+		}
+		r.Form.Set("clientOrderId", clientOrderId)
+	}
+	request, err := getEquityOrderRequestFromValues(r.Form)
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+
+	if eTradeClient, ok := r.Context().Value("eTradeClient").(client.ETradeClient); ok {
+		if response, err := PreviewOrder(eTradeClient, accountId, request); err == nil {
+			s.WriteJsonMap(w, response)
+		} else {
+			s.WriteError(w, err)
+		}
+	} else {
+		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
+	}
+}
+
+func (s *eTradeServer) PlaceOrder(w http.ResponseWriter, r *http.Request) {
+	accountId := chi.URLParam(r, "accountId")
+	if err := r.ParseForm(); err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	previewId, err := getInt64WithDefaultFromValues(r.Form, "previewId", 0)
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	if previewId == 0 {
+		s.WriteError(w, errors.New("missing preview ID"))
+		return
+	}
+	request, err := getEquityOrderRequestFromValues(r.Form)
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+
+	if eTradeClient, ok := r.Context().Value("eTradeClient").(client.ETradeClient); ok {
+		if response, err := PlaceOrder(eTradeClient, accountId, previewId, request); err == nil {
+			s.WriteJsonMap(w, response)
+		} else {
+			s.WriteError(w, err)
+		}
+	} else {
+		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
+	}
+}
+
+func (s *eTradeServer) PreviewChangedOrder(w http.ResponseWriter, r *http.Request) {
+	accountId := chi.URLParam(r, "accountId")
+	orderId, err := strconv.ParseInt(chi.URLParam(r, "orderId"), 10, 64)
+	if err != nil {
+		s.WriteError(w, errors.New("invalid order ID"))
+		return
+	}
+	changes, err := getOrderChangesFromValues(r.URL.Query())
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	clientOrderId := getStringWithDefaultFromValues(r.URL.Query(), "clientOrderId", "")
+	if clientOrderId == "" {
+		if clientOrderId, err = etradelib.NewClientOrderId(); err != nil {
+			s.WriteError(w, err)
+			return
+		}
+	}
+
+	if eTradeClient, ok := r.Context().Value("eTradeClient").(client.ETradeClient); ok {
+		if response, err := PreviewChangedOrder(
+			eTradeClient, accountId, orderId, clientOrderId, *changes,
+		); err == nil {
+			s.WriteJsonMap(w, response)
+		} else {
+			s.WriteError(w, err)
+		}
+	} else {
+		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
+	}
+}
+
+func (s *eTradeServer) PlaceChangedOrder(w http.ResponseWriter, r *http.Request) {
+	accountId := chi.URLParam(r, "accountId")
+	orderId, err := strconv.ParseInt(chi.URLParam(r, "orderId"), 10, 64)
+	if err != nil {
+		s.WriteError(w, errors.New("invalid order ID"))
+		return
+	}
+	changes, err := getOrderChangesFromValues(r.URL.Query())
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	previewId, err := getInt64WithDefaultFromValues(r.URL.Query(), "previewId", 0)
+	if err != nil {
+		s.WriteError(w, err)
+		return
+	}
+	clientOrderId := getStringWithDefaultFromValues(r.URL.Query(), "clientOrderId", "")
+
+	if eTradeClient, ok := r.Context().Value("eTradeClient").(client.ETradeClient); ok {
+		if response, err := PlaceChangedOrder(
+			eTradeClient, accountId, orderId, previewId, clientOrderId, *changes,
+		); err == nil {
+			s.WriteJsonMap(w, response)
+		} else {
+			s.WriteError(w, err)
+		}
+	} else {
+		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
+	}
+}
+
+func (s *eTradeServer) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	accountId := chi.URLParam(r, "accountId")
+	orderId, err := strconv.ParseInt(chi.URLParam(r, "orderId"), 10, 64)
+	if err != nil {
+		s.WriteError(w, errors.New("invalid order ID"))
+		return
+	}
+
+	if eTradeClient, ok := r.Context().Value("eTradeClient").(client.ETradeClient); ok {
+		if response, err := CancelOrders(eTradeClient, accountId, []int64{orderId}); err == nil {
+			s.WriteJsonMap(w, response)
+		} else {
+			s.WriteError(w, err)
+		}
+	} else {
+		s.WriteError(w, errors.New("unable to find ETrade client for customer"))
+	}
+}
+
 func (s *eTradeServer) ListAlerts(w http.ResponseWriter, r *http.Request) {
 	count, err := getIntWithDefaultFromValues(r.URL.Query(), "count", -1)
 	if err != nil {
@@ -508,6 +1091,65 @@ func (s *eTradeServer) GetQuote(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// StreamQuotes streams quotes as server-sent events until the caller
+// disconnects. Each "quote" event has a symbol and the quote fields that
+// changed since the previous event for the symbol (the first event has the
+// whole quote). Polling failures are sent as "error" events.
+func (s *eTradeServer) StreamQuotes(w http.ResponseWriter, r *http.Request) {
+	symbols := r.URL.Query()["symbol"]
+	if len(symbols) == 0 {
+		s.WriteErrorWithStatus(w, http.StatusBadRequest, errors.New("missing symbol"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.WriteError(w, errors.New("streaming is not supported by the connection"))
+		return
+	}
+
+	stream := s.GetQuoteStreamForCustomer(chi.URLParam(r, "customerId"))
+	subscriber := stream.Subscribe(symbols)
+	defer stream.Unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(quoteStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-subscriber.notify:
+			updates, err := stream.TakeUpdates(subscriber)
+			if err != nil {
+				if writeErr := s.WriteEvent(w, "error", client.NewStatusMap("error", "error", err.Error())); writeErr != nil {
+					return
+				}
+			}
+			updatedSymbols := make([]string, 0, len(updates))
+			for symbol := range updates {
+				updatedSymbols = append(updatedSymbols, symbol)
+			}
+			sort.Strings(updatedSymbols)
+			for _, symbol := range updatedSymbols {
+				if err := s.WriteEvent(
+					w, "quote", jsonmap.JsonMap{"symbol": symbol, "quote": updates[symbol]},
+				); err != nil {
+					return
+				}
+			}
+		}
+		flusher.Flush()
+	}
+}
+
 func (s *eTradeServer) GetOptionChains(w http.ResponseWriter, r *http.Request) {
 	symbol := getStringWithDefaultFromValues(r.URL.Query(), "symbol", "")
 	if symbol == "" {
@@ -634,9 +1276,48 @@ func (s *eTradeServer) WriteJsonMap(w http.ResponseWriter, jsonMap jsonmap.JsonM
 	}
 }
 
+// WriteEvent writes a server-sent event with the JSON map as its data.
+func (s *eTradeServer) WriteEvent(w http.ResponseWriter, event string, data jsonmap.JsonMap) error {
+	dataBytes, err := data.ToJsonBytes(false, false)
+	if err != nil {
+		s.logger.Error(fmt.Errorf("marshaling JSON event failed (%w)", err).Error())
+		return err
+	}
+	if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, bytes.TrimSpace(dataBytes)); err != nil {
+		s.logger.Debug(fmt.Errorf("writing event failed (%w)", err).Error())
+		return err
+	}
+	return nil
+}
+
 func (s *eTradeServer) WriteError(w http.ResponseWriter, err error) {
 	s.logger.Error(fmt.Errorf("server encountered an error processing request (%w)", err).Error())
 	responseMap := client.NewStatusMap("error", "error", err.Error())
+	statusCode := http.StatusInternalServerError
+	// Orders blocked by the customer's order policy are reported with the
+	// policy violations rather than as generic errors.
+	var violationError *etradelib.ETradeOrderPolicyViolationError
+	if errors.As(err, &violationError) {
+		responseMap = violationError.AsJsonMap()
+		statusCode = http.StatusForbidden
+	}
+	responseBytes, err := responseMap.ToJsonBytes(false, false)
+	if err != nil {
+		s.logger.Error(fmt.Errorf("marshaling JSON error response failed (%w)", err).Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if _, err = w.Write(responseBytes); err != nil {
+		s.logger.Error(fmt.Errorf("writing JSON error response failed (%w)", err).Error())
+	}
+}
+
+// WriteErrorWithStatus writes an error response with the status code.
+func (s *eTradeServer) WriteErrorWithStatus(w http.ResponseWriter, statusCode int, err error) {
+	s.logger.Debug(fmt.Errorf("server rejected request (%w)", err).Error())
+	responseMap := client.NewStatusMap("error", "error", err.Error())
 	responseBytes, err := responseMap.ToJsonBytes(false, false)
 	if err != nil {
 		s.logger.Error(fmt.Errorf("marshaling JSON error response failed (%w)", err).Error())
@@ -644,7 +1325,7 @@ func (s *eTradeServer) WriteError(w http.ResponseWriter, err error) {
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(statusCode)
 	if _, err = w.Write(responseBytes); err != nil {
 		s.logger.Error(fmt.Errorf("writing JSON error response failed (%w)", err).Error())
 	}
@@ -669,6 +1350,30 @@ func getIntWithDefaultFromValues(v url.Values, key string, defaultValue int) (in
 	}
 }
 
+func getInt64WithDefaultFromValues(v url.Values, key string, defaultValue int64) (int64, error) {
+	if !v.Has(key) {
+		return defaultValue, nil
+	}
+	stringValue := v.Get(key)
+	if value, err := strconv.ParseInt(stringValue, 10, 64); err == nil {
+		return value, nil
+	} else {
+		return 0, fmt.Errorf("%s is not a valid integer (%w)", stringValue, err)
+	}
+}
+
+func getFloatWithDefaultFromValues(v url.Values, key string, defaultValue float64) (float64, error) {
+	if !v.Has(key) {
+		return defaultValue, nil
+	}
+	stringValue := v.Get(key)
+	if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
+		return value, nil
+	} else {
+		return 0, fmt.Errorf("%s is not a valid number (%w)", stringValue, err)
+	}
+}
+
 func getBoolWithDefaultFromValues(v url.Values, key string, defaultValue bool) (bool, error) {
 	if !v.Has(key) {
 		return defaultValue, nil
@@ -709,3 +1414,78 @@ func getEnumFlagWithDefaultFromValues[T comparable](
 		return retVal, fmt.Errorf("%s is not a valid value for %s (%w)", enumString, key, err)
 	}
 }
+
+// getEquityOrderRequestFromValues creates an equity order request. The
+// defaults match the "orders preview" command's flags.
+func getEquityOrderRequestFromValues(v url.Values) (etradelib.ETradeOrderRequest, error) {
+	quantity, err := getInt64WithDefaultFromValues(v, "quantity", 0)
+	if err != nil {
+		return nil, err
+	}
+	limitPrice, err := getFloatWithDefaultFromValues(v, "limitPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	stopPrice, err := getFloatWithDefaultFromValues(v, "stopPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	allOrNone, err := getBoolWithDefaultFromValues(v, "allOrNone", false)
+	if err != nil {
+		return nil, err
+	}
+	orderAction, err := getEnumFlagWithDefaultFromValues(v, "action", orderActionMap, constants.OrderActionNil)
+	if err != nil {
+		return nil, err
+	}
+	priceType, err := getEnumFlagWithDefaultFromValues(
+		v, "priceType", orderPriceTypeMap, constants.OrderPriceTypeMarket,
+	)
+	if err != nil {
+		return nil, err
+	}
+	orderTerm, err := getEnumFlagWithDefaultFromValues(v, "term", orderTermMap, constants.OrderTermGoodForDay)
+	if err != nil {
+		return nil, err
+	}
+	marketSession, err := getEnumFlagWithDefaultFromValues(
+		v, "marketSession", marketSessionMap, constants.MarketSessionRegular,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return etradelib.CreateETradeEquityOrderRequest(
+		v.Get("clientOrderId"), v.Get("symbol"), orderAction, quantity, priceType, limitPrice, stopPrice, orderTerm,
+		marketSession, allOrNone,
+	)
+}
+
+func getOrderChangesFromValues(v url.Values) (*etradelib.ETradeOrderChanges, error) {
+	quantity, err := getInt64WithDefaultFromValues(v, "quantity", 0)
+	if err != nil {
+		return nil, err
+	}
+	limitPrice, err := getFloatWithDefaultFromValues(v, "limitPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	stopPrice, err := getFloatWithDefaultFromValues(v, "stopPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	priceType, err := getEnumFlagWithDefaultFromValues(v, "priceType", orderPriceTypeMap, constants.OrderPriceTypeNil)
+	if err != nil {
+		return nil, err
+	}
+	orderTerm, err := getEnumFlagWithDefaultFromValues(v, "term", orderTermMap, constants.OrderTermNil)
+	if err != nil {
+		return nil, err
+	}
+	return &etradelib.ETradeOrderChanges{
+		Quantity:   quantity,
+		PriceType:  priceType,
+		LimitPrice: limitPrice,
+		StopPrice:  stopPrice,
+		OrderTerm:  orderTerm,
+	}, nil
+}
@@ -0,0 +1,472 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The concurrent request tests should be run with the race detector (go test
+// -race) to check the server's synchronization.
+
+func TestETradeServer_ConcurrentRequests(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	server := newTestETradeServer(t, cfgFolder, false)
+
+	tests := []struct {
+		name           string
+		testMethod     string
+		testPath       string
+		expectContains string
+	}{
+		{
+			name:           "Lists Customers",
+			testMethod:     "GET",
+			testPath:       "/customers",
+			expectContains: `"customerId":"fake"`,
+		},
+		{
+			name:           "Renews Authentication",
+			testMethod:     "POST",
+			testPath:       "/customers/fake/auth",
+			expectContains: `"status":"success"`,
+		},
+		{
+			name:           "Lists Accounts",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts",
+			expectContains: `"accountIdKey":"fakeAccountKey1"`,
+		},
+		{
+			name:           "Gets Balances",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts/11111111/balance",
+			expectContains: `"accountId":"11111111"`,
+		},
+		{
+			name:           "Gets Quotes",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/market/quote?symbol=AAPL",
+			expectContains: `"lastTrade":190`,
+		},
+	}
+
+	// Call the Method Under Test
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		for _, tt := range tests {
+			wg.Add(1)
+			go func(testMethod, testPath, expectContains string) {
+				defer wg.Done()
+				status, body := doTestServerRequest(t, server, testMethod, testPath, nil)
+				assert.Equal(t, http.StatusOK, status, testPath)
+				assert.Contains(t, body, expectContains, testPath)
+			}(tt.testMethod, tt.testPath, tt.expectContains)
+		}
+	}
+	wg.Wait()
+}
+
+func TestETradeServer_ConcurrentAuthentication(t *testing.T) {
+	// The fake server doesn't know the cached access token, so renewing it
+	// fails and every login begins a new authorization.
+	fake := etradelibtest.NewFakeETradeServer(etradelibtest.CreateDefaultFakeETradeFixtures())
+	cfgFolder := newEndToEndConfiguration(t, fake)
+	server := newTestETradeServer(t, cfgFolder, false)
+
+	// Call the Method Under Test
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, body := doTestServerRequest(t, server, "POST", "/customers/fake/auth", nil)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Contains(t, body, `"status":"authorize"`)
+		}()
+	}
+	wg.Wait()
+
+	// All the logins shared one client, so verifying completes the most
+	// recent authorization and authenticates every later request.
+	status, body := doTestServerRequest(
+		t, server, "POST", "/customers/fake/auth", url.Values{"verifyCode": {"FAKE1"}},
+	)
+	require.Equal(t, http.StatusOK, status, body)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, body := doTestServerRequest(t, server, "GET", "/customers/fake/accounts", nil)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Contains(t, body, `"accountIdKey":"fakeAccountKey1"`)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestETradeServer_ConcurrentLogouts(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	server := newTestETradeServer(t, cfgFolder, false)
+
+	// Call the Method Under Test
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	statuses := map[int]int{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			status, _ := doTestServerRequest(t, server, "GET", "/customers/fake/accounts", nil)
+			mutex.Lock()
+			statuses[status]++
+			mutex.Unlock()
+		}()
+		go func() {
+			defer wg.Done()
+			status, _ := doTestServerRequest(t, server, "DELETE", "/customers/fake/auth", nil)
+			mutex.Lock()
+			statuses[status]++
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Only the first logout finds cached credentials to remove, and the
+	// requests that follow it fail authentication.
+	assert.Equal(t, 8, statuses[http.StatusOK]+statuses[http.StatusInternalServerError])
+	assert.GreaterOrEqual(t, statuses[http.StatusInternalServerError], 3)
+}
+
+func TestETradeServer_Tokens(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	logger := etradelibtest.CreateNullLogger()
+	tokenStore := &ServerTokenStore{}
+	readToken, _, err := tokenStore.CreateToken("", []string{"fake"}, ServerTokenCapabilityRead, time.Now())
+	require.Nil(t, err)
+	tradeToken, _, err := tokenStore.CreateToken("", []string{"fake"}, ServerTokenCapabilityTrade, time.Now())
+	require.Nil(t, err)
+	otherToken, _, err := tokenStore.CreateToken("", []string{"other"}, ServerTokenCapabilityTrade, time.Now())
+	require.Nil(t, err)
+	require.Nil(t, cfgFolder.SaveServerTokens(tokenStore, logger))
+	server := newTestETradeServer(t, cfgFolder, true)
+
+	tests := []struct {
+		name              string
+		testToken         string
+		testMethod        string
+		testPath          string
+		expectStatus      int
+		expectContains    string
+		expectNotContains string
+	}{
+		{
+			name:           "Rejects Request Without Token",
+			testToken:      "",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts",
+			expectStatus:   http.StatusUnauthorized,
+			expectContains: `"status":"error"`,
+		},
+		{
+			name:           "Serves OpenAPI Document Without Token",
+			testToken:      "",
+			testMethod:     "GET",
+			testPath:       "/openapi.json",
+			expectStatus:   http.StatusOK,
+			expectContains: `"openapi":"3.0.3"`,
+		},
+		{
+			name:           "Serves Docs Without Token",
+			testToken:      "",
+			testMethod:     "GET",
+			testPath:       "/docs",
+			expectStatus:   http.StatusOK,
+			expectContains: "<title>etrade-cli server API</title>",
+		},
+		{
+			name:           "Rejects Request With Invalid Token",
+			testToken:      readToken + "x",
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts",
+			expectStatus:   http.StatusUnauthorized,
+			expectContains: `"status":"error"`,
+		},
+		{
+			name:           "Allows Read With Read Token",
+			testToken:      readToken,
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts",
+			expectStatus:   http.StatusOK,
+			expectContains: `"accountIdKey":"fakeAccountKey1"`,
+		},
+		{
+			name:           "Rejects Authentication With Read Token",
+			testToken:      readToken,
+			testMethod:     "POST",
+			testPath:       "/customers/fake/auth",
+			expectStatus:   http.StatusForbidden,
+			expectContains: "allow trading",
+		},
+		{
+			name:           "Rejects Logout With Read Token",
+			testToken:      readToken,
+			testMethod:     "DELETE",
+			testPath:       "/customers/fake/auth",
+			expectStatus:   http.StatusForbidden,
+			expectContains: "allow trading",
+		},
+		{
+			name:           "Allows Authentication With Trade Token",
+			testToken:      tradeToken,
+			testMethod:     "POST",
+			testPath:       "/customers/fake/auth",
+			expectStatus:   http.StatusOK,
+			expectContains: `"status":"success"`,
+		},
+		{
+			name:           "Rejects Trading With Read Token",
+			testToken:      readToken,
+			testMethod:     "DELETE",
+			testPath:       "/customers/fake/accounts/11111111/orders/5002",
+			expectStatus:   http.StatusForbidden,
+			expectContains: "allow trading",
+		},
+		{
+			name:           "Allows Trading With Trade Token",
+			testToken:      tradeToken,
+			testMethod:     "DELETE",
+			testPath:       "/customers/fake/accounts/11111111/orders/5002",
+			expectStatus:   http.StatusOK,
+			expectContains: `"orderId":5002`,
+		},
+		{
+			name:           "Rejects Another Customer's Token",
+			testToken:      otherToken,
+			testMethod:     "GET",
+			testPath:       "/customers/fake/accounts",
+			expectStatus:   http.StatusForbidden,
+			expectContains: "allow customer fake",
+		},
+		{
+			name:              "Lists Only Token's Customers",
+			testToken:         otherToken,
+			testMethod:        "GET",
+			testPath:          "/customers",
+			expectStatus:      http.StatusOK,
+			expectContains:    `"customers":[]`,
+			expectNotContains: `"customerId":"fake"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				headers := http.Header{idempotencyKeyHeader: {tt.name}}
+				if tt.testToken != "" {
+					headers.Set("Authorization", "Bearer "+tt.testToken)
+				}
+				status, body := doTestServerRequestWithHeaders(t, server, headers, tt.testMethod, tt.testPath, nil)
+				assert.Equal(t, tt.expectStatus, status)
+				assert.Contains(t, body, tt.expectContains)
+				if tt.expectNotContains != "" {
+					assert.NotContains(t, body, tt.expectNotContains)
+				}
+			},
+		)
+	}
+
+	t.Run(
+		"Rejects Revoked Token", func(t *testing.T) {
+			require.Nil(t, tokenStore.RevokeToken(tokenStore.Authenticate(readToken).Id))
+			require.Nil(t, cfgFolder.SaveServerTokens(tokenStore, logger))
+			// Call the Method Under Test
+			status, _ := doTestServerRequestWithToken(t, server, readToken, "GET", "/customers/fake/accounts", nil)
+			assert.Equal(t, http.StatusUnauthorized, status)
+		},
+	)
+}
+
+func TestETradeServer_Orders(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	server := newTestETradeServer(t, cfgFolder, false)
+	ordersPath := "/customers/fake/accounts/11111111/orders"
+	orderForm := url.Values{
+		"symbol":     {"AAPL"},
+		"action":     {"buy"},
+		"quantity":   {"10"},
+		"priceType":  {"limit"},
+		"limitPrice": {"150"},
+	}
+
+	// Call the Method Under Test
+	status, body := doTestServerRequest(t, server, "POST", ordersPath+"/preview", orderForm)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "Idempotency-Key")
+
+	// Call the Method Under Test
+	status, body = doTestServerRequestWithHeaders(
+		t, server, http.Header{idempotencyKeyHeader: {"preview1"}}, "POST", ordersPath+"/preview", orderForm,
+	)
+	require.Equal(t, http.StatusOK, status, body)
+	preview, err := jsonmap.NewJsonMapFromIoReader(strings.NewReader(body))
+	require.Nil(t, err)
+	previewId, err := preview.GetIntAtPath(".previewIds[0].previewId")
+	require.Nil(t, err)
+	clientOrderId, err := preview.GetStringAtPath(".clientOrderId")
+	require.Nil(t, err)
+
+	placeForm := url.Values{"previewId": {strconv.FormatInt(previewId, 10)}, "clientOrderId": {clientOrderId}}
+	for key, values := range orderForm {
+		placeForm[key] = values
+	}
+	// Retries of the place request (including concurrent ones) share the
+	// first request's response, so the order is only placed once. (The fake
+	// server rejects a second placement of the preview.)
+	var wg sync.WaitGroup
+	statuses := make([]int, 4)
+	bodies := make([]string, 4)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Call the Method Under Test
+			statuses[i], bodies[i] = doTestServerRequestWithHeaders(
+				t, server, http.Header{idempotencyKeyHeader: {"place1"}}, "POST", ordersPath, placeForm,
+			)
+		}(i)
+	}
+	wg.Wait()
+	assert.Contains(t, bodies[0], `"orderIds":[{"orderId":`)
+	for i := range bodies {
+		assert.Equal(t, http.StatusOK, statuses[i])
+		assert.Equal(t, bodies[0], bodies[i])
+	}
+
+	// Call the Method Under Test
+	placeForm.Set("quantity", "20")
+	status, body = doTestServerRequestWithHeaders(
+		t, server, http.Header{idempotencyKeyHeader: {"place1"}}, "POST", ordersPath, placeForm,
+	)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, body, "different request")
+
+	// Call the Method Under Test
+	status, body = doTestServerRequestWithHeaders(
+		t, server, http.Header{idempotencyKeyHeader: {"place2"}}, "POST", ordersPath, placeForm,
+	)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, body, "400 Bad Request")
+}
+
+func TestETradeServer_StreamQuotes(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	server := newTestETradeServer(t, cfgFolder, false)
+
+	// Call the Method Under Test
+	status, body := doTestServerRequest(t, server, "GET", "/customers/fake/market/quote/stream", nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "missing symbol")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request, err := http.NewRequestWithContext(
+		ctx, "GET", server.URL+"/customers/fake/market/quote/stream?symbol=AAPL", nil,
+	)
+	require.Nil(t, err)
+	// Call the Method Under Test
+	response, err := http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	// The first event has the whole quote.
+	reader := bufio.NewReader(response.Body)
+	var event []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.Nil(t, err)
+		if line == "\n" {
+			break
+		}
+		event = append(event, strings.TrimSuffix(line, "\n"))
+	}
+	require.Len(t, event, 2)
+	assert.Equal(t, "event: quote", event[0])
+	assert.Contains(t, event[1], `"symbol":"AAPL"`)
+	assert.Contains(t, event[1], `"lastTrade":190`)
+}
+
+// newTestETradeServer starts a server for the configuration.
+func newTestETradeServer(t *testing.T, cfgFolder ConfigurationFolder, requireTokens bool) *httptest.Server {
+	logger := etradelibtest.CreateNullLogger()
+	cfgStore, err := cfgFolder.LoadCustomerConfiguration(logger)
+	require.Nil(t, err)
+	server := httptest.NewServer(
+		NewETradeServer("", logger, cfgFolder, cfgStore, nil, 0, requireTokens, 10*time.Millisecond, nil, nil).Handler,
+	)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// doTestServerRequest makes a request to the server (with the form, if it's
+// not nil) and returns the response's status and body.
+func doTestServerRequest(
+	t *testing.T, server *httptest.Server, method string, path string, form url.Values,
+) (int, string) {
+	return doTestServerRequestWithToken(t, server, "", method, path, form)
+}
+
+// doTestServerRequestWithToken makes a request with the API token (if it's
+// not empty).
+func doTestServerRequestWithToken(
+	t *testing.T, server *httptest.Server, token string, method string, path string, form url.Values,
+) (int, string) {
+	headers := http.Header{}
+	if token != "" {
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	return doTestServerRequestWithHeaders(t, server, headers, method, path, form)
+}
+
+// doTestServerRequestWithHeaders makes a request with the headers.
+func doTestServerRequestWithHeaders(
+	t *testing.T, server *httptest.Server, headers http.Header, method string, path string, form url.Values,
+) (int, string) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	request, err := http.NewRequest(method, server.URL+path, body)
+	require.Nil(t, err)
+	if form != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for name, values := range headers {
+		request.Header[name] = values
+	}
+	response, err := http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	responseBody, err := io.ReadAll(response.Body)
+	require.Nil(t, err)
+	return response.StatusCode, string(responseBody)
+}
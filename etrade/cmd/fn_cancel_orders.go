@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+// CancelOrders requests cancellation of each order in orderIds. A cancel that
+// E*TRADE rejects does not stop the remaining cancels; its result is reported
+// alongside the others so the caller can tell which cancels succeeded. An
+// authentication failure stops the cancels, since it would fail the rest of
+// them; the error is returned along with the results, in which it's recorded
+// against the order that failed and the orders that weren't sent.
+func CancelOrders(eTradeClient client.ETradeClient, accountId string, orderIds []int64) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	results := make(jsonmap.JsonSlice, 0, len(orderIds))
+	for i, orderId := range orderIds {
+		var result etradelib.ETradeCancelOrderResult
+		response, err := eTradeClient.CancelOrder(account.GetIdKey(), orderId)
+		if err == nil {
+			result, err = etradelib.CreateETradeCancelOrderResultFromResponse(response)
+		}
+		if err != nil && errors.Is(err, client.ErrETradeAuthFailed) {
+			for _, unsentOrderId := range orderIds[i:] {
+				results = append(
+					results, etradelib.CreateETradeCancelOrderResultFromError(unsentOrderId, err).AsJsonMap(),
+				)
+			}
+			return jsonmap.JsonMap{
+				"cancelResults": results,
+			}, err
+		}
+		if err != nil {
+			result = etradelib.CreateETradeCancelOrderResultFromError(orderId, err)
+		}
+		results = append(results, result.AsJsonMap())
+	}
+	return jsonmap.JsonMap{
+		"cancelResults": results,
+	}, nil
+}
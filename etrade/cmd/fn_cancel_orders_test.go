@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCancelOrders(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testCancelResponse := []byte(`
+{
+  "CancelOrderResponse": {
+    "orderId": 1234,
+    "cancelTime": 1234567890000
+  }
+}`)
+
+	type testFn func(mockClient *client.ETradeClientMock) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Cancels Orders",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("CancelOrder", "test key", int64(1234)).Return(testCancelResponse, nil)
+				mockClient.On("CancelOrder", "test key", int64(5678)).Return(
+					[]byte{}, errors.New("test error"),
+				)
+				mockClient.On("CancelOrder", "test key", int64(9012)).Return([]byte(`{"MISSING": {}}`), nil)
+				return CancelOrders(mockClient, "test id", []int64{1234, 5678, 9012})
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"cancelResults": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"status":     "success",
+						"orderId":    int64(1234),
+						"cancelTime": json.Number("1234567890000"),
+					},
+					jsonmap.JsonMap{
+						"status":  "error",
+						"orderId": int64(5678),
+						"error":   "test error",
+					},
+					jsonmap.JsonMap{
+						"status":  "error",
+						"orderId": int64(9012),
+						"error":   "cannot get value: cannot access .cancelOrderResponse because key cancelOrderResponse is not found in parent map",
+					},
+				},
+			},
+		},
+		{
+			name: "Fails With Bad Account ID",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				return CancelOrders(mockClient, "bad id", []int64{1234})
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails On Authentication Failure With Partial Results",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("CancelOrder", "test key", int64(1234)).Return(testCancelResponse, nil)
+				mockClient.On("CancelOrder", "test key", int64(5678)).Return(
+					[]byte{}, client.ErrETradeAuthFailed,
+				)
+				return CancelOrders(mockClient, "test id", []int64{1234, 5678, 9012})
+			},
+			expectErr: true,
+			expectValue: jsonmap.JsonMap{
+				"cancelResults": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"status":     "success",
+						"orderId":    int64(1234),
+						"cancelTime": json.Number("1234567890000"),
+					},
+					jsonmap.JsonMap{
+						"status":  "error",
+						"orderId": int64(5678),
+						"error":   client.ErrETradeAuthFailed.Error(),
+					},
+					jsonmap.JsonMap{
+						"status":  "error",
+						"orderId": int64(9012),
+						"error":   client.ErrETradeAuthFailed.Error(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := client.ETradeClientMock{}
+				// Call the Method Under Test
+				actualValue, err := tt.testFn(&mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"time"
+)
+
+// GetAccountPerformance returns an account's performance from the from date
+// to the to date, net of deposits and withdrawals. It's measured between the
+// account values and the cash flows in the account's transactions. The
+// account values are the total values of the account's snapshots in the store
+// (if it's not nil), the values given, which replace snapshot values on the
+// same dates, and, if the period ends today, the current value. If daily is
+// true, the reconstructed daily values are included.
+func GetAccountPerformance(
+	eTradeClient client.ETradeClient, store *SnapshotStore, accountId string, from time.Time, to time.Time,
+	values []AccountValue, daily bool, now time.Time,
+) (jsonmap.JsonMap, error) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return nil, err
+	}
+	today := getStartOfDay(now.In(location))
+	from, to = getStartOfDay(from.In(location)), getStartOfDay(to.In(location))
+	if to.After(today) {
+		to = today
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("the from date must be before the to date (%s)", to.Format(time.DateOnly))
+	}
+
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	var snapshotValues []AccountValue
+	if store != nil {
+		if snapshotValues, err = getSnapshotAccountValues(store, accountId, to, location); err != nil {
+			return nil, err
+		}
+	}
+	// The values are for calendar days, which are compared in ETrade's
+	// location. Later values replace earlier ones on the same day.
+	locatedValues := make([]AccountValue, 0, len(snapshotValues)+len(values)+1)
+	locatedValues = append(locatedValues, snapshotValues...)
+	for _, value := range values {
+		locatedValues = append(
+			locatedValues, AccountValue{
+				Date:  time.Date(value.Date.Year(), value.Date.Month(), value.Date.Day(), 0, 0, 0, 0, location),
+				Value: value.Value,
+			},
+		)
+	}
+	values = locatedValues
+	if to.Equal(today) {
+		currentValue, err := getCurrentAccountValue(eTradeClient, accountId)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, AccountValue{Date: today, Value: currentValue})
+	}
+
+	start, ok := getPerformanceStartValue(values, from)
+	if !ok {
+		return nil, fmt.Errorf(
+			"no account values are available (take snapshots of the account or give values from statements)",
+		)
+	}
+	// ETrade only keeps two years of transactions, which must include all
+	// the cash flows after the start value.
+	historyStart := time.Date(now.Year()-2, now.Month(), now.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+	if start.Date.Before(historyStart) {
+		return nil, fmt.Errorf(
+			"ETrade only keeps two years of transactions, which don't include the cash flows after %s",
+			start.Date.Format(time.DateOnly),
+		)
+	}
+	transactionList, err := listTransactionsForAccountIdKey(
+		eTradeClient, account.GetIdKey(), &start.Date, &to, constants.SortOrderAsc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var cashFlows []cashFlow
+	for _, transaction := range transactionList.GetAllTransactions() {
+		transactionModel, err := transaction.AsModel()
+		if err != nil {
+			return nil, err
+		}
+		if flow, ok := getCashFlow(transactionModel, location); ok {
+			cashFlows = append(cashFlows, flow)
+		}
+	}
+
+	performance, err := calculatePerformance(values, cashFlows, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return getPerformanceJsonMap(performance, daily), nil
+}
+
+// getSnapshotAccountValues returns the total values of the account's
+// snapshots taken by the end of the to date, as the values at the end of the
+// days that they were taken. The last snapshot taken on a day is its value.
+func getSnapshotAccountValues(
+	store *SnapshotStore, accountId string, to time.Time, location *time.Location,
+) ([]AccountValue, error) {
+	endOfDay := to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	snapshots, err := store.GetSnapshots(accountId, nil, &endOfDay)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]AccountValue, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		value := getSnapshotTotalAccountValue(snapshot)
+		if value <= 0 {
+			continue
+		}
+		values = append(values, AccountValue{Date: getStartOfDay(snapshot.TakenAt.In(location)), Value: value})
+	}
+	return values, nil
+}
+
+// getCurrentAccountValue returns the account's real-time total value from
+// its balances or, if the balances don't have it, the market value and cash
+// balance from its portfolio's totals.
+func getCurrentAccountValue(eTradeClient client.ETradeClient, accountId string) (float64, error) {
+	balancesMap, err := GetAccountBalances(eTradeClient, accountId, true)
+	if err != nil {
+		return 0, err
+	}
+	var balances model.Balances
+	if err = balancesMap.Decode(&balances); err != nil {
+		return 0, err
+	}
+	if balances.Computed != nil && balances.Computed.RealTimeValues != nil &&
+		balances.Computed.RealTimeValues.TotalAccountValue > 0 {
+		return balances.Computed.RealTimeValues.TotalAccountValue, nil
+	}
+
+	portfolio, err := ViewPortfolio(
+		eTradeClient, accountId, constants.PortfolioSortByNil, constants.SortOrderNil, constants.MarketSessionNil,
+		true, constants.PortfolioViewQuick, false,
+	)
+	if err != nil {
+		return 0, err
+	}
+	var portfolioTotals struct {
+		Totals struct {
+			TotalMarketValue float64 `json:"totalMarketValue"`
+			CashBalance      float64 `json:"cashBalance"`
+		} `json:"totals"`
+	}
+	if err = portfolio.Decode(&portfolioTotals); err != nil {
+		return 0, err
+	}
+	return portfolioTotals.Totals.TotalMarketValue + portfolioTotals.Totals.CashBalance, nil
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestGetAccountPerformance(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testTransactionsResponse := []byte(`
+{
+  "TransactionListResponse": {
+    "Transaction": [
+      {
+        "transactionId": "101",
+        "transactionDate": 1736528400000,
+        "amount": 100,
+        "description": "ACH DEPOSIT",
+        "transactionType": "Online Transfer"
+      },
+      {
+        "transactionId": "102",
+        "transactionDate": 1736787600000,
+        "amount": -1000,
+        "transactionType": "Bought",
+        "Brokerage": {
+          "Product": {"symbol": "AAPL", "securityType": "EQ"},
+          "quantity": 10,
+          "price": 100
+        }
+      }
+    ]
+  }
+}`)
+	testBalancesResponse := []byte(`
+{
+  "BalanceResponse": {
+    "accountId": "test id",
+    "Computed": {
+      "RealTimeValues": {
+        "totalAccountValue": 1210
+      }
+    }
+  }
+}`)
+	testBalancesWithoutValueResponse := []byte(`
+{
+  "BalanceResponse": {
+    "accountId": "test id"
+  }
+}`)
+	testPortfolioResponse := []byte(`
+{
+  "PortfolioResponse": {
+    "Totals": {
+      "totalMarketValue": 1000,
+      "cashBalance": 210
+    },
+    "AccountPortfolio": [
+      {
+        "Position": [
+          {
+            "positionId": 1234,
+            "Product": {"symbol": "AAPL", "securityType": "EQ"},
+            "quantity": 10
+          }
+        ]
+      }
+    ]
+  }
+}`)
+	testNow := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	testValues := []AccountValue{{Date: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), Value: 1000}}
+	expectPerformance := jsonmap.JsonMap{
+		"performance": jsonmap.JsonMap{
+			"startDate": "2024-12-31", "endDate": "2025-03-15", "startValue": 1000.0, "endValue": 1210.0,
+			"deposits": 100.0, "withdrawals": 0.0, "gain": 110.0, "timeWeightedReturnPct": 10.12, "xirrPct": 60.95,
+			"mtdReturnPct": 1.97, "qtdReturnPct": 10.12, "ytdReturnPct": 10.12,
+		},
+		"cashFlows": jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				"transactionId": "101", "date": "2025-01-10", "transactionType": "Online Transfer",
+				"description": "ACH DEPOSIT", "amount": 100.0,
+			},
+		},
+	}
+
+	testSnapshot := func(value float64) *Snapshot {
+		return &Snapshot{
+			AccountId: "test id", TakenAt: time.Date(2024, 12, 31, 21, 30, 0, 0, time.UTC),
+			Balances: jsonmap.JsonMap{
+				"computed": jsonmap.JsonMap{"realTimeValues": jsonmap.JsonMap{"totalAccountValue": value}},
+			},
+			Portfolio: jsonmap.JsonMap{},
+		}
+	}
+
+	type testFn func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Gets Performance To The Current Value",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("GetAccountBalances", "test key", true).Return(testBalancesResponse, nil)
+				mockClient.On(
+					"ListTransactions", "test key", mock.Anything, mock.Anything, constants.SortOrderAsc, "", 50,
+				).Return(testTransactionsResponse, nil)
+
+				return GetAccountPerformance(
+					mockClient, nil, "test id", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), testNow, testValues,
+					false, testNow,
+				)
+			},
+			expectErr:   false,
+			expectValue: expectPerformance,
+		},
+		{
+			name: "Gets Current Value From Portfolio Without Balance Value",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("GetAccountBalances", "test key", true).Return(testBalancesWithoutValueResponse, nil)
+				mockClient.On(
+					"ViewPortfolio", "test key", 65535, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+					constants.MarketSessionNil, true, true, constants.PortfolioViewQuick,
+				).Return(testPortfolioResponse, nil)
+				mockClient.On(
+					"ListTransactions", "test key", mock.Anything, mock.Anything, constants.SortOrderAsc, "", 50,
+				).Return(testTransactionsResponse, nil)
+
+				return GetAccountPerformance(
+					mockClient, nil, "test id", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), testNow, testValues,
+					false, testNow,
+				)
+			},
+			expectErr:   false,
+			expectValue: expectPerformance,
+		},
+		{
+			name: "Gets Start Value From Snapshots",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("GetAccountBalances", "test key", true).Return(testBalancesResponse, nil)
+				mockClient.On(
+					"ListTransactions", "test key", mock.Anything, mock.Anything, constants.SortOrderAsc, "", 50,
+				).Return(testTransactionsResponse, nil)
+				if err := store.AddSnapshot(testSnapshot(1000)); err != nil {
+					return nil, err
+				}
+
+				return GetAccountPerformance(
+					mockClient, store, "test id", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), testNow, nil, false,
+					testNow,
+				)
+			},
+			expectErr:   false,
+			expectValue: expectPerformance,
+		},
+		{
+			name: "Given Values Replace Snapshot Values",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("GetAccountBalances", "test key", true).Return(testBalancesResponse, nil)
+				mockClient.On(
+					"ListTransactions", "test key", mock.Anything, mock.Anything, constants.SortOrderAsc, "", 50,
+				).Return(testTransactionsResponse, nil)
+				if err := store.AddSnapshot(testSnapshot(900)); err != nil {
+					return nil, err
+				}
+
+				return GetAccountPerformance(
+					mockClient, store, "test id", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), testNow, testValues,
+					false, testNow,
+				)
+			},
+			expectErr:   false,
+			expectValue: expectPerformance,
+		},
+		{
+			name: "Fails With From Date After To Date",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				return GetAccountPerformance(
+					mockClient, nil, "test id", testNow, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), testValues,
+					false, testNow,
+				)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails With Start Value Before Transaction History",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("GetAccountBalances", "test key", true).Return(testBalancesResponse, nil)
+
+				return GetAccountPerformance(
+					mockClient, nil, "test id", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), testNow,
+					[]AccountValue{{Date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Value: 1000}}, false, testNow,
+				)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				mockClient := new(client.ETradeClientMock)
+				store := NewSnapshotStore(t.TempDir(), etradelibtest.CreateNullLogger())
+				actualValue, err := tt.testFn(mockClient, store)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
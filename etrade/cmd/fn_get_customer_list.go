@@ -8,7 +8,7 @@ func GetCustomerList(cfgStore *CustomerConfigurationStore) jsonmap.JsonMap {
 		customerMap := jsonmap.JsonMap{}
 		customerMap.SetString("customerId", customerId)
 		customerMap.SetString("customerName", customerConfig.CustomerName)
-		customerMap.SetBool("productionAccess", customerConfig.CustomerProduction)
+		customerMap.SetBool("productionAccess", customerConfig.GetEndpointProfile().IsProduction())
 		customerSlice = append(customerSlice, customerMap)
 	}
 	return jsonmap.JsonMap{
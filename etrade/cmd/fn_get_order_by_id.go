@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+)
+
+// GetOpenOrderById finds an open order in the account with the provided
+// account ID key.
+func GetOpenOrderById(eTradeClient client.ETradeClient, accountIdKey string, orderId int64) (
+	etradelib.ETradeOrder, error,
+) {
+	listOpenOrders := func(marker string) ([]byte, error) {
+		return eTradeClient.ListOrders(
+			accountIdKey, marker, constants.OrdersMaxCount, constants.OrderStatusOpen, nil, nil, nil,
+			constants.OrderSecurityTypeNil, constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+		)
+	}
+	response, err := listOpenOrders("")
+	if err != nil {
+		return nil, err
+	}
+	orderList, err := etradelib.CreateETradeOrderListFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	for orderList.GetOrderById(orderId) == nil && orderList.NextPage() != "" {
+		response, err = listOpenOrders(orderList.NextPage())
+		if err != nil {
+			return nil, err
+		}
+		err = orderList.AddPageFromResponse(response)
+		if err != nil {
+			return nil, err
+		}
+	}
+	order := orderList.GetOrderById(orderId)
+	if order == nil {
+		return nil, fmt.Errorf("open order with id %d not found", orderId)
+	}
+	return order, nil
+}
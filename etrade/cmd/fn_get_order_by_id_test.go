@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestGetOpenOrderById(t *testing.T) {
+	onListOpenOrders := func(mockClient *client.ETradeClientMock, marker string) *mock.Call {
+		return mockClient.On(
+			"ListOrders", "test key", marker, 100, constants.OrderStatusOpen, (*time.Time)(nil), (*time.Time)(nil),
+			[]string(nil), constants.OrderSecurityTypeNil, constants.OrderTransactionTypeNil,
+			constants.MarketSessionNil,
+		)
+	}
+	testOrdersResponse1 := []byte(`
+{
+  "OrdersResponse": {
+    "marker": "test marker",
+    "Order": [
+      {
+        "orderId": 1234
+      }
+    ]
+  }
+}`)
+	testOrdersResponse2 := []byte(`
+{
+  "OrdersResponse": {
+    "Order": [
+      {
+        "orderId": 5678
+      }
+    ]
+  }
+}`)
+
+	type testFn func(mockClient *client.ETradeClientMock) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Gets Order From First Page",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				onListOpenOrders(mockClient, "").Return(testOrdersResponse1, nil)
+				return GetOpenOrderById(mockClient, "test key", 1234)
+			},
+			expectErr: false,
+			expectValue: mustCreateETradeOrder(
+				jsonmap.JsonMap{
+					"orderId": json.Number("1234"),
+				},
+			),
+		},
+		{
+			name: "Gets Order From Later Page",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				onListOpenOrders(mockClient, "").Return(testOrdersResponse1, nil)
+				onListOpenOrders(mockClient, "test marker").Return(testOrdersResponse2, nil)
+				return GetOpenOrderById(mockClient, "test key", 5678)
+			},
+			expectErr: false,
+			expectValue: mustCreateETradeOrder(
+				jsonmap.JsonMap{
+					"orderId": json.Number("5678"),
+				},
+			),
+		},
+		{
+			name: "Fails If Order Not Found",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				onListOpenOrders(mockClient, "").Return(testOrdersResponse1, nil)
+				onListOpenOrders(mockClient, "test marker").Return(testOrdersResponse2, nil)
+				return GetOpenOrderById(mockClient, "test key", 9012)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails On ListOrders Error",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				onListOpenOrders(mockClient, "").Return([]byte{}, errors.New("test error"))
+				return GetOpenOrderById(mockClient, "test key", 1234)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := client.ETradeClientMock{}
+				// Call the Method Under Test
+				actualValue, err := tt.testFn(&mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+					assert.Nil(t, actualValue)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func mustCreateETradeOrder(orderMap jsonmap.JsonMap) etradelib.ETradeOrder {
+	order, err := etradelib.CreateETradeOrder(orderMap)
+	if err != nil {
+		panic(err)
+	}
+	return order
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"strings"
+	"time"
+)
+
+// portfolioDiffNow selects the account's current portfolio for a portfolio
+// diff.
+const portfolioDiffNow = "now"
+
+// GetPortfolioDiff compares two of an account's portfolios by security and
+// lot. Each portfolio is a stored snapshot's ID, a date (MMDDYYYY) for the
+// latest snapshot taken by the end of that day, or "now" for the account's
+// current portfolio.
+func GetPortfolioDiff(
+	eTradeClient client.ETradeClient, store *SnapshotStore, accountId string, from string, to string,
+	now time.Time,
+) (jsonmap.JsonMap, error) {
+	fromPortfolio, fromSnapshotId, fromTakenAt, err := getPortfolioDiffPortfolio(
+		eTradeClient, store, accountId, from, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	toPortfolio, toSnapshotId, toTakenAt, err := getPortfolioDiffPortfolio(eTradeClient, store, accountId, to, now)
+	if err != nil {
+		return nil, err
+	}
+	if toTakenAt.Before(fromTakenAt) {
+		return nil, fmt.Errorf(
+			"the from portfolio (%s) must be before the to portfolio (%s)", fromTakenAt.Format(time.RFC3339),
+			toTakenAt.Format(time.RFC3339),
+		)
+	}
+	fromPositions, err := getPortfolioDiffPositions(fromPortfolio)
+	if err != nil {
+		return nil, err
+	}
+	toPositions, err := getPortfolioDiffPositions(toPortfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	diffMap := getPortfolioDiffJsonMap(calculatePortfolioDiff(fromPositions, toPositions))
+	diffMap["comparison"] = jsonmap.JsonMap{
+		"accountId":      accountId,
+		"fromSnapshotId": fromSnapshotId,
+		"fromTime":       fromTakenAt.Format(time.RFC3339),
+		"toSnapshotId":   toSnapshotId,
+		"toTime":         toTakenAt.Format(time.RFC3339),
+	}
+	return diffMap, nil
+}
+
+// getPortfolioDiffPortfolio returns the portfolio selected by the value, the
+// ID of its snapshot (empty for the current portfolio), and when it was
+// taken.
+func getPortfolioDiffPortfolio(
+	eTradeClient client.ETradeClient, store *SnapshotStore, accountId string, value string, now time.Time,
+) (jsonmap.JsonMap, string, time.Time, error) {
+	if strings.EqualFold(value, portfolioDiffNow) {
+		portfolio, err := ViewPortfolio(
+			eTradeClient, accountId, constants.PortfolioSortByNil, constants.SortOrderNil,
+			constants.MarketSessionNil, true, constants.PortfolioViewQuick, true,
+		)
+		return portfolio, "", now, err
+	}
+
+	var snapshot *Snapshot
+	var err error
+	if _, parseErr := time.Parse(snapshotIdLayout, value); parseErr == nil {
+		snapshot, err = store.GetSnapshot(accountId, value)
+	} else {
+		location, locationErr := time.LoadLocation("America/New_York")
+		if locationErr != nil {
+			return nil, "", time.Time{}, locationErr
+		}
+		date, parseErr := time.ParseInLocation("01022006", value, location)
+		if parseErr != nil {
+			return nil, "", time.Time{}, fmt.Errorf(
+				"invalid portfolio %s (must be a snapshot ID, a date in format MMDDYYYY, or %s)", value,
+				portfolioDiffNow,
+			)
+		}
+		// The date includes the whole day.
+		endOfDay := date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		snapshot, err = getSnapshotByIdOrTime(store, accountId, "", &endOfDay)
+	}
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return snapshot.Portfolio, snapshot.Id, snapshot.TakenAt, nil
+}
+
+func getPortfolioDiffPositions(portfolio jsonmap.JsonMap) ([]model.Position, error) {
+	var positionList struct {
+		Positions []model.Position `json:"positions"`
+	}
+	if err := portfolio.Decode(&positionList); err != nil {
+		return nil, err
+	}
+	return positionList.Positions, nil
+}
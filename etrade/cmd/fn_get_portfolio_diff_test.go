@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGetPortfolioDiff(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testPortfolioResponse := []byte(`
+{
+  "PortfolioResponse": {
+    "Totals": {
+      "totalMarketValue": 1600,
+      "cashBalance": 0
+    },
+    "AccountPortfolio": [
+      {
+        "Position": [
+          {
+            "positionId": 1234,
+            "Product": {"symbol": "AAPL", "securityType": "EQ"},
+            "quantity": 10,
+            "marketValue": 1600,
+            "totalGain": 600
+          }
+        ]
+      }
+    ]
+  }
+}`)
+	testLotsResponse := []byte(`
+{
+  "PositionLotsResponse": {
+    "PositionLot": [
+      {
+        "positionId": 1234,
+        "positionLotId": 1,
+        "acquiredDate": 1704067200000,
+        "remainingQty": 10,
+        "marketValue": 1600,
+        "totalGain": 600
+      }
+    ]
+  }
+}`)
+	testSnapshotPortfolio, err := jsonmap.NewJsonMapFromJsonString(`
+{
+  "positions": [
+    {
+      "positionId": 1234,
+      "product": {"symbol": "AAPL", "securityType": "EQ"},
+      "quantity": 10,
+      "marketValue": 1500,
+      "totalGain": 500,
+      "lots": [
+        {
+          "positionId": 1234,
+          "positionLotId": 1,
+          "acquiredDate": 1704067200000,
+          "remainingQty": 10,
+          "marketValue": 1500,
+          "totalGain": 500
+        }
+      ]
+    }
+  ]
+}`)
+	require.Nil(t, err)
+	location, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+	testSnapshotTime := time.Date(2025, 1, 31, 16, 0, 0, 0, location)
+	testNow := time.Date(2025, 2, 7, 16, 0, 0, 0, location)
+	expectPositions := jsonmap.JsonSlice{
+		jsonmap.JsonMap{
+			"symbol": "AAPL", "securityType": "EQ", "positionType": "LONG", "symbolDescription": "",
+			"change": "unchanged", "fromQuantity": 10.0, "toQuantity": 10.0, "quantityChange": 0.0,
+			"fromMarketValue": 1500.0, "toMarketValue": 1600.0, "marketValueChange": 100.0,
+			"pnlContribution": 100.0,
+			"lots": jsonmap.JsonSlice{
+				jsonmap.JsonMap{
+					"positionLotId": int64(1), "acquiredDate": int64(1704067200000), "change": "unchanged",
+					"fromQuantity": 10.0, "toQuantity": 10.0, "quantityChange": 0.0, "fromMarketValue": 1500.0,
+					"toMarketValue": 1600.0, "marketValueChange": 100.0, "pnlContribution": 100.0,
+				},
+			},
+		},
+	}
+	expectTotals := jsonmap.JsonMap{
+		"fromMarketValue": 1500.0, "toMarketValue": 1600.0, "marketValueChange": 100.0, "pnlContribution": 100.0,
+		"addedPositions": 0, "removedPositions": 0, "changedPositions": 0,
+	}
+
+	type testFn func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Compares Snapshot To Current Portfolio",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On(
+					"ViewPortfolio", "test key", 65535, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+					constants.MarketSessionNil, true, true, constants.PortfolioViewQuick,
+				).Return(testPortfolioResponse, nil)
+				mockClient.On("ListPositionLotsDetails", "test key", int64(1234)).Return(testLotsResponse, nil)
+
+				return GetPortfolioDiff(mockClient, store, "test id", "20250131T210000Z", "now", testNow)
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"comparison": jsonmap.JsonMap{
+					"accountId": "test id", "fromSnapshotId": "20250131T210000Z",
+					"fromTime": "2025-01-31T16:00:00-05:00", "toSnapshotId": "", "toTime": "2025-02-07T16:00:00-05:00",
+				},
+				"positions": expectPositions,
+				"totals":    expectTotals,
+			},
+		},
+		{
+			name: "Selects Latest Snapshot By Date",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				return GetPortfolioDiff(mockClient, store, "test id", "01312025", "20250131T210000Z", testNow)
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"comparison": jsonmap.JsonMap{
+					"accountId": "test id", "fromSnapshotId": "20250131T210000Z",
+					"fromTime": "2025-01-31T16:00:00-05:00", "toSnapshotId": "20250131T210000Z",
+					"toTime": "2025-01-31T16:00:00-05:00",
+				},
+				"positions": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"symbol": "AAPL", "securityType": "EQ", "positionType": "LONG", "symbolDescription": "",
+						"change": "unchanged", "fromQuantity": 10.0, "toQuantity": 10.0, "quantityChange": 0.0,
+						"fromMarketValue": 1500.0, "toMarketValue": 1500.0, "marketValueChange": 0.0,
+						"pnlContribution": 0.0,
+						"lots": jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"positionLotId": int64(1), "acquiredDate": int64(1704067200000), "change": "unchanged",
+								"fromQuantity": 10.0, "toQuantity": 10.0, "quantityChange": 0.0,
+								"fromMarketValue": 1500.0, "toMarketValue": 1500.0, "marketValueChange": 0.0,
+								"pnlContribution": 0.0,
+							},
+						},
+					},
+				},
+				"totals": jsonmap.JsonMap{
+					"fromMarketValue": 1500.0, "toMarketValue": 1500.0, "marketValueChange": 0.0,
+					"pnlContribution": 0.0, "addedPositions": 0, "removedPositions": 0, "changedPositions": 0,
+				},
+			},
+		},
+		{
+			name: "Fails Without Snapshot By Date",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				return GetPortfolioDiff(mockClient, store, "test id", "01302025", "now", testNow)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails With Invalid Portfolio",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				return GetPortfolioDiff(mockClient, store, "test id", "last week", "now", testNow)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails With To Portfolio Before From Portfolio",
+			testFn: func(mockClient *client.ETradeClientMock, store *SnapshotStore) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On(
+					"ViewPortfolio", "test key", 65535, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+					constants.MarketSessionNil, true, true, constants.PortfolioViewQuick,
+				).Return(testPortfolioResponse, nil)
+				mockClient.On("ListPositionLotsDetails", "test key", int64(1234)).Return(testLotsResponse, nil)
+
+				return GetPortfolioDiff(mockClient, store, "test id", "now", "20250131T210000Z", testNow)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				store := NewSnapshotStore(t.TempDir(), etradelibtest.CreateNullLogger())
+				require.Nil(
+					t, store.AddSnapshot(
+						&Snapshot{
+							AccountId: "test id", TakenAt: testSnapshotTime, Balances: jsonmap.JsonMap{},
+							Portfolio: testSnapshotPortfolio,
+						},
+					),
+				)
+
+				// Call the Method Under Test
+				mockClient := new(client.ETradeClientMock)
+				actualValue, err := tt.testFn(mockClient, store)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"math"
+	"strconv"
+	"time"
+)
+
+// GetRealizedGains returns the realized gain or loss on each lot of each
+// sale in the account in the year, with the year's totals. Lots bought
+// within the account's transaction history come from its purchases; lots
+// bought before it come from the lots of the account's current positions.
+// Shares that can't be matched to either (e.g. shares bought before the
+// transaction history and no longer held) are reported without a cost basis.
+// Short positions (short sales and written options) are reported when
+// they're closed; it fails if a closed short position can't be matched.
+// Each trade's details are fetched for its fees and commissions, which are
+// included in its cost or proceeds.
+func GetRealizedGains(
+	eTradeClient client.ETradeClient, accountId string, year int, method LotMatchingMethod,
+	specificLots []SpecificLot, now time.Time,
+) (jsonmap.JsonMap, error) {
+	trades, openingLots, err := getGainsTradesAndLots(eTradeClient, accountId, year, now)
+	if err != nil {
+		return nil, err
+	}
+	gains, err := calculateRealizedGains(trades, openingLots, method, specificLots)
+	if err != nil {
+		return nil, err
+	}
+	return getRealizedGainsJsonMap(gains, year), nil
+}
+
+// getGainsTradesAndLots gets the purchases and sales that the year's gains
+// depend on, and the lots bought before them.
+func getGainsTradesAndLots(
+	eTradeClient client.ETradeClient, accountId string, year int, now time.Time,
+) ([]gainsTrade, []gainsLot, error) {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return nil, nil, err
+	}
+	now = now.In(location)
+	if year > now.Year() {
+		return nil, nil, fmt.Errorf("year %d hasn't started", year)
+	}
+	// ETrade only keeps two years of transactions, which must include all
+	// the year's sales.
+	historyStart := time.Date(now.Year()-2, now.Month(), now.Day(), 0, 0, 0, 0, location).AddDate(0, 0, 1)
+	if time.Date(year, time.January, 1, 0, 0, 0, 0, location).Before(historyStart) {
+		return nil, nil, fmt.Errorf("ETrade only keeps two years of transactions, which don't include all of %d", year)
+	}
+	// Purchases in the previous year may be sold in this one, and purchases
+	// after the year may make its losses wash sales.
+	startDate := time.Date(year-1, time.January, 1, 0, 0, 0, 0, location)
+	if startDate.Before(historyStart) {
+		startDate = historyStart
+	}
+	endDate := time.Date(year, time.December, 31, 0, 0, 0, 0, location).AddDate(0, 0, washSaleDays)
+	if endDate.After(now) {
+		endDate = now
+	}
+
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactionList, err := listTransactionsForAccountIdKey(
+		eTradeClient, account.GetIdKey(), &startDate, &endDate, constants.SortOrderAsc,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var trades []gainsTrade
+	for _, transaction := range transactionList.GetAllTransactions() {
+		transactionModel, err := transaction.AsModel()
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := getGainsTrade(transactionModel, location); !ok {
+			continue
+		}
+		detailsModel, err := getTransactionDetailsModel(
+			eTradeClient, account.GetIdKey(), transactionModel.TransactionId.String(),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		transactionModel = applyGainsTransactionDetails(transactionModel, detailsModel)
+		if trade, ok := getGainsTrade(transactionModel, location); ok {
+			trades = append(trades, trade)
+		}
+	}
+
+	portfolio, err := ViewPortfolio(
+		eTradeClient, accountId, constants.PortfolioSortByNil, constants.SortOrderNil, constants.MarketSessionNil,
+		false, constants.PortfolioViewQuick, true,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var portfolioModel struct {
+		Positions []model.Position `json:"positions"`
+	}
+	if err = portfolio.Decode(&portfolioModel); err != nil {
+		return nil, nil, err
+	}
+	return trades, getOpeningLots(portfolioModel.Positions, startDate, location), nil
+}
+
+// getTransactionDetailsModel gets a transaction's details, which have the
+// trade's fees and commissions.
+func getTransactionDetailsModel(
+	eTradeClient client.ETradeClient, accountIdKey string, transactionId string,
+) (*model.Transaction, error) {
+	response, err := eTradeClient.ListTransactionDetails(accountIdKey, transactionId)
+	if err != nil {
+		return nil, err
+	}
+	transactionDetails, err := etradelib.CreateETradeTransactionDetailsFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	return transactionDetails.AsModel()
+}
+
+// getOpeningLots returns the positions' lots that were bought (or, for short
+// positions, sold short) before the start date, whose trades aren't in the
+// transaction history. Since the lots' trades before the start date aren't
+// known, their original quantities are used.
+func getOpeningLots(positions []model.Position, startDate time.Time, location *time.Location) []gainsLot {
+	var lots []gainsLot
+	for _, position := range positions {
+		if position.Product == nil || position.Quantity == 0 {
+			continue
+		}
+		isShort := position.Quantity < 0
+		for _, lot := range position.Lots {
+			acquired := time.UnixMilli(lot.AcquiredDate).In(location)
+			if !acquired.Before(startDate) {
+				continue
+			}
+			quantity := math.Abs(lot.OriginalQty)
+			if quantity == 0 {
+				quantity = math.Abs(lot.RemainingQty)
+			}
+			if quantity == 0 {
+				continue
+			}
+			pricePerShare := lot.Price
+			if lot.RemainingQty != 0 && lot.TotalCost != 0 {
+				// The total cost includes commissions, fees, and the option
+				// multiplier.
+				pricePerShare = math.Abs(lot.TotalCost / lot.RemainingQty)
+			}
+			openingLot := gainsLot{
+				id:       "lot-" + strconv.FormatInt(lot.PositionLotId, 10),
+				security: getGainsSecurity(position.Product),
+				isShort:  isShort,
+				acquired: acquired,
+				quantity: quantity,
+			}
+			if isShort {
+				openingLot.proceedsPerShare = pricePerShare
+			} else {
+				openingLot.costPerShare = pricePerShare
+			}
+			lots = append(lots, openingLot)
+		}
+	}
+	return lots
+}
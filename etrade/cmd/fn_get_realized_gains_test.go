@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestGetRealizedGains(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testTransactionsResponse := []byte(`
+{
+  "TransactionListResponse": {
+    "Transaction": [
+      {
+        "transactionId": "101",
+        "transactionDate": 1704214800000,
+        "amount": -1000,
+        "transactionType": "Bought",
+        "Brokerage": {
+          "Product": {"symbol": "AAPL", "securityType": "EQ"},
+          "quantity": 10,
+          "price": 100
+        }
+      },
+      {
+        "transactionId": "102",
+        "transactionDate": 1706806800000,
+        "amount": 12,
+        "transactionType": "Dividend",
+        "Brokerage": {
+          "Product": {"symbol": "AAPL", "securityType": "EQ"}
+        }
+      },
+      {
+        "transactionId": "103",
+        "transactionDate": 1709312400000,
+        "amount": 1800,
+        "transactionType": "Sold",
+        "Brokerage": {
+          "Product": {"symbol": "AAPL", "securityType": "EQ"},
+          "quantity": -15,
+          "price": 120
+        }
+      }
+    ]
+  }
+}`)
+	// The purchase's amount doesn't include its commission, and the sale's
+	// amount is net of more fees than the list has.
+	testPurchaseDetailsResponse := []byte(`
+{
+  "TransactionDetailsResponse": {
+    "transactionId": 101,
+    "amount": -1000,
+    "Brokerage": {
+      "quantity": 10,
+      "price": 100,
+      "commission": 5
+    }
+  }
+}`)
+	testSaleDetailsResponse := []byte(`
+{
+  "TransactionDetailsResponse": {
+    "transactionId": 103,
+    "amount": 1794,
+    "Brokerage": {
+      "quantity": -15,
+      "price": 120,
+      "fee": 1,
+      "commission": 5
+    }
+  }
+}`)
+	testPortfolioResponse := []byte(`
+{
+  "PortfolioResponse": {
+    "AccountPortfolio": [
+      {
+        "Position": [
+          {
+            "positionId": 1234,
+            "Product": {"symbol": "AAPL", "securityType": "EQ"},
+            "quantity": 10
+          }
+        ]
+      }
+    ]
+  }
+}`)
+	testLotsResponse := []byte(`
+{
+  "PositionLotsResponse": {
+    "PositionLot": [
+      {
+        "positionLotId": 77,
+        "acquiredDate": 1620057600000,
+        "price": 50,
+        "totalCost": 250,
+        "originalQty": 10,
+        "remainingQty": 5
+      },
+      {
+        "positionLotId": 78,
+        "acquiredDate": 1704214800000,
+        "price": 100,
+        "totalCost": 500,
+        "originalQty": 10,
+        "remainingQty": 5
+      }
+    ]
+  }
+}`)
+
+	type testFn func(mockClient *client.ETradeClientMock) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Gets Gains From Transactions And Lots",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On(
+					"ListTransactions", "test key", mock.Anything, mock.Anything, constants.SortOrderAsc, "", 50,
+				).Return(testTransactionsResponse, nil)
+				mockClient.On("ListTransactionDetails", "test key", "101").Return(testPurchaseDetailsResponse, nil)
+				mockClient.On("ListTransactionDetails", "test key", "103").Return(testSaleDetailsResponse, nil)
+				mockClient.On(
+					"ViewPortfolio", "test key", 65535, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+					constants.MarketSessionNil, false, true, constants.PortfolioViewQuick,
+				).Return(testPortfolioResponse, nil)
+				mockClient.On("ListPositionLotsDetails", "test key", int64(1234)).Return(testLotsResponse, nil)
+
+				return GetRealizedGains(
+					mockClient, "test id", 2024, LotMatchingFifo, nil, time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC),
+				)
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"gains": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"saleTransactionId": "103", "symbol": "AAPL", "lotId": "lot-77", "quantity": 10.0,
+						"dateAcquired": "2021-05-03", "dateSold": "2024-03-01", "proceeds": 1196.0,
+						"costBasis": 500.0, "gain": 696.0, "term": "long", "washSale": false,
+						"washSaleLossDisallowed": 0.0, "form8949Box": "D",
+					},
+					jsonmap.JsonMap{
+						"saleTransactionId": "103", "symbol": "AAPL", "lotId": "101", "quantity": 5.0,
+						"dateAcquired": "2024-01-02", "dateSold": "2024-03-01", "proceeds": 598.0,
+						"costBasis": 502.5, "gain": 95.5, "term": "short", "washSale": false,
+						"washSaleLossDisallowed": 0.0, "form8949Box": "A",
+					},
+				},
+				"totals": jsonmap.JsonMap{
+					"proceeds": 1794.0, "costBasis": 1002.5, "washSaleLossDisallowed": 0.0, "shortTermGain": 95.5,
+					"longTermGain": 696.0, "totalGain": 791.5,
+				},
+			},
+		},
+		{
+			name: "Fails With Future Year",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				return GetRealizedGains(
+					mockClient, "test id", 2026, LotMatchingFifo, nil, time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC),
+				)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails With Year Before Transaction History",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				return GetRealizedGains(
+					mockClient, "test id", 2022, LotMatchingFifo, nil, time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC),
+				)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				mockClient := new(client.ETradeClientMock)
+				actualValue, err := tt.testFn(mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -13,17 +13,33 @@ func ListOrders(
 	toDate *time.Time, symbols []string, securityType constants.OrderSecurityType,
 	transactionType constants.OrderTransactionType, marketSession constants.MarketSession,
 ) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	orderList, err := listOrdersForAccountIdKey(
+		eTradeClient, account.GetIdKey(), status, fromDate, toDate, symbols, securityType, transactionType,
+		marketSession,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return orderList.AsJsonMap(), nil
+}
+
+// listOrdersForAccountIdKey gets all pages of an account's orders.
+func listOrdersForAccountIdKey(
+	eTradeClient client.ETradeClient, accountIdKey string, status constants.OrderStatus, fromDate *time.Time,
+	toDate *time.Time, symbols []string, securityType constants.OrderSecurityType,
+	transactionType constants.OrderTransactionType, marketSession constants.MarketSession,
+) (etradelib.ETradeOrderList, error) {
 	// This determines how many order items will be retrieved in each request.
 	// This should normally be set to the max for efficiency, but can be
 	// lowered to test the pagination logic.
 	const countPerRequest = constants.OrdersMaxCount
 
-	account, err := GetAccountById(eTradeClient, accountId)
-	if err != nil {
-		return nil, err
-	}
 	response, err := eTradeClient.ListOrders(
-		account.GetIdKey(), "", countPerRequest, status, fromDate, toDate, symbols, securityType,
+		accountIdKey, "", countPerRequest, status, fromDate, toDate, symbols, securityType,
 		transactionType, marketSession,
 	)
 	if err != nil {
@@ -36,7 +52,7 @@ func ListOrders(
 
 	for orderList.NextPage() != "" {
 		response, err = eTradeClient.ListOrders(
-			account.GetIdKey(), orderList.NextPage(), countPerRequest, status, fromDate, toDate,
+			accountIdKey, orderList.NextPage(), countPerRequest, status, fromDate, toDate,
 			symbols, securityType, transactionType, marketSession,
 		)
 		if err != nil {
@@ -47,5 +63,5 @@ func ListOrders(
 			return nil, err
 		}
 	}
-	return orderList.AsJsonMap(), nil
+	return orderList, nil
 }
@@ -12,17 +12,32 @@ func ListTransactions(
 	eTradeClient client.ETradeClient, accountId string, startDate *time.Time, endDate *time.Time,
 	sortOrder constants.SortOrder,
 ) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	transactionList, err := listTransactionsForAccountIdKey(
+		eTradeClient, account.GetIdKey(), startDate, endDate, sortOrder,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transactionList.AsJsonMap(), nil
+}
+
+// listTransactionsForAccountIdKey gets all pages of an account's
+// transactions.
+func listTransactionsForAccountIdKey(
+	eTradeClient client.ETradeClient, accountIdKey string, startDate *time.Time, endDate *time.Time,
+	sortOrder constants.SortOrder,
+) (etradelib.ETradeTransactionList, error) {
 	// This determines how many transaction items will be retrieved in each
 	// request. This should normally be set to the max for efficiency, but can
 	// be lowered to test the pagination logic.
 	const countPerRequest = constants.TransactionsMaxCount
 
-	account, err := GetAccountById(eTradeClient, accountId)
-	if err != nil {
-		return nil, err
-	}
 	response, err := eTradeClient.ListTransactions(
-		account.GetIdKey(),
+		accountIdKey,
 		startDate, endDate, sortOrder, "", countPerRequest,
 	)
 	if err != nil {
@@ -35,7 +50,7 @@ func ListTransactions(
 
 	for transactionList.NextPage() != "" {
 		response, err = eTradeClient.ListTransactions(
-			account.GetIdKey(),
+			accountIdKey,
 			startDate, endDate, sortOrder, transactionList.NextPage(), countPerRequest,
 		)
 		if err != nil {
@@ -46,6 +61,5 @@ func ListTransactions(
 			return nil, err
 		}
 	}
-
-	return transactionList.AsJsonMap(), nil
+	return transactionList, nil
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+func PlaceChangedOrder(
+	eTradeClient client.ETradeClient, accountId string, orderId int64, previewId int64, clientOrderId string,
+	changes etradelib.ETradeOrderChanges,
+) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	request, err := createChangeOrderRequest(eTradeClient, account.GetIdKey(), orderId, clientOrderId, changes)
+	if err != nil {
+		return nil, err
+	}
+	response, err := eTradeClient.PlaceChangedOrder(account.GetIdKey(), orderId, previewId, request.AsJsonMap())
+	if err != nil {
+		return nil, err
+	}
+	placeOrder, err := etradelib.CreateETradePlaceOrderFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	return placeOrder.AsJsonMap(), nil
+}
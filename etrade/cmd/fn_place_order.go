@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+func PlaceOrder(
+	eTradeClient client.ETradeClient, accountId string, previewId int64, request etradelib.ETradeOrderRequest,
+) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	response, err := eTradeClient.PlaceOrder(account.GetIdKey(), previewId, request.AsJsonMap())
+	if err != nil {
+		return nil, err
+	}
+	placeOrder, err := etradelib.CreateETradePlaceOrderFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	return placeOrder.AsJsonMap(), nil
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPlaceOrder(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testRequest, err := etradelib.CreateETradeEquityOrderRequest(
+		"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+		constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+
+	type testFn func(mockClient *client.ETradeClientMock) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Places Order",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				testPlaceResponse := []byte(`
+{
+  "PlaceOrderResponse": {
+    "orderType": "EQ",
+    "OrderIds": [
+      {
+        "orderId": 5678
+      }
+    ]
+  }
+}`)
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On(
+					"PlaceOrder", "test key", int64(1234), testRequest.AsJsonMap(),
+				).Return(testPlaceResponse, nil)
+				return PlaceOrder(mockClient, "test id", 1234, testRequest)
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"orderType": "EQ",
+				"orderIds": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"orderId": json.Number("5678"),
+					},
+				},
+			},
+		},
+		{
+			name: "Fails With Bad Account ID",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				return PlaceOrder(mockClient, "bad id", 1234, testRequest)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails On Bad Response",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				testPlaceResponse := []byte(`
+{
+  "PlaceOrderResponse": {
+}`)
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On(
+					"PlaceOrder", "test key", int64(1234), testRequest.AsJsonMap(),
+				).Return(testPlaceResponse, nil)
+				return PlaceOrder(mockClient, "test id", 1234, testRequest)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails On PlaceOrder Error",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On(
+					"PlaceOrder", "test key", int64(1234), testRequest.AsJsonMap(),
+				).Return([]byte{}, errors.New("test error"))
+				return PlaceOrder(mockClient, "test id", 1234, testRequest)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := client.ETradeClientMock{}
+				// Call the Method Under Test
+				actualValue, err := tt.testFn(&mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+func PreviewChangedOrder(
+	eTradeClient client.ETradeClient, accountId string, orderId int64, clientOrderId string,
+	changes etradelib.ETradeOrderChanges,
+) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	request, err := createChangeOrderRequest(eTradeClient, account.GetIdKey(), orderId, clientOrderId, changes)
+	if err != nil {
+		return nil, err
+	}
+	response, err := eTradeClient.PreviewChangedOrder(account.GetIdKey(), orderId, request.AsJsonMap())
+	if err != nil {
+		return nil, err
+	}
+	previewOrder, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	// Include the client order ID in the result because the change must be
+	// placed with the same client order ID that was used for the preview.
+	previewMap := previewOrder.AsJsonMap()
+	previewMap.SetString(etradelib.OrderRequestClientOrderIdKey, request.GetClientOrderId())
+	return previewMap, nil
+}
+
+// createChangeOrderRequest loads the current state of an open order and
+// applies the changes to it. Both the preview and the place steps build the
+// request this way so that the placed change matches the previewed change.
+func createChangeOrderRequest(
+	eTradeClient client.ETradeClient, accountIdKey string, orderId int64, clientOrderId string,
+	changes etradelib.ETradeOrderChanges,
+) (etradelib.ETradeOrderRequest, error) {
+	order, err := GetOpenOrderById(eTradeClient, accountIdKey, orderId)
+	if err != nil {
+		return nil, err
+	}
+	return etradelib.CreateETradeChangeOrderRequest(order, clientOrderId, changes)
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPreviewChangedOrder(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testOrdersResponse := []byte(`
+{
+  "OrdersResponse": {
+    "Order": [
+      {
+        "orderId": 1234,
+        "orderType": "EQ",
+        "OrderDetail": [
+          {
+            "status": "OPEN",
+            "orderTerm": "GOOD_FOR_DAY",
+            "priceType": "LIMIT",
+            "limitPrice": 100.5,
+            "marketSession": "REGULAR",
+            "allOrNone": false,
+            "Instrument": [
+              {
+                "Product": {
+                  "securityType": "EQ",
+                  "symbol": "ABC"
+                },
+                "orderAction": "BUY",
+                "quantityType": "QUANTITY",
+                "orderedQuantity": 10
+              }
+            ]
+          }
+        ]
+      }
+    ]
+  }
+}`)
+	testChanges := etradelib.ETradeOrderChanges{LimitPrice: 99.25}
+	testRequest := jsonmap.JsonMap{
+		"orderType":     "EQ",
+		"clientOrderId": "TestId",
+		"Order": jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				"allOrNone":     false,
+				"priceType":     "LIMIT",
+				"orderTerm":     "GOOD_FOR_DAY",
+				"marketSession": "REGULAR",
+				"limitPrice":    99.25,
+				"Instrument": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"Product": jsonmap.JsonMap{
+							"securityType": "EQ",
+							"symbol":       "ABC",
+						},
+						"orderAction":  "BUY",
+						"quantityType": "QUANTITY",
+						"quantity":     int64(10),
+					},
+				},
+			},
+		},
+	}
+	onListOpenOrders := func(mockClient *client.ETradeClientMock) {
+		mockClient.On(
+			"ListOrders", "test key", "", 100, constants.OrderStatusOpen, (*time.Time)(nil), (*time.Time)(nil),
+			[]string(nil), constants.OrderSecurityTypeNil, constants.OrderTransactionTypeNil,
+			constants.MarketSessionNil,
+		).Return(testOrdersResponse, nil)
+	}
+
+	type testFn func(mockClient *client.ETradeClientMock) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Previews Changed Order",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				testResponse := []byte(`
+{
+  "PreviewOrderResponse": {
+    "orderType": "EQ",
+    "PreviewIds": [
+      {
+        "previewId": 5678
+      }
+    ]
+  }
+}`)
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				onListOpenOrders(mockClient)
+				mockClient.On("PreviewChangedOrder", "test key", int64(1234), testRequest).Return(testResponse, nil)
+				return PreviewChangedOrder(mockClient, "test id", 1234, "TestId", testChanges)
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "EQ",
+				"clientOrderId": "TestId",
+				"previewIds": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"previewId": json.Number("5678"),
+					},
+				},
+			},
+		},
+		{
+			name: "Fails With Bad Account ID",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				return PreviewChangedOrder(mockClient, "bad id", 1234, "TestId", testChanges)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails If Order Is Not Open",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				onListOpenOrders(mockClient)
+				return PreviewChangedOrder(mockClient, "test id", 9999, "TestId", testChanges)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails On PreviewChangedOrder Error",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				onListOpenOrders(mockClient)
+				mockClient.On("PreviewChangedOrder", "test key", int64(1234), testRequest).Return([]byte{}, errors.New("test error"))
+				return PreviewChangedOrder(mockClient, "test id", 1234, "TestId", testChanges)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := client.ETradeClientMock{}
+				// Call the Method Under Test
+				actualValue, err := tt.testFn(&mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+func PreviewOrder(
+	eTradeClient client.ETradeClient, accountId string, request etradelib.ETradeOrderRequest,
+) (jsonmap.JsonMap, error) {
+	account, err := GetAccountById(eTradeClient, accountId)
+	if err != nil {
+		return nil, err
+	}
+	response, err := eTradeClient.PreviewOrder(account.GetIdKey(), request.AsJsonMap())
+	if err != nil {
+		return nil, err
+	}
+	previewOrder, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	// Include the client order ID in the result because the order must be
+	// placed with the same client order ID that was used for the preview.
+	previewMap := previewOrder.AsJsonMap()
+	previewMap.SetString(etradelib.OrderRequestClientOrderIdKey, request.GetClientOrderId())
+	return previewMap, nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestPreviewOrder(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`)
+	testRequest, err := etradelib.CreateETradeEquityOrderRequest(
+		"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+		constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+
+	type testFn func(mockClient *client.ETradeClientMock) (interface{}, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue interface{}
+	}{
+		{
+			name: "Previews Order",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				testPreviewResponse := []byte(`
+{
+  "PreviewOrderResponse": {
+    "orderType": "EQ",
+    "PreviewIds": [
+      {
+        "previewId": 1234
+      }
+    ]
+  }
+}`)
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("PreviewOrder", "test key", testRequest.AsJsonMap()).Return(testPreviewResponse, nil)
+				return PreviewOrder(mockClient, "test id", testRequest)
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "EQ",
+				"clientOrderId": "TestId",
+				"previewIds": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"previewId": json.Number("1234"),
+					},
+				},
+			},
+		},
+		{
+			name: "Fails With Bad Account ID",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				return PreviewOrder(mockClient, "bad id", testRequest)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails On Bad Response",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				testPreviewResponse := []byte(`
+{
+  "PreviewOrderResponse": {
+}`)
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("PreviewOrder", "test key", testRequest.AsJsonMap()).Return(testPreviewResponse, nil)
+				return PreviewOrder(mockClient, "test id", testRequest)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+		{
+			name: "Fails On PreviewOrder Error",
+			testFn: func(mockClient *client.ETradeClientMock) (interface{}, error) {
+				mockClient.On("ListAccounts").Return(testAccountList, nil)
+				mockClient.On("PreviewOrder", "test key", testRequest.AsJsonMap()).Return(
+					[]byte{}, errors.New("test error"),
+				)
+				return PreviewOrder(mockClient, "test id", testRequest)
+			},
+			expectErr:   true,
+			expectValue: jsonmap.JsonMap(nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := client.ETradeClientMock{}
+				// Call the Method Under Test
+				actualValue, err := tt.testFn(&mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"strings"
+	"time"
+)
+
+func AddRule(
+	cfgFolder ConfigurationFolder, cfgStore *CustomerConfigurationStore, rule Rule, logger *slog.Logger,
+) (jsonmap.JsonMap, error) {
+	if _, err := cfgStore.GetCustomerConfigurationById(rule.CustomerId); err != nil {
+		return nil, fmt.Errorf("customer id '%s' not found in config file", rule.CustomerId)
+	}
+	rule.Symbol = strings.ToUpper(rule.Symbol)
+	ruleStore, err := cfgFolder.LoadRules(logger)
+	if err != nil {
+		return nil, err
+	}
+	addedRule, err := ruleStore.AddRule(rule, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	ruleMap := ruleAsJsonMap(addedRule)
+	if err = cfgFolder.SaveRules(ruleStore, logger); err != nil {
+		return nil, err
+	}
+	return ruleMap, nil
+}
+
+func ListRules(cfgFolder ConfigurationFolder, logger *slog.Logger) (jsonmap.JsonMap, error) {
+	ruleStore, err := cfgFolder.LoadRules(logger)
+	if err != nil {
+		return nil, err
+	}
+	ruleSlice := jsonmap.JsonSlice{}
+	for i := range ruleStore.Rules {
+		ruleSlice = append(ruleSlice, ruleAsJsonMap(&ruleStore.Rules[i]))
+	}
+	return jsonmap.JsonMap{
+		"rules": ruleSlice,
+	}, nil
+}
+
+func RemoveRule(cfgFolder ConfigurationFolder, id string, logger *slog.Logger) (jsonmap.JsonMap, error) {
+	ruleStore, err := cfgFolder.LoadRules(logger)
+	if err != nil {
+		return nil, err
+	}
+	if err = ruleStore.RemoveRule(id); err != nil {
+		return nil, err
+	}
+	if err = cfgFolder.SaveRules(ruleStore, logger); err != nil {
+		return nil, err
+	}
+	return jsonmap.JsonMap{
+		"status": "success",
+	}, nil
+}
+
+func ruleAsJsonMap(rule *Rule) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"id":          rule.Id,
+		"customerId":  rule.CustomerId,
+		"description": rule.Description,
+		"symbol":      rule.Symbol,
+		"accountId":   rule.AccountId,
+		"field":       string(rule.Field),
+		"operator":    string(rule.Operator),
+		"value":       rule.Value,
+		"action":      string(rule.Action),
+		"target":      rule.Target,
+		"created":     rule.Created.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRules_AddListRemove(t *testing.T) {
+	logger := etradelibtest.CreateNullLogger()
+	cfgFolder := NewConfigurationFolder(t.TempDir())
+	cfgStore := &CustomerConfigurationStore{
+		customerConfigMap: map[string]CustomerConfiguration{
+			"TestCustomerId": {CustomerName: "Test Customer Name"},
+		},
+	}
+	rule := Rule{
+		CustomerId:  "TestCustomerId",
+		Description: "Test Rule",
+		Symbol:      "aapl",
+		Field:       RuleFieldLastTrade,
+		Operator:    RuleOperatorCrossesAbove,
+		Value:       200,
+		Action:      RuleActionWebhook,
+		Target:      "https://example.com/hook",
+	}
+
+	// Call the Method Under Test
+	unknownCustomerRule := rule
+	unknownCustomerRule.CustomerId = "UnknownCustomerId"
+	_, err := AddRule(cfgFolder, cfgStore, unknownCustomerRule, logger)
+	assert.EqualError(t, err, "customer id 'UnknownCustomerId' not found in config file")
+
+	// Call the Method Under Test
+	addResult, err := AddRule(cfgFolder, cfgStore, rule, logger)
+	require.Nil(t, err)
+	ruleId, err := addResult.GetString("id")
+	require.Nil(t, err)
+	created, err := addResult.GetString("created")
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	listResult, err := ListRules(cfgFolder, logger)
+	require.Nil(t, err)
+	expectedListResult := jsonmap.JsonMap{
+		"rules": jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				"id":          ruleId,
+				"customerId":  "TestCustomerId",
+				"description": "Test Rule",
+				"symbol":      "AAPL",
+				"accountId":   "",
+				"field":       "lastTrade",
+				"operator":    "crossesAbove",
+				"value":       200.0,
+				"action":      "webhook",
+				"target":      "https://example.com/hook",
+				"created":     created,
+			},
+		},
+	}
+	assert.Equal(t, expectedListResult, listResult)
+	assert.Equal(t, expectedListResult["rules"].(jsonmap.JsonSlice)[0], addResult)
+
+	// Call the Method Under Test
+	removeResult, err := RemoveRule(cfgFolder, ruleId, logger)
+	require.Nil(t, err)
+	assert.Equal(t, jsonmap.JsonMap{"status": "success"}, removeResult)
+	ruleStore, err := cfgFolder.LoadRules(logger)
+	require.Nil(t, err)
+	assert.Empty(t, ruleStore.Rules)
+
+	// Call the Method Under Test
+	_, err = RemoveRule(cfgFolder, ruleId, logger)
+	assert.EqualError(t, err, "rule "+ruleId+" not found")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"time"
+)
+
+func CreateServerToken(
+	cfgFolder ConfigurationFolder, cfgStore *CustomerConfigurationStore, description string, customerIds []string,
+	capability ServerTokenCapability, logger *slog.Logger,
+) (jsonmap.JsonMap, error) {
+	for _, customerId := range customerIds {
+		if _, err := cfgStore.GetCustomerConfigurationById(customerId); err != nil {
+			return nil, fmt.Errorf("customer id '%s' not found in config file", customerId)
+		}
+	}
+	tokenStore, err := cfgFolder.LoadServerTokens(logger)
+	if err != nil {
+		return nil, err
+	}
+	secret, token, err := tokenStore.CreateToken(description, customerIds, capability, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	tokenMap := serverTokenAsJsonMap(token)
+	tokenMap.SetString("token", secret)
+	if err = cfgFolder.SaveServerTokens(tokenStore, logger); err != nil {
+		return nil, err
+	}
+	return tokenMap, nil
+}
+
+func ListServerTokens(cfgFolder ConfigurationFolder, logger *slog.Logger) (jsonmap.JsonMap, error) {
+	tokenStore, err := cfgFolder.LoadServerTokens(logger)
+	if err != nil {
+		return nil, err
+	}
+	tokenSlice := jsonmap.JsonSlice{}
+	for i := range tokenStore.Tokens {
+		tokenSlice = append(tokenSlice, serverTokenAsJsonMap(&tokenStore.Tokens[i]))
+	}
+	return jsonmap.JsonMap{
+		"tokens": tokenSlice,
+	}, nil
+}
+
+func RevokeServerToken(cfgFolder ConfigurationFolder, id string, logger *slog.Logger) (jsonmap.JsonMap, error) {
+	tokenStore, err := cfgFolder.LoadServerTokens(logger)
+	if err != nil {
+		return nil, err
+	}
+	if err = tokenStore.RevokeToken(id); err != nil {
+		return nil, err
+	}
+	if err = cfgFolder.SaveServerTokens(tokenStore, logger); err != nil {
+		return nil, err
+	}
+	return jsonmap.JsonMap{
+		"status": "success",
+	}, nil
+}
+
+// serverTokenAsJsonMap returns the token's details (but not its hash).
+func serverTokenAsJsonMap(token *ServerToken) jsonmap.JsonMap {
+	customerIds := jsonmap.JsonSlice{}
+	for _, customerId := range token.CustomerIds {
+		customerIds = append(customerIds, customerId)
+	}
+	return jsonmap.JsonMap{
+		"id":          token.Id,
+		"description": token.Description,
+		"customerIds": customerIds,
+		"capability":  string(token.Capability),
+		"created":     token.Created.Format(time.RFC3339),
+	}
+}
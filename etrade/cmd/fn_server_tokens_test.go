@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestServerTokens_CreateListRevoke(t *testing.T) {
+	logger := etradelibtest.CreateNullLogger()
+	cfgFolder := NewConfigurationFolder(t.TempDir())
+	cfgStore := &CustomerConfigurationStore{
+		customerConfigMap: map[string]CustomerConfiguration{
+			"TestCustomerId": {CustomerName: "Test Customer Name"},
+		},
+	}
+
+	// Call the Method Under Test
+	_, err := CreateServerToken(
+		cfgFolder, cfgStore, "Test Token", []string{"UnknownCustomerId"}, ServerTokenCapabilityRead, logger,
+	)
+	assert.Error(t, err)
+
+	// Call the Method Under Test
+	createResult, err := CreateServerToken(
+		cfgFolder, cfgStore, "Test Token", []string{"TestCustomerId"}, ServerTokenCapabilityTrade, logger,
+	)
+	require.Nil(t, err)
+	tokenId, err := createResult.GetString("id")
+	require.Nil(t, err)
+	secret, err := createResult.GetString("token")
+	require.Nil(t, err)
+	tokenStore, err := cfgFolder.LoadServerTokens(logger)
+	require.Nil(t, err)
+	assert.NotNil(t, tokenStore.Authenticate(secret))
+
+	// Call the Method Under Test
+	listResult, err := ListServerTokens(cfgFolder, logger)
+	require.Nil(t, err)
+	created, err := listResult.GetStringAtPath(".tokens[0].created")
+	require.Nil(t, err)
+	expectedListResult := jsonmap.JsonMap{
+		"tokens": jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				"id":          tokenId,
+				"description": "Test Token",
+				"customerIds": jsonmap.JsonSlice{"TestCustomerId"},
+				"capability":  "trade",
+				"created":     created,
+			},
+		},
+	}
+	assert.Equal(t, expectedListResult, listResult)
+
+	// Call the Method Under Test
+	revokeResult, err := RevokeServerToken(cfgFolder, tokenId, logger)
+	require.Nil(t, err)
+	assert.Equal(t, jsonmap.JsonMap{"status": "success"}, revokeResult)
+	tokenStore, err = cfgFolder.LoadServerTokens(logger)
+	require.Nil(t, err)
+	assert.Nil(t, tokenStore.Authenticate(secret))
+
+	// Call the Method Under Test
+	_, err = RevokeServerToken(cfgFolder, tokenId, logger)
+	assert.Error(t, err)
+}
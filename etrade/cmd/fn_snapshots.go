@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"time"
+)
+
+// TakeSnapshots stores a snapshot of each account's balances and portfolio
+// and adds the account's new transactions to the store. Transactions are
+// listed from the date of the latest stored transaction or, if there are
+// none, from as far back as ETrade keeps them. If no account IDs are given,
+// it takes snapshots of all the customer's open accounts.
+func TakeSnapshots(
+	eTradeClient client.ETradeClient, store *SnapshotStore, accountIds []string, withLots bool, now time.Time,
+) (jsonmap.JsonMap, error) {
+	response, err := eTradeClient.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	accountList, err := etradelib.CreateETradeAccountListFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]etradelib.ETradeAccount, 0, len(accountIds))
+	if len(accountIds) == 0 {
+		for _, account := range accountList.GetAllAccounts() {
+			accountModel, err := account.AsModel()
+			if err != nil {
+				return nil, err
+			}
+			if accountModel.AccountStatus != "CLOSED" {
+				accounts = append(accounts, account)
+			}
+		}
+	} else {
+		for _, accountId := range accountIds {
+			account := accountList.GetAccountById(accountId)
+			if account == nil {
+				return nil, fmt.Errorf("account with id %s not found", accountId)
+			}
+			accounts = append(accounts, account)
+		}
+	}
+
+	// One account's failure doesn't keep the others from being stored.
+	snapshotSlice := jsonmap.JsonSlice{}
+	var errs []error
+	for _, account := range accounts {
+		snapshotMap, err := takeSnapshot(eTradeClient, store, account, withLots, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("account %s: %w", account.GetId(), err))
+			continue
+		}
+		snapshotSlice = append(snapshotSlice, snapshotMap)
+	}
+	return jsonmap.JsonMap{
+		"snapshots": snapshotSlice,
+	}, errors.Join(errs...)
+}
+
+func takeSnapshot(
+	eTradeClient client.ETradeClient, store *SnapshotStore, account etradelib.ETradeAccount, withLots bool,
+	now time.Time,
+) (jsonmap.JsonMap, error) {
+	balances, err := GetAccountBalances(eTradeClient, account.GetId(), true)
+	if err != nil {
+		return nil, err
+	}
+	portfolio, err := ViewPortfolio(
+		eTradeClient, account.GetId(), constants.PortfolioSortByNil, constants.SortOrderNil,
+		constants.MarketSessionNil, true, constants.PortfolioViewQuick, withLots,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions are listed by date, so the latest stored date is listed
+	// again in case more transactions were posted on it. The store ignores
+	// the ones that it already has.
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return nil, err
+	}
+	startDate := getStartOfDay(now.In(location)).AddDate(-2, 0, 1)
+	latestDate, found, err := store.GetLatestTransactionDate(account.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if found && latestDate.After(startDate) {
+		startDate = getStartOfDay(latestDate.In(location))
+	}
+	endDate := now.In(location)
+	transactionList, err := listTransactionsForAccountIdKey(
+		eTradeClient, account.GetIdKey(), &startDate, &endDate, constants.SortOrderAsc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]jsonmap.JsonMap, 0)
+	for _, transaction := range transactionList.GetAllTransactions() {
+		transactions = append(transactions, transaction.AsJsonMap())
+	}
+
+	snapshot := Snapshot{
+		AccountId: account.GetId(),
+		TakenAt:   now,
+		Balances:  balances,
+		Portfolio: portfolio,
+	}
+	if err = store.AddSnapshot(&snapshot); err != nil {
+		return nil, err
+	}
+	added, err := store.AddTransactions(account.GetId(), transactions)
+	if err != nil {
+		return nil, err
+	}
+	snapshotMap := getSnapshotSummaryJsonMap(&snapshot)
+	snapshotMap["transactionsAdded"] = added
+	return snapshotMap, nil
+}
+
+// ListSnapshots returns a summary of each of the account's stored snapshots
+// that were taken from the from time to the to time.
+func ListSnapshots(store *SnapshotStore, accountId string, from *time.Time, to *time.Time) (jsonmap.JsonMap, error) {
+	snapshots, err := store.GetSnapshots(accountId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	snapshotSlice := jsonmap.JsonSlice{}
+	for _, snapshot := range snapshots {
+		snapshotSlice = append(snapshotSlice, getSnapshotSummaryJsonMap(snapshot))
+	}
+	return jsonmap.JsonMap{
+		"snapshots": snapshotSlice,
+	}, nil
+}
+
+// GetSnapshotPortfolio returns the portfolio from the account's snapshot with
+// the ID or, if the ID is empty, from the latest snapshot that was taken by
+// the to time.
+func GetSnapshotPortfolio(store *SnapshotStore, accountId string, id string, to *time.Time) (jsonmap.JsonMap, error) {
+	snapshot, err := getSnapshotByIdOrTime(store, accountId, id, to)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Portfolio, nil
+}
+
+// ListStoredTransactions returns the account's stored transactions from the
+// from time to the to time.
+func ListStoredTransactions(store *SnapshotStore, accountId string, from *time.Time, to *time.Time) (
+	jsonmap.JsonMap, error,
+) {
+	transactions, err := store.GetTransactions(accountId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return jsonmap.JsonMap{
+		"transactions": jsonMapsAsJsonSlice(transactions),
+	}, nil
+}
+
+// getSnapshotByIdOrTime returns the account's snapshot with the ID or, if the
+// ID is empty, the latest snapshot that was taken by the to time (or at all,
+// if the to time is nil).
+func getSnapshotByIdOrTime(store *SnapshotStore, accountId string, id string, to *time.Time) (*Snapshot, error) {
+	if id != "" {
+		return store.GetSnapshot(accountId, id)
+	}
+	snapshots, err := store.GetSnapshots(accountId, nil, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		if to != nil {
+			return nil, fmt.Errorf(
+				"account %s has no snapshots taken by %s", accountId, to.Format(time.DateTime),
+			)
+		}
+		return nil, fmt.Errorf("account %s has no snapshots", accountId)
+	}
+	return snapshots[len(snapshots)-1], nil
+}
+
+// getSnapshotSummaryJsonMap returns the snapshot's ID and the account's
+// values when the snapshot was taken.
+func getSnapshotSummaryJsonMap(snapshot *Snapshot) jsonmap.JsonMap {
+	totalMarketValue, _ := snapshot.Portfolio.GetFloatAtPath(".totals.totalMarketValue")
+	cashBalance, _ := snapshot.Portfolio.GetFloatAtPath(".totals.cashBalance")
+	positions, _ := snapshot.Portfolio.GetSliceWithDefault("positions", jsonmap.JsonSlice{})
+	return jsonmap.JsonMap{
+		"id":                snapshot.Id,
+		"accountId":         snapshot.AccountId,
+		"takenAt":           snapshot.TakenAt.Format(time.RFC3339),
+		"totalAccountValue": getSnapshotTotalAccountValue(snapshot),
+		"totalMarketValue":  totalMarketValue,
+		"cashBalance":       cashBalance,
+		"positionCount":     len(positions),
+	}
+}
+
+// getSnapshotTotalAccountValue returns the real-time total value from the
+// snapshot's balances or, if the balances don't have it, the market value and
+// cash balance from its portfolio's totals.
+func getSnapshotTotalAccountValue(snapshot *Snapshot) float64 {
+	totalAccountValue, err := snapshot.Balances.GetFloatAtPath(".computed.realTimeValues.totalAccountValue")
+	if err == nil && totalAccountValue > 0 {
+		return totalAccountValue
+	}
+	totalMarketValue, _ := snapshot.Portfolio.GetFloatAtPath(".totals.totalMarketValue")
+	cashBalance, _ := snapshot.Portfolio.GetFloatAtPath(".totals.cashBalance")
+	return totalMarketValue + cashBalance
+}
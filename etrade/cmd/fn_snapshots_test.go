@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSnapshots_TakeAndQuery(t *testing.T) {
+	testAccountList := []byte(`
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key",
+          "accountStatus": "ACTIVE"
+        },
+        {
+          "accountId": "closed id",
+          "accountIdKey": "closed key",
+          "accountStatus": "CLOSED"
+        }
+      ]
+    }
+  }
+}`)
+	testBalancesResponse := []byte(`
+{
+  "BalanceResponse": {
+    "accountId": "test id",
+    "Computed": {
+      "RealTimeValues": {
+        "totalAccountValue": 1210
+      }
+    }
+  }
+}`)
+	testPortfolioResponse := []byte(`
+{
+  "PortfolioResponse": {
+    "Totals": {
+      "totalMarketValue": 1000,
+      "cashBalance": 210
+    },
+    "AccountPortfolio": [
+      {
+        "Position": [
+          {
+            "positionId": 1234,
+            "Product": {"symbol": "AAPL", "securityType": "EQ"},
+            "quantity": 10
+          }
+        ]
+      }
+    ]
+  }
+}`)
+	testFirstTransactionsResponse := []byte(`
+{
+  "TransactionListResponse": {
+    "Transaction": [
+      {
+        "transactionId": "101",
+        "transactionDate": 1736528400000,
+        "amount": 100,
+        "transactionType": "Online Transfer"
+      }
+    ]
+  }
+}`)
+	testSecondTransactionsResponse := []byte(`
+{
+  "TransactionListResponse": {
+    "Transaction": [
+      {
+        "transactionId": "101",
+        "transactionDate": 1736528400000,
+        "amount": 100,
+        "transactionType": "Online Transfer"
+      },
+      {
+        "transactionId": "102",
+        "transactionDate": 1736787600000,
+        "amount": -1000,
+        "transactionType": "Bought"
+      }
+    ]
+  }
+}`)
+	location, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+	testFirstNow := time.Date(2025, 1, 10, 16, 30, 0, 0, location)
+	testSecondNow := time.Date(2025, 1, 14, 16, 30, 0, 0, location)
+	store := NewSnapshotStore(t.TempDir(), etradelibtest.CreateNullLogger())
+
+	mockClient := new(client.ETradeClientMock)
+	mockClient.On("ListAccounts").Return(testAccountList, nil)
+	mockClient.On("GetAccountBalances", "test key", true).Return(testBalancesResponse, nil)
+	mockClient.On(
+		"ViewPortfolio", "test key", 65535, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+		constants.MarketSessionNil, true, true, constants.PortfolioViewQuick,
+	).Return(testPortfolioResponse, nil)
+	// The first snapshot lists as far back as ETrade keeps transactions.
+	firstStartDate := time.Date(2023, 1, 11, 0, 0, 0, 0, location)
+	mockClient.On(
+		"ListTransactions", "test key", mock.MatchedBy(
+			func(startDate *time.Time) bool { return startDate.Equal(firstStartDate) },
+		), mock.Anything, constants.SortOrderAsc, "", 50,
+	).Return(testFirstTransactionsResponse, nil).Once()
+	// The second snapshot lists from the date of the latest stored
+	// transaction.
+	secondStartDate := time.Date(2025, 1, 10, 0, 0, 0, 0, location)
+	mockClient.On(
+		"ListTransactions", "test key", mock.MatchedBy(
+			func(startDate *time.Time) bool { return startDate.Equal(secondStartDate) },
+		), mock.Anything, constants.SortOrderAsc, "", 50,
+	).Return(testSecondTransactionsResponse, nil).Once()
+
+	// Call the Method Under Test
+	firstResult, err := TakeSnapshots(mockClient, store, nil, false, testFirstNow)
+	require.Nil(t, err)
+	firstSnapshot := jsonmap.JsonMap{
+		"id": "20250110T213000Z", "accountId": "test id", "takenAt": "2025-01-10T16:30:00-05:00",
+		"totalAccountValue": 1210.0, "totalMarketValue": 1000.0, "cashBalance": 210.0, "positionCount": 1,
+	}
+	assert.Equal(
+		t, jsonmap.JsonMap{
+			"snapshots": jsonmap.JsonSlice{
+				jsonmap.JsonMap{
+					"id": "20250110T213000Z", "accountId": "test id", "takenAt": "2025-01-10T16:30:00-05:00",
+					"totalAccountValue": 1210.0, "totalMarketValue": 1000.0, "cashBalance": 210.0,
+					"positionCount": 1, "transactionsAdded": 1,
+				},
+			},
+		}, firstResult,
+	)
+
+	// Call the Method Under Test
+	secondResult, err := TakeSnapshots(mockClient, store, []string{"test id"}, false, testSecondNow)
+	require.Nil(t, err)
+	transactionsAdded, err := secondResult.GetIntAtPath(".snapshots[0].transactionsAdded")
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), transactionsAdded)
+	mockClient.AssertExpectations(t)
+
+	// Call the Method Under Test
+	_, err = TakeSnapshots(mockClient, store, []string{"unknown id"}, false, testSecondNow)
+	assert.EqualError(t, err, "account with id unknown id not found")
+
+	// Call the Method Under Test
+	listResult, err := ListSnapshots(store, "test id", nil, &testFirstNow)
+	require.Nil(t, err)
+	assert.Equal(t, jsonmap.JsonMap{"snapshots": jsonmap.JsonSlice{firstSnapshot}}, listResult)
+
+	// Call the Method Under Test
+	portfolio, err := GetSnapshotPortfolio(store, "test id", "", nil)
+	require.Nil(t, err)
+	symbol, err := portfolio.GetStringAtPath(".positions[0].product.symbol")
+	require.Nil(t, err)
+	assert.Equal(t, "AAPL", symbol)
+
+	// Call the Method Under Test
+	_, err = GetSnapshotPortfolio(store, "test id", "", &firstStartDate)
+	assert.EqualError(t, err, "account test id has no snapshots taken by 2023-01-11 00:00:00")
+
+	// Call the Method Under Test
+	transactionsResult, err := ListStoredTransactions(store, "test id", &secondStartDate, nil)
+	require.Nil(t, err)
+	transactions, err := transactionsResult.GetSliceOfMaps("transactions")
+	require.Nil(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "101", getSnapshotTransactionId(transactions[0]))
+	assert.Equal(t, "102", getSnapshotTransactionId(transactions[1]))
+}
@@ -0,0 +1,547 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LotMatchingMethod is how a sale's shares are matched to the lots that they
+// were bought in.
+type LotMatchingMethod string
+
+const (
+	// LotMatchingFifo sells the earliest-acquired lots first.
+	LotMatchingFifo LotMatchingMethod = "fifo"
+
+	// LotMatchingLifo sells the latest-acquired lots first.
+	LotMatchingLifo LotMatchingMethod = "lifo"
+
+	// LotMatchingHifo sells the lots with the highest cost per share first.
+	LotMatchingHifo LotMatchingMethod = "hifo"
+
+	// LotMatchingSpecific sells the lots named for each sale (see
+	// SpecificLot), and the earliest-acquired lots for the rest of the sale.
+	LotMatchingSpecific LotMatchingMethod = "specific"
+)
+
+// washSaleDays is how many days before or after a sale at a loss a purchase
+// of the same security makes the sale a wash sale.
+const washSaleDays = 30
+
+// gainsQuantityTolerance is the quantity below which a sale is considered
+// fully matched, to absorb floating-point error in fractional shares.
+const gainsQuantityTolerance = 1e-9
+
+// SpecificLot names a lot to sell shares of a sale from. The lot ID is the
+// transaction ID of the purchase or, for a lot bought before the
+// transaction history, "lot-" followed by ETrade's position lot ID. If the
+// quantity is zero, as many of the sale's shares as the lot has are sold
+// from it.
+type SpecificLot struct {
+	SaleTransactionId string
+	LotId             string
+	Quantity          float64
+}
+
+// ParseSpecificLot parses a specific lot in the form
+// SALE_TRANSACTION_ID=LOT_ID[:QUANTITY].
+func ParseSpecificLot(value string) (SpecificLot, error) {
+	saleTransactionId, lot, ok := strings.Cut(value, "=")
+	if !ok || saleTransactionId == "" || lot == "" {
+		return SpecificLot{}, fmt.Errorf("invalid lot %s (must be SALE_TRANSACTION_ID=LOT_ID[:QUANTITY])", value)
+	}
+	lotId, quantityString, hasQuantity := strings.Cut(lot, ":")
+	specificLot := SpecificLot{SaleTransactionId: saleTransactionId, LotId: lotId}
+	if hasQuantity {
+		quantity, err := strconv.ParseFloat(quantityString, 64)
+		if err != nil || quantity <= 0 {
+			return SpecificLot{}, fmt.Errorf("invalid lot quantity %s (must be greater than zero)", quantityString)
+		}
+		specificLot.Quantity = quantity
+	}
+	return specificLot, nil
+}
+
+// gainsTrade is a purchase or sale of a security.
+type gainsTrade struct {
+	transactionId string
+	security      string
+	securityType  string
+	date          time.Time
+	isBuy         bool
+	// isShort is true for a sale that opens a short position (a short sale
+	// or a written option) and for a purchase that closes one.
+	isShort  bool
+	quantity float64
+	// amount is the cost of a purchase or the proceeds of a sale, net of
+	// commissions and fees.
+	amount float64
+}
+
+// opens returns true if the trade opens a position: a purchase of a long
+// position or a sale of a short one.
+func (t *gainsTrade) opens() bool {
+	return t.isBuy != t.isShort
+}
+
+// gainsLot is shares of a security that were bought together or, for a short
+// lot, sold short together.
+type gainsLot struct {
+	id       string
+	security string
+	isShort  bool
+	acquired time.Time
+	quantity float64
+	// costPerShare includes losses disallowed by wash sales. Short lots don't
+	// have a cost until they're closed.
+	costPerShare float64
+	// proceedsPerShare is what a short lot's shares were sold for.
+	proceedsPerShare float64
+	// holdingStart is when the lot's holding period started. It's earlier
+	// than acquired if the lot replaced shares sold in a wash sale.
+	holdingStart time.Time
+
+	remaining float64
+	// washReplaced is how many of the lot's remaining shares have replaced
+	// shares sold in wash sales, which can only happen once per share.
+	washReplaced float64
+	// sequence orders the lot among the trades, so that a sale can't sell a
+	// lot that was bought after it on the same day.
+	sequence int
+}
+
+// realizedGain is the gain or loss on the shares of a sale that came from
+// one lot. If the shares couldn't be matched to a lot, hasBasis is false.
+type realizedGain struct {
+	saleTransactionId string
+	security          string
+	securityType      string
+	lotId             string
+	quantity          float64
+	acquired          time.Time
+	sold              time.Time
+	proceeds          float64
+	costBasis         float64
+	hasBasis          bool
+	isLongTerm        bool
+	// washSaleDisallowed is the part of the loss that a wash sale
+	// disallowed. It's added to the cost of the replacement shares.
+	washSaleDisallowed float64
+}
+
+// Gain returns the gain (negative for a loss), less any loss that a wash
+// sale disallowed.
+func (g *realizedGain) Gain() float64 {
+	return g.proceeds - g.costBasis + g.washSaleDisallowed
+}
+
+// calculateRealizedGains matches the shares of each sale to the lots that
+// they were bought in: lots bought before the trades (openingLots) and lots
+// bought by the trades. Likewise, the shares of each purchase that closes a
+// short position are matched to the short lots that they were sold in. It
+// returns the gain on each lot of each closing trade, in the order of the
+// trades. Sales at a loss with purchases of the same security within
+// washSaleDays have their losses disallowed, up to the number of replacement
+// shares, and the disallowed loss and holding period are added to the
+// replacement lots. Short positions are always short-term and aren't wash
+// sales. It fails if a short position's closing purchase can't be matched to
+// short lots, since the short sale's proceeds are unknown.
+func calculateRealizedGains(
+	trades []gainsTrade, openingLots []gainsLot, method LotMatchingMethod, specificLots []SpecificLot,
+) ([]realizedGain, error) {
+	trades = append([]gainsTrade(nil), trades...)
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].date.Before(trades[j].date) })
+
+	lots := make([]*gainsLot, 0, len(openingLots)+len(trades))
+	for i := range openingLots {
+		lot := openingLots[i]
+		lot.remaining = lot.quantity
+		lot.holdingStart = lot.acquired
+		lot.sequence = -1
+		lots = append(lots, &lot)
+	}
+	for i, trade := range trades {
+		if !trade.opens() {
+			continue
+		}
+		lot := &gainsLot{
+			id:           trade.transactionId,
+			security:     trade.security,
+			isShort:      trade.isShort,
+			acquired:     trade.date,
+			quantity:     trade.quantity,
+			holdingStart: trade.date,
+			remaining:    trade.quantity,
+			sequence:     i,
+		}
+		if trade.isShort {
+			lot.proceedsPerShare = trade.amount / trade.quantity
+		} else {
+			lot.costPerShare = trade.amount / trade.quantity
+		}
+		lots = append(lots, lot)
+	}
+	lotsById := map[string]*gainsLot{}
+	for _, lot := range lots {
+		lotsById[lot.id] = lot
+	}
+	specificLotsBySale := map[string][]SpecificLot{}
+	for _, specificLot := range specificLots {
+		specificLotsBySale[specificLot.SaleTransactionId] = append(
+			specificLotsBySale[specificLot.SaleTransactionId], specificLot,
+		)
+	}
+
+	var gains []realizedGain
+	for i, sale := range trades {
+		if sale.opens() {
+			continue
+		}
+		isAvailable := func(lot *gainsLot) bool {
+			return lot.security == sale.security && lot.isShort == sale.isShort && lot.sequence < i &&
+				lot.remaining > gainsQuantityTolerance
+		}
+		amountPerShare := sale.amount / sale.quantity
+		unsold := sale.quantity
+		var saleGains []realizedGain
+		sell := func(lot *gainsLot, quantity float64) {
+			// The lot's shares share one cost per share, so the sold shares
+			// take their part of the lot's wash sale replacements with them.
+			lot.washReplaced -= lot.washReplaced * quantity / lot.remaining
+			lot.remaining -= quantity
+			unsold -= quantity
+			gain := realizedGain{
+				saleTransactionId: sale.transactionId,
+				security:          sale.security,
+				securityType:      sale.securityType,
+				lotId:             lot.id,
+				quantity:          quantity,
+				acquired:          lot.acquired,
+				sold:              sale.date,
+				proceeds:          amountPerShare * quantity,
+				costBasis:         lot.costPerShare * quantity,
+				hasBasis:          true,
+				isLongTerm:        isLongTermHolding(lot.holdingStart, sale.date),
+			}
+			if lot.isShort {
+				// The shares that close a short position are acquired when
+				// they're bought, as Form 8949 reports short sales.
+				gain.acquired = sale.date
+				gain.proceeds = lot.proceedsPerShare * quantity
+				gain.costBasis = amountPerShare * quantity
+				gain.isLongTerm = false
+			}
+			saleGains = append(saleGains, gain)
+		}
+
+		if method == LotMatchingSpecific {
+			for _, specificLot := range specificLotsBySale[sale.transactionId] {
+				lot, ok := lotsById[specificLot.LotId]
+				if !ok || !isAvailable(lot) {
+					return nil, fmt.Errorf(
+						"lot %s isn't available to sale %s of %s", specificLot.LotId, sale.transactionId,
+						sale.security,
+					)
+				}
+				quantity := math.Min(unsold, lot.remaining)
+				if specificLot.Quantity > 0 {
+					quantity = math.Min(quantity, specificLot.Quantity)
+				}
+				sell(lot, quantity)
+			}
+		}
+
+		var candidates []*gainsLot
+		for _, lot := range lots {
+			if isAvailable(lot) {
+				candidates = append(candidates, lot)
+			}
+		}
+		sortLotsForMatching(candidates, method)
+		for _, lot := range candidates {
+			if unsold <= gainsQuantityTolerance {
+				break
+			}
+			if lot.remaining > gainsQuantityTolerance {
+				sell(lot, math.Min(unsold, lot.remaining))
+			}
+		}
+		if unsold > gainsQuantityTolerance && sale.isShort {
+			return nil, fmt.Errorf(
+				"purchase %s of %s closes short shares that weren't sold short within the transaction history",
+				sale.transactionId, sale.security,
+			)
+		}
+		if unsold > gainsQuantityTolerance {
+			saleGains = append(
+				saleGains, realizedGain{
+					saleTransactionId: sale.transactionId,
+					security:          sale.security,
+					securityType:      sale.securityType,
+					quantity:          unsold,
+					sold:              sale.date,
+					proceeds:          amountPerShare * unsold,
+				},
+			)
+		}
+
+		soldLotIds := map[string]bool{}
+		for _, gain := range saleGains {
+			soldLotIds[gain.lotId] = true
+		}
+		for j := range saleGains {
+			applyWashSale(&saleGains[j], lots, lotsById, soldLotIds)
+		}
+		gains = append(gains, saleGains...)
+	}
+	return gains, nil
+}
+
+// sortLotsForMatching sorts lots into the order that the method sells them
+// in. Specific-lot matching sells the lots that weren't named in FIFO order.
+func sortLotsForMatching(lots []*gainsLot, method LotMatchingMethod) {
+	sort.SliceStable(
+		lots, func(i, j int) bool {
+			switch method {
+			case LotMatchingLifo:
+				return lots[i].acquired.After(lots[j].acquired)
+			case LotMatchingHifo:
+				// Short lots that were sold for the least have the smallest
+				// gains, like long lots that cost the most.
+				if lots[i].isShort {
+					return lots[i].proceedsPerShare < lots[j].proceedsPerShare
+				}
+				return lots[i].costPerShare > lots[j].costPerShare
+			default:
+				return lots[i].acquired.Before(lots[j].acquired)
+			}
+		},
+	)
+}
+
+// applyWashSale disallows the loss on the gain's shares that were replaced
+// by purchases of the same security within washSaleDays of the sale, and
+// adds the disallowed loss and the sold shares' holding period to the
+// replacement lots. Lots that the sale sold from (soldLotIds) and short lots
+// can't be replacements, and losses on short lots aren't disallowed.
+func applyWashSale(
+	gain *realizedGain, lots []*gainsLot, lotsById map[string]*gainsLot, soldLotIds map[string]bool,
+) {
+	if !gain.hasBasis || gain.Gain() >= 0 || lotsById[gain.lotId].isShort {
+		return
+	}
+	soldLot := lotsById[gain.lotId]
+	lossPerShare := (gain.costBasis - gain.proceeds) / gain.quantity
+	windowStart := gain.sold.AddDate(0, 0, -washSaleDays)
+	windowEnd := gain.sold.AddDate(0, 0, washSaleDays)
+	unreplaced := gain.quantity
+	for _, lot := range lots {
+		if unreplaced <= gainsQuantityTolerance {
+			break
+		}
+		if soldLotIds[lot.id] || lot.isShort || lot.security != gain.security || lot.acquired.Before(windowStart) ||
+			lot.acquired.After(windowEnd) {
+			continue
+		}
+		// Shares of the lot that were already sold can't replace the gain's
+		// shares.
+		replaced := math.Min(unreplaced, lot.remaining-lot.washReplaced)
+		if replaced <= gainsQuantityTolerance {
+			continue
+		}
+		lot.washReplaced += replaced
+		unreplaced -= replaced
+		gain.washSaleDisallowed += lossPerShare * replaced
+		// The lot's shares share one cost per share, so the disallowed loss
+		// is spread over all of its remaining shares.
+		lot.costPerShare += lossPerShare * replaced / lot.remaining
+		holdingStart := lot.acquired.Add(-gain.sold.Sub(soldLot.holdingStart))
+		if holdingStart.Before(lot.holdingStart) {
+			lot.holdingStart = holdingStart
+		}
+	}
+}
+
+// isLongTermHolding returns true if shares held from holdingStart and sold
+// on sold were held for more than a year.
+func isLongTermHolding(holdingStart time.Time, sold time.Time) bool {
+	return sold.Format(time.DateOnly) > holdingStart.AddDate(1, 0, 0).Format(time.DateOnly)
+}
+
+// getForm8949Box returns the Form 8949 box that the gain is reported in: A
+// (short-term) or D (long-term) if the broker reported its cost basis to the
+// IRS, or B or E if it didn't. Brokers only report the cost basis of covered
+// securities, which were acquired after the reporting requirement started
+// for their type. Every sale in the account is on a 1099-B, so boxes C and F
+// aren't used.
+func getForm8949Box(gain *realizedGain) string {
+	coveredSince := time.Date(2011, time.January, 1, 0, 0, 0, 0, gain.acquired.Location())
+	switch gain.securityType {
+	case "", "EQ":
+	case "MF", "MMF":
+		coveredSince = coveredSince.AddDate(1, 0, 0)
+	default:
+		// Options and bonds
+		coveredSince = coveredSince.AddDate(3, 0, 0)
+	}
+	isCovered := !gain.acquired.Before(coveredSince)
+	switch {
+	case gain.isLongTerm && isCovered:
+		return "D"
+	case gain.isLongTerm:
+		return "E"
+	case isCovered:
+		return "A"
+	default:
+		return "B"
+	}
+}
+
+// getGainsSecurity identifies a product's security: its symbol or, for an
+// option, its symbol, expiration, strike price, and type.
+func getGainsSecurity(product *model.Product) string {
+	symbol := strings.ToUpper(product.Symbol)
+	if product.CallPut == "" {
+		return symbol
+	}
+	return fmt.Sprintf(
+		"%s %04d-%02d-%02d %s %s", symbol, product.ExpiryYear, product.ExpiryMonth, product.ExpiryDay,
+		strconv.FormatFloat(product.StrikePrice, 'f', -1, 64), strings.ToUpper(product.CallPut),
+	)
+}
+
+// getGainsTrade returns the purchase or sale in a transaction. It returns
+// false if the transaction isn't a purchase or sale.
+func getGainsTrade(transaction *model.Transaction, location *time.Location) (gainsTrade, bool) {
+	brokerage := transaction.Brokerage
+	if brokerage == nil || brokerage.Product == nil || brokerage.Product.Symbol == "" {
+		return gainsTrade{}, false
+	}
+	transactionType := transaction.TransactionType
+	if transactionType == "" {
+		transactionType = brokerage.TransactionType
+	}
+	var isBuy, isShort bool
+	switch strings.ToLower(transactionType) {
+	case "bought", "bought to open":
+		isBuy, isShort = true, false
+	case "sold", "sold to close":
+		isBuy, isShort = false, false
+	case "sold short", "sold to open":
+		isBuy, isShort = false, true
+	case "bought to cover", "bought to close":
+		isBuy, isShort = true, true
+	default:
+		return gainsTrade{}, false
+	}
+	quantity := math.Abs(brokerage.Quantity)
+	if quantity == 0 {
+		return gainsTrade{}, false
+	}
+	securityType := strings.ToUpper(brokerage.Product.SecurityType)
+	fees := brokerage.Fee + brokerage.Commission
+	grossAmount := quantity * brokerage.Price
+	amount := math.Abs(transaction.Amount)
+	if amount == 0 || (fees != 0 && math.Abs(amount-grossAmount) < 0.005) {
+		// The amount is usually net of fees, so it's only estimated from the
+		// price if it's missing or is the gross amount.
+		amount = grossAmount
+		if isBuy {
+			amount += fees
+		} else {
+			amount -= fees
+		}
+	}
+	return gainsTrade{
+		transactionId: transaction.TransactionId.String(),
+		security:      getGainsSecurity(brokerage.Product),
+		securityType:  securityType,
+		date:          time.UnixMilli(transaction.TransactionDate).In(location),
+		isBuy:         isBuy,
+		isShort:       isShort,
+		quantity:      quantity,
+		amount:        amount,
+	}, true
+}
+
+// applyGainsTransactionDetails returns the transaction with the amount,
+// fees, and commissions from its details, which may have fees and
+// commissions that the transaction list doesn't.
+func applyGainsTransactionDetails(transaction *model.Transaction, details *model.Transaction) *model.Transaction {
+	applied := *transaction
+	if details.Amount != 0 {
+		applied.Amount = details.Amount
+	}
+	if applied.Brokerage != nil && details.Brokerage != nil {
+		brokerage := *applied.Brokerage
+		if details.Brokerage.Fee != 0 {
+			brokerage.Fee = details.Brokerage.Fee
+		}
+		if details.Brokerage.Commission != 0 {
+			brokerage.Commission = details.Brokerage.Commission
+		}
+		applied.Brokerage = &brokerage
+	}
+	return &applied
+}
+
+// getRealizedGainsJsonMap returns the gains on the sales in the year, with
+// their totals.
+func getRealizedGainsJsonMap(gains []realizedGain, year int) jsonmap.JsonMap {
+	gainsSlice := jsonmap.JsonSlice{}
+	var proceeds, costBasis, washSaleDisallowed, shortTermGain, longTermGain float64
+	for i := range gains {
+		gain := &gains[i]
+		if gain.sold.Year() != year {
+			continue
+		}
+		gainMap := jsonmap.JsonMap{
+			"saleTransactionId":      gain.saleTransactionId,
+			"symbol":                 gain.security,
+			"lotId":                  gain.lotId,
+			"quantity":               gain.quantity,
+			"dateSold":               gain.sold.Format(time.DateOnly),
+			"proceeds":               roundToCents(gain.proceeds),
+			"washSale":               gain.washSaleDisallowed > 0,
+			"washSaleLossDisallowed": roundToCents(gain.washSaleDisallowed),
+		}
+		proceeds += gain.proceeds
+		if gain.hasBasis {
+			gainMap["dateAcquired"] = gain.acquired.Format(time.DateOnly)
+			gainMap["costBasis"] = roundToCents(gain.costBasis)
+			gainMap["gain"] = roundToCents(gain.Gain())
+			gainMap["form8949Box"] = getForm8949Box(gain)
+			costBasis += gain.costBasis
+			washSaleDisallowed += gain.washSaleDisallowed
+			if gain.isLongTerm {
+				gainMap["term"] = "long"
+				longTermGain += gain.Gain()
+			} else {
+				gainMap["term"] = "short"
+				shortTermGain += gain.Gain()
+			}
+		}
+		gainsSlice = append(gainsSlice, gainMap)
+	}
+	return jsonmap.JsonMap{
+		"gains": gainsSlice,
+		"totals": jsonmap.JsonMap{
+			"proceeds":               roundToCents(proceeds),
+			"costBasis":              roundToCents(costBasis),
+			"washSaleLossDisallowed": roundToCents(washSaleDisallowed),
+			"shortTermGain":          roundToCents(shortTermGain),
+			"longTermGain":           roundToCents(longTermGain),
+			"totalGain":              roundToCents(shortTermGain + longTermGain),
+		},
+	}
+}
+
+func roundToCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
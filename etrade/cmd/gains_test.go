@@ -0,0 +1,669 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testGainsDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func testGainsBuy(id string, date time.Time, quantity float64, amount float64) gainsTrade {
+	return gainsTrade{
+		transactionId: id, security: "AAPL", date: date, isBuy: true, quantity: quantity, amount: amount,
+	}
+}
+
+func testGainsSale(id string, date time.Time, quantity float64, amount float64) gainsTrade {
+	return gainsTrade{
+		transactionId: id, security: "AAPL", date: date, isBuy: false, quantity: quantity, amount: amount,
+	}
+}
+
+func testGainsShortSale(id string, date time.Time, quantity float64, amount float64) gainsTrade {
+	trade := testGainsSale(id, date, quantity, amount)
+	trade.isShort = true
+	return trade
+}
+
+func testGainsCover(id string, date time.Time, quantity float64, amount float64) gainsTrade {
+	trade := testGainsBuy(id, date, quantity, amount)
+	trade.isShort = true
+	return trade
+}
+
+// getTestGainSummaries summarizes the gains as "sale lot quantity proceeds
+// basis gain term disallowed".
+func getTestGainSummaries(gains []realizedGain) []string {
+	summaries := make([]string, 0, len(gains))
+	for _, gain := range gains {
+		term := ""
+		if gain.hasBasis {
+			term = "short"
+			if gain.isLongTerm {
+				term = "long"
+			}
+		}
+		summaries = append(
+			summaries, fmt.Sprintf(
+				"%s %s %g %.2f %.2f %.2f %s %.2f", gain.saleTransactionId, gain.lotId, gain.quantity, gain.proceeds,
+				gain.costBasis, gain.Gain(), term, gain.washSaleDisallowed,
+			),
+		)
+	}
+	return summaries
+}
+
+func TestCalculateRealizedGains(t *testing.T) {
+	threeLots := []gainsTrade{
+		testGainsBuy("B1", testGainsDate(2023, time.January, 3), 10, 1000),
+		testGainsBuy("B2", testGainsDate(2023, time.June, 1), 10, 1200),
+		testGainsBuy("B3", testGainsDate(2023, time.September, 1), 10, 1100),
+	}
+	tests := []struct {
+		name             string
+		testTrades       []gainsTrade
+		testOpeningLots  []gainsLot
+		testMethod       LotMatchingMethod
+		testSpecificLots []SpecificLot
+		expectErr        bool
+		expectSummaries  []string
+	}{
+		{
+			name: "FIFO Sells Earliest Lots First",
+			testTrades: append(
+				threeLots[:2:2], testGainsSale("S1", testGainsDate(2024, time.March, 1), 15, 2250),
+			),
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 B1 10 1500.00 1000.00 500.00 long 0.00",
+				"S1 B2 5 750.00 600.00 150.00 short 0.00",
+			},
+		},
+		{
+			name: "LIFO Sells Latest Lots First",
+			testTrades: append(
+				threeLots[:2:2], testGainsSale("S1", testGainsDate(2024, time.March, 1), 15, 2250),
+			),
+			testMethod: LotMatchingLifo,
+			expectSummaries: []string{
+				"S1 B2 10 1500.00 1200.00 300.00 short 0.00",
+				"S1 B1 5 750.00 500.00 250.00 long 0.00",
+			},
+		},
+		{
+			name:       "HIFO Sells Highest-Cost Lots First",
+			testTrades: append(threeLots[:3:3], testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1500)),
+			testMethod: LotMatchingHifo,
+			expectSummaries: []string{
+				"S1 B2 10 1500.00 1200.00 300.00 short 0.00",
+			},
+		},
+		{
+			name:       "Specific Lot Sells Named Lots Then Earliest Lots",
+			testTrades: append(threeLots[:3:3], testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1500)),
+			testMethod: LotMatchingSpecific,
+			testSpecificLots: []SpecificLot{
+				{SaleTransactionId: "S1", LotId: "B3", Quantity: 4},
+			},
+			expectSummaries: []string{
+				"S1 B3 4 600.00 440.00 160.00 short 0.00",
+				"S1 B1 6 900.00 600.00 300.00 long 0.00",
+			},
+		},
+		{
+			name:       "Specific Lot Fails With Unknown Lot",
+			testTrades: append(threeLots[:3:3], testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1500)),
+			testMethod: LotMatchingSpecific,
+			testSpecificLots: []SpecificLot{
+				{SaleTransactionId: "S1", LotId: "B9"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Sells Lots Bought Before The Trades",
+			testTrades: []gainsTrade{
+				testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1000),
+			},
+			testOpeningLots: []gainsLot{
+				{
+					id: "lot-7", security: "AAPL", acquired: testGainsDate(2021, time.May, 1), quantity: 10,
+					costPerShare: 50,
+				},
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 lot-7 10 1000.00 500.00 500.00 long 0.00",
+			},
+		},
+		{
+			name: "Reports Unmatched Shares Without Basis",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 4, 400),
+				testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1000),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 B1 4 400.00 400.00 0.00 short 0.00",
+				"S1  6 600.00 0.00 600.00  0.00",
+			},
+		},
+		{
+			name: "Doesn't Sell Lots Bought Later",
+			testTrades: []gainsTrade{
+				testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1000),
+				testGainsBuy("B1", testGainsDate(2024, time.March, 1), 10, 900),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1  10 1000.00 0.00 1000.00  0.00",
+			},
+		},
+		{
+			name: "Wash Sale Disallows Loss And Adds It To Replacement",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 10, 1000),
+				testGainsSale("S1", testGainsDate(2024, time.February, 1), 10, 800),
+				testGainsBuy("B2", testGainsDate(2024, time.February, 15), 10, 900),
+				testGainsSale("S2", testGainsDate(2024, time.June, 3), 10, 1000),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 B1 10 800.00 1000.00 0.00 short 200.00",
+				"S2 B2 10 1000.00 1100.00 -100.00 short 0.00",
+			},
+		},
+		{
+			name: "Wash Sale Disallows Loss Only On Replaced Shares",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 10, 1000),
+				testGainsSale("S1", testGainsDate(2024, time.February, 1), 10, 800),
+				testGainsBuy("B2", testGainsDate(2024, time.February, 15), 4, 360),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 B1 10 800.00 1000.00 -120.00 short 80.00",
+			},
+		},
+		{
+			name: "Wash Sale Replaces Only Shares Still Held",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 10, 1000),
+				testGainsBuy("B2", testGainsDate(2024, time.January, 20), 10, 900),
+				testGainsSale("S1", testGainsDate(2024, time.January, 25), 6, 600),
+				testGainsSale("S2", testGainsDate(2024, time.February, 1), 10, 800),
+				testGainsSale("S3", testGainsDate(2024, time.June, 3), 4, 400),
+			},
+			testMethod: LotMatchingSpecific,
+			testSpecificLots: []SpecificLot{
+				{SaleTransactionId: "S1", LotId: "B2"},
+				{SaleTransactionId: "S2", LotId: "B1"},
+			},
+			expectSummaries: []string{
+				"S1 B2 6 600.00 540.00 60.00 short 0.00",
+				"S2 B1 10 800.00 1000.00 -120.00 short 80.00",
+				"S3 B2 4 400.00 440.00 -40.00 short 0.00",
+			},
+		},
+		{
+			name: "Purchases Outside The Window Aren't Wash Sales",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 10, 1000),
+				testGainsSale("S1", testGainsDate(2024, time.February, 1), 10, 800),
+				testGainsBuy("B2", testGainsDate(2024, time.March, 3), 10, 900),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 B1 10 800.00 1000.00 -200.00 short 0.00",
+			},
+		},
+		{
+			name: "Closes Short Lots Short-Term",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2022, time.January, 3), 10, 500),
+				testGainsShortSale("SS1", testGainsDate(2023, time.January, 3), 10, 1500),
+				testGainsCover("C1", testGainsDate(2024, time.March, 1), 10, 1200),
+				testGainsSale("S1", testGainsDate(2024, time.March, 1), 10, 1300),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"C1 SS1 10 1500.00 1200.00 300.00 short 0.00",
+				"S1 B1 10 1300.00 500.00 800.00 long 0.00",
+			},
+		},
+		{
+			name: "HIFO Closes Short Lots Sold For The Least First",
+			testTrades: []gainsTrade{
+				testGainsShortSale("SS1", testGainsDate(2024, time.January, 3), 10, 1500),
+				testGainsShortSale("SS2", testGainsDate(2024, time.January, 4), 10, 1300),
+				testGainsCover("C1", testGainsDate(2024, time.March, 1), 10, 1400),
+			},
+			testMethod: LotMatchingHifo,
+			expectSummaries: []string{
+				"C1 SS2 10 1300.00 1400.00 -100.00 short 0.00",
+			},
+		},
+		{
+			name: "Short Losses Aren't Wash Sales Or Replacements",
+			testTrades: []gainsTrade{
+				testGainsShortSale("SS1", testGainsDate(2024, time.January, 3), 10, 1000),
+				testGainsCover("C1", testGainsDate(2024, time.February, 1), 10, 1200),
+				testGainsShortSale("SS2", testGainsDate(2024, time.February, 2), 10, 1200),
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 10, 1000),
+				testGainsSale("S1", testGainsDate(2024, time.February, 1), 10, 800),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"C1 SS1 10 1000.00 1200.00 -200.00 short 0.00",
+				"S1 B1 10 800.00 1000.00 -200.00 short 0.00",
+			},
+		},
+		{
+			name: "Closes Short Lots Sold Before The Trades",
+			testTrades: []gainsTrade{
+				testGainsCover("C1", testGainsDate(2024, time.March, 1), 1, 200),
+			},
+			testOpeningLots: []gainsLot{
+				{
+					id: "lot-8", security: "AAPL", isShort: true, acquired: testGainsDate(2021, time.May, 1),
+					quantity: 1, proceedsPerShare: 500,
+				},
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"C1 lot-8 1 500.00 200.00 300.00 short 0.00",
+			},
+		},
+		{
+			name: "Fails With Unmatched Short Shares",
+			testTrades: []gainsTrade{
+				testGainsShortSale("SS1", testGainsDate(2024, time.January, 3), 5, 500),
+				testGainsCover("C1", testGainsDate(2024, time.March, 1), 10, 1200),
+			},
+			testMethod: LotMatchingFifo,
+			expectErr:  true,
+		},
+		{
+			name: "Lots Sold Together Aren't Replacements",
+			testTrades: []gainsTrade{
+				testGainsBuy("B1", testGainsDate(2024, time.January, 2), 10, 1000),
+				testGainsBuy("B2", testGainsDate(2024, time.January, 20), 10, 1000),
+				testGainsSale("S1", testGainsDate(2024, time.February, 1), 20, 1600),
+			},
+			testMethod: LotMatchingFifo,
+			expectSummaries: []string{
+				"S1 B1 10 800.00 1000.00 -200.00 short 0.00",
+				"S1 B2 10 800.00 1000.00 -200.00 short 0.00",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualGains, err := calculateRealizedGains(
+					tt.testTrades, tt.testOpeningLots, tt.testMethod, tt.testSpecificLots,
+				)
+
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectSummaries, getTestGainSummaries(actualGains))
+				}
+			},
+		)
+	}
+}
+
+func TestIsLongTermHolding(t *testing.T) {
+	tests := []struct {
+		name        string
+		testSold    time.Time
+		expectValue bool
+	}{
+		{
+			name:        "One Year Is Short Term",
+			testSold:    testGainsDate(2024, time.January, 3),
+			expectValue: false,
+		},
+		{
+			name:        "More Than One Year Is Long Term",
+			testSold:    testGainsDate(2024, time.January, 4),
+			expectValue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := isLongTermHolding(testGainsDate(2023, time.January, 3), tt.testSold)
+
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestGetForm8949Box(t *testing.T) {
+	tests := []struct {
+		name        string
+		testGain    realizedGain
+		expectValue string
+	}{
+		{
+			name:        "Covered Short-Term Stock Is Box A",
+			testGain:    realizedGain{securityType: "EQ", acquired: testGainsDate(2011, time.January, 1)},
+			expectValue: "A",
+		},
+		{
+			name:        "Noncovered Short-Term Stock Is Box B",
+			testGain:    realizedGain{securityType: "EQ", acquired: testGainsDate(2010, time.December, 31)},
+			expectValue: "B",
+		},
+		{
+			name: "Covered Long-Term Mutual Fund Is Box D",
+			testGain: realizedGain{
+				securityType: "MF", acquired: testGainsDate(2012, time.January, 1), isLongTerm: true,
+			},
+			expectValue: "D",
+		},
+		{
+			name: "Noncovered Long-Term Option Is Box E",
+			testGain: realizedGain{
+				securityType: "OPTN", acquired: testGainsDate(2013, time.December, 31), isLongTerm: true,
+			},
+			expectValue: "E",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := getForm8949Box(&tt.testGain)
+
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestParseSpecificLot(t *testing.T) {
+	tests := []struct {
+		name        string
+		testValue   string
+		expectErr   bool
+		expectValue SpecificLot
+	}{
+		{
+			name:        "Parses Lot",
+			testValue:   "123=456",
+			expectValue: SpecificLot{SaleTransactionId: "123", LotId: "456"},
+		},
+		{
+			name:        "Parses Lot With Quantity",
+			testValue:   "123=lot-456:2.5",
+			expectValue: SpecificLot{SaleTransactionId: "123", LotId: "lot-456", Quantity: 2.5},
+		},
+		{
+			name:      "Fails Without Lot",
+			testValue: "123",
+			expectErr: true,
+		},
+		{
+			name:      "Fails With Bad Quantity",
+			testValue: "123=456:0",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := ParseSpecificLot(tt.testValue)
+
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
+
+func TestGetGainsTrade(t *testing.T) {
+	tests := []struct {
+		name            string
+		testTransaction model.Transaction
+		expectOk        bool
+		expectValue     gainsTrade
+	}{
+		{
+			name: "Gets Sale",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("1"),
+				TransactionDate: testGainsDate(2024, time.March, 1).UnixMilli(),
+				Amount:          1499.5,
+				TransactionType: "Sold",
+				Brokerage: &model.Brokerage{
+					Product: &model.Product{Symbol: "aapl", SecurityType: "EQ"}, Quantity: -10, Price: 150,
+				},
+			},
+			expectOk: true,
+			expectValue: gainsTrade{
+				transactionId: "1", security: "AAPL", securityType: "EQ", date: testGainsDate(2024, time.March, 1),
+				quantity: 10, amount: 1499.5,
+			},
+		},
+		{
+			name: "Gets Option Purchase Without Amount",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("2"),
+				TransactionDate: testGainsDate(2024, time.March, 1).UnixMilli(),
+				TransactionType: "Bought To Open",
+				Brokerage: &model.Brokerage{
+					Product: &model.Product{
+						Symbol: "AAPL", SecurityType: "OPTN", CallPut: "Call", ExpiryYear: 2024, ExpiryMonth: 6,
+						ExpiryDay: 21, StrikePrice: 190,
+					},
+					Quantity: 1, Price: 500, Fee: 0.5,
+				},
+			},
+			expectOk: true,
+			expectValue: gainsTrade{
+				transactionId: "2", security: "AAPL 2024-06-21 190 CALL", securityType: "OPTN",
+				date: testGainsDate(2024, time.March, 1), isBuy: true, quantity: 1, amount: 500.5,
+			},
+		},
+		{
+			name: "Adds Fees To Gross Amount",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("6"),
+				TransactionDate: testGainsDate(2024, time.March, 1).UnixMilli(),
+				Amount:          1500,
+				TransactionType: "Sold",
+				Brokerage: &model.Brokerage{
+					Product: &model.Product{Symbol: "AAPL", SecurityType: "EQ"}, Quantity: -10, Price: 150, Fee: 0.5,
+					Commission: 4.95,
+				},
+			},
+			expectOk: true,
+			expectValue: gainsTrade{
+				transactionId: "6", security: "AAPL", securityType: "EQ", date: testGainsDate(2024, time.March, 1),
+				quantity: 10, amount: 1494.55,
+			},
+		},
+		{
+			name: "Gets Short Sale",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("4"),
+				TransactionDate: testGainsDate(2024, time.March, 1).UnixMilli(),
+				Amount:          1499.5,
+				TransactionType: "Sold Short",
+				Brokerage: &model.Brokerage{
+					Product: &model.Product{Symbol: "AAPL", SecurityType: "EQ"}, Quantity: -10, Price: 150,
+				},
+			},
+			expectOk: true,
+			expectValue: gainsTrade{
+				transactionId: "4", security: "AAPL", securityType: "EQ", date: testGainsDate(2024, time.March, 1),
+				isShort: true, quantity: 10, amount: 1499.5,
+			},
+		},
+		{
+			name: "Gets Option Purchase To Close",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("5"),
+				TransactionDate: testGainsDate(2024, time.March, 1).UnixMilli(),
+				Amount:          -200.5,
+				TransactionType: "Bought To Close",
+				Brokerage: &model.Brokerage{
+					Product: &model.Product{
+						Symbol: "AAPL", SecurityType: "OPTN", CallPut: "Put", ExpiryYear: 2024, ExpiryMonth: 6,
+						ExpiryDay: 21, StrikePrice: 170,
+					},
+					Quantity: 1, Price: 200, Fee: 0.5,
+				},
+			},
+			expectOk: true,
+			expectValue: gainsTrade{
+				transactionId: "5", security: "AAPL 2024-06-21 170 PUT", securityType: "OPTN",
+				date: testGainsDate(2024, time.March, 1), isBuy: true, isShort: true, quantity: 1, amount: 200.5,
+			},
+		},
+		{
+			name: "Ignores Dividend",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("3"),
+				TransactionType: "Dividend",
+				Amount:          12,
+				Brokerage:       &model.Brokerage{Product: &model.Product{Symbol: "AAPL"}},
+			},
+			expectOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, ok := getGainsTrade(&tt.testTransaction, time.UTC)
+
+				assert.Equal(t, tt.expectOk, ok)
+				if tt.expectOk {
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
+
+func TestApplyGainsTransactionDetails(t *testing.T) {
+	testTransaction := model.Transaction{
+		TransactionId: json.Number("1"),
+		Amount:        -1000,
+		Brokerage:     &model.Brokerage{Product: &model.Product{Symbol: "AAPL"}, Quantity: 10, Price: 100, Fee: 0.5},
+	}
+	tests := []struct {
+		name        string
+		testDetails model.Transaction
+		expectValue model.Transaction
+	}{
+		{
+			name: "Applies Amount, Fees, And Commissions",
+			testDetails: model.Transaction{
+				TransactionId: json.Number("1"),
+				Amount:        -1005.5,
+				Brokerage:     &model.Brokerage{Fee: 0.55, Commission: 4.95},
+			},
+			expectValue: model.Transaction{
+				TransactionId: json.Number("1"),
+				Amount:        -1005.5,
+				Brokerage: &model.Brokerage{
+					Product: &model.Product{Symbol: "AAPL"}, Quantity: 10, Price: 100, Fee: 0.55, Commission: 4.95,
+				},
+			},
+		},
+		{
+			name:        "Keeps Transaction Without Details",
+			testDetails: model.Transaction{TransactionId: json.Number("1")},
+			expectValue: testTransaction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := applyGainsTransactionDetails(&testTransaction, &tt.testDetails)
+
+				assert.Equal(t, &tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestGetRealizedGainsJsonMap(t *testing.T) {
+	gains := []realizedGain{
+		{
+			saleTransactionId: "S0", security: "AAPL", lotId: "B0", quantity: 1,
+			acquired: testGainsDate(2022, time.March, 1), sold: testGainsDate(2023, time.March, 1), proceeds: 10,
+			costBasis: 5, hasBasis: true,
+		},
+		{
+			saleTransactionId: "S1", security: "AAPL", lotId: "B1", quantity: 10,
+			acquired: testGainsDate(2022, time.March, 1), sold: testGainsDate(2024, time.March, 1), proceeds: 1500,
+			costBasis: 1000, hasBasis: true, isLongTerm: true,
+		},
+		{
+			saleTransactionId: "S2", security: "AAPL", lotId: "B2", quantity: 10,
+			acquired: testGainsDate(2024, time.January, 2), sold: testGainsDate(2024, time.April, 1), proceeds: 800,
+			costBasis: 1000, hasBasis: true, washSaleDisallowed: 50,
+		},
+		{
+			saleTransactionId: "S3", security: "MSFT", quantity: 2, sold: testGainsDate(2024, time.May, 1),
+			proceeds: 600,
+		},
+	}
+
+	// Call the Method Under Test
+	actualValue := getRealizedGainsJsonMap(gains, 2024)
+
+	assert.Equal(
+		t, jsonmap.JsonMap{
+			"gains": jsonmap.JsonSlice{
+				jsonmap.JsonMap{
+					"saleTransactionId": "S1", "symbol": "AAPL", "lotId": "B1", "quantity": 10.0,
+					"dateAcquired": "2022-03-01", "dateSold": "2024-03-01", "proceeds": 1500.0, "costBasis": 1000.0,
+					"gain": 500.0, "term": "long", "washSale": false, "washSaleLossDisallowed": 0.0, "form8949Box": "D",
+				},
+				jsonmap.JsonMap{
+					"saleTransactionId": "S2", "symbol": "AAPL", "lotId": "B2", "quantity": 10.0,
+					"dateAcquired": "2024-01-02", "dateSold": "2024-04-01", "proceeds": 800.0, "costBasis": 1000.0,
+					"gain": -150.0, "term": "short", "washSale": true, "washSaleLossDisallowed": 50.0,
+					"form8949Box": "A",
+				},
+				jsonmap.JsonMap{
+					"saleTransactionId": "S3", "symbol": "MSFT", "lotId": "", "quantity": 2.0,
+					"dateSold": "2024-05-01", "proceeds": 600.0, "washSale": false, "washSaleLossDisallowed": 0.0,
+				},
+			},
+			"totals": jsonmap.JsonMap{
+				"proceeds": 2900.0, "costBasis": 2000.0, "washSaleLossDisallowed": 50.0, "shortTermGain": -150.0,
+				"longTermGain": 500.0, "totalGain": 350.0,
+			},
+		}, actualValue,
+	)
+}
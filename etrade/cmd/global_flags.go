@@ -1,10 +1,15 @@
 package cmd
 
+import "time"
+
 type globalFlags struct {
 	customerId     string
 	debug          bool
 	outputFileName string
 	outputFormat   enumFlagValue[outputFormat]
+	recordDir      string
+	replayDir      string
+	timeout        time.Duration
 }
 
 type outputFormat int
@@ -13,4 +18,7 @@ const (
 	outputFormatCsv = iota
 	outputFormatJson
 	outputFormatJsonPretty
+	outputFormatForm8949
+	outputFormatTxf
+	outputFormatScheduleD
 )
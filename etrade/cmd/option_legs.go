@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"strconv"
+	"strings"
+)
+
+// parseOptionLeg parses an option leg given on the command line in the form
+// ACTION:QUANTITY:OSIKEY (e.g. "buyOpen:1:ABC---240119C00150000").
+func parseOptionLeg(legString string) (etradelib.ETradeOptionLeg, error) {
+	parts := strings.SplitN(legString, ":", 3)
+	if len(parts) != 3 {
+		return etradelib.ETradeOptionLeg{}, fmt.Errorf(
+			"option leg '%s' must be in the form ACTION:QUANTITY:OSIKEY", legString,
+		)
+	}
+	orderAction, err := optionOrderActionMap.GetEnumValue(parts[0])
+	if err != nil {
+		return etradelib.ETradeOptionLeg{}, fmt.Errorf(
+			"option leg '%s' has an invalid action (%w)", legString, err,
+		)
+	}
+	quantity, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return etradelib.ETradeOptionLeg{}, fmt.Errorf(
+			"option leg '%s' has an invalid quantity (%w)", legString, err,
+		)
+	}
+	contract, err := etradelib.ParseOsiKey(parts[2])
+	if err != nil {
+		return etradelib.ETradeOptionLeg{}, err
+	}
+	return etradelib.ETradeOptionLeg{
+		Contract:    contract,
+		OrderAction: orderAction,
+		Quantity:    quantity,
+	}, nil
+}
+
+func parseOptionLegs(legStrings []string) ([]etradelib.ETradeOptionLeg, error) {
+	legs := make([]etradelib.ETradeOptionLeg, 0, len(legStrings))
+	for _, legString := range legStrings {
+		leg, err := parseOptionLeg(legString)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseOptionLegs(t *testing.T) {
+	tests := []struct {
+		name        string
+		legStrings  []string
+		expectErr   bool
+		expectValue []etradelib.ETradeOptionLeg
+	}{
+		{
+			name:       "Parses Legs",
+			legStrings: []string{"buyOpen:1:ABC---240119C00150000", "sellOpen:1:ABC---240119C00155000"},
+			expectErr:  false,
+			expectValue: []etradelib.ETradeOptionLeg{
+				{
+					Contract: etradelib.ETradeOptionContract{
+						Underlying:  "ABC",
+						CallPut:     constants.OptionTypeCall,
+						ExpiryDate:  time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+						StrikePrice: 150,
+					},
+					OrderAction: constants.OrderActionBuyOpen,
+					Quantity:    1,
+				},
+				{
+					Contract: etradelib.ETradeOptionContract{
+						Underlying:  "ABC",
+						CallPut:     constants.OptionTypeCall,
+						ExpiryDate:  time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+						StrikePrice: 155,
+					},
+					OrderAction: constants.OrderActionSellOpen,
+					Quantity:    1,
+				},
+			},
+		},
+		{
+			name:       "Fails With Missing Parts",
+			legStrings: []string{"buyOpen:ABC---240119C00150000"},
+			expectErr:  true,
+		},
+		{
+			name:       "Fails With Bad Action",
+			legStrings: []string{"buy:1:ABC---240119C00150000"},
+			expectErr:  true,
+		},
+		{
+			name:       "Fails With Bad Quantity",
+			legStrings: []string{"buyOpen:one:ABC---240119C00150000"},
+			expectErr:  true,
+		},
+		{
+			name:       "Fails With Bad OSI Key",
+			legStrings: []string{"buyOpen:1:ABC"},
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := parseOptionLegs(tt.legStrings)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/spf13/cobra"
+)
+
+// equityOrderFlags holds the flags that describe an equity order. The same
+// flags are used to preview an order and to place it, since ETrade requires
+// the placed order to match the previewed order.
+type equityOrderFlags struct {
+	quantity      int64
+	limitPrice    float64
+	stopPrice     float64
+	allOrNone     bool
+	clientOrderId string
+	orderAction   enumFlagValue[constants.OrderAction]
+	priceType     enumFlagValue[constants.OrderPriceType]
+	orderTerm     enumFlagValue[constants.OrderTerm]
+	marketSession enumFlagValue[constants.MarketSession]
+}
+
+func (f *equityOrderFlags) addFlags(cmd *cobra.Command) {
+	// Add Flags
+	cmd.Flags().Int64VarP(&f.quantity, "quantity", "q", 0, "number of shares")
+	cmd.Flags().Float64VarP(&f.limitPrice, "limit-price", "l", 0, "limit price (for limit and stop-limit orders)")
+	cmd.Flags().Float64VarP(&f.stopPrice, "stop-price", "s", 0, "stop price (for stop and stop-limit orders)")
+	cmd.Flags().BoolVar(&f.allOrNone, "all-or-none", false, "fill the entire order or none of it")
+	cmd.Flags().StringVarP(
+		&f.clientOrderId, "client-order-id", "i", "",
+		fmt.Sprintf("client order ID (up to %d characters)", constants.OrderClientIdMaxLength),
+	)
+	_ = cmd.MarkFlagRequired("quantity")
+
+	// Initialize Enum Flag Values
+	f.orderAction = *newEnumFlagValue(orderActionMap, constants.OrderActionNil)
+	f.priceType = *newEnumFlagValue(orderPriceTypeMap, constants.OrderPriceTypeMarket)
+	f.orderTerm = *newEnumFlagValue(orderTermMap, constants.OrderTermGoodForDay)
+	f.marketSession = *newEnumFlagValue(marketSessionMap, constants.MarketSessionRegular)
+
+	// Add Enum Flags
+	cmd.Flags().VarP(
+		&f.orderAction, "action", "a",
+		fmt.Sprintf("order action (%s)", f.orderAction.JoinAllowedValues(", ")),
+	)
+	_ = cmd.MarkFlagRequired("action")
+	_ = cmd.RegisterFlagCompletionFunc(
+		"action",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.orderAction.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.priceType, "price-type", "p",
+		fmt.Sprintf("price type (%s)", f.priceType.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"price-type",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.priceType.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.orderTerm, "term", "t",
+		fmt.Sprintf("order term (%s)", f.orderTerm.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"term",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.orderTerm.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.marketSession, "market-session", "m",
+		fmt.Sprintf("market session (%s)", f.marketSession.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"market-session",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.marketSession.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+}
+
+func (f *equityOrderFlags) createRequest(symbol string) (etradelib.ETradeOrderRequest, error) {
+	return etradelib.CreateETradeEquityOrderRequest(
+		f.clientOrderId, symbol, f.orderAction.Value(), f.quantity, f.priceType.Value(), f.limitPrice, f.stopPrice,
+		f.orderTerm.Value(), f.marketSession.Value(), f.allOrNone,
+	)
+}
+
+// changeOrderFlags holds the flags that describe changes to an open order.
+// Flags that are not provided leave the corresponding part of the order
+// unchanged.
+type changeOrderFlags struct {
+	quantity      int64
+	limitPrice    float64
+	stopPrice     float64
+	clientOrderId string
+	priceType     enumFlagValue[constants.OrderPriceType]
+	orderTerm     enumFlagValue[constants.OrderTerm]
+}
+
+func (f *changeOrderFlags) addFlags(cmd *cobra.Command) {
+	// Add Flags
+	cmd.Flags().Int64VarP(&f.quantity, "quantity", "q", 0, "new number of shares")
+	cmd.Flags().Float64VarP(&f.limitPrice, "limit-price", "l", 0, "new limit price")
+	cmd.Flags().Float64VarP(&f.stopPrice, "stop-price", "s", 0, "new stop price")
+	cmd.Flags().StringVarP(
+		&f.clientOrderId, "client-order-id", "i", "",
+		fmt.Sprintf("client order ID (up to %d characters)", constants.OrderClientIdMaxLength),
+	)
+
+	// Initialize Enum Flag Values
+	f.priceType = *newEnumFlagValue(orderPriceTypeMap, constants.OrderPriceTypeNil)
+	f.orderTerm = *newEnumFlagValue(orderTermMap, constants.OrderTermNil)
+
+	// Add Enum Flags
+	cmd.Flags().VarP(
+		&f.priceType, "price-type", "p",
+		fmt.Sprintf("new price type (%s)", f.priceType.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"price-type",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.priceType.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.orderTerm, "term", "t",
+		fmt.Sprintf("new order term (%s)", f.orderTerm.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"term",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.orderTerm.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+}
+
+func (f *changeOrderFlags) changes() etradelib.ETradeOrderChanges {
+	return etradelib.ETradeOrderChanges{
+		Quantity:   f.quantity,
+		PriceType:  f.priceType.Value(),
+		LimitPrice: f.limitPrice,
+		StopPrice:  f.stopPrice,
+		OrderTerm:  f.orderTerm.Value(),
+	}
+}
+
+// spreadOrderFlags holds the flags that describe a multi-leg option order.
+// The legs themselves are given as arguments.
+type spreadOrderFlags struct {
+	limitPrice    float64
+	allOrNone     bool
+	clientOrderId string
+	orderType     enumFlagValue[constants.OrderType]
+	priceType     enumFlagValue[constants.OrderPriceType]
+	orderTerm     enumFlagValue[constants.OrderTerm]
+	marketSession enumFlagValue[constants.MarketSession]
+}
+
+func (f *spreadOrderFlags) addFlags(cmd *cobra.Command) {
+	// Add Flags
+	cmd.Flags().Float64VarP(
+		&f.limitPrice, "limit-price", "l", 0, "net limit price (for netDebit and netCredit orders)",
+	)
+	cmd.Flags().BoolVar(&f.allOrNone, "all-or-none", false, "fill the entire order or none of it")
+	cmd.Flags().StringVarP(
+		&f.clientOrderId, "client-order-id", "i", "",
+		fmt.Sprintf("client order ID (up to %d characters)", constants.OrderClientIdMaxLength),
+	)
+
+	// Initialize Enum Flag Values
+	f.orderType = *newEnumFlagValue(spreadOrderTypeMap, constants.OrderTypeNil)
+	f.priceType = *newEnumFlagValue(spreadPriceTypeMap, constants.OrderPriceTypeNil)
+	f.orderTerm = *newEnumFlagValue(orderTermMap, constants.OrderTermGoodForDay)
+	f.marketSession = *newEnumFlagValue(marketSessionMap, constants.MarketSessionRegular)
+
+	// Add Enum Flags
+	cmd.Flags().VarP(
+		&f.orderType, "order-type", "y",
+		fmt.Sprintf(
+			"order type (%s); inferred from the legs if omitted", f.orderType.JoinAllowedValues(", "),
+		),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"order-type",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.orderType.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.priceType, "price-type", "p",
+		fmt.Sprintf("price type (%s)", f.priceType.JoinAllowedValues(", ")),
+	)
+	_ = cmd.MarkFlagRequired("price-type")
+	_ = cmd.RegisterFlagCompletionFunc(
+		"price-type",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.priceType.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.orderTerm, "term", "t",
+		fmt.Sprintf("order term (%s)", f.orderTerm.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"term",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.orderTerm.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+
+	cmd.Flags().VarP(
+		&f.marketSession, "market-session", "m",
+		fmt.Sprintf("market session (%s)", f.marketSession.JoinAllowedValues(", ")),
+	)
+	_ = cmd.RegisterFlagCompletionFunc(
+		"market-session",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return f.marketSession.AllowedValuesWithHelp(), cobra.ShellCompDirectiveDefault
+		},
+	)
+}
+
+func (f *spreadOrderFlags) createRequest(legStrings []string) (etradelib.ETradeOrderRequest, error) {
+	legs, err := parseOptionLegs(legStrings)
+	if err != nil {
+		return nil, err
+	}
+	orderType := f.orderType.Value()
+	if orderType == constants.OrderTypeNil {
+		orderType = etradelib.InferOptionOrderType(legs)
+	}
+	return etradelib.CreateETradeOptionOrderRequest(
+		f.clientOrderId, orderType, legs, f.priceType.Value(), f.limitPrice, 0, f.orderTerm.Value(),
+		f.marketSession.Value(), f.allOrNone,
+	)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"strings"
+	"time"
+)
+
+// orderPolicyClient wraps an ETradeClient and evaluates every order request
+// against a customer's order policy before it is previewed or placed. Orders
+// that violate the policy are never sent to ETrade; an
+// *etradelib.ETradeOrderPolicyViolationError is returned instead.
+type orderPolicyClient struct {
+	client.ETradeClient
+	policy *etradelib.ETradeOrderPolicy
+	now    func() time.Time
+}
+
+func newOrderPolicyClient(eTradeClient client.ETradeClient, policy *etradelib.ETradeOrderPolicy) client.ETradeClient {
+	return &orderPolicyClient{
+		ETradeClient: eTradeClient,
+		policy:       policy,
+		now:          time.Now,
+	}
+}
+
+func (c *orderPolicyClient) WithContext(ctx context.Context) client.ETradeClient {
+	return &orderPolicyClient{
+		ETradeClient: c.ETradeClient.WithContext(ctx),
+		policy:       c.policy,
+		now:          c.now,
+	}
+}
+
+func (c *orderPolicyClient) WithRequestTimeout(timeout time.Duration) client.ETradeClient {
+	return &orderPolicyClient{
+		ETradeClient: c.ETradeClient.WithRequestTimeout(timeout),
+		policy:       c.policy,
+		now:          c.now,
+	}
+}
+
+func (c *orderPolicyClient) PreviewOrder(accountIdKey string, request jsonmap.JsonMap) ([]byte, error) {
+	if err := c.evaluate(accountIdKey, request); err != nil {
+		return nil, err
+	}
+	return c.ETradeClient.PreviewOrder(accountIdKey, request)
+}
+
+func (c *orderPolicyClient) PlaceOrder(accountIdKey string, previewId int64, request jsonmap.JsonMap) (
+	[]byte, error,
+) {
+	if err := c.evaluate(accountIdKey, request); err != nil {
+		return nil, err
+	}
+	return c.ETradeClient.PlaceOrder(accountIdKey, previewId, request)
+}
+
+func (c *orderPolicyClient) PreviewChangedOrder(accountIdKey string, orderId int64, request jsonmap.JsonMap) (
+	[]byte, error,
+) {
+	if err := c.evaluate(accountIdKey, request); err != nil {
+		return nil, err
+	}
+	return c.ETradeClient.PreviewChangedOrder(accountIdKey, orderId, request)
+}
+
+func (c *orderPolicyClient) PlaceChangedOrder(
+	accountIdKey string, orderId int64, previewId int64, request jsonmap.JsonMap,
+) ([]byte, error) {
+	if err := c.evaluate(accountIdKey, request); err != nil {
+		return nil, err
+	}
+	return c.ETradeClient.PlaceChangedOrder(accountIdKey, orderId, previewId, request)
+}
+
+func (c *orderPolicyClient) evaluate(accountIdKey string, request jsonmap.JsonMap) error {
+	marketData := etradelib.ETradeOrderPolicyMarketData{
+		Now:        c.now(),
+		LastPrices: map[string]float64{},
+		SharesHeld: map[string]float64{},
+	}
+
+	symbols, err := c.policy.GetPriceSymbols(request)
+	if err != nil {
+		return err
+	}
+	if len(symbols) > 0 {
+		if marketData.LastPrices, err = c.getLastPrices(symbols); err != nil {
+			return err
+		}
+	}
+
+	needsSharesHeld, err := c.policy.NeedsSharesHeld(request)
+	if err != nil {
+		return err
+	}
+	if needsSharesHeld {
+		if marketData.SharesHeld, err = c.getSharesHeld(accountIdKey); err != nil {
+			return err
+		}
+	}
+	return c.policy.Evaluate(request, &marketData)
+}
+
+func (c *orderPolicyClient) getLastPrices(symbols []string) (map[string]float64, error) {
+	response, err := c.ETradeClient.GetQuotes(symbols, constants.QuoteDetailFlagIntraday, false, false)
+	if err != nil {
+		return nil, err
+	}
+	quoteList, err := etradelib.CreateETradeQuoteListFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	lastPrices := map[string]float64{}
+	for _, quote := range quoteList.GetAllQuotes() {
+		quoteMap := quote.AsJsonMap()
+		symbol, err := quoteMap.GetStringAtPath(".product.symbol")
+		if err != nil {
+			return nil, err
+		}
+		lastTrade, err := quoteMap.GetFloatAtPath(".intraday.lastTrade")
+		if err != nil {
+			return nil, err
+		}
+		lastPrices[symbol] = lastTrade
+	}
+	return lastPrices, nil
+}
+
+func (c *orderPolicyClient) getSharesHeld(accountIdKey string) (map[string]float64, error) {
+	const countPerRequest = constants.PortfolioMaxCount
+	response, err := c.ETradeClient.ViewPortfolio(
+		accountIdKey, countPerRequest, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+		constants.MarketSessionNil, false, false, constants.PortfolioViewNil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	positionList, err := etradelib.CreateETradePositionListFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	for positionList.NextPage() != "" {
+		response, err = c.ETradeClient.ViewPortfolio(
+			accountIdKey, countPerRequest, constants.PortfolioSortByNil, constants.SortOrderNil,
+			positionList.NextPage(), constants.MarketSessionNil, false, false, constants.PortfolioViewNil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err = positionList.AddPageFromResponse(response); err != nil {
+			return nil, err
+		}
+	}
+
+	sharesHeld := map[string]float64{}
+	for _, position := range positionList.GetAllPositions() {
+		positionMap := position.AsJsonMap()
+		securityType, err := positionMap.GetStringAtPathWithDefault(".product.securityType", "")
+		if err != nil {
+			return nil, err
+		}
+		if securityType != constants.OrderSecurityTypeEquity.String() {
+			continue
+		}
+		symbol, err := positionMap.GetStringAtPath(".product.symbol")
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := positionMap.GetFloatAtPath(".quantity")
+		if err != nil {
+			return nil, err
+		}
+		// Short positions have negative quantities and can't cover calls.
+		if quantity > 0 {
+			sharesHeld[strings.ToUpper(symbol)] += quantity
+		}
+	}
+	return sharesHeld, nil
+}
+
+// renderOrderError renders the explanation of an order policy violation, if
+// err is one, and then returns err so that the command still fails.
+func renderOrderError(renderer Renderer, err error) error {
+	var violationError *etradelib.ETradeOrderPolicyViolationError
+	if errors.As(err, &violationError) {
+		if renderErr := renderer.Render(violationError.AsJsonMap(), orderPolicyViolationDescriptor); renderErr != nil {
+			return renderErr
+		}
+	}
+	return err
+}
+
+var orderPolicyViolationDescriptor = []RenderDescriptor{
+	{
+		ObjectPath: "",
+		Values: []RenderValue{
+			{Header: "Status", Path: ".status"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   true,
+	},
+	{
+		ObjectPath: ".violations",
+		Values: []RenderValue{
+			{Header: "Rule", Path: ".rule"},
+			{Header: "Message", Path: ".message"},
+		},
+		DefaultValue: "",
+		SpaceAfter:   false,
+	},
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestOrderPolicyClient(t *testing.T) {
+	// A Wednesday at 10:00am Eastern
+	testNow := time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+	testPreviewResponse := []byte(`{"PreviewOrderResponse": {}}`)
+	testQuoteResponse := []byte(`
+{
+  "QuoteResponse": {
+    "QuoteData": [
+      {
+        "Intraday": {
+          "lastTrade": 101
+        },
+        "Product": {
+          "symbol": "ABC",
+          "securityType": "EQ"
+        }
+      }
+    ]
+  }
+}`)
+	testPortfolioResponse := []byte(`
+{
+  "PortfolioResponse": {
+    "AccountPortfolio": [
+      {
+        "Position": [
+          {
+            "positionId": 1,
+            "Product": {
+              "symbol": "ABC",
+              "securityType": "EQ"
+            },
+            "quantity": 200
+          },
+          {
+            "positionId": 2,
+            "Product": {
+              "symbol": "XYZ",
+              "securityType": "EQ"
+            },
+            "quantity": -500
+          }
+        ]
+      }
+    ]
+  }
+}`)
+	marketRequest, err := etradelib.CreateETradeEquityOrderRequest(
+		"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+		constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+	shortCallContract, err := etradelib.ParseOsiKey("ABC---240119C00150000")
+	require.Nil(t, err)
+	shortCallRequest, err := etradelib.CreateETradeOptionOrderRequest(
+		"TestId", constants.OrderTypeOption,
+		[]etradelib.ETradeOptionLeg{
+			{Contract: shortCallContract, OrderAction: constants.OrderActionSellOpen, Quantity: 2},
+		},
+		constants.OrderPriceTypeLimit, 1, 0, constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+
+	type testFn func(mockClient *client.ETradeClientMock) ([]byte, error)
+	tests := []struct {
+		name             string
+		testFn           testFn
+		expectErr        bool
+		expectViolations []etradelib.ETradeOrderPolicyViolation
+		expectValue      []byte
+	}{
+		{
+			name: "Previews Order Within Policy",
+			testFn: func(mockClient *client.ETradeClientMock) ([]byte, error) {
+				mockClient.On(
+					"GetQuotes", []string{"ABC"}, constants.QuoteDetailFlagIntraday, false, false,
+				).Return(testQuoteResponse, nil)
+				mockClient.On("PreviewOrder", "test key", marketRequest.AsJsonMap()).Return(
+					testPreviewResponse, nil,
+				)
+				return newTestOrderPolicyClient(mockClient, etradelib.ETradeOrderPolicy{MaxNotional: 1100}, testNow).
+					PreviewOrder("test key", marketRequest.AsJsonMap())
+			},
+			expectErr:   false,
+			expectValue: testPreviewResponse,
+		},
+		{
+			name: "Blocks Order Over Max Notional",
+			testFn: func(mockClient *client.ETradeClientMock) ([]byte, error) {
+				mockClient.On(
+					"GetQuotes", []string{"ABC"}, constants.QuoteDetailFlagIntraday, false, false,
+				).Return(testQuoteResponse, nil)
+				return newTestOrderPolicyClient(mockClient, etradelib.ETradeOrderPolicy{MaxNotional: 1000}, testNow).
+					PlaceOrder("test key", 1234, marketRequest.AsJsonMap())
+			},
+			expectErr: true,
+			expectViolations: []etradelib.ETradeOrderPolicyViolation{
+				{Rule: "maxNotional", Message: "order notional value of $1010.00 exceeds the maximum of $1000.00"},
+			},
+			expectValue: nil,
+		},
+		{
+			name: "Allows Short Call Covered By Shares",
+			testFn: func(mockClient *client.ETradeClientMock) ([]byte, error) {
+				mockClient.On(
+					"ViewPortfolio", "test key", constants.PortfolioMaxCount, constants.PortfolioSortByNil,
+					constants.SortOrderNil, "", constants.MarketSessionNil, false, false, constants.PortfolioViewNil,
+				).Return(testPortfolioResponse, nil)
+				mockClient.On("PreviewChangedOrder", "test key", int64(5), shortCallRequest.AsJsonMap()).Return(
+					testPreviewResponse, nil,
+				)
+				return newTestOrderPolicyClient(
+					mockClient, etradelib.ETradeOrderPolicy{NoNakedShortOptions: true}, testNow,
+				).PreviewChangedOrder("test key", 5, shortCallRequest.AsJsonMap())
+			},
+			expectErr:   false,
+			expectValue: testPreviewResponse,
+		},
+		{
+			name: "Blocks Changed Order With Denied Symbol",
+			testFn: func(mockClient *client.ETradeClientMock) ([]byte, error) {
+				return newTestOrderPolicyClient(
+					mockClient, etradelib.ETradeOrderPolicy{DenySymbols: []string{"ABC"}}, testNow,
+				).PlaceChangedOrder("test key", 5, 1234, shortCallRequest.AsJsonMap())
+			},
+			expectErr: true,
+			expectViolations: []etradelib.ETradeOrderPolicyViolation{
+				{Rule: "denySymbols", Message: "ABC is in the list of denied symbols"},
+			},
+			expectValue: nil,
+		},
+		{
+			name: "Fails On GetQuotes Error",
+			testFn: func(mockClient *client.ETradeClientMock) ([]byte, error) {
+				mockClient.On(
+					"GetQuotes", []string{"ABC"}, constants.QuoteDetailFlagIntraday, false, false,
+				).Return([]byte{}, errors.New("test error"))
+				return newTestOrderPolicyClient(mockClient, etradelib.ETradeOrderPolicy{MaxNotional: 1000}, testNow).
+					PreviewOrder("test key", marketRequest.AsJsonMap())
+			},
+			expectErr:        true,
+			expectViolations: nil,
+			expectValue:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := client.ETradeClientMock{}
+				// Call the Method Under Test
+				actualValue, err := tt.testFn(&mockClient)
+				if tt.expectErr {
+					assert.Error(t, err)
+					var violationError *etradelib.ETradeOrderPolicyViolationError
+					if tt.expectViolations == nil {
+						assert.False(t, errors.As(err, &violationError))
+					} else {
+						require.True(t, errors.As(err, &violationError))
+						assert.Equal(t, tt.expectViolations, violationError.Violations)
+					}
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func TestOrderPolicyClient_WithContext(t *testing.T) {
+	clientMock := new(client.ETradeClientMock)
+	policyClient := newOrderPolicyClient(clientMock, &etradelib.ETradeOrderPolicy{MaxShares: 1})
+	limitRequest, err := etradelib.CreateETradeEquityOrderRequest(
+		"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeLimit, 100, 0,
+		constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	contextClient := policyClient.WithContext(context.Background())
+	// The policy is still enforced, so the mock isn't called.
+	_, err = contextClient.PreviewOrder("1234", limitRequest.AsJsonMap())
+	var violationError *etradelib.ETradeOrderPolicyViolationError
+	assert.ErrorAs(t, err, &violationError)
+	clientMock.AssertExpectations(t)
+}
+
+func TestOrderPolicyClient_WithRequestTimeout(t *testing.T) {
+	clientMock := new(client.ETradeClientMock)
+	policyClient := newOrderPolicyClient(clientMock, &etradelib.ETradeOrderPolicy{MaxShares: 1})
+	limitRequest, err := etradelib.CreateETradeEquityOrderRequest(
+		"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeLimit, 100, 0,
+		constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	timeoutClient := policyClient.WithRequestTimeout(time.Second)
+	// The policy is still enforced, so the mock isn't called.
+	_, err = timeoutClient.PreviewOrder("1234", limitRequest.AsJsonMap())
+	var violationError *etradelib.ETradeOrderPolicyViolationError
+	assert.ErrorAs(t, err, &violationError)
+	clientMock.AssertExpectations(t)
+}
+
+func TestRenderOrderError(t *testing.T) {
+	violationError := &etradelib.ETradeOrderPolicyViolationError{
+		Violations: []etradelib.ETradeOrderPolicyViolation{
+			{Rule: "maxShares", Message: "too many shares"},
+		},
+	}
+	otherError := errors.New("test error")
+
+	tests := []struct {
+		name         string
+		err          error
+		expectRender bool
+	}{
+		{
+			name:         "Renders Policy Violation",
+			err:          violationError,
+			expectRender: true,
+		},
+		{
+			name:         "Does Not Render Other Errors",
+			err:          otherError,
+			expectRender: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				renderer := &testOrderErrorRenderer{}
+				// Call the Method Under Test
+				err := renderOrderError(renderer, tt.err)
+				assert.Equal(t, tt.err, err)
+				if tt.expectRender {
+					assert.Equal(t, []jsonmap.JsonMap{violationError.AsJsonMap()}, renderer.rendered)
+				} else {
+					assert.Nil(t, renderer.rendered)
+				}
+			},
+		)
+	}
+}
+
+func newTestOrderPolicyClient(
+	mockClient *client.ETradeClientMock, policy etradelib.ETradeOrderPolicy, now time.Time,
+) client.ETradeClient {
+	return &orderPolicyClient{
+		ETradeClient: mockClient,
+		policy:       &policy,
+		now:          func() time.Time { return now },
+	}
+}
+
+type testOrderErrorRenderer struct {
+	rendered []jsonmap.JsonMap
+}
+
+func (r *testOrderErrorRenderer) Render(value jsonmap.JsonMap, _ []RenderDescriptor) error {
+	r.rendered = append(r.rendered, value)
+	return nil
+}
+
+func (r *testOrderErrorRenderer) Close() error {
+	return nil
+}
@@ -0,0 +1,419 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// orderWatchStatuses are the order statuses that an order watcher lists.
+// Partially-filled orders may be open or have individual fills.
+var orderWatchStatuses = []constants.OrderStatus{
+	constants.OrderStatusOpen,
+	constants.OrderStatusIndividualFills,
+	constants.OrderStatusExecuted,
+	constants.OrderStatusCanceled,
+	constants.OrderStatusExpired,
+	constants.OrderStatusRejected,
+}
+
+// The events that an order watcher emits.
+const (
+	orderEventFilled          = "filled"
+	orderEventPartiallyFilled = "partiallyFilled"
+	orderEventCancelled       = "cancelled"
+	orderEventExpired         = "expired"
+	orderEventRejected        = "rejected"
+)
+
+// orderEventMaxQueued is the most events that are queued for a webhook that
+// can't be reached. Once it's reached, the oldest events are dropped.
+const orderEventMaxQueued = 1000
+
+// errOrderEventQueued is wrapped by the error for an event that some webhooks
+// didn't receive. The event is queued for those webhooks and retried, so it
+// doesn't need to be emitted again.
+var errOrderEventQueued = errors.New("queued for retry")
+
+// orderWatchSecretEnvironmentVariable is the environment variable that
+// provides the webhook secret when it isn't given with a flag, so that the
+// secret doesn't appear in the process list.
+const orderWatchSecretEnvironmentVariable = "ETRADE_WEBHOOK_SECRET"
+
+// orderSnapshot is the state of an order when it was last listed.
+type orderSnapshot struct {
+	status          string
+	orderedQuantity float64
+	filledQuantity  float64
+}
+
+type orderWatchKey struct {
+	accountId string
+	orderId   int64
+}
+
+// orderWatcher polls a customer's orders and emits an event when an order
+// fills, partially fills, or is cancelled, expires, or is rejected. The
+// first complete listing of an account's orders is its baseline, so orders
+// that changed before the watcher started don't emit events. Orders that are
+// no longer listed are forgotten.
+type orderWatcher struct {
+	customerId string
+	getClient  func() (client.ETradeClient, error)
+	accountIds []string
+	emit       func(event jsonmap.JsonMap) error
+	retry      func() error
+	now        func() time.Time
+
+	orders      map[orderWatchKey]orderSnapshot
+	hasBaseline map[string]bool
+}
+
+// newOrderWatcher creates an order watcher for the customer's accounts with
+// the IDs, or for all the customer's accounts if accountIds is empty. The
+// watcher gets the customer's ETrade client with getClient, emits its events
+// with emit, and, if retry isn't nil, calls it on each poll to retry the
+// events that emit queued.
+func newOrderWatcher(
+	customerId string, getClient func() (client.ETradeClient, error), accountIds []string,
+	emit func(event jsonmap.JsonMap) error, retry func() error, now func() time.Time,
+) *orderWatcher {
+	return &orderWatcher{
+		customerId:  customerId,
+		getClient:   getClient,
+		accountIds:  accountIds,
+		emit:        emit,
+		retry:       retry,
+		now:         now,
+		orders:      map[orderWatchKey]orderSnapshot{},
+		hasBaseline: map[string]bool{},
+	}
+}
+
+// Run polls every interval until the context is canceled. Polling errors are
+// logged, and polling continues.
+func (w *orderWatcher) Run(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(); err != nil && ctx.Err() == nil {
+			logger.Error(fmt.Errorf("watching orders for customer %s failed (%w)", w.customerId, err).Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll retries queued events, then lists the orders in the watched accounts
+// and emits events for the orders that changed since the previous poll. An
+// account whose orders couldn't all be listed still emits events for the
+// orders that were listed; the errors are returned together.
+func (w *orderWatcher) Poll() error {
+	var errs []error
+	if w.retry != nil {
+		if err := w.retry(); err != nil {
+			errs = append(errs, fmt.Errorf("retrying queued events failed (%w)", err))
+		}
+	}
+	eTradeClient, err := w.getClient()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	accounts, err := w.getAccounts(eTradeClient)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, account := range accounts {
+		accountErrs := w.pollAccount(eTradeClient, account)
+		errs = append(errs, accountErrs...)
+	}
+	return errors.Join(errs...)
+}
+
+func (w *orderWatcher) getAccounts(eTradeClient client.ETradeClient) ([]etradelib.ETradeAccount, error) {
+	response, err := eTradeClient.ListAccounts()
+	if err != nil {
+		return nil, err
+	}
+	accountList, err := etradelib.CreateETradeAccountListFromResponse(response)
+	if err != nil {
+		return nil, err
+	}
+	if len(w.accountIds) == 0 {
+		return accountList.GetAllAccounts(), nil
+	}
+	accounts := make([]etradelib.ETradeAccount, 0, len(w.accountIds))
+	for _, accountId := range w.accountIds {
+		account := accountList.GetAccountById(accountId)
+		if account == nil {
+			return nil, fmt.Errorf("account with id %s not found", accountId)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func (w *orderWatcher) pollAccount(eTradeClient client.ETradeClient, account etradelib.ETradeAccount) []error {
+	var errs []error
+	orders := map[int64]etradelib.ETradeOrder{}
+	snapshots := map[int64]orderSnapshot{}
+	isComplete := true
+	for _, status := range orderWatchStatuses {
+		orderList, err := listOrdersForAccountIdKey(
+			eTradeClient, account.GetIdKey(), status, nil, nil, nil, constants.OrderSecurityTypeNil,
+			constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+		)
+		if err != nil {
+			errs = append(
+				errs, fmt.Errorf("listing %s orders for account %s failed (%w)", status, account.GetId(), err),
+			)
+			isComplete = false
+			continue
+		}
+		for _, order := range orderList.GetAllOrders() {
+			snapshot, err := getOrderSnapshot(order)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("order %d in account %s: %w", order.GetId(), account.GetId(), err))
+				continue
+			}
+			// An order that changed while the lists were being retrieved may
+			// be in two of them. Its final status is the latest.
+			if existing, ok := snapshots[order.GetId()]; ok && isFinalOrderStatus(existing.status) {
+				continue
+			}
+			orders[order.GetId()] = order
+			snapshots[order.GetId()] = snapshot
+		}
+	}
+
+	orderIds := make([]int64, 0, len(snapshots))
+	for orderId := range snapshots {
+		orderIds = append(orderIds, orderId)
+	}
+	sort.Slice(orderIds, func(i, j int) bool { return orderIds[i] < orderIds[j] })
+	hasBaseline := w.hasBaseline[account.GetId()]
+	for _, orderId := range orderIds {
+		key := orderWatchKey{accountId: account.GetId(), orderId: orderId}
+		previous, hasPrevious := w.orders[key]
+		current := snapshots[orderId]
+		if !hasBaseline {
+			w.orders[key] = current
+			continue
+		}
+		var previousPtr *orderSnapshot
+		if hasPrevious {
+			previousPtr = &previous
+		}
+		eventType := getOrderEventType(previousPtr, current)
+		if eventType == "" {
+			w.orders[key] = current
+			continue
+		}
+		event := jsonmap.JsonMap{
+			"event":                  eventType,
+			"customerId":             w.customerId,
+			"accountId":              account.GetId(),
+			"orderId":                orderId,
+			"status":                 current.status,
+			"previousStatus":         previous.status,
+			"orderedQuantity":        current.orderedQuantity,
+			"filledQuantity":         current.filledQuantity,
+			"previousFilledQuantity": previous.filledQuantity,
+			"time":                   w.now().Format(time.RFC3339),
+			"order":                  orders[orderId].AsJsonMap(),
+		}
+		// The order keeps its previous snapshot until its event is emitted,
+		// so the next poll retries an event that couldn't be emitted. An
+		// event that was queued for some webhooks is retried by the emitter
+		// instead, so that the webhooks that got it don't get it again.
+		if err := w.emit(event); err != nil {
+			errs = append(errs, fmt.Errorf("emitting %s event for order %d failed (%w)", eventType, orderId, err))
+			if !errors.Is(err, errOrderEventQueued) {
+				continue
+			}
+		}
+		w.orders[key] = current
+	}
+	// Orders that aren't listed keep their previous snapshots if a list
+	// couldn't be retrieved, so that its orders don't look new on the next
+	// poll. Once every list has been retrieved, orders that aren't listed
+	// (e.g. old orders that ETrade no longer lists) are forgotten.
+	if isComplete {
+		w.hasBaseline[account.GetId()] = true
+		for key := range w.orders {
+			if _, isListed := snapshots[key.orderId]; key.accountId == account.GetId() && !isListed {
+				delete(w.orders, key)
+			}
+		}
+	}
+	return errs
+}
+
+// getOrderSnapshot returns the order's status (the status of its first order
+// detail) and its quantities (summed over all its legs).
+func getOrderSnapshot(order etradelib.ETradeOrder) (orderSnapshot, error) {
+	orderModel, err := order.AsModel()
+	if err != nil {
+		return orderSnapshot{}, err
+	}
+	if len(orderModel.OrderDetail) == 0 {
+		return orderSnapshot{}, errors.New("order has no details")
+	}
+	snapshot := orderSnapshot{status: strings.ToUpper(orderModel.OrderDetail[0].Status)}
+	for _, detail := range orderModel.OrderDetail {
+		for _, instrument := range detail.Instrument {
+			snapshot.orderedQuantity += instrument.OrderedQuantity
+			snapshot.filledQuantity += instrument.FilledQuantity
+		}
+	}
+	return snapshot, nil
+}
+
+// getOrderEventType returns the event for an order that changed from
+// previous (nil if the order is new) to current, or "" if the change doesn't
+// have an event.
+func getOrderEventType(previous *orderSnapshot, current orderSnapshot) string {
+	var previousStatus string
+	var previousFilledQuantity float64
+	if previous != nil {
+		previousStatus, previousFilledQuantity = previous.status, previous.filledQuantity
+	}
+	if current.status != previousStatus {
+		switch current.status {
+		case "EXECUTED":
+			return orderEventFilled
+		case "CANCELLED":
+			return orderEventCancelled
+		case "EXPIRED":
+			return orderEventExpired
+		case "REJECTED":
+			return orderEventRejected
+		}
+	}
+	if !isFinalOrderStatus(current.status) && current.filledQuantity > previousFilledQuantity {
+		return orderEventPartiallyFilled
+	}
+	return ""
+}
+
+func isFinalOrderStatus(status string) bool {
+	switch status {
+	case "EXECUTED", "CANCELLED", "EXPIRED", "REJECTED":
+		return true
+	default:
+		return false
+	}
+}
+
+// orderEventEmitter sends order events to webhooks (signed with the secret)
+// or, if there aren't any webhooks, writes them to an output as NDJSON. Each
+// webhook has its own queue of the events that it hasn't received, so that a
+// webhook that fails doesn't make the others get events again. It's safe for
+// concurrent use.
+type orderEventEmitter struct {
+	webhooks   []string
+	secret     string
+	output     io.Writer
+	httpClient *http.Client
+	now        func() time.Time
+
+	mutex  sync.Mutex
+	queued map[string][][]byte
+}
+
+// newOrderEventEmitter creates an emitter for the webhooks, or for the output
+// if there aren't any. If the secret is empty, it's read from the environment.
+// secretFlag is the name of the flag that gives the secret, for errors.
+func newOrderEventEmitter(
+	webhooks []string, secret string, secretFlag string, output io.Writer,
+) (*orderEventEmitter, error) {
+	if secret == "" {
+		secret = os.Getenv(orderWatchSecretEnvironmentVariable)
+	}
+	if len(webhooks) > 0 && secret == "" {
+		return nil, fmt.Errorf(
+			"webhooks require a secret (with --%s or %s)", secretFlag, orderWatchSecretEnvironmentVariable,
+		)
+	}
+	return &orderEventEmitter{
+		webhooks:   webhooks,
+		secret:     secret,
+		output:     output,
+		httpClient: newWebhookHttpClient(),
+		now:        time.Now,
+		queued:     map[string][][]byte{},
+	}, nil
+}
+
+// Emit sends the event to every webhook, after the events that are already
+// queued for it. A webhook that fails doesn't stop the others; the event is
+// queued for it, and the errors are returned together, wrapping
+// errOrderEventQueued.
+func (e *orderEventEmitter) Emit(event jsonmap.JsonMap) error {
+	eventBytes, err := event.ToJsonBytes(false, false)
+	if err != nil {
+		return err
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if len(e.webhooks) == 0 {
+		// The JSON ends with a newline.
+		_, err = e.output.Write(eventBytes)
+		return err
+	}
+	for _, webhook := range e.webhooks {
+		queued := append(e.queued[webhook], eventBytes)
+		if len(queued) > orderEventMaxQueued {
+			queued = queued[len(queued)-orderEventMaxQueued:]
+		}
+		e.queued[webhook] = queued
+	}
+	if err = e.sendQueued(); err != nil {
+		return fmt.Errorf("%w (%w)", err, errOrderEventQueued)
+	}
+	return nil
+}
+
+// Retry sends the events that are queued for the webhooks.
+func (e *orderEventEmitter) Retry() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.sendQueued()
+}
+
+// sendQueued sends each webhook its queued events, oldest first, until one
+// fails. The errors are returned together.
+func (e *orderEventEmitter) sendQueued() error {
+	var errs []error
+	for _, webhook := range e.webhooks {
+		queued := e.queued[webhook]
+		for len(queued) > 0 {
+			if err := postWebhook(e.httpClient, webhook, queued[0], e.secret, e.now()); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", webhook, err))
+				break
+			}
+			queued = queued[1:]
+		}
+		if len(queued) == 0 {
+			delete(e.queued, webhook)
+		} else {
+			e.queued[webhook] = queued
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,494 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetOrderEventType(t *testing.T) {
+	tests := []struct {
+		name          string
+		testPrevious  *orderSnapshot
+		testCurrent   orderSnapshot
+		expectedEvent string
+	}{
+		{
+			name:          "Open Order Without Fills Has No Event",
+			testPrevious:  &orderSnapshot{status: "OPEN", orderedQuantity: 10},
+			testCurrent:   orderSnapshot{status: "OPEN", orderedQuantity: 10},
+			expectedEvent: "",
+		},
+		{
+			name:          "New Open Order Has No Event",
+			testPrevious:  nil,
+			testCurrent:   orderSnapshot{status: "OPEN", orderedQuantity: 10},
+			expectedEvent: "",
+		},
+		{
+			name:          "Open Order With More Fills Is Partially Filled",
+			testPrevious:  &orderSnapshot{status: "OPEN", orderedQuantity: 10, filledQuantity: 2},
+			testCurrent:   orderSnapshot{status: "OPEN", orderedQuantity: 10, filledQuantity: 5},
+			expectedEvent: orderEventPartiallyFilled,
+		},
+		{
+			name:          "Executed Order Is Filled",
+			testPrevious:  &orderSnapshot{status: "OPEN", orderedQuantity: 10, filledQuantity: 5},
+			testCurrent:   orderSnapshot{status: "EXECUTED", orderedQuantity: 10, filledQuantity: 10},
+			expectedEvent: orderEventFilled,
+		},
+		{
+			name:          "New Executed Order Is Filled",
+			testPrevious:  nil,
+			testCurrent:   orderSnapshot{status: "EXECUTED", orderedQuantity: 10, filledQuantity: 10},
+			expectedEvent: orderEventFilled,
+		},
+		{
+			name:          "Cancelled Order Is Cancelled",
+			testPrevious:  &orderSnapshot{status: "CANCEL_REQUESTED", orderedQuantity: 10},
+			testCurrent:   orderSnapshot{status: "CANCELLED", orderedQuantity: 10},
+			expectedEvent: orderEventCancelled,
+		},
+		{
+			name:          "Expired Order Is Expired",
+			testPrevious:  &orderSnapshot{status: "OPEN", orderedQuantity: 10},
+			testCurrent:   orderSnapshot{status: "EXPIRED", orderedQuantity: 10},
+			expectedEvent: orderEventExpired,
+		},
+		{
+			name:          "Rejected Order Is Rejected",
+			testPrevious:  &orderSnapshot{status: "OPEN", orderedQuantity: 10},
+			testCurrent:   orderSnapshot{status: "REJECTED", orderedQuantity: 10},
+			expectedEvent: orderEventRejected,
+		},
+		{
+			name:          "Unchanged Executed Order Has No Event",
+			testPrevious:  &orderSnapshot{status: "EXECUTED", orderedQuantity: 10, filledQuantity: 10},
+			testCurrent:   orderSnapshot{status: "EXECUTED", orderedQuantity: 10, filledQuantity: 10},
+			expectedEvent: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualEvent := getOrderEventType(tt.testPrevious, tt.testCurrent)
+
+				assert.Equal(t, tt.expectedEvent, actualEvent)
+			},
+		)
+	}
+}
+
+const testOrderWatchAccountList = `
+{
+  "AccountListResponse": {
+    "Accounts": {
+      "Account": [
+        {
+          "accountId": "test id",
+          "accountIdKey": "test key"
+        }
+      ]
+    }
+  }
+}`
+
+// newTestOrdersResponse returns an orders response with an order for each
+// of the IDs, with the status and quantities.
+func newTestOrdersResponse(orderIds []int64, status string, ordered float64, filled float64) []byte {
+	orders := make([]string, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		orders = append(
+			orders, fmt.Sprintf(
+				`{"orderId":%d,"OrderDetail":[{"status":"%s",`+
+					`"Instrument":[{"orderedQuantity":%g,"filledQuantity":%g}]}]}`,
+				orderId, status, ordered, filled,
+			),
+		)
+	}
+	return []byte(fmt.Sprintf(`{"OrdersResponse":{"Order":[%s]}}`, strings.Join(orders, ",")))
+}
+
+// expectTestOrderWatchPoll resets the mock's expectations to a poll whose
+// order lists are the responses (by status). Statuses without a response
+// have no orders, and statuses with a nil response fail.
+func expectTestOrderWatchPoll(mockClient *client.ETradeClientMock, responses map[constants.OrderStatus][]byte) {
+	mockClient.ExpectedCalls = nil
+	mockClient.On("ListAccounts").Return([]byte(testOrderWatchAccountList), nil)
+	for _, status := range orderWatchStatuses {
+		response, ok := responses[status]
+		if !ok {
+			response = []byte(`{"OrdersResponse":{"Order":[]}}`)
+		}
+		call := mockClient.On(
+			"ListOrders", "test key", "", 100, status, (*time.Time)(nil), (*time.Time)(nil), []string(nil),
+			constants.OrderSecurityTypeNil, constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+		)
+		if response == nil {
+			call.Return([]byte(nil), errors.New("test error"))
+		} else {
+			call.Return(response, nil)
+		}
+	}
+}
+
+func TestOrderWatcher_Poll(t *testing.T) {
+	mockClient := new(client.ETradeClientMock)
+	var events []jsonmap.JsonMap
+	emit := func(event jsonmap.JsonMap) error {
+		events = append(events, event)
+		return nil
+	}
+	getClient := func() (client.ETradeClient, error) { return mockClient, nil }
+	now := func() time.Time { return time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC) }
+	watcher := newOrderWatcher("test customer", getClient, nil, emit, nil, now)
+
+	// The first poll is the baseline, so its executed order doesn't emit an
+	// event.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen:     newTestOrdersResponse([]int64{2, 3, 4}, "OPEN", 10, 0),
+			constants.OrderStatusExecuted: newTestOrdersResponse([]int64{1}, "EXECUTED", 10, 10),
+		},
+	)
+
+	// Call the Method Under Test
+	err := watcher.Poll()
+
+	require.Nil(t, err)
+	assert.Empty(t, events)
+
+	// Order 2 partially fills, order 3 fills, order 4 is cancelled, and the
+	// new order 5 is rejected.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen:     newTestOrdersResponse([]int64{2}, "OPEN", 10, 4),
+			constants.OrderStatusExecuted: newTestOrdersResponse([]int64{1, 3}, "EXECUTED", 10, 10),
+			constants.OrderStatusCanceled: newTestOrdersResponse([]int64{4}, "CANCELLED", 10, 0),
+			constants.OrderStatusRejected: newTestOrdersResponse([]int64{5}, "REJECTED", 10, 0),
+		},
+	)
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	require.Nil(t, err)
+	require.Len(t, events, 4)
+	var actualSummaries []string
+	for _, event := range events {
+		actualSummaries = append(
+			actualSummaries, fmt.Sprintf(
+				"%v %v %v->%v %v->%v", event["event"], event["orderId"], event["previousStatus"], event["status"],
+				event["previousFilledQuantity"], event["filledQuantity"],
+			),
+		)
+	}
+	assert.Equal(
+		t, []string{
+			"partiallyFilled 2 OPEN->OPEN 0->4",
+			"filled 3 OPEN->EXECUTED 0->10",
+			"cancelled 4 OPEN->CANCELLED 0->0",
+			"rejected 5 ->REJECTED 0->0",
+		}, actualSummaries,
+	)
+	assert.Equal(t, "test customer", events[0]["customerId"])
+	assert.Equal(t, "test id", events[0]["accountId"])
+	assert.Equal(t, "2023-11-14T22:13:20Z", events[0]["time"])
+	orderId, err := events[0].GetIntAtPath(".order.orderId")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), orderId)
+
+	// Nothing changed.
+	events = nil
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	require.Nil(t, err)
+	assert.Empty(t, events)
+}
+
+func TestOrderWatcher_Poll_IndividualFillsAndUnlistedOrders(t *testing.T) {
+	mockClient := new(client.ETradeClientMock)
+	var events []jsonmap.JsonMap
+	emit := func(event jsonmap.JsonMap) error {
+		events = append(events, event)
+		return nil
+	}
+	getClient := func() (client.ETradeClient, error) { return mockClient, nil }
+	watcher := newOrderWatcher("test customer", getClient, nil, emit, nil, time.Now)
+
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen:     newTestOrdersResponse([]int64{1}, "OPEN", 10, 0),
+			constants.OrderStatusExecuted: newTestOrdersResponse([]int64{2}, "EXECUTED", 10, 10),
+		},
+	)
+	err := watcher.Poll()
+	require.Nil(t, err)
+
+	// Order 1 is only listed with individual fills, and order 2 is no longer
+	// listed.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusIndividualFills: newTestOrdersResponse([]int64{1}, "INDIVIDUAL_FILLS", 10, 3),
+		},
+	)
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "partiallyFilled", events[0]["event"])
+	assert.Equal(t, int64(1), events[0]["orderId"])
+	assert.Equal(
+		t, map[orderWatchKey]orderSnapshot{
+			{accountId: "test id", orderId: 1}: {status: "INDIVIDUAL_FILLS", orderedQuantity: 10, filledQuantity: 3},
+		}, watcher.orders,
+	)
+}
+
+func TestOrderWatcher_Poll_IncompleteBaselineIsRetried(t *testing.T) {
+	mockClient := new(client.ETradeClientMock)
+	var events []jsonmap.JsonMap
+	emit := func(event jsonmap.JsonMap) error {
+		events = append(events, event)
+		return nil
+	}
+	getClient := func() (client.ETradeClient, error) { return mockClient, nil }
+	watcher := newOrderWatcher("test customer", getClient, nil, emit, nil, time.Now)
+
+	// The executed orders can't be listed, so this poll isn't a baseline.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen:     newTestOrdersResponse([]int64{1}, "OPEN", 10, 0),
+			constants.OrderStatusExecuted: nil,
+		},
+	)
+
+	// Call the Method Under Test
+	err := watcher.Poll()
+
+	assert.ErrorContains(t, err, "listing EXECUTED orders for account test id failed (test error)")
+	assert.Empty(t, events)
+
+	// This poll is the baseline, so the previously executed order 2 doesn't
+	// emit an event.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen:     newTestOrdersResponse([]int64{1}, "OPEN", 10, 0),
+			constants.OrderStatusExecuted: newTestOrdersResponse([]int64{2}, "EXECUTED", 10, 10),
+		},
+	)
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	assert.Nil(t, err)
+	assert.Empty(t, events)
+}
+
+func TestOrderWatcher_Poll_FailedEventIsRetried(t *testing.T) {
+	mockClient := new(client.ETradeClientMock)
+	var events []jsonmap.JsonMap
+	emitErr := errors.New("test error")
+	emit := func(event jsonmap.JsonMap) error {
+		if emitErr != nil {
+			return emitErr
+		}
+		events = append(events, event)
+		return nil
+	}
+	getClient := func() (client.ETradeClient, error) { return mockClient, nil }
+	watcher := newOrderWatcher("test customer", getClient, nil, emit, nil, time.Now)
+
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen: newTestOrdersResponse([]int64{1}, "OPEN", 10, 0),
+		},
+	)
+	err := watcher.Poll()
+	require.Nil(t, err)
+
+	// Order 1 fills, but its event can't be emitted.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusExecuted: newTestOrdersResponse([]int64{1}, "EXECUTED", 10, 10),
+		},
+	)
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	assert.ErrorContains(t, err, "emitting filled event for order 1 failed (test error)")
+	assert.Empty(t, events)
+
+	// The event is emitted by the next poll.
+	emitErr = nil
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	require.Nil(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "filled", events[0]["event"])
+	assert.Equal(t, "OPEN", events[0]["previousStatus"])
+
+	// Once it's emitted, it isn't emitted again.
+	events = nil
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	require.Nil(t, err)
+	assert.Empty(t, events)
+}
+
+func TestOrderWatcher_Poll_QueuedEventIsNotEmittedAgain(t *testing.T) {
+	mockClient := new(client.ETradeClientMock)
+	var events []jsonmap.JsonMap
+	emit := func(event jsonmap.JsonMap) error {
+		events = append(events, event)
+		return fmt.Errorf("test error (%w)", errOrderEventQueued)
+	}
+	retries := 0
+	retry := func() error {
+		retries += 1
+		return nil
+	}
+	getClient := func() (client.ETradeClient, error) { return mockClient, nil }
+	watcher := newOrderWatcher("test customer", getClient, nil, emit, retry, time.Now)
+
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusOpen: newTestOrdersResponse([]int64{1}, "OPEN", 10, 0),
+		},
+	)
+	err := watcher.Poll()
+	require.Nil(t, err)
+
+	// Order 1 fills, and its event is queued for a webhook that failed.
+	expectTestOrderWatchPoll(
+		mockClient, map[constants.OrderStatus][]byte{
+			constants.OrderStatusExecuted: newTestOrdersResponse([]int64{1}, "EXECUTED", 10, 10),
+		},
+	)
+
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	assert.ErrorContains(t, err, "emitting filled event for order 1 failed (test error (queued for retry))")
+	assert.Len(t, events, 1)
+
+	// The emitter retries the event, so the watcher doesn't emit it again.
+	// Call the Method Under Test
+	err = watcher.Poll()
+
+	require.Nil(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, 3, retries)
+}
+
+func TestOrderWatcher_Poll_FailsWithUnknownAccount(t *testing.T) {
+	mockClient := new(client.ETradeClientMock)
+	mockClient.On("ListAccounts").Return([]byte(testOrderWatchAccountList), nil)
+	getClient := func() (client.ETradeClient, error) { return mockClient, nil }
+	emit := func(event jsonmap.JsonMap) error { return nil }
+	watcher := newOrderWatcher("test customer", getClient, []string{"bad id"}, emit, nil, time.Now)
+
+	// Call the Method Under Test
+	err := watcher.Poll()
+
+	assert.EqualError(t, err, "account with id bad id not found")
+}
+
+func TestOrderEventEmitter_Emit_Stdout(t *testing.T) {
+	var output bytes.Buffer
+	emitter, err := newOrderEventEmitter(nil, "", "webhook-secret", &output)
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	err = emitter.Emit(jsonmap.JsonMap{"event": "filled", "orderId": int64(1)})
+
+	assert.Nil(t, err)
+	assert.Equal(t, `{"event":"filled","orderId":1}`+"\n", output.String())
+}
+
+func TestOrderEventEmitter_Emit_QueuesForFailedWebhook(t *testing.T) {
+	var received [2][]string
+	webhookDown := true
+	var webhookServers [2]*httptest.Server
+	for i := range webhookServers {
+		i := i
+		webhookServers[i] = httptest.NewServer(
+			http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if i == 1 && webhookDown {
+						w.WriteHeader(http.StatusBadGateway)
+						return
+					}
+					body, _ := io.ReadAll(r.Body)
+					received[i] = append(received[i], strings.TrimSpace(string(body)))
+				},
+			),
+		)
+		defer webhookServers[i].Close()
+	}
+	emitter, err := newOrderEventEmitter(
+		[]string{webhookServers[0].URL, webhookServers[1].URL}, "test secret", "webhook-secret", &bytes.Buffer{},
+	)
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	err = emitter.Emit(jsonmap.JsonMap{"orderId": int64(1)})
+
+	assert.ErrorIs(t, err, errOrderEventQueued)
+	assert.ErrorContains(t, err, webhookServers[1].URL+": webhook responded with 502 Bad Gateway")
+
+	// Call the Method Under Test
+	err = emitter.Retry()
+
+	assert.Error(t, err)
+
+	// The queued event is sent before the next one, and only to the webhook
+	// that didn't get it.
+	webhookDown = false
+
+	// Call the Method Under Test
+	err = emitter.Emit(jsonmap.JsonMap{"orderId": int64(2)})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`{"orderId":1}`, `{"orderId":2}`}, received[0])
+	assert.Equal(t, []string{`{"orderId":1}`, `{"orderId":2}`}, received[1])
+
+	// Call the Method Under Test
+	err = emitter.Retry()
+
+	assert.Nil(t, err)
+	assert.Len(t, received[1], 2)
+}
+
+func TestNewOrderEventEmitter_WebhooksRequireSecret(t *testing.T) {
+	t.Setenv(orderWatchSecretEnvironmentVariable, "")
+
+	// Call the Method Under Test
+	_, err := newOrderEventEmitter([]string{"http://localhost/hook"}, "", "order-webhook-secret", &bytes.Buffer{})
+
+	assert.EqualError(
+		t, err, "webhooks require a secret (with --order-webhook-secret or "+orderWatchSecretEnvironmentVariable+")",
+	)
+}
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccountValue is the total value of an account at the end of a day.
+type AccountValue struct {
+	Date  time.Time
+	Value float64
+}
+
+// ParseAccountValue parses an account value in the form MMDDYYYY=VALUE. The
+// date is in the location.
+func ParseAccountValue(value string, location *time.Location) (AccountValue, error) {
+	dateString, valueString, ok := strings.Cut(value, "=")
+	if !ok {
+		return AccountValue{}, fmt.Errorf("invalid account value %s (must be MMDDYYYY=VALUE)", value)
+	}
+	date, err := time.ParseInLocation("01022006", dateString, location)
+	if err != nil {
+		return AccountValue{}, fmt.Errorf("invalid account value date %s (must be MMDDYYYY)", dateString)
+	}
+	accountValue, err := strconv.ParseFloat(valueString, 64)
+	if err != nil || accountValue <= 0 {
+		return AccountValue{}, fmt.Errorf("invalid account value %s (must be greater than zero)", valueString)
+	}
+	return AccountValue{Date: date, Value: accountValue}, nil
+}
+
+// externalCashFlowTypes are the words in the types of transactions that move
+// cash into or out of an account, as opposed to trades, dividends, interest,
+// and fees, which are part of its return.
+var externalCashFlowTypes = []string{
+	"transfer", "deposit", "withdrawal", "contribution", "wire", "check", "bill payment", "direct debit",
+}
+
+// cashFlow is cash moved into (positive) or out of (negative) an account.
+type cashFlow struct {
+	transactionId   string
+	transactionType string
+	description     string
+	date            time.Time
+	amount          float64
+}
+
+// dailyAccountValue is an account's reconstructed value at the end of a day.
+type dailyAccountValue struct {
+	date     time.Time
+	value    float64
+	cashFlow float64
+	// growth is the day's growth factor, excluding the cash flow.
+	growth float64
+}
+
+// accountPerformance is an account's performance between two account values.
+type accountPerformance struct {
+	start       AccountValue
+	end         AccountValue
+	cashFlows   []cashFlow
+	dailyValues []dailyAccountValue
+	twr         float64
+	xirr        *float64
+}
+
+// getCashFlow returns the cash that a transaction moved into or out of an
+// account. It returns false if the transaction isn't an external cash flow.
+// Transfers of securities have no amount, so they aren't included.
+func getCashFlow(transaction *model.Transaction, location *time.Location) (cashFlow, bool) {
+	if transaction.Amount == 0 {
+		return cashFlow{}, false
+	}
+	transactionType := strings.ToLower(transaction.TransactionType)
+	for _, externalType := range externalCashFlowTypes {
+		if strings.Contains(transactionType, externalType) {
+			return cashFlow{
+				transactionId:   transaction.TransactionId.String(),
+				transactionType: transaction.TransactionType,
+				description:     transaction.Description,
+				date:            getStartOfDay(time.UnixMilli(transaction.TransactionDate).In(location)),
+				amount:          transaction.Amount,
+			}, true
+		}
+	}
+	return cashFlow{}, false
+}
+
+// getPerformanceStartValue returns the account value that a performance
+// period starting on the from date is measured from: the latest value on or
+// before the date or, if there isn't one, the earliest value after it.
+func getPerformanceStartValue(values []AccountValue, from time.Time) (AccountValue, bool) {
+	values = getSortedAccountValues(values)
+	for i := len(values) - 1; i >= 0; i-- {
+		if !values[i].Date.After(from) {
+			return values[i], true
+		}
+	}
+	if len(values) > 0 {
+		return values[0], true
+	}
+	return AccountValue{}, false
+}
+
+// calculatePerformance calculates an account's performance from the start
+// value (see getPerformanceStartValue) to the latest value on or before the
+// to date. The cash flows between each pair of consecutive values are
+// weighted by how long they were in the account (the Modified Dietz method)
+// to get the pair's return, and the returns are chained to get the
+// time-weighted return. Each pair's return is spread evenly over its days to
+// reconstruct the daily values.
+func calculatePerformance(
+	values []AccountValue, cashFlows []cashFlow, from time.Time, to time.Time,
+) (accountPerformance, error) {
+	start, ok := getPerformanceStartValue(values, from)
+	if !ok {
+		return accountPerformance{}, errors.New("no account values are available")
+	}
+	var periodValues []AccountValue
+	for _, value := range getSortedAccountValues(values) {
+		if !value.Date.Before(start.Date) && !value.Date.After(to) {
+			periodValues = append(periodValues, value)
+		}
+	}
+	if len(periodValues) < 2 {
+		return accountPerformance{}, fmt.Errorf(
+			"performance requires two account values between %s and %s", start.Date.Format(time.DateOnly),
+			to.Format(time.DateOnly),
+		)
+	}
+	end := periodValues[len(periodValues)-1]
+
+	cashFlowsByDate := map[string]float64{}
+	performance := accountPerformance{start: start, end: end, twr: 1}
+	for _, flow := range cashFlows {
+		if flow.date.After(start.Date) && !flow.date.After(end.Date) {
+			performance.cashFlows = append(performance.cashFlows, flow)
+			cashFlowsByDate[flow.date.Format(time.DateOnly)] += flow.amount
+		}
+	}
+	sort.SliceStable(
+		performance.cashFlows,
+		func(i, j int) bool { return performance.cashFlows[i].date.Before(performance.cashFlows[j].date) },
+	)
+
+	for i := 1; i < len(periodValues); i++ {
+		previous, current := periodValues[i-1], periodValues[i]
+		days := getDaysBetween(previous.Date, current.Date)
+		var netCashFlow, weightedCashFlow float64
+		for _, flow := range performance.cashFlows {
+			if flow.date.After(previous.Date) && !flow.date.After(current.Date) {
+				netCashFlow += flow.amount
+				weightedCashFlow += flow.amount * float64(days-getDaysBetween(previous.Date, flow.date)) / float64(days)
+			}
+		}
+		if previous.Value+weightedCashFlow <= 0 {
+			return accountPerformance{}, fmt.Errorf(
+				"the account had no invested value between %s and %s", previous.Date.Format(time.DateOnly),
+				current.Date.Format(time.DateOnly),
+			)
+		}
+		growth := 1 + (current.Value-previous.Value-netCashFlow)/(previous.Value+weightedCashFlow)
+		if growth <= 0 {
+			return accountPerformance{}, fmt.Errorf(
+				"the account lost more than its value between %s and %s", previous.Date.Format(time.DateOnly),
+				current.Date.Format(time.DateOnly),
+			)
+		}
+		performance.twr *= growth
+		dailyGrowth := math.Pow(growth, 1/float64(days))
+		value := previous.Value
+		for day := 1; day <= days; day++ {
+			date := previous.Date.AddDate(0, 0, day)
+			dateCashFlow := cashFlowsByDate[date.Format(time.DateOnly)]
+			value = value*dailyGrowth + dateCashFlow
+			if day == days {
+				// The reconstructed value drifts from the actual value
+				// because of the cash flows' weighting.
+				value = current.Value
+			}
+			performance.dailyValues = append(
+				performance.dailyValues, dailyAccountValue{
+					date: date, value: value, cashFlow: dateCashFlow, growth: dailyGrowth,
+				},
+			)
+		}
+	}
+	performance.twr--
+
+	// The XIRR is from the point of view of the account's owner, who invests
+	// the start value and the deposits, and receives the withdrawals and the
+	// end value.
+	xirrFlows := []cashFlow{{date: start.Date, amount: -start.Value}}
+	for _, flow := range performance.cashFlows {
+		xirrFlows = append(xirrFlows, cashFlow{date: flow.date, amount: -flow.amount})
+	}
+	xirrFlows = append(xirrFlows, cashFlow{date: end.Date, amount: end.Value})
+	if xirr, ok := calculateXirr(xirrFlows); ok {
+		performance.xirr = &xirr
+	}
+	return performance, nil
+}
+
+// getReturnSince returns the time-weighted return from the end of the day
+// before the since date to the end of the performance period. It returns
+// false if the period doesn't start before the since date.
+func (p *accountPerformance) getReturnSince(since time.Time) (float64, bool) {
+	if !p.start.Date.Before(since) {
+		return 0, false
+	}
+	growth := 1.0
+	for _, dailyValue := range p.dailyValues {
+		if !dailyValue.date.Before(since) {
+			growth *= dailyValue.growth
+		}
+	}
+	return growth - 1, true
+}
+
+// calculateXirr returns the annual rate at which the cash flows' net present
+// value is zero, found by bisection. It returns false if there isn't one.
+func calculateXirr(cashFlows []cashFlow) (float64, bool) {
+	if len(cashFlows) < 2 {
+		return 0, false
+	}
+	getNpv := func(rate float64) float64 {
+		var npv float64
+		for _, flow := range cashFlows {
+			years := float64(getDaysBetween(cashFlows[0].date, flow.date)) / 365
+			npv += flow.amount / math.Pow(1+rate, years)
+		}
+		return npv
+	}
+	low, high := -0.9999, 1.0
+	for getNpv(low)*getNpv(high) > 0 {
+		if high > 1e6 {
+			return 0, false
+		}
+		high *= 2
+	}
+	for i := 0; i < 200; i++ {
+		middle := (low + high) / 2
+		if getNpv(low)*getNpv(middle) <= 0 {
+			high = middle
+		} else {
+			low = middle
+		}
+	}
+	return (low + high) / 2, true
+}
+
+// getPerformanceJsonMap returns the performance, with the time-weighted
+// return for the month, quarter, and year to date and for the last year, if
+// the performance period covers them. Returns are percentages.
+func getPerformanceJsonMap(performance accountPerformance, daily bool) jsonmap.JsonMap {
+	var deposits, withdrawals float64
+	cashFlowsSlice := jsonmap.JsonSlice{}
+	for _, flow := range performance.cashFlows {
+		if flow.amount > 0 {
+			deposits += flow.amount
+		} else {
+			withdrawals -= flow.amount
+		}
+		cashFlowsSlice = append(
+			cashFlowsSlice, jsonmap.JsonMap{
+				"transactionId":   flow.transactionId,
+				"date":            flow.date.Format(time.DateOnly),
+				"transactionType": flow.transactionType,
+				"description":     flow.description,
+				"amount":          roundToCents(flow.amount),
+			},
+		)
+	}
+	start, end := performance.start, performance.end
+	summary := jsonmap.JsonMap{
+		"startDate":             start.Date.Format(time.DateOnly),
+		"endDate":               end.Date.Format(time.DateOnly),
+		"startValue":            roundToCents(start.Value),
+		"endValue":              roundToCents(end.Value),
+		"deposits":              roundToCents(deposits),
+		"withdrawals":           roundToCents(withdrawals),
+		"gain":                  roundToCents(end.Value - start.Value - deposits + withdrawals),
+		"timeWeightedReturnPct": getRoundedPercent(performance.twr),
+	}
+	if days := getDaysBetween(start.Date, end.Date); days >= 365 {
+		summary["annualizedReturnPct"] = getRoundedPercent(math.Pow(1+performance.twr, 365/float64(days)) - 1)
+	}
+	if performance.xirr != nil {
+		summary["xirrPct"] = getRoundedPercent(*performance.xirr)
+	}
+	periodStarts := map[string]time.Time{
+		"mtdReturnPct":     time.Date(end.Date.Year(), end.Date.Month(), 1, 0, 0, 0, 0, end.Date.Location()),
+		"qtdReturnPct":     time.Date(end.Date.Year(), (end.Date.Month()-1)/3*3+1, 1, 0, 0, 0, 0, end.Date.Location()),
+		"ytdReturnPct":     time.Date(end.Date.Year(), time.January, 1, 0, 0, 0, 0, end.Date.Location()),
+		"oneYearReturnPct": end.Date.AddDate(-1, 0, 1),
+	}
+	for key, since := range periodStarts {
+		if periodReturn, ok := performance.getReturnSince(since); ok {
+			summary[key] = getRoundedPercent(periodReturn)
+		}
+	}
+
+	performanceMap := jsonmap.JsonMap{
+		"performance": summary,
+		"cashFlows":   cashFlowsSlice,
+	}
+	if daily {
+		dailyValuesSlice := jsonmap.JsonSlice{}
+		for _, dailyValue := range performance.dailyValues {
+			dailyValuesSlice = append(
+				dailyValuesSlice, jsonmap.JsonMap{
+					"date":      dailyValue.date.Format(time.DateOnly),
+					"value":     roundToCents(dailyValue.value),
+					"cashFlow":  roundToCents(dailyValue.cashFlow),
+					"returnPct": getRoundedPercent(dailyValue.growth - 1),
+				},
+			)
+		}
+		performanceMap["dailyValues"] = dailyValuesSlice
+	}
+	return performanceMap
+}
+
+// getSortedAccountValues returns the values sorted by date. If there's more
+// than one value for a date, the last one is used.
+func getSortedAccountValues(values []AccountValue) []AccountValue {
+	valuesByDate := map[string]AccountValue{}
+	for _, value := range values {
+		valuesByDate[value.Date.Format(time.DateOnly)] = value
+	}
+	sortedValues := make([]AccountValue, 0, len(valuesByDate))
+	for _, value := range valuesByDate {
+		sortedValues = append(sortedValues, value)
+	}
+	sort.Slice(sortedValues, func(i, j int) bool { return sortedValues[i].Date.Before(sortedValues[j].Date) })
+	return sortedValues
+}
+
+// getStartOfDay returns midnight at the start of the time's day, in its
+// location.
+func getStartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// getDaysBetween returns the number of calendar days from one date to
+// another, which may differ from the number of 24-hour periods across a
+// daylight saving time change.
+func getDaysBetween(from time.Time, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
+
+// getRoundedPercent returns a rate as a percentage, rounded to hundredths of
+// a percent.
+func getRoundedPercent(rate float64) float64 {
+	return math.Round(rate*10000) / 100
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testPerformanceDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseAccountValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		testValue   string
+		expectErr   bool
+		expectValue AccountValue
+	}{
+		{
+			name:        "Parses Date And Value",
+			testValue:   "12312024=1234.56",
+			expectErr:   false,
+			expectValue: AccountValue{Date: testPerformanceDate(2024, time.December, 31), Value: 1234.56},
+		},
+		{
+			name:      "Fails Without Value",
+			testValue: "12312024",
+			expectErr: true,
+		},
+		{
+			name:      "Fails With Invalid Date",
+			testValue: "2024-12-31=1234.56",
+			expectErr: true,
+		},
+		{
+			name:      "Fails With Zero Value",
+			testValue: "12312024=0",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := ParseAccountValue(tt.testValue, time.UTC)
+
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
+
+func TestGetCashFlow(t *testing.T) {
+	tests := []struct {
+		name            string
+		testTransaction model.Transaction
+		expectOk        bool
+		expectValue     cashFlow
+	}{
+		{
+			name: "Gets Deposit",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("1"),
+				TransactionDate: 1719849600000,
+				Amount:          1000,
+				Description:     "ACH DEPOSIT",
+				TransactionType: "Online Transfer",
+			},
+			expectOk: true,
+			expectValue: cashFlow{
+				transactionId: "1", transactionType: "Online Transfer", description: "ACH DEPOSIT",
+				date: testPerformanceDate(2024, time.July, 1), amount: 1000,
+			},
+		},
+		{
+			name: "Gets Withdrawal",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("2"),
+				TransactionDate: 1719849600000,
+				Amount:          -500,
+				TransactionType: "Withdrawal",
+			},
+			expectOk: true,
+			expectValue: cashFlow{
+				transactionId: "2", transactionType: "Withdrawal", date: testPerformanceDate(2024, time.July, 1),
+				amount: -500,
+			},
+		},
+		{
+			name: "Ignores Dividend",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("3"),
+				Amount:          12,
+				TransactionType: "Dividend",
+			},
+			expectOk: false,
+		},
+		{
+			name: "Ignores Transfer Of Securities",
+			testTransaction: model.Transaction{
+				TransactionId:   json.Number("4"),
+				TransactionType: "Transfer",
+			},
+			expectOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, ok := getCashFlow(&tt.testTransaction, time.UTC)
+
+				assert.Equal(t, tt.expectOk, ok)
+				if tt.expectOk {
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
+
+func TestCalculatePerformance(t *testing.T) {
+	// The account gains 10% in each half of 2024, with a deposit between
+	// them.
+	testValues := []AccountValue{
+		{Date: testPerformanceDate(2023, time.December, 31), Value: 1000},
+		{Date: testPerformanceDate(2024, time.July, 1), Value: 2100},
+		{Date: testPerformanceDate(2024, time.December, 31), Value: 2310},
+	}
+	testCashFlows := []cashFlow{
+		{transactionId: "1", date: testPerformanceDate(2024, time.July, 1), amount: 1000},
+	}
+	tests := []struct {
+		name          string
+		testValues    []AccountValue
+		testCashFlows []cashFlow
+		testFrom      time.Time
+		testTo        time.Time
+		expectErr     bool
+		expectValue   jsonmap.JsonMap
+	}{
+		{
+			name:          "Calculates Returns Net Of Cash Flows",
+			testValues:    testValues,
+			testCashFlows: testCashFlows,
+			testFrom:      testPerformanceDate(2024, time.January, 1),
+			testTo:        testPerformanceDate(2024, time.December, 31),
+			expectErr:     false,
+			expectValue: jsonmap.JsonMap{
+				"performance": jsonmap.JsonMap{
+					"startDate": "2023-12-31", "endDate": "2024-12-31", "startValue": 1000.0, "endValue": 2310.0,
+					"deposits": 1000.0, "withdrawals": 0.0, "gain": 310.0, "timeWeightedReturnPct": 21.0,
+					"annualizedReturnPct": 20.94, "xirrPct": 20.94, "mtdReturnPct": 1.63, "qtdReturnPct": 4.91,
+					"ytdReturnPct": 21.0, "oneYearReturnPct": 21.0,
+				},
+				"cashFlows": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"transactionId": "1", "date": "2024-07-01", "transactionType": "", "description": "",
+						"amount": 1000.0,
+					},
+				},
+			},
+		},
+		{
+			name:          "Omits Returns Before The Start Value",
+			testValues:    testValues[1:],
+			testCashFlows: testCashFlows,
+			testFrom:      testPerformanceDate(2024, time.January, 1),
+			testTo:        testPerformanceDate(2024, time.December, 31),
+			expectErr:     false,
+			expectValue: jsonmap.JsonMap{
+				"performance": jsonmap.JsonMap{
+					"startDate": "2024-07-01", "endDate": "2024-12-31", "startValue": 2100.0, "endValue": 2310.0,
+					"deposits": 0.0, "withdrawals": 0.0, "gain": 210.0, "timeWeightedReturnPct": 10.0,
+					"xirrPct": 20.94, "mtdReturnPct": 1.63, "qtdReturnPct": 4.91,
+				},
+				"cashFlows": jsonmap.JsonSlice{},
+			},
+		},
+		{
+			name:          "Fails With One Value",
+			testValues:    testValues[:1],
+			testCashFlows: testCashFlows,
+			testFrom:      testPerformanceDate(2024, time.January, 1),
+			testTo:        testPerformanceDate(2024, time.December, 31),
+			expectErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := calculatePerformance(tt.testValues, tt.testCashFlows, tt.testFrom, tt.testTo)
+
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, getPerformanceJsonMap(actualValue, false))
+				}
+			},
+		)
+	}
+}
+
+func TestCalculatePerformance_DailyValues(t *testing.T) {
+	testValues := []AccountValue{
+		{Date: testPerformanceDate(2024, time.January, 1), Value: 1000},
+		{Date: testPerformanceDate(2024, time.January, 3), Value: 1210},
+	}
+
+	// Call the Method Under Test
+	actualValue, err := calculatePerformance(
+		testValues, nil, testPerformanceDate(2024, time.January, 1), testPerformanceDate(2024, time.January, 3),
+	)
+
+	assert.Nil(t, err)
+	actualMap := getPerformanceJsonMap(actualValue, true)
+	assert.Equal(
+		t, jsonmap.JsonSlice{
+			jsonmap.JsonMap{"date": "2024-01-02", "value": 1100.0, "cashFlow": 0.0, "returnPct": 10.0},
+			jsonmap.JsonMap{"date": "2024-01-03", "value": 1210.0, "cashFlow": 0.0, "returnPct": 10.0},
+		}, actualMap["dailyValues"],
+	)
+}
+
+func TestCalculateXirr(t *testing.T) {
+	tests := []struct {
+		name          string
+		testCashFlows []cashFlow
+		expectOk      bool
+		expectValue   float64
+	}{
+		{
+			name: "Calculates Rate Over One Year",
+			testCashFlows: []cashFlow{
+				{date: testPerformanceDate(2023, time.January, 1), amount: -1000},
+				{date: testPerformanceDate(2024, time.January, 1), amount: 1100},
+			},
+			expectOk:    true,
+			expectValue: 10.0,
+		},
+		{
+			name: "Fails Without A Return",
+			testCashFlows: []cashFlow{
+				{date: testPerformanceDate(2023, time.January, 1), amount: -1000},
+				{date: testPerformanceDate(2024, time.January, 1), amount: -1100},
+			},
+			expectOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, ok := calculateXirr(tt.testCashFlows)
+
+				assert.Equal(t, tt.expectOk, ok)
+				if tt.expectOk {
+					assert.Equal(t, tt.expectValue, getRoundedPercent(actualValue))
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	// portfolioDiffAdded is a position or lot that's only in the later
+	// portfolio.
+	portfolioDiffAdded = "added"
+
+	// portfolioDiffRemoved is a position or lot that's only in the earlier
+	// portfolio.
+	portfolioDiffRemoved = "removed"
+
+	// portfolioDiffIncreased is a position or lot whose quantity grew. A
+	// short position's quantity is negative, so it grows when it's covered.
+	portfolioDiffIncreased = "increased"
+
+	// portfolioDiffDecreased is a position or lot whose quantity shrank.
+	portfolioDiffDecreased = "decreased"
+
+	// portfolioDiffUnchanged is a position or lot whose quantity didn't
+	// change.
+	portfolioDiffUnchanged = "unchanged"
+)
+
+// portfolioHolding is the amount of a position or lot in one portfolio. The
+// quantity is negative for a short position.
+type portfolioHolding struct {
+	quantity    float64
+	marketValue float64
+	totalGain   float64
+}
+
+func (h *portfolioHolding) add(quantity float64, marketValue float64, totalGain float64) {
+	h.quantity += quantity
+	h.marketValue += marketValue
+	h.totalGain += totalGain
+}
+
+// portfolioDiffLot is a lot's holdings in the two portfolios.
+type portfolioDiffLot struct {
+	lotId        int64
+	acquiredDate int64
+	from         portfolioHolding
+	to           portfolioHolding
+}
+
+// portfolioDiffPosition is a security's long or short holdings in the two
+// portfolios. A security's positions of the same type are combined, and
+// options are told apart by their OSI keys.
+type portfolioDiffPosition struct {
+	key          string
+	symbol       string
+	securityType string
+	positionType string
+	description  string
+	from         portfolioHolding
+	to           portfolioHolding
+	// lots are the position's lots, or nil if either portfolio doesn't
+	// have lots.
+	lots map[int64]*portfolioDiffLot
+}
+
+// calculatePortfolioDiff compares the positions in two portfolios by
+// security and, if both portfolios have lots, by lot. It returns the
+// positions sorted by symbol.
+func calculatePortfolioDiff(fromPositions []model.Position, toPositions []model.Position) []*portfolioDiffPosition {
+	withLots := hasPortfolioLots(fromPositions) && hasPortfolioLots(toPositions)
+	positions := map[string]*portfolioDiffPosition{}
+	addPositions := func(modelPositions []model.Position, isFrom bool) {
+		for i := range modelPositions {
+			modelPosition := &modelPositions[i]
+			position := getPortfolioDiffPosition(positions, modelPosition)
+			holding := &position.to
+			if isFrom {
+				holding = &position.from
+			}
+			holding.add(
+				getPortfolioDiffQuantity(position.positionType, modelPosition.Quantity), modelPosition.MarketValue,
+				modelPosition.TotalGain,
+			)
+			if !withLots {
+				continue
+			}
+			if position.lots == nil {
+				position.lots = map[int64]*portfolioDiffLot{}
+			}
+			for _, modelLot := range modelPosition.Lots {
+				lot, found := position.lots[modelLot.PositionLotId]
+				if !found {
+					lot = &portfolioDiffLot{lotId: modelLot.PositionLotId, acquiredDate: modelLot.AcquiredDate}
+					position.lots[modelLot.PositionLotId] = lot
+				}
+				lotHolding := &lot.to
+				if isFrom {
+					lotHolding = &lot.from
+				}
+				lotHolding.add(
+					getPortfolioDiffQuantity(position.positionType, modelLot.RemainingQty), modelLot.MarketValue,
+					modelLot.TotalGain,
+				)
+			}
+		}
+	}
+	addPositions(fromPositions, true)
+	addPositions(toPositions, false)
+
+	sortedPositions := make([]*portfolioDiffPosition, 0, len(positions))
+	for _, position := range positions {
+		sortedPositions = append(sortedPositions, position)
+	}
+	sort.Slice(
+		sortedPositions, func(i, j int) bool {
+			return sortedPositions[i].key < sortedPositions[j].key
+		},
+	)
+	return sortedPositions
+}
+
+func getPortfolioDiffPosition(
+	positions map[string]*portfolioDiffPosition, modelPosition *model.Position,
+) *portfolioDiffPosition {
+	symbol, securityType := "", ""
+	if modelPosition.Product != nil {
+		symbol, securityType = modelPosition.Product.Symbol, modelPosition.Product.SecurityType
+	}
+	positionType := strings.ToUpper(modelPosition.PositionType)
+	if positionType == "" {
+		positionType = "LONG"
+		if modelPosition.Quantity < 0 {
+			positionType = "SHORT"
+		}
+	}
+	key := symbol
+	if modelPosition.OsiKey != "" {
+		key = modelPosition.OsiKey
+	}
+	// A security can be held long and short at once (e.g. in different
+	// account types), so each type is a separate position.
+	key += " " + positionType
+	position, found := positions[key]
+	if !found {
+		position = &portfolioDiffPosition{
+			key:          key,
+			symbol:       symbol,
+			securityType: securityType,
+			positionType: positionType,
+			description:  modelPosition.SymbolDescription,
+		}
+		positions[key] = position
+	}
+	return position
+}
+
+// getPortfolioDiffQuantity returns a position's or lot's quantity, negated
+// for a short position if ETrade reported it as positive.
+func getPortfolioDiffQuantity(positionType string, quantity float64) float64 {
+	if positionType == "SHORT" {
+		return -math.Abs(quantity)
+	}
+	return quantity
+}
+
+// hasPortfolioLots returns true if the portfolio's positions include their
+// lots. A portfolio without positions has all of its (no) lots.
+func hasPortfolioLots(positions []model.Position) bool {
+	for i := range positions {
+		if positions[i].Lots != nil {
+			return true
+		}
+	}
+	return len(positions) == 0
+}
+
+// getPortfolioDiffChange returns how a holding changed between the two
+// portfolios. The quantities are compared with their signs, so a short
+// holding that grew has decreased.
+func getPortfolioDiffChange(from *portfolioHolding, to *portfolioHolding) string {
+	isFromHeld := math.Abs(from.quantity) >= gainsQuantityTolerance
+	isToHeld := math.Abs(to.quantity) >= gainsQuantityTolerance
+	switch {
+	case !isFromHeld && isToHeld:
+		return portfolioDiffAdded
+	case isFromHeld && !isToHeld:
+		return portfolioDiffRemoved
+	case to.quantity-from.quantity >= gainsQuantityTolerance:
+		return portfolioDiffIncreased
+	case from.quantity-to.quantity >= gainsQuantityTolerance:
+		return portfolioDiffDecreased
+	default:
+		return portfolioDiffUnchanged
+	}
+}
+
+// getPnlContribution returns how much a holding's unrealized gain grew on the
+// quantity that's still held in the later portfolio. The gain on the quantity
+// that was sold (or, for a short holding, covered) in between is realized, so
+// it isn't in either portfolio. None of a holding that changed sides is still
+// held.
+func getPnlContribution(from *portfolioHolding, to *portfolioHolding) float64 {
+	heldShare := 1.0
+	if from.quantity != 0 {
+		heldShare = math.Min(math.Max(to.quantity/from.quantity, 0), 1)
+	}
+	return to.totalGain - from.totalGain*heldShare
+}
+
+// getPnlContribution returns the position's P&L contribution, from its lots
+// if both portfolios have them, since they show which shares were sold.
+func (p *portfolioDiffPosition) getPnlContribution() float64 {
+	if p.lots == nil {
+		return getPnlContribution(&p.from, &p.to)
+	}
+	pnlContribution := 0.0
+	for _, lot := range p.lots {
+		pnlContribution += getPnlContribution(&lot.from, &lot.to)
+	}
+	return pnlContribution
+}
+
+// getPortfolioDiffJsonMap returns the positions' changes and their totals.
+// Positions whose quantities didn't change are included, since their market
+// values did.
+func getPortfolioDiffJsonMap(positions []*portfolioDiffPosition) jsonmap.JsonMap {
+	positionSlice := jsonmap.JsonSlice{}
+	totals := struct {
+		from, to                portfolioHolding
+		pnlContribution         float64
+		added, removed, changed int
+	}{}
+	for _, position := range positions {
+		change := getPortfolioDiffChange(&position.from, &position.to)
+		pnlContribution := position.getPnlContribution()
+		totals.from.add(position.from.quantity, position.from.marketValue, position.from.totalGain)
+		totals.to.add(position.to.quantity, position.to.marketValue, position.to.totalGain)
+		totals.pnlContribution += pnlContribution
+		switch change {
+		case portfolioDiffAdded:
+			totals.added += 1
+		case portfolioDiffRemoved:
+			totals.removed += 1
+		case portfolioDiffIncreased, portfolioDiffDecreased:
+			totals.changed += 1
+		}
+		positionMap := getPortfolioHoldingsJsonMap(&position.from, &position.to, change, pnlContribution)
+		positionMap["symbol"] = position.symbol
+		positionMap["securityType"] = position.securityType
+		positionMap["positionType"] = position.positionType
+		positionMap["symbolDescription"] = position.description
+		if position.lots != nil {
+			positionMap["lots"] = getPortfolioDiffLotsJsonSlice(position.lots)
+		}
+		positionSlice = append(positionSlice, positionMap)
+	}
+	return jsonmap.JsonMap{
+		"positions": positionSlice,
+		"totals": jsonmap.JsonMap{
+			"fromMarketValue":   roundToCents(totals.from.marketValue),
+			"toMarketValue":     roundToCents(totals.to.marketValue),
+			"marketValueChange": roundToCents(totals.to.marketValue - totals.from.marketValue),
+			"pnlContribution":   roundToCents(totals.pnlContribution),
+			"addedPositions":    totals.added,
+			"removedPositions":  totals.removed,
+			"changedPositions":  totals.changed,
+		},
+	}
+}
+
+func getPortfolioDiffLotsJsonSlice(lots map[int64]*portfolioDiffLot) jsonmap.JsonSlice {
+	sortedLots := make([]*portfolioDiffLot, 0, len(lots))
+	for _, lot := range lots {
+		sortedLots = append(sortedLots, lot)
+	}
+	sort.Slice(
+		sortedLots, func(i, j int) bool {
+			if sortedLots[i].acquiredDate != sortedLots[j].acquiredDate {
+				return sortedLots[i].acquiredDate < sortedLots[j].acquiredDate
+			}
+			return sortedLots[i].lotId < sortedLots[j].lotId
+		},
+	)
+	lotSlice := jsonmap.JsonSlice{}
+	for _, lot := range sortedLots {
+		lotMap := getPortfolioHoldingsJsonMap(
+			&lot.from, &lot.to, getPortfolioDiffChange(&lot.from, &lot.to), getPnlContribution(&lot.from, &lot.to),
+		)
+		lotMap["positionLotId"] = lot.lotId
+		lotMap["acquiredDate"] = lot.acquiredDate
+		lotSlice = append(lotSlice, lotMap)
+	}
+	return lotSlice
+}
+
+func getPortfolioHoldingsJsonMap(
+	from *portfolioHolding, to *portfolioHolding, change string, pnlContribution float64,
+) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"change":            change,
+		"fromQuantity":      from.quantity,
+		"toQuantity":        to.quantity,
+		"quantityChange":    to.quantity - from.quantity,
+		"fromMarketValue":   roundToCents(from.marketValue),
+		"toMarketValue":     roundToCents(to.marketValue),
+		"marketValueChange": roundToCents(to.marketValue - from.marketValue),
+		"pnlContribution":   roundToCents(pnlContribution),
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCalculatePortfolioDiff(t *testing.T) {
+	testFromPositions := []model.Position{
+		{
+			Product: &model.Product{Symbol: "AAPL", SecurityType: "EQ"}, Quantity: 10, MarketValue: 1500,
+			TotalGain: 500,
+			Lots: []model.Lot{
+				{PositionLotId: 1, AcquiredDate: 1704067200000, RemainingQty: 5, MarketValue: 750, TotalGain: 250},
+				{PositionLotId: 2, AcquiredDate: 1706745600000, RemainingQty: 5, MarketValue: 750, TotalGain: 250},
+			},
+		},
+		{
+			Product: &model.Product{Symbol: "MSFT", SecurityType: "EQ"}, Quantity: 5, MarketValue: 2000,
+			TotalGain: 100,
+			Lots: []model.Lot{
+				{PositionLotId: 3, AcquiredDate: 1704067200000, RemainingQty: 5, MarketValue: 2000, TotalGain: 100},
+			},
+		},
+	}
+	testToPositions := []model.Position{
+		{
+			Product: &model.Product{Symbol: "GOOG", SecurityType: "EQ"}, Quantity: 2, MarketValue: 300,
+			TotalGain: 10,
+			Lots: []model.Lot{
+				{PositionLotId: 4, AcquiredDate: 1709251200000, RemainingQty: 2, MarketValue: 300, TotalGain: 10},
+			},
+		},
+		{
+			Product: &model.Product{Symbol: "AAPL", SecurityType: "EQ"}, Quantity: 5, MarketValue: 800,
+			TotalGain: 300,
+			Lots: []model.Lot{
+				{PositionLotId: 2, AcquiredDate: 1706745600000, RemainingQty: 5, MarketValue: 800, TotalGain: 300},
+			},
+		},
+	}
+	withoutLots := func(positions []model.Position) []model.Position {
+		positionsWithoutLots := make([]model.Position, 0, len(positions))
+		for _, position := range positions {
+			position.Lots = nil
+			positionsWithoutLots = append(positionsWithoutLots, position)
+		}
+		return positionsWithoutLots
+	}
+	expectTotals := jsonmap.JsonMap{
+		"fromMarketValue": 3500.0, "toMarketValue": 1100.0, "marketValueChange": -2400.0, "pnlContribution": 60.0,
+		"addedPositions": 1, "removedPositions": 1, "changedPositions": 1,
+	}
+	expectApplePosition := jsonmap.JsonMap{
+		"symbol": "AAPL", "securityType": "EQ", "positionType": "LONG", "symbolDescription": "", "change": "decreased",
+		"fromQuantity": 10.0, "toQuantity": 5.0, "quantityChange": -5.0, "fromMarketValue": 1500.0,
+		"toMarketValue": 800.0, "marketValueChange": -700.0, "pnlContribution": 50.0,
+	}
+	expectGooglePosition := jsonmap.JsonMap{
+		"symbol": "GOOG", "securityType": "EQ", "positionType": "LONG", "symbolDescription": "", "change": "added",
+		"fromQuantity": 0.0, "toQuantity": 2.0, "quantityChange": 2.0, "fromMarketValue": 0.0,
+		"toMarketValue": 300.0, "marketValueChange": 300.0, "pnlContribution": 10.0,
+	}
+	expectMicrosoftPosition := jsonmap.JsonMap{
+		"symbol": "MSFT", "securityType": "EQ", "positionType": "LONG", "symbolDescription": "", "change": "removed",
+		"fromQuantity": 5.0, "toQuantity": 0.0, "quantityChange": -5.0, "fromMarketValue": 2000.0,
+		"toMarketValue": 0.0, "marketValueChange": -2000.0, "pnlContribution": 0.0,
+	}
+	withLotsMap := func(position jsonmap.JsonMap, lots jsonmap.JsonSlice) jsonmap.JsonMap {
+		positionWithLots := jsonmap.JsonMap{"lots": lots}
+		for key, value := range position {
+			positionWithLots[key] = value
+		}
+		return positionWithLots
+	}
+
+	tests := []struct {
+		name              string
+		testFromPositions []model.Position
+		testToPositions   []model.Position
+		expectValue       jsonmap.JsonMap
+	}{
+		{
+			name:              "Compares Positions By Lot",
+			testFromPositions: testFromPositions,
+			testToPositions:   testToPositions,
+			expectValue: jsonmap.JsonMap{
+				"positions": jsonmap.JsonSlice{
+					withLotsMap(
+						expectApplePosition, jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"positionLotId": int64(1), "acquiredDate": int64(1704067200000), "change": "removed",
+								"fromQuantity": 5.0, "toQuantity": 0.0, "quantityChange": -5.0,
+								"fromMarketValue": 750.0, "toMarketValue": 0.0, "marketValueChange": -750.0,
+								"pnlContribution": 0.0,
+							},
+							jsonmap.JsonMap{
+								"positionLotId": int64(2), "acquiredDate": int64(1706745600000),
+								"change": "unchanged", "fromQuantity": 5.0, "toQuantity": 5.0, "quantityChange": 0.0,
+								"fromMarketValue": 750.0, "toMarketValue": 800.0, "marketValueChange": 50.0,
+								"pnlContribution": 50.0,
+							},
+						},
+					),
+					withLotsMap(
+						expectGooglePosition, jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"positionLotId": int64(4), "acquiredDate": int64(1709251200000), "change": "added",
+								"fromQuantity": 0.0, "toQuantity": 2.0, "quantityChange": 2.0,
+								"fromMarketValue": 0.0, "toMarketValue": 300.0, "marketValueChange": 300.0,
+								"pnlContribution": 10.0,
+							},
+						},
+					),
+					withLotsMap(
+						expectMicrosoftPosition, jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"positionLotId": int64(3), "acquiredDate": int64(1704067200000), "change": "removed",
+								"fromQuantity": 5.0, "toQuantity": 0.0, "quantityChange": -5.0,
+								"fromMarketValue": 2000.0, "toMarketValue": 0.0, "marketValueChange": -2000.0,
+								"pnlContribution": 0.0,
+							},
+						},
+					),
+				},
+				"totals": expectTotals,
+			},
+		},
+		{
+			name:              "Compares Positions Without Lots",
+			testFromPositions: withoutLots(testFromPositions),
+			testToPositions:   testToPositions,
+			expectValue: jsonmap.JsonMap{
+				"positions": jsonmap.JsonSlice{
+					expectApplePosition,
+					expectGooglePosition,
+					expectMicrosoftPosition,
+				},
+				"totals": expectTotals,
+			},
+		},
+		{
+			name: "Compares Short Positions By Signed Quantity",
+			testFromPositions: []model.Position{
+				{
+					Product: &model.Product{Symbol: "TSLA", SecurityType: "EQ"}, PositionType: "SHORT", Quantity: 100,
+					MarketValue: -20000, TotalGain: 500,
+				},
+				{
+					Product: &model.Product{Symbol: "XYZ", SecurityType: "EQ"}, Quantity: 100, MarketValue: 1000,
+					TotalGain: 100,
+				},
+			},
+			testToPositions: []model.Position{
+				{
+					Product: &model.Product{Symbol: "TSLA", SecurityType: "EQ"}, PositionType: "SHORT", Quantity: 50,
+					MarketValue: -9000, TotalGain: 1000,
+				},
+				{
+					Product: &model.Product{Symbol: "XYZ", SecurityType: "EQ"}, Quantity: -100, MarketValue: -1000,
+					TotalGain: -20,
+				},
+			},
+			expectValue: jsonmap.JsonMap{
+				"positions": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"symbol": "TSLA", "securityType": "EQ", "positionType": "SHORT", "symbolDescription": "",
+						"change": "increased", "fromQuantity": -100.0, "toQuantity": -50.0, "quantityChange": 50.0,
+						"fromMarketValue": -20000.0, "toMarketValue": -9000.0, "marketValueChange": 11000.0,
+						"pnlContribution": 750.0,
+					},
+					jsonmap.JsonMap{
+						"symbol": "XYZ", "securityType": "EQ", "positionType": "LONG", "symbolDescription": "",
+						"change": "removed", "fromQuantity": 100.0, "toQuantity": 0.0, "quantityChange": -100.0,
+						"fromMarketValue": 1000.0, "toMarketValue": 0.0, "marketValueChange": -1000.0,
+						"pnlContribution": 0.0,
+					},
+					jsonmap.JsonMap{
+						"symbol": "XYZ", "securityType": "EQ", "positionType": "SHORT", "symbolDescription": "",
+						"change": "added", "fromQuantity": 0.0, "toQuantity": -100.0, "quantityChange": -100.0,
+						"fromMarketValue": 0.0, "toMarketValue": -1000.0, "marketValueChange": -1000.0,
+						"pnlContribution": -20.0,
+					},
+				},
+				"totals": jsonmap.JsonMap{
+					"fromMarketValue": -19000.0, "toMarketValue": -10000.0, "marketValueChange": 9000.0,
+					"pnlContribution": 730.0, "addedPositions": 1, "removedPositions": 1, "changedPositions": 1,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := getPortfolioDiffJsonMap(calculatePortfolioDiff(tt.testFromPositions, tt.testToPositions))
+
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestGetPortfolioDiffChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		testFrom     float64
+		testTo       float64
+		expectChange string
+	}{
+		{name: "Long To Short Decreased", testFrom: 100, testTo: -100, expectChange: "decreased"},
+		{name: "Larger Short Decreased", testFrom: -100, testTo: -150, expectChange: "decreased"},
+		{name: "Covered Short Increased", testFrom: -100, testTo: -50, expectChange: "increased"},
+		{name: "Same Short Unchanged", testFrom: -100, testTo: -100, expectChange: "unchanged"},
+		{name: "New Short Added", testFrom: 0, testTo: -100, expectChange: "added"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualChange := getPortfolioDiffChange(
+					&portfolioHolding{quantity: tt.testFrom}, &portfolioHolding{quantity: tt.testTo},
+				)
+
+				assert.Equal(t, tt.expectChange, actualChange)
+			},
+		)
+	}
+}
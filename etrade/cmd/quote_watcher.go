@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"io"
+	"strings"
+	"time"
+)
+
+// quoteWatchHeaders are the headers of the quoteListAllDescriptor values
+// that a quote watcher shows, in the order that it shows them. The symbol
+// must be first.
+var quoteWatchHeaders = []string{
+	"Symbol", "Last Trade", "Change Close $", "Change Close %", "Bid", "Ask", "Total Volume",
+}
+
+const (
+	ansiReset       = "\x1b[0m"
+	ansiGreen       = "\x1b[32m"
+	ansiRed         = "\x1b[31m"
+	ansiClearToEnd  = "\x1b[J"
+	ansiCursorUpFmt = "\x1b[%dA\r"
+)
+
+// quoteWatcher polls quotes for some symbols and writes them to its output.
+// On a terminal, it redraws a table of the latest quotes in place and
+// highlights the quotes whose last trade price moved since the previous
+// poll. Otherwise, it appends a CSV line for each quote that it receives or,
+// if it has a renderer (e.g. for JSON output), renders the quotes from each
+// poll with it.
+type quoteWatcher struct {
+	output     io.Writer
+	renderer   Renderer
+	isTerminal bool
+	symbols    []string
+	interval   time.Duration
+	logger     *slog.Logger
+	now        func() time.Time
+	values     []RenderValue
+
+	csvWriter  *csv.Writer
+	quotes     map[string]jsonmap.JsonMap
+	moves      map[string]int
+	linesDrawn int
+}
+
+func newQuoteWatcher(
+	output io.Writer, renderer Renderer, isTerminal bool, symbols []string, interval time.Duration,
+	logger *slog.Logger, now func() time.Time,
+) (*quoteWatcher, error) {
+	headers := quoteWatchHeaders
+	if !isTerminal {
+		// Appended lines need the time of the quote; the table shows the time
+		// of the last update instead.
+		headers = append([]string{"Date"}, headers...)
+	}
+	values, err := getQuoteListAllValues(headers)
+	if err != nil {
+		return nil, err
+	}
+	return &quoteWatcher{
+		output:     output,
+		renderer:   renderer,
+		isTerminal: isTerminal,
+		symbols:    symbols,
+		interval:   interval,
+		logger:     logger,
+		now:        now,
+		values:     values,
+		quotes:     map[string]jsonmap.JsonMap{},
+		moves:      map[string]int{},
+	}, nil
+}
+
+// Run polls quotes every interval and renders them until the context is
+// canceled. It returns an error if the first poll fails, since there's
+// nothing to watch. Later failures are shown (or logged) and polling
+// continues.
+func (w *quoteWatcher) Run(ctx context.Context, eTradeClient client.ETradeClient) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	isFirstPoll := true
+	for {
+		quotes, err := getWatchedQuotes(eTradeClient, w.symbols)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && isFirstPoll {
+			return err
+		}
+		isFirstPoll = false
+		if err = w.Render(quotes, err); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Render renders the quotes from a poll. pollErr is the error from the poll,
+// if any.
+func (w *quoteWatcher) Render(quotes []jsonmap.JsonMap, pollErr error) error {
+	var received []jsonmap.JsonMap
+	for _, quote := range quotes {
+		symbol, err := quote.GetStringAtPath(".product.symbol")
+		if err != nil {
+			w.logger.Error(fmt.Errorf("quote watcher received a quote without a symbol (%w)", err).Error())
+			continue
+		}
+		symbol = strings.ToUpper(symbol)
+		w.moves[symbol] = 0
+		if previous, ok := w.quotes[symbol]; ok {
+			previousLastTrade, previousErr := previous.GetFloatAtPath(".all.lastTrade")
+			lastTrade, err := quote.GetFloatAtPath(".all.lastTrade")
+			if previousErr == nil && err == nil {
+				if lastTrade > previousLastTrade {
+					w.moves[symbol] = 1
+				} else if lastTrade < previousLastTrade {
+					w.moves[symbol] = -1
+				}
+			}
+		}
+		w.quotes[symbol] = quote
+		received = append(received, quote)
+	}
+	if w.isTerminal {
+		return w.renderTable(pollErr)
+	}
+	if pollErr != nil {
+		w.logger.Error(fmt.Errorf("getting quotes failed (%w)", pollErr).Error())
+	}
+	if w.renderer != nil {
+		return w.renderer.Render(
+			jsonmap.JsonMap{"quotes": jsonMapsAsJsonSlice(received)},
+			[]RenderDescriptor{{ObjectPath: ".quotes", Values: w.values, DefaultValue: ""}},
+		)
+	}
+	return w.renderLines(received)
+}
+
+func (w *quoteWatcher) renderLines(quotes []jsonmap.JsonMap) error {
+	if w.csvWriter == nil {
+		w.csvWriter = csv.NewWriter(w.output)
+		if err := w.csvWriter.Write(getHeadersForRenderValues(w.values)); err != nil {
+			return err
+		}
+	}
+	for _, quote := range quotes {
+		if err := w.csvWriter.Write(getValuesForRenderValues(quote, w.values, "")); err != nil {
+			return err
+		}
+	}
+	w.csvWriter.Flush()
+	return w.csvWriter.Error()
+}
+
+func (w *quoteWatcher) renderTable(pollErr error) error {
+	rows := [][]string{getHeadersForRenderValues(w.values)}
+	for _, symbol := range w.symbols {
+		row := getValuesForRenderValues(w.quotes[symbol], w.values, "")
+		row[0] = symbol
+		rows = append(rows, row)
+	}
+	widths := make([]int, len(w.values))
+	for _, row := range rows {
+		for i, value := range row {
+			if len(value) > widths[i] {
+				widths[i] = len(value)
+			}
+		}
+	}
+
+	lines := []string{
+		fmt.Sprintf("Every %s, updated %s (Ctrl-C to stop)", w.interval, w.now().Format(time.TimeOnly)),
+	}
+	for rowIndex, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			// Left-align the symbol and right-align the numbers.
+			if i == 0 {
+				cells[i] = fmt.Sprintf("%-*s", widths[i], value)
+			} else {
+				cells[i] = fmt.Sprintf("%*s", widths[i], value)
+			}
+		}
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+		if rowIndex > 0 {
+			switch w.moves[w.symbols[rowIndex-1]] {
+			case 1:
+				line = ansiGreen + line + ansiReset
+			case -1:
+				line = ansiRed + line + ansiReset
+			}
+		}
+		lines = append(lines, line)
+	}
+	if pollErr != nil {
+		lines = append(lines, fmt.Sprintf("Getting quotes failed: %s", pollErr))
+	}
+
+	var output strings.Builder
+	if w.linesDrawn > 0 {
+		output.WriteString(fmt.Sprintf(ansiCursorUpFmt, w.linesDrawn))
+	}
+	output.WriteString(ansiClearToEnd)
+	for _, line := range lines {
+		output.WriteString(line)
+		output.WriteString("\n")
+	}
+	w.linesDrawn = len(lines)
+	_, err := io.WriteString(w.output, output.String())
+	return err
+}
+
+// getWatchedQuotes gets quotes for the symbols, in batches of up to
+// GetQuotesMaxSymbols. If a batch fails, it returns the quotes that it got
+// along with the error.
+func getWatchedQuotes(eTradeClient client.ETradeClient, symbols []string) ([]jsonmap.JsonMap, error) {
+	var quotes []jsonmap.JsonMap
+	for start := 0; start < len(symbols); start += constants.GetQuotesMaxSymbols {
+		end := start + constants.GetQuotesMaxSymbols
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		response, err := GetQuotes(eTradeClient, symbols[start:end], constants.QuoteDetailFlagAll, false, true)
+		if err != nil {
+			return quotes, err
+		}
+		batchQuotes, err := response.GetSliceOfMapsAtPathWithDefault(".quotes", nil)
+		if err != nil {
+			return quotes, err
+		}
+		quotes = append(quotes, batchQuotes...)
+	}
+	return quotes, nil
+}
+
+// getQuoteListAllValues returns the quoteListAllDescriptor values with the
+// headers, in the order of the headers.
+func getQuoteListAllValues(headers []string) ([]RenderValue, error) {
+	values := make([]RenderValue, 0, len(headers))
+	for _, header := range headers {
+		value, found := getQuoteListAllValue(header)
+		if !found {
+			return nil, fmt.Errorf("quoteListAllDescriptor has no %q value", header)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func getQuoteListAllValue(header string) (RenderValue, bool) {
+	for _, value := range quoteListAllDescriptor[0].Values {
+		if value.Header == header {
+			return value, true
+		}
+	}
+	return RenderValue{}, false
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuoteWatcher_Render(t *testing.T) {
+	tests := []struct {
+		name         string
+		testTerminal bool
+		testPolls    []string
+		testErr      error
+		expectOutput string
+	}{
+		{
+			name:         "Appends CSV Lines",
+			testTerminal: false,
+			testPolls: []string{
+				`[{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190, "bid": 189.5, "totalVolume": 1000}}]`,
+				`[{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 191, "bid": 190.5, "totalVolume": 2000}}]`,
+			},
+			expectOutput: "Date,Symbol,Last Trade,Change Close $,Change Close %,Bid,Ask,Total Volume\n" +
+				",AAPL,190,,,189.5,,1000\n" +
+				",AAPL,191,,,190.5,,2000\n",
+		},
+		{
+			name:         "Redraws Table With Moves Highlighted",
+			testTerminal: true,
+			testPolls: []string{
+				`[{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190}}, {"product": {"symbol": "MSFT"}, ` +
+					`"all": {"lastTrade": 330}}]`,
+				`[{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 191}}, {"product": {"symbol": "MSFT"}, ` +
+					`"all": {"lastTrade": 329}}]`,
+			},
+			expectOutput: ansiClearToEnd +
+				"Every 5s, updated 12:00:00 (Ctrl-C to stop)\n" +
+				"Symbol  Last Trade  Change Close $  Change Close %  Bid  Ask  Total Volume\n" +
+				"AAPL           190\n" +
+				"MSFT           330\n" +
+				"\x1b[4A\r" + ansiClearToEnd +
+				"Every 5s, updated 12:00:00 (Ctrl-C to stop)\n" +
+				"Symbol  Last Trade  Change Close $  Change Close %  Bid  Ask  Total Volume\n" +
+				ansiGreen + "AAPL           191" + ansiReset + "\n" +
+				ansiRed + "MSFT           329" + ansiReset + "\n",
+		},
+		{
+			name:         "Shows Error And Keeps Quotes In Table",
+			testTerminal: true,
+			testPolls: []string{
+				`[{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190}}]`,
+				`[]`,
+			},
+			testErr: errors.New("request failed"),
+			expectOutput: ansiClearToEnd +
+				"Every 5s, updated 12:00:00 (Ctrl-C to stop)\n" +
+				"Symbol  Last Trade  Change Close $  Change Close %  Bid  Ask  Total Volume\n" +
+				"AAPL           190\n" +
+				"MSFT\n" +
+				"Getting quotes failed: request failed\n" +
+				"\x1b[5A\r" + ansiClearToEnd +
+				"Every 5s, updated 12:00:00 (Ctrl-C to stop)\n" +
+				"Symbol  Last Trade  Change Close $  Change Close %  Bid  Ask  Total Volume\n" +
+				"AAPL           190\n" +
+				"MSFT\n" +
+				"Getting quotes failed: request failed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var output bytes.Buffer
+				watcher, err := newQuoteWatcher(
+					&output, nil, tt.testTerminal, []string{"AAPL", "MSFT"}, 5*time.Second,
+					etradelibtest.CreateNullLogger(),
+					func() time.Time { return time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC) },
+				)
+				require.Nil(t, err)
+				for _, poll := range tt.testPolls {
+					response, err := jsonmap.NewJsonMapFromJsonString(`{"quotes": ` + poll + `}`)
+					require.Nil(t, err)
+					quotes, err := response.GetSliceOfMaps("quotes")
+					require.Nil(t, err)
+
+					// Call the Method Under Test
+					err = watcher.Render(quotes, tt.testErr)
+					require.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectOutput, output.String())
+			},
+		)
+	}
+}
+
+func TestQuoteWatcher_Render_WithRenderer(t *testing.T) {
+	outputFile, err := os.Create(filepath.Join(t.TempDir(), "output.json"))
+	require.Nil(t, err)
+	watcher, err := newQuoteWatcher(
+		outputFile, &jsonRenderer{outputFile: outputFile, pretty: false}, false, []string{"AAPL"}, 5*time.Second,
+		etradelibtest.CreateNullLogger(), time.Now,
+	)
+	require.Nil(t, err)
+	for _, poll := range []string{`[{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190}}]`, `[]`} {
+		response, err := jsonmap.NewJsonMapFromJsonString(`{"quotes": ` + poll + `}`)
+		require.Nil(t, err)
+		quotes, err := response.GetSliceOfMaps("quotes")
+		require.Nil(t, err)
+
+		// Call the Method Under Test
+		err = watcher.Render(quotes, nil)
+		require.Nil(t, err)
+	}
+	require.Nil(t, outputFile.Close())
+
+	output, err := os.ReadFile(outputFile.Name())
+	require.Nil(t, err)
+	assert.Equal(
+		t, `{"quotes":[{"all":{"lastTrade":190},"product":{"symbol":"AAPL"}}]}`+"\n"+`{"quotes":[]}`+"\n",
+		string(output),
+	)
+}
+
+func TestGetQuoteListAllValues(t *testing.T) {
+	tests := []struct {
+		name          string
+		testHeaders   []string
+		expectErr     bool
+		expectHeaders []string
+	}{
+		{
+			name:          "Returns Values In Header Order",
+			testHeaders:   []string{"Last Trade", "Symbol"},
+			expectErr:     false,
+			expectHeaders: []string{"Last Trade", "Symbol"},
+		},
+		{
+			name:          "Fails With Unknown Header",
+			testHeaders:   []string{"Symbol", "Unknown"},
+			expectErr:     true,
+			expectHeaders: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				values, err := getQuoteListAllValues(tt.testHeaders)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				actualHeaders := []string{}
+				for _, value := range values {
+					actualHeaders = append(actualHeaders, value.Header)
+				}
+				assert.Equal(t, tt.expectHeaders, actualHeaders)
+			},
+		)
+	}
+}
+
+func TestGetWatchedQuotes(t *testing.T) {
+	symbols := make([]string, constants.GetQuotesMaxSymbols+10)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("S%02d", i)
+	}
+	mockClient := &client.ETradeClientMock{}
+	for _, batch := range [][]string{symbols[:constants.GetQuotesMaxSymbols], symbols[constants.GetQuotesMaxSymbols:]} {
+		mockClient.On(
+			"GetQuotes", batch, constants.QuoteDetailFlagAll, false, true,
+		).Return(newTestQuoteResponse(batch, 100), nil)
+	}
+
+	// Call the Method Under Test
+	quotes, err := getWatchedQuotes(mockClient, symbols)
+
+	require.Nil(t, err)
+	assert.Len(t, quotes, len(symbols))
+	mockClient.AssertExpectations(t)
+}
+
+func TestQuoteWatcher_Run_FailsIfFirstPollFails(t *testing.T) {
+	mockClient := &client.ETradeClientMock{}
+	mockClient.On(
+		"GetQuotes", []string{"AAPL"}, constants.QuoteDetailFlagAll, false, true,
+	).Return([]byte(nil), errors.New("unauthorized"))
+	var output bytes.Buffer
+	watcher, err := newQuoteWatcher(
+		&output, nil, true, []string{"AAPL"}, time.Hour, etradelibtest.CreateNullLogger(), time.Now,
+	)
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	err = watcher.Run(context.Background(), mockClient)
+
+	assert.EqualError(t, err, "unauthorized")
+	assert.Empty(t, output.String())
+}
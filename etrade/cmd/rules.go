@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RuleField is the quote or portfolio value that a rule's condition tests.
+type RuleField string
+
+const (
+	// RuleFieldLastTrade is a symbol's last trade price.
+	RuleFieldLastTrade RuleField = "lastTrade"
+
+	// RuleFieldChangePct is a symbol's change since the previous close, as a
+	// percentage.
+	RuleFieldChangePct RuleField = "changePct"
+
+	// RuleFieldSpread is the difference between a symbol's ask and bid.
+	RuleFieldSpread RuleField = "spread"
+
+	// RuleFieldSpreadPct is the difference between a symbol's ask and bid,
+	// as a percentage of their midpoint.
+	RuleFieldSpreadPct RuleField = "spreadPct"
+
+	// RuleFieldGainPct is a position's (or, without a symbol, an account's)
+	// total gain, as a percentage.
+	RuleFieldGainPct RuleField = "gainPct"
+
+	// RuleFieldDayGain is a position's (or, without a symbol, an account's)
+	// gain today.
+	RuleFieldDayGain RuleField = "dayGain"
+
+	// RuleFieldDayGainPct is a position's (or, without a symbol, an
+	// account's) gain today, as a percentage.
+	RuleFieldDayGainPct RuleField = "dayGainPct"
+)
+
+// IsPortfolioField returns true if the field comes from an account's
+// portfolio rather than from a quote.
+func (f RuleField) IsPortfolioField() bool {
+	return f == RuleFieldGainPct || f == RuleFieldDayGain || f == RuleFieldDayGainPct
+}
+
+// RuleOperator is how a rule's condition compares its field to the rule's
+// value.
+type RuleOperator string
+
+const (
+	// RuleOperatorAbove is met when the field is above the value. The rule
+	// fires when the condition becomes met, including the first time that
+	// it's evaluated.
+	RuleOperatorAbove RuleOperator = "above"
+
+	// RuleOperatorBelow is met when the field is below the value. The rule
+	// fires when the condition becomes met, including the first time that
+	// it's evaluated.
+	RuleOperatorBelow RuleOperator = "below"
+
+	// RuleOperatorCrossesAbove is met when the field rises from at or below
+	// the value to above it. It's never met the first time that it's
+	// evaluated.
+	RuleOperatorCrossesAbove RuleOperator = "crossesAbove"
+
+	// RuleOperatorCrossesBelow is met when the field falls from at or above
+	// the value to below it. It's never met the first time that it's
+	// evaluated.
+	RuleOperatorCrossesBelow RuleOperator = "crossesBelow"
+)
+
+// RuleActionType is what a rule does when it fires.
+type RuleActionType string
+
+const (
+	// RuleActionStdout writes the rule's event to standard output.
+	RuleActionStdout RuleActionType = "stdout"
+
+	// RuleActionWebhook posts the rule's event to a URL.
+	RuleActionWebhook RuleActionType = "webhook"
+
+	// RuleActionShell runs a shell command with the rule's event on its
+	// standard input.
+	RuleActionShell RuleActionType = "shell"
+)
+
+// Rule is a condition over a quote or portfolio field and the action to take
+// when the condition is met. Rules are evaluated locally (by "rules run"),
+// unlike ETrade alerts.
+type Rule struct {
+	Id          string         `json:"id"`
+	CustomerId  string         `json:"customerId"`
+	Description string         `json:"description,omitempty"`
+	Symbol      string         `json:"symbol,omitempty"`
+	AccountId   string         `json:"accountId,omitempty"`
+	Field       RuleField      `json:"field"`
+	Operator    RuleOperator   `json:"operator"`
+	Value       float64        `json:"value"`
+	Action      RuleActionType `json:"action"`
+	Target      string         `json:"target,omitempty"`
+	Created     time.Time      `json:"created"`
+}
+
+// Validate returns an error if the rule can't be evaluated or its action
+// can't be taken.
+func (r *Rule) Validate() error {
+	if r.CustomerId == "" {
+		return errors.New("a rule must have a customer")
+	}
+	switch r.Field {
+	case RuleFieldLastTrade, RuleFieldChangePct, RuleFieldSpread, RuleFieldSpreadPct:
+		if r.Symbol == "" {
+			return fmt.Errorf("a %s rule must have a symbol", r.Field)
+		}
+		if r.AccountId != "" {
+			return fmt.Errorf("a %s rule can't have an account", r.Field)
+		}
+	case RuleFieldGainPct, RuleFieldDayGain, RuleFieldDayGainPct:
+		if r.AccountId == "" {
+			return fmt.Errorf("a %s rule must have an account", r.Field)
+		}
+	default:
+		return fmt.Errorf("unknown rule field %s", r.Field)
+	}
+	switch r.Operator {
+	case RuleOperatorAbove, RuleOperatorBelow, RuleOperatorCrossesAbove, RuleOperatorCrossesBelow:
+	default:
+		return fmt.Errorf("unknown rule operator %s", r.Operator)
+	}
+	switch r.Action {
+	case RuleActionStdout:
+		if r.Target != "" {
+			return errors.New("a stdout rule can't have a target")
+		}
+	case RuleActionWebhook:
+		targetUrl, err := url.Parse(r.Target)
+		if err != nil || (targetUrl.Scheme != "http" && targetUrl.Scheme != "https") || targetUrl.Host == "" {
+			return fmt.Errorf("a webhook rule's target must be an http or https URL (got '%s')", r.Target)
+		}
+	case RuleActionShell:
+		if r.Target == "" {
+			return errors.New("a shell rule's target must be a command")
+		}
+	default:
+		return fmt.Errorf("unknown rule action %s", r.Action)
+	}
+	return nil
+}
+
+type RuleStore struct {
+	Rules []Rule `json:"rules"`
+}
+
+func LoadRuleStore(reader io.Reader) (*RuleStore, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var store RuleStore
+	if err := json.Unmarshal(bytes, &store); err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// LoadRuleStoreFromFile loads the rule store from a file. If the file doesn't
+// exist, it returns an empty store.
+func LoadRuleStoreFromFile(filename string, logger *slog.Logger) (*RuleStore, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return &RuleStore{}, nil
+	}
+	if file != nil {
+		defer func(file *os.File) {
+			err = file.Close()
+			if err != nil {
+				logger.Error(fmt.Errorf("closing rule file failed (%w)", err).Error())
+			}
+		}(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return LoadRuleStore(file)
+}
+
+func SaveRuleStore(writer io.Writer, store *RuleStore) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(store); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveRuleStoreToFile saves the rule store to a file that only the current
+// user can read, since shell rules run commands as that user.
+func SaveRuleStoreToFile(filename string, store *RuleStore, logger *slog.Logger) error {
+	dirPath := filepath.Dir(filename)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if file != nil {
+		defer func(file *os.File) {
+			err = file.Close()
+			if err != nil {
+				logger.Error(fmt.Errorf("closing rule file failed (%w)", err).Error())
+			}
+		}(file)
+	}
+	if err != nil {
+		return err
+	}
+	return SaveRuleStore(file, store)
+}
+
+// AddRule validates the rule, gives it an ID, and adds it to the store. It
+// returns the stored rule.
+func (s *RuleStore) AddRule(rule Rule, now time.Time) (*Rule, error) {
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	idBytes := make([]byte, 4)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+	rule.Id = hex.EncodeToString(idBytes)
+	rule.Created = now
+	s.Rules = append(s.Rules, rule)
+	return &s.Rules[len(s.Rules)-1], nil
+}
+
+// RemoveRule removes the rule with the ID from the store.
+func (s *RuleStore) RemoveRule(id string) error {
+	for i := range s.Rules {
+		if s.Rules[i].Id == id {
+			s.Rules = append(s.Rules[:i], s.Rules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("rule %s not found", id)
+}
+
+// GetRulesForCustomer returns the customer's rules.
+func (s *RuleStore) GetRulesForCustomer(customerId string) []Rule {
+	var rules []Rule
+	for _, rule := range s.Rules {
+		if rule.CustomerId == customerId {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// ruleShellTimeout is how long a shell action's command may run before it's
+// killed.
+const ruleShellTimeout = time.Minute
+
+// ruleActionRunner takes rules' actions.
+type ruleActionRunner struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	httpClient *http.Client
+}
+
+func newRuleActionRunner(stdout io.Writer, stderr io.Writer) *ruleActionRunner {
+	return &ruleActionRunner{
+		stdout:     stdout,
+		stderr:     stderr,
+		httpClient: newWebhookHttpClient(),
+	}
+}
+
+// Fire takes the rule's action for the event:
+//   - stdout writes the event to standard output as a line of JSON.
+//   - webhook posts the event as JSON to the rule's target URL.
+//   - shell runs the rule's target with the shell, with the event as JSON on
+//     the command's standard input and its fields in ETRADE_RULE_*
+//     environment variables.
+func (r *ruleActionRunner) Fire(rule *Rule, event jsonmap.JsonMap) error {
+	eventBytes, err := event.ToJsonBytes(false, false)
+	if err != nil {
+		return err
+	}
+	switch rule.Action {
+	case RuleActionStdout:
+		// The JSON ends with a newline.
+		_, err = r.stdout.Write(eventBytes)
+		return err
+	case RuleActionWebhook:
+		return postWebhook(r.httpClient, rule.Target, eventBytes, "", time.Now())
+	case RuleActionShell:
+		return r.runShell(rule.Target, event, eventBytes)
+	default:
+		return fmt.Errorf("unknown rule action %s", rule.Action)
+	}
+}
+
+func (r *ruleActionRunner) runShell(command string, event jsonmap.JsonMap, eventBytes []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ruleShellTimeout)
+	defer cancel()
+	var shellCommand *exec.Cmd
+	if runtime.GOOS == "windows" {
+		shellCommand = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		shellCommand = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	shellCommand.Stdin = bytes.NewReader(eventBytes)
+	shellCommand.Stdout = r.stdout
+	shellCommand.Stderr = r.stderr
+	shellCommand.Env = append(os.Environ(), getRuleEventEnvironment(event)...)
+	return shellCommand.Run()
+}
+
+// getRuleEventEnvironment returns the event's fields as environment
+// variables (e.g. ETRADE_RULE_ID and ETRADE_RULE_VALUE).
+func getRuleEventEnvironment(event jsonmap.JsonMap) []string {
+	variables := []struct {
+		name string
+		key  string
+	}{
+		{"ETRADE_RULE_ID", "ruleId"},
+		{"ETRADE_RULE_DESCRIPTION", "description"},
+		{"ETRADE_RULE_CUSTOMER_ID", "customerId"},
+		{"ETRADE_RULE_SYMBOL", "symbol"},
+		{"ETRADE_RULE_ACCOUNT_ID", "accountId"},
+		{"ETRADE_RULE_FIELD", "field"},
+		{"ETRADE_RULE_OPERATOR", "operator"},
+		{"ETRADE_RULE_THRESHOLD", "threshold"},
+		{"ETRADE_RULE_VALUE", "value"},
+		{"ETRADE_RULE_TIME", "time"},
+	}
+	environment := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		var value string
+		switch typedValue := event[variable.key].(type) {
+		case float64:
+			value = strconv.FormatFloat(typedValue, 'f', -1, 64)
+		case nil:
+		default:
+			value = fmt.Sprintf("%v", typedValue)
+		}
+		environment = append(environment, variable.name+"="+value)
+	}
+	return environment
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+var testRuleEvent = jsonmap.JsonMap{
+	"ruleId":    "rule1",
+	"symbol":    "AAPL",
+	"field":     "lastTrade",
+	"threshold": 200.0,
+	"value":     201.5,
+}
+
+func TestRuleActionRunner_Fire_Stdout(t *testing.T) {
+	var stdout bytes.Buffer
+	runner := newRuleActionRunner(&stdout, io.Discard)
+
+	// Call the Method Under Test
+	err := runner.Fire(&Rule{Action: RuleActionStdout}, testRuleEvent)
+
+	require.Nil(t, err)
+	assert.Equal(
+		t, `{"field":"lastTrade","ruleId":"rule1","symbol":"AAPL","threshold":200,"value":201.5}`+"\n",
+		stdout.String(),
+	)
+}
+
+func TestRuleActionRunner_Fire_Webhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		testStatus int
+		expectErr  string
+	}{
+		{
+			name:       "Posts Event",
+			testStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Fails For Error Status",
+			testStatus: http.StatusInternalServerError,
+			expectErr:  "webhook responded with 500 Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var receivedContentType, receivedBody string
+				webhookServer := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							receivedContentType = r.Header.Get("Content-Type")
+							body, _ := io.ReadAll(r.Body)
+							receivedBody = string(body)
+							w.WriteHeader(tt.testStatus)
+						},
+					),
+				)
+				defer webhookServer.Close()
+				runner := newRuleActionRunner(io.Discard, io.Discard)
+
+				// Call the Method Under Test
+				err := runner.Fire(&Rule{Action: RuleActionWebhook, Target: webhookServer.URL}, testRuleEvent)
+
+				if tt.expectErr != "" {
+					assert.EqualError(t, err, tt.expectErr)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, "application/json", receivedContentType)
+				assert.JSONEq(
+					t, `{"field":"lastTrade","ruleId":"rule1","symbol":"AAPL","threshold":200,"value":201.5}`,
+					receivedBody,
+				)
+			},
+		)
+	}
+}
+
+func TestRuleActionRunner_Fire_Shell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test command requires a Unix shell")
+	}
+	var stdout bytes.Buffer
+	runner := newRuleActionRunner(&stdout, io.Discard)
+
+	// Call the Method Under Test
+	err := runner.Fire(
+		&Rule{Action: RuleActionShell, Target: `echo "$ETRADE_RULE_SYMBOL $ETRADE_RULE_VALUE"; cat`}, testRuleEvent,
+	)
+
+	require.Nil(t, err)
+	assert.Equal(
+		t, "AAPL 201.5\n"+`{"field":"lastTrade","ruleId":"rule1","symbol":"AAPL","threshold":200,"value":201.5}`+"\n",
+		stdout.String(),
+	)
+
+	// Call the Method Under Test
+	err = runner.Fire(&Rule{Action: RuleActionShell, Target: "exit 3"}, testRuleEvent)
+	assert.EqualError(t, err, "exit status 3")
+}
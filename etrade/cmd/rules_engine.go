@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ruleFireFn takes a rule's action for an event.
+type ruleFireFn func(rule *Rule, event jsonmap.JsonMap) error
+
+// ruleEngine evaluates rules against quotes and portfolios. It remembers
+// each rule's previous value between evaluations, so that a rule fires once
+// when its condition becomes met rather than every time it's evaluated.
+type ruleEngine struct {
+	rules  []Rule
+	fire   ruleFireFn
+	now    func() time.Time
+	states map[string]ruleState
+}
+
+type ruleState struct {
+	value float64
+	isMet bool
+}
+
+func newRuleEngine(rules []Rule, fire ruleFireFn, now func() time.Time) *ruleEngine {
+	return &ruleEngine{
+		rules:  rules,
+		fire:   fire,
+		now:    now,
+		states: map[string]ruleState{},
+	}
+}
+
+// SetRules replaces the rules. The previous values of the rules that remain
+// are kept.
+func (e *ruleEngine) SetRules(rules []Rule) {
+	ruleIds := map[string]bool{}
+	for _, rule := range rules {
+		ruleIds[rule.Id] = true
+	}
+	for ruleId := range e.states {
+		if !ruleIds[ruleId] {
+			delete(e.states, ruleId)
+		}
+	}
+	e.rules = rules
+}
+
+// Evaluate gets the quotes and portfolios that the rules need, evaluates the
+// rules, and fires the ones whose conditions became met. It returns the
+// events for the rules that fired. A rule whose value can't be determined or
+// whose action fails doesn't stop the others; their errors are returned
+// together. A rule whose action fails fires again at the next evaluation if
+// its condition is still met.
+func (e *ruleEngine) Evaluate(eTradeClient client.ETradeClient) ([]jsonmap.JsonMap, error) {
+	var errs []error
+	quotes, err := e.getQuotes(eTradeClient)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("getting quotes failed (%w)", err))
+	}
+	portfolios, portfolioErrs := e.getPortfolios(eTradeClient)
+	errs = append(errs, portfolioErrs...)
+
+	var events []jsonmap.JsonMap
+	for i := range e.rules {
+		rule := &e.rules[i]
+		var value float64
+		if rule.Field.IsPortfolioField() {
+			portfolio, ok := portfolios[rule.AccountId]
+			if !ok {
+				// The portfolio error has already been reported.
+				continue
+			}
+			value, err = getRulePortfolioValue(rule, portfolio)
+		} else {
+			quote, ok := quotes[strings.ToUpper(rule.Symbol)]
+			if !ok {
+				if quotes != nil {
+					errs = append(errs, fmt.Errorf("rule %s: no quote for %s", rule.Id, rule.Symbol))
+				}
+				continue
+			}
+			value, err = getRuleQuoteValue(rule, quote)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %s: %w", rule.Id, err))
+			continue
+		}
+
+		previous, hasPrevious := e.states[rule.Id]
+		isMet := false
+		switch rule.Operator {
+		case RuleOperatorAbove:
+			isMet = value > rule.Value
+		case RuleOperatorBelow:
+			isMet = value < rule.Value
+		case RuleOperatorCrossesAbove:
+			isMet = hasPrevious && previous.value <= rule.Value && value > rule.Value
+		case RuleOperatorCrossesBelow:
+			isMet = hasPrevious && previous.value >= rule.Value && value < rule.Value
+		}
+		if !isMet || previous.isMet {
+			e.states[rule.Id] = ruleState{value: value, isMet: isMet}
+			continue
+		}
+
+		event := getRuleEvent(rule, value, e.now())
+		if hasPrevious {
+			event.SetFloat("previousValue", previous.value)
+		}
+		events = append(events, event)
+		if err = e.fire(rule, event); err != nil {
+			// The rule's state is left as it was, so that it fires again.
+			errs = append(errs, fmt.Errorf("rule %s: %s action failed (%w)", rule.Id, rule.Action, err))
+			continue
+		}
+		e.states[rule.Id] = ruleState{value: value, isMet: true}
+	}
+	return events, errors.Join(errs...)
+}
+
+// getQuotes returns the latest quote for each symbol that the rules need, by
+// symbol. It returns nil if the rules don't need any quotes or getting the
+// quotes failed.
+func (e *ruleEngine) getQuotes(eTradeClient client.ETradeClient) (map[string]jsonmap.JsonMap, error) {
+	symbolSet := map[string]bool{}
+	for _, rule := range e.rules {
+		if !rule.Field.IsPortfolioField() {
+			symbolSet[strings.ToUpper(rule.Symbol)] = true
+		}
+	}
+	if len(symbolSet) == 0 {
+		return nil, nil
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	quoteList, err := getWatchedQuotes(eTradeClient, symbols)
+	if err != nil {
+		return nil, err
+	}
+	quotes := map[string]jsonmap.JsonMap{}
+	for _, quote := range quoteList {
+		if symbol, err := quote.GetStringAtPath(".product.symbol"); err == nil {
+			quotes[strings.ToUpper(symbol)] = quote
+		}
+	}
+	return quotes, nil
+}
+
+// getPortfolios returns the portfolio of each account that the rules need,
+// by account ID, along with the errors for the portfolios that it couldn't
+// get.
+func (e *ruleEngine) getPortfolios(eTradeClient client.ETradeClient) (map[string]jsonmap.JsonMap, []error) {
+	portfolios := map[string]jsonmap.JsonMap{}
+	failedAccountIds := map[string]bool{}
+	var errs []error
+	for _, rule := range e.rules {
+		if !rule.Field.IsPortfolioField() || portfolios[rule.AccountId] != nil || failedAccountIds[rule.AccountId] {
+			continue
+		}
+		portfolio, err := ViewPortfolio(
+			eTradeClient, rule.AccountId, constants.PortfolioSortByNil, constants.SortOrderNil,
+			constants.MarketSessionNil, true, constants.PortfolioViewQuick, false,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("getting portfolio for account %s failed (%w)", rule.AccountId, err))
+			failedAccountIds[rule.AccountId] = true
+			continue
+		}
+		portfolios[rule.AccountId] = portfolio
+	}
+	return portfolios, errs
+}
+
+func getRuleQuoteValue(rule *Rule, quote jsonmap.JsonMap) (float64, error) {
+	switch rule.Field {
+	case RuleFieldLastTrade:
+		return quote.GetFloatAtPath(".all.lastTrade")
+	case RuleFieldChangePct:
+		return quote.GetFloatAtPath(".all.changeClosePercentage")
+	case RuleFieldSpread, RuleFieldSpreadPct:
+		bid, err := quote.GetFloatAtPath(".all.bid")
+		if err != nil {
+			return 0, err
+		}
+		ask, err := quote.GetFloatAtPath(".all.ask")
+		if err != nil {
+			return 0, err
+		}
+		if rule.Field == RuleFieldSpread {
+			return ask - bid, nil
+		}
+		midpoint := (ask + bid) / 2
+		if midpoint <= 0 {
+			return 0, fmt.Errorf("%s has no bid or ask", rule.Symbol)
+		}
+		return (ask - bid) / midpoint * 100, nil
+	default:
+		return 0, fmt.Errorf("unknown quote field %s", rule.Field)
+	}
+}
+
+// getRulePortfolioValue returns the rule's field from the position with the
+// rule's symbol (the first one, if there are several) or, if the rule has no
+// symbol, from the portfolio's totals.
+func getRulePortfolioValue(rule *Rule, portfolio jsonmap.JsonMap) (float64, error) {
+	if rule.Symbol == "" {
+		totals, err := portfolio.GetMapAtPath(".totals")
+		if err != nil {
+			return 0, err
+		}
+		switch rule.Field {
+		case RuleFieldGainPct:
+			return totals.GetFloatAtPath(".totalGainLossPct")
+		case RuleFieldDayGain:
+			return totals.GetFloatAtPath(".todaysGainLoss")
+		case RuleFieldDayGainPct:
+			return totals.GetFloatAtPath(".todaysGainLossPct")
+		}
+		return 0, fmt.Errorf("unknown portfolio field %s", rule.Field)
+	}
+
+	positions, err := portfolio.GetSliceOfMapsAtPathWithDefault(".positions", nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, position := range positions {
+		symbol, _ := position.GetStringAtPathWithDefault(".product.symbol", "")
+		if !strings.EqualFold(symbol, rule.Symbol) {
+			continue
+		}
+		switch rule.Field {
+		case RuleFieldGainPct:
+			return position.GetFloatAtPath(".totalGainPct")
+		case RuleFieldDayGain:
+			return position.GetFloatAtPath(".daysGain")
+		case RuleFieldDayGainPct:
+			return position.GetFloatAtPath(".daysGainPct")
+		}
+		return 0, fmt.Errorf("unknown portfolio field %s", rule.Field)
+	}
+	return 0, fmt.Errorf("account %s has no position in %s", rule.AccountId, rule.Symbol)
+}
+
+// getRuleEvent describes a rule firing. It's what the rule's action sends.
+func getRuleEvent(rule *Rule, value float64, now time.Time) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"ruleId":      rule.Id,
+		"description": rule.Description,
+		"customerId":  rule.CustomerId,
+		"symbol":      rule.Symbol,
+		"accountId":   rule.AccountId,
+		"field":       string(rule.Field),
+		"operator":    string(rule.Operator),
+		"threshold":   rule.Value,
+		"value":       value,
+		"time":        now.Format(time.RFC3339),
+	}
+}
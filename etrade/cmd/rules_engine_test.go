@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRuleEngine_Evaluate(t *testing.T) {
+	tests := []struct {
+		name           string
+		testOperator   RuleOperator
+		testLastTrades []float64
+		testFailures   []bool
+		expectFired    []bool
+	}{
+		{
+			name:           "Above Fires When Met And Rearms",
+			testOperator:   RuleOperatorAbove,
+			testLastTrades: []float64{199, 201, 202, 199, 201},
+			expectFired:    []bool{false, true, false, false, true},
+		},
+		{
+			name:           "Above Fires On First Evaluation",
+			testOperator:   RuleOperatorAbove,
+			testLastTrades: []float64{201, 202},
+			expectFired:    []bool{true, false},
+		},
+		{
+			name:           "Below Fires When Met",
+			testOperator:   RuleOperatorBelow,
+			testLastTrades: []float64{201, 199, 198},
+			expectFired:    []bool{false, true, false},
+		},
+		{
+			name:           "Crosses Above Doesn't Fire On First Evaluation",
+			testOperator:   RuleOperatorCrossesAbove,
+			testLastTrades: []float64{201, 200, 201, 202},
+			expectFired:    []bool{false, false, true, false},
+		},
+		{
+			name:           "Crosses Below Fires When Falling Through Value",
+			testOperator:   RuleOperatorCrossesBelow,
+			testLastTrades: []float64{201, 199, 201, 199},
+			expectFired:    []bool{false, true, false, true},
+		},
+		{
+			name:           "Above Fires Again After Action Fails",
+			testOperator:   RuleOperatorAbove,
+			testLastTrades: []float64{201, 202, 203},
+			testFailures:   []bool{true, false, false},
+			expectFired:    []bool{true, true, false},
+		},
+		{
+			name:           "Crosses Above Fires Again After Action Fails",
+			testOperator:   RuleOperatorCrossesAbove,
+			testLastTrades: []float64{199, 201, 202, 203},
+			testFailures:   []bool{false, true, false, false},
+			expectFired:    []bool{false, true, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				mockClient := &client.ETradeClientMock{}
+				for _, lastTrade := range tt.testLastTrades {
+					mockClient.On(
+						"GetQuotes", []string{"AAPL"}, constants.QuoteDetailFlagAll, false, true,
+					).Return(newTestQuoteResponse([]string{"AAPL"}, lastTrade), nil).Once()
+				}
+				rule := Rule{
+					Id: "rule1", CustomerId: "Customer", Symbol: "aapl", Field: RuleFieldLastTrade,
+					Operator: tt.testOperator, Value: 200, Action: RuleActionStdout,
+				}
+				var firedEvents []jsonmap.JsonMap
+				var isFailing bool
+				engine := newRuleEngine(
+					[]Rule{rule}, func(rule *Rule, event jsonmap.JsonMap) error {
+						firedEvents = append(firedEvents, event)
+						if isFailing {
+							return errors.New("connection refused")
+						}
+						return nil
+					}, func() time.Time { return time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC) },
+				)
+
+				for i, lastTrade := range tt.testLastTrades {
+					firedEvents = nil
+					isFailing = tt.testFailures != nil && tt.testFailures[i]
+
+					// Call the Method Under Test
+					events, err := engine.Evaluate(mockClient)
+
+					if isFailing {
+						require.Error(t, err)
+					} else {
+						require.Nil(t, err)
+					}
+					assert.Equal(t, firedEvents, events)
+					if tt.expectFired[i] {
+						require.Len(t, events, 1, "evaluation %d", i)
+						assert.Equal(t, "rule1", events[0]["ruleId"])
+						assert.Equal(t, lastTrade, events[0]["value"])
+						assert.Equal(t, "2023-09-01T12:00:00Z", events[0]["time"])
+					} else {
+						assert.Empty(t, events, "evaluation %d", i)
+					}
+				}
+				mockClient.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func TestRuleEngine_Evaluate_ReportsErrors(t *testing.T) {
+	mockClient := &client.ETradeClientMock{}
+	mockClient.On(
+		"GetQuotes", []string{"AAPL", "MSFT"}, constants.QuoteDetailFlagAll, false, true,
+	).Return(newTestQuoteResponse([]string{"AAPL"}, 201), nil)
+	rules := []Rule{
+		{
+			Id: "rule1", CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldLastTrade,
+			Operator: RuleOperatorAbove, Value: 200, Action: RuleActionWebhook,
+		},
+		{
+			Id: "rule2", CustomerId: "Customer", Symbol: "MSFT", Field: RuleFieldLastTrade,
+			Operator: RuleOperatorAbove, Value: 200, Action: RuleActionStdout,
+		},
+	}
+	engine := newRuleEngine(
+		rules, func(rule *Rule, event jsonmap.JsonMap) error {
+			return errors.New("connection refused")
+		}, time.Now,
+	)
+
+	// Call the Method Under Test
+	events, err := engine.Evaluate(mockClient)
+
+	assert.Len(t, events, 1)
+	assert.EqualError(
+		t, err, "rule rule1: webhook action failed (connection refused)\nrule rule2: no quote for MSFT",
+	)
+}
+
+func TestRuleEngine_SetRules(t *testing.T) {
+	engine := newRuleEngine(nil, nil, time.Now)
+	engine.states["rule1"] = ruleState{value: 1}
+	engine.states["rule2"] = ruleState{value: 2}
+
+	// Call the Method Under Test
+	engine.SetRules([]Rule{{Id: "rule2"}})
+
+	assert.Equal(t, map[string]ruleState{"rule2": {value: 2}}, engine.states)
+}
+
+func TestGetRuleQuoteValue(t *testing.T) {
+	quote, err := jsonmap.NewJsonMapFromJsonString(
+		`{"all": {"lastTrade": 100, "changeClosePercentage": -2.5, "bid": 99, "ask": 101}}`,
+	)
+	require.Nil(t, err)
+
+	tests := []struct {
+		field       RuleField
+		expectValue float64
+	}{
+		{field: RuleFieldLastTrade, expectValue: 100},
+		{field: RuleFieldChangePct, expectValue: -2.5},
+		{field: RuleFieldSpread, expectValue: 2},
+		{field: RuleFieldSpreadPct, expectValue: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			string(tt.field), func(t *testing.T) {
+				// Call the Method Under Test
+				actual, err := getRuleQuoteValue(&Rule{Symbol: "AAPL", Field: tt.field}, quote)
+				require.Nil(t, err)
+				assert.Equal(t, tt.expectValue, actual)
+			},
+		)
+	}
+}
+
+func TestGetRulePortfolioValue(t *testing.T) {
+	portfolio, err := jsonmap.NewJsonMapFromJsonString(
+		`{
+  "positions": [
+    {"product": {"symbol": "AAPL"}, "totalGainPct": 12.5, "daysGain": 40, "daysGainPct": 1.5},
+    {"product": {"symbol": "MSFT"}, "totalGainPct": -3, "daysGain": -10, "daysGainPct": -0.5}
+  ],
+  "totals": {"totalGainLossPct": 8, "todaysGainLoss": 30, "todaysGainLossPct": 0.75}
+}`,
+	)
+	require.Nil(t, err)
+
+	tests := []struct {
+		testSymbol  string
+		testField   RuleField
+		expectValue float64
+		expectErr   string
+	}{
+		{testSymbol: "msft", testField: RuleFieldGainPct, expectValue: -3},
+		{testSymbol: "AAPL", testField: RuleFieldDayGain, expectValue: 40},
+		{testSymbol: "AAPL", testField: RuleFieldDayGainPct, expectValue: 1.5},
+		{testSymbol: "", testField: RuleFieldGainPct, expectValue: 8},
+		{testSymbol: "", testField: RuleFieldDayGain, expectValue: 30},
+		{testSymbol: "", testField: RuleFieldDayGainPct, expectValue: 0.75},
+		{testSymbol: "GOOG", testField: RuleFieldDayGain, expectErr: "account Account has no position in GOOG"},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			fmt.Sprintf("%s %s", tt.testSymbol, tt.testField), func(t *testing.T) {
+				rule := &Rule{AccountId: "Account", Symbol: tt.testSymbol, Field: tt.testField}
+
+				// Call the Method Under Test
+				actual, err := getRulePortfolioValue(rule, portfolio)
+
+				if tt.expectErr != "" {
+					assert.EqualError(t, err, tt.expectErr)
+				} else {
+					require.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actual)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRule_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		testRule  Rule
+		expectErr string
+	}{
+		{
+			name: "Accepts Quote Rule",
+			testRule: Rule{
+				CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldLastTrade, Operator: RuleOperatorAbove,
+				Action: RuleActionStdout,
+			},
+		},
+		{
+			name: "Accepts Account Totals Rule",
+			testRule: Rule{
+				CustomerId: "Customer", AccountId: "Account", Field: RuleFieldDayGain, Operator: RuleOperatorBelow,
+				Value: -1000, Action: RuleActionWebhook, Target: "https://example.com/hook",
+			},
+		},
+		{
+			name: "Requires Customer",
+			testRule: Rule{
+				Symbol: "AAPL", Field: RuleFieldLastTrade, Operator: RuleOperatorAbove, Action: RuleActionStdout,
+			},
+			expectErr: "a rule must have a customer",
+		},
+		{
+			name: "Requires Symbol For Quote Field",
+			testRule: Rule{
+				CustomerId: "Customer", Field: RuleFieldSpread, Operator: RuleOperatorAbove, Action: RuleActionStdout,
+			},
+			expectErr: "a spread rule must have a symbol",
+		},
+		{
+			name: "Rejects Account For Quote Field",
+			testRule: Rule{
+				CustomerId: "Customer", Symbol: "AAPL", AccountId: "Account", Field: RuleFieldChangePct,
+				Operator: RuleOperatorAbove, Action: RuleActionStdout,
+			},
+			expectErr: "a changePct rule can't have an account",
+		},
+		{
+			name: "Requires Account For Portfolio Field",
+			testRule: Rule{
+				CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldGainPct, Operator: RuleOperatorAbove,
+				Action: RuleActionStdout,
+			},
+			expectErr: "a gainPct rule must have an account",
+		},
+		{
+			name: "Requires Operator",
+			testRule: Rule{
+				CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldLastTrade, Action: RuleActionStdout,
+			},
+			expectErr: "unknown rule operator ",
+		},
+		{
+			name: "Requires Webhook URL",
+			testRule: Rule{
+				CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldLastTrade, Operator: RuleOperatorAbove,
+				Action: RuleActionWebhook, Target: "example.com/hook",
+			},
+			expectErr: "a webhook rule's target must be an http or https URL (got 'example.com/hook')",
+		},
+		{
+			name: "Requires Shell Command",
+			testRule: Rule{
+				CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldLastTrade, Operator: RuleOperatorAbove,
+				Action: RuleActionShell,
+			},
+			expectErr: "a shell rule's target must be a command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				err := tt.testRule.Validate()
+				if tt.expectErr == "" {
+					assert.Nil(t, err)
+				} else {
+					assert.EqualError(t, err, tt.expectErr)
+				}
+			},
+		)
+	}
+}
+
+func TestRuleStore_AddRule(t *testing.T) {
+	store := &RuleStore{}
+	now := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	// Call the Method Under Test
+	rule, err := store.AddRule(
+		Rule{
+			CustomerId: "Customer", Symbol: "AAPL", Field: RuleFieldLastTrade, Operator: RuleOperatorAbove,
+			Action: RuleActionStdout,
+		}, now,
+	)
+
+	require.Nil(t, err)
+	assert.Len(t, rule.Id, 8)
+	assert.Equal(t, now, rule.Created)
+	assert.Equal(t, []Rule{*rule}, store.GetRulesForCustomer("Customer"))
+	assert.Empty(t, store.GetRulesForCustomer("OtherCustomer"))
+
+	// Call the Method Under Test
+	_, err = store.AddRule(Rule{CustomerId: "Customer"}, now)
+	assert.Error(t, err)
+	assert.Len(t, store.Rules, 1)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// selfSignedCertificateLifetime is how long a generated certificate is valid.
+const selfSignedCertificateLifetime = 365 * 24 * time.Hour
+
+// LoadOrCreateSelfSignedCertificate loads the server's self-signed
+// certificate and key from the files. If they don't exist, or if the
+// certificate has expired, it generates new ones (for localhost, the
+// loopback addresses, and the host's name) and saves them.
+func LoadOrCreateSelfSignedCertificate(certPath string, keyPath string, now time.Time) (tls.Certificate, error) {
+	if certificate, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		if certificate.Leaf == nil {
+			certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+		}
+		if err == nil && now.Before(certificate.Leaf.NotAfter) {
+			return certificate, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return tls.Certificate{}, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	dnsNames := []string{"localhost"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" && hostname != "localhost" {
+		dnsNames = append(dnsNames, hostname)
+	}
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "etrade server"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(selfSignedCertificateLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+
+	if err = os.MkdirAll(filepath.Dir(certPath), 0755); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err = os.MkdirAll(filepath.Dir(keyPath), 0755); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err = os.WriteFile(keyPath, keyPem, 0600); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err = os.WriteFile(certPath, certPem, 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPem, keyPem)
+}
+
+// GetCertificateFingerprint returns the SHA-256 fingerprint of a
+// certificate's leaf, which clients can use to pin a self-signed certificate.
+func GetCertificateFingerprint(certificate tls.Certificate) string {
+	if len(certificate.Certificate) == 0 {
+		return ""
+	}
+	fingerprint := sha256.Sum256(certificate.Certificate[0])
+	return hex.EncodeToString(fingerprint[:])
+}
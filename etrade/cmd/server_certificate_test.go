@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"crypto/x509"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadOrCreateSelfSignedCertificate(t *testing.T) {
+	folder := t.TempDir()
+	certPath := filepath.Join(folder, ".etrade", "server-cert.pem")
+	keyPath := filepath.Join(folder, ".etrade", "server-key.pem")
+	now := time.Now()
+
+	// Call the Method Under Test
+	certificate, err := LoadOrCreateSelfSignedCertificate(certPath, keyPath, now)
+	require.Nil(t, err)
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.Nil(t, err)
+	assert.Nil(t, leaf.VerifyHostname("localhost"))
+	assert.Nil(t, leaf.VerifyHostname("127.0.0.1"))
+	keyInfo, err := os.Stat(keyPath)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), keyInfo.Mode().Perm())
+	fingerprint := GetCertificateFingerprint(certificate)
+	assert.Len(t, fingerprint, 64)
+
+	// Call the Method Under Test
+	reloadedCertificate, err := LoadOrCreateSelfSignedCertificate(certPath, keyPath, now.Add(time.Hour))
+	require.Nil(t, err)
+	assert.Equal(t, fingerprint, GetCertificateFingerprint(reloadedCertificate))
+
+	// Call the Method Under Test
+	renewedCertificate, err := LoadOrCreateSelfSignedCertificate(
+		certPath, keyPath, now.Add(selfSignedCertificateLifetime+time.Hour),
+	)
+	require.Nil(t, err)
+	assert.NotEqual(t, fingerprint, GetCertificateFingerprint(renewedCertificate))
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyKeyHeader is the request header that identifies a write
+	// request, so that retrying the request doesn't repeat it.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotencyReplayedHeader is set on responses that were replayed from
+	// an earlier request with the same idempotency key.
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+
+	// idempotencyKeyMaxLength is the maximum length of an idempotency key.
+	idempotencyKeyMaxLength = 255
+
+	// idempotencyKeyLifetime is how long a response is kept for replay.
+	idempotencyKeyLifetime = 24 * time.Hour
+
+	// idempotencyMaxBodySize is the largest request body that's accepted with
+	// an idempotency key.
+	idempotencyMaxBodySize = 1 << 20
+)
+
+// idempotentResponse is the response to a request with an idempotency key.
+// done is closed once the response has been recorded, so that a retry made
+// while the first request is still running can wait for its response.
+type idempotentResponse struct {
+	fingerprint string
+	created     time.Time
+	done        chan struct{}
+
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// idempotencyCache keeps the responses to requests with idempotency keys.
+// Responses are kept in memory, so they're lost when the server restarts.
+type idempotencyCache struct {
+	mutex     sync.Mutex
+	responses map[string]*idempotentResponse
+	now       func() time.Time
+}
+
+func newIdempotencyCache(now func() time.Time) *idempotencyCache {
+	return &idempotencyCache{
+		responses: map[string]*idempotentResponse{},
+		now:       now,
+	}
+}
+
+// Begin returns the response for the key. If there isn't one, it creates a
+// pending response (which must be completed with Complete) and returns it
+// with isNew set to true.
+func (c *idempotencyCache) Begin(key string, fingerprint string) (response *idempotentResponse, isNew bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := c.now()
+	for existingKey, existingResponse := range c.responses {
+		if now.Sub(existingResponse.created) > idempotencyKeyLifetime && existingResponse.isDone() {
+			delete(c.responses, existingKey)
+		}
+	}
+	if existingResponse, ok := c.responses[key]; ok {
+		return existingResponse, false
+	}
+	response = &idempotentResponse{
+		fingerprint: fingerprint,
+		created:     now,
+		done:        make(chan struct{}),
+	}
+	c.responses[key] = response
+	return response, true
+}
+
+// Complete records a pending response and releases the requests waiting
+// for it.
+func (c *idempotencyCache) Complete(response *idempotentResponse, statusCode int, header http.Header, body []byte) {
+	c.mutex.Lock()
+	response.statusCode = statusCode
+	response.header = header
+	response.body = body
+	c.mutex.Unlock()
+	close(response.done)
+}
+
+func (r *idempotentResponse) isDone() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// getIdempotencyFingerprint identifies a request by its method, URL, and
+// body, so that an idempotency key can't be reused for a different request.
+func getIdempotencyFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(r.Method), []byte(r.URL.Path), []byte(r.URL.RawQuery), body} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotencyResponseRecorder passes a response through to the client while
+// recording it for replay.
+type idempotencyResponseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *idempotencyResponseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *idempotencyResponseRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyCache_Begin(t *testing.T) {
+	start := time.Date(2023, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		testCompleted  bool
+		testElapsed    time.Duration
+		expectIsNew    bool
+		expectResponse bool
+	}{
+		{
+			name:           "Returns Completed Response",
+			testCompleted:  true,
+			testElapsed:    time.Hour,
+			expectIsNew:    false,
+			expectResponse: true,
+		},
+		{
+			name:           "Returns Pending Response",
+			testCompleted:  false,
+			testElapsed:    time.Hour,
+			expectIsNew:    false,
+			expectResponse: false,
+		},
+		{
+			name:           "Expires Completed Response",
+			testCompleted:  true,
+			testElapsed:    idempotencyKeyLifetime + time.Second,
+			expectIsNew:    true,
+			expectResponse: false,
+		},
+		{
+			name:           "Doesn't Expire Pending Response",
+			testCompleted:  false,
+			testElapsed:    idempotencyKeyLifetime + time.Second,
+			expectIsNew:    false,
+			expectResponse: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				now := start
+				cache := newIdempotencyCache(func() time.Time { return now })
+				first, isNew := cache.Begin("key", "fingerprint")
+				assert.True(t, isNew)
+				if tt.testCompleted {
+					cache.Complete(first, http.StatusOK, http.Header{}, []byte("body"))
+				}
+				now = now.Add(tt.testElapsed)
+
+				// Call the Method Under Test
+				actual, isNew := cache.Begin("key", "fingerprint2")
+
+				assert.Equal(t, tt.expectIsNew, isNew)
+				if tt.expectIsNew {
+					assert.Equal(t, "fingerprint2", actual.fingerprint)
+				} else {
+					assert.Same(t, first, actual)
+				}
+				assert.Equal(t, tt.expectResponse, actual.isDone())
+			},
+		)
+	}
+}
+
+func TestGetIdempotencyFingerprint(t *testing.T) {
+	request := httptest.NewRequest("POST", "/orders?a=1", nil)
+	fingerprint := getIdempotencyFingerprint(request, []byte("symbol=AAPL"))
+
+	tests := []struct {
+		name        string
+		testMethod  string
+		testTarget  string
+		testBody    string
+		expectEqual bool
+	}{
+		{
+			name:        "Same Request",
+			testMethod:  "POST",
+			testTarget:  "/orders?a=1",
+			testBody:    "symbol=AAPL",
+			expectEqual: true,
+		},
+		{
+			name:        "Different Method",
+			testMethod:  "PUT",
+			testTarget:  "/orders?a=1",
+			testBody:    "symbol=AAPL",
+			expectEqual: false,
+		},
+		{
+			name:        "Different Query",
+			testMethod:  "POST",
+			testTarget:  "/orders?a=2",
+			testBody:    "symbol=AAPL",
+			expectEqual: false,
+		},
+		{
+			name:        "Different Body",
+			testMethod:  "POST",
+			testTarget:  "/orders?a=1",
+			testBody:    "symbol=MSFT",
+			expectEqual: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actual := getIdempotencyFingerprint(httptest.NewRequest(tt.testMethod, tt.testTarget, nil), []byte(tt.testBody))
+				assert.Equal(t, tt.expectEqual, actual == fingerprint)
+			},
+		)
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// NewServerListener listens on the Unix domain socket, if unixSocketPath
+// isn't empty, or on the TCP address otherwise. The socket file is given the
+// permissions in unixSocketMode, so that only the users allowed to open it
+// can make requests. A stale socket left by a server that didn't shut down
+// cleanly is replaced, but any other file at the path is left alone.
+func NewServerListener(addr string, unixSocketPath string, unixSocketMode os.FileMode) (net.Listener, error) {
+	if unixSocketPath == "" {
+		return net.Listen("tcp", addr)
+	}
+	if info, err := os.Lstat(unixSocketPath); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and isn't a socket", unixSocketPath)
+		}
+		if err = os.Remove(unixSocketPath); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", unixSocketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(unixSocketPath, unixSocketMode); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewServerListener(t *testing.T) {
+	t.Run(
+		"Listens On TCP Address", func(t *testing.T) {
+			// Call the Method Under Test
+			listener, err := NewServerListener("127.0.0.1:0", "", 0600)
+			require.Nil(t, err)
+			defer func() {
+				_ = listener.Close()
+			}()
+			assert.Equal(t, "tcp", listener.Addr().Network())
+		},
+	)
+	t.Run(
+		"Listens On Unix Socket With Mode", func(t *testing.T) {
+			socketPath := filepath.Join(shortTempDir(t), "etrade.sock")
+			// Call the Method Under Test
+			listener, err := NewServerListener("", socketPath, 0660)
+			require.Nil(t, err)
+			defer func() {
+				_ = listener.Close()
+			}()
+			info, err := os.Stat(socketPath)
+			require.Nil(t, err)
+			assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+			connection, err := net.Dial("unix", socketPath)
+			require.Nil(t, err)
+			_ = connection.Close()
+		},
+	)
+	t.Run(
+		"Replaces Stale Socket", func(t *testing.T) {
+			socketPath := filepath.Join(shortTempDir(t), "etrade.sock")
+			staleListener, err := net.Listen("unix", socketPath)
+			require.Nil(t, err)
+			// Leave the socket file behind, as a crashed server would.
+			staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+			_ = staleListener.Close()
+			// Call the Method Under Test
+			listener, err := NewServerListener("", socketPath, 0600)
+			require.Nil(t, err)
+			_ = listener.Close()
+		},
+	)
+	t.Run(
+		"Fails If Path Isn't A Socket", func(t *testing.T) {
+			filePath := filepath.Join(shortTempDir(t), "etrade.sock")
+			require.Nil(t, os.WriteFile(filePath, []byte("data"), 0600))
+			// Call the Method Under Test
+			_, err := NewServerListener("", filePath, 0600)
+			assert.Error(t, err)
+			fileBytes, err := os.ReadFile(filePath)
+			require.Nil(t, err)
+			assert.Equal(t, "data", string(fileBytes))
+		},
+	)
+}
+
+// shortTempDir returns a temporary directory with a path short enough for a
+// Unix domain socket (whose paths are limited to about 100 bytes).
+func shortTempDir(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "etrade")
+	require.Nil(t, err)
+	t.Cleanup(
+		func() {
+			_ = os.RemoveAll(dir)
+		},
+	)
+	return dir
+}
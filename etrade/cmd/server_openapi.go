@@ -0,0 +1,706 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// serverParameter describes a query or form parameter of a server route.
+type serverParameter struct {
+	Name        string
+	Description string
+	Type        string
+	Format      string
+	Pattern     string
+	Enum        []string
+	Default     interface{}
+	Required    bool
+	Repeated    bool
+}
+
+// serverOperation describes a server route for the OpenAPI document. Path
+// parameters are taken from the path, so only query and form parameters are
+// listed.
+type serverOperation struct {
+	Method          string
+	Path            string
+	OperationId     string
+	Tag             string
+	Summary         string
+	Description     string
+	Deprecated      bool
+	QueryParameters []serverParameter
+	FormParameters  []serverParameter
+	// Public routes don't require an API token.
+	Public bool
+	// Trading routes require an API token that allows trading.
+	Trading bool
+	// Idempotent routes require an idempotency key header.
+	Idempotent bool
+	// ContentType is the type of a successful response. If it's empty, the
+	// response is JSON.
+	ContentType string
+}
+
+// serverPathParameterDescriptions describes the server routes' path
+// parameters.
+var serverPathParameterDescriptions = map[string]string{
+	"customerId":    "The customer ID (from the customer configuration file)",
+	"accountId":     "The account ID",
+	"transactionId": "The transaction ID",
+	"orderId":       "The order ID",
+	"alertId":       "The alert ID",
+}
+
+var orderChangeParameters = []serverParameter{
+	integerParameter("quantity", "The new quantity (single-leg orders only)"),
+	enumParameter("priceType", orderPriceTypeMap, "The new price type"),
+	numberParameter("limitPrice", "The new limit price"),
+	numberParameter("stopPrice", "The new stop price"),
+	enumParameter("term", orderTermMap, "The new order term"),
+}
+
+var listOrdersParameters = []serverParameter{
+	stringParameter(
+		"symbol", "The symbol(s) for which to list orders (repeat the parameter for up to 25 symbols)",
+	).repeated(),
+	dateParameter("fromDate", "The earliest date to include (history is available for two years)"),
+	dateParameter("toDate", "The latest date to include"),
+	enumParameter("status", orderStatusMap, "List only orders with this status"),
+	enumParameter("securityType", orderSecurityTypeMap, "List only orders for securities of this type"),
+	enumParameter("transactionType", orderTransactionTypeMap, "List only orders with this transaction type"),
+	enumParameter("marketSession", marketSessionMap, "The market session from which to return results"),
+}
+
+var equityOrderParameters = []serverParameter{
+	stringParameter("symbol", "The symbol to buy or sell").required(),
+	enumParameter("action", orderActionMap, "The order action").required(),
+	integerParameter("quantity", "The number of shares").required(),
+	enumParameter("priceType", orderPriceTypeMap, "The price type").withDefault("market"),
+	numberParameter("limitPrice", "The limit price (for limit and stop-limit orders)"),
+	numberParameter("stopPrice", "The stop price (for stop and stop-limit orders)"),
+	enumParameter("term", orderTermMap, "The order term").withDefault("goodForDay"),
+	enumParameter("marketSession", marketSessionMap, "The market session").withDefault("regular"),
+	booleanParameter("allOrNone", false, "Whether to fill the entire order or none of it"),
+}
+
+// serverOperations documents every server route. The server's tests check
+// that each route has an operation here.
+var serverOperations = []serverOperation{
+	{
+		Method:      "GET",
+		Path:        "/openapi.json",
+		OperationId: "getOpenApiDocument",
+		Tag:         "documentation",
+		Summary:     "Get this OpenAPI document",
+		Public:      true,
+	},
+	{
+		Method:      "GET",
+		Path:        "/docs",
+		OperationId: "getDocs",
+		Tag:         "documentation",
+		Summary:     "Get the API documentation page",
+		Public:      true,
+		ContentType: "text/html",
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers",
+		OperationId: "listCustomers",
+		Tag:         "customers",
+		Summary:     "Get customer list",
+	},
+	{
+		Method:      "POST",
+		Path:        "/customers/{customerId}/auth",
+		OperationId: "login",
+		Tag:         "auth",
+		Summary:     "Begin authentication or, with a verify code, complete it",
+		Trading:     true,
+		FormParameters: []serverParameter{
+			stringParameter("verifyCode", "The verify code obtained from the authorization URL"),
+		},
+	},
+	{
+		Method:      "DELETE",
+		Path:        "/customers/{customerId}/auth",
+		OperationId: "logout",
+		Tag:         "auth",
+		Summary:     "Clear cached credentials",
+		Trading:     true,
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/accounts",
+		OperationId: "listAccounts",
+		Tag:         "accounts",
+		Summary:     "Get customer account list",
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/accounts/{accountId}/balance",
+		OperationId: "getAccountBalances",
+		Tag:         "accounts",
+		Summary:     "Get customer account balance",
+		QueryParameters: []serverParameter{
+			booleanParameter("realTimeBalance", true, "Whether to include real time balance"),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/accounts/{accountId}/portfolio",
+		OperationId: "viewPortfolio",
+		Tag:         "accounts",
+		Summary:     "Get customer account portfolio",
+		QueryParameters: []serverParameter{
+			booleanParameter("totalsRequired", true, "Whether to include totals"),
+			enumParameter("view", portfolioViewMap, "The portfolio view to return"),
+			enumParameter("sortBy", portfolioSortByMap, "The value by which to sort the portfolio results"),
+			enumParameter("sortOrder", sortOrderMap, "The sort order"),
+			enumParameter("marketSession", marketSessionMap, "The market session from which to return results"),
+			booleanParameter(
+				"withLots", false,
+				"Whether to include lot information for each position (this makes the request significantly slower)",
+			),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/accounts/{accountId}/transactions",
+		OperationId: "listTransactions",
+		Tag:         "accounts",
+		Summary:     "Get customer account transactions list",
+		QueryParameters: []serverParameter{
+			dateParameter("startDate", "The earliest date to include (history is available for two years)"),
+			dateParameter("endDate", "The latest date to include"),
+			enumParameter("sortOrder", sortOrderMap, "The sort order"),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/accounts/{accountId}/transactions/{transactionId}",
+		OperationId: "getTransactionDetails",
+		Tag:         "accounts",
+		Summary:     "Get customer account transaction detail",
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/accounts/{accountId}/tax/8949",
+		OperationId: "getTaxForm",
+		Tag:         "accounts",
+		Summary:     "Get customer account realized gains as a tax form",
+		Description: "Returns the realized gains on the account's sales in the year as Form 8949 rows (CSV), a TXF " +
+			"file (text/plain), or a Schedule D summary (CSV). Sales with shares that have no cost basis can only " +
+			"be returned as Form 8949 rows.",
+		QueryParameters: []serverParameter{
+			integerParameter("year", "The tax year of the sales (defaults to the current year)"),
+			enumParameter("method", lotMatchingMethodMap, "The lot matching method"),
+			stringParameter(
+				"lot", "Sell a sale's shares from a lot, as SALE_TRANSACTION_ID=LOT_ID[:QUANTITY] (requires the "+
+					"specific method; repeat the parameter for more lots)",
+			).repeated(),
+			enumParameter("format", taxFormatMap, "The tax form or file to return"),
+		},
+		ContentType: "text/csv",
+	},
+	{
+		Method:          "GET",
+		Path:            "/customers/{customerId}/accounts/{accountId}/orders",
+		OperationId:     "listOrders",
+		Tag:             "orders",
+		Summary:         "List customer account orders",
+		QueryParameters: listOrdersParameters,
+	},
+	{
+		Method:          "GET",
+		Path:            "/customers/{customerId}/accounts/{accountId}/transactions/orders",
+		OperationId:     "listOrdersDeprecated",
+		Tag:             "orders",
+		Summary:         "List customer account orders (use /customers/{customerId}/accounts/{accountId}/orders)",
+		Deprecated:      true,
+		QueryParameters: listOrdersParameters,
+	},
+	{
+		Method:      "POST",
+		Path:        "/customers/{customerId}/accounts/{accountId}/orders/preview",
+		OperationId: "previewOrder",
+		Tag:         "orders",
+		Summary:     "Preview an equity order",
+		FormParameters: append(
+			equityOrderParameters,
+			stringParameter("clientOrderId", "The client order ID for the order (one is generated if omitted)"),
+		),
+		Trading:    true,
+		Idempotent: true,
+	},
+	{
+		Method:      "POST",
+		Path:        "/customers/{customerId}/accounts/{accountId}/orders",
+		OperationId: "placeOrder",
+		Tag:         "orders",
+		Summary:     "Place a previewed equity order (with the same parameters that were previewed)",
+		FormParameters: append(
+			[]serverParameter{
+				integerParameter("previewId", "The preview ID returned when the order was previewed").required(),
+				stringParameter(
+					"clientOrderId", "The client order ID returned when the order was previewed",
+				).required(),
+			},
+			equityOrderParameters...,
+		),
+		Trading:    true,
+		Idempotent: true,
+	},
+	{
+		Method:      "PUT",
+		Path:        "/customers/{customerId}/accounts/{accountId}/orders/{orderId}/preview",
+		OperationId: "previewChangedOrder",
+		Tag:         "orders",
+		Summary:     "Preview a change to an open customer account order",
+		QueryParameters: append(
+			orderChangeParameters,
+			stringParameter("clientOrderId", "The client order ID for the change (one is generated if omitted)"),
+		),
+		Trading:    true,
+		Idempotent: true,
+	},
+	{
+		Method:      "PUT",
+		Path:        "/customers/{customerId}/accounts/{accountId}/orders/{orderId}",
+		OperationId: "placeChangedOrder",
+		Tag:         "orders",
+		Summary:     "Place a previewed change to an open customer account order",
+		QueryParameters: append(
+			[]serverParameter{
+				integerParameter("previewId", "The preview ID returned when the change was previewed").required(),
+				stringParameter(
+					"clientOrderId", "The client order ID returned when the change was previewed",
+				).required(),
+			},
+			orderChangeParameters...,
+		),
+		Trading:    true,
+		Idempotent: true,
+	},
+	{
+		Method:      "DELETE",
+		Path:        "/customers/{customerId}/accounts/{accountId}/orders/{orderId}",
+		OperationId: "cancelOrder",
+		Tag:         "orders",
+		Summary:     "Cancel customer account order",
+		Trading:     true,
+		Idempotent:  true,
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/alerts",
+		OperationId: "listAlerts",
+		Tag:         "alerts",
+		Summary:     "Get customer alert list",
+		QueryParameters: []serverParameter{
+			integerParameter("count", "Maximum number of alerts to list"),
+			stringParameter("search", "Return alerts whose subjects include the search string"),
+			enumParameter("category", alertCategoryMap, "Return only alerts in this category"),
+			enumParameter("status", alertStatusMap, "Return only alerts with this status"),
+			enumParameter("sortOrder", sortOrderMap, "The sort order"),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/alerts/{alertId}",
+		OperationId: "getAlertDetails",
+		Tag:         "alerts",
+		Summary:     "Get customer alert details",
+	},
+	{
+		Method:      "DELETE",
+		Path:        "/customers/{customerId}/alerts/{alertId}",
+		OperationId: "deleteAlert",
+		Tag:         "alerts",
+		Summary:     "Delete customer alert",
+		Trading:     true,
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/market/lookup",
+		OperationId: "lookup",
+		Tag:         "market",
+		Summary:     "Search for a company and get matching symbols",
+		QueryParameters: []serverParameter{
+			stringParameter("search", "Return symbols that match the search string").required(),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/market/quote",
+		OperationId: "getQuotes",
+		Tag:         "market",
+		Summary:     "Get quotes for one or more symbols",
+		QueryParameters: []serverParameter{
+			stringParameter(
+				"symbol", "The symbol(s) for which to quote (repeat the parameter for up to 25 symbols)",
+			).required().repeated(),
+			enumParameter("detail", quoteDetailMap, "The quote detail to return"),
+			booleanParameter("requireEarningsDate", true, "Whether to include the next earnings date"),
+			booleanParameter(
+				"skipMiniOptionsCheck", false, "Whether to skip checking whether the symbol has mini options",
+			),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/market/quote/stream",
+		OperationId: "streamQuotes",
+		Tag:         "market",
+		Summary:     "Stream quotes for one or more symbols",
+		Description: "Streams quotes as server-sent events. Each \"quote\" event's data has a symbol and the " +
+			"quote fields that changed since the symbol's previous event (the first event has the whole quote, " +
+			"and removed fields are null). Polling failures are sent as \"error\" events.",
+		QueryParameters: []serverParameter{
+			stringParameter(
+				"symbol", "The symbol(s) for which to stream quotes (repeat the parameter for more symbols)",
+			).required().repeated(),
+		},
+		ContentType: "text/event-stream",
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/market/optionchains",
+		OperationId: "getOptionChains",
+		Tag:         "market",
+		Summary:     "Get option chains for a symbol",
+		QueryParameters: []serverParameter{
+			stringParameter("symbol", "The symbol for which to get option chains").required(),
+			integerParameter("expiryYear", "Fetch option chains with this expiry year"),
+			integerParameter("expiryMonth", "Fetch option chains with this expiry month (1-12)"),
+			integerParameter("expiryDay", "Fetch option chains with this expiry day (1-31)"),
+			integerParameter("strikePriceNear", "Return option chains with a strike price nearer to this value"),
+			integerParameter("noOfStrikes", "Return option chains with this many strikes"),
+			booleanParameter("includeWeekly", true, "Whether to include weekly options"),
+			booleanParameter("skipAdjusted", false, "Whether to skip adjusted options"),
+			enumParameter("optionCategory", optionCategoryMap, "Return only options in this category"),
+			enumParameter("chainType", optionChainTypeMap, "Return only options with this chain type"),
+			enumParameter("priceType", optionPriceTypeMap, "Return only options with this price type"),
+		},
+	},
+	{
+		Method:      "GET",
+		Path:        "/customers/{customerId}/market/optionexpire",
+		OperationId: "getOptionExpireDates",
+		Tag:         "market",
+		Summary:     "Get option expire dates for a symbol",
+		QueryParameters: []serverParameter{
+			stringParameter("symbol", "The symbol for which to get option expire dates").required(),
+			enumParameter("expiryType", optionExpiryTypeMap, "Return only options with this expiration type"),
+		},
+	},
+}
+
+func stringParameter(name string, description string) serverParameter {
+	return serverParameter{Name: name, Description: description, Type: "string"}
+}
+
+func integerParameter(name string, description string) serverParameter {
+	return serverParameter{Name: name, Description: description, Type: "integer", Format: "int64"}
+}
+
+func numberParameter(name string, description string) serverParameter {
+	return serverParameter{Name: name, Description: description, Type: "number", Format: "double"}
+}
+
+func booleanParameter(name string, defaultValue bool, description string) serverParameter {
+	return serverParameter{Name: name, Description: description, Type: "boolean", Default: defaultValue}
+}
+
+// dateParameter describes a date parameter, which is formatted as MMDDYYYY.
+func dateParameter(name string, description string) serverParameter {
+	return serverParameter{
+		Name: name, Description: description + ", formatted as MMDDYYYY", Type: "string", Pattern: "^[0-9]{8}$",
+	}
+}
+
+// enumParameter describes a parameter whose allowed values are the names in
+// enumMap. The description lists each value's help.
+func enumParameter[T comparable](name string, enumMap enumValueWithHelpMap[T], description string) serverParameter {
+	values := make([]string, 0, len(enumMap))
+	for value := range enumMap {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	descriptionLines := []string{description + ":"}
+	for _, value := range values {
+		descriptionLines = append(descriptionLines, fmt.Sprintf("* `%s` - %s", value, enumMap[value].Help))
+	}
+	return serverParameter{
+		Name: name, Description: strings.Join(descriptionLines, "\n"), Type: "string", Enum: values,
+	}
+}
+
+func (p serverParameter) required() serverParameter {
+	p.Required = true
+	return p
+}
+
+func (p serverParameter) withDefault(defaultValue interface{}) serverParameter {
+	p.Default = defaultValue
+	return p
+}
+
+// repeated describes a query parameter that may be repeated to provide a
+// list of values.
+func (p serverParameter) repeated() serverParameter {
+	p.Repeated = true
+	return p
+}
+
+func (p serverParameter) schema() jsonmap.JsonMap {
+	schema := jsonmap.JsonMap{"type": p.Type}
+	if p.Format != "" {
+		schema["format"] = p.Format
+	}
+	if p.Pattern != "" {
+		schema["pattern"] = p.Pattern
+	}
+	if p.Enum != nil {
+		schema["enum"] = p.Enum
+	}
+	if p.Default != nil {
+		schema["default"] = p.Default
+	}
+	if p.Repeated {
+		return jsonmap.JsonMap{"type": "array", "items": schema}
+	}
+	return schema
+}
+
+// serverDocsHtml is the API documentation page, which renders the OpenAPI
+// document.
+//
+//go:embed server_docs.html
+var serverDocsHtml []byte
+
+var serverPathParameterRegexp = regexp.MustCompile(`{([^}]+)}`)
+
+// NewServerOpenApiDocument creates an OpenAPI 3 document that describes the
+// server's routes. It returns an error if a route has no operation in
+// serverOperations or if an operation has no route.
+func NewServerOpenApiDocument(routes chi.Routes) (jsonmap.JsonMap, error) {
+	operationsByRoute := map[string]*serverOperation{}
+	for i := range serverOperations {
+		operation := &serverOperations[i]
+		operationsByRoute[operation.Method+" "+operation.Path] = operation
+	}
+
+	paths := jsonmap.JsonMap{}
+	var undocumentedRoutes []string
+	err := chi.Walk(
+		routes, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+			// Subrouter roots (e.g. "/alerts/{alertId}/") are matched without
+			// their trailing slash.
+			if len(route) > 1 {
+				route = strings.TrimSuffix(route, "/")
+			}
+			operation, ok := operationsByRoute[method+" "+route]
+			if !ok {
+				undocumentedRoutes = append(undocumentedRoutes, method+" "+route)
+				return nil
+			}
+			delete(operationsByRoute, method+" "+route)
+			pathItem, ok := paths[route].(jsonmap.JsonMap)
+			if !ok {
+				pathItem = jsonmap.JsonMap{}
+				paths[route] = pathItem
+			}
+			pathItem[strings.ToLower(method)] = operation.asJsonMap()
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(undocumentedRoutes) > 0 {
+		sort.Strings(undocumentedRoutes)
+		return nil, fmt.Errorf("routes have no OpenAPI operation: %s", strings.Join(undocumentedRoutes, ", "))
+	}
+	if len(operationsByRoute) > 0 {
+		var missingRoutes []string
+		for route := range operationsByRoute {
+			missingRoutes = append(missingRoutes, route)
+		}
+		sort.Strings(missingRoutes)
+		return nil, fmt.Errorf("OpenAPI operations have no route: %s", strings.Join(missingRoutes, ", "))
+	}
+
+	return jsonmap.JsonMap{
+		"openapi": "3.0.3",
+		"info": jsonmap.JsonMap{
+			"title": "etrade-cli server",
+			"description": "The API served by `etrade server`. Once an API token has been created (with `etrade " +
+				"server tokens create`), every request except the documentation requires one.",
+			"version": "1.0.0",
+		},
+		"servers":  jsonmap.JsonSlice{jsonmap.JsonMap{"url": "/"}},
+		"security": jsonmap.JsonSlice{jsonmap.JsonMap{"bearerAuth": jsonmap.JsonSlice{}}},
+		"paths":    paths,
+		"components": jsonmap.JsonMap{
+			"securitySchemes": jsonmap.JsonMap{
+				"bearerAuth": jsonmap.JsonMap{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "An API token created with `etrade server tokens create`",
+				},
+			},
+			"schemas": jsonmap.JsonMap{
+				"Response": jsonmap.JsonMap{
+					"type":                 "object",
+					"description":          "The requested data, in the same form as the etrade command's JSON output",
+					"additionalProperties": true,
+				},
+				"Status": jsonmap.JsonMap{
+					"type": "object",
+					"properties": jsonmap.JsonMap{
+						"status": jsonmap.JsonMap{"type": "string", "enum": []string{"success", "error"}},
+						"error":  jsonmap.JsonMap{"type": "string"},
+					},
+					"required":             []string{"status"},
+					"additionalProperties": true,
+				},
+			},
+			"responses": jsonmap.JsonMap{
+				"Unauthorized": newOpenApiStatusResponse("A valid API token is required"),
+				"Forbidden": newOpenApiStatusResponse(
+					"The API token doesn't allow the request, or the order violates the customer's order policy",
+				),
+				"Error": newOpenApiStatusResponse("The request failed"),
+				"MissingIdempotencyKey": newOpenApiStatusResponse(
+					"The request doesn't have an " + idempotencyKeyHeader + " header",
+				),
+				"IdempotencyKeyReused": newOpenApiStatusResponse(
+					"The " + idempotencyKeyHeader + " was already used for a different request",
+				),
+			},
+		},
+	}, nil
+}
+
+func (o *serverOperation) asJsonMap() jsonmap.JsonMap {
+	parameters := jsonmap.JsonSlice{}
+	for _, match := range serverPathParameterRegexp.FindAllStringSubmatch(o.Path, -1) {
+		parameters = append(
+			parameters, jsonmap.JsonMap{
+				"name":        match[1],
+				"in":          "path",
+				"description": serverPathParameterDescriptions[match[1]],
+				"required":    true,
+				"schema":      jsonmap.JsonMap{"type": "string"},
+			},
+		)
+	}
+	if o.Idempotent {
+		parameters = append(
+			parameters, jsonmap.JsonMap{
+				"name": idempotencyKeyHeader,
+				"in":   "header",
+				"description": fmt.Sprintf(
+					"A unique key (e.g. a UUID) for the request. Retrying the request with the same key replays the "+
+						"first response (for %s) instead of repeating the request.", idempotencyKeyLifetime,
+				),
+				"required": true,
+				"schema":   jsonmap.JsonMap{"type": "string", "maxLength": idempotencyKeyMaxLength},
+			},
+		)
+	}
+	for _, p := range o.QueryParameters {
+		parameter := jsonmap.JsonMap{
+			"name":        p.Name,
+			"in":          "query",
+			"description": p.Description,
+			"required":    p.Required,
+			"schema":      p.schema(),
+		}
+		if p.Repeated {
+			parameter["style"] = "form"
+			parameter["explode"] = true
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	var successContent jsonmap.JsonMap
+	if o.ContentType == "" {
+		successContent = jsonmap.JsonMap{
+			"application/json": jsonmap.JsonMap{"schema": jsonmap.JsonMap{"$ref": "#/components/schemas/Response"}},
+		}
+	} else {
+		successContent = jsonmap.JsonMap{
+			o.ContentType: jsonmap.JsonMap{"schema": jsonmap.JsonMap{"type": "string"}},
+		}
+	}
+	responses := jsonmap.JsonMap{
+		"200": jsonmap.JsonMap{"description": "Success", "content": successContent},
+	}
+	if !o.Public {
+		responses["401"] = jsonmap.JsonMap{"$ref": "#/components/responses/Unauthorized"}
+		responses["500"] = jsonmap.JsonMap{"$ref": "#/components/responses/Error"}
+	}
+	if o.Idempotent {
+		responses["400"] = jsonmap.JsonMap{"$ref": "#/components/responses/MissingIdempotencyKey"}
+		responses["422"] = jsonmap.JsonMap{"$ref": "#/components/responses/IdempotencyKeyReused"}
+	}
+	if strings.HasPrefix(o.Path, "/customers/{customerId}") {
+		responses["403"] = jsonmap.JsonMap{"$ref": "#/components/responses/Forbidden"}
+		responses["404"] = jsonmap.JsonMap{"description": "The customer isn't configured"}
+	}
+
+	operation := jsonmap.JsonMap{
+		"operationId": o.OperationId,
+		"tags":        []string{o.Tag},
+		"summary":     o.Summary,
+		"parameters":  parameters,
+		"responses":   responses,
+	}
+	description := o.Description
+	if o.Trading {
+		description = strings.TrimSpace(description + " Requires an API token that allows trading.")
+	}
+	if description != "" {
+		operation["description"] = description
+	}
+	if o.Deprecated {
+		operation["deprecated"] = true
+	}
+	if o.Public {
+		operation["security"] = jsonmap.JsonSlice{}
+	}
+	if len(o.FormParameters) > 0 {
+		properties := jsonmap.JsonMap{}
+		for _, p := range o.FormParameters {
+			schema := p.schema()
+			schema["description"] = p.Description
+			properties[p.Name] = schema
+		}
+		operation["requestBody"] = jsonmap.JsonMap{
+			"content": jsonmap.JsonMap{
+				"application/x-www-form-urlencoded": jsonmap.JsonMap{
+					"schema": jsonmap.JsonMap{"type": "object", "properties": properties},
+				},
+			},
+		}
+	}
+	return operation
+}
+
+func newOpenApiStatusResponse(description string) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"description": description,
+		"content": jsonmap.JsonMap{
+			"application/json": jsonmap.JsonMap{"schema": jsonmap.JsonMap{"$ref": "#/components/schemas/Status"}},
+		},
+	}
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewServerOpenApiDocument_DocumentsEveryServerRoute(t *testing.T) {
+	_, cfgFolder := newEndToEndFakeServer(t)
+	logger := etradelibtest.CreateNullLogger()
+	cfgStore, err := cfgFolder.LoadCustomerConfiguration(logger)
+	require.Nil(t, err)
+	routes := NewETradeServer(
+		"", logger, cfgFolder, cfgStore, nil, 0, false, time.Second, nil, nil,
+	).Handler.(chi.Routes)
+
+	// Call the Method Under Test
+	document, err := NewServerOpenApiDocument(routes)
+
+	// This fails when a route is added to the server without an operation in
+	// serverOperations (or an operation's route is removed).
+	require.Nil(t, err)
+	paths, err := document.GetMap("paths")
+	require.Nil(t, err)
+	var operationCount int
+	_ = chi.Walk(
+		routes, func(string, string, http.Handler, ...func(http.Handler) http.Handler) error {
+			operationCount++
+			return nil
+		},
+	)
+	var documentedCount int
+	for _, pathItem := range paths {
+		documentedCount += len(pathItem.(jsonmap.JsonMap))
+	}
+	assert.Equal(t, operationCount, documentedCount)
+}
+
+func TestNewServerOpenApiDocument(t *testing.T) {
+	routes := chi.NewRouter()
+	routes.Get("/openapi.json", func(http.ResponseWriter, *http.Request) {})
+	routes.Route(
+		"/customers/{customerId}", func(r chi.Router) {
+			r.Get("/market/quote", func(http.ResponseWriter, *http.Request) {})
+			r.Route(
+				"/alerts/{alertId}", func(r chi.Router) {
+					r.Delete("/", func(http.ResponseWriter, *http.Request) {})
+				},
+			)
+		},
+	)
+
+	tests := []struct {
+		name       string
+		testPath   string
+		testMethod string
+		expectKey  string
+		expectJson string
+	}{
+		{
+			name:       "Documentation Is Public",
+			testPath:   "/openapi.json",
+			testMethod: "get",
+			expectKey:  "security",
+			expectJson: `[]`,
+		},
+		{
+			name:       "Includes Path Parameters",
+			testPath:   "/customers/{customerId}/alerts/{alertId}",
+			testMethod: "delete",
+			expectKey:  "parameters",
+			expectJson: `[
+  {
+    "description": "The customer ID (from the customer configuration file)",
+    "in": "path",
+    "name": "customerId",
+    "required": true,
+    "schema": {"type": "string"}
+  },
+  {
+    "description": "The alert ID",
+    "in": "path",
+    "name": "alertId",
+    "required": true,
+    "schema": {"type": "string"}
+  }
+]`,
+		},
+		{
+			name:       "Notes Trading Requirement",
+			testPath:   "/customers/{customerId}/alerts/{alertId}",
+			testMethod: "delete",
+			expectKey:  "description",
+			expectJson: `"Requires an API token that allows trading."`,
+		},
+		{
+			name:       "Includes Error Responses",
+			testPath:   "/customers/{customerId}/market/quote",
+			testMethod: "get",
+			expectKey:  "responses",
+			expectJson: `{
+  "200": {
+    "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Response"}}},
+    "description": "Success"
+  },
+  "401": {"$ref": "#/components/responses/Unauthorized"},
+  "403": {"$ref": "#/components/responses/Forbidden"},
+  "404": {"description": "The customer isn't configured"},
+  "500": {"$ref": "#/components/responses/Error"}
+}`,
+		},
+	}
+
+	// Only document the routes above.
+	defer useServerOperations(t, "getOpenApiDocument", "getQuotes", "deleteAlert")()
+
+	// Call the Method Under Test
+	document, err := NewServerOpenApiDocument(routes)
+	require.Nil(t, err)
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				paths := document["paths"].(jsonmap.JsonMap)
+				operation := paths[tt.testPath].(jsonmap.JsonMap)[tt.testMethod].(jsonmap.JsonMap)
+				actualJson, err := json.Marshal(operation[tt.expectKey])
+				require.Nil(t, err)
+				assert.JSONEq(t, tt.expectJson, string(actualJson))
+			},
+		)
+	}
+}
+
+func TestNewServerOpenApiDocument_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		testOperations []string
+		expectErr      string
+	}{
+		{
+			name:           "Fails For Undocumented Route",
+			testOperations: []string{"getOpenApiDocument"},
+			expectErr:      "routes have no OpenAPI operation: GET /customers/{customerId}/market/quote",
+		},
+		{
+			name:           "Fails For Operation Without Route",
+			testOperations: []string{"getOpenApiDocument", "getQuotes", "listAlerts"},
+			expectErr:      "OpenAPI operations have no route: GET /customers/{customerId}/alerts",
+		},
+	}
+
+	routes := chi.NewRouter()
+	routes.Get("/openapi.json", func(http.ResponseWriter, *http.Request) {})
+	routes.Get("/customers/{customerId}/market/quote", func(http.ResponseWriter, *http.Request) {})
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				defer useServerOperations(t, tt.testOperations...)()
+				// Call the Method Under Test
+				_, err := NewServerOpenApiDocument(routes)
+				assert.EqualError(t, err, tt.expectErr)
+			},
+		)
+	}
+}
+
+// useServerOperations replaces serverOperations with the operations that
+// have the IDs. It returns the function that restores serverOperations.
+func useServerOperations(t *testing.T, operationIds ...string) func() {
+	savedOperations := serverOperations
+	var operations []serverOperation
+	for _, operationId := range operationIds {
+		found := false
+		for _, operation := range savedOperations {
+			if operation.OperationId == operationId {
+				operations = append(operations, operation)
+				found = true
+			}
+		}
+		require.True(t, found, operationId)
+	}
+	serverOperations = operations
+	return func() {
+		serverOperations = savedOperations
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// quoteStreamKeepAliveInterval is how often a quote stream with no updates
+// sends a comment, so that proxies don't close the idle connection.
+const quoteStreamKeepAliveInterval = 15 * time.Second
+
+// quoteStream polls quotes for a customer's quote subscribers. All the
+// subscribed symbols are polled together (in as few requests as possible),
+// so subscribers that watch the same symbols don't add to the customer's API
+// usage. Subscribers receive only the quote fields that changed.
+type quoteStream struct {
+	getClient func() (client.ETradeClient, error)
+	interval  time.Duration
+	logger    *slog.Logger
+
+	// mutex guards the subscribers, the latest quotes, and the polling state.
+	mutex       sync.Mutex
+	subscribers map[*quoteSubscriber]struct{}
+	quotes      map[string]jsonmap.JsonMap
+	stopPolling context.CancelFunc
+	wake        chan struct{}
+}
+
+// quoteSubscriber is a subscription to quotes for some symbols. Updates are
+// merged into pending until the subscriber takes them, so a slow subscriber
+// never blocks polling and never misses a change.
+type quoteSubscriber struct {
+	symbols map[string]bool
+	notify  chan struct{}
+	pending map[string]jsonmap.JsonMap
+	err     error
+}
+
+// newQuoteStream creates a quote stream that gets the customer's ETrade
+// client with getClient (so that it uses the current client after a logout)
+// and polls every interval while it has subscribers.
+func newQuoteStream(
+	getClient func() (client.ETradeClient, error), interval time.Duration, logger *slog.Logger,
+) *quoteStream {
+	return &quoteStream{
+		getClient:   getClient,
+		interval:    interval,
+		logger:      logger,
+		subscribers: map[*quoteSubscriber]struct{}{},
+		quotes:      map[string]jsonmap.JsonMap{},
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// Subscribe subscribes to quotes for the symbols. The subscriber's first
+// update has the latest quote for each symbol that has already been polled;
+// the other symbols are polled right away. Unsubscribe must be called when
+// the subscriber is done.
+func (s *quoteStream) Subscribe(symbols []string) *quoteSubscriber {
+	subscriber := &quoteSubscriber{
+		symbols: map[string]bool{},
+		notify:  make(chan struct{}, 1),
+		pending: map[string]jsonmap.JsonMap{},
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	needPoll := false
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		subscriber.symbols[symbol] = true
+		if quote, ok := s.quotes[symbol]; ok {
+			subscriber.pending[symbol] = mergeQuoteChanges(jsonmap.JsonMap{}, quote)
+		} else {
+			needPoll = true
+		}
+	}
+	s.subscribers[subscriber] = struct{}{}
+	if len(subscriber.pending) > 0 {
+		subscriber.signal()
+	}
+	if s.stopPolling == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopPolling = cancel
+		go s.run(ctx)
+	} else if needPoll {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return subscriber
+}
+
+// Unsubscribe removes the subscriber. Polling stops when the last
+// subscriber is removed.
+func (s *quoteStream) Unsubscribe(subscriber *quoteSubscriber) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.subscribers, subscriber)
+	// Forget the quotes that no one is subscribed to, so that a new
+	// subscriber doesn't receive a stale quote.
+	symbols := s.getSymbols()
+	for symbol := range s.quotes {
+		if !symbols[symbol] {
+			delete(s.quotes, symbol)
+		}
+	}
+	if len(s.subscribers) == 0 && s.stopPolling != nil {
+		s.stopPolling()
+		s.stopPolling = nil
+	}
+}
+
+// TakeUpdates returns the subscriber's pending quote changes (by symbol) and
+// polling error, if any, and clears them.
+func (s *quoteStream) TakeUpdates(subscriber *quoteSubscriber) (map[string]jsonmap.JsonMap, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	updates, err := subscriber.pending, subscriber.err
+	subscriber.pending = map[string]jsonmap.JsonMap{}
+	subscriber.err = nil
+	return updates, err
+}
+
+func (s *quoteStream) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// poll gets quotes for all the subscribed symbols, in batches of up to
+// GetQuotesMaxSymbols, and publishes them.
+func (s *quoteStream) poll(ctx context.Context) {
+	s.mutex.Lock()
+	symbols := make([]string, 0, len(s.quotes))
+	for symbol := range s.getSymbols() {
+		symbols = append(symbols, symbol)
+	}
+	s.mutex.Unlock()
+	sort.Strings(symbols)
+
+	eTradeClient, err := s.getClient()
+	if err != nil {
+		s.publishError(err)
+		return
+	}
+	eTradeClient = eTradeClient.WithContext(ctx)
+	for start := 0; start < len(symbols); start += constants.GetQuotesMaxSymbols {
+		end := start + constants.GetQuotesMaxSymbols
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		response, err := GetQuotes(eTradeClient, symbols[start:end], constants.QuoteDetailFlagAll, false, true)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.publishError(err)
+			continue
+		}
+		quotes, err := response.GetSliceOfMapsAtPathWithDefault(".quotes", nil)
+		if err != nil {
+			s.publishError(err)
+			continue
+		}
+		s.publishQuotes(quotes)
+	}
+}
+
+// publishQuotes adds the quotes' changes to the pending updates of the
+// subscribers to their symbols.
+func (s *quoteStream) publishQuotes(quotes []jsonmap.JsonMap) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	symbols := s.getSymbols()
+	for _, quote := range quotes {
+		symbol, err := quote.GetStringAtPath(".product.symbol")
+		if err != nil {
+			s.logger.Error(fmt.Errorf("quote stream received a quote without a symbol (%w)", err).Error())
+			continue
+		}
+		symbol = strings.ToUpper(symbol)
+		// Ignore quotes for symbols that were unsubscribed while polling.
+		if !symbols[symbol] {
+			continue
+		}
+		changes := diffQuotes(s.quotes[symbol], quote)
+		s.quotes[symbol] = quote
+		if len(changes) == 0 {
+			continue
+		}
+		for subscriber := range s.subscribers {
+			if subscriber.symbols[symbol] {
+				if subscriber.pending[symbol] == nil {
+					subscriber.pending[symbol] = jsonmap.JsonMap{}
+				}
+				mergeQuoteChanges(subscriber.pending[symbol], changes)
+				subscriber.signal()
+			}
+		}
+	}
+}
+
+func (s *quoteStream) publishError(err error) {
+	s.logger.Error(fmt.Errorf("quote stream polling failed (%w)", err).Error())
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for subscriber := range s.subscribers {
+		subscriber.err = err
+		subscriber.signal()
+	}
+}
+
+// getSymbols returns the symbols that have subscribers. The caller must hold
+// the mutex.
+func (s *quoteStream) getSymbols() map[string]bool {
+	symbols := map[string]bool{}
+	for subscriber := range s.subscribers {
+		for symbol := range subscriber.symbols {
+			symbols[symbol] = true
+		}
+	}
+	return symbols
+}
+
+func (s *quoteSubscriber) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// diffQuotes returns the fields of current that differ from previous. Nested
+// objects are compared field by field, and fields that were removed are
+// included with nil values, so that applying the changes to previous (with
+// mergeQuoteChanges) produces current.
+func diffQuotes(previous map[string]interface{}, current map[string]interface{}) jsonmap.JsonMap {
+	changes := jsonmap.JsonMap{}
+	for key, value := range current {
+		previousValue, ok := previous[key]
+		previousMap, previousIsMap := asQuoteObject(previousValue)
+		currentMap, currentIsMap := asQuoteObject(value)
+		if ok && previousIsMap && currentIsMap {
+			if nestedChanges := diffQuotes(previousMap, currentMap); len(nestedChanges) > 0 {
+				changes[key] = nestedChanges
+			}
+		} else if !ok || !reflect.DeepEqual(previousValue, value) {
+			changes[key] = value
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			changes[key] = nil
+		}
+	}
+	return changes
+}
+
+// mergeQuoteChanges applies changes to quote and returns quote. Nested
+// objects are copied rather than shared, so that later merges don't modify
+// the changes.
+func mergeQuoteChanges(quote jsonmap.JsonMap, changes map[string]interface{}) jsonmap.JsonMap {
+	for key, value := range changes {
+		if changesMap, ok := asQuoteObject(value); ok {
+			quoteMap, ok := quote[key].(jsonmap.JsonMap)
+			if !ok {
+				quoteMap = jsonmap.JsonMap{}
+				quote[key] = quoteMap
+			}
+			mergeQuoteChanges(quoteMap, changesMap)
+		} else {
+			quote[key] = value
+		}
+	}
+	return quote
+}
+
+func asQuoteObject(value interface{}) (map[string]interface{}, bool) {
+	switch typedValue := value.(type) {
+	case jsonmap.JsonMap:
+		return typedValue, true
+	case map[string]interface{}:
+		return typedValue, true
+	default:
+		return nil, false
+	}
+}
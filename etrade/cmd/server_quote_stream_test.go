@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffQuotes(t *testing.T) {
+	tests := []struct {
+		name         string
+		testPrevious string
+		testCurrent  string
+		expectJson   string
+	}{
+		{
+			name:         "Returns Whole Quote Without Previous Quote",
+			testPrevious: `{}`,
+			testCurrent:  `{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190}}`,
+			expectJson:   `{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190}}`,
+		},
+		{
+			name:         "Returns Only Changed Fields",
+			testPrevious: `{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190, "bid": 189}, "dateTime": 1}`,
+			testCurrent:  `{"product": {"symbol": "AAPL"}, "all": {"lastTrade": 191, "bid": 189}, "dateTime": 2}`,
+			expectJson:   `{"all": {"lastTrade": 191}, "dateTime": 2}`,
+		},
+		{
+			name:         "Returns Removed Fields As Null",
+			testPrevious: `{"all": {"lastTrade": 190, "bid": 189}}`,
+			testCurrent:  `{"all": {"lastTrade": 190}}`,
+			expectJson:   `{"all": {"bid": null}}`,
+		},
+		{
+			name:         "Returns Changed Slices Whole",
+			testPrevious: `{"messages": [1, 2]}`,
+			testCurrent:  `{"messages": [1, 3]}`,
+			expectJson:   `{"messages": [1, 3]}`,
+		},
+		{
+			name:         "Returns Nothing Without Changes",
+			testPrevious: `{"all": {"lastTrade": 190}}`,
+			testCurrent:  `{"all": {"lastTrade": 190}}`,
+			expectJson:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				previous, err := jsonmap.NewJsonMapFromJsonString(tt.testPrevious)
+				require.Nil(t, err)
+				current, err := jsonmap.NewJsonMapFromJsonString(tt.testCurrent)
+				require.Nil(t, err)
+
+				// Call the Method Under Test
+				actual := diffQuotes(previous, current)
+
+				actualJson, err := json.Marshal(actual)
+				require.Nil(t, err)
+				assert.JSONEq(t, tt.expectJson, string(actualJson))
+			},
+		)
+	}
+}
+
+func TestMergeQuoteChanges(t *testing.T) {
+	pending, err := jsonmap.NewJsonMapFromJsonString(`{"all": {"lastTrade": 190, "bid": 189}}`)
+	require.Nil(t, err)
+	changes, err := jsonmap.NewJsonMapFromJsonString(`{"all": {"lastTrade": 191, "ask": 192}, "dateTime": 2}`)
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	actual := mergeQuoteChanges(pending, changes)
+
+	actualJson, err := json.Marshal(actual)
+	require.Nil(t, err)
+	assert.JSONEq(t, `{"all": {"lastTrade": 191, "bid": 189, "ask": 192}, "dateTime": 2}`, string(actualJson))
+	// The changes aren't shared with the merged quote.
+	actual["all"].(jsonmap.JsonMap)["lastTrade"] = 0
+	assert.Equal(t, `{"all":{"ask":192,"lastTrade":191},"dateTime":2}`, mustMarshalString(t, changes))
+}
+
+func TestQuoteStream_BatchesSymbols(t *testing.T) {
+	symbols := make([]string, constants.GetQuotesMaxSymbols+10)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("S%02d", i)
+	}
+	mockClient := &client.ETradeClientMock{}
+	for _, batch := range [][]string{symbols[:constants.GetQuotesMaxSymbols], symbols[constants.GetQuotesMaxSymbols:]} {
+		mockClient.On(
+			"GetQuotes", batch, constants.QuoteDetailFlagAll, false, true,
+		).Return(newTestQuoteResponse(batch, 100), nil)
+	}
+	stream := newQuoteStream(
+		func() (client.ETradeClient, error) { return mockClient, nil }, time.Hour,
+		etradelibtest.CreateNullLogger(),
+	)
+
+	// Call the Method Under Test
+	subscriber := stream.Subscribe(symbols)
+	defer stream.Unsubscribe(subscriber)
+
+	updates := takeTestQuoteUpdates(t, stream, subscriber, len(symbols))
+	assert.Len(t, updates, len(symbols))
+	mockClient.AssertExpectations(t)
+}
+
+func TestQuoteStream_SendsOnlyChanges(t *testing.T) {
+	mockClient := &client.ETradeClientMock{}
+	mockClient.On(
+		"GetQuotes", []string{"AAPL"}, constants.QuoteDetailFlagAll, false, true,
+	).Return(newTestQuoteResponse([]string{"AAPL"}, 190), nil).Once()
+	mockClient.On(
+		"GetQuotes", []string{"AAPL"}, constants.QuoteDetailFlagAll, false, true,
+	).Return(newTestQuoteResponse([]string{"AAPL"}, 191), nil)
+	// Only the first poll is automatic; the test polls again itself.
+	stream := newQuoteStream(
+		func() (client.ETradeClient, error) { return mockClient, nil }, time.Hour,
+		etradelibtest.CreateNullLogger(),
+	)
+
+	// Call the Method Under Test
+	subscriber := stream.Subscribe([]string{"aapl"})
+
+	updates := takeTestQuoteUpdates(t, stream, subscriber, 1)
+	assert.JSONEq(
+		t, `{"AAPL": {"product": {"symbol": "AAPL"}, "all": {"lastTrade": 190, "bid": 1}}}`,
+		mustMarshalString(t, updates),
+	)
+	stream.poll(context.Background())
+	updates = takeTestQuoteUpdates(t, stream, subscriber, 1)
+	assert.JSONEq(t, `{"AAPL": {"all": {"lastTrade": 191}}}`, mustMarshalString(t, updates))
+
+	// A new subscriber receives the latest quote.
+	otherSubscriber := stream.Subscribe([]string{"AAPL"})
+	updates = takeTestQuoteUpdates(t, stream, otherSubscriber, 1)
+	assert.JSONEq(
+		t, `{"AAPL": {"product": {"symbol": "AAPL"}, "all": {"lastTrade": 191, "bid": 1}}}`,
+		mustMarshalString(t, updates),
+	)
+
+	stream.Unsubscribe(subscriber)
+	stream.Unsubscribe(otherSubscriber)
+	stream.mutex.Lock()
+	assert.Nil(t, stream.stopPolling)
+	assert.Empty(t, stream.quotes)
+	stream.mutex.Unlock()
+}
+
+// newTestQuoteResponse creates a GetQuotes response with a quote for each
+// symbol.
+func newTestQuoteResponse(symbols []string, lastTrade float64) []byte {
+	quotes := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		quotes = append(
+			quotes, fmt.Sprintf(`{"Product": {"symbol": "%s"}, "All": {"lastTrade": %g, "bid": 1}}`, symbol, lastTrade),
+		)
+	}
+	return []byte(`{"QuoteResponse": {"QuoteData": [` + strings.Join(quotes, ",") + `]}}`)
+}
+
+// takeTestQuoteUpdates takes the subscriber's updates until it has updates
+// for count symbols.
+func takeTestQuoteUpdates(
+	t *testing.T, stream *quoteStream, subscriber *quoteSubscriber, count int,
+) map[string]jsonmap.JsonMap {
+	allUpdates := map[string]jsonmap.JsonMap{}
+	for len(allUpdates) < count {
+		select {
+		case <-subscriber.notify:
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timed out waiting for quote updates")
+		}
+		updates, err := stream.TakeUpdates(subscriber)
+		require.Nil(t, err)
+		for symbol, update := range updates {
+			allUpdates[symbol] = update
+		}
+	}
+	return allUpdates
+}
+
+func mustMarshalString(t *testing.T, value interface{}) string {
+	valueBytes, err := json.Marshal(value)
+	require.Nil(t, err)
+	return string(valueBytes)
+}
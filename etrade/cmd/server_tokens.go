@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerTokenCapability is what a server API token allows its bearer to do.
+type ServerTokenCapability string
+
+const (
+	// ServerTokenCapabilityRead allows requests that don't change an account
+	// or its credentials: reading accounts, alerts, orders, and market data.
+	ServerTokenCapabilityRead ServerTokenCapability = "read"
+
+	// ServerTokenCapabilityTrade allows all requests, including those that
+	// preview, place, change, or cancel orders, delete alerts, and log the
+	// customer in or out.
+	ServerTokenCapabilityTrade ServerTokenCapability = "trade"
+)
+
+// serverTokenPrefix begins every server API token, so that tokens are easy to
+// recognize (e.g. by secret scanners).
+const serverTokenPrefix = "etk_"
+
+// ServerToken is a server API token. Only a hash of the token is stored; the
+// token itself is shown once, when it's created.
+type ServerToken struct {
+	Id          string                `json:"id"`
+	Description string                `json:"description,omitempty"`
+	Hash        string                `json:"hash"`
+	CustomerIds []string              `json:"customerIds"`
+	Capability  ServerTokenCapability `json:"capability"`
+	Created     time.Time             `json:"created"`
+}
+
+// AllowsCustomer returns true if the token may be used for the customer.
+func (t *ServerToken) AllowsCustomer(customerId string) bool {
+	for _, allowedCustomerId := range t.CustomerIds {
+		if allowedCustomerId == customerId {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsTrading returns true if the token may be used for requests that
+// change an account or its credentials.
+func (t *ServerToken) AllowsTrading() bool {
+	return t.Capability == ServerTokenCapabilityTrade
+}
+
+type ServerTokenStore struct {
+	Tokens []ServerToken `json:"tokens"`
+}
+
+func LoadServerTokenStore(reader io.Reader) (*ServerTokenStore, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var store ServerTokenStore
+	if err := json.Unmarshal(bytes, &store); err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// LoadServerTokenStoreFromFile loads the token store from a file. If the file
+// doesn't exist, it returns an empty store.
+func LoadServerTokenStoreFromFile(filename string, logger *slog.Logger) (*ServerTokenStore, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return &ServerTokenStore{}, nil
+	}
+	if file != nil {
+		defer func(file *os.File) {
+			err = file.Close()
+			if err != nil {
+				logger.Error(fmt.Errorf("closing server token file failed (%w)", err).Error())
+			}
+		}(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return LoadServerTokenStore(file)
+}
+
+func SaveServerTokenStore(writer io.Writer, store *ServerTokenStore) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(store); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SaveServerTokenStoreToFile saves the token store to a file that only the
+// current user can read.
+func SaveServerTokenStoreToFile(filename string, store *ServerTokenStore, logger *slog.Logger) error {
+	dirPath := filepath.Dir(filename)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if file != nil {
+		defer func(file *os.File) {
+			err = file.Close()
+			if err != nil {
+				logger.Error(fmt.Errorf("closing server token file failed (%w)", err).Error())
+			}
+		}(file)
+	}
+	if err != nil {
+		return err
+	}
+	return SaveServerTokenStore(file, store)
+}
+
+// CreateToken adds a token to the store and returns the token's secret value
+// (which the store doesn't keep) along with the stored token.
+func (s *ServerTokenStore) CreateToken(
+	description string, customerIds []string, capability ServerTokenCapability, now time.Time,
+) (string, *ServerToken, error) {
+	if len(customerIds) == 0 {
+		return "", nil, errors.New("a token must allow at least one customer")
+	}
+	if capability != ServerTokenCapabilityRead && capability != ServerTokenCapabilityTrade {
+		return "", nil, fmt.Errorf("unknown token capability %s", capability)
+	}
+	idBytes := make([]byte, 4)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", nil, err
+	}
+	secretBytes := make([]byte, 24)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(idBytes)
+	secret := serverTokenPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+	s.Tokens = append(
+		s.Tokens, ServerToken{
+			Id:          id,
+			Description: description,
+			Hash:        hashServerToken(secret),
+			CustomerIds: customerIds,
+			Capability:  capability,
+			Created:     now,
+		},
+	)
+	return secret, &s.Tokens[len(s.Tokens)-1], nil
+}
+
+// RevokeToken removes the token with the ID from the store.
+func (s *ServerTokenStore) RevokeToken(id string) error {
+	for i := range s.Tokens {
+		if s.Tokens[i].Id == id {
+			s.Tokens = append(s.Tokens[:i], s.Tokens[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("token %s not found", id)
+}
+
+// Authenticate returns the stored token whose secret value is secret, or nil
+// if there isn't one.
+func (s *ServerTokenStore) Authenticate(secret string) *ServerToken {
+	id, _, found := strings.Cut(strings.TrimPrefix(secret, serverTokenPrefix), "_")
+	if !found || !strings.HasPrefix(secret, serverTokenPrefix) {
+		return nil
+	}
+	hash := hashServerToken(secret)
+	for i := range s.Tokens {
+		if s.Tokens[i].Id == id && subtle.ConstantTimeCompare([]byte(s.Tokens[i].Hash), []byte(hash)) == 1 {
+			return &s.Tokens[i]
+		}
+	}
+	return nil
+}
+
+func hashServerToken(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// serverTokenSource provides the server's token store. It reloads the store
+// when the token file changes, so that tokens created or revoked while the
+// server runs take effect immediately.
+type serverTokenSource struct {
+	filename string
+	logger   *slog.Logger
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	store   *ServerTokenStore
+}
+
+func newServerTokenSource(filename string, logger *slog.Logger) *serverTokenSource {
+	return &serverTokenSource{
+		filename: filename,
+		logger:   logger,
+	}
+}
+
+// GetStore returns the current token store. If the token file doesn't
+// exist, the store is empty.
+func (s *serverTokenSource) GetStore() (*ServerTokenStore, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var modTime time.Time
+	var size int64
+	if info, err := os.Stat(s.filename); err == nil {
+		modTime, size = info.ModTime(), info.Size()
+	}
+	if s.store == nil || !modTime.Equal(s.modTime) || size != s.size {
+		store, err := LoadServerTokenStoreFromFile(s.filename, s.logger)
+		if err != nil {
+			return nil, fmt.Errorf("loading server tokens failed (%w)", err)
+		}
+		s.store = store
+		s.modTime, s.size = modTime, size
+	}
+	return s.store, nil
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerTokenStore_CreateToken(t *testing.T) {
+	testTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name           string
+		testCustomers  []string
+		testCapability ServerTokenCapability
+		expectErr      bool
+	}{
+		{
+			name:           "Creates Read Token",
+			testCustomers:  []string{"customer1"},
+			testCapability: ServerTokenCapabilityRead,
+			expectErr:      false,
+		},
+		{
+			name:           "Creates Trade Token",
+			testCustomers:  []string{"customer1", "customer2"},
+			testCapability: ServerTokenCapabilityTrade,
+			expectErr:      false,
+		},
+		{
+			name:           "Fails Without Customers",
+			testCustomers:  nil,
+			testCapability: ServerTokenCapabilityRead,
+			expectErr:      true,
+		},
+		{
+			name:           "Fails With Unknown Capability",
+			testCustomers:  []string{"customer1"},
+			testCapability: "admin",
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				store := &ServerTokenStore{}
+				// Call the Method Under Test
+				secret, token, err := store.CreateToken("Test", tt.testCustomers, tt.testCapability, testTime)
+				if tt.expectErr {
+					assert.Error(t, err)
+					assert.Empty(t, store.Tokens)
+					return
+				}
+				require.Nil(t, err)
+				assert.True(t, strings.HasPrefix(secret, serverTokenPrefix+token.Id+"_"))
+				assert.NotContains(t, token.Hash, secret)
+				assert.Equal(t, tt.testCustomers, token.CustomerIds)
+				assert.Equal(t, tt.testCapability, token.Capability)
+				assert.Equal(t, testTime, token.Created)
+				assert.Equal(t, token, store.Authenticate(secret))
+			},
+		)
+	}
+}
+
+func TestServerTokenStore_Authenticate(t *testing.T) {
+	store := &ServerTokenStore{}
+	secret, token, err := store.CreateToken("", []string{"customer1"}, ServerTokenCapabilityRead, time.Now())
+	require.Nil(t, err)
+	otherSecret, _, err := store.CreateToken("", []string{"customer2"}, ServerTokenCapabilityRead, time.Now())
+	require.Nil(t, err)
+
+	tests := []struct {
+		name        string
+		testSecret  string
+		expectValue *ServerToken
+	}{
+		{
+			name:        "Accepts Token",
+			testSecret:  secret,
+			expectValue: token,
+		},
+		{
+			name:        "Rejects Modified Token",
+			testSecret:  secret + "x",
+			expectValue: nil,
+		},
+		{
+			name:        "Rejects Token With Another Id",
+			testSecret:  serverTokenPrefix + token.Id + strings.TrimPrefix(otherSecret, serverTokenPrefix)[8:],
+			expectValue: nil,
+		},
+		{
+			name:        "Rejects Malformed Token",
+			testSecret:  "Bearer",
+			expectValue: nil,
+		},
+		{
+			name:        "Rejects Empty Token",
+			testSecret:  "",
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := store.Authenticate(tt.testSecret)
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestServerTokenStore_RevokeToken(t *testing.T) {
+	store := &ServerTokenStore{}
+	secret, token, err := store.CreateToken("", []string{"customer1"}, ServerTokenCapabilityRead, time.Now())
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	err = store.RevokeToken(token.Id)
+	assert.Nil(t, err)
+	assert.Nil(t, store.Authenticate(secret))
+
+	// Call the Method Under Test
+	err = store.RevokeToken(token.Id)
+	assert.Error(t, err)
+}
+
+func TestServerToken_Allows(t *testing.T) {
+	token := &ServerToken{CustomerIds: []string{"customer1", "customer2"}, Capability: ServerTokenCapabilityRead}
+	assert.True(t, token.AllowsCustomer("customer2"))
+	assert.False(t, token.AllowsCustomer("customer3"))
+	assert.False(t, token.AllowsTrading())
+	token.Capability = ServerTokenCapabilityTrade
+	assert.True(t, token.AllowsTrading())
+}
+
+func TestServerTokenStore_SaveAndLoadFile(t *testing.T) {
+	logger := etradelibtest.CreateNullLogger()
+	filename := filepath.Join(t.TempDir(), ".etrade", "server-tokens.json")
+
+	// A missing file is an empty store
+	store, err := LoadServerTokenStoreFromFile(filename, logger)
+	require.Nil(t, err)
+	assert.Empty(t, store.Tokens)
+
+	secret, _, err := store.CreateToken("Test", []string{"customer1"}, ServerTokenCapabilityTrade, time.Now())
+	require.Nil(t, err)
+
+	// Call the Method Under Test
+	err = SaveServerTokenStoreToFile(filename, store, logger)
+	require.Nil(t, err)
+	info, err := os.Stat(filename)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	fileBytes, err := os.ReadFile(filename)
+	require.Nil(t, err)
+	assert.NotContains(t, string(fileBytes), secret)
+
+	// Call the Method Under Test
+	loadedStore, err := LoadServerTokenStoreFromFile(filename, logger)
+	require.Nil(t, err)
+	require.NotNil(t, loadedStore.Authenticate(secret))
+	assert.Equal(t, "Test", loadedStore.Authenticate(secret).Description)
+}
+
+func TestServerTokenSource_ReloadsChangedFile(t *testing.T) {
+	logger := etradelibtest.CreateNullLogger()
+	filename := filepath.Join(t.TempDir(), "server-tokens.json")
+	source := newServerTokenSource(filename, logger)
+
+	store, err := source.GetStore()
+	require.Nil(t, err)
+	assert.Empty(t, store.Tokens)
+
+	newStore := &ServerTokenStore{}
+	secret, _, err := newStore.CreateToken("", []string{"customer1"}, ServerTokenCapabilityRead, time.Now())
+	require.Nil(t, err)
+	require.Nil(t, SaveServerTokenStoreToFile(filename, newStore, logger))
+
+	// Call the Method Under Test
+	store, err = source.GetStore()
+	require.Nil(t, err)
+	assert.NotNil(t, store.Authenticate(secret))
+
+	require.Nil(t, os.WriteFile(filename, []byte("not json"), 0600))
+
+	// Call the Method Under Test
+	_, err = source.GetStore()
+	assert.Error(t, err)
+}
@@ -0,0 +1,452 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshotIdLayout is the layout of a snapshot's ID, which is the UTC time
+// that it was taken. The fraction of a second is left out when it's zero.
+const snapshotIdLayout = "20060102T150405.999999999Z"
+
+// snapshotTransactionsFileName is the name of the file, in an account's
+// snapshot folder, that holds all the account's stored transactions, one
+// JSON object per line.
+const snapshotTransactionsFileName = "transactions.ndjson"
+
+// snapshotLockFileName is the name of the file, in the store's folder, that
+// processes lock while they write to the store.
+const snapshotLockFileName = ".lock"
+
+// Snapshot is an account's balances and portfolio at the time that it was
+// taken.
+type Snapshot struct {
+	Id        string          `json:"id"`
+	AccountId string          `json:"accountId"`
+	TakenAt   time.Time       `json:"takenAt"`
+	Balances  jsonmap.JsonMap `json:"balances"`
+	Portfolio jsonmap.JsonMap `json:"portfolio"`
+}
+
+// SnapshotStore is a durable record of accounts' snapshots and transactions,
+// kept in a folder with a subfolder for each account. Each snapshot is its
+// own file, and each account's new transactions are appended to a single
+// file, without duplicates, so that the record can outlast ETrade's two years
+// of history. Writes are serialized by a mutex within the process and by a
+// lock file across processes (e.g. the server's scheduled snapshots and a CLI
+// run from cron). Snapshot files are written to a temporary file and renamed,
+// and transactions are appended a line at a time, so reads don't need the
+// lock.
+type SnapshotStore struct {
+	path   string
+	logger *slog.Logger
+	mutex  sync.Mutex
+}
+
+func NewSnapshotStore(path string, logger *slog.Logger) *SnapshotStore {
+	return &SnapshotStore{
+		path:   path,
+		logger: logger,
+	}
+}
+
+// AddSnapshot gives the snapshot an ID from the time that it was taken and
+// stores it. If the account already has a snapshot with that ID (e.g. one
+// taken by another process at the same time), the ID's time is moved ahead a
+// nanosecond at a time until it's unique.
+func (s *SnapshotStore) AddSnapshot(snapshot *Snapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	accountPath, err := s.getAccountPath(snapshot.AccountId)
+	if err != nil {
+		return err
+	}
+	idTime := snapshot.TakenAt.UTC()
+	for {
+		snapshot.Id = idTime.Format(snapshotIdLayout)
+		_, err = os.Stat(filepath.Join(accountPath, snapshot.Id+".json"))
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		idTime = idTime.Add(time.Nanosecond)
+	}
+	return s.writeFile(filepath.Join(accountPath, snapshot.Id+".json"), snapshot)
+}
+
+// GetSnapshot returns the account's snapshot with the ID.
+func (s *SnapshotStore) GetSnapshot(accountId string, id string) (*Snapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	accountPath, err := s.getAccountPath(accountId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = time.Parse(snapshotIdLayout, id); err != nil {
+		return nil, fmt.Errorf("invalid snapshot id %s", id)
+	}
+	snapshot, err := s.readSnapshot(filepath.Join(accountPath, id+".json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("account %s has no snapshot %s", accountId, id)
+	}
+	return snapshot, err
+}
+
+// GetSnapshots returns the account's snapshots, oldest first, that were
+// taken from the from time to the to time. A nil time leaves that end of the
+// range open.
+func (s *SnapshotStore) GetSnapshots(accountId string, from *time.Time, to *time.Time) ([]*Snapshot, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	accountPath, err := s.getAccountPath(accountId)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(accountPath)
+	if os.IsNotExist(err) {
+		return []*Snapshot{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// File names don't sort by time, since the fraction of a second is left
+	// out of IDs when it's zero, so the IDs' times are sorted instead.
+	type snapshotFile struct {
+		name   string
+		idTime time.Time
+	}
+	files := make([]snapshotFile, 0, len(entries))
+	for _, entry := range entries {
+		id, found := strings.CutSuffix(entry.Name(), ".json")
+		if !found || entry.IsDir() {
+			continue
+		}
+		idTime, err := time.Parse(snapshotIdLayout, id)
+		if err != nil {
+			continue
+		}
+		if (from != nil && idTime.Before(*from)) || (to != nil && idTime.After(*to)) {
+			continue
+		}
+		files = append(files, snapshotFile{name: entry.Name(), idTime: idTime})
+	}
+	sort.Slice(
+		files, func(i, j int) bool {
+			return files[i].idTime.Before(files[j].idTime)
+		},
+	)
+	snapshots := make([]*Snapshot, 0, len(files))
+	for _, file := range files {
+		snapshot, err := s.readSnapshot(filepath.Join(accountPath, file.name))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// AddTransactions adds the transactions that the account doesn't already
+// have to the store. It returns the number of transactions that were added.
+func (s *SnapshotStore) AddTransactions(accountId string, transactions []jsonmap.JsonMap) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	accountPath, err := s.getAccountPath(accountId)
+	if err != nil {
+		return 0, err
+	}
+	storedTransactions, err := s.readTransactions(accountPath)
+	if err != nil {
+		return 0, err
+	}
+	storedIds := make(map[string]bool, len(storedTransactions))
+	for _, transaction := range storedTransactions {
+		storedIds[getSnapshotTransactionId(transaction)] = true
+	}
+	var lines bytes.Buffer
+	added := 0
+	for _, transaction := range transactions {
+		transactionId := getSnapshotTransactionId(transaction)
+		if transactionId == "" {
+			return 0, errors.New("transaction has no transaction id")
+		}
+		if storedIds[transactionId] {
+			continue
+		}
+		storedIds[transactionId] = true
+		if err = transaction.ToIoWriter(&lines, false, false); err != nil {
+			return 0, err
+		}
+		added += 1
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	if err = s.appendFile(filepath.Join(accountPath, snapshotTransactionsFileName), lines.Bytes()); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// GetTransactions returns the account's stored transactions, oldest first,
+// from the from time to the to time. A nil time leaves that end of the range
+// open.
+func (s *SnapshotStore) GetTransactions(accountId string, from *time.Time, to *time.Time) (
+	[]jsonmap.JsonMap, error,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	accountPath, err := s.getAccountPath(accountId)
+	if err != nil {
+		return nil, err
+	}
+	storedTransactions, err := s.readTransactions(accountPath)
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]jsonmap.JsonMap, 0, len(storedTransactions))
+	for _, transaction := range storedTransactions {
+		transactionDate := getSnapshotTransactionDate(transaction)
+		if (from != nil && transactionDate.Before(*from)) || (to != nil && transactionDate.After(*to)) {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+// GetLatestTransactionDate returns the date of the account's most recent
+// stored transaction. It returns false if the account has no stored
+// transactions.
+func (s *SnapshotStore) GetLatestTransactionDate(accountId string) (time.Time, bool, error) {
+	transactions, err := s.GetTransactions(accountId, nil, nil)
+	if err != nil || len(transactions) == 0 {
+		return time.Time{}, false, err
+	}
+	return getSnapshotTransactionDate(transactions[len(transactions)-1]), true, nil
+}
+
+// getAccountPath returns the path to the account's folder. It fails if the
+// account ID can't safely be used as a folder name.
+func (s *SnapshotStore) getAccountPath(accountId string) (string, error) {
+	if accountId == "" || accountId == "." || accountId == ".." || strings.ContainsAny(accountId, `/\`) {
+		return "", fmt.Errorf("invalid account id %s", accountId)
+	}
+	return filepath.Join(s.path, accountId), nil
+}
+
+func (s *SnapshotStore) readSnapshot(filename string) (*Snapshot, error) {
+	snapshotMap, err := s.readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := Snapshot{}
+	if snapshot.Id, err = snapshotMap.GetString("id"); err != nil {
+		return nil, err
+	}
+	if snapshot.AccountId, err = snapshotMap.GetString("accountId"); err != nil {
+		return nil, err
+	}
+	takenAt, err := snapshotMap.GetString("takenAt")
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.TakenAt, err = time.Parse(time.RFC3339Nano, takenAt); err != nil {
+		return nil, err
+	}
+	if snapshot.Balances, err = snapshotMap.GetMapWithDefault("balances", jsonmap.JsonMap{}); err != nil {
+		return nil, err
+	}
+	if snapshot.Portfolio, err = snapshotMap.GetMapWithDefault("portfolio", jsonmap.JsonMap{}); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// readTransactions returns the account's stored transactions, oldest first.
+// A last line without a newline is the remains of an interrupted append, so
+// it's skipped.
+func (s *SnapshotStore) readTransactions(accountPath string) ([]jsonmap.JsonMap, error) {
+	data, err := os.ReadFile(filepath.Join(accountPath, snapshotTransactionsFileName))
+	if os.IsNotExist(err) {
+		return []jsonmap.JsonMap{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	if len(lines[len(lines)-1]) > 0 {
+		s.logger.Warn("skipping incomplete transaction at the end of " + snapshotTransactionsFileName)
+	}
+	transactions := make([]jsonmap.JsonMap, 0, len(lines))
+	for _, line := range lines[:len(lines)-1] {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		transaction, err := jsonmap.NewJsonMapFromJsonBytes(line)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	// Transactions are appended in the order that they're listed, which isn't
+	// always by date.
+	sort.SliceStable(
+		transactions, func(i, j int) bool {
+			return getSnapshotTransactionDate(transactions[i]).Before(getSnapshotTransactionDate(transactions[j]))
+		},
+	)
+	return transactions, nil
+}
+
+// lock locks the store against writes by other processes and returns a
+// function that unlocks it. The lock belongs to the open lock file, so the
+// operating system releases it if the process exits without unlocking.
+func (s *SnapshotStore) lock() (func(), error) {
+	if err := os.MkdirAll(s.path, 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filepath.Join(s.path, snapshotLockFileName), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err = lockSnapshotFile(file); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("locking snapshot store failed (%w)", err)
+	}
+	return func() {
+		if err := unlockSnapshotFile(file); err != nil {
+			s.logger.Error(fmt.Errorf("unlocking snapshot store failed (%w)", err).Error())
+		}
+		if err := file.Close(); err != nil {
+			s.logger.Error(fmt.Errorf("closing snapshot lock file failed (%w)", err).Error())
+		}
+	}, nil
+}
+
+// readFile reads a JSON file into a map, keeping its numbers as they were
+// written.
+func (s *SnapshotStore) readFile(filename string) (jsonmap.JsonMap, error) {
+	file, err := os.Open(filename)
+	if file != nil {
+		defer func(file *os.File) {
+			err := file.Close()
+			if err != nil {
+				s.logger.Error(fmt.Errorf("closing snapshot file failed (%w)", err).Error())
+			}
+		}(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return jsonmap.NewJsonMapFromIoReader(file)
+}
+
+// writeFile writes the value to a JSON file. It writes a temporary file and
+// then renames it, so that an interrupted write doesn't lose what was stored.
+func (s *SnapshotStore) writeFile(filename string, value interface{}) error {
+	dirPath := filepath.Dir(filename)
+	if err := os.MkdirAll(dirPath, 0700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dirPath, ".tmp-")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(value)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, filename)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+	}
+	return err
+}
+
+// appendFile appends the data to a file. If the file doesn't end with a
+// newline because an earlier append was interrupted, it drops the incomplete
+// line first, so that the appended lines can still be read.
+func (s *SnapshotStore) appendFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	existingData, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if completeSize := bytes.LastIndexByte(existingData, '\n') + 1; completeSize < len(existingData) {
+		err = file.Truncate(int64(completeSize))
+	}
+	if err == nil {
+		_, err = file.Write(data)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func getSnapshotTransactionId(transaction jsonmap.JsonMap) string {
+	if transactionId, ok := transaction["transactionId"]; ok && transactionId != nil {
+		return fmt.Sprint(transactionId)
+	}
+	return ""
+}
+
+// getSnapshotTransactionDate returns the transaction's date, which ETrade
+// gives in milliseconds since the epoch.
+func getSnapshotTransactionDate(transaction jsonmap.JsonMap) time.Time {
+	transactionDate, err := transaction.GetInt("transactionDate")
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(transactionDate)
+}
+
+func jsonMapsAsJsonSlice(maps []jsonmap.JsonMap) jsonmap.JsonSlice {
+	slice := make(jsonmap.JsonSlice, 0, len(maps))
+	for _, m := range maps {
+		slice = append(slice, m)
+	}
+	return slice
+}
@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package cmd
+
+import "os"
+
+// lockSnapshotFile doesn't lock anything on platforms without file locks, so
+// only the store's mutex serializes writes.
+func lockSnapshotFile(_ *os.File) error {
+	return nil
+}
+
+func unlockSnapshotFile(_ *os.File) error {
+	return nil
+}
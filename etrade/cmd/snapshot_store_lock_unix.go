@@ -0,0 +1,25 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockSnapshotFile waits for an exclusive lock on the file.
+func lockSnapshotFile(file *os.File) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		// The wait can be interrupted by a signal, in which case it's
+		// retried.
+		if !errors.Is(err, syscall.EINTR) {
+			return err
+		}
+	}
+}
+
+func unlockSnapshotFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lockfileExclusiveLock is LockFileEx's LOCKFILE_EXCLUSIVE_LOCK flag.
+const lockfileExclusiveLock = 0x2
+
+// lockSnapshotFile waits for an exclusive lock on the file's first byte.
+func lockSnapshotFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	result, _, err := procLockFileEx.Call(
+		file.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)),
+	)
+	if result == 0 {
+		return err
+	}
+	return nil
+}
+
+func unlockSnapshotFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+	result, _, err := procUnlockFileEx.Call(file.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if result == 0 {
+		return err
+	}
+	return nil
+}
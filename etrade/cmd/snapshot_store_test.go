@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSnapshotTransaction(t *testing.T, id string, date time.Time) jsonmap.JsonMap {
+	transaction, err := jsonmap.NewJsonMapFromJsonString(
+		`{"transactionId": "` + id + `", "transactionDate": ` + strconv.FormatInt(date.UnixMilli(), 10) + `}`,
+	)
+	require.Nil(t, err)
+	return transaction
+}
+
+func TestSnapshotStore_Snapshots(t *testing.T) {
+	store := NewSnapshotStore(t.TempDir(), etradelibtest.CreateNullLogger())
+	testTimes := []time.Time{
+		time.Date(2025, 1, 31, 21, 0, 0, 0, time.UTC),
+		time.Date(2025, 2, 28, 21, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 31, 21, 0, 0, 0, time.UTC),
+	}
+	for i, takenAt := range testTimes {
+		snapshot := Snapshot{
+			AccountId: "test id",
+			TakenAt:   takenAt,
+			Balances:  jsonmap.JsonMap{"accountId": "test id"},
+			Portfolio: jsonmap.JsonMap{"totals": jsonmap.JsonMap{"cashBalance": float64(i)}},
+		}
+
+		// Call the Method Under Test
+		err := store.AddSnapshot(&snapshot)
+		require.Nil(t, err)
+		assert.Equal(t, takenAt.Format(snapshotIdLayout), snapshot.Id)
+	}
+
+	// A snapshot taken at the same time as another gets the next ID.
+	// Call the Method Under Test
+	sameTimeSnapshot := Snapshot{AccountId: "test id", TakenAt: testTimes[1]}
+	err := store.AddSnapshot(&sameTimeSnapshot)
+	require.Nil(t, err)
+	assert.Equal(t, "20250228T210000.000000001Z", sameTimeSnapshot.Id)
+
+	// Call the Method Under Test
+	snapshots, err := store.GetSnapshots("test id", &testTimes[1], nil)
+	require.Nil(t, err)
+	require.Len(t, snapshots, 3)
+	assert.Equal(t, "20250228T210000Z", snapshots[0].Id)
+	assert.Equal(t, "20250228T210000.000000001Z", snapshots[1].Id)
+	assert.Equal(t, "20250331T210000Z", snapshots[2].Id)
+	assert.True(t, testTimes[1].Equal(snapshots[0].TakenAt))
+	cashBalance, err := snapshots[2].Portfolio.GetFloatAtPath(".totals.cashBalance")
+	require.Nil(t, err)
+	assert.Equal(t, 2.0, cashBalance)
+
+	// Call the Method Under Test
+	snapshots, err = store.GetSnapshots("test id", nil, &testTimes[1])
+	require.Nil(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "20250131T210000Z", snapshots[0].Id)
+
+	// Call the Method Under Test
+	snapshots, err = store.GetSnapshots("other id", nil, nil)
+	require.Nil(t, err)
+	assert.Empty(t, snapshots)
+
+	// Call the Method Under Test
+	snapshot, err := store.GetSnapshot("test id", "20250228T210000Z")
+	require.Nil(t, err)
+	assert.Equal(t, "test id", snapshot.AccountId)
+	assert.Equal(t, jsonmap.JsonMap{"accountId": "test id"}, snapshot.Balances)
+
+	// Call the Method Under Test
+	_, err = store.GetSnapshot("test id", "20250301T210000Z")
+	assert.EqualError(t, err, "account test id has no snapshot 20250301T210000Z")
+
+	// Call the Method Under Test
+	_, err = store.GetSnapshot("test id", "../rules")
+	assert.EqualError(t, err, "invalid snapshot id ../rules")
+
+	// Call the Method Under Test
+	_, err = store.GetSnapshots("../test id", nil, nil)
+	assert.EqualError(t, err, "invalid account id ../test id")
+}
+
+func TestSnapshotStore_Transactions(t *testing.T) {
+	store := NewSnapshotStore(t.TempDir(), etradelibtest.CreateNullLogger())
+	testTimes := []time.Time{
+		time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 13, 17, 0, 0, 0, time.UTC),
+		time.Date(2025, 1, 14, 17, 0, 0, 0, time.UTC),
+	}
+
+	// Call the Method Under Test
+	_, found, err := store.GetLatestTransactionDate("test id")
+	require.Nil(t, err)
+	assert.False(t, found)
+
+	// Call the Method Under Test
+	added, err := store.AddTransactions(
+		"test id", []jsonmap.JsonMap{
+			newTestSnapshotTransaction(t, "102", testTimes[1]),
+			newTestSnapshotTransaction(t, "101", testTimes[0]),
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 2, added)
+
+	// Transaction 102 is listed again, since it's on the latest stored date.
+	// Call the Method Under Test
+	added, err = store.AddTransactions(
+		"test id", []jsonmap.JsonMap{
+			newTestSnapshotTransaction(t, "102", testTimes[1]),
+			newTestSnapshotTransaction(t, "103", testTimes[2]),
+		},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 1, added)
+
+	// Call the Method Under Test
+	transactions, err := store.GetTransactions("test id", nil, nil)
+	require.Nil(t, err)
+	transactionIds := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionIds = append(transactionIds, getSnapshotTransactionId(transaction))
+	}
+	assert.Equal(t, []string{"101", "102", "103"}, transactionIds)
+
+	// Call the Method Under Test
+	transactions, err = store.GetTransactions("test id", &testTimes[1], &testTimes[1])
+	require.Nil(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "102", getSnapshotTransactionId(transactions[0]))
+
+	// Call the Method Under Test
+	latestDate, found, err := store.GetLatestTransactionDate("test id")
+	require.Nil(t, err)
+	assert.True(t, found)
+	assert.True(t, testTimes[2].Equal(latestDate))
+
+	// Call the Method Under Test
+	_, err = store.AddTransactions("test id", []jsonmap.JsonMap{{"amount": 100}})
+	assert.EqualError(t, err, "transaction has no transaction id")
+}
+
+func TestSnapshotStore_AddTransactions_SkipsInterruptedAppend(t *testing.T) {
+	path := t.TempDir()
+	store := NewSnapshotStore(path, etradelibtest.CreateNullLogger())
+	testTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+	_, err := store.AddTransactions("test id", []jsonmap.JsonMap{newTestSnapshotTransaction(t, "101", testTime)})
+	require.Nil(t, err)
+	// Leave an incomplete line, like an append that was interrupted.
+	file, err := os.OpenFile(filepath.Join(path, "test id", snapshotTransactionsFileName), os.O_APPEND|os.O_WRONLY, 0)
+	require.Nil(t, err)
+	_, err = file.WriteString(`{"transactionId": "10`)
+	require.Nil(t, err)
+	require.Nil(t, file.Close())
+
+	// Call the Method Under Test
+	added, err := store.AddTransactions(
+		"test id", []jsonmap.JsonMap{newTestSnapshotTransaction(t, "102", testTime)},
+	)
+	require.Nil(t, err)
+	assert.Equal(t, 1, added)
+
+	transactions, err := store.GetTransactions("test id", nil, nil)
+	require.Nil(t, err)
+	transactionIds := make([]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		transactionIds = append(transactionIds, getSnapshotTransactionId(transaction))
+	}
+	assert.Equal(t, []string{"101", "102"}, transactionIds)
+}
+
+func TestSnapshotStore_AddTransactions_SeparateStoresDontLoseTransactions(t *testing.T) {
+	// Stores that share a folder don't share a mutex, like the stores of
+	// different processes, so only the lock file keeps their writes apart.
+	path := t.TempDir()
+	stores := []*SnapshotStore{
+		NewSnapshotStore(path, etradelibtest.CreateNullLogger()),
+		NewSnapshotStore(path, etradelibtest.CreateNullLogger()),
+	}
+	testTime := time.Date(2025, 1, 10, 17, 0, 0, 0, time.UTC)
+	const transactionsPerStore = 20
+
+	testTransactions := make([][]jsonmap.JsonMap, len(stores))
+	for i := range stores {
+		for j := 0; j < transactionsPerStore; j++ {
+			testTransactions[i] = append(
+				testTransactions[i],
+				newTestSnapshotTransaction(t, strconv.Itoa(i*transactionsPerStore+j), testTime),
+			)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(stores)*transactionsPerStore)
+	for i, store := range stores {
+		wg.Add(1)
+		go func(store *SnapshotStore, transactions []jsonmap.JsonMap) {
+			defer wg.Done()
+			for _, transaction := range transactions {
+				// Call the Method Under Test
+				_, err := store.AddTransactions("test id", []jsonmap.JsonMap{transaction})
+				errs <- err
+			}
+		}(store, testTransactions[i])
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.Nil(t, err)
+	}
+	transactions, err := stores[0].GetTransactions("test id", nil, nil)
+	require.Nil(t, err)
+	assert.Len(t, transactions, len(stores)*transactionsPerStore)
+}
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"io"
+	"strconv"
+	"time"
+)
+
+// taxFormat is a tax form or file that realized gains can be exported to.
+type taxFormat int
+
+const (
+	// taxFormatForm8949 is a CSV of Form 8949 rows, grouped by box, with
+	// each box's totals.
+	taxFormatForm8949 taxFormat = iota
+
+	// taxFormatTxf is a Tax Exchange Format (TXF) file that tax software can
+	// import.
+	taxFormatTxf
+
+	// taxFormatScheduleD is a CSV of the Schedule D lines that the Form 8949
+	// boxes' totals are carried to.
+	taxFormatScheduleD
+)
+
+// form8949Boxes are the Form 8949 boxes in the order that they're reported.
+// Part I has the short-term boxes, and Part II has the long-term boxes.
+var form8949Boxes = []struct {
+	box            string
+	part           string
+	txfRefNumber   int
+	scheduleDLine  string
+	scheduleDTitle string
+}{
+	{"A", "I", 321, "1b", "Short-term transactions reported on Form 8949 with Box A checked"},
+	{"B", "I", 711, "2", "Short-term transactions reported on Form 8949 with Box B checked"},
+	{"C", "I", 712, "3", "Short-term transactions reported on Form 8949 with Box C checked"},
+	{"D", "II", 323, "8b", "Long-term transactions reported on Form 8949 with Box D checked"},
+	{"E", "II", 713, "9", "Long-term transactions reported on Form 8949 with Box E checked"},
+	{"F", "II", 714, "10", "Long-term transactions reported on Form 8949 with Box F checked"},
+}
+
+// form8949DateLayout is the date layout of Form 8949 and TXF files.
+const form8949DateLayout = "01/02/2006"
+
+// taxGain is a row of realized gains, as rendered by the "accounts gains"
+// command.
+type taxGain struct {
+	SaleTransactionId      string   `json:"saleTransactionId"`
+	Symbol                 string   `json:"symbol"`
+	Quantity               float64  `json:"quantity"`
+	DateAcquired           string   `json:"dateAcquired"`
+	DateSold               string   `json:"dateSold"`
+	Proceeds               float64  `json:"proceeds"`
+	CostBasis              *float64 `json:"costBasis"`
+	WashSaleLossDisallowed float64  `json:"washSaleLossDisallowed"`
+	Gain                   float64  `json:"gain"`
+	Form8949Box            string   `json:"form8949Box"`
+}
+
+// taxRenderer renders realized gains (from GetRealizedGains) as a tax form or
+// file. It can't render anything else.
+type taxRenderer struct {
+	outputFile io.Writer
+	format     taxFormat
+	now        func() time.Time
+}
+
+func (t *taxRenderer) Render(jsonMap jsonmap.JsonMap, _ []RenderDescriptor) error {
+	if _, err := jsonMap.GetSlice("gains"); err != nil {
+		return errors.New("tax formats can only render realized gains")
+	}
+	var gains struct {
+		Gains []taxGain `json:"gains"`
+	}
+	if err := jsonMap.Decode(&gains); err != nil {
+		return err
+	}
+	// Gains without a cost basis can't be put in a box, so they're listed
+	// on Form 8949 for their basis to be filled in but can't be summed.
+	if t.format != taxFormatForm8949 {
+		for _, gain := range gains.Gains {
+			if gain.CostBasis == nil {
+				return fmt.Errorf(
+					"sale %s of %s has shares without a cost basis, which only the form8949 format can render",
+					gain.SaleTransactionId, gain.Symbol,
+				)
+			}
+		}
+	}
+	switch t.format {
+	case taxFormatTxf:
+		return t.renderTxf(gains.Gains)
+	case taxFormatScheduleD:
+		return t.renderScheduleD(gains.Gains)
+	default:
+		return t.renderForm8949(gains.Gains)
+	}
+}
+
+func (t *taxRenderer) Close() error {
+	if closer, ok := t.outputFile.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// renderForm8949 writes the gains in Form 8949's columns, with a row of
+// totals after each box's gains. Gains without a cost basis are written
+// last, without a box.
+func (t *taxRenderer) renderForm8949(gains []taxGain) error {
+	writer := csv.NewWriter(t.outputFile)
+	defer writer.Flush()
+
+	err := writer.Write(
+		[]string{
+			"Part", "Box", "Description", "Date Acquired", "Date Sold", "Proceeds", "Cost Basis", "Code",
+			"Adjustment", "Gain or Loss",
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, box := range form8949Boxes {
+		var proceeds, costBasis, adjustment, gainOrLoss float64
+		var hasGains bool
+		for _, gain := range gains {
+			if gain.Form8949Box != box.box || gain.CostBasis == nil {
+				continue
+			}
+			hasGains = true
+			code := ""
+			if gain.WashSaleLossDisallowed > 0 {
+				code = "W"
+			}
+			err = writer.Write(
+				[]string{
+					box.part, box.box, getTaxDescription(gain), formatTaxDate(gain.DateAcquired),
+					formatTaxDate(gain.DateSold), formatTaxAmount(gain.Proceeds), formatTaxAmount(*gain.CostBasis),
+					code, formatOptionalTaxAmount(gain.WashSaleLossDisallowed), formatTaxAmount(gain.Gain),
+				},
+			)
+			if err != nil {
+				return err
+			}
+			proceeds += gain.Proceeds
+			costBasis += *gain.CostBasis
+			adjustment += gain.WashSaleLossDisallowed
+			gainOrLoss += gain.Gain
+		}
+		if !hasGains {
+			continue
+		}
+		err = writer.Write(
+			[]string{
+				box.part, box.box, "Totals", "", "", formatTaxAmount(proceeds), formatTaxAmount(costBasis), "",
+				formatOptionalTaxAmount(adjustment), formatTaxAmount(gainOrLoss),
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	for _, gain := range gains {
+		if gain.CostBasis != nil {
+			continue
+		}
+		err = writer.Write(
+			[]string{
+				"", "", getTaxDescription(gain), "", formatTaxDate(gain.DateSold), formatTaxAmount(gain.Proceeds),
+				"", "", "", "",
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderTxf writes the gains as TXF version 042 detailed records, one per
+// gain. The lines end with CRLF, which all the tax software that imports TXF
+// accepts.
+func (t *taxRenderer) renderTxf(gains []taxGain) error {
+	var lines []string
+	lines = append(lines, "V042", "Aetrade-cli", "D"+t.now().Format(form8949DateLayout), "^")
+	for _, box := range form8949Boxes {
+		for _, gain := range gains {
+			if gain.Form8949Box != box.box {
+				continue
+			}
+			lines = append(
+				lines,
+				"TD",
+				"N"+strconv.Itoa(box.txfRefNumber),
+				"C1",
+				"L1",
+				"P"+getTaxDescription(gain),
+				"D"+formatTaxDate(gain.DateAcquired),
+				"D"+formatTaxDate(gain.DateSold),
+				"$"+formatTaxAmount(*gain.CostBasis),
+				"$"+formatTaxAmount(gain.Proceeds),
+			)
+			if gain.WashSaleLossDisallowed > 0 {
+				lines = append(lines, "$"+formatTaxAmount(gain.WashSaleLossDisallowed))
+			}
+			lines = append(lines, "^")
+		}
+	}
+	for _, line := range lines {
+		if _, err := io.WriteString(t.outputFile, line+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderScheduleD writes the Schedule D lines for each Form 8949 box, and the
+// net short-term, long-term, and total gains. The net gains only include the
+// gains in the account, not the other lines of Schedule D (such as
+// carryovers).
+func (t *taxRenderer) renderScheduleD(gains []taxGain) error {
+	writer := csv.NewWriter(t.outputFile)
+	defer writer.Flush()
+
+	err := writer.Write([]string{"Line", "Description", "Proceeds", "Cost Basis", "Adjustments", "Gain or Loss"})
+	if err != nil {
+		return err
+	}
+	netGains := map[string]float64{}
+	for _, box := range form8949Boxes {
+		var proceeds, costBasis, adjustments, gainOrLoss float64
+		for _, gain := range gains {
+			if gain.Form8949Box == box.box {
+				proceeds += gain.Proceeds
+				costBasis += *gain.CostBasis
+				adjustments += gain.WashSaleLossDisallowed
+				gainOrLoss += gain.Gain
+			}
+		}
+		netGains[box.part] += gainOrLoss
+		err = writer.Write(
+			[]string{
+				box.scheduleDLine, box.scheduleDTitle, formatTaxAmount(proceeds), formatTaxAmount(costBasis),
+				formatTaxAmount(adjustments), formatTaxAmount(gainOrLoss),
+			},
+		)
+		if err != nil {
+			return err
+		}
+		// The net gains follow the last box of each part.
+		switch box.box {
+		case "C":
+			err = writer.Write(getScheduleDNetLine("7", "Net short-term capital gain or (loss)", netGains["I"]))
+		case "F":
+			err = writer.Write(getScheduleDNetLine("15", "Net long-term capital gain or (loss)", netGains["II"]))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Write(getScheduleDNetLine("16", "Total capital gain or (loss)", netGains["I"]+netGains["II"]))
+}
+
+func getScheduleDNetLine(line string, description string, gainOrLoss float64) []string {
+	return []string{line, description, "", "", "", formatTaxAmount(gainOrLoss)}
+}
+
+// getTaxDescription describes the property sold, as the quantity and
+// security (e.g. "10 AAPL").
+func getTaxDescription(gain taxGain) string {
+	return strconv.FormatFloat(gain.Quantity, 'f', -1, 64) + " " + gain.Symbol
+}
+
+// formatTaxDate converts a date from the gains' layout to the forms' layout.
+func formatTaxDate(date string) string {
+	parsed, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format(form8949DateLayout)
+}
+
+func formatTaxAmount(amount float64) string {
+	return strconv.FormatFloat(roundToCents(amount), 'f', 2, 64)
+}
+
+// formatOptionalTaxAmount formats an amount that's left blank on the forms
+// when it's zero.
+func formatOptionalTaxAmount(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return formatTaxAmount(amount)
+}
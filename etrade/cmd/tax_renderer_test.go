@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bytes"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// testTaxGains are realized gains with a long-term gain in box D, a wash
+// sale in box A, and shares without a cost basis.
+var testTaxGains = jsonmap.JsonMap{
+	"gains": jsonmap.JsonSlice{
+		jsonmap.JsonMap{
+			"saleTransactionId": "S1", "symbol": "AAPL", "lotId": "B1", "quantity": 10.0,
+			"dateAcquired": "2022-03-01", "dateSold": "2024-03-01", "proceeds": 1500.0, "costBasis": 1000.0,
+			"gain": 500.0, "term": "long", "washSale": false, "washSaleLossDisallowed": 0.0, "form8949Box": "D",
+		},
+		jsonmap.JsonMap{
+			"saleTransactionId": "S2", "symbol": "AAPL", "lotId": "B2", "quantity": 10.0,
+			"dateAcquired": "2024-01-02", "dateSold": "2024-04-01", "proceeds": 800.0, "costBasis": 1000.0,
+			"gain": -150.0, "term": "short", "washSale": true, "washSaleLossDisallowed": 50.0, "form8949Box": "A",
+		},
+		jsonmap.JsonMap{
+			"saleTransactionId": "S3", "symbol": "MSFT", "lotId": "", "quantity": 2.0, "dateSold": "2024-05-01",
+			"proceeds": 600.0, "washSale": false, "washSaleLossDisallowed": 0.0,
+		},
+	},
+	"totals": jsonmap.JsonMap{},
+}
+
+func TestTaxRenderer_Render(t *testing.T) {
+	tests := []struct {
+		name        string
+		testFormat  taxFormat
+		testGains   jsonmap.JsonMap
+		expectErr   bool
+		expectValue string
+	}{
+		{
+			name:       "Renders Form 8949",
+			testFormat: taxFormatForm8949,
+			testGains:  testTaxGains,
+			expectErr:  false,
+			expectValue: "Part,Box,Description,Date Acquired,Date Sold,Proceeds,Cost Basis,Code,Adjustment," +
+				"Gain or Loss\n" +
+				"I,A,10 AAPL,01/02/2024,04/01/2024,800.00,1000.00,W,50.00,-150.00\n" +
+				"I,A,Totals,,,800.00,1000.00,,50.00,-150.00\n" +
+				"II,D,10 AAPL,03/01/2022,03/01/2024,1500.00,1000.00,,,500.00\n" +
+				"II,D,Totals,,,1500.00,1000.00,,,500.00\n" +
+				",,2 MSFT,,05/01/2024,600.00,,,,\n",
+		},
+		{
+			name:       "Renders TXF",
+			testFormat: taxFormatTxf,
+			testGains: jsonmap.JsonMap{
+				"gains": jsonmap.JsonSlice{
+					testTaxGains["gains"].(jsonmap.JsonSlice)[0], testTaxGains["gains"].(jsonmap.JsonSlice)[1],
+				},
+			},
+			expectErr: false,
+			expectValue: "V042\r\nAetrade-cli\r\nD01/15/2025\r\n^\r\n" +
+				"TD\r\nN321\r\nC1\r\nL1\r\nP10 AAPL\r\nD01/02/2024\r\nD04/01/2024\r\n$1000.00\r\n$800.00\r\n" +
+				"$50.00\r\n^\r\n" +
+				"TD\r\nN323\r\nC1\r\nL1\r\nP10 AAPL\r\nD03/01/2022\r\nD03/01/2024\r\n$1000.00\r\n$1500.00\r\n^\r\n",
+		},
+		{
+			name:       "Renders Schedule D",
+			testFormat: taxFormatScheduleD,
+			testGains: jsonmap.JsonMap{
+				"gains": jsonmap.JsonSlice{
+					testTaxGains["gains"].(jsonmap.JsonSlice)[0], testTaxGains["gains"].(jsonmap.JsonSlice)[1],
+				},
+			},
+			expectErr: false,
+			expectValue: "Line,Description,Proceeds,Cost Basis,Adjustments,Gain or Loss\n" +
+				"1b,Short-term transactions reported on Form 8949 with Box A checked,800.00,1000.00,50.00,-150.00\n" +
+				"2,Short-term transactions reported on Form 8949 with Box B checked,0.00,0.00,0.00,0.00\n" +
+				"3,Short-term transactions reported on Form 8949 with Box C checked,0.00,0.00,0.00,0.00\n" +
+				"7,Net short-term capital gain or (loss),,,,-150.00\n" +
+				"8b,Long-term transactions reported on Form 8949 with Box D checked,1500.00,1000.00,0.00,500.00\n" +
+				"9,Long-term transactions reported on Form 8949 with Box E checked,0.00,0.00,0.00,0.00\n" +
+				"10,Long-term transactions reported on Form 8949 with Box F checked,0.00,0.00,0.00,0.00\n" +
+				"15,Net long-term capital gain or (loss),,,,500.00\n" +
+				"16,Total capital gain or (loss),,,,350.00\n",
+		},
+		{
+			name:       "TXF Fails With Gains Without Cost Basis",
+			testFormat: taxFormatTxf,
+			testGains:  testTaxGains,
+			expectErr:  true,
+		},
+		{
+			name:       "Fails With Other Responses",
+			testFormat: taxFormatForm8949,
+			testGains:  jsonmap.JsonMap{"accounts": jsonmap.JsonSlice{}},
+			expectErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var output bytes.Buffer
+				renderer := &taxRenderer{
+					outputFile: &output,
+					format:     tt.testFormat,
+					now:        func() time.Time { return time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC) },
+				}
+
+				// Call the Method Under Test
+				err := renderer.Render(tt.testGains, nil)
+
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, output.String())
+				}
+			},
+		)
+	}
+}
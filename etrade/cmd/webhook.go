@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// webhookTimeout is how long a webhook request waits for a response.
+	webhookTimeout = 10 * time.Second
+
+	// webhookTimestampHeader is the time (in Unix seconds) when a signed
+	// webhook request was sent.
+	webhookTimestampHeader = "X-Etrade-Timestamp"
+
+	// webhookSignatureHeader is a signed webhook request's signature:
+	// "sha256=" followed by the hex HMAC-SHA256, keyed by the webhook secret,
+	// of the timestamp, a period, and the request body.
+	webhookSignatureHeader = "X-Etrade-Signature"
+)
+
+func newWebhookHttpClient() *http.Client {
+	return &http.Client{Timeout: webhookTimeout}
+}
+
+// postWebhook posts a JSON body to a webhook URL. If secret isn't empty, the
+// request is signed, so that the receiver can check that it came from this
+// client and isn't being replayed. A response status other than 2xx is an
+// error.
+func postWebhook(httpClient *http.Client, targetUrl string, body []byte, secret string, now time.Time) error {
+	request, err := http.NewRequest(http.MethodPost, targetUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		request.Header.Set(webhookTimestampHeader, timestamp)
+		request.Header.Set(webhookSignatureHeader, getWebhookSignature(secret, timestamp, body))
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", response.Status)
+	}
+	return nil
+}
+
+func getWebhookSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
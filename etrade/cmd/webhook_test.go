@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPostWebhook(t *testing.T) {
+	tests := []struct {
+		name            string
+		testSecret      string
+		testStatus      int
+		expectErr       string
+		expectTimestamp string
+		expectSignature string
+	}{
+		{
+			name:            "Posts Signed Body",
+			testSecret:      "test secret",
+			testStatus:      http.StatusOK,
+			expectTimestamp: "1700000000",
+			expectSignature: "sha256=7b001d52815de83400289ec0bc0eaac906e3a012cc8baf7efb89c83ae8d6b888",
+		},
+		{
+			name:       "Posts Unsigned Body Without Secret",
+			testSecret: "",
+			testStatus: http.StatusNoContent,
+		},
+		{
+			name:            "Fails With Non-2xx Response",
+			testSecret:      "test secret",
+			testStatus:      http.StatusBadGateway,
+			expectErr:       "webhook responded with 502 Bad Gateway",
+			expectTimestamp: "1700000000",
+			expectSignature: "sha256=7b001d52815de83400289ec0bc0eaac906e3a012cc8baf7efb89c83ae8d6b888",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var receivedContentType, receivedTimestamp, receivedSignature, receivedBody string
+				webhookServer := httptest.NewServer(
+					http.HandlerFunc(
+						func(w http.ResponseWriter, r *http.Request) {
+							receivedContentType = r.Header.Get("Content-Type")
+							receivedTimestamp = r.Header.Get(webhookTimestampHeader)
+							receivedSignature = r.Header.Get(webhookSignatureHeader)
+							body, _ := io.ReadAll(r.Body)
+							receivedBody = string(body)
+							w.WriteHeader(tt.testStatus)
+						},
+					),
+				)
+				defer webhookServer.Close()
+
+				// Call the Method Under Test
+				err := postWebhook(
+					newWebhookHttpClient(), webhookServer.URL, []byte(`{"event":"filled"}`), tt.testSecret,
+					time.Unix(1700000000, 0),
+				)
+
+				if tt.expectErr != "" {
+					assert.EqualError(t, err, tt.expectErr)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, "application/json", receivedContentType)
+				assert.Equal(t, tt.expectTimestamp, receivedTimestamp)
+				assert.Equal(t, tt.expectSignature, receivedSignature)
+				assert.Equal(t, `{"event":"filled"}`, receivedBody)
+			},
+		)
+	}
+}
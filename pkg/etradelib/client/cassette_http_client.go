@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A cassette is a directory of recorded HTTP interactions, one JSON file per
+// request/response pair. The files are numbered in the order in which the
+// requests were made so that they can be replayed in the same order.
+
+// CassetteInteraction is a recorded request and its response.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method  string      `json:"method"`
+	Url     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Status     string      `json:"status"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// HttpClientWrapper wraps the HTTP client that sends ETrade requests (for
+// example, to record them). The wrapped client signs API requests with the
+// OAuth credentials; requests for OAuth tokens are already signed.
+type HttpClientWrapper func(httpClient HttpClient) HttpClient
+
+// scrubbedValue replaces credentials in recorded interactions.
+const scrubbedValue = "REDACTED"
+
+// scrubbedHeaders are removed from recorded interactions because they carry
+// credentials or session state.
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+type recordingHttpClient struct {
+	httpClient  HttpClient
+	cassetteDir string
+}
+
+// recordingMutex serializes the numbering of recordings, since several
+// recording clients may share a cassette directory.
+var recordingMutex sync.Mutex
+
+// CreateRecordingHttpClient creates an HTTP client that sends requests with
+// httpClient and records each request and response in the cassette
+// directory, which is created if it doesn't exist. OAuth credentials are
+// scrubbed from the recordings. Recordings are added to any that are already
+// in the directory.
+func CreateRecordingHttpClient(httpClient HttpClient, cassetteDir string) HttpClient {
+	return &recordingHttpClient{
+		httpClient:  httpClient,
+		cassetteDir: cassetteDir,
+	}
+}
+
+func (c *recordingHttpClient) Do(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	response, err := c.httpClient.Do(req)
+	if err != nil {
+		return response, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method:  req.Method,
+			Url:     scrubUrl(req.URL),
+			Headers: scrubHeaders(req.Header),
+			Body:    string(requestBody),
+		},
+		Response: CassetteResponse{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Headers:    scrubHeaders(response.Header),
+			Body:       scrubBody(string(responseBody)),
+		},
+	}
+	if err = c.save(&interaction); err != nil {
+		return nil, fmt.Errorf("recording %s %s failed (%w)", req.Method, req.URL.Path, err)
+	}
+	return response, nil
+}
+
+func (c *recordingHttpClient) save(interaction *CassetteInteraction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return err
+	}
+	requestUrl, err := url.Parse(interaction.Request.Url)
+	if err != nil {
+		return err
+	}
+	name := cassetteNameRegexp.ReplaceAllString(requestUrl.Path, "_")
+
+	recordingMutex.Lock()
+	defer recordingMutex.Unlock()
+	if err = os.MkdirAll(c.cassetteDir, 0700); err != nil {
+		return err
+	}
+	filenames, err := getCassetteFilenames(c.cassetteDir)
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("%04d-%s%s.json", len(filenames)+1, interaction.Request.Method, name)
+	return os.WriteFile(filepath.Join(c.cassetteDir, filename), data, 0600)
+}
+
+type replayingHttpClient struct {
+	mutex        sync.Mutex
+	interactions map[string][]*CassetteInteraction
+}
+
+// CreateReplayingHttpClient creates an HTTP client that answers requests with
+// the responses recorded in the cassette directory instead of sending them.
+// Requests match recordings with the same method, path, query (ignoring OAuth
+// parameters), and body. Identical requests get their recorded responses in
+// order, and the last response is repeated once they run out. Requests
+// without a recording fail.
+func CreateReplayingHttpClient(cassetteDir string) (HttpClient, error) {
+	interactions, err := LoadCassette(cassetteDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(interactions) == 0 {
+		return nil, fmt.Errorf("cassette %s has no recordings", cassetteDir)
+	}
+	c := replayingHttpClient{interactions: map[string][]*CassetteInteraction{}}
+	for i := range interactions {
+		requestUrl, err := url.Parse(interactions[i].Request.Url)
+		if err != nil {
+			return nil, err
+		}
+		key := getInteractionKey(interactions[i].Request.Method, requestUrl, interactions[i].Request.Body)
+		c.interactions[key] = append(c.interactions[key], &interactions[i])
+	}
+	return &c, nil
+}
+
+func (c *replayingHttpClient) Do(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		var err error
+		if requestBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+	}
+	key := getInteractionKey(req.Method, req.URL, string(requestBody))
+
+	c.mutex.Lock()
+	recorded := c.interactions[key]
+	if len(recorded) == 0 {
+		c.mutex.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, scrubUrl(req.URL))
+	}
+	interaction := recorded[0]
+	if len(recorded) > 1 {
+		c.interactions[key] = recorded[1:]
+	}
+	c.mutex.Unlock()
+
+	return &http.Response{
+		StatusCode: interaction.Response.StatusCode,
+		Status:     interaction.Response.Status,
+		Header:     interaction.Response.Headers.Clone(),
+		Body:       io.NopCloser(strings.NewReader(interaction.Response.Body)),
+		Request:    req,
+	}, nil
+}
+
+// LoadCassette loads the interactions recorded in a cassette directory, in
+// the order in which they were recorded.
+func LoadCassette(cassetteDir string) ([]CassetteInteraction, error) {
+	filenames, err := getCassetteFilenames(cassetteDir)
+	if err != nil {
+		return nil, err
+	}
+	interactions := make([]CassetteInteraction, 0, len(filenames))
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var interaction CassetteInteraction
+		if err = json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("cassette file %s is invalid (%w)", filename, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	return interactions, nil
+}
+
+// cassetteNameRegexp matches the characters of a URL path that aren't used in
+// cassette filenames.
+var cassetteNameRegexp = regexp.MustCompile(`[^A-Za-z0-9.]+`)
+
+func getCassetteFilenames(cassetteDir string) ([]string, error) {
+	filenames, err := filepath.Glob(filepath.Join(cassetteDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+func getInteractionKey(method string, requestUrl *url.URL, body string) string {
+	query := requestUrl.Query()
+	for key := range query {
+		if isOAuthParameter(key) {
+			query.Del(key)
+		}
+	}
+	return method + " " + requestUrl.Path + "?" + query.Encode() + "\n" + body
+}
+
+func isOAuthParameter(key string) bool {
+	return strings.HasPrefix(strings.ToLower(key), "oauth_")
+}
+
+// scrubUrl returns the URL with the values of any OAuth query parameters
+// replaced.
+func scrubUrl(requestUrl *url.URL) string {
+	scrubbed := *requestUrl
+	query := scrubbed.Query()
+	for key := range query {
+		if isOAuthParameter(key) {
+			query.Set(key, scrubbedValue)
+		}
+	}
+	scrubbed.RawQuery = query.Encode()
+	return scrubbed.String()
+}
+
+func scrubHeaders(headers http.Header) http.Header {
+	scrubbed := headers.Clone()
+	for _, header := range scrubbedHeaders {
+		scrubbed.Del(header)
+	}
+	if len(scrubbed) == 0 {
+		return nil
+	}
+	return scrubbed
+}
+
+// scrubBody replaces the tokens in form-encoded OAuth responses (e.g. from
+// the token endpoints). Other bodies are returned unchanged.
+func scrubBody(body string) string {
+	values, err := url.ParseQuery(body)
+	if err != nil || len(values) == 0 {
+		return body
+	}
+	scrubbed := false
+	for key := range values {
+		if isOAuthParameter(key) {
+			values.Set(key, scrubbedValue)
+			scrubbed = true
+		}
+	}
+	if !scrubbed {
+		return body
+	}
+	return values.Encode()
+}
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCassetteHttpClient(t *testing.T) {
+	type request struct {
+		method string
+		url    string
+		body   string
+	}
+	tests := []struct {
+		name          string
+		testRecord    []request
+		testReplay    []request
+		expectErr     bool
+		expectBodies  []string
+		expectRequest CassetteRequest
+	}{
+		{
+			name: "Replays Responses In Recorded Order",
+			testRecord: []request{
+				{method: "GET", url: "https://api.etrade.com/v1/accounts/list?oauth_token=secret"},
+				{method: "GET", url: "https://api.etrade.com/v1/accounts/list?oauth_token=secret"},
+				{method: "POST", url: "https://api.etrade.com/v1/accounts/a/orders/preview", body: `{"a":1}`},
+			},
+			testReplay: []request{
+				{method: "POST", url: "https://api.etrade.com/v1/accounts/a/orders/preview", body: `{"a":1}`},
+				{method: "GET", url: "https://api.etrade.com/v1/accounts/list"},
+				{method: "GET", url: "https://api.etrade.com/v1/accounts/list"},
+				{method: "GET", url: "https://api.etrade.com/v1/accounts/list"},
+			},
+			expectErr:    false,
+			expectBodies: []string{"response 3", "response 1", "response 2", "response 2"},
+			expectRequest: CassetteRequest{
+				Method:  "GET",
+				Url:     "https://api.etrade.com/v1/accounts/list?oauth_token=REDACTED",
+				Headers: http.Header{"Accept": {"application/json"}},
+			},
+		},
+		{
+			name: "Fails Without Matching Recording",
+			testRecord: []request{
+				{method: "POST", url: "https://api.etrade.com/v1/accounts/a/orders/preview", body: `{"a":1}`},
+			},
+			testReplay: []request{
+				{method: "POST", url: "https://api.etrade.com/v1/accounts/a/orders/preview", body: `{"a":2}`},
+			},
+			expectErr:    true,
+			expectBodies: nil,
+			expectRequest: CassetteRequest{
+				Method:  "POST",
+				Url:     "https://api.etrade.com/v1/accounts/a/orders/preview",
+				Headers: http.Header{"Accept": {"application/json"}},
+				Body:    `{"a":1}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				cassetteDir := t.TempDir()
+				mockClient := &httpClientMock{}
+				recorder := CreateRecordingHttpClient(mockClient, cassetteDir)
+				for i, r := range tt.testRecord {
+					responseBody := "response " + string(rune('1'+i))
+					if r.body == "" {
+						mockClient.On("Do", r.method, r.url).Return(http.StatusOK, responseBody, nil).Once()
+					} else {
+						mockClient.On("Do", r.method, r.url, r.body).Return(http.StatusOK, responseBody, nil).Once()
+					}
+					// Call the Method Under Test
+					response, err := recorder.Do(newCassetteTestRequest(t, r.method, r.url, r.body))
+					require.Nil(t, err)
+					body, err := io.ReadAll(response.Body)
+					require.Nil(t, err)
+					assert.Equal(t, responseBody, string(body))
+				}
+				mockClient.AssertExpectations(t)
+
+				interactions, err := LoadCassette(cassetteDir)
+				require.Nil(t, err)
+				require.Len(t, interactions, len(tt.testRecord))
+				assert.Equal(t, tt.expectRequest, interactions[0].Request)
+
+				replayer, err := CreateReplayingHttpClient(cassetteDir)
+				require.Nil(t, err)
+				var actualBodies []string
+				for _, r := range tt.testReplay {
+					// Call the Method Under Test
+					response, err := replayer.Do(newCassetteTestRequest(t, r.method, r.url, r.body))
+					if err != nil {
+						require.True(t, tt.expectErr, err.Error())
+						continue
+					}
+					body, err := io.ReadAll(response.Body)
+					require.Nil(t, err)
+					actualBodies = append(actualBodies, string(body))
+				}
+				assert.Equal(t, tt.expectBodies, actualBodies)
+			},
+		)
+	}
+}
+
+func TestScrubBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		testBody    string
+		expectValue string
+	}{
+		{
+			name:        "Scrubs OAuth Tokens",
+			testBody:    "oauth_token=abc&oauth_token_secret=def",
+			expectValue: "oauth_token=REDACTED&oauth_token_secret=REDACTED",
+		},
+		{
+			name:        "Leaves JSON Unchanged",
+			testBody:    `{"oauth_token":"abc"}`,
+			expectValue: `{"oauth_token":"abc"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := scrubBody(tt.testBody)
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func newCassetteTestRequest(t *testing.T, method string, url string, body string) *http.Request {
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, bodyReader)
+	require.Nil(t, err)
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Authorization", `OAuth oauth_signature="secret"`)
+	return req
+}
@@ -88,6 +88,21 @@ const (
 	OptionExpiryTypeMonthEnd
 )
 
+// OptionType specifies whether an option is a call or a put.
+// See the constants below for semantics.
+type OptionType int
+
+const (
+	// OptionTypeNil indicates no option type
+	OptionTypeNil OptionType = iota
+
+	// OptionTypeCall is a call option
+	OptionTypeCall
+
+	// OptionTypePut is a put option
+	OptionTypePut
+)
+
 var optionCategoryToString = map[OptionCategory]string{
 	OptionCategoryStandard: "STANDARD",
 	OptionCategoryAll:      "ALL",
@@ -147,3 +162,16 @@ func (e OptionExpiryType) String() string {
 	}
 	return "UNKNOWN"
 }
+
+var optionTypeToString = map[OptionType]string{
+	OptionTypeCall: "CALL",
+	OptionTypePut:  "PUT",
+}
+
+// String converts an OptionType to its string representation.
+func (e OptionType) String() string {
+	if s, found := optionTypeToString[e]; found {
+		return s
+	}
+	return "UNKNOWN"
+}
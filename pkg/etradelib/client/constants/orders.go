@@ -8,6 +8,10 @@ const OrdersMaxCount = 100
 // in a List Orders request
 const ListOrdersMaxSymbols = 25
 
+// OrderClientIdMaxLength is the maximum length of the client order ID that
+// accompanies a Preview Order or Place Order request.
+const OrderClientIdMaxLength = 20
+
 // OrderStatus specifies the status of orders to retrieve.
 // See the constants below for semantics.
 type OrderStatus int
@@ -89,6 +93,134 @@ const (
 	OrderTransactionTypeMutualFundExchange
 )
 
+// OrderType specifies the type of order to preview or place.
+// See the constants below for semantics.
+type OrderType int
+
+const (
+	// OrderTypeNil indicates no order type
+	OrderTypeNil OrderType = iota
+
+	// OrderTypeEquity is an equity order
+	OrderTypeEquity
+
+	// OrderTypeOption is a single-leg option order
+	OrderTypeOption
+
+	// OrderTypeSpreads is a multi-leg option spread order
+	OrderTypeSpreads
+
+	// OrderTypeBuyWrites is a buy-write (covered call) order
+	OrderTypeBuyWrites
+
+	// OrderTypeButterfly is a butterfly order
+	OrderTypeButterfly
+
+	// OrderTypeIronButterfly is an iron butterfly order
+	OrderTypeIronButterfly
+
+	// OrderTypeCondor is a condor order
+	OrderTypeCondor
+
+	// OrderTypeIronCondor is an iron condor order
+	OrderTypeIronCondor
+
+	// OrderTypeMutualFund is a mutual fund order
+	OrderTypeMutualFund
+
+	// OrderTypeMoneyMarketFund is a money market fund order
+	OrderTypeMoneyMarketFund
+)
+
+// OrderPriceType specifies the price type of order to preview or place.
+// See the constants below for semantics.
+type OrderPriceType int
+
+const (
+	// OrderPriceTypeNil indicates no price type
+	OrderPriceTypeNil OrderPriceType = iota
+
+	// OrderPriceTypeMarket executes at the best available price
+	OrderPriceTypeMarket
+
+	// OrderPriceTypeLimit executes at the limit price or better
+	OrderPriceTypeLimit
+
+	// OrderPriceTypeStop becomes a market order once the stop price is reached
+	OrderPriceTypeStop
+
+	// OrderPriceTypeStopLimit becomes a limit order once the stop price is
+	// reached
+	OrderPriceTypeStopLimit
+
+	// OrderPriceTypeNetDebit executes a multi-leg order for a net debit of
+	// the limit price or less
+	OrderPriceTypeNetDebit
+
+	// OrderPriceTypeNetCredit executes a multi-leg order for a net credit of
+	// the limit price or more
+	OrderPriceTypeNetCredit
+
+	// OrderPriceTypeNetEven executes a multi-leg order for no net debit or
+	// credit
+	OrderPriceTypeNetEven
+)
+
+// OrderTerm specifies how long an order remains in effect.
+// See the constants below for semantics.
+type OrderTerm int
+
+const (
+	// OrderTermNil indicates no order term
+	OrderTermNil OrderTerm = iota
+
+	// OrderTermGoodForDay keeps the order open until the end of the day
+	OrderTermGoodForDay
+
+	// OrderTermGoodUntilCancel keeps the order open until it is canceled
+	OrderTermGoodUntilCancel
+
+	// OrderTermImmediateOrCancel fills as much of the order as possible
+	// immediately and cancels the remainder
+	OrderTermImmediateOrCancel
+
+	// OrderTermFillOrKill fills the entire order immediately or cancels it
+	OrderTermFillOrKill
+)
+
+// OrderAction specifies the action to take for an order instrument.
+// See the constants below for semantics.
+type OrderAction int
+
+const (
+	// OrderActionNil indicates no order action
+	OrderActionNil OrderAction = iota
+
+	// OrderActionBuy buys an equity
+	OrderActionBuy
+
+	// OrderActionSell sells an equity
+	OrderActionSell
+
+	// OrderActionBuyToCover buys an equity to cover a short position
+	OrderActionBuyToCover
+
+	// OrderActionSellShort sells an equity short
+	OrderActionSellShort
+
+	// OrderActionBuyOpen buys an option to open a position
+	OrderActionBuyOpen
+
+	// OrderActionSellOpen sells (writes) an option to open a position
+	OrderActionSellOpen
+
+	// OrderActionBuyClose buys an option to close a short position
+	OrderActionBuyClose
+
+	// OrderActionSellClose sells an option to close a long position
+	OrderActionSellClose
+)
+
 var orderStatusToString = map[OrderStatus]string{
 	OrderStatusOpen:            "OPEN",
 	OrderStatusExecuted:        "EXECUTED",
@@ -138,3 +270,88 @@ func (e OrderTransactionType) String() string {
 	}
 	return "UNKNOWN"
 }
+
+var orderTypeToString = map[OrderType]string{
+	OrderTypeEquity:          "EQ",
+	OrderTypeOption:          "OPTN",
+	OrderTypeSpreads:         "SPREADS",
+	OrderTypeBuyWrites:       "BUY_WRITES",
+	OrderTypeButterfly:       "BUTTERFLY",
+	OrderTypeIronButterfly:   "IRON_BUTTERFLY",
+	OrderTypeCondor:          "CONDOR",
+	OrderTypeIronCondor:      "IRON_CONDOR",
+	OrderTypeMutualFund:      "MF",
+	OrderTypeMoneyMarketFund: "MMF",
+}
+
+// String converts an OrderType to its string representation.
+func (e OrderType) String() string {
+	if s, found := orderTypeToString[e]; found {
+		return s
+	}
+	return "UNKNOWN"
+}
+
+var orderPriceTypeToString = map[OrderPriceType]string{
+	OrderPriceTypeMarket:    "MARKET",
+	OrderPriceTypeLimit:     "LIMIT",
+	OrderPriceTypeStop:      "STOP",
+	OrderPriceTypeStopLimit: "STOP_LIMIT",
+	OrderPriceTypeNetDebit:  "NET_DEBIT",
+	OrderPriceTypeNetCredit: "NET_CREDIT",
+	OrderPriceTypeNetEven:   "EVEN",
+}
+
+// String converts an OrderPriceType to its string representation.
+func (e OrderPriceType) String() string {
+	if s, found := orderPriceTypeToString[e]; found {
+		return s
+	}
+	return "UNKNOWN"
+}
+
+var orderTermToString = map[OrderTerm]string{
+	OrderTermGoodForDay:        "GOOD_FOR_DAY",
+	OrderTermGoodUntilCancel:   "GOOD_UNTIL_CANCEL",
+	OrderTermImmediateOrCancel: "IMMEDIATE_OR_CANCEL",
+	OrderTermFillOrKill:        "FILL_OR_KILL",
+}
+
+// String converts an OrderTerm to its string representation.
+func (e OrderTerm) String() string {
+	if s, found := orderTermToString[e]; found {
+		return s
+	}
+	return "UNKNOWN"
+}
+
+var orderActionToString = map[OrderAction]string{
+	OrderActionBuy:        "BUY",
+	OrderActionSell:       "SELL",
+	OrderActionBuyToCover: "BUY_TO_COVER",
+	OrderActionSellShort:  "SELL_SHORT",
+	OrderActionBuyOpen:    "BUY_OPEN",
+	OrderActionSellOpen:   "SELL_OPEN",
+	OrderActionBuyClose:   "BUY_CLOSE",
+	OrderActionSellClose:  "SELL_CLOSE",
+}
+
+// String converts an OrderAction to its string representation.
+func (e OrderAction) String() string {
+	if s, found := orderActionToString[e]; found {
+		return s
+	}
+	return "UNKNOWN"
+}
+
+// OrderPriceTypeFromString converts a string representation of a price type
+// (as returned by ETrade) to an OrderPriceType. It returns OrderPriceTypeNil
+// if the string is not recognized.
+func OrderPriceTypeFromString(s string) OrderPriceType {
+	for k, v := range orderPriceTypeToString {
+		if v == s {
+			return k
+		}
+	}
+	return OrderPriceTypeNil
+}
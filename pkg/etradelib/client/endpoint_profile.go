@@ -0,0 +1,80 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EndpointEnvironment identifies the servers that a client talks to.
+type EndpointEnvironment string
+
+const (
+	// EndpointEnvironmentSandbox indicates ETrade's sandbox servers.
+	EndpointEnvironmentSandbox EndpointEnvironment = "sandbox"
+
+	// EndpointEnvironmentProduction indicates ETrade's production servers.
+	EndpointEnvironmentProduction EndpointEnvironment = "production"
+
+	// EndpointEnvironmentCustom indicates servers other than ETrade's (e.g. a
+	// mock, a proxy, or a recording gateway).
+	EndpointEnvironmentCustom EndpointEnvironment = "custom"
+)
+
+// EndpointProfile describes the environment that a client talks to. All the
+// client's endpoint URLs are derived from it.
+type EndpointProfile struct {
+	// Environment is the environment. If it's empty, the sandbox is used.
+	Environment EndpointEnvironment `json:"environment,omitempty"`
+
+	// UrlBase replaces the environment's base URL (e.g.
+	// "https://api.etrade.com") for all the API and OAuth endpoints. It's
+	// required for the custom environment.
+	UrlBase string `json:"urlBase,omitempty"`
+
+	// The OAuth URLs replace the environment's corresponding OAuth endpoints
+	// if they're not empty. In the custom environment, the authorization page
+	// defaults to "/e/t/etws/authorize" on the base URL; otherwise, it's
+	// always on ETrade's website.
+	RequestTokenUrl         string `json:"requestTokenUrl,omitempty"`
+	AuthorizeApplicationUrl string `json:"authorizeApplicationUrl,omitempty"`
+	AccessTokenUrl          string `json:"accessTokenUrl,omitempty"`
+	RenewAccessTokenUrl     string `json:"renewAccessTokenUrl,omitempty"`
+	RevokeAccessTokenUrl    string `json:"revokeAccessTokenUrl,omitempty"`
+}
+
+// NewEndpointProfile creates a profile for ETrade's production or sandbox
+// servers.
+func NewEndpointProfile(production bool) *EndpointProfile {
+	if production {
+		return &EndpointProfile{Environment: EndpointEnvironmentProduction}
+	}
+	return &EndpointProfile{Environment: EndpointEnvironmentSandbox}
+}
+
+// IsProduction returns true if the profile is for ETrade's production
+// servers.
+func (p *EndpointProfile) IsProduction() bool {
+	return p != nil && p.Environment == EndpointEnvironmentProduction
+}
+
+// Validate returns an error if there's no profile, if the environment is
+// unknown, or if the custom environment has no base URL.
+func (p *EndpointProfile) Validate() error {
+	if p == nil {
+		return errors.New("no endpoint profile provided")
+	}
+	switch p.Environment {
+	case "", EndpointEnvironmentSandbox, EndpointEnvironmentProduction:
+		return nil
+	case EndpointEnvironmentCustom:
+		if p.UrlBase == "" {
+			return errors.New("the custom environment requires a base URL")
+		}
+		return nil
+	default:
+		return fmt.Errorf(
+			"unknown environment %s (must be %s, %s, or %s)", p.Environment, EndpointEnvironmentSandbox,
+			EndpointEnvironmentProduction, EndpointEnvironmentCustom,
+		)
+	}
+}
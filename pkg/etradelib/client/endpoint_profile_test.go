@@ -0,0 +1,80 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewEndpointProfile(t *testing.T) {
+	assert.Equal(t, &EndpointProfile{Environment: EndpointEnvironmentProduction}, NewEndpointProfile(true))
+	assert.Equal(t, &EndpointProfile{Environment: EndpointEnvironmentSandbox}, NewEndpointProfile(false))
+}
+
+func TestEndpointProfile_IsProduction(t *testing.T) {
+	assert.True(t, NewEndpointProfile(true).IsProduction())
+	assert.False(t, NewEndpointProfile(false).IsProduction())
+	assert.False(t, (&EndpointProfile{}).IsProduction())
+	assert.False(t, (*EndpointProfile)(nil).IsProduction())
+	assert.False(
+		t, (&EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: "https://api.etrade.com"}).IsProduction(),
+	)
+}
+
+func TestEndpointProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		testProfile EndpointProfile
+		expectErr   bool
+	}{
+		{
+			name:        "Empty Environment Is Valid",
+			testProfile: EndpointProfile{},
+			expectErr:   false,
+		},
+		{
+			name:        "Sandbox Is Valid",
+			testProfile: EndpointProfile{Environment: EndpointEnvironmentSandbox},
+			expectErr:   false,
+		},
+		{
+			name:        "Production Is Valid",
+			testProfile: EndpointProfile{Environment: EndpointEnvironmentProduction},
+			expectErr:   false,
+		},
+		{
+			name:        "Custom With Base URL Is Valid",
+			testProfile: EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: "http://127.0.0.1:8080"},
+			expectErr:   false,
+		},
+		{
+			name:        "Custom Without Base URL Is Invalid",
+			testProfile: EndpointProfile{Environment: EndpointEnvironmentCustom},
+			expectErr:   true,
+		},
+		{
+			name:        "Unknown Environment Is Invalid",
+			testProfile: EndpointProfile{Environment: "staging"},
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				err := tt.testProfile.Validate()
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+			},
+		)
+	}
+}
+
+func TestEndpointProfile_ValidateFailsWithoutProfile(t *testing.T) {
+	// Call the Method Under Test
+	err := (*EndpointProfile)(nil).Validate()
+	assert.EqualError(t, err, "no endpoint profile provided")
+}
@@ -161,6 +161,7 @@ const (
 
 	getRequestTokenUrlTemplate         = "{B}/oauth/request_token"
 	authorizeApplicationUrlTemplate    = "https://us.etrade.com/e/t/etws/authorize"
+	customAuthorizeUrlTemplate         = "{B}/e/t/etws/authorize"
 	getAccessTokenUrlTemplate          = "{B}/oauth/access_token"
 	renewAccessTokenUrlTemplate        = "{B}/oauth/renew_access_token"
 	revokeAccessTokenUrlTemplate       = "{B}/oauth/revoke_access_token"
@@ -185,19 +186,48 @@ const (
 	placeChangedOrderUrlTemplate       = "{B}/v1/accounts/%s/orders/%s/change/place"
 )
 
+// GetEndpointUrls returns the URLs of the endpoints on ETrade's production or
+// sandbox servers.
 func GetEndpointUrls(production bool) EndpointUrls {
-	var urlBase string
-	if production {
+	// ETrade's environments are always valid.
+	urls, _ := GetEndpointUrlsForProfile(NewEndpointProfile(production))
+	return urls
+}
+
+// GetEndpointUrlsForProfile returns the URLs of the endpoints in the
+// environment described by profile, or an error if the profile is nil or
+// isn't valid.
+func GetEndpointUrlsForProfile(profile *EndpointProfile) (EndpointUrls, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	urlBase := sandboxUrlBase
+	if profile.IsProduction() {
 		urlBase = productionUrlBase
-	} else {
-		urlBase = sandboxUrlBase
+	}
+	authorizeUrlTemplate := authorizeApplicationUrlTemplate
+	if profile.Environment == EndpointEnvironmentCustom {
+		authorizeUrlTemplate = customAuthorizeUrlTemplate
+	}
+	if profile.UrlBase != "" {
+		urlBase = strings.TrimSuffix(profile.UrlBase, "/")
 	}
 	return &endpointUrls{
-		getRequestTokenUrl:         renderUrlTemplateWithBase(getRequestTokenUrlTemplate, urlBase),
-		authorizeApplicationUrl:    renderUrlTemplateWithBase(authorizeApplicationUrlTemplate, urlBase),
-		getAccessTokenUrl:          renderUrlTemplateWithBase(getAccessTokenUrlTemplate, urlBase),
-		renewAccessTokenUrl:        renderUrlTemplateWithBase(renewAccessTokenUrlTemplate, urlBase),
-		revokeAccessTokenUrl:       renderUrlTemplateWithBase(revokeAccessTokenUrlTemplate, urlBase),
+		getRequestTokenUrl: getUrlOrDefault(
+			profile.RequestTokenUrl, renderUrlTemplateWithBase(getRequestTokenUrlTemplate, urlBase),
+		),
+		authorizeApplicationUrl: getUrlOrDefault(
+			profile.AuthorizeApplicationUrl, renderUrlTemplateWithBase(authorizeUrlTemplate, urlBase),
+		),
+		getAccessTokenUrl: getUrlOrDefault(
+			profile.AccessTokenUrl, renderUrlTemplateWithBase(getAccessTokenUrlTemplate, urlBase),
+		),
+		renewAccessTokenUrl: getUrlOrDefault(
+			profile.RenewAccessTokenUrl, renderUrlTemplateWithBase(renewAccessTokenUrlTemplate, urlBase),
+		),
+		revokeAccessTokenUrl: getUrlOrDefault(
+			profile.RevokeAccessTokenUrl, renderUrlTemplateWithBase(revokeAccessTokenUrlTemplate, urlBase),
+		),
 		listAccountsUrl:            renderUrlTemplateWithBase(listAccountsUrlTemplate, urlBase),
 		getAccountBalancesUrl:      renderUrlTemplateWithBase(getAccountBalancesUrlTemplate, urlBase),
 		listTransactionsUrl:        renderUrlTemplateWithBase(listTransactionsUrlTemplate, urlBase),
@@ -217,9 +247,16 @@ func GetEndpointUrls(production bool) EndpointUrls {
 		cancelOrderUrl:             renderUrlTemplateWithBase(cancelOrderUrlTemplate, urlBase),
 		changePreviewedOrderUrl:    renderUrlTemplateWithBase(changePreviewedOrderUrlTemplate, urlBase),
 		placeChangedOrderUrl:       renderUrlTemplateWithBase(placeChangedOrderUrlTemplate, urlBase),
-	}
+	}, nil
 }
 
 func renderUrlTemplateWithBase(urlTemplate string, base string) string {
 	return strings.Replace(urlTemplate, "{B}", base, 1)
 }
+
+func getUrlOrDefault(url string, defaultUrl string) string {
+	if url != "" {
+		return url
+	}
+	return defaultUrl
+}
@@ -6,7 +6,7 @@ import (
 )
 
 func TestSandboxUrls(t *testing.T) {
-	var urls = GetEndpointUrls(false)
+	urls := GetEndpointUrls(false)
 
 	assert.Equal(
 		t,
@@ -126,7 +126,7 @@ func TestSandboxUrls(t *testing.T) {
 }
 
 func TestProductionUrls(t *testing.T) {
-	var urls = GetEndpointUrls(true)
+	urls := GetEndpointUrls(true)
 
 	assert.Equal(
 		t,
@@ -239,3 +239,110 @@ func TestProductionUrls(t *testing.T) {
 		urls.PlaceChangedOrderUrl("1234", "5678"),
 	)
 }
+
+func TestCustomUrls(t *testing.T) {
+	urls, err := GetEndpointUrlsForProfile(
+		&EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: "http://127.0.0.1:8080/"},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(
+		t,
+		"http://127.0.0.1:8080/oauth/request_token",
+		urls.GetRequestTokenUrl(),
+	)
+	assert.Equal(
+		t,
+		"http://127.0.0.1:8080/e/t/etws/authorize",
+		urls.AuthorizeApplicationUrl(),
+	)
+	assert.Equal(
+		t,
+		"http://127.0.0.1:8080/oauth/access_token",
+		urls.GetAccessTokenUrl(),
+	)
+	assert.Equal(
+		t,
+		"http://127.0.0.1:8080/v1/accounts/list",
+		urls.ListAccountsUrl(),
+	)
+	assert.Equal(
+		t,
+		"http://127.0.0.1:8080/v1/accounts/1234/orders/5678/change/place",
+		urls.PlaceChangedOrderUrl("1234", "5678"),
+	)
+}
+
+func TestEndpointUrlOverrides(t *testing.T) {
+	urls, err := GetEndpointUrlsForProfile(
+		&EndpointProfile{
+			Environment:             EndpointEnvironmentProduction,
+			UrlBase:                 "https://api2.etrade.com",
+			RequestTokenUrl:         "https://proxy/request",
+			AuthorizeApplicationUrl: "https://proxy/authorize",
+			AccessTokenUrl:          "https://proxy/access",
+			RenewAccessTokenUrl:     "https://proxy/renew",
+			RevokeAccessTokenUrl:    "https://proxy/revoke",
+		},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(
+		t,
+		"https://proxy/request",
+		urls.GetRequestTokenUrl(),
+	)
+	assert.Equal(
+		t,
+		"https://proxy/authorize",
+		urls.AuthorizeApplicationUrl(),
+	)
+	assert.Equal(
+		t,
+		"https://proxy/access",
+		urls.GetAccessTokenUrl(),
+	)
+	assert.Equal(
+		t,
+		"https://proxy/renew",
+		urls.RenewAccessTokenUrl(),
+	)
+	assert.Equal(
+		t,
+		"https://proxy/revoke",
+		urls.RevokeAccessTokenUrl(),
+	)
+	assert.Equal(
+		t,
+		"https://api2.etrade.com/v1/accounts/list",
+		urls.ListAccountsUrl(),
+	)
+}
+
+func TestAlternateHostKeepsETradeAuthorizeUrl(t *testing.T) {
+	urls, err := GetEndpointUrlsForProfile(
+		&EndpointProfile{Environment: EndpointEnvironmentSandbox, UrlBase: "https://apisb2.etrade.com"},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(
+		t,
+		"https://us.etrade.com/e/t/etws/authorize",
+		urls.AuthorizeApplicationUrl(),
+	)
+	assert.Equal(
+		t,
+		"https://apisb2.etrade.com/oauth/request_token",
+		urls.GetRequestTokenUrl(),
+	)
+}
+
+func TestInvalidProfileFails(t *testing.T) {
+	_, err := GetEndpointUrlsForProfile(&EndpointProfile{Environment: EndpointEnvironmentCustom})
+	assert.Error(t, err)
+}
+
+func TestNilProfileFails(t *testing.T) {
+	_, err := GetEndpointUrlsForProfile(nil)
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/dghubble/oauth1"
@@ -8,13 +10,28 @@ import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
 	"golang.org/x/exp/slog"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 type ETradeClient interface {
+	// WithContext returns a client whose requests are made with the context,
+	// so that they're canceled when the context is canceled or its deadline
+	// passes. The returned client shares its authentication state with the
+	// original.
+	WithContext(ctx context.Context) ETradeClient
+
+	// WithRequestTimeout returns a client whose API requests are each
+	// canceled if they take longer than the timeout (unless it's zero),
+	// including any waits for the rate limiter and retries. The returned
+	// client shares its authentication state with the original.
+	WithRequestTimeout(timeout time.Duration) ETradeClient
+
 	Authenticate() ([]byte, error)
 
 	Verify(verifyKey string) ([]byte, error)
@@ -69,29 +86,78 @@ type ETradeClient interface {
 		toDate *time.Time, symbols []string, securityType constants.OrderSecurityType,
 		transactionType constants.OrderTransactionType, marketSession constants.MarketSession,
 	) ([]byte, error)
+
+	PreviewOrder(accountIdKey string, request jsonmap.JsonMap) ([]byte, error)
+
+	PlaceOrder(accountIdKey string, previewId int64, request jsonmap.JsonMap) ([]byte, error)
+
+	CancelOrder(accountIdKey string, orderId int64) ([]byte, error)
+
+	PreviewChangedOrder(accountIdKey string, orderId int64, request jsonmap.JsonMap) ([]byte, error)
+
+	PlaceChangedOrder(accountIdKey string, orderId int64, previewId int64, request jsonmap.JsonMap) ([]byte, error)
 }
 
 type eTradeClient struct {
 	urls           EndpointUrls
-	httpClient     HttpClient
 	logger         *slog.Logger
 	config         OAuthConfig
 	consumerKey    string
 	consumerSecret string
-	requestToken   string
-	requestSecret  string
-	accessToken    string
-	accessSecret   string
+	session        *eTradeSession
+	ctx            context.Context
+	requestTimeout time.Duration
+
+	httpClientWrapper HttpClientWrapper
+
+	limiters    map[requestCategory]*tokenBucket
+	retryPolicy retryPolicy
+	now         func() time.Time
+	sleep       func(ctx context.Context, d time.Duration) error
+	random      func() float64
+}
+
+// eTradeSession is the authentication state of a client. The clients returned
+// by WithContext share it, so that authentication begun with one of them can
+// be completed with another. The state is guarded by mutex, and authMutex
+// serializes authentication so that concurrent Authenticate and Verify calls
+// can't interleave their token exchanges.
+type eTradeSession struct {
+	mutex         sync.RWMutex
+	authMutex     sync.Mutex
+	httpClient    HttpClient
+	requestToken  string
+	requestSecret string
+	accessToken   string
+	accessSecret  string
 }
 
 func CreateETradeClient(
 	logger *slog.Logger, production bool, consumerKey string, consumerSecret string, accessToken string,
 	accessSecret string,
+) (ETradeClient, error) {
+	return CreateETradeClientWithHttpClientWrapper(
+		logger, NewEndpointProfile(production), consumerKey, consumerSecret, accessToken, accessSecret, nil,
+	)
+}
+
+// CreateETradeClientWithHttpClientWrapper creates a client whose requests are
+// sent through the HTTP client returned by httpClientWrapper (if it's not
+// nil) to the endpoints in the environment described by profile.
+func CreateETradeClientWithHttpClientWrapper(
+	logger *slog.Logger, profile *EndpointProfile, consumerKey string, consumerSecret string, accessToken string,
+	accessSecret string, httpClientWrapper HttpClientWrapper,
 ) (ETradeClient, error) {
 	if consumerKey == "" || consumerSecret == "" {
 		return nil, errors.New("invalid consumer credentials provided")
 	}
-	urls := GetEndpointUrls(production)
+	if profile == nil {
+		return nil, errors.New("no endpoint profile provided")
+	}
+	urls, err := GetEndpointUrlsForProfile(profile)
+	if err != nil {
+		return nil, err
+	}
 
 	authorizeEndpoint := oauth1.Endpoint{
 		RequestTokenURL: urls.GetRequestTokenUrl(),
@@ -106,19 +172,94 @@ func CreateETradeClient(
 		Endpoint:       authorizeEndpoint,
 	}
 
-	token := oauth1.NewToken(accessToken, oauth1.PercentEncode(accessSecret))
-	httpClient := config.Client(oauth1.NoContext, token)
-
-	return &eTradeClient{
+	c := &eTradeClient{
 		urls:           urls,
-		httpClient:     httpClient,
 		logger:         logger,
 		config:         &config,
 		consumerKey:    consumerKey,
 		consumerSecret: consumerSecret,
-		accessToken:    accessToken,
-		accessSecret:   accessSecret,
-	}, nil
+		session: &eTradeSession{
+			accessToken:  accessToken,
+			accessSecret: accessSecret,
+		},
+		ctx:               context.Background(),
+		httpClientWrapper: httpClientWrapper,
+		limiters:          newRateLimiters(defaultRateLimits),
+		retryPolicy:       defaultRetryPolicy,
+		now:               time.Now,
+		sleep:             sleepWithContext,
+		random:            rand.Float64,
+	}
+	c.session.httpClient = c.newHttpClient(oauth1.NewToken(accessToken, oauth1.PercentEncode(accessSecret)))
+	config.HTTPClient = c.newTokenHttpClient(c.ctx)
+	return c, nil
+}
+
+// newHttpClient creates an HTTP client that signs requests with the token.
+// The context passed to the OAuth config only supplies an optional base HTTP
+// client; each request carries its own context.
+func (c *eTradeClient) newHttpClient(token *oauth1.Token) HttpClient {
+	httpClient := HttpClient(c.config.Client(oauth1.NoContext, token))
+	if c.httpClientWrapper != nil {
+		httpClient = c.httpClientWrapper(httpClient)
+	}
+	return httpClient
+}
+
+// newTokenHttpClient creates the HTTP client that the OAuth library gets
+// request and access tokens with. The library signs those requests itself,
+// so they're sent through the wrapper around an unsigned HTTP client, which
+// lets them be recorded and replayed like the other requests. The library
+// doesn't accept contexts for them, so the HTTP client adds the context.
+func (c *eTradeClient) newTokenHttpClient(ctx context.Context) *http.Client {
+	httpClient := HttpClient(http.DefaultClient)
+	if c.httpClientWrapper != nil {
+		httpClient = c.httpClientWrapper(httpClient)
+	}
+	return &http.Client{
+		Transport: &contextTransport{ctx: ctx, httpClient: httpClient},
+	}
+}
+
+func (c *eTradeClient) WithContext(ctx context.Context) ETradeClient {
+	contextClient := *c
+	contextClient.ctx = ctx
+	if config, ok := c.config.(*oauth1.Config); ok {
+		contextConfig := *config
+		contextConfig.HTTPClient = c.newTokenHttpClient(ctx)
+		contextClient.config = &contextConfig
+	}
+	return &contextClient
+}
+
+func (c *eTradeClient) WithRequestTimeout(timeout time.Duration) ETradeClient {
+	timeoutClient := *c
+	timeoutClient.requestTimeout = timeout
+	return &timeoutClient
+}
+
+// contextTransport makes its requests with a context, through an HTTP
+// client.
+type contextTransport struct {
+	ctx        context.Context
+	httpClient HttpClient
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.httpClient.Do(req.WithContext(t.ctx))
+}
+
+// sleepWithContext sleeps for the duration or until the context is done, in
+// which case it returns the context's error.
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 var ErrETradeAuthFailed = errors.New("authentication failed")
@@ -130,7 +271,9 @@ func IsAuthFailed(err error) bool {
 const queryDateLayout = "01022006"
 
 func (c *eTradeClient) Authenticate() ([]byte, error) {
-	_, err := c.doRequest("GET", c.urls.RenewAccessTokenUrl(), nil)
+	c.session.authMutex.Lock()
+	defer c.session.authMutex.Unlock()
+	_, err := c.doRequest(requestCategoryAccounts, "GET", c.urls.RenewAccessTokenUrl(), nil)
 	// If access token renewal succeeded, then we're done. Return success.
 	if err == nil {
 		return NewStatusResponse("success"), nil
@@ -141,38 +284,58 @@ func (c *eTradeClient) Authenticate() ([]byte, error) {
 	}
 	// If access token renewal failed, then begin a new auth session by
 	// requesting a new token.
-	c.requestToken, c.requestSecret, err = c.config.RequestToken()
+	requestToken, requestSecret, err := c.config.RequestToken()
 	if err != nil {
 		return nil, err
 	}
+	c.session.mutex.Lock()
+	c.session.requestToken, c.session.requestSecret = requestToken, requestSecret
+	c.session.mutex.Unlock()
 	// Format and return the authorization string
 	authorizeUrl, err := url.Parse(c.urls.AuthorizeApplicationUrl())
 	values := authorizeUrl.Query()
 	values.Add("key", c.consumerKey)
-	values.Add("token", c.requestToken)
+	values.Add("token", requestToken)
 	authorizeUrl.RawQuery = values.Encode()
 	return NewStatusResponse("authorize", "authorizationUrl", authorizeUrl.String()), nil
 }
 
 func (c *eTradeClient) Verify(verifyKey string) ([]byte, error) {
-	var err error
-	c.accessToken, c.accessSecret, err = c.config.AccessToken(
-		c.requestToken, oauth1.PercentEncode(c.requestSecret), verifyKey,
+	c.session.authMutex.Lock()
+	defer c.session.authMutex.Unlock()
+	c.session.mutex.RLock()
+	requestToken, requestSecret := c.session.requestToken, c.session.requestSecret
+	c.session.mutex.RUnlock()
+	accessToken, accessSecret, err := c.config.AccessToken(
+		requestToken, oauth1.PercentEncode(requestSecret), verifyKey,
 	)
 	if err != nil {
 		return nil, err
 	}
-	token := oauth1.NewToken(c.accessToken, oauth1.PercentEncode(c.accessSecret))
-	c.httpClient = c.config.Client(oauth1.NoContext, token)
+	httpClient := c.newHttpClient(oauth1.NewToken(accessToken, oauth1.PercentEncode(accessSecret)))
+	c.session.mutex.Lock()
+	c.session.accessToken, c.session.accessSecret = accessToken, accessSecret
+	c.session.httpClient = httpClient
+	c.session.mutex.Unlock()
 	return NewStatusResponse("success"), nil
 }
 
 func (c *eTradeClient) GetKeys() (consumerKey string, consumerSecret string, accessToken string, accessSecret string) {
-	return c.consumerKey, c.consumerSecret, c.accessToken, c.accessSecret
+	c.session.mutex.RLock()
+	defer c.session.mutex.RUnlock()
+	return c.consumerKey, c.consumerSecret, c.session.accessToken, c.session.accessSecret
+}
+
+// getHttpClient returns the HTTP client that signs requests with the
+// session's current access token.
+func (c *eTradeClient) getHttpClient() HttpClient {
+	c.session.mutex.RLock()
+	defer c.session.mutex.RUnlock()
+	return c.session.httpClient
 }
 
 func (c *eTradeClient) ListAccounts() ([]byte, error) {
-	response, err := c.doRequest("GET", c.urls.ListAccountsUrl(), nil)
+	response, err := c.doRequest(requestCategoryAccounts, "GET", c.urls.ListAccountsUrl(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +350,9 @@ func (c *eTradeClient) GetAccountBalances(accountIdKey string, realTimeNAV bool)
 	queryValues.Add("instType", "BROKERAGE")
 	queryValues.Add("realTimeNAV", fmt.Sprintf("%t", realTimeNAV))
 
-	response, err := c.doRequest("GET", c.urls.GetAccountBalancesUrl(accountIdKey), queryValues)
+	response, err := c.doRequest(
+		requestCategoryAccounts, "GET", c.urls.GetAccountBalancesUrl(accountIdKey), queryValues,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +383,7 @@ func (c *eTradeClient) ListTransactions(
 		queryValues.Add("count", fmt.Sprintf("%d", count))
 	}
 
-	response, err := c.doRequest("GET", c.urls.ListTransactionsUrl(accountIdKey), queryValues)
+	response, err := c.doRequest(requestCategoryAccounts, "GET", c.urls.ListTransactionsUrl(accountIdKey), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -232,7 +397,9 @@ func (c *eTradeClient) ListTransactionDetails(accountIdKey string, transactionId
 	if transactionId == "" {
 		return nil, errors.New("transactionId not provided")
 	}
-	response, err := c.doRequest("GET", c.urls.ListTransactionDetailsUrl(accountIdKey, transactionId), nil)
+	response, err := c.doRequest(
+		requestCategoryAccounts, "GET", c.urls.ListTransactionDetailsUrl(accountIdKey, transactionId), nil,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +440,7 @@ func (c *eTradeClient) ViewPortfolio(
 		queryValues.Add("view", view.String())
 	}
 
-	response, err := c.doRequest("GET", c.urls.ViewPortfolioUrl(accountIdKey), queryValues)
+	response, err := c.doRequest(requestCategoryAccounts, "GET", c.urls.ViewPortfolioUrl(accountIdKey), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -285,7 +452,9 @@ func (c *eTradeClient) ListPositionLotsDetails(accountIdKey string, positionId i
 		return nil, errors.New("accountIdKey not provided")
 	}
 
-	response, err := c.doRequest("GET", c.urls.ListPositionLotsDetailsUrl(accountIdKey, positionId), nil)
+	response, err := c.doRequest(
+		requestCategoryAccounts, "GET", c.urls.ListPositionLotsDetailsUrl(accountIdKey, positionId), nil,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -320,7 +489,7 @@ func (c *eTradeClient) ListAlerts(
 		queryValues.Add("search", search)
 	}
 
-	response, err := c.doRequest("GET", c.urls.ListAlertsUrl(), queryValues)
+	response, err := c.doRequest(requestCategoryAccounts, "GET", c.urls.ListAlertsUrl(), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -331,7 +500,7 @@ func (c *eTradeClient) ListAlertDetails(alertId string, htmlTags bool) ([]byte,
 	queryValues := url.Values{}
 	queryValues.Add("htmlTags", fmt.Sprintf("%t", htmlTags))
 
-	response, err := c.doRequest("GET", c.urls.ListAlertDetailsUrl(alertId), queryValues)
+	response, err := c.doRequest(requestCategoryAccounts, "GET", c.urls.ListAlertDetailsUrl(alertId), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -339,7 +508,9 @@ func (c *eTradeClient) ListAlertDetails(alertId string, htmlTags bool) ([]byte,
 }
 
 func (c *eTradeClient) DeleteAlerts(alertIds []string) ([]byte, error) {
-	response, err := c.doRequest("DELETE", c.urls.DeleteAlertUrl(strings.Join(alertIds, ",")), nil)
+	response, err := c.doRequest(
+		requestCategoryAccounts, "DELETE", c.urls.DeleteAlertUrl(strings.Join(alertIds, ",")), nil,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -369,7 +540,7 @@ func (c *eTradeClient) GetQuotes(
 		queryValues.Add("detailFlag", detailFlag.String())
 	}
 
-	response, err := c.doRequest("GET", c.urls.GetQuotesUrl(symbolsList), queryValues)
+	response, err := c.doRequest(requestCategoryMarket, "GET", c.urls.GetQuotesUrl(symbolsList), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -380,7 +551,7 @@ func (c *eTradeClient) LookupProduct(search string) ([]byte, error) {
 	if search == "" {
 		return nil, errors.New("no search string provided")
 	}
-	response, err := c.doRequest("GET", c.urls.LookUpProductUrl(search), nil)
+	response, err := c.doRequest(requestCategoryMarket, "GET", c.urls.LookUpProductUrl(search), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -424,7 +595,7 @@ func (c *eTradeClient) GetOptionChains(
 		queryValues.Add("priceType", priceType.String())
 	}
 
-	response, err := c.doRequest("GET", c.urls.GetOptionChainsUrl(), queryValues)
+	response, err := c.doRequest(requestCategoryMarket, "GET", c.urls.GetOptionChainsUrl(), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -441,7 +612,7 @@ func (c *eTradeClient) GetOptionExpireDates(symbol string, expiryType constants.
 		queryValues.Add("expiryType", expiryType.String())
 	}
 
-	response, err := c.doRequest("GET", c.urls.GetOptionExpireDatesUrl(), queryValues)
+	response, err := c.doRequest(requestCategoryMarket, "GET", c.urls.GetOptionExpireDatesUrl(), queryValues)
 	if err != nil {
 		return nil, err
 	}
@@ -490,37 +661,268 @@ func (c *eTradeClient) ListOrders(
 		queryValues.Add("marketSession", marketSession.String())
 	}
 
-	response, err := c.doRequest("GET", c.urls.ListOrdersUrl(accountIdKey), queryValues)
+	response, err := c.doRequest(requestCategoryOrders, "GET", c.urls.ListOrdersUrl(accountIdKey), queryValues)
 	if err != nil {
 		return nil, err
 	}
 	return response, nil
 }
 
-func (c *eTradeClient) doRequest(method string, baseUrl string, queryValues url.Values) ([]byte, error) {
-	req, err := http.NewRequest(method, baseUrl, nil)
+func (c *eTradeClient) PreviewOrder(accountIdKey string, request jsonmap.JsonMap) ([]byte, error) {
+	if accountIdKey == "" {
+		return nil, errors.New("accountIdKey not provided")
+	}
+	if request == nil {
+		return nil, errors.New("order request not provided")
+	}
+	requestMap := jsonmap.JsonMap{
+		"PreviewOrderRequest": request,
+	}
+	requestBody, err := requestMap.ToJsonBytes(false, false)
 	if err != nil {
 		return nil, err
 	}
 
-	// Request that the server respond with JSON
-	req.Header.Add("Accept", `application/json`)
+	response, err := c.doRequestWithBody(
+		requestCategoryOrders, "POST", c.urls.PreviewOrderUrl(accountIdKey), nil, requestBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *eTradeClient) PlaceOrder(accountIdKey string, previewId int64, request jsonmap.JsonMap) ([]byte, error) {
+	if accountIdKey == "" {
+		return nil, errors.New("accountIdKey not provided")
+	}
+	if previewId <= 0 {
+		return nil, errors.New("previewId not provided (orders must be previewed before they can be placed)")
+	}
+	if request == nil {
+		return nil, errors.New("order request not provided")
+	}
+	requestBody, err := newPlaceOrderRequestBody(previewId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.doRequestWithBody(
+		requestCategoryOrders, "POST", c.urls.PlaceOrderUrl(accountIdKey), nil, requestBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
 
+func (c *eTradeClient) CancelOrder(accountIdKey string, orderId int64) ([]byte, error) {
+	if accountIdKey == "" {
+		return nil, errors.New("accountIdKey not provided")
+	}
+	if orderId <= 0 {
+		return nil, errors.New("orderId not provided")
+	}
+	requestMap := jsonmap.JsonMap{
+		"CancelOrderRequest": jsonmap.JsonMap{
+			"orderId": orderId,
+		},
+	}
+	requestBody, err := requestMap.ToJsonBytes(false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.doRequestWithBody(
+		requestCategoryOrders, "PUT", c.urls.CancelOrderUrl(accountIdKey), nil, requestBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *eTradeClient) PreviewChangedOrder(accountIdKey string, orderId int64, request jsonmap.JsonMap) (
+	[]byte, error,
+) {
+	if accountIdKey == "" {
+		return nil, errors.New("accountIdKey not provided")
+	}
+	if orderId <= 0 {
+		return nil, errors.New("orderId not provided")
+	}
+	if request == nil {
+		return nil, errors.New("order request not provided")
+	}
+	requestMap := jsonmap.JsonMap{
+		"PreviewOrderRequest": request,
+	}
+	requestBody, err := requestMap.ToJsonBytes(false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.doRequestWithBody(
+		requestCategoryOrders, "PUT", c.urls.ChangePreviewedOrderUrl(accountIdKey, strconv.FormatInt(orderId, 10)), nil,
+		requestBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *eTradeClient) PlaceChangedOrder(
+	accountIdKey string, orderId int64, previewId int64, request jsonmap.JsonMap,
+) ([]byte, error) {
+	if accountIdKey == "" {
+		return nil, errors.New("accountIdKey not provided")
+	}
+	if orderId <= 0 {
+		return nil, errors.New("orderId not provided")
+	}
+	if previewId <= 0 {
+		return nil, errors.New("previewId not provided (order changes must be previewed before they can be placed)")
+	}
+	if request == nil {
+		return nil, errors.New("order request not provided")
+	}
+	requestBody, err := newPlaceOrderRequestBody(previewId, request)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.doRequestWithBody(
+		requestCategoryOrders, "PUT", c.urls.PlaceChangedOrderUrl(accountIdKey, strconv.FormatInt(orderId, 10)), nil,
+		requestBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// newPlaceOrderRequestBody wraps an order request and the ID of its preview in
+// the body of a place order request.
+func newPlaceOrderRequestBody(previewId int64, request jsonmap.JsonMap) ([]byte, error) {
+	// Copy the request so that adding the preview ID doesn't modify the
+	// caller's map.
+	placeRequest := make(jsonmap.JsonMap, len(request)+1)
+	for key, value := range request {
+		placeRequest[key] = value
+	}
+	placeRequest["PreviewIds"] = jsonmap.JsonSlice{
+		jsonmap.JsonMap{"previewId": previewId},
+	}
+	requestMap := jsonmap.JsonMap{
+		"PlaceOrderRequest": placeRequest,
+	}
+	return requestMap.ToJsonBytes(false, false)
+}
+
+func (c *eTradeClient) doRequest(
+	category requestCategory, method string, baseUrl string, queryValues url.Values,
+) ([]byte, error) {
+	return c.doRequestWithBody(category, method, baseUrl, queryValues, nil)
+}
+
+// doRequestWithBody waits for the rate limiter for the request's category and
+// performs the request. If the request fails with a transient error (e.g. the
+// server is throttling requests), then it's retried with exponential backoff,
+// unless it modifies orders.
+func (c *eTradeClient) doRequestWithBody(
+	category requestCategory, method string, baseUrl string, queryValues url.Values, body []byte,
+) ([]byte, error) {
 	// Parse any query parameters from the base URL and merge them with the provided query parameters and encode
-	urlQueryValues, err := url.ParseQuery(req.URL.RawQuery)
+	requestUrl, err := url.Parse(baseUrl)
 	if err != nil {
 		return nil, err
 	}
-	for key, values := range urlQueryValues {
-		for _, value := range values {
-			queryValues.Add(key, value)
+	mergedQueryValues := url.Values{}
+	for _, values := range []url.Values{queryValues, requestUrl.Query()} {
+		for key, keyValues := range values {
+			for _, value := range keyValues {
+				mergedQueryValues.Add(key, value)
+			}
+		}
+	}
+	requestUrl.RawQuery = mergedQueryValues.Encode()
+
+	ctx := c.ctx
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+	retryable := isRetryableRequest(category, method)
+	for retry := 0; ; retry++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if limiter, found := c.limiters[category]; found {
+			if wait := limiter.reserve(c.now()); wait > 0 {
+				c.logger.Debug(fmt.Sprintf("rate limiter (%s) delayed %s %s by %s", category, method, requestUrl, wait))
+				if err := c.sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+			}
+		}
+		responseBytes, err := c.doSingleRequest(ctx, method, requestUrl.String(), body)
+		if err == nil {
+			if retry > 0 {
+				c.logger.Debug(fmt.Sprintf("%s %s succeeded after %d retries", method, requestUrl, retry))
+			}
+			return responseBytes, nil
+		}
+		// Don't retry if the context was canceled or its deadline passed.
+		if !retryable || ctx.Err() != nil || !isRetryableError(err) || retry >= c.retryPolicy.maxRetries {
+			if retry > 0 {
+				c.logger.Debug(fmt.Sprintf("%s %s failed after %d retries", method, requestUrl, retry))
+			}
+			return nil, err
 		}
+		delay := c.retryPolicy.getDelay(retry, c.random())
+		if retryAfter := getRetryAfter(err); retryAfter > delay {
+			delay = retryAfter
+		}
+		c.logger.Debug(
+			fmt.Sprintf(
+				"%s %s failed (%s); retry %d of %d in %s", method, requestUrl, err, retry+1,
+				c.retryPolicy.maxRetries, delay,
+			),
+		)
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doSingleRequest performs a request once, with the context.
+func (c *eTradeClient) doSingleRequest(
+	ctx context.Context, method string, requestUrl string, body []byte,
+) ([]byte, error) {
+	var bodyReader io.Reader = nil
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestUrl, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Request that the server respond with JSON
+	req.Header.Add("Accept", `application/json`)
+	if body != nil {
+		// Let the server know that the request body is JSON
+		req.Header.Add("Content-Type", `application/json`)
 	}
-	req.URL.RawQuery = queryValues.Encode()
 
 	// Perform the request
 	c.logger.Debug(method + " " + req.URL.String())
-	httpResponse, err := c.httpClient.Do(req)
+	if body != nil {
+		c.logger.Debug(string(body))
+	}
+	httpResponse, err := c.getHttpClient().Do(req)
 	if httpResponse != nil {
 		defer func(Body io.ReadCloser) {
 			err := Body.Close()
@@ -539,7 +941,7 @@ func (c *eTradeClient) doRequest(method string, baseUrl string, queryValues url.
 	}
 	// Return a failure if the status code is not 200
 	if httpResponse.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed: %s", httpResponse.Status)
+		return nil, newHttpStatusError(httpResponse)
 	}
 	// Return the response bytes if no error
 	responseBytes, err := io.ReadAll(httpResponse.Body)
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
 	"github.com/stretchr/testify/mock"
 	"time"
 )
@@ -10,6 +12,17 @@ type ETradeClientMock struct {
 	mock.Mock
 }
 
+// WithContext returns the mock itself, so tests don't need to expect it.
+func (c *ETradeClientMock) WithContext(_ context.Context) ETradeClient {
+	return c
+}
+
+// WithRequestTimeout returns the mock itself, so tests don't need to expect
+// it.
+func (c *ETradeClientMock) WithRequestTimeout(_ time.Duration) ETradeClient {
+	return c
+}
+
 func (c *ETradeClientMock) Authenticate() ([]byte, error) {
 	args := c.Called()
 	return args.Get(0).([]byte), args.Error(1)
@@ -121,3 +134,32 @@ func (c *ETradeClientMock) ListOrders(
 	)
 	return args.Get(0).([]byte), args.Error(1)
 }
+
+func (c *ETradeClientMock) PreviewOrder(accountIdKey string, request jsonmap.JsonMap) ([]byte, error) {
+	args := c.Called(accountIdKey, request)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (c *ETradeClientMock) PlaceOrder(accountIdKey string, previewId int64, request jsonmap.JsonMap) ([]byte, error) {
+	args := c.Called(accountIdKey, previewId, request)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (c *ETradeClientMock) CancelOrder(accountIdKey string, orderId int64) ([]byte, error) {
+	args := c.Called(accountIdKey, orderId)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (c *ETradeClientMock) PreviewChangedOrder(accountIdKey string, orderId int64, request jsonmap.JsonMap) (
+	[]byte, error,
+) {
+	args := c.Called(accountIdKey, orderId, request)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (c *ETradeClientMock) PlaceChangedOrder(
+	accountIdKey string, orderId int64, previewId int64, request jsonmap.JsonMap,
+) ([]byte, error) {
+	args := c.Called(accountIdKey, orderId, previewId, request)
+	return args.Get(0).([]byte), args.Error(1)
+}
@@ -1,12 +1,21 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"github.com/dghubble/oauth1"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/etradelibtest"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -15,18 +24,24 @@ func createMockClient(
 	httpClient HttpClient, config OAuthConfig, production bool,
 	consumerKey, consumerSecret, requestToken, requestSecret, accessToken, accessSecret string,
 ) ETradeClient {
-
+	urls := GetEndpointUrls(production)
 	return &eTradeClient{
-		urls:           GetEndpointUrls(production),
-		httpClient:     httpClient,
+		urls:           urls,
 		logger:         etradelibtest.CreateNullLogger(),
 		config:         config,
 		consumerKey:    consumerKey,
 		consumerSecret: consumerSecret,
-		requestToken:   requestToken,
-		requestSecret:  requestSecret,
-		accessToken:    accessToken,
-		accessSecret:   accessSecret,
+		session: &eTradeSession{
+			httpClient:    httpClient,
+			requestToken:  requestToken,
+			requestSecret: requestSecret,
+			accessToken:   accessToken,
+			accessSecret:  accessSecret,
+		},
+		ctx:    context.Background(),
+		now:    time.Now,
+		sleep:  func(context.Context, time.Duration) error { return nil },
+		random: func() float64 { return 0 },
 	}
 }
 
@@ -875,6 +890,200 @@ func TestETradeClient(t *testing.T) {
 			expectResponse: nil,
 			expectErr:      true,
 		},
+		{
+			name: "Preview Order",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "POST", "https://api.etrade.com/v1/accounts/1234/orders/preview",
+					`{"PreviewOrderRequest":{"orderType":"EQ"}}`+"\n",
+				).Return(http.StatusOK, testResponseData, nil)
+
+				return testClient.PreviewOrder("1234", jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+		},
+		{
+			name: "Preview Order Fails On HTTP Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "POST", "https://api.etrade.com/v1/accounts/1234/orders/preview",
+					`{"PreviewOrderRequest":{"orderType":"EQ"}}`+"\n",
+				).Return(0, "", errors.New("test error"))
+
+				return testClient.PreviewOrder("1234", jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(nil),
+			expectErr:      true,
+		},
+		{
+			name: "Preview Order Fails Without Account ID Key",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.PreviewOrder("", jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Preview Order Fails Without Request",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.PreviewOrder("1234", nil)
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Place Order",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "POST", "https://api.etrade.com/v1/accounts/1234/orders/place",
+					`{"PlaceOrderRequest":{"PreviewIds":[{"previewId":5678}],"orderType":"EQ"}}`+"\n",
+				).Return(http.StatusOK, testResponseData, nil)
+
+				return testClient.PlaceOrder("1234", 5678, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+		},
+		{
+			name: "Place Order Fails On HTTP Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "POST", "https://api.etrade.com/v1/accounts/1234/orders/place",
+					`{"PlaceOrderRequest":{"PreviewIds":[{"previewId":5678}],"orderType":"EQ"}}`+"\n",
+				).Return(0, "", errors.New("test error"))
+
+				return testClient.PlaceOrder("1234", 5678, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(nil),
+			expectErr:      true,
+		},
+		{
+			name: "Place Order Fails Without Preview ID",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.PlaceOrder("1234", 0, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Place Order Fails Without Account ID Key",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.PlaceOrder("", 5678, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Cancel Order",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "PUT", "https://api.etrade.com/v1/accounts/1234/orders/cancel",
+					`{"CancelOrderRequest":{"orderId":5678}}`+"\n",
+				).Return(http.StatusOK, testResponseData, nil)
+
+				return testClient.CancelOrder("1234", 5678)
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+		},
+		{
+			name: "Cancel Order Fails On HTTP Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "PUT", "https://api.etrade.com/v1/accounts/1234/orders/cancel",
+					`{"CancelOrderRequest":{"orderId":5678}}`+"\n",
+				).Return(0, "", errors.New("test error"))
+
+				return testClient.CancelOrder("1234", 5678)
+			},
+			expectResponse: []byte(nil),
+			expectErr:      true,
+		},
+		{
+			name: "Cancel Order Fails Without Order ID",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.CancelOrder("1234", 0)
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Cancel Order Fails Without Account ID Key",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.CancelOrder("", 5678)
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Preview Changed Order",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "PUT", "https://api.etrade.com/v1/accounts/1234/orders/5678/change/preview",
+					`{"PreviewOrderRequest":{"orderType":"EQ"}}`+"\n",
+				).Return(http.StatusOK, testResponseData, nil)
+
+				return testClient.PreviewChangedOrder("1234", 5678, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+		},
+		{
+			name: "Preview Changed Order Fails On HTTP Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "PUT", "https://api.etrade.com/v1/accounts/1234/orders/5678/change/preview",
+					`{"PreviewOrderRequest":{"orderType":"EQ"}}`+"\n",
+				).Return(0, "", errors.New("test error"))
+
+				return testClient.PreviewChangedOrder("1234", 5678, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(nil),
+			expectErr:      true,
+		},
+		{
+			name: "Preview Changed Order Fails Without Order ID",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.PreviewChangedOrder("1234", 0, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
+		{
+			name: "Place Changed Order",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "PUT", "https://api.etrade.com/v1/accounts/1234/orders/5678/change/place",
+					`{"PlaceOrderRequest":{"PreviewIds":[{"previewId":9012}],"orderType":"EQ"}}`+"\n",
+				).Return(http.StatusOK, testResponseData, nil)
+
+				return testClient.PlaceChangedOrder("1234", 5678, 9012, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+		},
+		{
+			name: "Place Changed Order Fails On HTTP Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "PUT", "https://api.etrade.com/v1/accounts/1234/orders/5678/change/place",
+					`{"PlaceOrderRequest":{"PreviewIds":[{"previewId":9012}],"orderType":"EQ"}}`+"\n",
+				).Return(0, "", errors.New("test error"))
+
+				return testClient.PlaceChangedOrder("1234", 5678, 9012, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: []byte(nil),
+			expectErr:      true,
+		},
+		{
+			name: "Place Changed Order Fails Without Preview ID",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				return testClient.PlaceChangedOrder("1234", 5678, 0, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: nil,
+			expectErr:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -897,3 +1106,383 @@ func TestETradeClient(t *testing.T) {
 		)
 	}
 }
+
+func TestETradeClient_Retries(t *testing.T) {
+	type testFn func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error)
+
+	const testResponseData = `{"testResponse": true}`
+	const listAccountsUrl = "https://api.etrade.com/v1/accounts/list"
+	connectionResetError := &url.Error{Op: "Get", URL: listAccountsUrl, Err: syscall.ECONNRESET}
+
+	tests := []struct {
+		name           string
+		testFn         testFn
+		expectResponse []byte
+		expectErr      bool
+		expectSleeps   []time.Duration
+	}{
+		{
+			name: "Retries After Server Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusServiceUnavailable, "", nil).Once()
+				clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusOK, testResponseData, nil).Once()
+				return testClient.ListAccounts()
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+			expectSleeps:   []time.Duration{250 * time.Millisecond},
+		},
+		{
+			name: "Retries After Throttling And Connection Reset With Backoff",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusTooManyRequests, "", nil).Once()
+				clientMock.On("Do", "GET", listAccountsUrl).Return(0, "", connectionResetError).Once()
+				clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusOK, testResponseData, nil).Once()
+				return testClient.ListAccounts()
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+			expectSleeps:   []time.Duration{250 * time.Millisecond, 500 * time.Millisecond},
+		},
+		{
+			name: "Fails After Max Retries",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusBadGateway, "", nil).Times(5)
+				return testClient.ListAccounts()
+			},
+			expectResponse: nil,
+			expectErr:      true,
+			expectSleeps: []time.Duration{
+				250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second,
+			},
+		},
+		{
+			name: "Does Not Retry Client Error",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusBadRequest, "", nil).Once()
+				return testClient.ListAccounts()
+			},
+			expectResponse: nil,
+			expectErr:      true,
+			expectSleeps:   nil,
+		},
+		{
+			name: "Does Not Retry Order Post",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "POST", "https://api.etrade.com/v1/accounts/1234/orders/place", mock.Anything,
+				).Return(http.StatusServiceUnavailable, "", nil).Once()
+				return testClient.PlaceOrder("1234", 5678, jsonmap.JsonMap{"orderType": "EQ"})
+			},
+			expectResponse: nil,
+			expectErr:      true,
+			expectSleeps:   nil,
+		},
+		{
+			name: "Retries Order List",
+			testFn: func(testClient ETradeClient, clientMock *httpClientMock) ([]byte, error) {
+				clientMock.On(
+					"Do", "GET", "https://api.etrade.com/v1/accounts/1234/orders",
+				).Return(http.StatusServiceUnavailable, "", nil).Once()
+				clientMock.On(
+					"Do", "GET", "https://api.etrade.com/v1/accounts/1234/orders",
+				).Return(http.StatusOK, testResponseData, nil).Once()
+				return testClient.ListOrders(
+					"1234", "", -1, constants.OrderStatusNil, nil, nil, nil, constants.OrderSecurityTypeNil,
+					constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+				)
+			},
+			expectResponse: []byte(testResponseData),
+			expectErr:      false,
+			expectSleeps:   []time.Duration{250 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				clientMock := new(httpClientMock)
+				testClient := createMockClient(clientMock, nil, true, "", "", "", "", "", "").(*eTradeClient)
+				testClient.retryPolicy = defaultRetryPolicy
+				var actualSleeps []time.Duration
+				testClient.sleep = func(_ context.Context, d time.Duration) error {
+					actualSleeps = append(actualSleeps, d)
+					return nil
+				}
+				// Call the Method Under Test
+				actualResponse, err := tt.testFn(testClient, clientMock)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectResponse, actualResponse)
+				assert.Equal(t, tt.expectSleeps, actualSleeps)
+				clientMock.AssertExpectations(t)
+			},
+		)
+	}
+}
+
+func TestETradeClient_RateLimits(t *testing.T) {
+	const listAccountsUrl = "https://api.etrade.com/v1/accounts/list"
+	clientMock := new(httpClientMock)
+	clientMock.On("Do", "GET", listAccountsUrl).Return(http.StatusOK, "", nil)
+	clientMock.On("Do", "GET", "https://api.etrade.com/v1/market/lookup/ABC").Return(http.StatusOK, "", nil)
+	testClient := createMockClient(clientMock, nil, true, "", "", "", "", "", "").(*eTradeClient)
+	testClient.limiters = newRateLimiters(
+		map[requestCategory]rateLimit{
+			requestCategoryAccounts: {requestsPerSecond: 2, burst: 1},
+			requestCategoryMarket:   {requestsPerSecond: 2, burst: 1},
+		},
+	)
+	testTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	testClient.now = func() time.Time { return testTime }
+	var actualSleeps []time.Duration
+	testClient.sleep = func(_ context.Context, d time.Duration) error {
+		actualSleeps = append(actualSleeps, d)
+		return nil
+	}
+
+	// Call the Method Under Test
+	_, err := testClient.ListAccounts()
+	assert.Nil(t, err)
+	_, err = testClient.ListAccounts()
+	assert.Nil(t, err)
+	// The market category has its own limit.
+	_, err = testClient.LookupProduct("ABC")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []time.Duration{500 * time.Millisecond}, actualSleeps)
+}
+
+func TestETradeClient_WithContext(t *testing.T) {
+	t.Run(
+		"Canceled Context Fails Without Request", func(t *testing.T) {
+			clientMock := new(httpClientMock)
+			testClient := createMockClient(clientMock, nil, true, "", "", "", "", "", "")
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			// Call the Method Under Test
+			_, err := testClient.WithContext(ctx).ListAccounts()
+			assert.ErrorIs(t, err, context.Canceled)
+			clientMock.AssertNotCalled(t, "Do", "GET", "https://api.etrade.com/v1/accounts/list")
+		},
+	)
+	t.Run(
+		"Canceled Context Stops Retries", func(t *testing.T) {
+			clientMock := new(httpClientMock)
+			clientMock.On("Do", "GET", "https://api.etrade.com/v1/accounts/list").Return(
+				http.StatusServiceUnavailable, "", nil,
+			).Once()
+			testClient := createMockClient(clientMock, nil, true, "", "", "", "", "", "").(*eTradeClient)
+			testClient.retryPolicy = defaultRetryPolicy
+			ctx, cancel := context.WithCancel(context.Background())
+			testClient.sleep = func(ctx context.Context, d time.Duration) error {
+				cancel()
+				return sleepWithContext(ctx, d)
+			}
+			// Call the Method Under Test
+			_, err := testClient.WithContext(ctx).ListAccounts()
+			assert.ErrorIs(t, err, context.Canceled)
+			clientMock.AssertExpectations(t)
+		},
+	)
+	t.Run(
+		"Deadline Stops Hung Request", func(t *testing.T) {
+			unblock := make(chan struct{})
+			server := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						<-unblock
+					},
+				),
+			)
+			defer server.Close()
+			defer close(unblock)
+			testClient, err := CreateETradeClientWithHttpClientWrapper(
+				etradelibtest.CreateNullLogger(),
+				&EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: server.URL}, "key", "secret", "",
+				"", nil,
+			)
+			assert.Nil(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			// Call the Method Under Test
+			_, err = testClient.WithContext(ctx).ListAccounts()
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		},
+	)
+	t.Run(
+		"Request Timeout Stops Hung Request", func(t *testing.T) {
+			unblock := make(chan struct{})
+			server := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						<-unblock
+					},
+				),
+			)
+			defer server.Close()
+			defer close(unblock)
+			testClient, err := CreateETradeClientWithHttpClientWrapper(
+				etradelibtest.CreateNullLogger(),
+				&EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: server.URL}, "key", "secret", "",
+				"", nil,
+			)
+			assert.Nil(t, err)
+			// Call the Method Under Test
+			_, err = testClient.WithRequestTimeout(50 * time.Millisecond).ListAccounts()
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+		},
+	)
+	t.Run(
+		"Request Timeout Applies To Each Request", func(t *testing.T) {
+			server := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						time.Sleep(40 * time.Millisecond)
+						_, _ = w.Write([]byte(`{}`))
+					},
+				),
+			)
+			defer server.Close()
+			testClient, err := CreateETradeClientWithHttpClientWrapper(
+				etradelibtest.CreateNullLogger(),
+				&EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: server.URL}, "key", "secret", "",
+				"", nil,
+			)
+			assert.Nil(t, err)
+			// Don't let the rate limiter delay the requests.
+			testClient.(*eTradeClient).limiters = nil
+			timeoutClient := testClient.WithRequestTimeout(200 * time.Millisecond)
+			// The requests take longer than the timeout together, but not
+			// individually.
+			for i := 0; i < 10; i++ {
+				// Call the Method Under Test
+				_, err = timeoutClient.ListAccounts()
+				assert.Nil(t, err)
+			}
+		},
+	)
+	t.Run(
+		"Records Token Requests", func(t *testing.T) {
+			server := httptest.NewServer(
+				http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						if strings.HasSuffix(r.URL.Path, "/renew_access_token") {
+							w.WriteHeader(http.StatusUnauthorized)
+							return
+						}
+						_, _ = w.Write(
+							[]byte("oauth_token=token&oauth_token_secret=secret&oauth_callback_confirmed=true"),
+						)
+					},
+				),
+			)
+			defer server.Close()
+			cassetteDir := t.TempDir()
+			testClient, err := CreateETradeClientWithHttpClientWrapper(
+				etradelibtest.CreateNullLogger(),
+				&EndpointProfile{Environment: EndpointEnvironmentCustom, UrlBase: server.URL}, "key", "secret", "",
+				"", func(httpClient HttpClient) HttpClient {
+					return CreateRecordingHttpClient(httpClient, cassetteDir)
+				},
+			)
+			require.Nil(t, err)
+			// Call the Method Under Test
+			_, err = testClient.WithContext(context.Background()).Authenticate()
+			require.Nil(t, err)
+			_, err = testClient.Verify("TestVerifyKey")
+			require.Nil(t, err)
+
+			interactions, err := LoadCassette(cassetteDir)
+			require.Nil(t, err)
+			var actualPaths []string
+			for _, interaction := range interactions {
+				requestUrl, err := url.Parse(interaction.Request.Url)
+				require.Nil(t, err)
+				actualPaths = append(actualPaths, requestUrl.Path)
+			}
+			assert.Equal(
+				t, []string{"/oauth/renew_access_token", "/oauth/request_token", "/oauth/access_token"}, actualPaths,
+			)
+		},
+	)
+	t.Run(
+		"Shares Authentication State", func(t *testing.T) {
+			clientMock := new(httpClientMock)
+			clientMock.On("Do", "GET", "https://api.etrade.com/oauth/renew_access_token").Return(
+				http.StatusUnauthorized, "", nil,
+			)
+			configMock := new(oAuthConfigMock)
+			configMock.On("RequestToken").Return("TestRequestToken", "TestRequestSecret", nil)
+			configMock.On("AccessToken", "TestRequestToken", "TestRequestSecret", "TestVerifyKey").Return(
+				"TestAccessToken", "TestAccessSecret", nil,
+			)
+			configMock.On("Client", mock.Anything, mock.Anything).Return(&http.Client{})
+			testClient := createMockClient(clientMock, configMock, true, "", "", "", "", "", "")
+			// Call the Method Under Test
+			_, err := testClient.WithContext(context.Background()).Authenticate()
+			assert.Nil(t, err)
+			_, err = testClient.Verify("TestVerifyKey")
+			assert.Nil(t, err)
+			_, _, accessToken, accessSecret := testClient.WithContext(context.Background()).GetKeys()
+			assert.Equal(t, "TestAccessToken", accessToken)
+			assert.Equal(t, "TestAccessSecret", accessSecret)
+		},
+	)
+}
+
+func TestETradeClient_ConcurrentAuthentication(t *testing.T) {
+	clientMock := new(httpClientMock)
+	clientMock.On("Do", "GET", "https://api.etrade.com/oauth/renew_access_token").Return(
+		http.StatusUnauthorized, "", nil,
+	)
+	clientMock.On("Do", "GET", "https://api.etrade.com/v1/accounts/list").Return(http.StatusOK, "{}", nil)
+	configMock := new(oAuthConfigMock)
+	configMock.On("RequestToken").Return("TestRequestToken", "TestRequestSecret", nil)
+	configMock.On(
+		"AccessToken", "TestRequestToken", "TestRequestSecret", "TestVerifyKey",
+	).Return("TestAccessToken", "TestAccessSecret", nil)
+	configMock.On("Client", mock.Anything, mock.Anything).Return(&http.Client{})
+	testClient := createMockClient(
+		clientMock, configMock, true, "TestConsumerKey", "TestConsumerSecret", "", "", "", "",
+	).(*eTradeClient)
+	testClient.httpClientWrapper = func(HttpClient) HttpClient {
+		return clientMock
+	}
+
+	// Authenticate, verify, and make requests with several copies of the
+	// client at once. The race detector reports any unsynchronized access to
+	// their shared session.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			contextClient := testClient.WithContext(context.Background())
+			_, err := contextClient.Authenticate()
+			assert.Nil(t, err)
+			_, err = contextClient.Verify("TestVerifyKey")
+			assert.Nil(t, err)
+			_, err = contextClient.ListAccounts()
+			assert.Nil(t, err)
+			_, _, _, _ = contextClient.GetKeys()
+		}()
+	}
+	wg.Wait()
+
+	_, _, actualAccessToken, actualAccessSecret := testClient.GetKeys()
+	assert.Equal(t, "TestAccessToken", actualAccessToken)
+	assert.Equal(t, "TestAccessSecret", actualAccessSecret)
+}
+
+func TestCreateETradeClientWithHttpClientWrapper_FailsWithoutProfile(t *testing.T) {
+	// Call the Method Under Test
+	testClient, err := CreateETradeClientWithHttpClientWrapper(
+		etradelibtest.CreateNullLogger(), nil, "key", "secret", "", "", nil,
+	)
+	assert.EqualError(t, err, "no endpoint profile provided")
+	assert.Nil(t, testClient)
+}
@@ -12,7 +12,18 @@ type httpClientMock struct {
 }
 
 func (m *httpClientMock) Do(req *http.Request) (*http.Response, error) {
-	args := m.Called(req.Method, req.URL.String())
+	var args mock.Arguments
+	if req.Body != nil {
+		// Requests with a body include the body as a string so that tests can
+		// verify its contents.
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		args = m.Called(req.Method, req.URL.String(), string(body))
+	} else {
+		args = m.Called(req.Method, req.URL.String())
+	}
 
 	responseCode := args.Int(0)
 	responseBody := args.String(1)
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// requestCategory identifies the ETrade API category whose rate limit a
+// request counts against.
+type requestCategory int
+
+const (
+	// requestCategoryAccounts indicates the accounts and alerts APIs (and the
+	// renewal of access tokens)
+	requestCategoryAccounts requestCategory = iota
+
+	// requestCategoryMarket indicates the market APIs
+	requestCategoryMarket
+
+	// requestCategoryOrders indicates the order APIs
+	requestCategoryOrders
+)
+
+func (c requestCategory) String() string {
+	switch c {
+	case requestCategoryAccounts:
+		return "accounts"
+	case requestCategoryMarket:
+		return "market"
+	case requestCategoryOrders:
+		return "orders"
+	default:
+		return "unknown"
+	}
+}
+
+// rateLimit is the sustained rate and the burst size allowed for a category.
+type rateLimit struct {
+	requestsPerSecond float64
+	burst             int
+}
+
+// defaultRateLimits are conservative limits that keep a client well below
+// the rates at which ETrade starts throttling.
+var defaultRateLimits = map[requestCategory]rateLimit{
+	requestCategoryAccounts: {requestsPerSecond: 4, burst: 4},
+	requestCategoryMarket:   {requestsPerSecond: 4, burst: 4},
+	requestCategoryOrders:   {requestsPerSecond: 2, burst: 2},
+}
+
+// newRateLimiters creates a token bucket for each category.
+func newRateLimiters(limits map[requestCategory]rateLimit) map[requestCategory]*tokenBucket {
+	limiters := make(map[requestCategory]*tokenBucket, len(limits))
+	for category, limit := range limits {
+		limiters[category] = newTokenBucket(limit.requestsPerSecond, limit.burst)
+	}
+	return limiters
+}
+
+// tokenBucket is a token bucket rate limiter. It's safe for concurrent use.
+type tokenBucket struct {
+	mutex             sync.Mutex
+	requestsPerSecond float64
+	burst             float64
+	tokens            float64
+	lastUpdate        time.Time
+}
+
+func newTokenBucket(requestsPerSecond float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		requestsPerSecond: requestsPerSecond,
+		burst:             float64(burst),
+		tokens:            float64(burst),
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller must
+// wait before using it. The bucket may go into debt so that callers that
+// reserve tokens at the same time are spaced out.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.lastUpdate.IsZero() && now.After(b.lastUpdate) {
+		elapsed := now.Sub(b.lastUpdate).Seconds()
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.requestsPerSecond)
+	}
+	if now.After(b.lastUpdate) {
+		b.lastUpdate = now
+	}
+	b.tokens -= 1
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.requestsPerSecond * float64(time.Second))
+}
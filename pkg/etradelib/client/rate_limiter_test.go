@@ -0,0 +1,63 @@
+package client
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	testTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		testTimes   []time.Time
+		expectWaits []time.Duration
+	}{
+		{
+			name:        "Allows Burst Without Waiting",
+			testTimes:   []time.Time{testTime, testTime},
+			expectWaits: []time.Duration{0, 0},
+		},
+		{
+			name:        "Spaces Requests Beyond Burst",
+			testTimes:   []time.Time{testTime, testTime, testTime, testTime},
+			expectWaits: []time.Duration{0, 0, 500 * time.Millisecond, time.Second},
+		},
+		{
+			name: "Refills Over Time",
+			testTimes: []time.Time{
+				testTime, testTime, testTime, testTime.Add(time.Second), testTime.Add(time.Second),
+			},
+			expectWaits: []time.Duration{0, 0, 500 * time.Millisecond, 0, 500 * time.Millisecond},
+		},
+		{
+			name: "Does Not Refill Beyond Burst",
+			testTimes: []time.Time{
+				testTime, testTime.Add(time.Hour), testTime.Add(time.Hour), testTime.Add(time.Hour),
+			},
+			expectWaits: []time.Duration{0, 0, 0, 500 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				bucket := newTokenBucket(2, 2)
+				var actualWaits []time.Duration
+				for _, now := range tt.testTimes {
+					// Call the Method Under Test
+					actualWaits = append(actualWaits, bucket.reserve(now))
+				}
+				assert.Equal(t, tt.expectWaits, actualWaits)
+			},
+		)
+	}
+}
+
+func TestNewRateLimiters(t *testing.T) {
+	limiters := newRateLimiters(defaultRateLimits)
+	assert.Len(t, limiters, 3)
+	assert.Equal(t, 2.0, limiters[requestCategoryOrders].requestsPerSecond)
+	assert.Equal(t, 2.0, limiters[requestCategoryOrders].burst)
+}
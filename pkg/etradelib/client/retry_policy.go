@@ -0,0 +1,103 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// retryPolicy determines how many times, and how long after, a failed
+// request is retried.
+type retryPolicy struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	maxRetries: 4,
+	baseDelay:  500 * time.Millisecond,
+	maxDelay:   10 * time.Second,
+}
+
+// getDelay returns the delay before a retry (numbered from 0). The delay
+// grows exponentially up to the maximum, and half of it is jittered by the
+// random value (which must be in [0, 1)) so that clients that fail together
+// don't retry together.
+func (p retryPolicy) getDelay(retry int, random float64) time.Duration {
+	delay := p.maxDelay
+	if retry < 32 {
+		if exponentialDelay := p.baseDelay << retry; exponentialDelay > 0 && exponentialDelay < p.maxDelay {
+			delay = exponentialDelay
+		}
+	}
+	return delay/2 + time.Duration(random*float64(delay/2))
+}
+
+// httpStatusError is the error for a response whose status isn't OK (or
+// unauthorized, which is ErrETradeAuthFailed).
+type httpStatusError struct {
+	statusCode int
+	status     string
+	retryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return "request failed: " + e.status
+}
+
+func newHttpStatusError(response *http.Response) *httpStatusError {
+	statusError := &httpStatusError{
+		statusCode: response.StatusCode,
+		status:     response.Status,
+	}
+	if statusError.status == "" {
+		statusError.status = strconv.Itoa(response.StatusCode) + " " + http.StatusText(response.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		statusError.retryAfter = time.Duration(seconds) * time.Second
+	}
+	return statusError
+}
+
+// isRetryableRequest returns true if a request may be retried automatically.
+// Requests that modify orders (e.g. placing one) are never retried, because a
+// request that seemed to fail may have succeeded.
+func isRetryableRequest(category requestCategory, method string) bool {
+	return category != requestCategoryOrders || method == http.MethodGet
+}
+
+// isRetryableError returns true if a request that failed with the error may
+// succeed if it's retried: the server is throttling requests or had a
+// temporary failure, or the connection failed.
+func isRetryableError(err error) bool {
+	var statusError *httpStatusError
+	if errors.As(err, &statusError) {
+		switch statusError.statusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		default:
+			return false
+		}
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netError net.Error
+	return errors.As(err, &netError) && netError.Timeout()
+}
+
+// getRetryAfter returns the delay that the server requested before a retry,
+// or zero if it didn't request one.
+func getRetryAfter(err error) time.Duration {
+	var statusError *httpStatusError
+	if errors.As(err, &statusError) {
+		return statusError.retryAfter
+	}
+	return 0
+}
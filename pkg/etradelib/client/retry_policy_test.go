@@ -0,0 +1,99 @@
+package client
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_GetDelay(t *testing.T) {
+	policy := retryPolicy{maxRetries: 10, baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	assert.Equal(t, 50*time.Millisecond, policy.getDelay(0, 0))
+	assert.Equal(t, 75*time.Millisecond, policy.getDelay(0, 0.5))
+	assert.Equal(t, 150*time.Millisecond, policy.getDelay(1, 0.5))
+	assert.Equal(t, 400*time.Millisecond, policy.getDelay(3, 0))
+	assert.Equal(t, 500*time.Millisecond, policy.getDelay(4, 0))
+	assert.Equal(t, 500*time.Millisecond, policy.getDelay(100, 0))
+}
+
+func TestIsRetryableRequest(t *testing.T) {
+	assert.True(t, isRetryableRequest(requestCategoryAccounts, "GET"))
+	assert.True(t, isRetryableRequest(requestCategoryAccounts, "DELETE"))
+	assert.True(t, isRetryableRequest(requestCategoryMarket, "GET"))
+	assert.True(t, isRetryableRequest(requestCategoryOrders, "GET"))
+	assert.False(t, isRetryableRequest(requestCategoryOrders, "POST"))
+	assert.False(t, isRetryableRequest(requestCategoryOrders, "PUT"))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name        string
+		testErr     error
+		expectValue bool
+	}{
+		{
+			name:        "Throttling Is Retryable",
+			testErr:     &httpStatusError{statusCode: http.StatusTooManyRequests},
+			expectValue: true,
+		},
+		{
+			name:        "Server Error Is Retryable",
+			testErr:     &httpStatusError{statusCode: http.StatusServiceUnavailable},
+			expectValue: true,
+		},
+		{
+			name:        "Client Error Is Not Retryable",
+			testErr:     &httpStatusError{statusCode: http.StatusBadRequest},
+			expectValue: false,
+		},
+		{
+			name:        "Connection Reset Is Retryable",
+			testErr:     &url.Error{Op: "Get", URL: "https://api.etrade.com", Err: syscall.ECONNRESET},
+			expectValue: true,
+		},
+		{
+			name:        "Unexpected EOF Is Retryable",
+			testErr:     &url.Error{Op: "Get", URL: "https://api.etrade.com", Err: io.ErrUnexpectedEOF},
+			expectValue: true,
+		},
+		{
+			name:        "Auth Failure Is Not Retryable",
+			testErr:     ErrETradeAuthFailed,
+			expectValue: false,
+		},
+		{
+			name:        "Other Error Is Not Retryable",
+			testErr:     errors.New("test error"),
+			expectValue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := isRetryableError(tt.testErr)
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestNewHttpStatusError(t *testing.T) {
+	response := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"3"}},
+	}
+
+	// Call the Method Under Test
+	statusError := newHttpStatusError(response)
+	assert.Equal(t, "request failed: 429 Too Many Requests", statusError.Error())
+	assert.Equal(t, 3*time.Second, getRetryAfter(statusError))
+	assert.Equal(t, time.Duration(0), getRetryAfter(errors.New("test error")))
+}
@@ -2,12 +2,14 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 )
 
 type ETradeAccount interface {
 	GetId() string
 	GetIdKey() string
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Account, error)
 }
 
 type eTradeAccount struct {
@@ -62,3 +64,12 @@ func (e *eTradeAccount) GetIdKey() string {
 func (e *eTradeAccount) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the account as a typed model.
+func (e *eTradeAccount) AsModel() (*model.Account, error) {
+	var m model.Account
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -2,6 +2,7 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -118,3 +119,34 @@ func TestETradeAccount_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeAccount_AsModel(t *testing.T) {
+	responseMap, err := NewNormalizedJsonMap(
+		[]byte(`
+{
+  "accountId": "1234",
+  "accountIdKey": "abcd",
+  "accountMode": "MARGIN",
+  "accountName": "Brokerage",
+  "accountType": "INDIVIDUAL",
+  "accountStatus": "ACTIVE",
+  "closedDate": 0
+}`),
+	)
+	require.Nil(t, err)
+	testObject, err := CreateETradeAccountFromMap(responseMap)
+	require.Nil(t, err)
+	expectedValue := &model.Account{
+		AccountId:     "1234",
+		AccountIdKey:  "abcd",
+		AccountMode:   "MARGIN",
+		AccountName:   "Brokerage",
+		AccountType:   "INDIVIDUAL",
+		AccountStatus: "ACTIVE",
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
@@ -2,11 +2,13 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 )
 
 type ETradeAlert interface {
 	GetId() int64
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Alert, error)
 }
 
 type eTradeAlert struct {
@@ -44,3 +46,12 @@ func (e *eTradeAlert) GetId() int64 {
 func (e *eTradeAlert) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the alert as a typed model.
+func (e *eTradeAlert) AsModel() (*model.Alert, error) {
+	var m model.Alert
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -3,6 +3,7 @@ package etradelib
 import (
 	"encoding/json"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -85,3 +86,29 @@ func TestETradeAlert_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeAlert_AsModel(t *testing.T) {
+	responseMap, err := NewNormalizedJsonMap(
+		[]byte(`
+{
+  "id": 1234,
+  "createTime": 1699900000,
+  "subject": "Test Subject",
+  "status": "UNREAD"
+}`),
+	)
+	require.Nil(t, err)
+	testObject, err := CreateETradeAlert(responseMap)
+	require.Nil(t, err)
+	expectedValue := &model.Alert{
+		Id:         1234,
+		CreateTime: 1699900000,
+		Subject:    "Test Subject",
+		Status:     "UNREAD",
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
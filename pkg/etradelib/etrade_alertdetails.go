@@ -1,10 +1,14 @@
 package etradelib
 
-import "github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+)
 
 type ETradeAlertDetails interface {
 	GetId() int64
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Alert, error)
 }
 
 type eTradeAlertDetails struct {
@@ -61,3 +65,12 @@ func (e *eTradeAlertDetails) GetId() int64 {
 func (e *eTradeAlertDetails) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the alert details as a typed model.
+func (e *eTradeAlertDetails) AsModel() (*model.Alert, error) {
+	var m model.Alert
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
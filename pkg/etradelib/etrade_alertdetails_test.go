@@ -3,7 +3,9 @@ package etradelib
 import (
 	"encoding/json"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -106,3 +108,29 @@ func TestETradeAlertDetails_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeAlertDetails_AsModel(t *testing.T) {
+	testObject, err := CreateETradeAlertDetailsFromResponse(
+		[]byte(`
+{
+  "AlertDetailsResponse": {
+    "id": 1234,
+    "msgText": "Test Message",
+    "readTime": 1699810000,
+    "symbol": "ABC"
+  }
+}`),
+	)
+	require.Nil(t, err)
+	expectedValue := &model.Alert{
+		Id:       1234,
+		MsgText:  "Test Message",
+		ReadTime: 1699810000,
+		Symbol:   "ABC",
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
@@ -1,9 +1,13 @@
 package etradelib
 
-import "github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+)
 
 type ETradeBalances interface {
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Balances, error)
 }
 
 type eTradeBalances struct {
@@ -46,3 +50,12 @@ func CreateETradeBalances(responseMap jsonmap.JsonMap) (ETradeBalances, error) {
 func (e *eTradeBalances) AsJsonMap() jsonmap.JsonMap {
 	return e.balancesMap
 }
+
+// AsModel returns the balances as a typed model.
+func (e *eTradeBalances) AsModel() (*model.Balances, error) {
+	var m model.Balances
+	if err := e.balancesMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -2,7 +2,9 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -81,3 +83,47 @@ func TestETradeBalances_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectValue, actualValue)
 }
+
+func TestETradeBalances_AsModel(t *testing.T) {
+	testObject, err := CreateETradeBalancesFromResponse(
+		[]byte(`
+{
+  "BalanceResponse": {
+    "accountId": "1234",
+    "accountType": "INDIVIDUAL",
+    "optionLevel": "LEVEL_2",
+    "Cash": {
+      "moneyMktBalance": 10.5
+    },
+    "Computed": {
+      "cashAvailableForInvestment": 100.25,
+      "cashBalance": 100.25,
+      "RealTimeValues": {
+        "totalAccountValue": 1100.25
+      }
+    }
+  }
+}`),
+	)
+	require.Nil(t, err)
+	expectedValue := &model.Balances{
+		AccountId:   "1234",
+		AccountType: "INDIVIDUAL",
+		OptionLevel: "LEVEL_2",
+		Cash: &model.CashBalances{
+			MoneyMktBalance: 10.5,
+		},
+		Computed: &model.ComputedBalance{
+			CashAvailableForInvestment: 100.25,
+			CashBalance:                100.25,
+			RealTimeValues: &model.RealTimeValues{
+				TotalAccountValue: 1100.25,
+			},
+		},
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
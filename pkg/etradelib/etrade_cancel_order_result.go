@@ -0,0 +1,145 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+type ETradeCancelOrderResult interface {
+	GetOrderId() int64
+	IsSuccess() bool
+	GetError() string
+	AsJsonMap() jsonmap.JsonMap
+}
+
+type eTradeCancelOrderResult struct {
+	orderId   int64
+	isSuccess bool
+	error     string
+	jsonMap   jsonmap.JsonMap
+}
+
+const (
+	// The AsJsonMap() map looks like this for a successful cancellation:
+	// {
+	//   "status": "success",
+	//   "orderId": 1234,
+	//   "accountId": "1234",
+	//   "cancelTime": 1234567890000,
+	//   "messages": {
+	//     "message": [
+	//       {
+	//         "code": 5011,
+	//         "description": "Your request to cancel your order is being processed.",
+	//         "type": "WARNING"
+	//       }
+	//     ]
+	//   }
+	// }
+	//
+	// And it looks like this for a rejected cancellation:
+	// {
+	//   "status": "error",
+	//   "orderId": 1234,
+	//   "error": "request failed: 400 Bad Request"
+	// }
+
+	// CancelOrderResultStatusKey is the key for the status
+	CancelOrderResultStatusKey = "status"
+
+	// CancelOrderResultOrderIdKey is the key for the order ID
+	CancelOrderResultOrderIdKey = "orderId"
+
+	// CancelOrderResultErrorKey is the key for the error message
+	CancelOrderResultErrorKey = "error"
+)
+
+const (
+	// The cancel order response JSON looks like this:
+	// {
+	//   "CancelOrderResponse": {
+	//     "accountId": "1234",
+	//     "orderId": 1234,
+	//     "cancelTime": 1234567890000,
+	//     "Messages": {
+	//       "Message": [
+	//         {
+	//           "code": 5011,
+	//           "description": "Your request to cancel your order is being processed.",
+	//           "type": "WARNING"
+	//         }
+	//       ]
+	//     }
+	//   }
+	// }
+
+	// cancelOrderResultCancelOrderResponsePath is the path to the cancel
+	// order response
+	cancelOrderResultCancelOrderResponsePath = ".cancelOrderResponse"
+
+	// cancelOrderResultOrderIdResponsePath is the path to the cancelled
+	// order ID within the cancel order response
+	cancelOrderResultOrderIdResponsePath = ".orderId"
+)
+
+func CreateETradeCancelOrderResultFromResponse(response []byte) (ETradeCancelOrderResult, error) {
+	responseMap, err := NewNormalizedJsonMap(response)
+	if err != nil {
+		return nil, err
+	}
+	return CreateETradeCancelOrderResult(responseMap)
+}
+
+func CreateETradeCancelOrderResult(responseMap jsonmap.JsonMap) (ETradeCancelOrderResult, error) {
+	cancelMap, err := responseMap.GetMapAtPath(cancelOrderResultCancelOrderResponsePath)
+	if err != nil {
+		return nil, err
+	}
+	orderId, err := cancelMap.GetIntAtPath(cancelOrderResultOrderIdResponsePath)
+	if err != nil {
+		return nil, err
+	}
+	return &eTradeCancelOrderResult{
+		orderId:   orderId,
+		isSuccess: true,
+		error:     "",
+		jsonMap:   cancelMap,
+	}, nil
+}
+
+// CreateETradeCancelOrderResultFromError creates a result for a cancellation
+// that E*TRADE rejected (or that could not be sent at all).
+func CreateETradeCancelOrderResultFromError(orderId int64, err error) ETradeCancelOrderResult {
+	return &eTradeCancelOrderResult{
+		orderId:   orderId,
+		isSuccess: false,
+		error:     err.Error(),
+		jsonMap:   jsonmap.JsonMap{},
+	}
+}
+
+func (e *eTradeCancelOrderResult) GetOrderId() int64 {
+	return e.orderId
+}
+
+func (e *eTradeCancelOrderResult) IsSuccess() bool {
+	return e.isSuccess
+}
+
+func (e *eTradeCancelOrderResult) GetError() string {
+	return e.error
+}
+
+func (e *eTradeCancelOrderResult) AsJsonMap() jsonmap.JsonMap {
+	resultMap := make(jsonmap.JsonMap, len(e.jsonMap)+3)
+	for key, value := range e.jsonMap {
+		resultMap[key] = value
+	}
+	resultMap[CancelOrderResultOrderIdKey] = e.orderId
+	if e.isSuccess {
+		resultMap[CancelOrderResultStatusKey] = "success"
+	} else {
+		resultMap[CancelOrderResultStatusKey] = "error"
+		resultMap[CancelOrderResultErrorKey] = e.error
+	}
+	return resultMap
+}
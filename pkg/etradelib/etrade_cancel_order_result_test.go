@@ -0,0 +1,131 @@
+package etradelib
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateETradeCancelOrderResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		testJson    string
+		expectErr   bool
+		expectValue ETradeCancelOrderResult
+	}{
+		{
+			name: "Creates Cancel Order Result",
+			testJson: `
+{
+  "CancelOrderResponse": {
+    "accountId": "5678",
+    "orderId": 1234,
+    "cancelTime": 1234567890000
+  }
+}`,
+			expectErr: false,
+			expectValue: &eTradeCancelOrderResult{
+				orderId:   1234,
+				isSuccess: true,
+				error:     "",
+				jsonMap: jsonmap.JsonMap{
+					"accountId":  "5678",
+					"orderId":    json.Number("1234"),
+					"cancelTime": json.Number("1234567890000"),
+				},
+			},
+		},
+		{
+			name: "Fails Without Order ID",
+			testJson: `
+{
+  "CancelOrderResponse": {
+    "accountId": "5678"
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Cancel Order Response",
+			testJson: `
+{
+  "MISSING": {
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails On Bad JSON",
+			testJson: `
+{
+  "CancelOrderResponse": {
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := CreateETradeCancelOrderResultFromResponse([]byte(tt.testJson))
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestETradeCancelOrderResult_AsJsonMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		testResult  ETradeCancelOrderResult
+		expectValue jsonmap.JsonMap
+	}{
+		{
+			name: "Successful Cancellation",
+			testResult: &eTradeCancelOrderResult{
+				orderId:   1234,
+				isSuccess: true,
+				error:     "",
+				jsonMap: jsonmap.JsonMap{
+					"accountId": "5678",
+					"orderId":   json.Number("1234"),
+				},
+			},
+			expectValue: jsonmap.JsonMap{
+				"status":    "success",
+				"accountId": "5678",
+				"orderId":   int64(1234),
+			},
+		},
+		{
+			name:       "Rejected Cancellation",
+			testResult: CreateETradeCancelOrderResultFromError(1234, errors.New("test error")),
+			expectValue: jsonmap.JsonMap{
+				"status":  "error",
+				"orderId": int64(1234),
+				"error":   "test error",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := tt.testResult.AsJsonMap()
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
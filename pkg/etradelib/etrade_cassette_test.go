@@ -0,0 +1,130 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// cassetteParsers map the requests recorded in a cassette to the functions
+// that parse their responses.
+var cassetteParsers = []struct {
+	method string
+	path   *regexp.Regexp
+	parse  func(response []byte) error
+}{
+	{"GET", regexp.MustCompile(`^/v1/accounts/list$`), func(r []byte) error {
+		_, err := CreateETradeAccountListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/accounts/[^/]+/balance$`), func(r []byte) error {
+		_, err := CreateETradeBalancesFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/accounts/[^/]+/transactions$`), func(r []byte) error {
+		_, err := CreateETradeTransactionListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/accounts/[^/]+/transactions/[^/]+$`), func(r []byte) error {
+		_, err := CreateETradeTransactionDetailsFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/accounts/[^/]+/portfolio$`), func(r []byte) error {
+		_, err := CreateETradePositionListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/accounts/[^/]+/orders$`), func(r []byte) error {
+		_, err := CreateETradeOrderListFromResponse(r)
+		return err
+	}},
+	{"POST", regexp.MustCompile(`^/v1/accounts/[^/]+/orders/preview$`), func(r []byte) error {
+		_, err := CreateETradePreviewOrderFromResponse(r)
+		return err
+	}},
+	{"POST", regexp.MustCompile(`^/v1/accounts/[^/]+/orders/place$`), func(r []byte) error {
+		_, err := CreateETradePlaceOrderFromResponse(r)
+		return err
+	}},
+	{"PUT", regexp.MustCompile(`^/v1/accounts/[^/]+/orders/cancel$`), func(r []byte) error {
+		_, err := CreateETradeCancelOrderResultFromResponse(r)
+		return err
+	}},
+	{"PUT", regexp.MustCompile(`^/v1/accounts/[^/]+/orders/[^/]+/change/preview$`), func(r []byte) error {
+		_, err := CreateETradePreviewOrderFromResponse(r)
+		return err
+	}},
+	{"PUT", regexp.MustCompile(`^/v1/accounts/[^/]+/orders/[^/]+/change/place$`), func(r []byte) error {
+		_, err := CreateETradePlaceOrderFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/user/alerts$`), func(r []byte) error {
+		_, err := CreateETradeAlertListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/user/alerts/[^/]+$`), func(r []byte) error {
+		_, err := CreateETradeAlertDetailsFromResponse(r)
+		return err
+	}},
+	{"DELETE", regexp.MustCompile(`^/v1/user/alerts/[^/]+$`), func(r []byte) error {
+		_, err := CreateETradeDeleteAlertsFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/market/quote/[^/]+$`), func(r []byte) error {
+		_, err := CreateETradeQuoteListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/market/lookup/[^/]+$`), func(r []byte) error {
+		_, err := CreateETradeLookupResultListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/market/optionchains$`), func(r []byte) error {
+		_, err := CreateETradeOptionChainPairListFromResponse(r)
+		return err
+	}},
+	{"GET", regexp.MustCompile(`^/v1/market/optionexpiredate$`), func(r []byte) error {
+		_, err := CreateETradeOptionExpireDateListFromResponse(r)
+		return err
+	}},
+}
+
+// TestCassettes parses every successful response recorded (with the --record
+// flag) in the cassettes under testdata/cassettes. To turn an ETrade response
+// that breaks a parser into a regression test, copy its cassette there.
+func TestCassettes(t *testing.T) {
+	cassetteDirs, err := filepath.Glob(filepath.Join("testdata", "cassettes", "*"))
+	require.Nil(t, err)
+
+	for _, cassetteDir := range cassetteDirs {
+		if info, err := os.Stat(cassetteDir); err != nil || !info.IsDir() {
+			continue
+		}
+		interactions, err := client.LoadCassette(cassetteDir)
+		require.Nil(t, err)
+		for i, interaction := range interactions {
+			if interaction.Response.StatusCode != http.StatusOK {
+				continue
+			}
+			requestUrl, err := url.Parse(interaction.Request.Url)
+			require.Nil(t, err)
+			for _, parser := range cassetteParsers {
+				if parser.method != interaction.Request.Method || !parser.path.MatchString(requestUrl.Path) {
+					continue
+				}
+				t.Run(
+					filepath.Base(cassetteDir)+"/"+interaction.Request.Method+" "+requestUrl.Path, func(t *testing.T) {
+						// Call the Method Under Test
+						err := parser.parse([]byte(interaction.Response.Body))
+						assert.Nil(t, err, "interaction %d", i+1)
+					},
+				)
+				break
+			}
+		}
+	}
+}
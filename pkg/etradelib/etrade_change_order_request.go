@@ -0,0 +1,232 @@
+package etradelib
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+// ETradeOrderChanges describes the edits to make to an open order. Fields
+// left at their zero values keep the order's current value.
+type ETradeOrderChanges struct {
+	Quantity   int64
+	PriceType  constants.OrderPriceType
+	LimitPrice float64
+	StopPrice  float64
+	OrderTerm  constants.OrderTerm
+}
+
+const (
+	// The order JSON (as returned by ListOrders) looks like this:
+	// {
+	//   "orderId": 1234,
+	//   "orderType": "EQ",
+	//   "orderDetail": [
+	//     {
+	//       "status": "OPEN",
+	//       "orderTerm": "GOOD_FOR_DAY",
+	//       "priceType": "LIMIT",
+	//       "limitPrice": 123.45,
+	//       "stopPrice": 0,
+	//       "marketSession": "REGULAR",
+	//       "allOrNone": false,
+	//       "instrument": [
+	//         {
+	//           "product": {
+	//             "securityType": "EQ",
+	//             "symbol": "ABC"
+	//           },
+	//           "orderAction": "BUY",
+	//           "quantityType": "QUANTITY",
+	//           "orderedQuantity": 10,
+	//           <other instrument keys/values>
+	//         }
+	//       ],
+	//       <other order detail keys/values>
+	//     }
+	//   ]
+	// }
+
+	// changeOrderOrderTypeResponseKey is the key for the order type
+	changeOrderOrderTypeResponseKey = "orderType"
+
+	// changeOrderOrderDetailResponsePath is the path to the order detail
+	changeOrderOrderDetailResponsePath = ".orderDetail[0]"
+
+	// changeOrderInstrumentsResponseKey is the key for the slice of
+	// instruments in the order detail
+	changeOrderInstrumentsResponseKey = "instrument"
+
+	// changeOrderProductResponseKey is the key for the product in each
+	// instrument
+	changeOrderProductResponseKey = "product"
+)
+
+// changeOrderProductKeys are the product keys that are carried over from the
+// existing order into the change request. The listed product includes other
+// keys that ETrade does not accept in a request.
+var changeOrderProductKeys = []string{
+	"securityType", "symbol", "callPut", "expiryYear", "expiryMonth", "expiryDay", "strikePrice",
+}
+
+// CreateETradeChangeOrderRequest creates a request that changes an open
+// order. The request starts from the order's current details (as returned by
+// ListOrders) and applies the provided changes. The quantity may only be
+// changed on single-leg orders.
+func CreateETradeChangeOrderRequest(
+	order ETradeOrder, clientOrderId string, changes ETradeOrderChanges,
+) (ETradeOrderRequest, error) {
+	if err := validateClientOrderId(clientOrderId); err != nil {
+		return nil, err
+	}
+	if changes.Quantity < 0 {
+		return nil, fmt.Errorf("quantity %d must be greater than zero", changes.Quantity)
+	}
+
+	orderMap := order.AsJsonMap()
+	orderType, err := orderMap.GetString(changeOrderOrderTypeResponseKey)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := orderMap.GetMapAtPath(changeOrderOrderDetailResponsePath)
+	if err != nil {
+		return nil, err
+	}
+	status, err := detail.GetStringWithDefault("status", "")
+	if err != nil {
+		return nil, err
+	}
+	if status != constants.OrderStatusOpen.String() {
+		return nil, fmt.Errorf("order %d is not open and cannot be changed (status %s)", order.GetId(), status)
+	}
+
+	priceTypeString, err := detail.GetString("priceType")
+	if err != nil {
+		return nil, err
+	}
+	priceType := constants.OrderPriceTypeFromString(priceTypeString)
+	if changes.PriceType != constants.OrderPriceTypeNil {
+		priceType = changes.PriceType
+	}
+	currentLimitPrice, err := detail.GetFloatWithDefault("limitPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	currentStopPrice, err := detail.GetFloatWithDefault("stopPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the current prices only if the (possibly new) price type still
+	// uses them.
+	limitPrice, stopPrice := 0.0, 0.0
+	hasLimitPrice, hasStopPrice, _ := getOrderPriceTypePrices(priceType)
+	if hasLimitPrice {
+		limitPrice = currentLimitPrice
+	}
+	if hasStopPrice {
+		stopPrice = currentStopPrice
+	}
+	if changes.LimitPrice != 0 {
+		limitPrice = changes.LimitPrice
+	}
+	if changes.StopPrice != 0 {
+		stopPrice = changes.StopPrice
+	}
+	needLimitPrice, needStopPrice, err := validateOrderPrices(priceType, limitPrice, stopPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	orderTerm, err := detail.GetString("orderTerm")
+	if err != nil {
+		return nil, err
+	}
+	if changes.OrderTerm != constants.OrderTermNil {
+		orderTerm = changes.OrderTerm.String()
+	}
+	marketSession, err := detail.GetString("marketSession")
+	if err != nil {
+		return nil, err
+	}
+	allOrNone, err := detail.GetBoolWithDefault("allOrNone", false)
+	if err != nil {
+		return nil, err
+	}
+
+	currentInstruments, err := detail.GetSliceOfMaps(changeOrderInstrumentsResponseKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(currentInstruments) < 1 {
+		return nil, fmt.Errorf("order %d has no instruments", order.GetId())
+	}
+	if changes.Quantity != 0 && len(currentInstruments) > 1 {
+		return nil, errors.New("the quantity can only be changed on single-leg orders")
+	}
+	instruments := make(jsonmap.JsonSlice, 0, len(currentInstruments))
+	for _, currentInstrument := range currentInstruments {
+		instrument, err := createChangeOrderInstrument(currentInstrument, changes.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		instruments = append(instruments, instrument)
+	}
+
+	orderDetail := jsonmap.JsonMap{
+		"allOrNone":               allOrNone,
+		"priceType":               priceType.String(),
+		"orderTerm":               orderTerm,
+		"marketSession":           marketSession,
+		OrderRequestInstrumentKey: instruments,
+	}
+	if needLimitPrice {
+		orderDetail["limitPrice"] = limitPrice
+	}
+	if needStopPrice {
+		orderDetail["stopPrice"] = stopPrice
+	}
+
+	return &eTradeOrderRequest{
+		clientOrderId: clientOrderId,
+		jsonMap: jsonmap.JsonMap{
+			OrderRequestOrderTypeKey:     orderType,
+			OrderRequestClientOrderIdKey: clientOrderId,
+			OrderRequestOrderKey:         jsonmap.JsonSlice{orderDetail},
+		},
+	}, nil
+}
+
+func createChangeOrderInstrument(currentInstrument jsonmap.JsonMap, newQuantity int64) (jsonmap.JsonMap, error) {
+	currentProduct, err := currentInstrument.GetMap(changeOrderProductResponseKey)
+	if err != nil {
+		return nil, err
+	}
+	product := jsonmap.JsonMap{}
+	for _, key := range changeOrderProductKeys {
+		if value, found := currentProduct[key]; found {
+			product[key] = value
+		}
+	}
+	orderAction, err := currentInstrument.GetString("orderAction")
+	if err != nil {
+		return nil, err
+	}
+	quantityType, err := currentInstrument.GetStringWithDefault("quantityType", "QUANTITY")
+	if err != nil {
+		return nil, err
+	}
+	quantity := newQuantity
+	if quantity == 0 {
+		quantity, err = currentInstrument.GetInt("orderedQuantity")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jsonmap.JsonMap{
+		OrderRequestProductKey: product,
+		"orderAction":          orderAction,
+		"quantityType":         quantityType,
+		"quantity":             quantity,
+	}, nil
+}
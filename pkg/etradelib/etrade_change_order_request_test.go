@@ -0,0 +1,279 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreateETradeChangeOrderRequest(t *testing.T) {
+	testOrderJson := `
+{
+  "orderId": 1234,
+  "orderType": "EQ",
+  "orderDetail": [
+    {
+      "status": "OPEN",
+      "orderTerm": "GOOD_FOR_DAY",
+      "priceType": "LIMIT",
+      "limitPrice": 100.5,
+      "stopPrice": 0,
+      "marketSession": "REGULAR",
+      "allOrNone": false,
+      "instrument": [
+        {
+          "product": {
+            "securityType": "EQ",
+            "symbol": "ABC",
+            "productId": {}
+          },
+          "orderAction": "BUY",
+          "quantityType": "QUANTITY",
+          "orderedQuantity": 10,
+          "filledQuantity": 0
+        }
+      ]
+    }
+  ]
+}`
+	testMultiLegOrderJson := `
+{
+  "orderId": 1234,
+  "orderType": "SPREADS",
+  "orderDetail": [
+    {
+      "status": "OPEN",
+      "orderTerm": "GOOD_FOR_DAY",
+      "priceType": "NET_DEBIT",
+      "limitPrice": 1.25,
+      "marketSession": "REGULAR",
+      "instrument": [
+        {
+          "product": {"securityType": "OPTN", "symbol": "ABC"},
+          "orderAction": "BUY_OPEN",
+          "orderedQuantity": 1
+        },
+        {
+          "product": {"securityType": "OPTN", "symbol": "ABC"},
+          "orderAction": "SELL_OPEN",
+          "orderedQuantity": 1
+        }
+      ]
+    }
+  ]
+}`
+	testExecutedOrderJson := `
+{
+  "orderId": 1234,
+  "orderType": "EQ",
+  "orderDetail": [
+    {
+      "status": "EXECUTED",
+      "orderTerm": "GOOD_FOR_DAY",
+      "priceType": "MARKET",
+      "marketSession": "REGULAR",
+      "instrument": []
+    }
+  ]
+}`
+	expectedInstrument := func(quantity int64) jsonmap.JsonSlice {
+		return jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				"Product": jsonmap.JsonMap{
+					"securityType": "EQ",
+					"symbol":       "ABC",
+				},
+				"orderAction":  "BUY",
+				"quantityType": "QUANTITY",
+				"quantity":     quantity,
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		orderJson     string
+		clientOrderId string
+		changes       ETradeOrderChanges
+		expectErr     bool
+		expectValue   jsonmap.JsonMap
+	}{
+		{
+			name:          "Changes Limit Price",
+			orderJson:     testOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{LimitPrice: 99.25},
+			expectErr:     false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "EQ",
+				"clientOrderId": "TestId",
+				"Order": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"allOrNone":     false,
+						"priceType":     "LIMIT",
+						"orderTerm":     "GOOD_FOR_DAY",
+						"marketSession": "REGULAR",
+						"limitPrice":    99.25,
+						"Instrument":    expectedInstrument(10),
+					},
+				},
+			},
+		},
+		{
+			name:          "Keeps Current Values When Unchanged",
+			orderJson:     testOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{},
+			expectErr:     false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "EQ",
+				"clientOrderId": "TestId",
+				"Order": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"allOrNone":     false,
+						"priceType":     "LIMIT",
+						"orderTerm":     "GOOD_FOR_DAY",
+						"marketSession": "REGULAR",
+						"limitPrice":    100.5,
+						"Instrument":    expectedInstrument(10),
+					},
+				},
+			},
+		},
+		{
+			name:          "Changes Price Type, Quantity, And Term",
+			orderJson:     testOrderJson,
+			clientOrderId: "TestId",
+			changes: ETradeOrderChanges{
+				Quantity:  20,
+				PriceType: constants.OrderPriceTypeStopLimit,
+				StopPrice: 101,
+				OrderTerm: constants.OrderTermGoodUntilCancel,
+			},
+			expectErr: false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "EQ",
+				"clientOrderId": "TestId",
+				"Order": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"allOrNone":     false,
+						"priceType":     "STOP_LIMIT",
+						"orderTerm":     "GOOD_UNTIL_CANCEL",
+						"marketSession": "REGULAR",
+						"limitPrice":    100.5,
+						"stopPrice":     float64(101),
+						"Instrument":    expectedInstrument(20),
+					},
+				},
+			},
+		},
+		{
+			name:          "Changes To Market Order Drops Limit Price",
+			orderJson:     testOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{PriceType: constants.OrderPriceTypeMarket},
+			expectErr:     false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "EQ",
+				"clientOrderId": "TestId",
+				"Order": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"allOrNone":     false,
+						"priceType":     "MARKET",
+						"orderTerm":     "GOOD_FOR_DAY",
+						"marketSession": "REGULAR",
+						"Instrument":    expectedInstrument(10),
+					},
+				},
+			},
+		},
+		{
+			name:          "Changes Term Of Multi-Leg Order",
+			orderJson:     testMultiLegOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{OrderTerm: constants.OrderTermGoodUntilCancel},
+			expectErr:     false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "SPREADS",
+				"clientOrderId": "TestId",
+				"Order": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"allOrNone":     false,
+						"priceType":     "NET_DEBIT",
+						"orderTerm":     "GOOD_UNTIL_CANCEL",
+						"marketSession": "REGULAR",
+						"limitPrice":    1.25,
+						"Instrument": jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"Product":      jsonmap.JsonMap{"securityType": "OPTN", "symbol": "ABC"},
+								"orderAction":  "BUY_OPEN",
+								"quantityType": "QUANTITY",
+								"quantity":     int64(1),
+							},
+							jsonmap.JsonMap{
+								"Product":      jsonmap.JsonMap{"securityType": "OPTN", "symbol": "ABC"},
+								"orderAction":  "SELL_OPEN",
+								"quantityType": "QUANTITY",
+								"quantity":     int64(1),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:          "Fails Without Required Stop Price",
+			orderJson:     testOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{PriceType: constants.OrderPriceTypeStop},
+			expectErr:     true,
+			expectValue:   nil,
+		},
+		{
+			name:          "Fails To Change Quantity Of Multi-Leg Order",
+			orderJson:     testMultiLegOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{Quantity: 2},
+			expectErr:     true,
+			expectValue:   nil,
+		},
+		{
+			name:          "Fails If Order Is Not Open",
+			orderJson:     testExecutedOrderJson,
+			clientOrderId: "TestId",
+			changes:       ETradeOrderChanges{},
+			expectErr:     true,
+			expectValue:   nil,
+		},
+		{
+			name:          "Fails Without Client Order ID",
+			orderJson:     testOrderJson,
+			clientOrderId: "",
+			changes:       ETradeOrderChanges{},
+			expectErr:     true,
+			expectValue:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				orderMap, err := jsonmap.NewJsonMapFromJsonString(tt.orderJson)
+				require.Nil(t, err)
+				order, err := CreateETradeOrder(orderMap)
+				require.Nil(t, err)
+				// Call the Method Under Test
+				actualValue, err := CreateETradeChangeOrderRequest(order, tt.clientOrderId, tt.changes)
+				if tt.expectErr {
+					assert.Error(t, err)
+					assert.Nil(t, actualValue)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue.AsJsonMap())
+				}
+			},
+		)
+	}
+}
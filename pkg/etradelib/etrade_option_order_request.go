@@ -0,0 +1,393 @@
+package etradelib
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OptionOrderMaxLegs is the maximum number of legs in a multi-leg option
+// order.
+const OptionOrderMaxLegs = 4
+
+// ETradeOptionContract identifies a single option contract.
+type ETradeOptionContract struct {
+	Underlying  string
+	CallPut     constants.OptionType
+	ExpiryDate  time.Time
+	StrikePrice float64
+}
+
+// ETradeOptionLeg is one leg of an option order.
+type ETradeOptionLeg struct {
+	Contract    ETradeOptionContract
+	OrderAction constants.OrderAction
+	Quantity    int64
+}
+
+// osiKeySuffixLength is the length of the part of an OSI key that follows
+// the root symbol: a YYMMDD expiration date, a C or P, and the strike price
+// in thousandths of a dollar as 8 digits.
+const osiKeySuffixLength = 15
+
+// ParseOsiKey parses an Options Symbology Initiative key (such as the osiKey
+// values in an ETradeOptionChainPair). ETrade pads the root symbol with
+// dashes rather than the spaces used by the OSI standard; both are accepted.
+func ParseOsiKey(osiKey string) (ETradeOptionContract, error) {
+	key := strings.TrimSpace(osiKey)
+	if len(key) <= osiKeySuffixLength {
+		return ETradeOptionContract{}, fmt.Errorf("'%s' is not a valid OSI key", osiKey)
+	}
+	root := strings.TrimRight(key[:len(key)-osiKeySuffixLength], " -")
+	suffix := key[len(key)-osiKeySuffixLength:]
+	if root == "" || len(root) > 6 {
+		return ETradeOptionContract{}, fmt.Errorf("'%s' is not a valid OSI key (bad root symbol)", osiKey)
+	}
+	expiryDate, err := time.Parse("060102", suffix[:6])
+	if err != nil {
+		return ETradeOptionContract{}, fmt.Errorf("'%s' is not a valid OSI key (bad expiration date)", osiKey)
+	}
+	var callPut constants.OptionType
+	switch suffix[6] {
+	case 'C':
+		callPut = constants.OptionTypeCall
+	case 'P':
+		callPut = constants.OptionTypePut
+	default:
+		return ETradeOptionContract{}, fmt.Errorf("'%s' is not a valid OSI key (bad option type)", osiKey)
+	}
+	strikeThousandths, err := strconv.ParseUint(suffix[7:], 10, 64)
+	if err != nil {
+		return ETradeOptionContract{}, fmt.Errorf("'%s' is not a valid OSI key (bad strike price)", osiKey)
+	}
+	return ETradeOptionContract{
+		Underlying:  root,
+		CallPut:     callPut,
+		ExpiryDate:  expiryDate,
+		StrikePrice: float64(strikeThousandths) / 1000,
+	}, nil
+}
+
+// GetOsiKey formats the contract as an Options Symbology Initiative key, with
+// the root symbol padded with dashes as ETrade does.
+func (c ETradeOptionContract) GetOsiKey() string {
+	callPut := "C"
+	if c.CallPut == constants.OptionTypePut {
+		callPut = "P"
+	}
+	root := c.Underlying
+	if len(root) < 6 {
+		root += strings.Repeat("-", 6-len(root))
+	}
+	return fmt.Sprintf("%s%s%s%08d", root, c.ExpiryDate.Format("060102"), callPut, c.getStrikeThousandths())
+}
+
+// getStrikeThousandths returns the strike price in thousandths of a dollar,
+// so that strikes can be compared without floating-point rounding errors.
+func (c ETradeOptionContract) getStrikeThousandths() int64 {
+	return int64(math.Round(c.StrikePrice * 1000))
+}
+
+// CreateETradeOptionOrderRequest creates a request for a single-leg or
+// multi-leg option order. Use OrderTypeOption for a single leg; multi-leg
+// orders must use the order type that describes the strategy (e.g.
+// OrderTypeSpreads for verticals, calendars, straddles, and strangles). The
+// legs must share an underlying and their quantities must match the ratios
+// of the strategy.
+func CreateETradeOptionOrderRequest(
+	clientOrderId string, orderType constants.OrderType, legs []ETradeOptionLeg, priceType constants.OrderPriceType,
+	limitPrice float64, stopPrice float64, orderTerm constants.OrderTerm, marketSession constants.MarketSession,
+	allOrNone bool,
+) (ETradeOrderRequest, error) {
+	if err := validateClientOrderId(clientOrderId); err != nil {
+		return nil, err
+	}
+	if err := validateOptionLegs(orderType, legs); err != nil {
+		return nil, err
+	}
+	if orderType == constants.OrderTypeOption {
+		switch priceType {
+		case constants.OrderPriceTypeMarket, constants.OrderPriceTypeLimit, constants.OrderPriceTypeStop,
+			constants.OrderPriceTypeStopLimit:
+		default:
+			return nil, fmt.Errorf("price type %s is not valid for a single-leg option order", priceType)
+		}
+	} else {
+		switch priceType {
+		case constants.OrderPriceTypeMarket, constants.OrderPriceTypeNetDebit, constants.OrderPriceTypeNetCredit,
+			constants.OrderPriceTypeNetEven:
+		default:
+			return nil, fmt.Errorf("price type %s is not valid for a multi-leg option order", priceType)
+		}
+	}
+	needLimitPrice, needStopPrice, err := validateOrderPrices(priceType, limitPrice, stopPrice)
+	if err != nil {
+		return nil, err
+	}
+	if orderTerm == constants.OrderTermNil {
+		return nil, errors.New("order term not provided")
+	}
+	if marketSession == constants.MarketSessionNil {
+		return nil, errors.New("market session not provided")
+	}
+
+	instruments := make(jsonmap.JsonSlice, 0, len(legs))
+	for _, leg := range legs {
+		instruments = append(
+			instruments, jsonmap.JsonMap{
+				OrderRequestProductKey: jsonmap.JsonMap{
+					"securityType": constants.OrderSecurityTypeOption.String(),
+					"symbol":       leg.Contract.Underlying,
+					"callPut":      leg.Contract.CallPut.String(),
+					"expiryYear":   int64(leg.Contract.ExpiryDate.Year()),
+					"expiryMonth":  int64(leg.Contract.ExpiryDate.Month()),
+					"expiryDay":    int64(leg.Contract.ExpiryDate.Day()),
+					"strikePrice":  leg.Contract.StrikePrice,
+				},
+				"orderAction":  leg.OrderAction.String(),
+				"quantityType": "QUANTITY",
+				"quantity":     leg.Quantity,
+			},
+		)
+	}
+	orderDetail := jsonmap.JsonMap{
+		"allOrNone":               allOrNone,
+		"priceType":               priceType.String(),
+		"orderTerm":               orderTerm.String(),
+		"marketSession":           marketSession.String(),
+		OrderRequestInstrumentKey: instruments,
+	}
+	if needLimitPrice {
+		orderDetail["limitPrice"] = limitPrice
+	}
+	if needStopPrice {
+		orderDetail["stopPrice"] = stopPrice
+	}
+
+	return &eTradeOrderRequest{
+		clientOrderId: clientOrderId,
+		jsonMap: jsonmap.JsonMap{
+			OrderRequestOrderTypeKey:     orderType.String(),
+			OrderRequestClientOrderIdKey: clientOrderId,
+			OrderRequestOrderKey:         jsonmap.JsonSlice{orderDetail},
+		},
+	}, nil
+}
+
+// InferOptionOrderType returns the order type that best describes a set of
+// option legs. It does not validate the legs; CreateETradeOptionOrderRequest
+// does that.
+func InferOptionOrderType(legs []ETradeOptionLeg) constants.OrderType {
+	switch len(legs) {
+	case 1:
+		return constants.OrderTypeOption
+	case 2:
+		return constants.OrderTypeSpreads
+	case 3:
+		return constants.OrderTypeButterfly
+	case 4:
+		calls := 0
+		for _, leg := range legs {
+			if leg.Contract.CallPut == constants.OptionTypeCall {
+				calls++
+			}
+		}
+		if calls == 0 || calls == 4 {
+			return constants.OrderTypeCondor
+		}
+		sorted := sortLegsByStrike(legs)
+		if sorted[1].Contract.StrikePrice == sorted[2].Contract.StrikePrice {
+			return constants.OrderTypeIronButterfly
+		}
+		return constants.OrderTypeIronCondor
+	default:
+		return constants.OrderTypeNil
+	}
+}
+
+func validateOptionLegs(orderType constants.OrderType, legs []ETradeOptionLeg) error {
+	if len(legs) < 1 {
+		return errors.New("no option legs provided")
+	}
+	if len(legs) > OptionOrderMaxLegs {
+		return fmt.Errorf("%d legs exceeds the maximum of %d legs in an order", len(legs), OptionOrderMaxLegs)
+	}
+	underlying := legs[0].Contract.Underlying
+	for i, leg := range legs {
+		if leg.Contract.Underlying == "" {
+			return fmt.Errorf("leg %d has no underlying symbol", i+1)
+		}
+		if leg.Contract.Underlying != underlying {
+			return fmt.Errorf(
+				"all legs must share an underlying (leg %d is %s, not %s)", i+1, leg.Contract.Underlying, underlying,
+			)
+		}
+		if leg.Contract.CallPut != constants.OptionTypeCall && leg.Contract.CallPut != constants.OptionTypePut {
+			return fmt.Errorf("leg %d is neither a call nor a put", i+1)
+		}
+		if leg.Contract.StrikePrice <= 0 {
+			return fmt.Errorf("leg %d has no strike price", i+1)
+		}
+		if leg.Contract.ExpiryDate.IsZero() {
+			return fmt.Errorf("leg %d has no expiration date", i+1)
+		}
+		switch leg.OrderAction {
+		case constants.OrderActionBuyOpen, constants.OrderActionSellOpen, constants.OrderActionBuyClose,
+			constants.OrderActionSellClose:
+		default:
+			return fmt.Errorf("order action %s is not valid for an option leg", leg.OrderAction)
+		}
+		if leg.Quantity <= 0 {
+			return fmt.Errorf("leg %d quantity %d must be greater than zero", i+1, leg.Quantity)
+		}
+		for j := 0; j < i; j++ {
+			if legs[j].Contract == leg.Contract {
+				return fmt.Errorf("legs %d and %d are the same contract", j+1, i+1)
+			}
+		}
+	}
+
+	switch orderType {
+	case constants.OrderTypeOption:
+		if len(legs) != 1 {
+			return fmt.Errorf("a single-leg option order must have 1 leg, not %d", len(legs))
+		}
+	case constants.OrderTypeSpreads:
+		if len(legs) != 2 {
+			return fmt.Errorf("a spread must have 2 legs, not %d", len(legs))
+		}
+		if legs[0].Quantity != legs[1].Quantity {
+			return errors.New("both legs of a spread must have the same quantity")
+		}
+	case constants.OrderTypeButterfly:
+		return validateButterflyLegs(legs)
+	case constants.OrderTypeCondor:
+		return validateCondorLegs(legs)
+	case constants.OrderTypeIronButterfly, constants.OrderTypeIronCondor:
+		return validateIronLegs(orderType, legs)
+	default:
+		return fmt.Errorf("order type %s is not supported for option orders", orderType)
+	}
+	return nil
+}
+
+func validateButterflyLegs(legs []ETradeOptionLeg) error {
+	if len(legs) != 3 {
+		return fmt.Errorf("a butterfly must have 3 legs, not %d", len(legs))
+	}
+	if err := validateSameTypeAndExpiry(legs); err != nil {
+		return err
+	}
+	sorted := sortLegsByStrike(legs)
+	lower, middle, upper := sorted[0], sorted[1], sorted[2]
+	lowerStrike := lower.Contract.getStrikeThousandths()
+	middleStrike := middle.Contract.getStrikeThousandths()
+	upperStrike := upper.Contract.getStrikeThousandths()
+	if lowerStrike == middleStrike || middleStrike == upperStrike {
+		return errors.New("the strikes of a butterfly must all differ")
+	}
+	if middleStrike-lowerStrike != upperStrike-middleStrike {
+		return errors.New("the wings of a butterfly must be the same width")
+	}
+	if lower.Quantity != upper.Quantity || middle.Quantity != 2*lower.Quantity {
+		return errors.New("the quantities of a butterfly must be in a 1:2:1 ratio")
+	}
+	if isBuyAction(lower.OrderAction) != isBuyAction(upper.OrderAction) ||
+		isBuyAction(lower.OrderAction) == isBuyAction(middle.OrderAction) {
+		return errors.New("the body of a butterfly must be traded opposite its wings")
+	}
+	return nil
+}
+
+func validateCondorLegs(legs []ETradeOptionLeg) error {
+	if len(legs) != 4 {
+		return fmt.Errorf("a condor must have 4 legs, not %d", len(legs))
+	}
+	if err := validateSameTypeAndExpiry(legs); err != nil {
+		return err
+	}
+	return validateFourLegStructure(sortLegsByStrike(legs), "condor")
+}
+
+func validateIronLegs(orderType constants.OrderType, legs []ETradeOptionLeg) error {
+	name := "iron condor"
+	if orderType == constants.OrderTypeIronButterfly {
+		name = "iron butterfly"
+	}
+	if len(legs) != 4 {
+		return fmt.Errorf("an %s must have 4 legs, not %d", name, len(legs))
+	}
+	for _, leg := range legs[1:] {
+		if !leg.Contract.ExpiryDate.Equal(legs[0].Contract.ExpiryDate) {
+			return fmt.Errorf("all legs of an %s must have the same expiration date", name)
+		}
+	}
+	sorted := sortLegsByStrike(legs)
+	if sorted[0].Contract.CallPut != constants.OptionTypePut || sorted[1].Contract.CallPut != constants.OptionTypePut ||
+		sorted[2].Contract.CallPut != constants.OptionTypeCall || sorted[3].Contract.CallPut != constants.OptionTypeCall {
+		return fmt.Errorf("an %s must have two puts with strikes at or below two calls", name)
+	}
+	innerStrikesMatch := sorted[1].Contract.StrikePrice == sorted[2].Contract.StrikePrice
+	if orderType == constants.OrderTypeIronButterfly && !innerStrikesMatch {
+		return errors.New("the inner put and call of an iron butterfly must have the same strike")
+	}
+	if orderType == constants.OrderTypeIronCondor && innerStrikesMatch {
+		return errors.New("the inner put and call of an iron condor must have different strikes")
+	}
+	return validateFourLegStructure(sorted, name)
+}
+
+// validateFourLegStructure checks that four legs (sorted by strike) have equal
+// quantities and that the outer legs are traded opposite the inner legs.
+func validateFourLegStructure(sorted []ETradeOptionLeg, name string) error {
+	for _, leg := range sorted[1:] {
+		if leg.Quantity != sorted[0].Quantity {
+			return fmt.Errorf("all legs of a %s must have the same quantity", name)
+		}
+	}
+	outerIsBuy := isBuyAction(sorted[0].OrderAction)
+	if isBuyAction(sorted[3].OrderAction) != outerIsBuy || isBuyAction(sorted[1].OrderAction) == outerIsBuy ||
+		isBuyAction(sorted[2].OrderAction) == outerIsBuy {
+		return fmt.Errorf("the inner legs of a %s must be traded opposite its outer legs", name)
+	}
+	return nil
+}
+
+func validateSameTypeAndExpiry(legs []ETradeOptionLeg) error {
+	for _, leg := range legs[1:] {
+		if leg.Contract.CallPut != legs[0].Contract.CallPut {
+			return errors.New("all legs must be calls or all legs must be puts")
+		}
+		if !leg.Contract.ExpiryDate.Equal(legs[0].Contract.ExpiryDate) {
+			return errors.New("all legs must have the same expiration date")
+		}
+	}
+	return nil
+}
+
+func sortLegsByStrike(legs []ETradeOptionLeg) []ETradeOptionLeg {
+	sorted := make([]ETradeOptionLeg, len(legs))
+	copy(sorted, legs)
+	sort.SliceStable(
+		sorted, func(i, j int) bool {
+			if sorted[i].Contract.StrikePrice != sorted[j].Contract.StrikePrice {
+				return sorted[i].Contract.StrikePrice < sorted[j].Contract.StrikePrice
+			}
+			// Puts sort before calls at the same strike (as in an iron
+			// butterfly).
+			return sorted[i].Contract.CallPut == constants.OptionTypePut &&
+				sorted[j].Contract.CallPut == constants.OptionTypeCall
+		},
+	)
+	return sorted
+}
+
+func isBuyAction(action constants.OrderAction) bool {
+	return action == constants.OrderActionBuyOpen || action == constants.OrderActionBuyClose
+}
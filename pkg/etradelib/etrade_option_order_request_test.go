@@ -0,0 +1,362 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testOptionContract(callPut constants.OptionType, strikePrice float64) ETradeOptionContract {
+	return ETradeOptionContract{
+		Underlying:  "ABC",
+		CallPut:     callPut,
+		ExpiryDate:  time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+		StrikePrice: strikePrice,
+	}
+}
+
+func TestParseOsiKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		osiKey      string
+		expectErr   bool
+		expectValue ETradeOptionContract
+	}{
+		{
+			name:        "Parses ETrade OSI Key",
+			osiKey:      "ABC---240119C00150000",
+			expectErr:   false,
+			expectValue: testOptionContract(constants.OptionTypeCall, 150),
+		},
+		{
+			name:        "Parses Standard OSI Key",
+			osiKey:      "ABC   240119P00152500",
+			expectErr:   false,
+			expectValue: testOptionContract(constants.OptionTypePut, 152.5),
+		},
+		{
+			name:        "Parses Unpadded OSI Key",
+			osiKey:      "ABC240119P00000500",
+			expectErr:   false,
+			expectValue: testOptionContract(constants.OptionTypePut, 0.5),
+		},
+		{
+			name:      "Fails On Missing Root Symbol",
+			osiKey:    "240119C00150000",
+			expectErr: true,
+		},
+		{
+			name:      "Fails On Bad Date",
+			osiKey:    "ABC---241319C00150000",
+			expectErr: true,
+		},
+		{
+			name:      "Fails On Bad Option Type",
+			osiKey:    "ABC---240119X00150000",
+			expectErr: true,
+		},
+		{
+			name:      "Fails On Bad Strike",
+			osiKey:    "ABC---240119C0015000X",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := ParseOsiKey(tt.osiKey)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
+
+func TestETradeOptionContract_GetOsiKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		contract    ETradeOptionContract
+		expectValue string
+	}{
+		{
+			name:        "Formats Call",
+			contract:    testOptionContract(constants.OptionTypeCall, 150),
+			expectValue: "ABC---240119C00150000",
+		},
+		{
+			name:        "Formats Put With Fractional Strike",
+			contract:    testOptionContract(constants.OptionTypePut, 152.5),
+			expectValue: "ABC---240119P00152500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := tt.contract.GetOsiKey()
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestCreateETradeOptionOrderRequest(t *testing.T) {
+	call150 := testOptionContract(constants.OptionTypeCall, 150)
+	call155 := testOptionContract(constants.OptionTypeCall, 155)
+	otherUnderlying := call155
+	otherUnderlying.Underlying = "XYZ"
+
+	tests := []struct {
+		name        string
+		orderType   constants.OrderType
+		legs        []ETradeOptionLeg
+		priceType   constants.OrderPriceType
+		limitPrice  float64
+		expectErr   bool
+		expectValue jsonmap.JsonMap
+	}{
+		{
+			name:      "Creates Vertical Spread Request",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 2},
+				{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 2},
+			},
+			priceType:  constants.OrderPriceTypeNetDebit,
+			limitPrice: 1.25,
+			expectErr:  false,
+			expectValue: jsonmap.JsonMap{
+				"orderType":     "SPREADS",
+				"clientOrderId": "TestId",
+				"Order": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"allOrNone":     false,
+						"priceType":     "NET_DEBIT",
+						"orderTerm":     "GOOD_FOR_DAY",
+						"marketSession": "REGULAR",
+						"limitPrice":    1.25,
+						"Instrument": jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"Product": jsonmap.JsonMap{
+									"securityType": "OPTN",
+									"symbol":       "ABC",
+									"callPut":      "CALL",
+									"expiryYear":   int64(2024),
+									"expiryMonth":  int64(1),
+									"expiryDay":    int64(19),
+									"strikePrice":  float64(150),
+								},
+								"orderAction":  "BUY_OPEN",
+								"quantityType": "QUANTITY",
+								"quantity":     int64(2),
+							},
+							jsonmap.JsonMap{
+								"Product": jsonmap.JsonMap{
+									"securityType": "OPTN",
+									"symbol":       "ABC",
+									"callPut":      "CALL",
+									"expiryYear":   int64(2024),
+									"expiryMonth":  int64(1),
+									"expiryDay":    int64(19),
+									"strikePrice":  float64(155),
+								},
+								"orderAction":  "SELL_OPEN",
+								"quantityType": "QUANTITY",
+								"quantity":     int64(2),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "Fails If Legs Do Not Share An Underlying",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				{Contract: otherUnderlying, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+			},
+			priceType:  constants.OrderPriceTypeNetDebit,
+			limitPrice: 1.25,
+			expectErr:  true,
+		},
+		{
+			name:      "Fails If Spread Quantities Differ",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 2},
+			},
+			priceType:  constants.OrderPriceTypeNetDebit,
+			limitPrice: 1.25,
+			expectErr:  true,
+		},
+		{
+			name:      "Fails With Equity Order Action",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuy, Quantity: 1},
+				{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+			},
+			priceType:  constants.OrderPriceTypeNetDebit,
+			limitPrice: 1.25,
+			expectErr:  true,
+		},
+		{
+			name:      "Fails With Single-Leg Price Type For Spread",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+			},
+			priceType:  constants.OrderPriceTypeLimit,
+			limitPrice: 1.25,
+			expectErr:  true,
+		},
+		{
+			name:      "Fails Without Net Debit Limit Price",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+			},
+			priceType: constants.OrderPriceTypeNetDebit,
+			expectErr: true,
+		},
+		{
+			name:      "Fails With Wrong Leg Count For Order Type",
+			orderType: constants.OrderTypeButterfly,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+			},
+			priceType:  constants.OrderPriceTypeNetDebit,
+			limitPrice: 1.25,
+			expectErr:  true,
+		},
+		{
+			name:      "Fails With Duplicate Legs",
+			orderType: constants.OrderTypeSpreads,
+			legs: []ETradeOptionLeg{
+				{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				{Contract: call150, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+			},
+			priceType: constants.OrderPriceTypeNetEven,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := CreateETradeOptionOrderRequest(
+					"TestId", tt.orderType, tt.legs, tt.priceType, tt.limitPrice, 0, constants.OrderTermGoodForDay,
+					constants.MarketSessionRegular, false,
+				)
+				if tt.expectErr {
+					assert.Error(t, err)
+					assert.Nil(t, actualValue)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue.AsJsonMap())
+				}
+			},
+		)
+	}
+}
+
+func TestInferOptionOrderType(t *testing.T) {
+	put140 := testOptionContract(constants.OptionTypePut, 140)
+	put145 := testOptionContract(constants.OptionTypePut, 145)
+	put150 := testOptionContract(constants.OptionTypePut, 150)
+	call150 := testOptionContract(constants.OptionTypeCall, 150)
+	call155 := testOptionContract(constants.OptionTypeCall, 155)
+	call160 := testOptionContract(constants.OptionTypeCall, 160)
+	call165 := testOptionContract(constants.OptionTypeCall, 165)
+	legs := func(contracts ...ETradeOptionContract) []ETradeOptionLeg {
+		result := make([]ETradeOptionLeg, 0, len(contracts))
+		for _, contract := range contracts {
+			result = append(result, ETradeOptionLeg{Contract: contract})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name        string
+		legs        []ETradeOptionLeg
+		expectValue constants.OrderType
+	}{
+		{"Single Option", legs(call150), constants.OrderTypeOption},
+		{"Spread", legs(call150, call155), constants.OrderTypeSpreads},
+		{"Butterfly", legs(call150, call155, call160), constants.OrderTypeButterfly},
+		{"Condor", legs(call150, call155, call160, call165), constants.OrderTypeCondor},
+		{"Iron Condor", legs(put140, put145, call155, call160), constants.OrderTypeIronCondor},
+		{"Iron Butterfly", legs(put140, put150, call150, call160), constants.OrderTypeIronButterfly},
+		{"Too Many Legs", legs(put140, put145, put150, call150, call155), constants.OrderTypeNil},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue := InferOptionOrderType(tt.legs)
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestValidateButterflyLegs(t *testing.T) {
+	butterfly := func(strikes ...float64) []ETradeOptionLeg {
+		return []ETradeOptionLeg{
+			{
+				Contract:    testOptionContract(constants.OptionTypeCall, strikes[0]),
+				OrderAction: constants.OrderActionBuyOpen, Quantity: 1,
+			},
+			{
+				Contract:    testOptionContract(constants.OptionTypeCall, strikes[1]),
+				OrderAction: constants.OrderActionSellOpen, Quantity: 2,
+			},
+			{
+				Contract:    testOptionContract(constants.OptionTypeCall, strikes[2]),
+				OrderAction: constants.OrderActionBuyOpen, Quantity: 1,
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		legs      []ETradeOptionLeg
+		expectErr bool
+	}{
+		{"Allows Whole Strikes", butterfly(150, 155, 160), false},
+		{"Allows Half-Dollar Strikes", butterfly(2.5, 5, 7.5), false},
+		{"Allows Strikes With Inexact Differences", butterfly(0.1, 0.2, 0.3), false},
+		{"Fails With Uneven Wings", butterfly(150, 155, 165), true},
+		{"Fails With Equal Strikes", butterfly(150, 150, 150), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				err := validateButterflyLegs(tt.legs)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,168 @@
+package etradelib
+
+import (
+	"errors"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+)
+
+// ETradeOptionStrategy is a set of option legs along with the order type that
+// ETrade uses for them. Pass its fields to CreateETradeOptionOrderRequest to
+// create an order.
+type ETradeOptionStrategy struct {
+	OrderType constants.OrderType
+	Legs      []ETradeOptionLeg
+}
+
+// CreateVerticalSpread buys one contract and sells another of the same type
+// and expiration at a different strike.
+func CreateVerticalSpread(long ETradeOptionContract, short ETradeOptionContract, quantity int64, opening bool) (
+	ETradeOptionStrategy, error,
+) {
+	if long.CallPut != short.CallPut {
+		return ETradeOptionStrategy{}, errors.New("both legs of a vertical spread must be calls or puts")
+	}
+	if !long.ExpiryDate.Equal(short.ExpiryDate) {
+		return ETradeOptionStrategy{}, errors.New("both legs of a vertical spread must have the same expiration date")
+	}
+	if long.StrikePrice == short.StrikePrice {
+		return ETradeOptionStrategy{}, errors.New("the legs of a vertical spread must have different strikes")
+	}
+	return newOptionStrategy(
+		constants.OrderTypeSpreads,
+		newOptionLeg(long, true, opening, quantity),
+		newOptionLeg(short, false, opening, quantity),
+	)
+}
+
+// CreateCalendarSpread buys one contract and sells another of the same type
+// and strike at a different expiration.
+func CreateCalendarSpread(long ETradeOptionContract, short ETradeOptionContract, quantity int64, opening bool) (
+	ETradeOptionStrategy, error,
+) {
+	if long.CallPut != short.CallPut {
+		return ETradeOptionStrategy{}, errors.New("both legs of a calendar spread must be calls or puts")
+	}
+	if long.StrikePrice != short.StrikePrice {
+		return ETradeOptionStrategy{}, errors.New("both legs of a calendar spread must have the same strike")
+	}
+	if long.ExpiryDate.Equal(short.ExpiryDate) {
+		return ETradeOptionStrategy{}, errors.New(
+			"the legs of a calendar spread must have different expiration dates",
+		)
+	}
+	return newOptionStrategy(
+		constants.OrderTypeSpreads,
+		newOptionLeg(long, true, opening, quantity),
+		newOptionLeg(short, false, opening, quantity),
+	)
+}
+
+// CreateStraddle buys (or sells) a call and a put with the same strike and
+// expiration.
+func CreateStraddle(call ETradeOptionContract, put ETradeOptionContract, quantity int64, buy bool, opening bool) (
+	ETradeOptionStrategy, error,
+) {
+	if err := validateCallAndPut(call, put); err != nil {
+		return ETradeOptionStrategy{}, err
+	}
+	if call.StrikePrice != put.StrikePrice {
+		return ETradeOptionStrategy{}, errors.New("the call and put of a straddle must have the same strike")
+	}
+	return newOptionStrategy(
+		constants.OrderTypeSpreads,
+		newOptionLeg(call, buy, opening, quantity),
+		newOptionLeg(put, buy, opening, quantity),
+	)
+}
+
+// CreateStrangle buys (or sells) an out-of-the-money call and put with the
+// same expiration. The call strike must be above the put strike.
+func CreateStrangle(call ETradeOptionContract, put ETradeOptionContract, quantity int64, buy bool, opening bool) (
+	ETradeOptionStrategy, error,
+) {
+	if err := validateCallAndPut(call, put); err != nil {
+		return ETradeOptionStrategy{}, err
+	}
+	if call.StrikePrice <= put.StrikePrice {
+		return ETradeOptionStrategy{}, errors.New("the call strike of a strangle must be above the put strike")
+	}
+	return newOptionStrategy(
+		constants.OrderTypeSpreads,
+		newOptionLeg(call, buy, opening, quantity),
+		newOptionLeg(put, buy, opening, quantity),
+	)
+}
+
+// CreateButterfly trades the lower and upper wings in one direction and twice
+// as many middle contracts in the other. Buying a butterfly buys the wings.
+func CreateButterfly(
+	lower ETradeOptionContract, middle ETradeOptionContract, upper ETradeOptionContract, quantity int64, buy bool,
+	opening bool,
+) (ETradeOptionStrategy, error) {
+	return newOptionStrategy(
+		constants.OrderTypeButterfly,
+		newOptionLeg(lower, buy, opening, quantity),
+		newOptionLeg(middle, !buy, opening, 2*quantity),
+		newOptionLeg(upper, buy, opening, quantity),
+	)
+}
+
+// CreateIronCondor trades two puts and two calls with strikes in ascending
+// order. Selling an iron condor (the usual way to open one for a credit)
+// sells the inner put and call and buys the outer put and call. If the inner
+// strikes are the same, the order is an iron butterfly.
+func CreateIronCondor(
+	lowerPut ETradeOptionContract, upperPut ETradeOptionContract, lowerCall ETradeOptionContract,
+	upperCall ETradeOptionContract, quantity int64, buy bool, opening bool,
+) (ETradeOptionStrategy, error) {
+	orderType := constants.OrderTypeIronCondor
+	if upperPut.StrikePrice == lowerCall.StrikePrice {
+		orderType = constants.OrderTypeIronButterfly
+	}
+	return newOptionStrategy(
+		orderType,
+		newOptionLeg(lowerPut, !buy, opening, quantity),
+		newOptionLeg(upperPut, buy, opening, quantity),
+		newOptionLeg(lowerCall, buy, opening, quantity),
+		newOptionLeg(upperCall, !buy, opening, quantity),
+	)
+}
+
+func newOptionStrategy(orderType constants.OrderType, legs ...ETradeOptionLeg) (ETradeOptionStrategy, error) {
+	if err := validateOptionLegs(orderType, legs); err != nil {
+		return ETradeOptionStrategy{}, err
+	}
+	return ETradeOptionStrategy{
+		OrderType: orderType,
+		Legs:      legs,
+	}, nil
+}
+
+func newOptionLeg(contract ETradeOptionContract, buy bool, opening bool, quantity int64) ETradeOptionLeg {
+	var orderAction constants.OrderAction
+	switch {
+	case buy && opening:
+		orderAction = constants.OrderActionBuyOpen
+	case buy && !opening:
+		orderAction = constants.OrderActionBuyClose
+	case !buy && opening:
+		orderAction = constants.OrderActionSellOpen
+	default:
+		orderAction = constants.OrderActionSellClose
+	}
+	return ETradeOptionLeg{
+		Contract:    contract,
+		OrderAction: orderAction,
+		Quantity:    quantity,
+	}
+}
+
+func validateCallAndPut(call ETradeOptionContract, put ETradeOptionContract) error {
+	if call.CallPut != constants.OptionTypeCall || put.CallPut != constants.OptionTypePut {
+		return errors.New("expected a call and a put")
+	}
+	if !call.ExpiryDate.Equal(put.ExpiryDate) {
+		return errors.New("the call and put must have the same expiration date")
+	}
+	return nil
+}
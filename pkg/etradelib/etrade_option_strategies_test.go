@@ -0,0 +1,190 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCreateOptionStrategies(t *testing.T) {
+	put140 := testOptionContract(constants.OptionTypePut, 140)
+	put145 := testOptionContract(constants.OptionTypePut, 145)
+	put150 := testOptionContract(constants.OptionTypePut, 150)
+	call150 := testOptionContract(constants.OptionTypeCall, 150)
+	call155 := testOptionContract(constants.OptionTypeCall, 155)
+	call160 := testOptionContract(constants.OptionTypeCall, 160)
+	call150Later := call150
+	call150Later.ExpiryDate = time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)
+
+	type testFn func() (ETradeOptionStrategy, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue ETradeOptionStrategy
+	}{
+		{
+			name: "Creates Vertical Spread",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateVerticalSpread(call150, call155, 1, true)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeSpreads,
+				Legs: []ETradeOptionLeg{
+					{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+				},
+			},
+		},
+		{
+			name: "Vertical Spread Fails With Mixed Types",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateVerticalSpread(call150, put145, 1, true)
+			},
+			expectErr: true,
+		},
+		{
+			name: "Creates Calendar Spread",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateCalendarSpread(call150Later, call150, 1, false)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeSpreads,
+				Legs: []ETradeOptionLeg{
+					{Contract: call150Later, OrderAction: constants.OrderActionBuyClose, Quantity: 1},
+					{Contract: call150, OrderAction: constants.OrderActionSellClose, Quantity: 1},
+				},
+			},
+		},
+		{
+			name: "Calendar Spread Fails With Same Expiration",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateCalendarSpread(call150, call150, 1, true)
+			},
+			expectErr: true,
+		},
+		{
+			name: "Creates Straddle",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateStraddle(call150, put150, 3, true, true)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeSpreads,
+				Legs: []ETradeOptionLeg{
+					{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 3},
+					{Contract: put150, OrderAction: constants.OrderActionBuyOpen, Quantity: 3},
+				},
+			},
+		},
+		{
+			name: "Straddle Fails With Different Strikes",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateStraddle(call155, put150, 1, true, true)
+			},
+			expectErr: true,
+		},
+		{
+			name: "Creates Strangle",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateStrangle(call155, put145, 1, false, true)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeSpreads,
+				Legs: []ETradeOptionLeg{
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+					{Contract: put145, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+				},
+			},
+		},
+		{
+			name: "Strangle Fails With Inverted Strikes",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateStrangle(testOptionContract(constants.OptionTypeCall, 140), put145, 1, false, true)
+			},
+			expectErr: true,
+		},
+		{
+			name: "Creates Butterfly",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateButterfly(call150, call155, call160, 1, true, true)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeButterfly,
+				Legs: []ETradeOptionLeg{
+					{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 2},
+					{Contract: call160, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				},
+			},
+		},
+		{
+			name: "Butterfly Fails With Unequal Wings",
+			testFn: func() (ETradeOptionStrategy, error) {
+				call165 := testOptionContract(constants.OptionTypeCall, 165)
+				return CreateButterfly(call150, call155, call165, 1, true, true)
+			},
+			expectErr: true,
+		},
+		{
+			name: "Creates Iron Condor",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateIronCondor(put140, put145, call155, call160, 1, false, true)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeIronCondor,
+				Legs: []ETradeOptionLeg{
+					{Contract: put140, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+					{Contract: put145, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+					{Contract: call160, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				},
+			},
+		},
+		{
+			name: "Creates Iron Butterfly",
+			testFn: func() (ETradeOptionStrategy, error) {
+				return CreateIronCondor(put140, put150, call150, call160, 1, false, true)
+			},
+			expectErr: false,
+			expectValue: ETradeOptionStrategy{
+				OrderType: constants.OrderTypeIronButterfly,
+				Legs: []ETradeOptionLeg{
+					{Contract: put140, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+					{Contract: put150, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+					{Contract: call150, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+					{Contract: call160, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+				},
+			},
+		},
+		{
+			name: "Iron Condor Fails With Calls Below Puts",
+			testFn: func() (ETradeOptionStrategy, error) {
+				call145 := testOptionContract(constants.OptionTypeCall, 145)
+				return CreateIronCondor(put140, put150, call145, call160, 1, false, true)
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := tt.testFn()
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
@@ -2,22 +2,69 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 )
 
 type ETradeOptionChainPair interface {
+	GetCallOsiKey() string
+	GetPutOsiKey() string
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.OptionPair, error)
 }
 
 type eTradeOptionChainPair struct {
 	jsonMap jsonmap.JsonMap
 }
 
+const (
+	// The AsJsonMap() map looks like this:
+	// {
+	//   "call": {
+	//     "osiKey": "ABC---240119C00150000",
+	//     <other call keys/values>
+	//   },
+	//   "put": {
+	//     "osiKey": "ABC---240119P00150000",
+	//     <other put keys/values>
+	//   }
+	// }
+
+	// OptionChainPairCallOsiKeyPath is the path to the OSI key of the call
+	OptionChainPairCallOsiKeyPath = ".call.osiKey"
+
+	// OptionChainPairPutOsiKeyPath is the path to the OSI key of the put
+	OptionChainPairPutOsiKeyPath = ".put.osiKey"
+)
+
 func CreateETradeOptionChainPair(responseMap jsonmap.JsonMap) (ETradeOptionChainPair, error) {
 	return &eTradeOptionChainPair{
 		jsonMap: responseMap,
 	}, nil
 }
 
+// GetCallOsiKey returns the OSI key of the call, or an empty string if the
+// pair has no call (e.g. if only puts were requested).
+func (e *eTradeOptionChainPair) GetCallOsiKey() string {
+	osiKey, _ := e.jsonMap.GetStringAtPathWithDefault(OptionChainPairCallOsiKeyPath, "")
+	return osiKey
+}
+
+// GetPutOsiKey returns the OSI key of the put, or an empty string if the pair
+// has no put (e.g. if only calls were requested).
+func (e *eTradeOptionChainPair) GetPutOsiKey() string {
+	osiKey, _ := e.jsonMap.GetStringAtPathWithDefault(OptionChainPairPutOsiKeyPath, "")
+	return osiKey
+}
+
 func (e *eTradeOptionChainPair) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the option pair as a typed model.
+func (e *eTradeOptionChainPair) AsModel() (*model.OptionPair, error) {
+	var m model.OptionPair
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
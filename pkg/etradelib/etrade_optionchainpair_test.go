@@ -2,6 +2,7 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -60,3 +61,56 @@ func TestETradeOptionChainPair_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeOptionChainPair_GetOsiKeys(t *testing.T) {
+	responseMap, err := NewNormalizedJsonMap(
+		[]byte(`
+{
+  "Call": {
+    "osiKey": "ABC---240119C00150000"
+  }
+}`),
+	)
+	require.Nil(t, err)
+	testObject, err := CreateETradeOptionChainPair(responseMap)
+	require.Nil(t, err)
+
+	assert.Equal(t, "ABC---240119C00150000", testObject.GetCallOsiKey())
+	assert.Equal(t, "", testObject.GetPutOsiKey())
+}
+
+func TestETradeOptionChainPair_AsModel(t *testing.T) {
+	responseMap, err := NewNormalizedJsonMap(
+		[]byte(`
+{
+  "Call": {
+    "optionType": "CALL",
+    "strikePrice": 150,
+    "osiKey": "ABC---240119C00150000",
+    "OptionGreeks": {
+      "delta": 0.72,
+      "iv": 0.21
+    }
+  }
+}`),
+	)
+	require.Nil(t, err)
+	testObject, err := CreateETradeOptionChainPair(responseMap)
+	require.Nil(t, err)
+	expectedValue := &model.OptionPair{
+		Call: &model.OptionDetails{
+			OptionType:  "CALL",
+			StrikePrice: 150,
+			OsiKey:      "ABC---240119C00150000",
+			OptionGreeks: &model.OptionGreeks{
+				Delta: 0.72,
+				Iv:    0.21,
+			},
+		},
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
@@ -1,10 +1,14 @@
 package etradelib
 
-import "github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+)
 
 type ETradeOrder interface {
 	GetId() int64
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Order, error)
 }
 
 type eTradeOrder struct {
@@ -42,3 +46,12 @@ func (e *eTradeOrder) GetId() int64 {
 func (e *eTradeOrder) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the order as a typed model.
+func (e *eTradeOrder) AsModel() (*model.Order, error) {
+	var m model.Order
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,518 @@
+package etradelib
+
+import (
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"math"
+	"strings"
+	"time"
+)
+
+// ETradeOrderPolicy is a set of rules that every order request must pass
+// before it is sent to ETrade. Rules left at their zero values are not
+// enforced.
+type ETradeOrderPolicy struct {
+	// MaxNotional is the largest dollar value allowed in a single order.
+	MaxNotional float64 `json:"maxNotional,omitempty"`
+
+	// MaxShares is the largest number of shares allowed in a single order.
+	MaxShares int64 `json:"maxShares,omitempty"`
+
+	// AllowSymbols, if not empty, lists the only symbols that may be traded.
+	// Options are matched by their underlying symbol.
+	AllowSymbols []string `json:"allowSymbols,omitempty"`
+
+	// DenySymbols lists symbols that may not be traded. Options are matched by
+	// their underlying symbol.
+	DenySymbols []string `json:"denySymbols,omitempty"`
+
+	// NoMarketOrdersOutsideRegularHours blocks market orders outside the
+	// regular session (9:30am to 4:00pm Eastern, Monday through Friday).
+	NoMarketOrdersOutsideRegularHours bool `json:"noMarketOrdersOutsideRegularHours,omitempty"`
+
+	// NoNakedShortOptions blocks option sales to open that are not covered by
+	// a long option of the same type in the same order (expiring no earlier)
+	// or, for calls, by shares held in the account.
+	NoNakedShortOptions bool `json:"noNakedShortOptions,omitempty"`
+}
+
+// ETradeOrderPolicyMarketData holds the information, beyond the order itself,
+// that some policy rules need.
+type ETradeOrderPolicyMarketData struct {
+	// Now is the time at which the order is being submitted.
+	Now time.Time
+
+	// LastPrices maps symbols to their last trade prices. It must include the
+	// symbols returned by ETradeOrderPolicy.GetPriceSymbols.
+	LastPrices map[string]float64
+
+	// SharesHeld maps symbols to the number of shares held in the account. It
+	// must be populated if ETradeOrderPolicy.NeedsSharesHeld returns true.
+	SharesHeld map[string]float64
+}
+
+// ETradeOrderPolicyViolation explains why an order was blocked. Rule is the
+// JSON name of the policy rule that the order violated.
+type ETradeOrderPolicyViolation struct {
+	Rule    string
+	Message string
+}
+
+// ETradeOrderPolicyViolationError is returned in place of submitting an order
+// that violates the policy.
+type ETradeOrderPolicyViolationError struct {
+	Violations []ETradeOrderPolicyViolation
+}
+
+const (
+	// The ETradeOrderPolicyViolationError AsJsonMap() map looks like this:
+	// {
+	//   "status": "blocked",
+	//   "violations": [
+	//     {
+	//       "rule": "maxShares",
+	//       "message": "order is for 200 shares, which exceeds the maximum of 100"
+	//     }
+	//   ]
+	// }
+
+	// OrderPolicyViolationsPath is the path to the slice of violations
+	OrderPolicyViolationsPath = ".violations"
+)
+
+func (e *ETradeOrderPolicyViolationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return "order blocked by policy: " + strings.Join(messages, "; ")
+}
+
+func (e *ETradeOrderPolicyViolationError) AsJsonMap() jsonmap.JsonMap {
+	violations := make(jsonmap.JsonSlice, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		violations = append(
+			violations, jsonmap.JsonMap{
+				"rule":    violation.Rule,
+				"message": violation.Message,
+			},
+		)
+	}
+	return jsonmap.JsonMap{
+		"status":     "blocked",
+		"violations": violations,
+	}
+}
+
+// orderPolicyLeg is an order request instrument reduced to what the policy
+// rules need.
+type orderPolicyLeg struct {
+	symbol       string
+	securityType string
+	callPut      string
+	expiry       time.Time
+	orderAction  string
+	quantity     int64
+}
+
+// orderPolicyOrder is an order request reduced to what the policy rules need.
+type orderPolicyOrder struct {
+	priceType     constants.OrderPriceType
+	marketSession string
+	limitPrice    float64
+	stopPrice     float64
+	legs          []orderPolicyLeg
+}
+
+// GetPriceSymbols returns the symbols whose last prices are needed to
+// evaluate the order request. Prices are only needed to determine the
+// notional value of equity orders that don't specify a price.
+func (p *ETradeOrderPolicy) GetPriceSymbols(request jsonmap.JsonMap) ([]string, error) {
+	if p.MaxNotional <= 0 {
+		return []string{}, nil
+	}
+	order, err := newOrderPolicyOrder(request)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make([]string, 0, len(order.legs))
+	if order.priceType == constants.OrderPriceTypeMarket {
+		for _, leg := range order.legs {
+			if leg.securityType == constants.OrderSecurityTypeEquity.String() {
+				symbols = append(symbols, leg.symbol)
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// NeedsSharesHeld returns whether the account's holdings are needed to
+// evaluate the order request. They are only needed to determine whether a
+// short call is covered.
+func (p *ETradeOrderPolicy) NeedsSharesHeld(request jsonmap.JsonMap) (bool, error) {
+	if !p.NoNakedShortOptions {
+		return false, nil
+	}
+	order, err := newOrderPolicyOrder(request)
+	if err != nil {
+		return false, err
+	}
+	for _, leg := range order.legs {
+		if leg.orderAction == constants.OrderActionSellOpen.String() &&
+			leg.callPut == constants.OptionTypeCall.String() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Evaluate checks an order request (as created by one of the
+// CreateETrade...OrderRequest functions) against the policy. It returns an
+// ETradeOrderPolicyViolationError if the order violates any rules.
+func (p *ETradeOrderPolicy) Evaluate(request jsonmap.JsonMap, marketData *ETradeOrderPolicyMarketData) error {
+	order, err := newOrderPolicyOrder(request)
+	if err != nil {
+		return err
+	}
+	violations := make([]ETradeOrderPolicyViolation, 0)
+	violations = append(violations, p.evaluateSymbols(order)...)
+	violations = append(violations, p.evaluateMaxShares(order)...)
+	violations = append(violations, p.evaluateMaxNotional(order, marketData)...)
+	violations = append(violations, p.evaluateMarketHours(order, marketData)...)
+	violations = append(violations, p.evaluateNakedShortOptions(order, marketData)...)
+	if len(violations) > 0 {
+		return &ETradeOrderPolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+func (p *ETradeOrderPolicy) evaluateSymbols(order *orderPolicyOrder) []ETradeOrderPolicyViolation {
+	violations := make([]ETradeOrderPolicyViolation, 0)
+	for _, leg := range order.legs {
+		if len(p.AllowSymbols) > 0 && !containsSymbol(p.AllowSymbols, leg.symbol) {
+			violations = append(
+				violations, ETradeOrderPolicyViolation{
+					Rule:    "allowSymbols",
+					Message: fmt.Sprintf("%s is not in the list of allowed symbols", leg.symbol),
+				},
+			)
+		}
+		if containsSymbol(p.DenySymbols, leg.symbol) {
+			violations = append(
+				violations, ETradeOrderPolicyViolation{
+					Rule:    "denySymbols",
+					Message: fmt.Sprintf("%s is in the list of denied symbols", leg.symbol),
+				},
+			)
+		}
+	}
+	return violations
+}
+
+func (p *ETradeOrderPolicy) evaluateMaxShares(order *orderPolicyOrder) []ETradeOrderPolicyViolation {
+	if p.MaxShares <= 0 {
+		return nil
+	}
+	var shares int64 = 0
+	for _, leg := range order.legs {
+		if leg.securityType == constants.OrderSecurityTypeEquity.String() {
+			shares += leg.quantity
+		}
+	}
+	if shares > p.MaxShares {
+		return []ETradeOrderPolicyViolation{
+			{
+				Rule:    "maxShares",
+				Message: fmt.Sprintf("order is for %d shares, which exceeds the maximum of %d", shares, p.MaxShares),
+			},
+		}
+	}
+	return nil
+}
+
+func (p *ETradeOrderPolicy) evaluateMaxNotional(
+	order *orderPolicyOrder, marketData *ETradeOrderPolicyMarketData,
+) []ETradeOrderPolicyViolation {
+	if p.MaxNotional <= 0 {
+		return nil
+	}
+	notional, err := order.notional(marketData.LastPrices)
+	if err != nil {
+		// If the notional value can't be determined, then the order can't be
+		// shown to be within the limit, so it's blocked.
+		return []ETradeOrderPolicyViolation{
+			{
+				Rule:    "maxNotional",
+				Message: fmt.Sprintf("unable to determine the notional value of the order (%s)", err.Error()),
+			},
+		}
+	}
+	if notional > p.MaxNotional {
+		return []ETradeOrderPolicyViolation{
+			{
+				Rule: "maxNotional",
+				Message: fmt.Sprintf(
+					"order notional value of $%.2f exceeds the maximum of $%.2f", notional, p.MaxNotional,
+				),
+			},
+		}
+	}
+	return nil
+}
+
+func (p *ETradeOrderPolicy) evaluateMarketHours(
+	order *orderPolicyOrder, marketData *ETradeOrderPolicyMarketData,
+) []ETradeOrderPolicyViolation {
+	if !p.NoMarketOrdersOutsideRegularHours || order.priceType != constants.OrderPriceTypeMarket {
+		return nil
+	}
+	regularSession := constants.MarketSessionRegular
+	if order.marketSession != regularSession.String() || !isRegularMarketHours(marketData.Now) {
+		return []ETradeOrderPolicyViolation{
+			{
+				Rule:    "noMarketOrdersOutsideRegularHours",
+				Message: "market orders are only allowed during regular market hours",
+			},
+		}
+	}
+	return nil
+}
+
+func (p *ETradeOrderPolicy) evaluateNakedShortOptions(
+	order *orderPolicyOrder, marketData *ETradeOrderPolicyMarketData,
+) []ETradeOrderPolicyViolation {
+	if !p.NoNakedShortOptions {
+		return nil
+	}
+	// Track how much of each long leg remains available to cover shorts, and
+	// how many calls the shares held in the account can cover.
+	longAvailable := make([]int64, len(order.legs))
+	for i, leg := range order.legs {
+		if leg.orderAction == constants.OrderActionBuyOpen.String() {
+			longAvailable[i] = leg.quantity
+		}
+	}
+	coveredCallsAvailable := make(map[string]int64)
+	for symbol, shares := range marketData.SharesHeld {
+		coveredCallsAvailable[strings.ToUpper(symbol)] = int64(math.Floor(shares / 100))
+	}
+
+	violations := make([]ETradeOrderPolicyViolation, 0)
+	for _, short := range order.legs {
+		if short.orderAction != constants.OrderActionSellOpen.String() {
+			continue
+		}
+		uncovered := short.quantity
+		for i, long := range order.legs {
+			if uncovered == 0 {
+				break
+			}
+			if longAvailable[i] > 0 && long.callPut == short.callPut && !long.expiry.Before(short.expiry) {
+				covered := minInt64(uncovered, longAvailable[i])
+				longAvailable[i] -= covered
+				uncovered -= covered
+			}
+		}
+		if uncovered > 0 && short.callPut == constants.OptionTypeCall.String() {
+			symbol := strings.ToUpper(short.symbol)
+			covered := minInt64(uncovered, coveredCallsAvailable[symbol])
+			coveredCallsAvailable[symbol] -= covered
+			uncovered -= covered
+		}
+		if uncovered > 0 {
+			violations = append(
+				violations, ETradeOrderPolicyViolation{
+					Rule: "noNakedShortOptions",
+					Message: fmt.Sprintf(
+						"%d of %d short %s %s contracts are not covered", uncovered, short.quantity, short.symbol,
+						strings.ToLower(short.callPut),
+					),
+				},
+			)
+		}
+	}
+	return violations
+}
+
+// notional returns the dollar value of the order. Equity orders without a
+// price use the last price of the symbol. Option orders are valued at their
+// limit (or stop) price times the contract multiplier.
+func (o *orderPolicyOrder) notional(lastPrices map[string]float64) (float64, error) {
+	const optionMultiplier = 100
+	if len(o.legs) == 1 {
+		leg := o.legs[0]
+		multiplier := 1.0
+		if leg.securityType == constants.OrderSecurityTypeOption.String() {
+			multiplier = optionMultiplier
+		}
+		price := 0.0
+		switch o.priceType {
+		case constants.OrderPriceTypeLimit, constants.OrderPriceTypeStopLimit:
+			price = math.Max(o.limitPrice, o.stopPrice)
+		case constants.OrderPriceTypeStop:
+			price = o.stopPrice
+		case constants.OrderPriceTypeMarket:
+			if leg.securityType != constants.OrderSecurityTypeEquity.String() {
+				return 0, fmt.Errorf("market %s orders have no price", leg.securityType)
+			}
+			lastPrice, found := lastPrices[leg.symbol]
+			if !found {
+				return 0, fmt.Errorf("no last price for %s", leg.symbol)
+			}
+			price = lastPrice
+		default:
+			return 0, fmt.Errorf("price type %s is not supported", o.priceType)
+		}
+		return price * float64(leg.quantity) * multiplier, nil
+	}
+
+	// A multi-leg order's price applies to one unit of the strategy, and the
+	// smallest leg quantity is the number of units.
+	units := o.legs[0].quantity
+	for _, leg := range o.legs[1:] {
+		units = minInt64(units, leg.quantity)
+	}
+	switch o.priceType {
+	case constants.OrderPriceTypeNetDebit, constants.OrderPriceTypeNetCredit:
+		return o.limitPrice * float64(units) * optionMultiplier, nil
+	case constants.OrderPriceTypeNetEven:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("multi-leg %s orders have no price", o.priceType)
+	}
+}
+
+func newOrderPolicyOrder(request jsonmap.JsonMap) (*orderPolicyOrder, error) {
+	details, err := request.GetSliceOfMaps(OrderRequestOrderKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(details) != 1 {
+		return nil, fmt.Errorf("expected one order detail in the request, found %d", len(details))
+	}
+	detail := details[0]
+	priceType, err := detail.GetString("priceType")
+	if err != nil {
+		return nil, err
+	}
+	marketSession, err := detail.GetString("marketSession")
+	if err != nil {
+		return nil, err
+	}
+	limitPrice, err := detail.GetFloatWithDefault("limitPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	stopPrice, err := detail.GetFloatWithDefault("stopPrice", 0)
+	if err != nil {
+		return nil, err
+	}
+	instruments, err := detail.GetSliceOfMaps(OrderRequestInstrumentKey)
+	if err != nil {
+		return nil, err
+	}
+	order := orderPolicyOrder{
+		priceType:     constants.OrderPriceTypeFromString(priceType),
+		marketSession: marketSession,
+		limitPrice:    limitPrice,
+		stopPrice:     stopPrice,
+		legs:          make([]orderPolicyLeg, 0, len(instruments)),
+	}
+	if len(instruments) < 1 {
+		return nil, fmt.Errorf("the order request has no instruments")
+	}
+	for _, instrument := range instruments {
+		leg, err := newOrderPolicyLeg(instrument)
+		if err != nil {
+			return nil, err
+		}
+		order.legs = append(order.legs, *leg)
+	}
+	return &order, nil
+}
+
+func newOrderPolicyLeg(instrument jsonmap.JsonMap) (*orderPolicyLeg, error) {
+	product, err := instrument.GetMap(OrderRequestProductKey)
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := product.GetString("symbol")
+	if err != nil {
+		return nil, err
+	}
+	securityType, err := product.GetString("securityType")
+	if err != nil {
+		return nil, err
+	}
+	callPut, err := product.GetStringWithDefault("callPut", "")
+	if err != nil {
+		return nil, err
+	}
+	expiry := time.Time{}
+	if securityType == constants.OrderSecurityTypeOption.String() {
+		year, err := product.GetInt("expiryYear")
+		if err != nil {
+			return nil, err
+		}
+		month, err := product.GetInt("expiryMonth")
+		if err != nil {
+			return nil, err
+		}
+		day, err := product.GetInt("expiryDay")
+		if err != nil {
+			return nil, err
+		}
+		expiry = time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC)
+	}
+	orderAction, err := instrument.GetString("orderAction")
+	if err != nil {
+		return nil, err
+	}
+	quantity, err := instrument.GetInt("quantity")
+	if err != nil {
+		return nil, err
+	}
+	return &orderPolicyLeg{
+		symbol:       symbol,
+		securityType: securityType,
+		callPut:      callPut,
+		expiry:       expiry,
+		orderAction:  orderAction,
+		quantity:     quantity,
+	}, nil
+}
+
+// isRegularMarketHours returns whether t falls within the regular session:
+// 9:30am to 4:00pm Eastern, Monday through Friday. Market holidays are not
+// considered.
+func isRegularMarketHours(t time.Time) bool {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		// Fall back to Eastern Standard Time if the time zone database is
+		// unavailable.
+		location = time.FixedZone("EST", -5*60*60)
+	}
+	t = t.In(location)
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	return minutes >= 9*60+30 && minutes < 16*60
+}
+
+func containsSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+func minInt64(a int64, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,501 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// testPolicyRegularHours is a Wednesday at 10:00am Eastern
+var testPolicyRegularHours = time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+
+// testPolicyAfterHours is a Wednesday at 5:00pm Eastern
+var testPolicyAfterHours = time.Date(2024, 1, 17, 22, 0, 0, 0, time.UTC)
+
+// testPolicyWeekend is a Saturday at 10:00am Eastern
+var testPolicyWeekend = time.Date(2024, 1, 20, 15, 0, 0, 0, time.UTC)
+
+func testPolicyEquityRequest(
+	symbol string, quantity int64, priceType constants.OrderPriceType, limitPrice float64,
+	marketSession constants.MarketSession,
+) jsonmap.JsonMap {
+	request, err := CreateETradeEquityOrderRequest(
+		"TestId", symbol, constants.OrderActionBuy, quantity, priceType, limitPrice, 0,
+		constants.OrderTermGoodForDay, marketSession, false,
+	)
+	if err != nil {
+		panic(err)
+	}
+	return request.AsJsonMap()
+}
+
+func testPolicyOptionRequest(
+	orderType constants.OrderType, legs []ETradeOptionLeg, priceType constants.OrderPriceType, limitPrice float64,
+) jsonmap.JsonMap {
+	request, err := CreateETradeOptionOrderRequest(
+		"TestId", orderType, legs, priceType, limitPrice, 0, constants.OrderTermGoodForDay,
+		constants.MarketSessionRegular, false,
+	)
+	if err != nil {
+		panic(err)
+	}
+	return request.AsJsonMap()
+}
+
+func TestETradeOrderPolicy_Evaluate(t *testing.T) {
+	call150 := testOptionContract(constants.OptionTypeCall, 150)
+	call155 := testOptionContract(constants.OptionTypeCall, 155)
+	put145 := testOptionContract(constants.OptionTypePut, 145)
+	laterCall155 := call155
+	laterCall155.ExpiryDate = time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		policy           ETradeOrderPolicy
+		request          jsonmap.JsonMap
+		marketData       ETradeOrderPolicyMarketData
+		expectErr        bool
+		expectViolations []ETradeOrderPolicyViolation
+	}{
+		{
+			name:   "Empty Policy Allows Order",
+			policy: ETradeOrderPolicy{},
+			request: testPolicyEquityRequest(
+				"ABC", 1000, constants.OrderPriceTypeMarket, 0, constants.MarketSessionExtended,
+			),
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyWeekend},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Max Notional Allows Limit Order Under Limit",
+			policy: ETradeOrderPolicy{MaxNotional: 1000},
+			request: testPolicyEquityRequest(
+				"ABC", 10, constants.OrderPriceTypeLimit, 100, constants.MarketSessionRegular,
+			),
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Max Notional Blocks Limit Order Over Limit",
+			policy: ETradeOrderPolicy{MaxNotional: 1000},
+			request: testPolicyEquityRequest(
+				"ABC", 11, constants.OrderPriceTypeLimit, 100, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "maxNotional", Message: "order notional value of $1100.00 exceeds the maximum of $1000.00"},
+			},
+		},
+		{
+			name:   "Max Notional Uses Last Price For Market Order",
+			policy: ETradeOrderPolicy{MaxNotional: 1000},
+			request: testPolicyEquityRequest(
+				"ABC", 10, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{
+				Now:        testPolicyRegularHours,
+				LastPrices: map[string]float64{"ABC": 101},
+			},
+			expectErr: true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "maxNotional", Message: "order notional value of $1010.00 exceeds the maximum of $1000.00"},
+			},
+		},
+		{
+			name:   "Max Notional Blocks Market Order Without Last Price",
+			policy: ETradeOrderPolicy{MaxNotional: 1000},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "maxNotional", Message: "unable to determine the notional value of the order (no last price for ABC)"},
+			},
+		},
+		{
+			name:   "Max Notional Applies Option Multiplier To Spread",
+			policy: ETradeOrderPolicy{MaxNotional: 500},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeSpreads,
+				[]ETradeOptionLeg{
+					{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 3},
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 3},
+				},
+				constants.OrderPriceTypeNetDebit, 2,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "maxNotional", Message: "order notional value of $600.00 exceeds the maximum of $500.00"},
+			},
+		},
+		{
+			name:   "Max Shares Blocks Large Order",
+			policy: ETradeOrderPolicy{MaxShares: 100},
+			request: testPolicyEquityRequest(
+				"ABC", 200, constants.OrderPriceTypeLimit, 1, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "maxShares", Message: "order is for 200 shares, which exceeds the maximum of 100"},
+			},
+		},
+		{
+			name:   "Allow List Matches Case-Insensitively",
+			policy: ETradeOrderPolicy{AllowSymbols: []string{"abc"}},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeLimit, 1, constants.MarketSessionRegular,
+			),
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Allow List Blocks Unlisted Symbol",
+			policy: ETradeOrderPolicy{AllowSymbols: []string{"XYZ"}},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeLimit, 1, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "allowSymbols", Message: "ABC is not in the list of allowed symbols"},
+			},
+		},
+		{
+			name:   "Deny List Blocks Listed Symbol",
+			policy: ETradeOrderPolicy{DenySymbols: []string{"abc"}},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeLimit, 1, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "denySymbols", Message: "ABC is in the list of denied symbols"},
+			},
+		},
+		{
+			name:   "Market Hours Allows Market Order During Regular Hours",
+			policy: ETradeOrderPolicy{NoMarketOrdersOutsideRegularHours: true},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Market Hours Blocks Market Order After Hours",
+			policy: ETradeOrderPolicy{NoMarketOrdersOutsideRegularHours: true},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyAfterHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{
+					Rule:    "noMarketOrdersOutsideRegularHours",
+					Message: "market orders are only allowed during regular market hours",
+				},
+			},
+		},
+		{
+			name:   "Market Hours Blocks Market Order On Weekend",
+			policy: ETradeOrderPolicy{NoMarketOrdersOutsideRegularHours: true},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyWeekend},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{
+					Rule:    "noMarketOrdersOutsideRegularHours",
+					Message: "market orders are only allowed during regular market hours",
+				},
+			},
+		},
+		{
+			name:   "Market Hours Blocks Market Order In Extended Session",
+			policy: ETradeOrderPolicy{NoMarketOrdersOutsideRegularHours: true},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionExtended,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{
+					Rule:    "noMarketOrdersOutsideRegularHours",
+					Message: "market orders are only allowed during regular market hours",
+				},
+			},
+		},
+		{
+			name:   "Market Hours Allows Limit Order After Hours",
+			policy: ETradeOrderPolicy{NoMarketOrdersOutsideRegularHours: true},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeLimit, 1, constants.MarketSessionExtended,
+			),
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyAfterHours},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Naked Short Allows Vertical Spread",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeSpreads,
+				[]ETradeOptionLeg{
+					{Contract: call150, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+				},
+				constants.OrderPriceTypeNetDebit, 2,
+			),
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Naked Short Blocks Short Leg Expiring After Long Leg",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeSpreads,
+				[]ETradeOptionLeg{
+					{Contract: call155, OrderAction: constants.OrderActionBuyOpen, Quantity: 1},
+					{Contract: laterCall155, OrderAction: constants.OrderActionSellOpen, Quantity: 1},
+				},
+				constants.OrderPriceTypeNetCredit, 1,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "noNakedShortOptions", Message: "1 of 1 short ABC call contracts are not covered"},
+			},
+		},
+		{
+			name:   "Naked Short Blocks Short Put",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeOption,
+				[]ETradeOptionLeg{
+					{Contract: put145, OrderAction: constants.OrderActionSellOpen, Quantity: 2},
+				},
+				constants.OrderPriceTypeLimit, 1,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "noNakedShortOptions", Message: "2 of 2 short ABC put contracts are not covered"},
+			},
+		},
+		{
+			name:   "Naked Short Allows Call Covered By Shares",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeOption,
+				[]ETradeOptionLeg{
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 2},
+				},
+				constants.OrderPriceTypeLimit, 1,
+			),
+			marketData: ETradeOrderPolicyMarketData{
+				Now:        testPolicyRegularHours,
+				SharesHeld: map[string]float64{"ABC": 250},
+			},
+			expectErr:        false,
+			expectViolations: nil,
+		},
+		{
+			name:   "Naked Short Blocks Call Partly Covered By Shares",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeOption,
+				[]ETradeOptionLeg{
+					{Contract: call155, OrderAction: constants.OrderActionSellOpen, Quantity: 3},
+				},
+				constants.OrderPriceTypeLimit, 1,
+			),
+			marketData: ETradeOrderPolicyMarketData{
+				Now:        testPolicyRegularHours,
+				SharesHeld: map[string]float64{"ABC": 250},
+			},
+			expectErr: true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "noNakedShortOptions", Message: "1 of 3 short ABC call contracts are not covered"},
+			},
+		},
+		{
+			name: "Reports All Violations",
+			policy: ETradeOrderPolicy{
+				MaxShares:   10,
+				DenySymbols: []string{"ABC"},
+			},
+			request: testPolicyEquityRequest(
+				"ABC", 20, constants.OrderPriceTypeLimit, 1, constants.MarketSessionRegular,
+			),
+			marketData: ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:  true,
+			expectViolations: []ETradeOrderPolicyViolation{
+				{Rule: "denySymbols", Message: "ABC is in the list of denied symbols"},
+				{Rule: "maxShares", Message: "order is for 20 shares, which exceeds the maximum of 10"},
+			},
+		},
+		{
+			name:             "Fails On Malformed Request",
+			policy:           ETradeOrderPolicy{MaxShares: 10},
+			request:          jsonmap.JsonMap{},
+			marketData:       ETradeOrderPolicyMarketData{Now: testPolicyRegularHours},
+			expectErr:        true,
+			expectViolations: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				err := tt.policy.Evaluate(tt.request, &tt.marketData)
+				if tt.expectErr {
+					assert.Error(t, err)
+					violationError, isViolationError := err.(*ETradeOrderPolicyViolationError)
+					if tt.expectViolations == nil {
+						assert.False(t, isViolationError)
+					} else {
+						assert.True(t, isViolationError)
+						assert.Equal(t, tt.expectViolations, violationError.Violations)
+					}
+				} else {
+					assert.Nil(t, err)
+				}
+			},
+		)
+	}
+}
+
+func TestETradeOrderPolicy_GetPriceSymbols(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      ETradeOrderPolicy
+		request     jsonmap.JsonMap
+		expectValue []string
+	}{
+		{
+			name:   "Returns Symbol For Market Order",
+			policy: ETradeOrderPolicy{MaxNotional: 1000},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			expectValue: []string{"ABC"},
+		},
+		{
+			name:   "Returns Nothing For Limit Order",
+			policy: ETradeOrderPolicy{MaxNotional: 1000},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeLimit, 1, constants.MarketSessionRegular,
+			),
+			expectValue: []string{},
+		},
+		{
+			name:   "Returns Nothing Without Max Notional",
+			policy: ETradeOrderPolicy{},
+			request: testPolicyEquityRequest(
+				"ABC", 1, constants.OrderPriceTypeMarket, 0, constants.MarketSessionRegular,
+			),
+			expectValue: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := tt.policy.GetPriceSymbols(tt.request)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestETradeOrderPolicy_NeedsSharesHeld(t *testing.T) {
+	shortCall := []ETradeOptionLeg{
+		{
+			Contract:    testOptionContract(constants.OptionTypeCall, 155),
+			OrderAction: constants.OrderActionSellOpen,
+			Quantity:    1,
+		},
+	}
+	shortPut := []ETradeOptionLeg{
+		{
+			Contract:    testOptionContract(constants.OptionTypePut, 145),
+			OrderAction: constants.OrderActionSellOpen,
+			Quantity:    1,
+		},
+	}
+	tests := []struct {
+		name        string
+		policy      ETradeOrderPolicy
+		request     jsonmap.JsonMap
+		expectValue bool
+	}{
+		{
+			name:   "Needs Shares For Short Call",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeOption, shortCall, constants.OrderPriceTypeLimit, 1,
+			),
+			expectValue: true,
+		},
+		{
+			name:   "Does Not Need Shares For Short Put",
+			policy: ETradeOrderPolicy{NoNakedShortOptions: true},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeOption, shortPut, constants.OrderPriceTypeLimit, 1,
+			),
+			expectValue: false,
+		},
+		{
+			name:   "Does Not Need Shares Without Rule",
+			policy: ETradeOrderPolicy{},
+			request: testPolicyOptionRequest(
+				constants.OrderTypeOption, shortCall, constants.OrderPriceTypeLimit, 1,
+			),
+			expectValue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := tt.policy.NeedsSharesHeld(tt.request)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestETradeOrderPolicyViolationError_AsJsonMap(t *testing.T) {
+	testObject := &ETradeOrderPolicyViolationError{
+		Violations: []ETradeOrderPolicyViolation{
+			{Rule: "maxShares", Message: "too many shares"},
+			{Rule: "denySymbols", Message: "denied"},
+		},
+	}
+	expectValue := jsonmap.JsonMap{
+		"status": "blocked",
+		"violations": jsonmap.JsonSlice{
+			jsonmap.JsonMap{"rule": "maxShares", "message": "too many shares"},
+			jsonmap.JsonMap{"rule": "denySymbols", "message": "denied"},
+		},
+	}
+	assert.Equal(t, expectValue, testObject.AsJsonMap())
+	assert.Equal(t, "order blocked by policy: too many shares; denied", testObject.Error())
+}
@@ -0,0 +1,217 @@
+package etradelib
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+type ETradeOrderRequest interface {
+	GetClientOrderId() string
+	AsJsonMap() jsonmap.JsonMap
+}
+
+type eTradeOrderRequest struct {
+	clientOrderId string
+	jsonMap       jsonmap.JsonMap
+}
+
+const (
+	// The AsJsonMap() map looks like this and is used as the body of preview
+	// and place order requests:
+	// {
+	//   "orderType": "EQ",
+	//   "clientOrderId": "<client order id>",
+	//   "Order": [
+	//     {
+	//       "allOrNone": false,
+	//       "priceType": "LIMIT",
+	//       "orderTerm": "GOOD_FOR_DAY",
+	//       "marketSession": "REGULAR",
+	//       "limitPrice": 123.45,
+	//       "Instrument": [
+	//         {
+	//           "Product": {
+	//             "securityType": "EQ",
+	//             "symbol": "ABC"
+	//           },
+	//           "orderAction": "BUY",
+	//           "quantityType": "QUANTITY",
+	//           "quantity": 10
+	//         }
+	//       ]
+	//     }
+	//   ]
+	// }
+
+	// OrderRequestOrderTypeKey is the key for the order type
+	OrderRequestOrderTypeKey = "orderType"
+
+	// OrderRequestClientOrderIdKey is the key for the client order ID
+	OrderRequestClientOrderIdKey = "clientOrderId"
+
+	// OrderRequestOrderKey is the key for the slice of order details
+	OrderRequestOrderKey = "Order"
+
+	// OrderRequestInstrumentKey is the key for the slice of instruments in
+	// each order detail
+	OrderRequestInstrumentKey = "Instrument"
+
+	// OrderRequestProductKey is the key for the product in each instrument
+	OrderRequestProductKey = "Product"
+)
+
+// CreateETradeEquityOrderRequest creates a request for a single-leg equity
+// order. The limit price is required for limit and stop-limit orders and the
+// stop price is required for stop and stop-limit orders. Prices that don't
+// apply to the price type must be zero.
+func CreateETradeEquityOrderRequest(
+	clientOrderId string, symbol string, orderAction constants.OrderAction, quantity int64,
+	priceType constants.OrderPriceType, limitPrice float64, stopPrice float64, orderTerm constants.OrderTerm,
+	marketSession constants.MarketSession, allOrNone bool,
+) (ETradeOrderRequest, error) {
+	if err := validateClientOrderId(clientOrderId); err != nil {
+		return nil, err
+	}
+	if symbol == "" {
+		return nil, errors.New("symbol not provided")
+	}
+	switch orderAction {
+	case constants.OrderActionBuy, constants.OrderActionSell, constants.OrderActionBuyToCover,
+		constants.OrderActionSellShort:
+	default:
+		return nil, fmt.Errorf("order action %s is not valid for an equity order", orderAction)
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity %d must be greater than zero", quantity)
+	}
+	switch priceType {
+	case constants.OrderPriceTypeMarket, constants.OrderPriceTypeLimit, constants.OrderPriceTypeStop,
+		constants.OrderPriceTypeStopLimit:
+	default:
+		return nil, fmt.Errorf("price type %s is not valid for an equity order", priceType)
+	}
+	needLimitPrice, needStopPrice, err := validateOrderPrices(priceType, limitPrice, stopPrice)
+	if err != nil {
+		return nil, err
+	}
+	if orderTerm == constants.OrderTermNil {
+		return nil, errors.New("order term not provided")
+	}
+	if marketSession == constants.MarketSessionNil {
+		return nil, errors.New("market session not provided")
+	}
+
+	orderDetail := jsonmap.JsonMap{
+		"allOrNone":     allOrNone,
+		"priceType":     priceType.String(),
+		"orderTerm":     orderTerm.String(),
+		"marketSession": marketSession.String(),
+		OrderRequestInstrumentKey: jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				OrderRequestProductKey: jsonmap.JsonMap{
+					"securityType": constants.OrderSecurityTypeEquity.String(),
+					"symbol":       symbol,
+				},
+				"orderAction":  orderAction.String(),
+				"quantityType": "QUANTITY",
+				"quantity":     quantity,
+			},
+		},
+	}
+	if needLimitPrice {
+		orderDetail["limitPrice"] = limitPrice
+	}
+	if needStopPrice {
+		orderDetail["stopPrice"] = stopPrice
+	}
+
+	return &eTradeOrderRequest{
+		clientOrderId: clientOrderId,
+		jsonMap: jsonmap.JsonMap{
+			OrderRequestOrderTypeKey:     constants.OrderTypeEquity.String(),
+			OrderRequestClientOrderIdKey: clientOrderId,
+			OrderRequestOrderKey:         jsonmap.JsonSlice{orderDetail},
+		},
+	}, nil
+}
+
+// validateClientOrderId checks that a client order ID was provided and that
+// ETrade will accept it.
+func validateClientOrderId(clientOrderId string) error {
+	if clientOrderId == "" {
+		return errors.New("client order ID not provided")
+	}
+	if len(clientOrderId) > constants.OrderClientIdMaxLength {
+		return fmt.Errorf(
+			"client order ID %s exceeds the maximum length of %d", clientOrderId, constants.OrderClientIdMaxLength,
+		)
+	}
+	return nil
+}
+
+// validateOrderPrices checks that the limit and stop prices agree with the
+// price type and returns whether each price belongs in the order request.
+func validateOrderPrices(priceType constants.OrderPriceType, limitPrice float64, stopPrice float64) (
+	needLimitPrice bool, needStopPrice bool, err error,
+) {
+	if limitPrice < 0 || stopPrice < 0 {
+		return false, false, errors.New("prices must not be negative")
+	}
+	needLimitPrice, needStopPrice, ok := getOrderPriceTypePrices(priceType)
+	if !ok {
+		return false, false, fmt.Errorf("price type %s is not valid", priceType)
+	}
+	if needLimitPrice != (limitPrice > 0) {
+		if needLimitPrice {
+			return false, false, fmt.Errorf("a limit price is required for %s orders", priceType)
+		}
+		return false, false, fmt.Errorf("a limit price is not allowed for %s orders", priceType)
+	}
+	if needStopPrice != (stopPrice > 0) {
+		if needStopPrice {
+			return false, false, fmt.Errorf("a stop price is required for %s orders", priceType)
+		}
+		return false, false, fmt.Errorf("a stop price is not allowed for %s orders", priceType)
+	}
+	return needLimitPrice, needStopPrice, nil
+}
+
+// getOrderPriceTypePrices returns whether orders of the price type have a
+// limit price and a stop price. It returns false if the price type isn't
+// valid for an order.
+func getOrderPriceTypePrices(priceType constants.OrderPriceType) (hasLimitPrice bool, hasStopPrice bool, ok bool) {
+	switch priceType {
+	case constants.OrderPriceTypeMarket, constants.OrderPriceTypeNetEven:
+		return false, false, true
+	case constants.OrderPriceTypeLimit, constants.OrderPriceTypeNetDebit, constants.OrderPriceTypeNetCredit:
+		return true, false, true
+	case constants.OrderPriceTypeStop:
+		return false, true, true
+	case constants.OrderPriceTypeStopLimit:
+		return true, true, true
+	default:
+		return false, false, false
+	}
+}
+
+// NewClientOrderId returns a random client order ID that is suitable for a
+// preview or place order request.
+func NewClientOrderId() (string, error) {
+	idBytes := make([]byte, constants.OrderClientIdMaxLength/2)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(idBytes), nil
+}
+
+func (e *eTradeOrderRequest) GetClientOrderId() string {
+	return e.clientOrderId
+}
+
+func (e *eTradeOrderRequest) AsJsonMap() jsonmap.JsonMap {
+	return e.jsonMap
+}
@@ -0,0 +1,253 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreateETradeEquityOrderRequest(t *testing.T) {
+	type testFn func() (ETradeOrderRequest, error)
+	tests := []struct {
+		name        string
+		testFn      testFn
+		expectErr   bool
+		expectValue ETradeOrderRequest
+	}{
+		{
+			name: "Creates Market Order Request",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr: false,
+			expectValue: &eTradeOrderRequest{
+				clientOrderId: "TestId",
+				jsonMap: jsonmap.JsonMap{
+					"orderType":     "EQ",
+					"clientOrderId": "TestId",
+					"Order": jsonmap.JsonSlice{
+						jsonmap.JsonMap{
+							"allOrNone":     false,
+							"priceType":     "MARKET",
+							"orderTerm":     "GOOD_FOR_DAY",
+							"marketSession": "REGULAR",
+							"Instrument": jsonmap.JsonSlice{
+								jsonmap.JsonMap{
+									"Product": jsonmap.JsonMap{
+										"securityType": "EQ",
+										"symbol":       "ABC",
+									},
+									"orderAction":  "BUY",
+									"quantityType": "QUANTITY",
+									"quantity":     int64(10),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Creates Stop Limit Order Request",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionSell, 10, constants.OrderPriceTypeStopLimit, 1.5, 2.5,
+					constants.OrderTermGoodUntilCancel, constants.MarketSessionExtended, true,
+				)
+			},
+			expectErr: false,
+			expectValue: &eTradeOrderRequest{
+				clientOrderId: "TestId",
+				jsonMap: jsonmap.JsonMap{
+					"orderType":     "EQ",
+					"clientOrderId": "TestId",
+					"Order": jsonmap.JsonSlice{
+						jsonmap.JsonMap{
+							"allOrNone":     true,
+							"priceType":     "STOP_LIMIT",
+							"orderTerm":     "GOOD_UNTIL_CANCEL",
+							"marketSession": "EXTENDED",
+							"limitPrice":    1.5,
+							"stopPrice":     2.5,
+							"Instrument": jsonmap.JsonSlice{
+								jsonmap.JsonMap{
+									"Product": jsonmap.JsonMap{
+										"securityType": "EQ",
+										"symbol":       "ABC",
+									},
+									"orderAction":  "SELL",
+									"quantityType": "QUANTITY",
+									"quantity":     int64(10),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Fails Without Client Order ID",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails With Client Order ID That Is Too Long",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"123456789012345678901", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0,
+					0, constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Symbol",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Order Action",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionNil, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails With Zero Quantity",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 0, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails With Limit Price On Market Order",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 1.5, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Limit Price On Limit Order",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeLimit, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Stop Price On Stop Order",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeStop, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Order Term",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermNil, constants.MarketSessionRegular, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Market Session",
+			testFn: func() (ETradeOrderRequest, error) {
+				return CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionNil, false,
+				)
+			},
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := tt.testFn()
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestNewClientOrderId(t *testing.T) {
+	actualValue, err := NewClientOrderId()
+	require.Nil(t, err)
+	assert.Len(t, actualValue, constants.OrderClientIdMaxLength)
+}
+
+func TestETradeOrderRequest_GetClientOrderId(t *testing.T) {
+	testObject := &eTradeOrderRequest{
+		clientOrderId: "TestId",
+		jsonMap: jsonmap.JsonMap{
+			"clientOrderId": "TestId",
+		},
+	}
+	expectedValue := "TestId"
+
+	actualValue := testObject.GetClientOrderId()
+	assert.Equal(t, expectedValue, actualValue)
+}
+
+func TestETradeOrderRequest_AsJsonMap(t *testing.T) {
+	testObject := &eTradeOrderRequest{
+		clientOrderId: "TestId",
+		jsonMap: jsonmap.JsonMap{
+			"clientOrderId": "TestId",
+		},
+	}
+	expectedValue := jsonmap.JsonMap{
+		"clientOrderId": "TestId",
+	}
+
+	actualValue := testObject.AsJsonMap()
+	assert.Equal(t, expectedValue, actualValue)
+}
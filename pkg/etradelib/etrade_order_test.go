@@ -3,6 +3,7 @@ package etradelib
 import (
 	"encoding/json"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -85,3 +86,63 @@ func TestETradeOrder_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeOrder_AsModel(t *testing.T) {
+	responseMap, err := NewNormalizedJsonMap(
+		[]byte(`
+{
+  "orderId": 1234,
+  "orderType": "EQ",
+  "OrderDetail": [
+    {
+      "status": "EXECUTED",
+      "priceType": "LIMIT",
+      "limitPrice": 100.5,
+      "Instrument": [
+        {
+          "Product": {
+            "symbol": "ABC",
+            "securityType": "EQ"
+          },
+          "orderAction": "BUY",
+          "orderedQuantity": 10,
+          "filledQuantity": 10,
+          "averageExecutionPrice": 100.25
+        }
+      ]
+    }
+  ]
+}`),
+	)
+	require.Nil(t, err)
+	testObject, err := CreateETradeOrder(responseMap)
+	require.Nil(t, err)
+	expectedValue := &model.Order{
+		OrderId:   1234,
+		OrderType: "EQ",
+		OrderDetail: []model.OrderDetail{
+			{
+				Status:     "EXECUTED",
+				PriceType:  "LIMIT",
+				LimitPrice: 100.5,
+				Instrument: []model.Instrument{
+					{
+						Product: &model.Product{
+							Symbol:       "ABC",
+							SecurityType: "EQ",
+						},
+						OrderAction:           "BUY",
+						OrderedQuantity:       10,
+						FilledQuantity:        10,
+						AverageExecutionPrice: 100.25,
+					},
+				},
+			},
+		},
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
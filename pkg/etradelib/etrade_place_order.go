@@ -0,0 +1,85 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+type ETradePlaceOrder interface {
+	GetOrderId() int64
+	AsJsonMap() jsonmap.JsonMap
+}
+
+type eTradePlaceOrder struct {
+	orderId int64
+	jsonMap jsonmap.JsonMap
+}
+
+const (
+	// The AsJsonMap() map looks like this:
+	// {
+	//   "orderType": "EQ",
+	//   "orderIds": [
+	//     {
+	//       "orderId": 1234
+	//     }
+	//   ],
+	//   "order": [
+	//     {
+	//       <order detail>
+	//     }
+	//   ],
+	//   <other placed order keys/values>
+	// }
+
+	// PlaceOrderOrderIdPath is the path to the ID of the placed order
+	PlaceOrderOrderIdPath = ".orderIds[0].orderId"
+)
+
+const (
+	// The place order response JSON looks like this:
+	// {
+	//   "PlaceOrderResponse": {
+	//     "orderType": "EQ",
+	//     "OrderIds": [
+	//       {
+	//         "orderId": 1234
+	//       }
+	//     ],
+	//     <other placed order keys/values>
+	//   }
+	// }
+
+	// placeOrderPlaceOrderResponsePath is the path to the placed order
+	placeOrderPlaceOrderResponsePath = ".placeOrderResponse"
+)
+
+func CreateETradePlaceOrderFromResponse(response []byte) (ETradePlaceOrder, error) {
+	responseMap, err := NewNormalizedJsonMap(response)
+	if err != nil {
+		return nil, err
+	}
+	return CreateETradePlaceOrder(responseMap)
+}
+
+func CreateETradePlaceOrder(responseMap jsonmap.JsonMap) (ETradePlaceOrder, error) {
+	placeMap, err := responseMap.GetMapAtPath(placeOrderPlaceOrderResponsePath)
+	if err != nil {
+		return nil, err
+	}
+	orderId, err := placeMap.GetIntAtPath(PlaceOrderOrderIdPath)
+	if err != nil {
+		return nil, err
+	}
+	return &eTradePlaceOrder{
+		orderId: orderId,
+		jsonMap: placeMap,
+	}, nil
+}
+
+func (e *eTradePlaceOrder) GetOrderId() int64 {
+	return e.orderId
+}
+
+func (e *eTradePlaceOrder) AsJsonMap() jsonmap.JsonMap {
+	return e.jsonMap
+}
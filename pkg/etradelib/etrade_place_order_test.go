@@ -0,0 +1,115 @@
+package etradelib
+
+import (
+	"encoding/json"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateETradePlaceOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		testJson    string
+		expectErr   bool
+		expectValue ETradePlaceOrder
+	}{
+		{
+			name: "Creates Place Order",
+			testJson: `
+{
+  "PlaceOrderResponse": {
+    "orderType": "EQ",
+    "OrderIds": [
+      {
+        "orderId": 1234
+      }
+    ]
+  }
+}`,
+			expectErr: false,
+			expectValue: &eTradePlaceOrder{
+				orderId: 1234,
+				jsonMap: jsonmap.JsonMap{
+					"orderType": "EQ",
+					"orderIds": jsonmap.JsonSlice{
+						jsonmap.JsonMap{
+							"orderId": json.Number("1234"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Fails Without Order ID",
+			testJson: `
+{
+  "PlaceOrderResponse": {
+    "orderType": "EQ"
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Place Order Response",
+			testJson: `
+{
+  "MISSING": {
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails On Bad JSON",
+			testJson: `
+{
+  "PlaceOrderResponse": {
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := CreateETradePlaceOrderFromResponse([]byte(tt.testJson))
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestETradePlaceOrder_GetOrderId(t *testing.T) {
+	testObject := &eTradePlaceOrder{
+		orderId: 1234,
+		jsonMap: jsonmap.JsonMap{},
+	}
+	expectedValue := int64(1234)
+
+	actualValue := testObject.GetOrderId()
+	assert.Equal(t, expectedValue, actualValue)
+}
+
+func TestETradePlaceOrder_AsJsonMap(t *testing.T) {
+	testObject := &eTradePlaceOrder{
+		orderId: 1234,
+		jsonMap: jsonmap.JsonMap{
+			"orderType": "EQ",
+		},
+	}
+	expectedValue := jsonmap.JsonMap{
+		"orderType": "EQ",
+	}
+
+	actualValue := testObject.AsJsonMap()
+	assert.Equal(t, expectedValue, actualValue)
+}
@@ -1,12 +1,16 @@
 package etradelib
 
-import "github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+)
 
 type ETradePosition interface {
 	GetId() int64
 	AddLots(responseMap jsonmap.JsonMap) error
 	AddLotsFromResponse(response []byte) error
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Position, error)
 }
 
 type eTradePosition struct {
@@ -76,6 +80,16 @@ func (e *eTradePosition) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
 
+// AsModel returns the position as a typed model. The model's lots are
+// populated only if lots have been added to the position.
+func (e *eTradePosition) AsModel() (*model.Position, error) {
+	var m model.Position
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
 func (e *eTradePosition) AddLots(responseMap jsonmap.JsonMap) error {
 	lotsSlice, err := responseMap.GetSliceAtPath(positionLotsPositionLotResponsePath)
 	if err != nil {
@@ -3,6 +3,7 @@ package etradelib
 import (
 	"encoding/json"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -171,3 +172,109 @@ func TestETradePortfolioPosition_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradePortfolioPosition_AsModel(t *testing.T) {
+	tests := []struct {
+		name         string
+		testJson     string
+		testLotsJson string
+		expectErr    bool
+		expectValue  *model.Position
+	}{
+		{
+			name: "Decodes Position Without Lots",
+			testJson: `
+{
+  "positionId": 1234,
+  "Product": {
+    "symbol": "ABC",
+    "securityType": "EQ"
+  },
+  "quantity": 10,
+  "pricePaid": 12.5,
+  "Quick": {
+    "lastTrade": 13.25
+  }
+}`,
+			expectErr: false,
+			expectValue: &model.Position{
+				PositionId: 1234,
+				Product: &model.Product{
+					Symbol:       "ABC",
+					SecurityType: "EQ",
+				},
+				Quantity:  10,
+				PricePaid: 12.5,
+				Quick: &model.QuickView{
+					LastTrade: 13.25,
+				},
+			},
+		},
+		{
+			name: "Decodes Position With Lots",
+			testJson: `
+{
+  "positionId": 1234
+}`,
+			testLotsJson: `
+{
+  "PositionLotsResponse": {
+    "PositionLot": [
+      {
+        "positionId": 1234,
+        "positionLotId": 5678,
+        "price": 12.5,
+        "remainingQty": 10,
+        "acquiredDate": 1672617600000
+      }
+    ]
+  }
+}`,
+			expectErr: false,
+			expectValue: &model.Position{
+				PositionId: 1234,
+				Lots: []model.Lot{
+					{
+						PositionId:    1234,
+						PositionLotId: 5678,
+						Price:         12.5,
+						RemainingQty:  10,
+						AcquiredDate:  1672617600000,
+					},
+				},
+			},
+		},
+		{
+			name: "Fails On Type Mismatch",
+			testJson: `
+{
+  "positionId": 1234,
+  "quantity": "ten"
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				responseMap, err := NewNormalizedJsonMap([]byte(tt.testJson))
+				require.Nil(t, err)
+				testObject, err := CreateETradePosition(responseMap)
+				require.Nil(t, err)
+				if tt.testLotsJson != "" {
+					require.Nil(t, testObject.AddLotsFromResponse([]byte(tt.testLotsJson)))
+				}
+				// Call the Method Under Test
+				actualValue, err := testObject.AsModel()
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
@@ -0,0 +1,86 @@
+package etradelib
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+)
+
+type ETradePreviewOrder interface {
+	GetPreviewId() int64
+	AsJsonMap() jsonmap.JsonMap
+}
+
+type eTradePreviewOrder struct {
+	previewId int64
+	jsonMap   jsonmap.JsonMap
+}
+
+const (
+	// The AsJsonMap() map looks like this:
+	// {
+	//   "orderType": "EQ",
+	//   "totalOrderValue": 123.45,
+	//   "previewIds": [
+	//     {
+	//       "previewId": 1234
+	//     }
+	//   ],
+	//   "order": [
+	//     {
+	//       <order detail>
+	//     }
+	//   ],
+	//   <other preview keys/values>
+	// }
+
+	// PreviewOrderPreviewIdPath is the path to the preview ID
+	PreviewOrderPreviewIdPath = ".previewIds[0].previewId"
+)
+
+const (
+	// The preview order response JSON looks like this:
+	// {
+	//   "PreviewOrderResponse": {
+	//     "orderType": "EQ",
+	//     "PreviewIds": [
+	//       {
+	//         "previewId": 1234
+	//       }
+	//     ],
+	//     <other preview keys/values>
+	//   }
+	// }
+
+	// previewOrderPreviewOrderResponsePath is the path to the preview
+	previewOrderPreviewOrderResponsePath = ".previewOrderResponse"
+)
+
+func CreateETradePreviewOrderFromResponse(response []byte) (ETradePreviewOrder, error) {
+	responseMap, err := NewNormalizedJsonMap(response)
+	if err != nil {
+		return nil, err
+	}
+	return CreateETradePreviewOrder(responseMap)
+}
+
+func CreateETradePreviewOrder(responseMap jsonmap.JsonMap) (ETradePreviewOrder, error) {
+	previewMap, err := responseMap.GetMapAtPath(previewOrderPreviewOrderResponsePath)
+	if err != nil {
+		return nil, err
+	}
+	previewId, err := previewMap.GetIntAtPath(PreviewOrderPreviewIdPath)
+	if err != nil {
+		return nil, err
+	}
+	return &eTradePreviewOrder{
+		previewId: previewId,
+		jsonMap:   previewMap,
+	}, nil
+}
+
+func (e *eTradePreviewOrder) GetPreviewId() int64 {
+	return e.previewId
+}
+
+func (e *eTradePreviewOrder) AsJsonMap() jsonmap.JsonMap {
+	return e.jsonMap
+}
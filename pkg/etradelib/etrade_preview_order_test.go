@@ -0,0 +1,115 @@
+package etradelib
+
+import (
+	"encoding/json"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateETradePreviewOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		testJson    string
+		expectErr   bool
+		expectValue ETradePreviewOrder
+	}{
+		{
+			name: "Creates Preview Order",
+			testJson: `
+{
+  "PreviewOrderResponse": {
+    "orderType": "EQ",
+    "PreviewIds": [
+      {
+        "previewId": 1234
+      }
+    ]
+  }
+}`,
+			expectErr: false,
+			expectValue: &eTradePreviewOrder{
+				previewId: 1234,
+				jsonMap: jsonmap.JsonMap{
+					"orderType": "EQ",
+					"previewIds": jsonmap.JsonSlice{
+						jsonmap.JsonMap{
+							"previewId": json.Number("1234"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Fails Without Preview ID",
+			testJson: `
+{
+  "PreviewOrderResponse": {
+    "orderType": "EQ"
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails Without Preview Order Response",
+			testJson: `
+{
+  "MISSING": {
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name: "Fails On Bad JSON",
+			testJson: `
+{
+  "PreviewOrderResponse": {
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := CreateETradePreviewOrderFromResponse([]byte(tt.testJson))
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
+
+func TestETradePreviewOrder_GetPreviewId(t *testing.T) {
+	testObject := &eTradePreviewOrder{
+		previewId: 1234,
+		jsonMap:   jsonmap.JsonMap{},
+	}
+	expectedValue := int64(1234)
+
+	actualValue := testObject.GetPreviewId()
+	assert.Equal(t, expectedValue, actualValue)
+}
+
+func TestETradePreviewOrder_AsJsonMap(t *testing.T) {
+	testObject := &eTradePreviewOrder{
+		previewId: 1234,
+		jsonMap: jsonmap.JsonMap{
+			"orderType": "EQ",
+		},
+	}
+	expectedValue := jsonmap.JsonMap{
+		"orderType": "EQ",
+	}
+
+	actualValue := testObject.AsJsonMap()
+	assert.Equal(t, expectedValue, actualValue)
+}
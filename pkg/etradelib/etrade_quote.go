@@ -2,10 +2,12 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 )
 
 type ETradeQuote interface {
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Quote, error)
 }
 
 type eTradeQuote struct {
@@ -21,3 +23,13 @@ func CreateETradeQuote(responseMap jsonmap.JsonMap) (ETradeQuote, error) {
 func (e *eTradeQuote) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the quote as a typed model. Only the detail section that
+// matches the request's detail flag is populated.
+func (e *eTradeQuote) AsModel() (*model.Quote, error) {
+	var m model.Quote
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -2,6 +2,7 @@ package etradelib
 
 import (
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -60,3 +61,76 @@ func TestETradeQuote_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeQuote_AsModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		testJson    string
+		expectErr   bool
+		expectValue *model.Quote
+	}{
+		{
+			name: "Decodes Quote",
+			testJson: `
+{
+  "dateTimeUTC": 1705525199,
+  "quoteStatus": "CLOSING",
+  "Intraday": {
+    "ask": 101.6,
+    "bid": 101.4,
+    "lastTrade": 101.5,
+    "totalVolume": 123456
+  },
+  "Product": {
+    "symbol": "ABC",
+    "securityType": "EQ"
+  }
+}`,
+			expectErr: false,
+			expectValue: &model.Quote{
+				DateTimeUTC: 1705525199,
+				QuoteStatus: "CLOSING",
+				Product: &model.Product{
+					Symbol:       "ABC",
+					SecurityType: "EQ",
+				},
+				Intraday: &model.IntradayQuote{
+					Ask:         101.6,
+					Bid:         101.4,
+					LastTrade:   101.5,
+					TotalVolume: 123456,
+				},
+			},
+		},
+		{
+			name: "Fails On Type Mismatch",
+			testJson: `
+{
+  "All": {
+    "lastTrade": "NotANumber"
+  }
+}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				responseMap, err := NewNormalizedJsonMap([]byte(tt.testJson))
+				require.Nil(t, err)
+				testObject, err := CreateETradeQuote(responseMap)
+				require.Nil(t, err)
+				// Call the Method Under Test
+				actualValue, err := testObject.AsModel()
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+				}
+				assert.Equal(t, tt.expectValue, actualValue)
+			},
+		)
+	}
+}
@@ -1,10 +1,14 @@
 package etradelib
 
-import "github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+)
 
 type ETradeTransaction interface {
 	GetId() string
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Transaction, error)
 }
 
 type eTradeTransaction struct {
@@ -42,3 +46,12 @@ func (e *eTradeTransaction) GetId() string {
 func (e *eTradeTransaction) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the transaction as a typed model.
+func (e *eTradeTransaction) AsModel() (*model.Transaction, error) {
+	var m model.Transaction
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
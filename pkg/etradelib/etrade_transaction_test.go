@@ -1,7 +1,9 @@
 package etradelib
 
 import (
+	"encoding/json"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -84,3 +86,45 @@ func TestETradeTransaction_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeTransaction_AsModel(t *testing.T) {
+	responseMap, err := NewNormalizedJsonMap(
+		[]byte(`
+{
+  "transactionId": "1234",
+  "transactionDate": 1672617600000,
+  "amount": -1400,
+  "transactionType": "Bought",
+  "brokerage": {
+    "product": {
+      "symbol": "ABC",
+      "securityType": "EQ"
+    },
+    "quantity": 10,
+    "price": 140
+  }
+}`),
+	)
+	require.Nil(t, err)
+	testObject, err := CreateETradeTransaction(responseMap)
+	require.Nil(t, err)
+	expectedValue := &model.Transaction{
+		TransactionId:   json.Number("1234"),
+		TransactionDate: 1672617600000,
+		Amount:          -1400,
+		TransactionType: "Bought",
+		Brokerage: &model.Brokerage{
+			Product: &model.Product{
+				Symbol:       "ABC",
+				SecurityType: "EQ",
+			},
+			Quantity: 10,
+			Price:    140,
+		},
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
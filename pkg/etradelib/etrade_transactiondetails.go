@@ -1,10 +1,14 @@
 package etradelib
 
-import "github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
+)
 
 type ETradeTransactionDetails interface {
 	GetId() int64
 	AsJsonMap() jsonmap.JsonMap
+	AsModel() (*model.Transaction, error)
 }
 
 type eTradeTransactionDetails struct {
@@ -63,3 +67,13 @@ func (e *eTradeTransactionDetails) GetId() int64 {
 func (e *eTradeTransactionDetails) AsJsonMap() jsonmap.JsonMap {
 	return e.jsonMap
 }
+
+// AsModel returns the transaction details as a typed model. Details include
+// the transaction's category, which the transaction list omits.
+func (e *eTradeTransactionDetails) AsModel() (*model.Transaction, error) {
+	var m model.Transaction
+	if err := e.jsonMap.Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -3,7 +3,9 @@ package etradelib
 import (
 	"encoding/json"
 	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
@@ -95,3 +97,33 @@ func TestETradeTransactionDetails_AsJsonMap(t *testing.T) {
 	actualValue := testObject.AsJsonMap()
 	assert.Equal(t, expectedValue, actualValue)
 }
+
+func TestETradeTransactionDetails_AsModel(t *testing.T) {
+	testObject, err := CreateETradeTransactionDetailsFromResponse(
+		[]byte(`
+{
+  "TransactionDetailsResponse": {
+    "transactionId": 1234,
+    "amount": 25.5,
+    "Category": {
+      "categoryId": "1",
+      "parentId": "2"
+    }
+  }
+}`),
+	)
+	require.Nil(t, err)
+	expectedValue := &model.Transaction{
+		TransactionId: json.Number("1234"),
+		Amount:        25.5,
+		Category: &model.Category{
+			CategoryId: "1",
+			ParentId:   "2",
+		},
+	}
+
+	// Call the Method Under Test
+	actualValue, err := testObject.AsModel()
+	assert.Nil(t, err)
+	assert.Equal(t, expectedValue, actualValue)
+}
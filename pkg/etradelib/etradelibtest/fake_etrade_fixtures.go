@@ -0,0 +1,221 @@
+package etradelibtest
+
+import (
+	"bytes"
+	_ "embed"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"io"
+	"os"
+)
+
+// FakeETradeFixtures seed a fake ETrade server. The maps have the same form
+// (including the capitalization of keys) as the corresponding parts of
+// ETrade's responses.
+type FakeETradeFixtures struct {
+	// ConsumerKey is the only consumer key that the server accepts.
+	ConsumerKey string
+
+	// VerifyCode is the code that the authorization page displays and that
+	// the server accepts to complete authentication.
+	VerifyCode string
+
+	Accounts []*FakeETradeAccount
+
+	// Alerts are alert details, which must have an "id".
+	Alerts []jsonmap.JsonMap
+
+	// Quotes are quote data, which must have a "Product" with a "symbol".
+	Quotes []jsonmap.JsonMap
+
+	// Lookup are product lookup results, which must have a "symbol" and a
+	// "description".
+	Lookup []jsonmap.JsonMap
+
+	// OptionChains map symbols to option chain responses.
+	OptionChains map[string]jsonmap.JsonMap
+
+	// OptionExpireDates map symbols to expiration dates.
+	OptionExpireDates map[string]jsonmap.JsonSlice
+}
+
+type FakeETradeAccount struct {
+	// Account is the account list entry, which must have an "accountIdKey".
+	Account jsonmap.JsonMap
+
+	Balance jsonmap.JsonMap
+
+	// Totals are the portfolio totals.
+	Totals jsonmap.JsonMap
+
+	// Positions must have a "positionId".
+	Positions []jsonmap.JsonMap
+
+	// Lots map position IDs to the lots of the position.
+	Lots map[string]jsonmap.JsonSlice
+
+	// Transactions are transaction details, which must have a numeric
+	// "transactionId".
+	Transactions []jsonmap.JsonMap
+
+	// Orders must have an "orderId" and an "OrderDetail" slice with a
+	// "status".
+	Orders []jsonmap.JsonMap
+}
+
+const (
+	// The fixtures JSON looks like this:
+	// {
+	//   "consumerKey": "key",
+	//   "verifyCode": "code",
+	//   "accounts": [
+	//     {
+	//       "account": {"accountIdKey": "key", ...},
+	//       "balance": {...},
+	//       "totals": {...},
+	//       "positions": [{"positionId": 1, ...}],
+	//       "lots": {"1": [{...}]},
+	//       "transactions": [{"transactionId": 2, ...}],
+	//       "orders": [{"orderId": 3, "OrderDetail": [{"status": "OPEN", ...}], ...}]
+	//     }
+	//   ],
+	//   "alerts": [{"id": 4, ...}],
+	//   "quotes": [{"Product": {"symbol": "ABC", ...}, ...}],
+	//   "lookup": [{"symbol": "ABC", "description": "ABC CORP", ...}],
+	//   "optionChains": {"ABC": {"OptionPair": [...], ...}},
+	//   "optionExpireDates": {"ABC": [{"year": 2023, ...}]}
+	// }
+
+	fakeFixturesConsumerKeyKey       = "consumerKey"
+	fakeFixturesVerifyCodeKey        = "verifyCode"
+	fakeFixturesAccountsKey          = "accounts"
+	fakeFixturesAlertsKey            = "alerts"
+	fakeFixturesQuotesKey            = "quotes"
+	fakeFixturesLookupKey            = "lookup"
+	fakeFixturesOptionChainsKey      = "optionChains"
+	fakeFixturesOptionExpireDatesKey = "optionExpireDates"
+
+	fakeAccountAccountKey      = "account"
+	fakeAccountBalanceKey      = "balance"
+	fakeAccountTotalsKey       = "totals"
+	fakeAccountPositionsKey    = "positions"
+	fakeAccountLotsKey         = "lots"
+	fakeAccountTransactionsKey = "transactions"
+	fakeAccountOrdersKey       = "orders"
+)
+
+//go:embed fake_etrade_fixtures.json
+var defaultFakeETradeFixtures []byte
+
+// CreateDefaultFakeETradeFixtures creates fixtures with two accounts (one with
+// positions, lots, transactions, and orders, and one that's empty), alerts,
+// quotes and lookup results for AAPL and MSFT, and an AAPL option chain. The
+// consumer key is "fakeConsumerKey" and the verify code is "FAKE1".
+func CreateDefaultFakeETradeFixtures() *FakeETradeFixtures {
+	fixtures, err := LoadFakeETradeFixtures(bytes.NewReader(defaultFakeETradeFixtures))
+	if err != nil {
+		panic(err)
+	}
+	return fixtures
+}
+
+func LoadFakeETradeFixturesFromFile(filename string) (*FakeETradeFixtures, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+	return LoadFakeETradeFixtures(file)
+}
+
+func LoadFakeETradeFixtures(reader io.Reader) (*FakeETradeFixtures, error) {
+	fixturesMap, err := jsonmap.NewJsonMapFromIoReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	fixtures := FakeETradeFixtures{
+		OptionChains:      map[string]jsonmap.JsonMap{},
+		OptionExpireDates: map[string]jsonmap.JsonSlice{},
+	}
+	if fixtures.ConsumerKey, err = fixturesMap.GetStringWithDefault(fakeFixturesConsumerKeyKey, ""); err != nil {
+		return nil, err
+	}
+	if fixtures.VerifyCode, err = fixturesMap.GetStringWithDefault(fakeFixturesVerifyCodeKey, ""); err != nil {
+		return nil, err
+	}
+	accountMaps, err := fixturesMap.GetSliceOfMapsWithDefault(fakeFixturesAccountsKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, accountMap := range accountMaps {
+		account, err := loadFakeETradeAccount(accountMap)
+		if err != nil {
+			return nil, err
+		}
+		fixtures.Accounts = append(fixtures.Accounts, account)
+	}
+	if fixtures.Alerts, err = fixturesMap.GetSliceOfMapsWithDefault(fakeFixturesAlertsKey, nil); err != nil {
+		return nil, err
+	}
+	if fixtures.Quotes, err = fixturesMap.GetSliceOfMapsWithDefault(fakeFixturesQuotesKey, nil); err != nil {
+		return nil, err
+	}
+	if fixtures.Lookup, err = fixturesMap.GetSliceOfMapsWithDefault(fakeFixturesLookupKey, nil); err != nil {
+		return nil, err
+	}
+	optionChains, err := fixturesMap.GetMapWithDefault(fakeFixturesOptionChainsKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	for symbol := range optionChains {
+		if fixtures.OptionChains[symbol], err = optionChains.GetMap(symbol); err != nil {
+			return nil, err
+		}
+	}
+	optionExpireDates, err := fixturesMap.GetMapWithDefault(fakeFixturesOptionExpireDatesKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	for symbol := range optionExpireDates {
+		if fixtures.OptionExpireDates[symbol], err = optionExpireDates.GetSlice(symbol); err != nil {
+			return nil, err
+		}
+	}
+	return &fixtures, nil
+}
+
+func loadFakeETradeAccount(accountMap jsonmap.JsonMap) (*FakeETradeAccount, error) {
+	var err error
+	account := FakeETradeAccount{
+		Lots: map[string]jsonmap.JsonSlice{},
+	}
+	if account.Account, err = accountMap.GetMap(fakeAccountAccountKey); err != nil {
+		return nil, err
+	}
+	if account.Balance, err = accountMap.GetMapWithDefault(fakeAccountBalanceKey, nil); err != nil {
+		return nil, err
+	}
+	if account.Totals, err = accountMap.GetMapWithDefault(fakeAccountTotalsKey, nil); err != nil {
+		return nil, err
+	}
+	if account.Positions, err = accountMap.GetSliceOfMapsWithDefault(fakeAccountPositionsKey, nil); err != nil {
+		return nil, err
+	}
+	lots, err := accountMap.GetMapWithDefault(fakeAccountLotsKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	for positionId := range lots {
+		if account.Lots[positionId], err = lots.GetSlice(positionId); err != nil {
+			return nil, err
+		}
+	}
+	if account.Transactions, err = accountMap.GetSliceOfMapsWithDefault(fakeAccountTransactionsKey, nil); err != nil {
+		return nil, err
+	}
+	if account.Orders, err = accountMap.GetSliceOfMapsWithDefault(fakeAccountOrdersKey, nil); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
@@ -0,0 +1,943 @@
+package etradelibtest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeETradeServer is a fake of the ETrade API that serves responses from
+// fixtures. It implements the OAuth handshake (without checking signatures),
+// pages lists with markers the way ETrade does, responds to bad requests with
+// ETrade's error payloads, and keeps track of the orders that are previewed,
+// placed, changed, and canceled. Run it with httptest.NewServer and point a
+// client at it with a custom URL base.
+type FakeETradeServer struct {
+	mutex    sync.Mutex
+	router   chi.Router
+	fixtures *FakeETradeFixtures
+	now      func() time.Time
+
+	requestTokens  map[string]bool
+	accessTokens   map[string]bool
+	previews       map[string]*fakePreview
+	injectedErrors []*fakeInjectedError
+	nextId         int64
+}
+
+type fakePreview struct {
+	accountIdKey string
+	orderId      string
+	request      []byte
+}
+
+type fakeInjectedError struct {
+	method     string
+	path       string
+	statusCode int
+	code       int
+	message    string
+}
+
+const (
+	// fakeFirstId is the first ID given to previews, orders, and tokens. It's
+	// large so that it doesn't collide with the IDs in fixtures.
+	fakeFirstId = 900000
+
+	fakeTransactionsDefaultCount = 50
+	fakePortfolioDefaultCount    = 50
+	fakeOrdersDefaultCount       = 25
+	fakeAlertsDefaultCount       = 25
+	fakeQuotesMaxSymbols         = 25
+)
+
+// These are the codes in ETrade's error payloads.
+const (
+	fakeErrorCodeInvalidAccount = 100
+	fakeErrorCodeInvalidRequest = 101
+	fakeErrorCodeInvalidSymbol  = 10033
+	fakeErrorCodeNotFound       = 1001
+	fakeErrorCodeInvalidPreview = 1033
+	fakeErrorCodeOrderNotOpen   = 5001
+)
+
+// NewFakeETradeServer creates a fake ETrade server. The server modifies the
+// fixtures as orders change and alerts are deleted.
+func NewFakeETradeServer(fixtures *FakeETradeFixtures) *FakeETradeServer {
+	s := &FakeETradeServer{
+		fixtures:      fixtures,
+		now:           time.Now,
+		requestTokens: map[string]bool{},
+		accessTokens:  map[string]bool{},
+		previews:      map[string]*fakePreview{},
+		nextId:        fakeFirstId,
+	}
+
+	r := chi.NewRouter()
+	r.Post("/oauth/request_token", s.getRequestToken)
+	r.Get("/e/t/etws/authorize", s.authorizeApplication)
+	r.Post("/oauth/access_token", s.getAccessToken)
+	r.Get("/oauth/renew_access_token", s.renewAccessToken)
+	r.Get("/oauth/revoke_access_token", s.revokeAccessToken)
+	r.Route(
+		"/v1", func(r chi.Router) {
+			r.Use(s.requireAccessToken)
+			r.Get("/accounts/list", s.listAccounts)
+			r.Route(
+				"/accounts/{accountIdKey}", func(r chi.Router) {
+					r.Get("/balance", s.getAccountBalances)
+					r.Get("/transactions", s.listTransactions)
+					r.Get("/transactions/{transactionId}", s.listTransactionDetails)
+					r.Get("/portfolio", s.viewPortfolio)
+					r.Get("/portfolio/{positionId}", s.listPositionLotsDetails)
+					r.Get("/orders", s.listOrders)
+					r.Post("/orders/preview", s.previewOrder)
+					r.Post("/orders/place", s.placeOrder)
+					r.Put("/orders/cancel", s.cancelOrder)
+					r.Put("/orders/{orderId}/change/preview", s.previewOrder)
+					r.Put("/orders/{orderId}/change/place", s.placeOrder)
+				},
+			)
+			r.Get("/user/alerts", s.listAlerts)
+			r.Get("/user/alerts/{alertId}", s.listAlertDetails)
+			r.Delete("/user/alerts/{alertIds}", s.deleteAlerts)
+			r.Get("/market/quote/{symbols}", s.getQuotes)
+			r.Get("/market/lookup/{search}", s.lookupProduct)
+			r.Get("/market/optionchains", s.getOptionChains)
+			r.Get("/market/optionexpiredate", s.getOptionExpireDates)
+		},
+	)
+	s.router = r
+	return s
+}
+
+func (s *FakeETradeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, e := range s.injectedErrors {
+		if e.method == r.Method && e.path == r.URL.Path {
+			s.injectedErrors = append(s.injectedErrors[:i], s.injectedErrors[i+1:]...)
+			writeFakeError(w, e.statusCode, e.code, e.message)
+			return
+		}
+	}
+	s.router.ServeHTTP(w, r)
+}
+
+// AddAccessToken makes the server accept an access token, as if it had been
+// issued by the OAuth handshake. This lets tests skip the handshake.
+func (s *FakeETradeServer) AddAccessToken(accessToken string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.accessTokens[accessToken] = true
+}
+
+// InjectError makes the next request with the method and path fail with an
+// ETrade error payload.
+func (s *FakeETradeServer) InjectError(method string, path string, statusCode int, code int, message string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.injectedErrors = append(
+		s.injectedErrors, &fakeInjectedError{
+			method:     method,
+			path:       path,
+			statusCode: statusCode,
+			code:       code,
+			message:    message,
+		},
+	)
+}
+
+func (s *FakeETradeServer) newId() int64 {
+	s.nextId++
+	return s.nextId
+}
+
+func (s *FakeETradeServer) getRequestToken(w http.ResponseWriter, r *http.Request) {
+	params := getOAuthParameters(r)
+	if params.Get("oauth_consumer_key") != s.fixtures.ConsumerKey {
+		writeFakeOAuthProblem(w, "consumer_key_unknown")
+		return
+	}
+	token := fmt.Sprintf("requestToken%d", s.newId())
+	s.requestTokens[token] = true
+	writeFakeForm(
+		w, url.Values{
+			"oauth_token":              {token},
+			"oauth_token_secret":       {token + "Secret"},
+			"oauth_callback_confirmed": {"true"},
+		},
+	)
+}
+
+func (s *FakeETradeServer) authorizeApplication(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("key") != s.fixtures.ConsumerKey || !s.requestTokens[query.Get("token")] {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "<html><body>Verification code: %s</body></html>", s.fixtures.VerifyCode)
+}
+
+func (s *FakeETradeServer) getAccessToken(w http.ResponseWriter, r *http.Request) {
+	params := getOAuthParameters(r)
+	if params.Get("oauth_consumer_key") != s.fixtures.ConsumerKey {
+		writeFakeOAuthProblem(w, "consumer_key_unknown")
+		return
+	}
+	requestToken := params.Get("oauth_token")
+	if !s.requestTokens[requestToken] {
+		writeFakeOAuthProblem(w, "token_rejected")
+		return
+	}
+	if params.Get("oauth_verifier") != s.fixtures.VerifyCode {
+		writeFakeOAuthProblem(w, "verifier_invalid")
+		return
+	}
+	delete(s.requestTokens, requestToken)
+	token := fmt.Sprintf("accessToken%d", s.newId())
+	s.accessTokens[token] = true
+	writeFakeForm(
+		w, url.Values{
+			"oauth_token":        {token},
+			"oauth_token_secret": {token + "Secret"},
+		},
+	)
+}
+
+func (s *FakeETradeServer) renewAccessToken(w http.ResponseWriter, r *http.Request) {
+	if !s.hasAccessToken(r) {
+		writeFakeOAuthProblem(w, "token_rejected")
+		return
+	}
+	_, _ = w.Write([]byte("Access Token has been renewed"))
+}
+
+func (s *FakeETradeServer) revokeAccessToken(w http.ResponseWriter, r *http.Request) {
+	if !s.hasAccessToken(r) {
+		writeFakeOAuthProblem(w, "token_rejected")
+		return
+	}
+	delete(s.accessTokens, getOAuthParameters(r).Get("oauth_token"))
+	_, _ = w.Write([]byte("Revoked Access Token"))
+}
+
+func (s *FakeETradeServer) requireAccessToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if !s.hasAccessToken(r) {
+				writeFakeOAuthProblem(w, "token_rejected")
+				return
+			}
+			next.ServeHTTP(w, r)
+		},
+	)
+}
+
+func (s *FakeETradeServer) hasAccessToken(r *http.Request) bool {
+	params := getOAuthParameters(r)
+	return params.Get("oauth_consumer_key") == s.fixtures.ConsumerKey && s.accessTokens[params.Get("oauth_token")]
+}
+
+func (s *FakeETradeServer) listAccounts(w http.ResponseWriter, _ *http.Request) {
+	accounts := make(jsonmap.JsonSlice, 0, len(s.fixtures.Accounts))
+	for _, a := range s.fixtures.Accounts {
+		accounts = append(accounts, a.Account)
+	}
+	writeFakeJson(
+		w, jsonmap.JsonMap{
+			"AccountListResponse": jsonmap.JsonMap{
+				"Accounts": jsonmap.JsonMap{
+					"Account": accounts,
+				},
+			},
+		},
+	)
+}
+
+func (s *FakeETradeServer) getAccountBalances(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"BalanceResponse": account.Balance})
+}
+
+func (s *FakeETradeServer) listTransactions(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	query := r.URL.Query()
+	transactions := make([]jsonmap.JsonMap, len(account.Transactions))
+	copy(transactions, account.Transactions)
+	// ETrade lists the most recent transactions first by default.
+	sort.SliceStable(
+		transactions, func(i, j int) bool {
+			iDate, _ := transactions[i].GetIntWithDefault("transactionDate", 0)
+			jDate, _ := transactions[j].GetIntWithDefault("transactionDate", 0)
+			if query.Get("sortOrder") == "ASC" {
+				return iDate < jDate
+			}
+			return iDate > jDate
+		},
+	)
+	start, end, nextMarker, ok := getFakePage(w, query, fakeTransactionsDefaultCount, len(transactions))
+	if !ok {
+		return
+	}
+	transactionsSlice := make(jsonmap.JsonSlice, 0, end-start)
+	for _, transaction := range transactions[start:end] {
+		// ETrade lists transaction IDs as strings but details them as
+		// numbers.
+		listed := copyFakeJsonMap(transaction)
+		listed["transactionId"] = getFakeId(transaction, "transactionId")
+		transactionsSlice = append(transactionsSlice, listed)
+	}
+	response := jsonmap.JsonMap{
+		"transactionCount": len(transactionsSlice),
+		"totalCount":       len(transactions),
+		"moreTransactions": nextMarker != "",
+		"Transaction":      transactionsSlice,
+	}
+	if nextMarker != "" {
+		response["marker"] = nextMarker
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"TransactionListResponse": response})
+}
+
+func (s *FakeETradeServer) listTransactionDetails(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	transaction := findFakeById(account.Transactions, "transactionId", chi.URLParam(r, "transactionId"))
+	if transaction == nil {
+		writeFakeError(w, http.StatusNotFound, fakeErrorCodeNotFound, "The transaction was not found.")
+		return
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"TransactionDetailsResponse": transaction})
+}
+
+func (s *FakeETradeServer) viewPortfolio(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	query := r.URL.Query()
+	count, ok := getFakeCount(w, query, fakePortfolioDefaultCount)
+	if !ok {
+		return
+	}
+	page := 1
+	if query.Has("pageNumber") {
+		var err error
+		if page, err = strconv.Atoi(query.Get("pageNumber")); err != nil || page < 1 {
+			writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, "The page number is invalid.")
+			return
+		}
+	}
+	start := (page - 1) * count
+	if start > len(account.Positions) {
+		start = len(account.Positions)
+	}
+	end := start + count
+	if end > len(account.Positions) {
+		end = len(account.Positions)
+	}
+	positions := make(jsonmap.JsonSlice, 0, end-start)
+	for _, position := range account.Positions[start:end] {
+		positions = append(positions, position)
+	}
+	accountId, _ := account.Account.GetStringWithDefault("accountId", "")
+	accountPortfolio := jsonmap.JsonMap{
+		"accountId":  accountId,
+		"Position":   positions,
+		"totalPages": (len(account.Positions) + count - 1) / count,
+	}
+	if end < len(account.Positions) {
+		accountPortfolio["nextPageNo"] = strconv.Itoa(page + 1)
+	}
+	response := jsonmap.JsonMap{
+		"AccountPortfolio": jsonmap.JsonSlice{accountPortfolio},
+	}
+	if query.Get("totalsRequired") == "true" && account.Totals != nil {
+		response["Totals"] = account.Totals
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"PortfolioResponse": response})
+}
+
+func (s *FakeETradeServer) listPositionLotsDetails(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	positionId := chi.URLParam(r, "positionId")
+	if findFakeById(account.Positions, "positionId", positionId) == nil {
+		writeFakeError(w, http.StatusNotFound, fakeErrorCodeNotFound, "The position was not found.")
+		return
+	}
+	lots := account.Lots[positionId]
+	if lots == nil {
+		lots = jsonmap.JsonSlice{}
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"PositionLotsResponse": jsonmap.JsonMap{"PositionLot": lots}})
+}
+
+func (s *FakeETradeServer) listOrders(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	query := r.URL.Query()
+	orders := make([]jsonmap.JsonMap, 0, len(account.Orders))
+	for _, order := range account.Orders {
+		if !query.Has("status") || getFakeOrderStatus(order) == query.Get("status") {
+			orders = append(orders, order)
+		}
+	}
+	start, end, nextMarker, ok := getFakePage(w, query, fakeOrdersDefaultCount, len(orders))
+	if !ok {
+		return
+	}
+	ordersSlice := make(jsonmap.JsonSlice, 0, end-start)
+	for _, order := range orders[start:end] {
+		ordersSlice = append(ordersSlice, order)
+	}
+	response := jsonmap.JsonMap{"Order": ordersSlice}
+	if nextMarker != "" {
+		response["marker"] = nextMarker
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"OrdersResponse": response})
+}
+
+// previewOrder previews a new order or, if the path has an order ID, a change
+// to an open order.
+func (s *FakeETradeServer) previewOrder(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	orderId := chi.URLParam(r, "orderId")
+	if orderId != "" && s.getOpenOrder(w, account, orderId) == nil {
+		return
+	}
+	request := getFakeRequestBody(w, r, "PreviewOrderRequest")
+	if request == nil {
+		return
+	}
+	requestBytes, err := request.ToJsonBytes(false, false)
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	previewId := s.newId()
+	accountIdKey := chi.URLParam(r, "accountIdKey")
+	s.previews[strconv.FormatInt(previewId, 10)] = &fakePreview{
+		accountIdKey: accountIdKey,
+		orderId:      orderId,
+		request:      requestBytes,
+	}
+	response := s.newFakeOrderResponse(account, request)
+	response["previewTime"] = s.now().UnixMilli()
+	response["PreviewIds"] = jsonmap.JsonSlice{
+		jsonmap.JsonMap{"previewId": previewId},
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"PreviewOrderResponse": response})
+}
+
+// placeOrder places a previewed order or, if the path has an order ID, a
+// previewed change to an open order. The order must match its preview.
+func (s *FakeETradeServer) placeOrder(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	orderId := chi.URLParam(r, "orderId")
+	var existing jsonmap.JsonMap
+	if orderId != "" {
+		if existing = s.getOpenOrder(w, account, orderId); existing == nil {
+			return
+		}
+	}
+	request := getFakeRequestBody(w, r, "PlaceOrderRequest")
+	if request == nil {
+		return
+	}
+	previewId, err := request.GetIntAtPath(".PreviewIds[0].previewId")
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidPreview, "The preview ID is missing.")
+		return
+	}
+	delete(request, "PreviewIds")
+	requestBytes, err := request.ToJsonBytes(false, false)
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, err.Error())
+		return
+	}
+	previewKey := strconv.FormatInt(previewId, 10)
+	preview := s.previews[previewKey]
+	if preview == nil || preview.accountIdKey != chi.URLParam(r, "accountIdKey") || preview.orderId != orderId ||
+		!bytes.Equal(preview.request, requestBytes) {
+		writeFakeError(
+			w, http.StatusBadRequest, fakeErrorCodeInvalidPreview, "The order does not match a previewed order.",
+		)
+		return
+	}
+	delete(s.previews, previewKey)
+
+	placedTime := s.now().UnixMilli()
+	order := s.newFakeOrder(request, placedTime)
+	if existing != nil {
+		// The change replaces the order but keeps its ID.
+		order["orderId"] = existing["orderId"]
+		for key := range existing {
+			delete(existing, key)
+		}
+		for key, value := range order {
+			existing[key] = value
+		}
+	} else {
+		// ETrade lists the most recent orders first.
+		account.Orders = append([]jsonmap.JsonMap{order}, account.Orders...)
+	}
+	response := s.newFakeOrderResponse(account, request)
+	response["placedTime"] = placedTime
+	response["OrderIds"] = jsonmap.JsonSlice{
+		jsonmap.JsonMap{"orderId": order["orderId"]},
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"PlaceOrderResponse": response})
+}
+
+func (s *FakeETradeServer) cancelOrder(w http.ResponseWriter, r *http.Request) {
+	account := s.getAccount(w, r)
+	if account == nil {
+		return
+	}
+	request := getFakeRequestBody(w, r, "CancelOrderRequest")
+	if request == nil {
+		return
+	}
+	orderId, err := request.GetInt("orderId")
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, "The order ID is missing.")
+		return
+	}
+	order := s.getOpenOrder(w, account, strconv.FormatInt(orderId, 10))
+	if order == nil {
+		return
+	}
+	orderDetails, _ := order.GetSliceOfMapsWithDefault("OrderDetail", nil)
+	for _, orderDetail := range orderDetails {
+		orderDetail["status"] = "CANCELLED"
+	}
+	accountId, _ := account.Account.GetStringWithDefault("accountId", "")
+	writeFakeJson(
+		w, jsonmap.JsonMap{
+			"CancelOrderResponse": jsonmap.JsonMap{
+				"accountId":  accountId,
+				"orderId":    orderId,
+				"cancelTime": s.now().UnixMilli(),
+				"Messages": jsonmap.JsonMap{
+					"Message": jsonmap.JsonSlice{
+						jsonmap.JsonMap{
+							"code":        5011,
+							"description": "Your request to cancel your order is being processed.",
+							"type":        "WARNING",
+						},
+					},
+				},
+			},
+		},
+	)
+}
+
+func (s *FakeETradeServer) listAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	count, ok := getFakeCount(w, query, fakeAlertsDefaultCount)
+	if !ok {
+		return
+	}
+	alerts := jsonmap.JsonSlice{}
+	for _, alert := range s.fixtures.Alerts {
+		if len(alerts) >= count {
+			break
+		}
+		status, _ := alert.GetStringWithDefault("status", "")
+		if query.Has("status") && status != query.Get("status") {
+			continue
+		}
+		alerts = append(
+			alerts, jsonmap.JsonMap{
+				"id":         alert["id"],
+				"createTime": alert["createTime"],
+				"subject":    alert["subject"],
+				"status":     status,
+			},
+		)
+	}
+	writeFakeJson(
+		w, jsonmap.JsonMap{
+			"AlertsResponse": jsonmap.JsonMap{
+				"totalAlerts": len(alerts),
+				"Alert":       alerts,
+			},
+		},
+	)
+}
+
+func (s *FakeETradeServer) listAlertDetails(w http.ResponseWriter, r *http.Request) {
+	alert := findFakeById(s.fixtures.Alerts, "id", chi.URLParam(r, "alertId"))
+	if alert == nil {
+		writeFakeError(w, http.StatusNotFound, fakeErrorCodeNotFound, "The alert was not found.")
+		return
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"AlertDetailsResponse": alert})
+}
+
+func (s *FakeETradeServer) deleteAlerts(w http.ResponseWriter, r *http.Request) {
+	failedAlerts := jsonmap.JsonSlice{}
+	for _, alertId := range strings.Split(chi.URLParam(r, "alertIds"), ",") {
+		alert := findFakeById(s.fixtures.Alerts, "id", alertId)
+		if alert == nil {
+			id, err := strconv.ParseInt(alertId, 10, 64)
+			if err != nil {
+				writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, "The alert ID is invalid.")
+				return
+			}
+			failedAlerts = append(failedAlerts, id)
+			continue
+		}
+		alerts := make([]jsonmap.JsonMap, 0, len(s.fixtures.Alerts))
+		for _, a := range s.fixtures.Alerts {
+			if getFakeId(a, "id") != alertId {
+				alerts = append(alerts, a)
+			}
+		}
+		s.fixtures.Alerts = alerts
+	}
+	response := jsonmap.JsonMap{"result": "SUCCESS"}
+	if len(failedAlerts) > 0 {
+		response["result"] = "ERROR"
+		response["failedAlerts"] = jsonmap.JsonMap{"alertId": failedAlerts}
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"AlertsResponse": response})
+}
+
+func (s *FakeETradeServer) getQuotes(w http.ResponseWriter, r *http.Request) {
+	symbols := strings.Split(chi.URLParam(r, "symbols"), ",")
+	if len(symbols) > fakeQuotesMaxSymbols {
+		writeFakeError(
+			w, http.StatusBadRequest, fakeErrorCodeInvalidRequest,
+			fmt.Sprintf("A maximum of %d symbols may be requested.", fakeQuotesMaxSymbols),
+		)
+		return
+	}
+	quotes := jsonmap.JsonSlice{}
+	messages := jsonmap.JsonSlice{}
+	for _, symbol := range symbols {
+		quote := s.findQuote(symbol)
+		if quote == nil {
+			messages = append(
+				messages, jsonmap.JsonMap{
+					"description": fmt.Sprintf("%s is not a valid symbol", symbol),
+					"code":        fakeErrorCodeInvalidSymbol,
+					"type":        "WARNING",
+				},
+			)
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+	response := jsonmap.JsonMap{"QuoteData": quotes}
+	if len(messages) > 0 {
+		response["Messages"] = jsonmap.JsonMap{"Message": messages}
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"QuoteResponse": response})
+}
+
+func (s *FakeETradeServer) findQuote(symbol string) jsonmap.JsonMap {
+	for _, quote := range s.fixtures.Quotes {
+		quoteSymbol, _ := quote.GetStringAtPathWithDefault(".Product.symbol", "")
+		if strings.EqualFold(quoteSymbol, symbol) {
+			return quote
+		}
+	}
+	return nil
+}
+
+func (s *FakeETradeServer) lookupProduct(w http.ResponseWriter, r *http.Request) {
+	search := strings.ToUpper(chi.URLParam(r, "search"))
+	results := jsonmap.JsonSlice{}
+	for _, result := range s.fixtures.Lookup {
+		symbol, _ := result.GetStringWithDefault("symbol", "")
+		description, _ := result.GetStringWithDefault("description", "")
+		if strings.HasPrefix(strings.ToUpper(symbol), search) || strings.Contains(
+			strings.ToUpper(description), search,
+		) {
+			results = append(results, result)
+		}
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"LookupResponse": jsonmap.JsonMap{"Data": results}})
+}
+
+func (s *FakeETradeServer) getOptionChains(w http.ResponseWriter, r *http.Request) {
+	optionChain, found := s.fixtures.OptionChains[strings.ToUpper(r.URL.Query().Get("symbol"))]
+	if !found {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidSymbol, "The symbol is invalid.")
+		return
+	}
+	writeFakeJson(w, jsonmap.JsonMap{"OptionChainResponse": optionChain})
+}
+
+func (s *FakeETradeServer) getOptionExpireDates(w http.ResponseWriter, r *http.Request) {
+	expireDates, found := s.fixtures.OptionExpireDates[strings.ToUpper(r.URL.Query().Get("symbol"))]
+	if !found {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidSymbol, "The symbol is invalid.")
+		return
+	}
+	writeFakeJson(
+		w, jsonmap.JsonMap{
+			"OptionExpireDateResponse": jsonmap.JsonMap{
+				"ExpirationDate": expireDates,
+			},
+		},
+	)
+}
+
+// getAccount returns the account in the request path. If there's no such
+// account, it writes an error and returns nil.
+func (s *FakeETradeServer) getAccount(w http.ResponseWriter, r *http.Request) *FakeETradeAccount {
+	accountIdKey := chi.URLParam(r, "accountIdKey")
+	for _, account := range s.fixtures.Accounts {
+		if key, _ := account.Account.GetStringWithDefault("accountIdKey", ""); key == accountIdKey {
+			return account
+		}
+	}
+	writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidAccount, "Please enter a valid account key.")
+	return nil
+}
+
+// getOpenOrder returns an open order in the account. If there's no such
+// order, it writes an error and returns nil.
+func (s *FakeETradeServer) getOpenOrder(
+	w http.ResponseWriter, account *FakeETradeAccount, orderId string,
+) jsonmap.JsonMap {
+	order := findFakeById(account.Orders, "orderId", orderId)
+	if order == nil {
+		writeFakeError(w, http.StatusNotFound, fakeErrorCodeNotFound, "The order was not found.")
+		return nil
+	}
+	if getFakeOrderStatus(order) != "OPEN" {
+		writeFakeError(
+			w, http.StatusBadRequest, fakeErrorCodeOrderNotOpen, "The order can no longer be changed or canceled.",
+		)
+		return nil
+	}
+	return order
+}
+
+// newFakeOrder creates an open order, as ETrade lists it, from an order
+// request.
+func (s *FakeETradeServer) newFakeOrder(request jsonmap.JsonMap, placedTime int64) jsonmap.JsonMap {
+	orderType, _ := request.GetStringWithDefault("orderType", "")
+	requestOrders, _ := request.GetSliceOfMapsWithDefault("Order", nil)
+	orderDetails := make(jsonmap.JsonSlice, 0, len(requestOrders))
+	for _, requestOrder := range requestOrders {
+		orderDetail := copyFakeJsonMap(requestOrder)
+		orderDetail["status"] = "OPEN"
+		orderDetail["placedTime"] = placedTime
+		instruments, _ := orderDetail.GetSliceOfMapsWithDefault("Instrument", nil)
+		listedInstruments := make(jsonmap.JsonSlice, 0, len(instruments))
+		for _, instrument := range instruments {
+			listedInstrument := copyFakeJsonMap(instrument)
+			listedInstrument["orderedQuantity"] = instrument["quantity"]
+			listedInstrument["filledQuantity"] = 0
+			listedInstruments = append(listedInstruments, listedInstrument)
+		}
+		orderDetail["Instrument"] = listedInstruments
+		orderDetails = append(orderDetails, orderDetail)
+	}
+	return jsonmap.JsonMap{
+		"orderId":     s.newId(),
+		"orderType":   orderType,
+		"OrderDetail": orderDetails,
+	}
+}
+
+// newFakeOrderResponse creates the part of a preview or place order response
+// that echoes the request.
+func (s *FakeETradeServer) newFakeOrderResponse(account *FakeETradeAccount, request jsonmap.JsonMap) jsonmap.JsonMap {
+	accountId, _ := account.Account.GetStringWithDefault("accountId", "")
+	response := jsonmap.JsonMap{
+		"accountId": accountId,
+		"orderType": request["orderType"],
+		"Order":     request["Order"],
+	}
+	if clientOrderId, found := request["clientOrderId"]; found {
+		response["clientOrderId"] = clientOrderId
+	}
+	return response
+}
+
+func getFakeOrderStatus(order jsonmap.JsonMap) string {
+	status, _ := order.GetStringAtPathWithDefault(".OrderDetail[0].status", "")
+	return status
+}
+
+// getOAuthParameters returns the parameters from the OAuth authorization
+// header of a request.
+func getOAuthParameters(r *http.Request) url.Values {
+	params := url.Values{}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "OAuth ") {
+		return params
+	}
+	for _, param := range strings.Split(strings.TrimPrefix(header, "OAuth "), ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params.Set(key, value)
+	}
+	return params
+}
+
+// getFakeRequestBody returns the object with the key in the request body. If
+// the body is invalid, it writes an error and returns nil.
+func getFakeRequestBody(w http.ResponseWriter, r *http.Request, key string) jsonmap.JsonMap {
+	body, err := jsonmap.NewJsonMapFromIoReader(r.Body)
+	if err == nil {
+		var request jsonmap.JsonMap
+		if request, err = body.GetMap(key); err == nil && request != nil {
+			return request
+		}
+	}
+	writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, "The request body is invalid.")
+	return nil
+}
+
+// getFakeCount returns the count in the query. If the count is invalid, it
+// writes an error and returns false.
+func getFakeCount(w http.ResponseWriter, query url.Values, defaultCount int) (int, bool) {
+	if !query.Has("count") {
+		return defaultCount, true
+	}
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil || count < 0 {
+		writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, "The count is invalid.")
+		return 0, false
+	}
+	if count == 0 {
+		count = defaultCount
+	}
+	return count, true
+}
+
+// getFakePage returns the start and end of the page of items selected by the
+// marker and count in the query, and the marker for the next page (which is
+// empty if there are no more pages). Markers are the index of the first item
+// on the page. If the query is invalid, it writes an error and returns false.
+func getFakePage(w http.ResponseWriter, query url.Values, defaultCount int, total int) (int, int, string, bool) {
+	count, ok := getFakeCount(w, query, defaultCount)
+	if !ok {
+		return 0, 0, "", false
+	}
+	start := 0
+	if query.Has("marker") {
+		var err error
+		if start, err = strconv.Atoi(query.Get("marker")); err != nil || start < 0 {
+			writeFakeError(w, http.StatusBadRequest, fakeErrorCodeInvalidRequest, "The marker is invalid.")
+			return 0, 0, "", false
+		}
+	}
+	if start > total {
+		start = total
+	}
+	end := start + count
+	if end >= total {
+		return start, total, "", true
+	}
+	return start, end, strconv.Itoa(end), true
+}
+
+func findFakeById(items []jsonmap.JsonMap, idKey string, id string) jsonmap.JsonMap {
+	for _, item := range items {
+		if getFakeId(item, idKey) == id {
+			return item
+		}
+	}
+	return nil
+}
+
+func getFakeId(item jsonmap.JsonMap, idKey string) string {
+	id, found := item[idKey]
+	if !found {
+		return ""
+	}
+	return fmt.Sprint(id)
+}
+
+func copyFakeJsonMap(m jsonmap.JsonMap) jsonmap.JsonMap {
+	copied := make(jsonmap.JsonMap, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+func writeFakeJson(w http.ResponseWriter, response jsonmap.JsonMap) {
+	writeFakeJsonWithStatus(w, http.StatusOK, response)
+}
+
+func writeFakeJsonWithStatus(w http.ResponseWriter, statusCode int, response jsonmap.JsonMap) {
+	responseBytes, err := response.ToJsonBytes(false, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(responseBytes)
+}
+
+// writeFakeError writes an ETrade error payload.
+func writeFakeError(w http.ResponseWriter, statusCode int, code int, message string) {
+	writeFakeJsonWithStatus(
+		w, statusCode, jsonmap.JsonMap{
+			"Error": jsonmap.JsonMap{
+				"code":    code,
+				"message": message,
+			},
+		},
+	)
+}
+
+// writeFakeOAuthProblem writes the response to a request that fails OAuth
+// authentication.
+func writeFakeOAuthProblem(w http.ResponseWriter, problem string) {
+	w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = w.Write([]byte("oauth_problem=" + problem))
+}
+
+func writeFakeForm(w http.ResponseWriter, values url.Values) {
+	w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(values.Encode()))
+}
@@ -0,0 +1,414 @@
+package etradelibtest
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testFakeConsumerKey    = "fakeConsumerKey"
+	testFakeConsumerSecret = "fakeConsumerSecret"
+	testFakeAccessToken    = "testAccessToken"
+	testFakeAccountIdKey   = "fakeAccountKey1"
+)
+
+func TestFakeETradeServer_Authentication(t *testing.T) {
+	fake := NewFakeETradeServer(CreateDefaultFakeETradeFixtures())
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	// An unknown consumer key can't authenticate.
+	badClient, err := client.CreateETradeClientWithHttpClientWrapper(
+		CreateNullLogger(), newTestFakeEndpointProfile(server.URL), "badKey", testFakeConsumerSecret, "", "", nil,
+	)
+	require.Nil(t, err)
+	_, err = badClient.Authenticate()
+	assert.Error(t, err)
+
+	// Call the Method Under Test
+	eTradeClient, err := client.CreateETradeClientWithHttpClientWrapper(
+		CreateNullLogger(), newTestFakeEndpointProfile(server.URL), testFakeConsumerKey, testFakeConsumerSecret, "", "",
+		nil,
+	)
+	require.Nil(t, err)
+	response, err := eTradeClient.Authenticate()
+	require.Nil(t, err)
+	authStatus, err := etradelib.CreateETradeAuthenticationStatusFromResponse(response)
+	require.Nil(t, err)
+	require.True(t, authStatus.NeedAuthorization())
+
+	// The authorization page shows the verify code.
+	require.True(t, strings.HasPrefix(authStatus.GetAuthorizationUrl(), server.URL+"/e/t/etws/authorize?"))
+	authorizationResponse, err := http.Get(authStatus.GetAuthorizationUrl())
+	require.Nil(t, err)
+	authorizationPage, err := io.ReadAll(authorizationResponse.Body)
+	_ = authorizationResponse.Body.Close()
+	require.Nil(t, err)
+	assert.Contains(t, string(authorizationPage), "FAKE1")
+
+	// Call the Method Under Test
+	_, err = eTradeClient.Verify("WRONG")
+	assert.Error(t, err)
+	_, err = eTradeClient.Verify("FAKE1")
+	require.Nil(t, err)
+	_, err = eTradeClient.ListAccounts()
+	assert.Nil(t, err)
+
+	// A client with the cached access token renews it instead of
+	// re-authenticating.
+	_, _, accessToken, accessSecret := eTradeClient.GetKeys()
+	cachedClient, err := client.CreateETradeClientWithHttpClientWrapper(
+		CreateNullLogger(), newTestFakeEndpointProfile(server.URL), testFakeConsumerKey, testFakeConsumerSecret,
+		accessToken, accessSecret, nil,
+	)
+	require.Nil(t, err)
+	response, err = cachedClient.Authenticate()
+	require.Nil(t, err)
+	authStatus, err = etradelib.CreateETradeAuthenticationStatusFromResponse(response)
+	require.Nil(t, err)
+	assert.False(t, authStatus.NeedAuthorization())
+
+	// An unknown access token is rejected.
+	unknownClient, err := client.CreateETradeClientWithHttpClientWrapper(
+		CreateNullLogger(), newTestFakeEndpointProfile(server.URL), testFakeConsumerKey, testFakeConsumerSecret,
+		"unknown", "unknown", nil,
+	)
+	require.Nil(t, err)
+	_, err = unknownClient.ListAccounts()
+	assert.True(t, client.IsAuthFailed(err))
+}
+
+func TestFakeETradeServer(t *testing.T) {
+	type testFn func(t *testing.T, c client.ETradeClient, fake *FakeETradeServer)
+
+	tests := []struct {
+		name   string
+		testFn testFn
+	}{
+		{
+			name: "Lists Accounts And Balances",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				response, err := c.ListAccounts()
+				require.Nil(t, err)
+				accountList, err := etradelib.CreateETradeAccountListFromResponse(response)
+				require.Nil(t, err)
+				require.Len(t, accountList.GetAllAccounts(), 2)
+				assert.Equal(t, testFakeAccountIdKey, accountList.GetAccountById("11111111").GetIdKey())
+
+				response, err = c.GetAccountBalances(testFakeAccountIdKey, true)
+				require.Nil(t, err)
+				balances, err := etradelib.CreateETradeBalancesFromResponse(response)
+				require.Nil(t, err)
+				balancesMap := balances.AsJsonMap()
+				assert.Equal(t, "11111111", getTestString(t, balancesMap, ".accountId"))
+
+				_, err = c.GetAccountBalances("unknown", true)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Pages Transactions",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				response, err := c.ListTransactions(testFakeAccountIdKey, nil, nil, constants.SortOrderNil, "", 2)
+				require.Nil(t, err)
+				transactionList, err := etradelib.CreateETradeTransactionListFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, "2", transactionList.NextPage())
+
+				response, err = c.ListTransactions(
+					testFakeAccountIdKey, nil, nil, constants.SortOrderNil, transactionList.NextPage(), 2,
+				)
+				require.Nil(t, err)
+				require.Nil(t, transactionList.AddPageFromResponse(response))
+				assert.Equal(t, "", transactionList.NextPage())
+
+				// The most recent transactions are listed first.
+				var transactionIds []string
+				for _, transaction := range transactionList.GetAllTransactions() {
+					transactionIds = append(transactionIds, transaction.GetId())
+				}
+				assert.Equal(t, []string{"4003", "4002", "4001"}, transactionIds)
+
+				response, err = c.ListTransactionDetails(testFakeAccountIdKey, "4001")
+				require.Nil(t, err)
+				transactionDetails, err := etradelib.CreateETradeTransactionDetailsFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, int64(4001), transactionDetails.GetId())
+
+				_, err = c.ListTransactionDetails(testFakeAccountIdKey, "9999")
+				assert.Error(t, err)
+				_, err = c.ListTransactions(testFakeAccountIdKey, nil, nil, constants.SortOrderNil, "bad", 2)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Pages Portfolio With Lots",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				response, err := c.ViewPortfolio(
+					testFakeAccountIdKey, 1, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+					constants.MarketSessionNil, true, true, constants.PortfolioViewNil,
+				)
+				require.Nil(t, err)
+				positionList, err := etradelib.CreateETradePositionListFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, "2", positionList.NextPage())
+
+				response, err = c.ViewPortfolio(
+					testFakeAccountIdKey, 1, constants.PortfolioSortByNil, constants.SortOrderNil,
+					positionList.NextPage(), constants.MarketSessionNil, true, true, constants.PortfolioViewNil,
+				)
+				require.Nil(t, err)
+				require.Nil(t, positionList.AddPageFromResponse(response))
+				assert.Equal(t, "", positionList.NextPage())
+				require.Len(t, positionList.GetAllPositions(), 2)
+				assert.Equal(t, 6000.0, getTestFloat(t, positionList.AsJsonMap(), ".totals.totalMarketValue"))
+
+				position := positionList.GetPositionById(1001)
+				require.NotNil(t, position)
+				response, err = c.ListPositionLotsDetails(testFakeAccountIdKey, 1001)
+				require.Nil(t, err)
+				require.Nil(t, position.AddLotsFromResponse(response))
+				positionMap := position.AsJsonMap()
+				lots, err := positionMap.GetSliceAtPath(etradelib.PositionLotsPath)
+				require.Nil(t, err)
+				assert.Len(t, lots, 2)
+
+				_, err = c.ListPositionLotsDetails(testFakeAccountIdKey, 9999)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Places, Changes, And Cancels Orders",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				request := newTestOrderRequest(150)
+				response, err := c.PreviewOrder(testFakeAccountIdKey, request)
+				require.Nil(t, err)
+				preview, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+				require.Nil(t, err)
+
+				// Orders must match their previews.
+				_, err = c.PlaceOrder(testFakeAccountIdKey, preview.GetPreviewId(), newTestOrderRequest(151))
+				assert.Error(t, err)
+
+				response, err = c.PlaceOrder(testFakeAccountIdKey, preview.GetPreviewId(), request)
+				require.Nil(t, err)
+				placeOrder, err := etradelib.CreateETradePlaceOrderFromResponse(response)
+				require.Nil(t, err)
+				orderId := placeOrder.GetOrderId()
+				assert.Equal(t, "OPEN", getTestOrderStatus(t, c, orderId))
+
+				// Previews can only be placed once.
+				_, err = c.PlaceOrder(testFakeAccountIdKey, preview.GetPreviewId(), request)
+				assert.Error(t, err)
+
+				changeRequest := newTestOrderRequest(155)
+				response, err = c.PreviewChangedOrder(testFakeAccountIdKey, orderId, changeRequest)
+				require.Nil(t, err)
+				preview, err = etradelib.CreateETradePreviewOrderFromResponse(response)
+				require.Nil(t, err)
+				response, err = c.PlaceChangedOrder(
+					testFakeAccountIdKey, orderId, preview.GetPreviewId(), changeRequest,
+				)
+				require.Nil(t, err)
+				placeOrder, err = etradelib.CreateETradePlaceOrderFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, orderId, placeOrder.GetOrderId())
+
+				response, err = c.CancelOrder(testFakeAccountIdKey, orderId)
+				require.Nil(t, err)
+				cancelResult, err := etradelib.CreateETradeCancelOrderResultFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, orderId, cancelResult.GetOrderId())
+				assert.Equal(t, "CANCELLED", getTestOrderStatus(t, c, orderId))
+
+				// Canceled orders can't be canceled again.
+				_, err = c.CancelOrder(testFakeAccountIdKey, orderId)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Filters Orders By Status",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				response, err := c.ListOrders(
+					testFakeAccountIdKey, "", -1, constants.OrderStatusOpen, nil, nil, nil,
+					constants.OrderSecurityTypeNil, constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+				)
+				require.Nil(t, err)
+				orderList, err := etradelib.CreateETradeOrderListFromResponse(response)
+				require.Nil(t, err)
+				require.Len(t, orderList.GetAllOrders(), 1)
+				assert.Equal(t, int64(5002), orderList.GetAllOrders()[0].GetId())
+			},
+		},
+		{
+			name: "Lists And Deletes Alerts",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				response, err := c.ListAlerts(
+					-1, constants.AlertCategoryNil, constants.AlertStatusNil, constants.SortOrderNil, "",
+				)
+				require.Nil(t, err)
+				alertList, err := etradelib.CreateETradeAlertListFromResponse(response)
+				require.Nil(t, err)
+				assert.Len(t, alertList.GetAllAlerts(), 2)
+
+				response, err = c.ListAlertDetails("6001", false)
+				require.Nil(t, err)
+				alertDetails, err := etradelib.CreateETradeAlertDetailsFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, "AAPL", getTestString(t, alertDetails.AsJsonMap(), ".symbol"))
+
+				response, err = c.DeleteAlerts([]string{"6001", "9999"})
+				require.Nil(t, err)
+				deleteAlerts, err := etradelib.CreateETradeDeleteAlertsFromResponse(response)
+				require.Nil(t, err)
+				assert.False(t, deleteAlerts.IsSuccess())
+				assert.Equal(t, []int64{9999}, deleteAlerts.GetFailedAlerts())
+
+				_, err = c.ListAlertDetails("6001", false)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Gets Market Data",
+			testFn: func(t *testing.T, c client.ETradeClient, _ *FakeETradeServer) {
+				response, err := c.GetQuotes(
+					[]string{"AAPL", "XYZ"}, constants.QuoteDetailFlagAll, false, false,
+				)
+				require.Nil(t, err)
+				quoteList, err := etradelib.CreateETradeQuoteListFromResponse(response)
+				require.Nil(t, err)
+				assert.Len(t, quoteList.GetAllQuotes(), 1)
+				quoteListMap := quoteList.AsJsonMap()
+				messages, err := quoteListMap.GetSliceAtPath(etradelib.QuoteListMessagesPath)
+				require.Nil(t, err)
+				assert.Len(t, messages, 1)
+
+				response, err = c.LookupProduct("micro")
+				require.Nil(t, err)
+				lookupResults, err := etradelib.CreateETradeLookupResultListFromResponse(response)
+				require.Nil(t, err)
+				assert.Len(t, lookupResults.GetAllResults(), 1)
+
+				response, err = c.GetOptionChains(
+					"AAPL", -1, -1, -1, -1, -1, false, false, constants.OptionCategoryNil,
+					constants.OptionChainTypeNil, constants.OptionPriceTypeNil,
+				)
+				require.Nil(t, err)
+				optionChains, err := etradelib.CreateETradeOptionChainPairListFromResponse(response)
+				require.Nil(t, err)
+				assert.Len(t, optionChains.GetAllOptionChainPairs(), 2)
+
+				response, err = c.GetOptionExpireDates("AAPL", constants.OptionExpiryTypeNil)
+				require.Nil(t, err)
+				expireDates, err := etradelib.CreateETradeOptionExpireDateListFromResponse(response)
+				require.Nil(t, err)
+				assert.Len(t, expireDates.GetAllOptionExpireDates(), 3)
+
+				_, err = c.GetOptionExpireDates("XYZ", constants.OptionExpiryTypeNil)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Fails Requests With Injected Errors",
+			testFn: func(t *testing.T, c client.ETradeClient, fake *FakeETradeServer) {
+				fake.InjectError("GET", "/v1/accounts/list", http.StatusBadRequest, 9999, "Bad Request")
+				_, err := c.ListAccounts()
+				assert.Error(t, err)
+				_, err = c.ListAccounts()
+				assert.Nil(t, err)
+
+				// The client retries transient errors.
+				fake.InjectError("GET", "/v1/accounts/list", http.StatusServiceUnavailable, 9999, "Unavailable")
+				_, err = c.ListAccounts()
+				assert.Nil(t, err)
+
+				fake.InjectError("GET", "/v1/accounts/list", http.StatusUnauthorized, 9999, "Unauthorized")
+				_, err = c.ListAccounts()
+				assert.True(t, client.IsAuthFailed(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				fake := NewFakeETradeServer(CreateDefaultFakeETradeFixtures())
+				fake.AddAccessToken(testFakeAccessToken)
+				server := httptest.NewServer(fake)
+				defer server.Close()
+				eTradeClient, err := client.CreateETradeClientWithHttpClientWrapper(
+					CreateNullLogger(), newTestFakeEndpointProfile(server.URL), testFakeConsumerKey,
+					testFakeConsumerSecret, testFakeAccessToken, "secret", nil,
+				)
+				require.Nil(t, err)
+				// Call the Method Under Test
+				tt.testFn(t, eTradeClient, fake)
+			},
+		)
+	}
+}
+
+func newTestOrderRequest(limitPrice float64) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"orderType":     "EQ",
+		"clientOrderId": "test",
+		"Order": jsonmap.JsonSlice{
+			jsonmap.JsonMap{
+				"allOrNone":     false,
+				"priceType":     "LIMIT",
+				"orderTerm":     "GOOD_FOR_DAY",
+				"marketSession": "REGULAR",
+				"limitPrice":    limitPrice,
+				"Instrument": jsonmap.JsonSlice{
+					jsonmap.JsonMap{
+						"Product": jsonmap.JsonMap{
+							"securityType": "EQ",
+							"symbol":       "AAPL",
+						},
+						"orderAction":  "BUY",
+						"quantityType": "QUANTITY",
+						"quantity":     10,
+					},
+				},
+			},
+		},
+	}
+}
+
+func getTestOrderStatus(t *testing.T, c client.ETradeClient, orderId int64) string {
+	response, err := c.ListOrders(
+		testFakeAccountIdKey, "", -1, constants.OrderStatusNil, nil, nil, nil, constants.OrderSecurityTypeNil,
+		constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+	)
+	require.Nil(t, err)
+	orderList, err := etradelib.CreateETradeOrderListFromResponse(response)
+	require.Nil(t, err)
+	order := orderList.GetOrderById(orderId)
+	require.NotNil(t, order)
+	return getTestString(t, order.AsJsonMap(), ".orderDetail[0].status")
+}
+
+func getTestString(t *testing.T, m jsonmap.JsonMap, path string) string {
+	value, err := m.GetStringAtPath(path)
+	require.Nil(t, err)
+	return value
+}
+
+func getTestFloat(t *testing.T, m jsonmap.JsonMap, path string) float64 {
+	value, err := m.GetFloatAtPath(path)
+	require.Nil(t, err)
+	return value
+}
+
+func newTestFakeEndpointProfile(urlBase string) *client.EndpointProfile {
+	return &client.EndpointProfile{Environment: client.EndpointEnvironmentCustom, UrlBase: urlBase}
+}
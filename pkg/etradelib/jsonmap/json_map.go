@@ -76,3 +76,14 @@ func (m *JsonMap) ToJsonString(pretty bool, escapeHtml bool) (string, error) {
 	}
 	return byteBuffer.String(), nil
 }
+
+// Decode unmarshals the map into the value pointed to by v (e.g. a struct with
+// json tags). It returns an error if a value in the map can't be stored in
+// the corresponding field.
+func (m *JsonMap) Decode(v interface{}) error {
+	jsonBytes, err := m.ToJsonBytes(false, false)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonBytes, v)
+}
@@ -197,3 +197,61 @@ func TestJsonMap_ToJsonBytes(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, []byte(expectedJsonStringEscapeHtml), actualValue)
 }
+
+func TestJsonMap_Decode(t *testing.T) {
+	type testStruct struct {
+		TestString string  `json:"testString"`
+		TestFloat  float64 `json:"testFloat"`
+		TestInt    int64   `json:"testInt"`
+		TestBool   bool    `json:"testBool"`
+	}
+
+	tests := []struct {
+		name        string
+		testJsonMap JsonMap
+		expectErr   bool
+		expectValue testStruct
+	}{
+		{
+			name: "Decodes Map",
+			testJsonMap: JsonMap{
+				"testString": "TestStringValue",
+				"testFloat":  json.Number("123.456"),
+				"testInt":    json.Number("123"),
+				"testBool":   true,
+				"testExtra":  "Ignored",
+			},
+			expectErr: false,
+			expectValue: testStruct{
+				TestString: "TestStringValue",
+				TestFloat:  123.456,
+				TestInt:    123,
+				TestBool:   true,
+			},
+		},
+		{
+			name: "Fails On Type Mismatch",
+			testJsonMap: JsonMap{
+				"testInt": "NotAnInt",
+			},
+			expectErr:   true,
+			expectValue: testStruct{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var actualValue testStruct
+				// Call the Method Under Test
+				err := tt.testJsonMap.Decode(&actualValue)
+				if tt.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, tt.expectValue, actualValue)
+				}
+			},
+		)
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+// Account is an account from the account list.
+type Account struct {
+	AccountId         string `json:"accountId"`
+	AccountIdKey      string `json:"accountIdKey"`
+	AccountMode       string `json:"accountMode"`
+	AccountDesc       string `json:"accountDesc"`
+	AccountName       string `json:"accountName"`
+	AccountType       string `json:"accountType"`
+	InstitutionType   string `json:"institutionType"`
+	AccountStatus     string `json:"accountStatus"`
+	ClosedDate        int64  `json:"closedDate"`
+	ShareWorksAccount bool   `json:"shareWorksAccount"`
+}
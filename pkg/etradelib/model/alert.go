@@ -0,0 +1,15 @@
+package model
+
+// Alert is an alert from the alert list. The message text and the read and
+// delete times are only populated for alert details.
+type Alert struct {
+	Id         int64  `json:"id"`
+	CreateTime int64  `json:"createTime"`
+	Subject    string `json:"subject"`
+	Status     string `json:"status"`
+	MsgText    string `json:"msgText"`
+	ReadTime   int64  `json:"readTime"`
+	DeleteTime int64  `json:"deleteTime"`
+	Symbol     string `json:"symbol"`
+	Category   string `json:"category"`
+}
@@ -0,0 +1,71 @@
+package model
+
+// Balances are an account's balances.
+type Balances struct {
+	AccountId          string           `json:"accountId"`
+	InstitutionType    string           `json:"institutionType"`
+	AsOfDate           int64            `json:"asOfDate"`
+	AccountType        string           `json:"accountType"`
+	OptionLevel        string           `json:"optionLevel"`
+	AccountDescription string           `json:"accountDescription"`
+	QuoteMode          int64            `json:"quoteMode"`
+	DayTraderStatus    string           `json:"dayTraderStatus"`
+	AccountMode        string           `json:"accountMode"`
+	Cash               *CashBalances    `json:"cash"`
+	Margin             *MarginBalances  `json:"margin"`
+	Computed           *ComputedBalance `json:"computed"`
+}
+
+// CashBalances are the balances of a cash account.
+type CashBalances struct {
+	FundsForOpenOrdersCash float64 `json:"fundsForOpenOrdersCash"`
+	MoneyMktBalance        float64 `json:"moneyMktBalance"`
+}
+
+// MarginBalances are the balances of a margin account.
+type MarginBalances struct {
+	DtCashOpenOrderReserve   float64 `json:"dtCashOpenOrderReserve"`
+	DtMarginOpenOrderReserve float64 `json:"dtMarginOpenOrderReserve"`
+}
+
+// ComputedBalance is the balance information that ETrade computes for an
+// account.
+type ComputedBalance struct {
+	CashAvailableForInvestment     float64         `json:"cashAvailableForInvestment"`
+	CashAvailableForWithdrawal     float64         `json:"cashAvailableForWithdrawal"`
+	TotalAvailableForWithdrawal    float64         `json:"totalAvailableForWithdrawal"`
+	NetCash                        float64         `json:"netCash"`
+	CashBalance                    float64         `json:"cashBalance"`
+	SettledCashForInvestment       float64         `json:"settledCashForInvestment"`
+	UnSettledCashForInvestment     float64         `json:"unSettledCashForInvestment"`
+	FundsWithheldFromPurchasePower float64         `json:"fundsWithheldFromPurchasePower"`
+	FundsWithheldFromWithdrawal    float64         `json:"fundsWithheldFromWithdrawal"`
+	MarginBuyingPower              float64         `json:"marginBuyingPower"`
+	CashBuyingPower                float64         `json:"cashBuyingPower"`
+	DtMarginBuyingPower            float64         `json:"dtMarginBuyingPower"`
+	DtCashBuyingPower              float64         `json:"dtCashBuyingPower"`
+	MarginBalance                  float64         `json:"marginBalance"`
+	ShortAdjustBalance             float64         `json:"shortAdjustBalance"`
+	RegtEquity                     float64         `json:"regtEquity"`
+	RegtEquityPercent              float64         `json:"regtEquityPercent"`
+	AccountBalance                 float64         `json:"accountBalance"`
+	OpenCalls                      *OpenCalls      `json:"openCalls"`
+	RealTimeValues                 *RealTimeValues `json:"realTimeValues"`
+}
+
+// OpenCalls are an account's outstanding calls.
+type OpenCalls struct {
+	MinEquityCall float64 `json:"minEquityCall"`
+	FedCall       float64 `json:"fedCall"`
+	CashCall      float64 `json:"cashCall"`
+	HouseCall     float64 `json:"houseCall"`
+}
+
+// RealTimeValues are an account's values at the time of the request.
+type RealTimeValues struct {
+	TotalAccountValue float64 `json:"totalAccountValue"`
+	NetMv             float64 `json:"netMv"`
+	NetMvLong         float64 `json:"netMvLong"`
+	NetMvShort        float64 `json:"netMvShort"`
+	TotalLongValue    float64 `json:"totalLongValue"`
+}
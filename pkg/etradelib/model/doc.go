@@ -0,0 +1,10 @@
+// Package model contains strongly typed representations of the ETrade API's
+// responses. They're decoded from the normalized JSON maps held by the
+// etradelib types (see, for example, ETradeAccount.AsModel), so their json
+// tags use the normalized, lowerCamelCase keys.
+//
+// Monetary amounts and quantities are float64. Timestamps are int64 values as
+// ETrade returns them: most are milliseconds since the epoch, but quote and
+// alert times are seconds since the epoch. Fields that ETrade omits are left
+// at their zero values.
+package model
@@ -0,0 +1,43 @@
+package model
+
+// OptionPair is a call and a put at the same strike from an option chain.
+// Either may be nil if only calls or only puts were requested.
+type OptionPair struct {
+	Call *OptionDetails `json:"call"`
+	Put  *OptionDetails `json:"put"`
+}
+
+// OptionDetails are the details of an option in an option chain.
+type OptionDetails struct {
+	OptionCategory   string        `json:"optionCategory"`
+	OptionRootSymbol string        `json:"optionRootSymbol"`
+	TimeStamp        int64         `json:"timeStamp"`
+	AdjustedFlag     bool          `json:"adjustedFlag"`
+	DisplaySymbol    string        `json:"displaySymbol"`
+	OptionType       string        `json:"optionType"`
+	StrikePrice      float64       `json:"strikePrice"`
+	Symbol           string        `json:"symbol"`
+	Bid              float64       `json:"bid"`
+	Ask              float64       `json:"ask"`
+	BidSize          int64         `json:"bidSize"`
+	AskSize          int64         `json:"askSize"`
+	InTheMoney       string        `json:"inTheMoney"`
+	Volume           int64         `json:"volume"`
+	OpenInterest     int64         `json:"openInterest"`
+	NetChange        float64       `json:"netChange"`
+	LastPrice        float64       `json:"lastPrice"`
+	QuoteDetail      string        `json:"quoteDetail"`
+	OsiKey           string        `json:"osiKey"`
+	OptionGreeks     *OptionGreeks `json:"optionGreeks"`
+}
+
+// OptionGreeks are the greeks of an option.
+type OptionGreeks struct {
+	Rho          float64 `json:"rho"`
+	Vega         float64 `json:"vega"`
+	Theta        float64 `json:"theta"`
+	Delta        float64 `json:"delta"`
+	Gamma        float64 `json:"gamma"`
+	Iv           float64 `json:"iv"`
+	CurrentValue bool    `json:"currentValue"`
+}
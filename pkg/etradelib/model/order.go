@@ -0,0 +1,44 @@
+package model
+
+// Order is an order from an account's order list.
+type Order struct {
+	OrderId     int64         `json:"orderId"`
+	Details     string        `json:"details"`
+	OrderType   string        `json:"orderType"`
+	OrderDetail []OrderDetail `json:"orderDetail"`
+}
+
+// OrderDetail is the detail of an order (or of one of the orders in a group
+// of orders).
+type OrderDetail struct {
+	OrderNumber   int64        `json:"orderNumber"`
+	PlacedTime    int64        `json:"placedTime"`
+	ExecutedTime  int64        `json:"executedTime"`
+	OrderValue    float64      `json:"orderValue"`
+	Status        string       `json:"status"`
+	OrderTerm     string       `json:"orderTerm"`
+	PriceType     string       `json:"priceType"`
+	LimitPrice    float64      `json:"limitPrice"`
+	StopPrice     float64      `json:"stopPrice"`
+	MarketSession string       `json:"marketSession"`
+	AllOrNone     bool         `json:"allOrNone"`
+	NetPrice      float64      `json:"netPrice"`
+	NetBid        float64      `json:"netBid"`
+	NetAsk        float64      `json:"netAsk"`
+	Gcd           int64        `json:"gcd"`
+	Ratio         string       `json:"ratio"`
+	Instrument    []Instrument `json:"instrument"`
+}
+
+// Instrument is a leg of an order.
+type Instrument struct {
+	Product               *Product `json:"product"`
+	SymbolDescription     string   `json:"symbolDescription"`
+	OrderAction           string   `json:"orderAction"`
+	QuantityType          string   `json:"quantityType"`
+	OrderedQuantity       float64  `json:"orderedQuantity"`
+	FilledQuantity        float64  `json:"filledQuantity"`
+	AverageExecutionPrice float64  `json:"averageExecutionPrice"`
+	EstimatedCommission   float64  `json:"estimatedCommission"`
+	EstimatedFees         float64  `json:"estimatedFees"`
+}
@@ -0,0 +1,72 @@
+package model
+
+// Position is a position in an account's portfolio. Lots is only populated
+// if the position's lots were requested.
+type Position struct {
+	PositionId        int64      `json:"positionId"`
+	AccountId         string     `json:"accountId"`
+	Product           *Product   `json:"product"`
+	OsiKey            string     `json:"osiKey"`
+	SymbolDescription string     `json:"symbolDescription"`
+	DateAcquired      int64      `json:"dateAcquired"`
+	PricePaid         float64    `json:"pricePaid"`
+	Commissions       float64    `json:"commissions"`
+	OtherFees         float64    `json:"otherFees"`
+	Quantity          float64    `json:"quantity"`
+	PositionIndicator string     `json:"positionIndicator"`
+	PositionType      string     `json:"positionType"`
+	DaysGain          float64    `json:"daysGain"`
+	DaysGainPct       float64    `json:"daysGainPct"`
+	MarketValue       float64    `json:"marketValue"`
+	TotalCost         float64    `json:"totalCost"`
+	TotalGain         float64    `json:"totalGain"`
+	TotalGainPct      float64    `json:"totalGainPct"`
+	PctOfPortfolio    float64    `json:"pctOfPortfolio"`
+	CostPerShare      float64    `json:"costPerShare"`
+	TodayCommissions  float64    `json:"todayCommissions"`
+	TodayFees         float64    `json:"todayFees"`
+	TodayPricePaid    float64    `json:"todayPricePaid"`
+	TodayQuantity     float64    `json:"todayQuantity"`
+	AdjPrevClose      float64    `json:"adjPrevClose"`
+	Quick             *QuickView `json:"quick"`
+	Lots              []Lot      `json:"lots"`
+}
+
+// QuickView is the quote summary included with a position.
+type QuickView struct {
+	LastTrade     float64 `json:"lastTrade"`
+	LastTradeTime int64   `json:"lastTradeTime"`
+	Change        float64 `json:"change"`
+	ChangePct     float64 `json:"changePct"`
+	Volume        int64   `json:"volume"`
+	QuoteStatus   string  `json:"quoteStatus"`
+}
+
+// Lot is a tax lot of a position.
+type Lot struct {
+	PositionId          int64   `json:"positionId"`
+	PositionLotId       int64   `json:"positionLotId"`
+	Price               float64 `json:"price"`
+	TermCode            int64   `json:"termCode"`
+	DaysGain            float64 `json:"daysGain"`
+	DaysGainPct         float64 `json:"daysGainPct"`
+	MarketValue         float64 `json:"marketValue"`
+	TotalCost           float64 `json:"totalCost"`
+	TotalCostForGainPct float64 `json:"totalCostForGainPct"`
+	TotalGain           float64 `json:"totalGain"`
+	LotSourceCode       int64   `json:"lotSourceCode"`
+	OriginalQty         float64 `json:"originalQty"`
+	RemainingQty        float64 `json:"remainingQty"`
+	AvailableQty        float64 `json:"availableQty"`
+	OrderNo             int64   `json:"orderNo"`
+	LegNo               int64   `json:"legNo"`
+	AcquiredDate        int64   `json:"acquiredDate"`
+	LocationCode        int64   `json:"locationCode"`
+	ExchangeRate        float64 `json:"exchangeRate"`
+	SettlementCurrency  string  `json:"settlementCurrency"`
+	PaymentCurrency     string  `json:"paymentCurrency"`
+	AdjPrice            float64 `json:"adjPrice"`
+	CommPerShare        float64 `json:"commPerShare"`
+	FeesPerShare        float64 `json:"feesPerShare"`
+	ShortType           int64   `json:"shortType"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// Product identifies a security (e.g. a stock or an option).
+type Product struct {
+	Symbol       string  `json:"symbol"`
+	SecurityType string  `json:"securityType"`
+	CallPut      string  `json:"callPut"`
+	ExpiryYear   int64   `json:"expiryYear"`
+	ExpiryMonth  int64   `json:"expiryMonth"`
+	ExpiryDay    int64   `json:"expiryDay"`
+	StrikePrice  float64 `json:"strikePrice"`
+}
@@ -0,0 +1,107 @@
+package model
+
+// Quote is a quote for a security. Which of the detail sections (All,
+// Fundamental, Intraday, Options, Week52) is populated depends on the detail
+// flag of the request.
+type Quote struct {
+	DateTime    string            `json:"dateTime"`
+	DateTimeUTC int64             `json:"dateTimeUTC"`
+	QuoteStatus string            `json:"quoteStatus"`
+	AhFlag      string            `json:"ahFlag"`
+	Product     *Product          `json:"product"`
+	All         *AllQuoteDetails  `json:"all"`
+	Fundamental *FundamentalQuote `json:"fundamental"`
+	Intraday    *IntradayQuote    `json:"intraday"`
+	Options     *OptionQuote      `json:"option"`
+	Week52      *Week52Quote      `json:"week52"`
+}
+
+// AllQuoteDetails are the details of a quote requested with the "all" detail
+// flag.
+type AllQuoteDetails struct {
+	AdjustedFlag          bool    `json:"adjustedFlag"`
+	Ask                   float64 `json:"ask"`
+	AskSize               int64   `json:"askSize"`
+	Bid                   float64 `json:"bid"`
+	BidSize               int64   `json:"bidSize"`
+	ChangeClose           float64 `json:"changeClose"`
+	ChangeClosePercentage float64 `json:"changeClosePercentage"`
+	CompanyName           string  `json:"companyName"`
+	High                  float64 `json:"high"`
+	Low                   float64 `json:"low"`
+	LastTrade             float64 `json:"lastTrade"`
+	Open                  float64 `json:"open"`
+	PreviousClose         float64 `json:"previousClose"`
+	TotalVolume           int64   `json:"totalVolume"`
+	SymbolDescription     string  `json:"symbolDescription"`
+	High52                float64 `json:"high52"`
+	Low52                 float64 `json:"low52"`
+	Week52HiDate          int64   `json:"week52HiDate"`
+	Week52LowDate         int64   `json:"week52LowDate"`
+	Eps                   float64 `json:"eps"`
+	Pe                    float64 `json:"pe"`
+	MarketCap             float64 `json:"marketCap"`
+	Dividend              float64 `json:"dividend"`
+	Yield                 float64 `json:"yield"`
+}
+
+// FundamentalQuote is the detail of a quote requested with the "fundamental"
+// detail flag.
+type FundamentalQuote struct {
+	CompanyName       string  `json:"companyName"`
+	Eps               float64 `json:"eps"`
+	EstEarnings       float64 `json:"estEarnings"`
+	High52            float64 `json:"high52"`
+	LastTrade         float64 `json:"lastTrade"`
+	Low52             float64 `json:"low52"`
+	SymbolDescription string  `json:"symbolDescription"`
+}
+
+// IntradayQuote is the detail of a quote requested with the "intraday"
+// detail flag.
+type IntradayQuote struct {
+	Ask                   float64 `json:"ask"`
+	Bid                   float64 `json:"bid"`
+	ChangeClose           float64 `json:"changeClose"`
+	ChangeClosePercentage float64 `json:"changeClosePercentage"`
+	CompanyName           string  `json:"companyName"`
+	High                  float64 `json:"high"`
+	LastTrade             float64 `json:"lastTrade"`
+	Low                   float64 `json:"low"`
+	TotalVolume           int64   `json:"totalVolume"`
+}
+
+// OptionQuote is the detail of a quote requested with the "options" detail
+// flag.
+type OptionQuote struct {
+	Ask                    float64       `json:"ask"`
+	AskSize                int64         `json:"askSize"`
+	Bid                    float64       `json:"bid"`
+	BidSize                int64         `json:"bidSize"`
+	CompanyName            string        `json:"companyName"`
+	DaysToExpiration       int64         `json:"daysToExpiration"`
+	LastTrade              float64       `json:"lastTrade"`
+	OpenInterest           int64         `json:"openInterest"`
+	OptionPreviousBidPrice float64       `json:"optionPreviousBidPrice"`
+	OptionPreviousAskPrice float64       `json:"optionPreviousAskPrice"`
+	OsiKey                 string        `json:"osiKey"`
+	IntrinsicValue         float64       `json:"intrinsicValue"`
+	TimePremium            float64       `json:"timePremium"`
+	OptionMultiplier       float64       `json:"optionMultiplier"`
+	ContractSize           float64       `json:"contractSize"`
+	SymbolDescription      string        `json:"symbolDescription"`
+	OptionGreeks           *OptionGreeks `json:"optionGreeks"`
+}
+
+// Week52Quote is the detail of a quote requested with the "week_52" detail
+// flag.
+type Week52Quote struct {
+	CompanyName       string  `json:"companyName"`
+	High52            float64 `json:"high52"`
+	LastTrade         float64 `json:"lastTrade"`
+	Low52             float64 `json:"low52"`
+	Perf12Months      float64 `json:"perf12Months"`
+	PreviousClose     float64 `json:"previousClose"`
+	SymbolDescription string  `json:"symbolDescription"`
+	TotalVolume       int64   `json:"totalVolume"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "encoding/json"
+
+// Transaction is a transaction in an account's history. ETrade returns the
+// transaction ID as a string in some responses and as a number in others, so
+// it's kept as a json.Number.
+type Transaction struct {
+	TransactionId   json.Number `json:"transactionId"`
+	AccountId       string      `json:"accountId"`
+	TransactionDate int64       `json:"transactionDate"`
+	PostDate        int64       `json:"postDate"`
+	Amount          float64     `json:"amount"`
+	Description     string      `json:"description"`
+	TransactionType string      `json:"transactionType"`
+	Memo            string      `json:"memo"`
+	ImageFlag       bool        `json:"imageFlag"`
+	InstType        string      `json:"instType"`
+	Category        *Category   `json:"category"`
+	Brokerage       *Brokerage  `json:"brokerage"`
+}
+
+// Category is the category that a transaction is assigned to.
+type Category struct {
+	CategoryId string `json:"categoryId"`
+	ParentId   string `json:"parentId"`
+}
+
+// Brokerage is the brokerage detail of a transaction (e.g. the security that
+// was bought or sold).
+type Brokerage struct {
+	TransactionType    string   `json:"transactionType"`
+	Product            *Product `json:"product"`
+	Quantity           float64  `json:"quantity"`
+	Price              float64  `json:"price"`
+	SettlementCurrency string   `json:"settlementCurrency"`
+	PaymentCurrency    string   `json:"paymentCurrency"`
+	Fee                float64  `json:"fee"`
+	Commission         float64  `json:"commission"`
+	DisplaySymbol      string   `json:"displaySymbol"`
+	SettlementDate     int64    `json:"settlementDate"`
+}
@@ -0,0 +1,480 @@
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"math"
+	"time"
+)
+
+// buyActions are the order actions that buy. All others sell.
+var buyActions = map[string]bool{
+	constants.OrderActionBuy.String():        true,
+	constants.OrderActionBuyToCover.String(): true,
+	constants.OrderActionBuyOpen.String():    true,
+	constants.OrderActionBuyClose.String():   true,
+}
+
+// closingActions are the order actions that reduce an existing position.
+var closingActions = map[string]bool{
+	constants.OrderActionSell.String():       true,
+	constants.OrderActionBuyToCover.String(): true,
+	constants.OrderActionBuyClose.String():   true,
+	constants.OrderActionSellClose.String():  true,
+}
+
+// equityActions and optionActions are the order actions allowed for each
+// security type.
+var equityActions = map[string]bool{
+	constants.OrderActionBuy.String():        true,
+	constants.OrderActionSell.String():       true,
+	constants.OrderActionBuyToCover.String(): true,
+	constants.OrderActionSellShort.String():  true,
+}
+
+var optionActions = map[string]bool{
+	constants.OrderActionBuyOpen.String():   true,
+	constants.OrderActionSellOpen.String():  true,
+	constants.OrderActionBuyClose.String():  true,
+	constants.OrderActionSellClose.String(): true,
+}
+
+// newOrder creates an order from an order request (as created by one of the
+// etradelib CreateETrade...OrderRequest functions).
+func newOrder(request jsonmap.JsonMap) (*order, error) {
+	var o order
+	var err error
+	if o.OrderType, err = request.GetString(etradelib.OrderRequestOrderTypeKey); err != nil {
+		return nil, err
+	}
+	if o.ClientOrderId, err = request.GetString(etradelib.OrderRequestClientOrderIdKey); err != nil {
+		return nil, err
+	}
+	details, err := request.GetSliceOfMaps(etradelib.OrderRequestOrderKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(details) != 1 {
+		return nil, fmt.Errorf("expected one order detail in the request, found %d", len(details))
+	}
+	detail := details[0]
+	if o.PriceType, err = detail.GetString("priceType"); err != nil {
+		return nil, err
+	}
+	if o.OrderTerm, err = detail.GetString("orderTerm"); err != nil {
+		return nil, err
+	}
+	if o.MarketSession, err = detail.GetString("marketSession"); err != nil {
+		return nil, err
+	}
+	if o.LimitPrice, err = detail.GetFloatWithDefault("limitPrice", 0); err != nil {
+		return nil, err
+	}
+	if o.StopPrice, err = detail.GetFloatWithDefault("stopPrice", 0); err != nil {
+		return nil, err
+	}
+	if o.AllOrNone, err = detail.GetBoolWithDefault("allOrNone", false); err != nil {
+		return nil, err
+	}
+	instruments, err := detail.GetSliceOfMaps(etradelib.OrderRequestInstrumentKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(instruments) < 1 {
+		return nil, errors.New("the order request has no instruments")
+	}
+	for _, instrument := range instruments {
+		leg, err := newOrderLeg(instrument)
+		if err != nil {
+			return nil, err
+		}
+		o.Legs = append(o.Legs, leg)
+	}
+	return &o, nil
+}
+
+func newOrderLeg(instrument jsonmap.JsonMap) (*orderLeg, error) {
+	productMap, err := instrument.GetMap(etradelib.OrderRequestProductKey)
+	if err != nil {
+		return nil, err
+	}
+	p, err := newProduct(productMap)
+	if err != nil {
+		return nil, err
+	}
+	orderAction, err := instrument.GetString("orderAction")
+	if err != nil {
+		return nil, err
+	}
+	if (p.isOption() && !optionActions[orderAction]) || (!p.isOption() && !equityActions[orderAction]) {
+		return nil, fmt.Errorf("order action %s is not valid for security type %s", orderAction, p.SecurityType)
+	}
+	quantity, err := instrument.GetInt("quantity")
+	if err != nil {
+		return nil, err
+	}
+	if quantity <= 0 {
+		return nil, fmt.Errorf("quantity %d must be greater than zero", quantity)
+	}
+	return &orderLeg{
+		Product:     p,
+		OrderAction: orderAction,
+		Quantity:    quantity,
+	}, nil
+}
+
+func (l *orderLeg) isBuy() bool {
+	return buyActions[l.OrderAction]
+}
+
+// signedQuantity returns the leg's quantity as it changes the position:
+// positive for buys and negative for sells.
+func (l *orderLeg) signedQuantity() float64 {
+	if l.isBuy() {
+		return float64(l.Quantity)
+	}
+	return -float64(l.Quantity)
+}
+
+func (l *orderLeg) transactionType() string {
+	switch l.OrderAction {
+	case constants.OrderActionBuy.String():
+		return "Bought"
+	case constants.OrderActionSell.String():
+		return "Sold"
+	case constants.OrderActionSellShort.String():
+		return "Sold Short"
+	case constants.OrderActionBuyToCover.String():
+		return "Bought To Cover"
+	case constants.OrderActionBuyOpen.String():
+		return "Bought To Open"
+	case constants.OrderActionSellOpen.String():
+		return "Sold To Open"
+	case constants.OrderActionBuyClose.String():
+		return "Bought To Close"
+	default:
+		return "Sold To Close"
+	}
+}
+
+// units returns the number of units of a multi-leg strategy, which is the
+// smallest leg quantity.
+func (o *order) units() int64 {
+	units := o.Legs[0].Quantity
+	for _, leg := range o.Legs[1:] {
+		if leg.Quantity < units {
+			units = leg.Quantity
+		}
+	}
+	return units
+}
+
+// validatePositions ensures that closing legs don't exceed the positions
+// that they close.
+func (a *account) validatePositions(o *order) error {
+	for _, leg := range o.Legs {
+		if !closingActions[leg.OrderAction] {
+			continue
+		}
+		held := a.heldQuantity(leg.Product)
+		if leg.isBuy() {
+			held = -held
+		}
+		if float64(leg.Quantity) > held {
+			return fmt.Errorf(
+				"%s %d %s exceeds the %g held", leg.OrderAction, leg.Quantity, leg.Product.description(),
+				math.Max(held, 0),
+			)
+		}
+	}
+	return nil
+}
+
+// estimateCost returns the cash that the order is expected to use (or, if
+// negative, to raise). Market orders are estimated from the current quotes.
+func (o *order) estimateCost(quotes QuoteSource) float64 {
+	if len(o.Legs) > 1 && o.PriceType != constants.OrderPriceTypeMarket.String() {
+		net := o.LimitPrice * float64(o.units()) * optionMultiplier
+		switch o.PriceType {
+		case constants.OrderPriceTypeNetDebit.String():
+			return net
+		case constants.OrderPriceTypeNetCredit.String():
+			return -net
+		default:
+			return 0
+		}
+	}
+	cost := 0.0
+	for _, leg := range o.Legs {
+		price := 0.0
+		switch o.PriceType {
+		case constants.OrderPriceTypeLimit.String(), constants.OrderPriceTypeStopLimit.String():
+			price = o.LimitPrice
+		case constants.OrderPriceTypeStop.String():
+			price = o.StopPrice
+		default:
+			if quote, found := quotes.GetQuote(leg.Product.quoteSymbol()); found {
+				if leg.isBuy() {
+					price = quote.buyPrice()
+				} else {
+					price = quote.sellPrice()
+				}
+			}
+		}
+		cost += leg.signedQuantity() * price * leg.Product.multiplier()
+	}
+	return cost
+}
+
+// isExpired returns whether an open order has outlived its term. Day orders
+// expire at the end of the day on which they were placed.
+func (o *order) isExpired(now time.Time) bool {
+	if o.OrderTerm != constants.OrderTermGoodForDay.String() {
+		return false
+	}
+	location := marketLocation()
+	placedYear, placedMonth, placedDay := time.UnixMilli(o.PlacedTime).In(location).Date()
+	nowYear, nowMonth, nowDay := now.In(location).Date()
+	return placedYear != nowYear || placedMonth != nowMonth || placedDay != nowDay
+}
+
+// isImmediate returns whether an order must fill when it is placed or be
+// canceled.
+func (o *order) isImmediate() bool {
+	return o.OrderTerm == constants.OrderTermImmediateOrCancel.String() ||
+		o.OrderTerm == constants.OrderTermFillOrKill.String()
+}
+
+// legPrices returns the prices at which each leg would fill and the last
+// trade prices, or false if any leg has no quote.
+func (o *order) legPrices(quotes QuoteSource) ([]float64, []float64, bool) {
+	fillPrices := make([]float64, len(o.Legs))
+	lastPrices := make([]float64, len(o.Legs))
+	for i, leg := range o.Legs {
+		quote, found := quotes.GetQuote(leg.Product.quoteSymbol())
+		if !found {
+			return nil, nil, false
+		}
+		if leg.isBuy() {
+			fillPrices[i] = quote.buyPrice()
+		} else {
+			fillPrices[i] = quote.sellPrice()
+		}
+		lastPrices[i] = quote.lastPrice()
+		if fillPrices[i] <= 0 {
+			return nil, nil, false
+		}
+	}
+	return fillPrices, lastPrices, true
+}
+
+// isMarketable returns whether the order's price conditions are met at the
+// provided prices.
+func (o *order) isMarketable(fillPrices []float64, lastPrices []float64) bool {
+	if len(o.Legs) == 1 {
+		buy := o.Legs[0].isBuy()
+		price, last := fillPrices[0], lastPrices[0]
+		limitMet := (buy && price <= o.LimitPrice) || (!buy && price >= o.LimitPrice)
+		stopMet := (buy && last >= o.StopPrice) || (!buy && last <= o.StopPrice)
+		switch o.PriceType {
+		case constants.OrderPriceTypeMarket.String():
+			return true
+		case constants.OrderPriceTypeLimit.String():
+			return limitMet
+		case constants.OrderPriceTypeStop.String():
+			return stopMet
+		case constants.OrderPriceTypeStopLimit.String():
+			return stopMet && limitMet
+		default:
+			return false
+		}
+	}
+
+	// The net price of one unit of a multi-leg order. Positive is a debit.
+	units := float64(o.units())
+	net := 0.0
+	for i, leg := range o.Legs {
+		if leg.isBuy() {
+			net += fillPrices[i] * float64(leg.Quantity) / units
+		} else {
+			net -= fillPrices[i] * float64(leg.Quantity) / units
+		}
+	}
+	switch o.PriceType {
+	case constants.OrderPriceTypeMarket.String():
+		return true
+	case constants.OrderPriceTypeNetDebit.String():
+		return net <= o.LimitPrice
+	case constants.OrderPriceTypeNetCredit.String():
+		return -net >= o.LimitPrice
+	case constants.OrderPriceTypeNetEven.String():
+		return net <= 0
+	default:
+		return false
+	}
+}
+
+// refreshOrders expires and fills the open orders in every account. It
+// returns whether any orders changed.
+func (s *state) refreshOrders(quotes QuoteSource, now time.Time) bool {
+	changed := false
+	for _, a := range s.Accounts {
+		for _, o := range a.Orders {
+			if o.Status != constants.OrderStatusOpen.String() {
+				continue
+			}
+			if o.isExpired(now) {
+				o.Status = constants.OrderStatusExpired.String()
+				changed = true
+				continue
+			}
+			if s.tryFill(a, o, quotes, now) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// tryFill fills the order if its price conditions are met. Orders that can
+// no longer be filled (e.g. because the account lacks the cash) are
+// rejected. It returns whether the order changed.
+func (s *state) tryFill(a *account, o *order, quotes QuoteSource, now time.Time) bool {
+	fillPrices, lastPrices, found := o.legPrices(quotes)
+	if !found || !o.isMarketable(fillPrices, lastPrices) {
+		return false
+	}
+	if err := a.validatePositions(o); err != nil {
+		o.Status = constants.OrderStatusRejected.String()
+		o.Reason = err.Error()
+		return true
+	}
+	cashChange := 0.0
+	for i, leg := range o.Legs {
+		cashChange -= leg.signedQuantity() * fillPrices[i] * leg.Product.multiplier()
+	}
+	if a.Cash+cashChange < 0 {
+		o.Status = constants.OrderStatusRejected.String()
+		o.Reason = fmt.Sprintf("insufficient cash: the order costs $%.2f but only $%.2f is available", -cashChange, a.Cash)
+		return true
+	}
+
+	a.Cash += cashChange
+	for i, leg := range o.Legs {
+		leg.FilledQuantity = leg.Quantity
+		leg.AverageExecutionPrice = fillPrices[i]
+		a.addToPosition(s, leg.Product, leg.signedQuantity(), fillPrices[i], now)
+		p := leg.Product
+		a.Transactions = append(
+			a.Transactions, &transaction{
+				TransactionId:   s.newId(),
+				TransactionDate: now.UnixMilli(),
+				TransactionType: leg.transactionType(),
+				Description:     fmt.Sprintf("%s %d %s @ %g", leg.transactionType(), leg.Quantity, p.description(), fillPrices[i]),
+				Amount:          -leg.signedQuantity() * fillPrices[i] * p.multiplier(),
+				Product:         &p,
+				Quantity:        leg.signedQuantity(),
+				Price:           fillPrices[i],
+				OrderId:         o.OrderId,
+			},
+		)
+	}
+	o.Status = constants.OrderStatusExecuted.String()
+	o.ExecutedTime = now.UnixMilli()
+	return true
+}
+
+// marketLocation returns the time zone of the US equity markets.
+func marketLocation() *time.Location {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		// Fall back to Eastern Standard Time if the time zone database is
+		// unavailable.
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return location
+}
+
+func (o *order) detailAsJsonMap(quotes QuoteSource) jsonmap.JsonMap {
+	instruments := make(jsonmap.JsonSlice, 0, len(o.Legs))
+	for _, leg := range o.Legs {
+		instrument := jsonmap.JsonMap{
+			"Product":               leg.Product.asJsonMap(),
+			"symbolDescription":     leg.Product.description(),
+			"orderAction":           leg.OrderAction,
+			"quantityType":          "QUANTITY",
+			"quantity":              leg.Quantity,
+			"orderedQuantity":       leg.Quantity,
+			"filledQuantity":        leg.FilledQuantity,
+			"averageExecutionPrice": leg.AverageExecutionPrice,
+			"estimatedCommission":   0.0,
+			"estimatedFees":         0.0,
+		}
+		if leg.Product.isOption() {
+			instrument["osiKey"] = leg.Product.quoteSymbol()
+		}
+		if quote, found := quotes.GetQuote(leg.Product.quoteSymbol()); found {
+			instrument["bid"] = quote.Bid
+			instrument["ask"] = quote.Ask
+			instrument["lastprice"] = quote.LastTrade
+		}
+		instruments = append(instruments, instrument)
+	}
+	detail := jsonmap.JsonMap{
+		"priceType":            o.PriceType,
+		"orderTerm":            o.OrderTerm,
+		"marketSession":        o.MarketSession,
+		"allOrNone":            o.AllOrNone,
+		"orderValue":           o.estimateCost(quotes),
+		"estimatedCommission":  0.0,
+		"estimatedTotalAmount": o.estimateCost(quotes),
+		"Instrument":           instruments,
+	}
+	if o.Status != "" {
+		detail["status"] = o.Status
+	}
+	if o.PlacedTime != 0 {
+		detail["placedTime"] = o.PlacedTime
+	}
+	if o.ExecutedTime != 0 {
+		detail["executedTime"] = o.ExecutedTime
+	}
+	if o.LimitPrice != 0 {
+		detail["limitPrice"] = o.LimitPrice
+	}
+	if o.StopPrice != 0 {
+		detail["stopPrice"] = o.StopPrice
+	}
+	if o.Reason != "" {
+		detail["reason"] = o.Reason
+	}
+	return detail
+}
+
+func (o *order) asJsonMap(quotes QuoteSource) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"orderId":       o.OrderId,
+		"orderType":     o.OrderType,
+		"clientOrderId": o.ClientOrderId,
+		"OrderDetail":   jsonmap.JsonSlice{o.detailAsJsonMap(quotes)},
+	}
+}
+
+// hasSymbol returns whether any leg of the order is for one of the symbols.
+func (o *order) hasSymbol(symbols []string) bool {
+	for _, leg := range o.Legs {
+		for _, symbol := range symbols {
+			if leg.Product.Symbol == normalizeQuoteSymbol(symbol) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (o *order) securityType() string {
+	return o.Legs[0].Product.SecurityType
+}
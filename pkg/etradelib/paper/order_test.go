@@ -0,0 +1,84 @@
+package paper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOrder_IsMarketable(t *testing.T) {
+	call := product{Symbol: "ABC", SecurityType: "OPTN", CallPut: "CALL"}
+	tests := []struct {
+		name         string
+		testOrder    order
+		testFill     []float64
+		testLast     []float64
+		expectResult bool
+	}{
+		{
+			name: "Buy Limit Below Ask Is Not Marketable",
+			testOrder: order{
+				PriceType: "LIMIT", LimitPrice: 10,
+				Legs: []*orderLeg{{OrderAction: "BUY", Quantity: 1}},
+			},
+			testFill:     []float64{10.1},
+			testLast:     []float64{10},
+			expectResult: false,
+		},
+		{
+			name: "Sell Limit Below Bid Is Marketable",
+			testOrder: order{
+				PriceType: "LIMIT", LimitPrice: 10,
+				Legs: []*orderLeg{{OrderAction: "SELL", Quantity: 1}},
+			},
+			testFill:     []float64{10.1},
+			testLast:     []float64{10},
+			expectResult: true,
+		},
+		{
+			name: "Sell Stop Triggers At Last Trade",
+			testOrder: order{
+				PriceType: "STOP", StopPrice: 10,
+				Legs: []*orderLeg{{OrderAction: "SELL", Quantity: 1}},
+			},
+			testFill:     []float64{9.8},
+			testLast:     []float64{9.9},
+			expectResult: true,
+		},
+		{
+			name: "Net Debit Spread Within Limit Is Marketable",
+			testOrder: order{
+				PriceType: "NET_DEBIT", LimitPrice: 1.5,
+				Legs: []*orderLeg{
+					{Product: call, OrderAction: "BUY_OPEN", Quantity: 2},
+					{Product: call, OrderAction: "SELL_OPEN", Quantity: 2},
+				},
+			},
+			testFill:     []float64{3, 1.6},
+			testLast:     []float64{3, 1.6},
+			expectResult: true,
+		},
+		{
+			name: "Net Credit Spread Below Limit Is Not Marketable",
+			testOrder: order{
+				PriceType: "NET_CREDIT", LimitPrice: 1.5,
+				Legs: []*orderLeg{
+					{Product: call, OrderAction: "SELL_OPEN", Quantity: 1},
+					{Product: call, OrderAction: "BUY_OPEN", Quantity: 1},
+				},
+			},
+			testFill:     []float64{3, 1.6},
+			testLast:     []float64{3, 1.6},
+			expectResult: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualResult := tt.testOrder.isMarketable(tt.testFill, tt.testLast)
+				assert.Equal(t, tt.expectResult, actualResult)
+			},
+		)
+	}
+}
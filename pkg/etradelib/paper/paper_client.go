@@ -0,0 +1,1138 @@
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"golang.org/x/exp/slog"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStartingCash is the cash that a new paper trading account starts
+// with unless another amount is configured.
+const DefaultStartingCash = 100000.0
+
+const (
+	// paperConsumerKey stands in for a consumer key, which paper trading
+	// doesn't need. Cached credentials are keyed by it.
+	paperConsumerKey = "paper"
+
+	// transactionsDefaultCount and portfolioDefaultCount match the ETrade
+	// defaults for requests that don't specify a count.
+	transactionsDefaultCount = 50
+	portfolioDefaultCount    = 50
+)
+
+type paperClient struct {
+	logger        *slog.Logger
+	stateFilename string
+	startingCash  float64
+	quotes        QuoteSource
+	now           func() time.Time
+	mutex         sync.Mutex
+}
+
+// CreateETradeClient creates an ETradeClient that trades fake money. The
+// accounts, positions, orders, and transactions are kept in the state file,
+// which is created with a single account holding startingCash if it doesn't
+// exist. Orders fill against the quotes from the quote source. The responses
+// have the same form as ETrade's, so the client can be used anywhere that the
+// real client is used.
+func CreateETradeClient(
+	logger *slog.Logger, stateFilename string, startingCash float64, quotes QuoteSource,
+) client.ETradeClient {
+	return &paperClient{
+		logger:        logger,
+		stateFilename: stateFilename,
+		startingCash:  startingCash,
+		quotes:        quotes,
+		now:           time.Now,
+	}
+}
+
+// stateFn performs an operation on the state and returns the response and
+// whether the state changed.
+type stateFn func(s *state, now time.Time) (jsonmap.JsonMap, bool, error)
+
+// update loads the state, fills or expires any open orders, and then calls fn.
+// The state is saved if anything changed.
+func (c *paperClient) update(fn stateFn) ([]byte, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	s, changed, err := loadState(c.stateFilename, c.startingCash, now)
+	if err != nil {
+		return nil, err
+	}
+	if s.refreshOrders(c.quotes, now) {
+		changed = true
+	}
+	response, fnChanged, fnErr := fn(s, now)
+	if changed || (fnErr == nil && fnChanged) {
+		if err = saveState(c.stateFilename, s); err != nil {
+			return nil, err
+		}
+	}
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	return c.respond(response)
+}
+
+func (c *paperClient) respond(response jsonmap.JsonMap) ([]byte, error) {
+	responseBytes, err := response.ToJsonBytes(false, false)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug(string(responseBytes))
+	return responseBytes, nil
+}
+
+// WithContext returns the client itself, since paper trading doesn't make any
+// requests that could be canceled.
+func (c *paperClient) WithContext(_ context.Context) client.ETradeClient {
+	return c
+}
+
+// WithRequestTimeout returns the client itself, since paper trading doesn't
+// make any requests that could time out.
+func (c *paperClient) WithRequestTimeout(_ time.Duration) client.ETradeClient {
+	return c
+}
+
+func (c *paperClient) Authenticate() ([]byte, error) {
+	return client.NewStatusResponse("success"), nil
+}
+
+func (c *paperClient) Verify(_ string) ([]byte, error) {
+	return client.NewStatusResponse("success"), nil
+}
+
+func (c *paperClient) GetKeys() (consumerKey string, consumerSecret string, accessToken string, accessSecret string) {
+	return paperConsumerKey, "", "", ""
+}
+
+func (c *paperClient) ListAccounts() ([]byte, error) {
+	return c.update(
+		func(s *state, _ time.Time) (jsonmap.JsonMap, bool, error) {
+			accounts := make(jsonmap.JsonSlice, 0, len(s.Accounts))
+			for _, a := range s.Accounts {
+				accounts = append(accounts, a.asJsonMap())
+			}
+			return jsonmap.JsonMap{
+				"AccountListResponse": jsonmap.JsonMap{
+					"Accounts": jsonmap.JsonMap{
+						"Account": accounts,
+					},
+				},
+			}, false, nil
+		},
+	)
+}
+
+func (c *paperClient) GetAccountBalances(accountIdKey string, _ bool) ([]byte, error) {
+	return c.update(
+		func(s *state, _ time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			reserved := a.reservedCash(c.quotes, 0)
+			longValue, shortValue := a.marketValues(c.quotes)
+			return jsonmap.JsonMap{
+				"BalanceResponse": jsonmap.JsonMap{
+					"accountId":          a.AccountId,
+					"accountType":        "INDIVIDUAL",
+					"accountDescription": a.AccountName,
+					"accountMode":        "CASH",
+					"Cash": jsonmap.JsonMap{
+						"fundsForOpenOrdersCash": reserved,
+						"moneyMktBalance":        0.0,
+					},
+					"Computed": jsonmap.JsonMap{
+						"cashAvailableForInvestment": a.Cash - reserved,
+						"cashAvailableForWithdrawal": a.Cash - reserved,
+						"cashBuyingPower":            a.Cash - reserved,
+						"netCash":                    a.Cash,
+						"cashBalance":                a.Cash,
+						"accountBalance":             a.Cash,
+						"RealTimeValues": jsonmap.JsonMap{
+							"totalAccountValue": a.Cash + longValue + shortValue,
+							"netMv":             longValue + shortValue,
+							"netMvLong":         longValue,
+							"netMvShort":        shortValue,
+						},
+					},
+				},
+			}, false, nil
+		},
+	)
+}
+
+func (c *paperClient) ListTransactions(
+	accountIdKey string, startDate *time.Time, endDate *time.Time, sortOrder constants.SortOrder, marker string,
+	count int,
+) ([]byte, error) {
+	return c.update(
+		func(s *state, _ time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			transactions := make([]*transaction, 0, len(a.Transactions))
+			for _, t := range a.Transactions {
+				if isInDateRange(t.TransactionDate, startDate, endDate) {
+					transactions = append(transactions, t)
+				}
+			}
+			// ETrade lists the most recent transactions first by default.
+			sort.Slice(
+				transactions, func(i, j int) bool {
+					a, b := transactions[i], transactions[j]
+					if sortOrder != constants.SortOrderAsc {
+						a, b = b, a
+					}
+					if a.TransactionDate != b.TransactionDate {
+						return a.TransactionDate < b.TransactionDate
+					}
+					return a.TransactionId < b.TransactionId
+				},
+			)
+			if count <= 0 {
+				count = transactionsDefaultCount
+			}
+			start, end, nextMarker, err := getPage(marker, count, len(transactions))
+			if err != nil {
+				return nil, false, err
+			}
+			transactionsSlice := make(jsonmap.JsonSlice, 0, end-start)
+			for _, t := range transactions[start:end] {
+				transactionMap := t.asJsonMap(a)
+				transactionMap["transactionId"] = strconv.FormatInt(t.TransactionId, 10)
+				transactionsSlice = append(transactionsSlice, transactionMap)
+			}
+			response := jsonmap.JsonMap{
+				"transactionCount": len(transactionsSlice),
+				"totalCount":       len(transactions),
+				"moreTransactions": nextMarker != "",
+				"Transaction":      transactionsSlice,
+			}
+			if nextMarker != "" {
+				response["marker"] = nextMarker
+			}
+			return jsonmap.JsonMap{"TransactionListResponse": response}, false, nil
+		},
+	)
+}
+
+func (c *paperClient) ListTransactionDetails(accountIdKey string, transactionId string) ([]byte, error) {
+	return c.update(
+		func(s *state, _ time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			id, err := strconv.ParseInt(transactionId, 10, 64)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid transaction ID %s", transactionId)
+			}
+			for _, t := range a.Transactions {
+				if t.TransactionId == id {
+					return jsonmap.JsonMap{"TransactionDetailsResponse": t.asJsonMap(a)}, false, nil
+				}
+			}
+			return nil, false, fmt.Errorf("transaction %s not found", transactionId)
+		},
+	)
+}
+
+func (c *paperClient) ViewPortfolio(
+	accountIdKey string, count int, sortBy constants.PortfolioSortBy, sortOrder constants.SortOrder,
+	pageNumber string, _ constants.MarketSession, totalsRequired bool, _ bool,
+	view constants.PortfolioView,
+) ([]byte, error) {
+	return c.update(
+		func(s *state, now time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			positions := make([]*position, len(a.Positions))
+			copy(positions, a.Positions)
+			sortPositions(positions, sortBy, sortOrder, c.quotes)
+
+			if count <= 0 {
+				count = portfolioDefaultCount
+			}
+			page := 1
+			if pageNumber != "" {
+				if page, err = strconv.Atoi(pageNumber); err != nil || page < 1 {
+					return nil, false, fmt.Errorf("invalid page number %s", pageNumber)
+				}
+			}
+			start, end, nextMarker, err := getPage(strconv.Itoa((page-1)*count), count, len(positions))
+			if err != nil {
+				return nil, false, err
+			}
+
+			longValue, shortValue := a.marketValues(c.quotes)
+			positionsSlice := make(jsonmap.JsonSlice, 0, end-start)
+			for _, p := range positions[start:end] {
+				positionsSlice = append(positionsSlice, p.asJsonMap(c.quotes, view, longValue+shortValue, now))
+			}
+			accountPortfolio := jsonmap.JsonMap{
+				"accountId":  a.AccountId,
+				"Position":   positionsSlice,
+				"totalPages": (len(positions) + count - 1) / count,
+			}
+			if nextMarker != "" {
+				accountPortfolio["nextPageNo"] = strconv.Itoa(page + 1)
+			}
+			response := jsonmap.JsonMap{
+				"AccountPortfolio": jsonmap.JsonSlice{accountPortfolio},
+			}
+			if totalsRequired {
+				totalCost := 0.0
+				for _, p := range a.Positions {
+					totalCost += p.totalCost()
+				}
+				totalGain := longValue + shortValue - totalCost
+				response["Totals"] = jsonmap.JsonMap{
+					"todaysGainLoss":    0.0,
+					"todaysGainLossPct": 0.0,
+					"totalMarketValue":  a.Cash + longValue + shortValue,
+					"totalGainLoss":     totalGain,
+					"totalGainLossPct":  percent(totalGain, totalCost),
+					"totalPricePaid":    totalCost,
+					"cashBalance":       a.Cash,
+				}
+			}
+			return jsonmap.JsonMap{"PortfolioResponse": response}, false, nil
+		},
+	)
+}
+
+func (c *paperClient) ListPositionLotsDetails(accountIdKey string, positionId int64) ([]byte, error) {
+	return c.update(
+		func(s *state, _ time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			for _, p := range a.Positions {
+				if p.PositionId != positionId {
+					continue
+				}
+				lots := make(jsonmap.JsonSlice, 0, len(p.Lots))
+				for _, l := range p.Lots {
+					lots = append(lots, p.lotAsJsonMap(l, c.quotes))
+				}
+				return jsonmap.JsonMap{
+					"PositionLotsResponse": jsonmap.JsonMap{
+						"PositionLot": lots,
+					},
+				}, false, nil
+			}
+			return nil, false, fmt.Errorf("position %d not found", positionId)
+		},
+	)
+}
+
+func (c *paperClient) ListAlerts(
+	_ int, _ constants.AlertCategory, _ constants.AlertStatus, _ constants.SortOrder, _ string,
+) ([]byte, error) {
+	return c.respond(
+		jsonmap.JsonMap{
+			"AlertsResponse": jsonmap.JsonMap{
+				"totalAlerts": 0,
+				"Alert":       jsonmap.JsonSlice{},
+			},
+		},
+	)
+}
+
+func (c *paperClient) ListAlertDetails(alertId string, _ bool) ([]byte, error) {
+	return nil, fmt.Errorf("alert %s not found (paper trading has no alerts)", alertId)
+}
+
+func (c *paperClient) DeleteAlerts(alertIds []string) ([]byte, error) {
+	return nil, fmt.Errorf("alerts %s not found (paper trading has no alerts)", strings.Join(alertIds, ","))
+}
+
+func (c *paperClient) GetQuotes(
+	symbols []string, detailFlag constants.QuoteDetailFlag, _ bool, _ bool,
+) ([]byte, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("at least one symbol is required")
+	}
+	if len(symbols) > constants.GetQuotesMaxSymbols {
+		return nil, fmt.Errorf("a maximum of %d symbols is allowed", constants.GetQuotesMaxSymbols)
+	}
+	now := c.now()
+	quoteData := jsonmap.JsonSlice{}
+	messages := jsonmap.JsonSlice{}
+	for _, symbol := range symbols {
+		quote, found := c.quotes.GetQuote(symbol)
+		if !found {
+			messages = append(
+				messages, jsonmap.JsonMap{
+					"description": fmt.Sprintf("%s is not a valid symbol", symbol),
+					"type":        "WARNING",
+				},
+			)
+			continue
+		}
+		quoteData = append(
+			quoteData, jsonmap.JsonMap{
+				"dateTimeUTC":                     now.Unix(),
+				"quoteStatus":                     "REALTIME",
+				"ahFlag":                          "false",
+				"Product":                         quoteProduct(symbol).asJsonMap(),
+				quoteDetailSectionKey(detailFlag): quoteDetailAsJsonMap(quote),
+			},
+		)
+	}
+	response := jsonmap.JsonMap{"QuoteData": quoteData}
+	if len(messages) > 0 {
+		response["Messages"] = jsonmap.JsonMap{"Message": messages}
+	}
+	return c.respond(jsonmap.JsonMap{"QuoteResponse": response})
+}
+
+func (c *paperClient) LookupProduct(search string) ([]byte, error) {
+	search = strings.ToUpper(strings.TrimSpace(search))
+	data := jsonmap.JsonSlice{}
+	for _, symbol := range c.quotes.GetSymbols() {
+		if quoteProduct(symbol).isOption() || !strings.HasPrefix(symbol, search) {
+			continue
+		}
+		data = append(
+			data, jsonmap.JsonMap{
+				"symbol":      symbol,
+				"description": symbol,
+				"type":        "EQUITY",
+			},
+		)
+	}
+	return c.respond(jsonmap.JsonMap{"LookupResponse": jsonmap.JsonMap{"Data": data}})
+}
+
+func (c *paperClient) GetOptionChains(
+	symbol string, expiryYear int, expiryMonth int, expiryDay int, strikePriceNear int, noOfStrikes int,
+	_ bool, _ bool, _ constants.OptionCategory, chainType constants.OptionChainType, _ constants.OptionPriceType,
+) ([]byte, error) {
+	symbol = strings.ToUpper(symbol)
+	expiries := c.optionExpiries(symbol)
+	var expiry *product
+	for i := range expiries {
+		e := &expiries[i]
+		if (expiryYear <= 0 || e.ExpiryYear == int64(expiryYear)) &&
+			(expiryMonth <= 0 || e.ExpiryMonth == int64(expiryMonth)) &&
+			(expiryDay <= 0 || e.ExpiryDay == int64(expiryDay)) {
+			expiry = e
+			break
+		}
+	}
+	if expiry == nil {
+		return nil, fmt.Errorf("no option chains found for %s", symbol)
+	}
+
+	// Group the calls and puts for the expiration date by strike price.
+	calls := map[float64]product{}
+	puts := map[float64]product{}
+	for _, quoteSymbol := range c.quotes.GetSymbols() {
+		p := quoteProduct(quoteSymbol)
+		if !p.isOption() || p.Symbol != symbol || p.ExpiryYear != expiry.ExpiryYear ||
+			p.ExpiryMonth != expiry.ExpiryMonth || p.ExpiryDay != expiry.ExpiryDay {
+			continue
+		}
+		if p.CallPut == constants.OptionTypeCall.String() {
+			calls[p.StrikePrice] = p
+		} else {
+			puts[p.StrikePrice] = p
+		}
+	}
+	strikes := make([]float64, 0, len(calls)+len(puts))
+	for strike := range calls {
+		strikes = append(strikes, strike)
+	}
+	for strike := range puts {
+		if _, found := calls[strike]; !found {
+			strikes = append(strikes, strike)
+		}
+	}
+
+	nearPrice := float64(strikePriceNear)
+	if nearPrice <= 0 {
+		if quote, found := c.quotes.GetQuote(symbol); found {
+			nearPrice = quote.lastPrice()
+		}
+	}
+	if noOfStrikes > 0 && noOfStrikes < len(strikes) {
+		sort.Slice(
+			strikes, func(i, j int) bool {
+				return math.Abs(strikes[i]-nearPrice) < math.Abs(strikes[j]-nearPrice)
+			},
+		)
+		strikes = strikes[:noOfStrikes]
+	}
+	sort.Float64s(strikes)
+
+	now := c.now()
+	pairs := make(jsonmap.JsonSlice, 0, len(strikes))
+	for _, strike := range strikes {
+		pair := jsonmap.JsonMap{}
+		if call, found := calls[strike]; found && chainType != constants.OptionChainTypePut {
+			pair["Call"] = c.optionDetailsAsJsonMap(call, now)
+		}
+		if put, found := puts[strike]; found && chainType != constants.OptionChainTypeCall {
+			pair["Put"] = c.optionDetailsAsJsonMap(put, now)
+		}
+		if len(pair) > 0 {
+			pairs = append(pairs, pair)
+		}
+	}
+	return c.respond(
+		jsonmap.JsonMap{
+			"OptionChainResponse": jsonmap.JsonMap{
+				"OptionPair": pairs,
+				"timeStamp":  now.Unix(),
+				"quoteType":  "DELAYED",
+				"nearPrice":  nearPrice,
+				"SelectedED": jsonmap.JsonMap{
+					"year":  expiry.ExpiryYear,
+					"month": expiry.ExpiryMonth,
+					"day":   expiry.ExpiryDay,
+				},
+			},
+		},
+	)
+}
+
+func (c *paperClient) GetOptionExpireDates(symbol string, expiryType constants.OptionExpiryType) ([]byte, error) {
+	expirationDates := jsonmap.JsonSlice{}
+	for _, e := range c.optionExpiries(strings.ToUpper(symbol)) {
+		eType := e.expiryType()
+		if expiryType != constants.OptionExpiryTypeNil && expiryType != constants.OptionExpiryTypeAll &&
+			expiryType != constants.OptionExpiryTypeUnspecified && expiryType != eType {
+			continue
+		}
+		expirationDates = append(
+			expirationDates, jsonmap.JsonMap{
+				"year":       e.ExpiryYear,
+				"month":      e.ExpiryMonth,
+				"day":        e.ExpiryDay,
+				"expiryType": eType.String(),
+			},
+		)
+	}
+	if len(expirationDates) == 0 {
+		return nil, fmt.Errorf("no option expiration dates found for %s", symbol)
+	}
+	return c.respond(
+		jsonmap.JsonMap{
+			"OptionExpireDateResponse": jsonmap.JsonMap{
+				"ExpirationDate": expirationDates,
+			},
+		},
+	)
+}
+
+func (c *paperClient) ListOrders(
+	accountIdKey string, marker string, count int, status constants.OrderStatus, fromDate *time.Time,
+	toDate *time.Time, symbols []string, securityType constants.OrderSecurityType,
+	_ constants.OrderTransactionType, marketSession constants.MarketSession,
+) ([]byte, error) {
+	return c.update(
+		func(s *state, _ time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			orders := make([]*order, 0, len(a.Orders))
+			for _, o := range a.Orders {
+				if (status != constants.OrderStatusNil && o.Status != status.String()) ||
+					!isInDateRange(o.PlacedTime, fromDate, toDate) ||
+					(len(symbols) > 0 && !o.hasSymbol(symbols)) ||
+					(securityType != constants.OrderSecurityTypeNil && o.securityType() != securityType.String()) ||
+					(marketSession != constants.MarketSessionNil && o.MarketSession != marketSession.String()) {
+					continue
+				}
+				orders = append(orders, o)
+			}
+			// ETrade lists the most recent orders first.
+			sort.SliceStable(
+				orders, func(i, j int) bool {
+					return orders[i].OrderId > orders[j].OrderId
+				},
+			)
+			if count <= 0 {
+				count = constants.OrdersMaxCount
+			}
+			start, end, nextMarker, err := getPage(marker, count, len(orders))
+			if err != nil {
+				return nil, false, err
+			}
+			ordersSlice := make(jsonmap.JsonSlice, 0, end-start)
+			for _, o := range orders[start:end] {
+				ordersSlice = append(ordersSlice, o.asJsonMap(c.quotes))
+			}
+			response := jsonmap.JsonMap{"Order": ordersSlice}
+			if nextMarker != "" {
+				response["marker"] = nextMarker
+			}
+			return jsonmap.JsonMap{"OrdersResponse": response}, false, nil
+		},
+	)
+}
+
+func (c *paperClient) PreviewOrder(accountIdKey string, request jsonmap.JsonMap) ([]byte, error) {
+	return c.update(
+		func(s *state, now time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			o, err := newOrder(request)
+			if err != nil {
+				return nil, false, err
+			}
+			return c.previewOrder(s, a, o, 0, now)
+		},
+	)
+}
+
+func (c *paperClient) PlaceOrder(accountIdKey string, previewId int64, request jsonmap.JsonMap) ([]byte, error) {
+	return c.update(
+		func(s *state, now time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			p, err := findPreviewedOrder(a, previewId, 0, request)
+			if err != nil {
+				return nil, false, err
+			}
+			a.removePreview(p)
+			o := p.Order
+			o.OrderId = s.newId()
+			a.Orders = append(a.Orders, o)
+			return c.placeOrder(s, a, o, now), true, nil
+		},
+	)
+}
+
+func (c *paperClient) CancelOrder(accountIdKey string, orderId int64) ([]byte, error) {
+	return c.update(
+		func(s *state, now time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			o, err := findOpenOrder(a, orderId)
+			if err != nil {
+				return nil, false, err
+			}
+			o.Status = constants.OrderStatusCanceled.String()
+			return jsonmap.JsonMap{
+				"CancelOrderResponse": jsonmap.JsonMap{
+					"accountId":  a.AccountId,
+					"orderId":    o.OrderId,
+					"cancelTime": now.UnixMilli(),
+					"Messages": jsonmap.JsonMap{
+						"Message": jsonmap.JsonSlice{
+							jsonmap.JsonMap{
+								"code":        5011,
+								"description": "Your request to cancel your order is being processed.",
+								"type":        "WARNING",
+							},
+						},
+					},
+				},
+			}, true, nil
+		},
+	)
+}
+
+func (c *paperClient) PreviewChangedOrder(accountIdKey string, orderId int64, request jsonmap.JsonMap) (
+	[]byte, error,
+) {
+	return c.update(
+		func(s *state, now time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			if _, err = findOpenOrder(a, orderId); err != nil {
+				return nil, false, err
+			}
+			o, err := newOrder(request)
+			if err != nil {
+				return nil, false, err
+			}
+			return c.previewOrder(s, a, o, orderId, now)
+		},
+	)
+}
+
+func (c *paperClient) PlaceChangedOrder(
+	accountIdKey string, orderId int64, previewId int64, request jsonmap.JsonMap,
+) ([]byte, error) {
+	return c.update(
+		func(s *state, now time.Time) (jsonmap.JsonMap, bool, error) {
+			a, err := s.getAccount(accountIdKey)
+			if err != nil {
+				return nil, false, err
+			}
+			existing, err := findOpenOrder(a, orderId)
+			if err != nil {
+				return nil, false, err
+			}
+			p, err := findPreviewedOrder(a, previewId, orderId, request)
+			if err != nil {
+				return nil, false, err
+			}
+			a.removePreview(p)
+			o := p.Order
+			// The changed order replaces the existing one but keeps its ID.
+			o.OrderId = existing.OrderId
+			*existing = *o
+			return c.placeOrder(s, a, existing, now), true, nil
+		},
+	)
+}
+
+// previewOrder checks that the account can afford the order and saves it as
+// a preview that can later be placed.
+func (c *paperClient) previewOrder(s *state, a *account, o *order, orderId int64, now time.Time) (
+	jsonmap.JsonMap, bool, error,
+) {
+	if err := a.validatePositions(o); err != nil {
+		return nil, false, err
+	}
+	cost := o.estimateCost(c.quotes)
+	available := a.Cash - a.reservedCash(c.quotes, orderId)
+	if cost > available {
+		return nil, false, fmt.Errorf(
+			"insufficient cash: the order costs $%.2f but only $%.2f is available", cost, available,
+		)
+	}
+	p := &preview{PreviewId: s.newId(), OrderId: orderId, Order: o}
+	a.addPreview(p)
+	return jsonmap.JsonMap{
+		"PreviewOrderResponse": jsonmap.JsonMap{
+			"accountId":       a.AccountId,
+			"orderType":       o.OrderType,
+			"totalOrderValue": cost,
+			"previewTime":     now.UnixMilli(),
+			"PreviewIds": jsonmap.JsonSlice{
+				jsonmap.JsonMap{"previewId": p.PreviewId},
+			},
+			"Order": jsonmap.JsonSlice{o.detailAsJsonMap(c.quotes)},
+		},
+	}, true, nil
+}
+
+// findPreviewedOrder returns a preview, whose order must match the order in
+// the request. The preview is only removed once its order is placed, so a
+// request that doesn't match it can be corrected and placed again.
+func findPreviewedOrder(a *account, previewId int64, orderId int64, request jsonmap.JsonMap) (*preview, error) {
+	requested, err := newOrder(request)
+	if err != nil {
+		return nil, err
+	}
+	p, err := a.findPreview(previewId, orderId)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(p.Order, requested) {
+		return nil, fmt.Errorf("the order does not match preview %d", previewId)
+	}
+	return p, nil
+}
+
+// placeOrder opens the order and tries to fill it immediately. Immediate or
+// cancel and fill or kill orders that don't fill are canceled.
+func (c *paperClient) placeOrder(s *state, a *account, o *order, now time.Time) jsonmap.JsonMap {
+	o.Status = constants.OrderStatusOpen.String()
+	o.PlacedTime = now.UnixMilli()
+	s.tryFill(a, o, c.quotes, now)
+	if o.Status == constants.OrderStatusOpen.String() && o.isImmediate() {
+		o.Status = constants.OrderStatusCanceled.String()
+	}
+	return jsonmap.JsonMap{
+		"PlaceOrderResponse": jsonmap.JsonMap{
+			"accountId":  a.AccountId,
+			"orderType":  o.OrderType,
+			"placedTime": o.PlacedTime,
+			"OrderIds": jsonmap.JsonSlice{
+				jsonmap.JsonMap{"orderId": o.OrderId},
+			},
+			"Order": jsonmap.JsonSlice{o.detailAsJsonMap(c.quotes)},
+		},
+	}
+}
+
+func findOpenOrder(a *account, orderId int64) (*order, error) {
+	o := a.findOrder(orderId)
+	if o == nil {
+		return nil, fmt.Errorf("order %d not found", orderId)
+	}
+	if o.Status != constants.OrderStatusOpen.String() {
+		return nil, fmt.Errorf("order %d is %s and can no longer be changed", orderId, o.Status)
+	}
+	return o, nil
+}
+
+// optionExpiries returns a product for each distinct expiration date of the
+// options on symbol in the quote source, earliest first.
+func (c *paperClient) optionExpiries(symbol string) []product {
+	expiries := []product{}
+	seen := map[product]bool{}
+	for _, quoteSymbol := range c.quotes.GetSymbols() {
+		p := quoteProduct(quoteSymbol)
+		if !p.isOption() || p.Symbol != symbol {
+			continue
+		}
+		expiry := product{
+			Symbol:       p.Symbol,
+			SecurityType: p.SecurityType,
+			ExpiryYear:   p.ExpiryYear,
+			ExpiryMonth:  p.ExpiryMonth,
+			ExpiryDay:    p.ExpiryDay,
+		}
+		if !seen[expiry] {
+			seen[expiry] = true
+			expiries = append(expiries, expiry)
+		}
+	}
+	sort.Slice(
+		expiries, func(i, j int) bool {
+			return expiries[i].contract().ExpiryDate.Before(expiries[j].contract().ExpiryDate)
+		},
+	)
+	return expiries
+}
+
+func (c *paperClient) optionDetailsAsJsonMap(p product, now time.Time) jsonmap.JsonMap {
+	quote, _ := c.quotes.GetQuote(p.quoteSymbol())
+	return jsonmap.JsonMap{
+		"optionCategory":   "STANDARD",
+		"optionRootSymbol": p.Symbol,
+		"timeStamp":        now.Unix(),
+		"adjustedFlag":     false,
+		"displaySymbol":    p.description(),
+		"optionType":       p.CallPut,
+		"strikePrice":      p.StrikePrice,
+		"symbol":           p.Symbol,
+		"bid":              quote.Bid,
+		"ask":              quote.Ask,
+		"lastPrice":        quote.LastTrade,
+		"osiKey":           p.quoteSymbol(),
+	}
+}
+
+// expiryType classifies an expiration date. Monthly options expire on the
+// third Friday of the month.
+func (p product) expiryType() constants.OptionExpiryType {
+	date := p.contract().ExpiryDate
+	if date.Weekday() == time.Friday && date.Day() >= 15 && date.Day() <= 21 {
+		return constants.OptionExpiryTypeMonthly
+	}
+	return constants.OptionExpiryTypeWeekly
+}
+
+// quoteProduct returns the product for a quote symbol, which is either an
+// equity symbol or an option OSI key.
+func quoteProduct(symbol string) product {
+	symbol = normalizeQuoteSymbol(symbol)
+	if contract, err := etradelib.ParseOsiKey(symbol); err == nil {
+		return product{
+			Symbol:       contract.Underlying,
+			SecurityType: constants.OrderSecurityTypeOption.String(),
+			CallPut:      contract.CallPut.String(),
+			ExpiryYear:   int64(contract.ExpiryDate.Year()),
+			ExpiryMonth:  int64(contract.ExpiryDate.Month()),
+			ExpiryDay:    int64(contract.ExpiryDate.Day()),
+			StrikePrice:  contract.StrikePrice,
+		}
+	}
+	return product{Symbol: symbol, SecurityType: constants.OrderSecurityTypeEquity.String()}
+}
+
+// quoteDetailSectionKey returns the key under which ETrade returns the quote
+// detail requested by a detail flag.
+func quoteDetailSectionKey(detailFlag constants.QuoteDetailFlag) string {
+	switch detailFlag {
+	case constants.QuoteDetailFlagFundamental:
+		return "Fundamental"
+	case constants.QuoteDetailFlagIntraday:
+		return "Intraday"
+	case constants.QuoteDetailFlagOptions:
+		return "Option"
+	case constants.QuoteDetailFlagWeek52:
+		return "Week52"
+	case constants.QuoteDetailFlagMutualFund:
+		return "MutualFund"
+	default:
+		return "All"
+	}
+}
+
+func quoteDetailAsJsonMap(quote Quote) jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"lastTrade":             quote.lastPrice(),
+		"bid":                   quote.Bid,
+		"ask":                   quote.Ask,
+		"changeClose":           0.0,
+		"changeClosePercentage": 0.0,
+		"totalVolume":           0,
+	}
+}
+
+func (a *account) asJsonMap() jsonmap.JsonMap {
+	return jsonmap.JsonMap{
+		"accountId":       a.AccountId,
+		"accountIdKey":    a.AccountIdKey,
+		"accountMode":     "CASH",
+		"accountDesc":     a.AccountName,
+		"accountName":     a.AccountName,
+		"accountType":     "INDIVIDUAL",
+		"institutionType": "BROKERAGE",
+		"accountStatus":   "ACTIVE",
+	}
+}
+
+// reservedCash returns the cash that open orders (other than the one being
+// changed, if any) are expected to use.
+func (a *account) reservedCash(quotes QuoteSource, excludeOrderId int64) float64 {
+	reserved := 0.0
+	for _, o := range a.Orders {
+		if o.Status != constants.OrderStatusOpen.String() || o.OrderId == excludeOrderId {
+			continue
+		}
+		if cost := o.estimateCost(quotes); cost > 0 {
+			reserved += cost
+		}
+	}
+	return reserved
+}
+
+// marketValues returns the market values of the long and short positions.
+func (a *account) marketValues(quotes QuoteSource) (float64, float64) {
+	longValue, shortValue := 0.0, 0.0
+	for _, p := range a.Positions {
+		if value := p.marketValue(quotes); value >= 0 {
+			longValue += value
+		} else {
+			shortValue += value
+		}
+	}
+	return longValue, shortValue
+}
+
+// lastPrice returns the position's last trade price or, if the quote source
+// has no price, its average cost.
+func (p *position) lastPrice(quotes QuoteSource) float64 {
+	if quote, found := quotes.GetQuote(p.Product.quoteSymbol()); found && quote.lastPrice() > 0 {
+		return quote.lastPrice()
+	}
+	return p.costPerShare()
+}
+
+func (p *position) costPerShare() float64 {
+	quantity := p.quantity()
+	if quantity == 0 {
+		return 0
+	}
+	return p.totalCost() / (quantity * p.Product.multiplier())
+}
+
+func (p *position) marketValue(quotes QuoteSource) float64 {
+	return p.quantity() * p.lastPrice(quotes) * p.Product.multiplier()
+}
+
+// portfolioViewSectionKey returns the key under which ETrade returns the
+// position detail for a portfolio view.
+func portfolioViewSectionKey(view constants.PortfolioView) string {
+	switch view {
+	case constants.PortfolioViewPerformance:
+		return "Performance"
+	case constants.PortfolioViewFundamental:
+		return "Fundamental"
+	case constants.PortfolioViewOptionsWatch:
+		return "OptionsWatch"
+	case constants.PortfolioViewComplete:
+		return "Complete"
+	default:
+		return "Quick"
+	}
+}
+
+func (p *position) asJsonMap(
+	quotes QuoteSource, view constants.PortfolioView, totalValue float64, now time.Time,
+) jsonmap.JsonMap {
+	quantity := p.quantity()
+	positionType := "LONG"
+	if quantity < 0 {
+		positionType = "SHORT"
+	}
+	marketValue := p.marketValue(quotes)
+	totalCost := p.totalCost()
+	totalGain := marketValue - totalCost
+	positionMap := jsonmap.JsonMap{
+		"positionId":        p.PositionId,
+		"symbolDescription": p.Product.description(),
+		"dateAcquired":      p.dateAcquired(),
+		"pricePaid":         p.costPerShare(),
+		"commissions":       0.0,
+		"otherFees":         0.0,
+		"quantity":          quantity,
+		"positionType":      positionType,
+		"daysGain":          0.0,
+		"daysGainPct":       0.0,
+		"marketValue":       marketValue,
+		"totalCost":         totalCost,
+		"totalGain":         totalGain,
+		"totalGainPct":      percent(totalGain, math.Abs(totalCost)),
+		"pctOfPortfolio":    percent(marketValue, totalValue),
+		"costPerShare":      p.costPerShare(),
+		"Product":           p.Product.asJsonMap(),
+		portfolioViewSectionKey(view): jsonmap.JsonMap{
+			"lastTrade":     p.lastPrice(quotes),
+			"lastTradeTime": now.Unix(),
+			"change":        0.0,
+			"changePct":     0.0,
+		},
+	}
+	if p.Product.isOption() {
+		positionMap["osiKey"] = p.Product.quoteSymbol()
+	}
+	return positionMap
+}
+
+func (p *position) lotAsJsonMap(l *lot, quotes QuoteSource) jsonmap.JsonMap {
+	multiplier := p.Product.multiplier()
+	marketValue := l.Quantity * p.lastPrice(quotes) * multiplier
+	totalCost := l.Quantity * l.Price * multiplier
+	return jsonmap.JsonMap{
+		"positionId":          p.PositionId,
+		"positionLotId":       l.LotId,
+		"price":               l.Price,
+		"daysGain":            0.0,
+		"daysGainPct":         0.0,
+		"marketValue":         marketValue,
+		"totalCost":           totalCost,
+		"totalCostForGainPct": math.Abs(totalCost),
+		"totalGain":           marketValue - totalCost,
+		"originalQty":         l.Quantity,
+		"remainingQty":        l.Quantity,
+		"availableQty":        l.Quantity,
+		"acquiredDate":        l.Acquired,
+	}
+}
+
+func sortPositions(
+	positions []*position, sortBy constants.PortfolioSortBy, sortOrder constants.SortOrder, quotes QuoteSource,
+) {
+	less := func(a *position, b *position) bool {
+		switch sortBy {
+		case constants.PortfolioSortByQuantity:
+			return a.quantity() < b.quantity()
+		case constants.PortfolioSortByMarketValue:
+			return a.marketValue(quotes) < b.marketValue(quotes)
+		case constants.PortfolioSortByTotalGain:
+			return a.marketValue(quotes)-a.totalCost() < b.marketValue(quotes)-b.totalCost()
+		default:
+			return a.Product.quoteSymbol() < b.Product.quoteSymbol()
+		}
+	}
+	sort.SliceStable(
+		positions, func(i, j int) bool {
+			if sortOrder == constants.SortOrderDesc {
+				return less(positions[j], positions[i])
+			}
+			return less(positions[i], positions[j])
+		},
+	)
+}
+
+func (t *transaction) asJsonMap(a *account) jsonmap.JsonMap {
+	brokerage := jsonmap.JsonMap{
+		"quantity":           t.Quantity,
+		"price":              t.Price,
+		"fee":                0.0,
+		"settlementCurrency": "USD",
+		"paymentCurrency":    "USD",
+	}
+	if t.Product != nil {
+		brokerage["Product"] = t.Product.asJsonMap()
+		brokerage["displaySymbol"] = t.Product.description()
+	}
+	transactionMap := jsonmap.JsonMap{
+		"transactionId":   t.TransactionId,
+		"accountId":       a.AccountId,
+		"transactionDate": t.TransactionDate,
+		"postDate":        t.TransactionDate,
+		"amount":          t.Amount,
+		"description":     t.Description,
+		"transactionType": t.TransactionType,
+		"Brokerage":       brokerage,
+	}
+	if t.OrderId != 0 {
+		transactionMap["orderNo"] = t.OrderId
+	}
+	return transactionMap
+}
+
+// getPage returns the range of items in a page that starts at the offset in
+// marker, along with the marker for the next page (or an empty string if
+// this is the last page).
+func getPage(marker string, count int, total int) (int, int, string, error) {
+	start := 0
+	if marker != "" {
+		var err error
+		if start, err = strconv.Atoi(marker); err != nil || start < 0 {
+			return 0, 0, "", fmt.Errorf("invalid marker %s", marker)
+		}
+	}
+	if start > total {
+		start = total
+	}
+	end := start + count
+	if end >= total {
+		return start, total, "", nil
+	}
+	return start, end, strconv.Itoa(end), nil
+}
+
+// isInDateRange returns whether a time in milliseconds falls on or between
+// the start and end dates. Either date may be nil.
+func isInDateRange(milliseconds int64, startDate *time.Time, endDate *time.Time) bool {
+	t := time.UnixMilli(milliseconds)
+	if startDate != nil && t.Before(*startDate) {
+		return false
+	}
+	if endDate != nil && !t.Before(endDate.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+func percent(value float64, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return value / total * 100
+}
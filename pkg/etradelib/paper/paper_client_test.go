@@ -0,0 +1,276 @@
+package paper
+
+import (
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPaperClient(t *testing.T) {
+	type testFn func(t *testing.T, c *paperClient, quotes *quoteSource)
+	tests := []struct {
+		name   string
+		testFn testFn
+	}{
+		{
+			name: "Fills Market Order At Ask",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				orderId, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeMarket, 0))
+				require.Nil(t, err)
+				assert.Equal(t, "EXECUTED", getOrderStatus(t, c, orderId))
+				assert.Equal(t, 100000.0-10*101, getCashBalance(t, c))
+			},
+		},
+		{
+			name: "Fills Limit Order When It Becomes Marketable",
+			testFn: func(t *testing.T, c *paperClient, quotes *quoteSource) {
+				orderId, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeLimit, 95))
+				require.Nil(t, err)
+				assert.Equal(t, "OPEN", getOrderStatus(t, c, orderId))
+				assert.Equal(t, 100000.0, getCashBalance(t, c))
+
+				quotes.quotes["ABC"] = Quote{LastTrade: 94, Bid: 93.9, Ask: 94.1}
+				assert.Equal(t, "EXECUTED", getOrderStatus(t, c, orderId))
+				assert.Equal(t, 100000.0-10*94.1, getCashBalance(t, c))
+			},
+		},
+		{
+			name: "Expires Day Order After The Day It Was Placed",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				orderId, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeLimit, 95))
+				require.Nil(t, err)
+				c.now = func() time.Time { return testNow.AddDate(0, 0, 1) }
+				assert.Equal(t, "EXPIRED", getOrderStatus(t, c, orderId))
+			},
+		},
+		{
+			name: "Cancels Unfilled Immediate Or Cancel Order",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				request, err := etradelib.CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 10, constants.OrderPriceTypeLimit, 95, 0,
+					constants.OrderTermImmediateOrCancel, constants.MarketSessionRegular, false,
+				)
+				require.Nil(t, err)
+				orderId, err := previewAndPlace(c, request.AsJsonMap())
+				require.Nil(t, err)
+				assert.Equal(t, "CANCELLED", getOrderStatus(t, c, orderId))
+			},
+		},
+		{
+			name: "Cancels Open Order",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				orderId, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeLimit, 95))
+				require.Nil(t, err)
+				response, err := c.CancelOrder(paperAccountIdKey, orderId)
+				require.Nil(t, err)
+				result, err := etradelib.CreateETradeCancelOrderResultFromResponse(response)
+				require.Nil(t, err)
+				assert.Equal(t, orderId, result.GetOrderId())
+				assert.Equal(t, "CANCELLED", getOrderStatus(t, c, orderId))
+
+				_, err = c.CancelOrder(paperAccountIdKey, orderId)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name: "Changes Open Order",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				orderId, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeLimit, 95))
+				require.Nil(t, err)
+				changed := testEquityRequest(t, constants.OrderPriceTypeLimit, 102)
+				response, err := c.PreviewChangedOrder(paperAccountIdKey, orderId, changed)
+				require.Nil(t, err)
+				preview, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+				require.Nil(t, err)
+				_, err = c.PlaceChangedOrder(paperAccountIdKey, orderId, preview.GetPreviewId(), changed)
+				require.Nil(t, err)
+				assert.Equal(t, "EXECUTED", getOrderStatus(t, c, orderId))
+			},
+		},
+		{
+			name: "Rejects Preview With Insufficient Cash",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				request, err := etradelib.CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionBuy, 1000, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+				require.Nil(t, err)
+				_, err = c.PreviewOrder(paperAccountIdKey, request.AsJsonMap())
+				assert.ErrorContains(t, err, "insufficient cash")
+			},
+		},
+		{
+			name: "Rejects Sale Exceeding Position",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				request, err := etradelib.CreateETradeEquityOrderRequest(
+					"TestId", "ABC", constants.OrderActionSell, 10, constants.OrderPriceTypeMarket, 0, 0,
+					constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+				)
+				require.Nil(t, err)
+				_, err = c.PreviewOrder(paperAccountIdKey, request.AsJsonMap())
+				assert.ErrorContains(t, err, "exceeds the 0 held")
+			},
+		},
+		{
+			name: "Rejects Order That Does Not Match Preview",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				response, err := c.PreviewOrder(
+					paperAccountIdKey, testEquityRequest(t, constants.OrderPriceTypeLimit, 95),
+				)
+				require.Nil(t, err)
+				preview, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+				require.Nil(t, err)
+				_, err = c.PlaceOrder(
+					paperAccountIdKey, preview.GetPreviewId(), testEquityRequest(t, constants.OrderPriceTypeLimit, 96),
+				)
+				assert.ErrorContains(t, err, "does not match")
+			},
+		},
+		{
+			name: "Keeps Preview When Order Does Not Match",
+			testFn: func(t *testing.T, c *paperClient, quotes *quoteSource) {
+				// The open order fills during the failed request, so that the
+				// state is saved anyway.
+				openOrderId, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeLimit, 95))
+				require.Nil(t, err)
+				request := testEquityRequest(t, constants.OrderPriceTypeLimit, 90)
+				response, err := c.PreviewOrder(paperAccountIdKey, request)
+				require.Nil(t, err)
+				preview, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+				require.Nil(t, err)
+				quotes.quotes["ABC"] = Quote{LastTrade: 94, Bid: 93.9, Ask: 94.1}
+				_, err = c.PlaceOrder(
+					paperAccountIdKey, preview.GetPreviewId(), testEquityRequest(t, constants.OrderPriceTypeLimit, 96),
+				)
+				require.ErrorContains(t, err, "does not match")
+				assert.Equal(t, "EXECUTED", getOrderStatus(t, c, openOrderId))
+
+				_, err = c.PlaceOrder(paperAccountIdKey, preview.GetPreviewId(), request)
+				assert.Nil(t, err)
+				_, err = c.PlaceOrder(paperAccountIdKey, preview.GetPreviewId(), request)
+				assert.ErrorContains(t, err, "not found")
+			},
+		},
+		{
+			name: "Lists Positions And Transactions",
+			testFn: func(t *testing.T, c *paperClient, _ *quoteSource) {
+				_, err := previewAndPlace(c, testEquityRequest(t, constants.OrderPriceTypeMarket, 0))
+				require.Nil(t, err)
+
+				response, err := c.ViewPortfolio(
+					paperAccountIdKey, 0, constants.PortfolioSortByNil, constants.SortOrderNil, "",
+					constants.MarketSessionNil, true, false, constants.PortfolioViewNil,
+				)
+				require.Nil(t, err)
+				positionList, err := etradelib.CreateETradePositionListFromResponse(response)
+				require.Nil(t, err)
+				require.Len(t, positionList.GetAllPositions(), 1)
+				positionMap := positionList.GetAllPositions()[0].AsJsonMap()
+				assert.Equal(t, "ABC", getString(t, positionMap, ".product.symbol"))
+				assert.Equal(t, 1000.0, getFloat(t, positionMap, ".marketValue"))
+
+				response, err = c.ListTransactions(paperAccountIdKey, nil, nil, constants.SortOrderNil, "", 1)
+				require.Nil(t, err)
+				transactionList, err := etradelib.CreateETradeTransactionListFromResponse(response)
+				require.Nil(t, err)
+				require.Len(t, transactionList.GetAllTransactions(), 1)
+				assert.Equal(
+					t, "Bought",
+					getString(t, transactionList.GetAllTransactions()[0].AsJsonMap(), ".transactionType"),
+				)
+				assert.NotEqual(t, "", transactionList.NextPage())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				quotes := &quoteSource{
+					quotes: map[string]Quote{"ABC": {LastTrade: 100, Bid: 99, Ask: 101}},
+				}
+				c := &paperClient{
+					logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+					stateFilename: filepath.Join(t.TempDir(), "paper.json"),
+					startingCash:  100000,
+					quotes:        quotes,
+					now:           func() time.Time { return testNow },
+				}
+				// Call the Methods Under Test
+				tt.testFn(t, c, quotes)
+			},
+		)
+	}
+}
+
+// A Wednesday at 10:00am Eastern
+var testNow = time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+
+func testEquityRequest(t *testing.T, priceType constants.OrderPriceType, limitPrice float64) jsonmap.JsonMap {
+	request, err := etradelib.CreateETradeEquityOrderRequest(
+		"TestId", "ABC", constants.OrderActionBuy, 10, priceType, limitPrice, 0,
+		constants.OrderTermGoodForDay, constants.MarketSessionRegular, false,
+	)
+	require.Nil(t, err)
+	return request.AsJsonMap()
+}
+
+func previewAndPlace(c *paperClient, request jsonmap.JsonMap) (int64, error) {
+	response, err := c.PreviewOrder(paperAccountIdKey, request)
+	if err != nil {
+		return 0, err
+	}
+	preview, err := etradelib.CreateETradePreviewOrderFromResponse(response)
+	if err != nil {
+		return 0, err
+	}
+	response, err = c.PlaceOrder(paperAccountIdKey, preview.GetPreviewId(), request)
+	if err != nil {
+		return 0, err
+	}
+	placed, err := etradelib.CreateETradePlaceOrderFromResponse(response)
+	if err != nil {
+		return 0, err
+	}
+	return placed.GetOrderId(), nil
+}
+
+func getOrderStatus(t *testing.T, c *paperClient, orderId int64) string {
+	response, err := c.ListOrders(
+		paperAccountIdKey, "", 0, constants.OrderStatusNil, nil, nil, nil, constants.OrderSecurityTypeNil,
+		constants.OrderTransactionTypeNil, constants.MarketSessionNil,
+	)
+	require.Nil(t, err)
+	orderList, err := etradelib.CreateETradeOrderListFromResponse(response)
+	require.Nil(t, err)
+	o := orderList.GetOrderById(orderId)
+	require.NotNil(t, o)
+	return getString(t, o.AsJsonMap(), ".orderDetail[0].status")
+}
+
+func getCashBalance(t *testing.T, c *paperClient) float64 {
+	response, err := c.GetAccountBalances(paperAccountIdKey, true)
+	require.Nil(t, err)
+	balances, err := etradelib.CreateETradeBalancesFromResponse(response)
+	require.Nil(t, err)
+	return getFloat(t, balances.AsJsonMap(), ".computed.cashBalance")
+}
+
+func getString(t *testing.T, m jsonmap.JsonMap, path string) string {
+	value, err := m.GetStringAtPath(path)
+	require.Nil(t, err)
+	return value
+}
+
+func getFloat(t *testing.T, m jsonmap.JsonMap, path string) float64 {
+	value, err := m.GetFloatAtPath(path)
+	require.Nil(t, err)
+	return value
+}
@@ -0,0 +1,240 @@
+package paper
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Quote is the simulated market for a symbol. Orders to buy fill at the ask
+// and orders to sell fill at the bid. If either is missing, orders fill at
+// the last trade price.
+type Quote struct {
+	LastTrade float64 `json:"lastTrade"`
+	Bid       float64 `json:"bid"`
+	Ask       float64 `json:"ask"`
+}
+
+func (q Quote) buyPrice() float64 {
+	if q.Ask > 0 {
+		return q.Ask
+	}
+	return q.LastTrade
+}
+
+func (q Quote) sellPrice() float64 {
+	if q.Bid > 0 {
+		return q.Bid
+	}
+	return q.LastTrade
+}
+
+func (q Quote) lastPrice() float64 {
+	if q.LastTrade > 0 {
+		return q.LastTrade
+	}
+	return (q.Bid + q.Ask) / 2
+}
+
+// QuoteSource provides the quotes that paper orders fill against. Equities
+// are identified by their symbols and options by their OSI keys (as
+// formatted by ETradeOptionContract.GetOsiKey).
+type QuoteSource interface {
+	GetQuote(symbol string) (Quote, bool)
+	GetSymbols() []string
+}
+
+type quoteSource struct {
+	quotes map[string]Quote
+}
+
+// NewQuoteSource creates a QuoteSource from a map of symbols (or option OSI
+// keys) to quotes.
+func NewQuoteSource(quotes map[string]Quote) QuoteSource {
+	source := quoteSource{quotes: make(map[string]Quote, len(quotes))}
+	for symbol, quote := range quotes {
+		source.quotes[normalizeQuoteSymbol(symbol)] = quote
+	}
+	return &source
+}
+
+// LoadQuoteSourceFromFile loads quotes from a file. See LoadQuoteSource for
+// the supported formats.
+func LoadQuoteSourceFromFile(filename string) (QuoteSource, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return LoadQuoteSource(data)
+}
+
+// LoadQuoteSource loads quotes from JSON in one of these formats:
+//
+// A price file, which maps symbols (or option OSI keys) either to a last
+// trade price or to a quote:
+//
+//	{
+//	  "AAPL": 190.5,
+//	  "ABC---240119C00150000": {"lastTrade": 1.15, "bid": 1.1, "ask": 1.2}
+//	}
+//
+// Recorded quotes, which are either an ETrade quote response or the JSON
+// output of the `market quote` command.
+func LoadQuoteSource(data []byte) (QuoteSource, error) {
+	rawMap, err := jsonmap.NewJsonMapFromJsonBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, found := rawMap["QuoteResponse"]; found {
+		quoteList, err := etradelib.CreateETradeQuoteListFromResponse(data)
+		if err != nil {
+			return nil, err
+		}
+		return loadRecordedQuotes(quoteList.AsJsonMap())
+	}
+	if _, found := rawMap["quotes"]; found {
+		return loadRecordedQuotes(rawMap)
+	}
+	return loadPriceFile(data)
+}
+
+func loadPriceFile(data []byte) (QuoteSource, error) {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]Quote, len(entries))
+	for symbol, entry := range entries {
+		var price float64
+		if err := json.Unmarshal(entry, &price); err == nil {
+			quotes[symbol] = Quote{LastTrade: price}
+			continue
+		}
+		var quote Quote
+		if err := json.Unmarshal(entry, &quote); err != nil {
+			return nil, fmt.Errorf("the price for %s is neither a number nor a quote (%w)", symbol, err)
+		}
+		quotes[symbol] = quote
+	}
+	return NewQuoteSource(quotes), nil
+}
+
+// recordedQuoteDetailPaths are the quote details that may hold prices, in
+// order of preference.
+var recordedQuoteDetailPaths = []string{".all", ".intraday", ".option", ".fundamental", ".week52", ".mutualFund"}
+
+func loadRecordedQuotes(quoteListMap jsonmap.JsonMap) (QuoteSource, error) {
+	quoteMaps, err := quoteListMap.GetSliceOfMapsAtPathWithDefault(etradelib.QuoteListQuotesPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	quotes := make(map[string]Quote, len(quoteMaps))
+	for _, quoteMap := range quoteMaps {
+		product, err := quoteMap.GetMap("product")
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := productQuoteSymbol(product)
+		if err != nil {
+			return nil, err
+		}
+		for _, detailPath := range recordedQuoteDetailPaths {
+			detail, err := quoteMap.GetMapAtPathWithDefault(detailPath, nil)
+			if err != nil {
+				return nil, err
+			}
+			if detail == nil {
+				continue
+			}
+			quote := Quote{}
+			if quote.LastTrade, err = detail.GetFloatWithDefault("lastTrade", 0); err != nil {
+				return nil, err
+			}
+			if quote.Bid, err = detail.GetFloatWithDefault("bid", 0); err != nil {
+				return nil, err
+			}
+			if quote.Ask, err = detail.GetFloatWithDefault("ask", 0); err != nil {
+				return nil, err
+			}
+			quotes[symbol] = quote
+			break
+		}
+	}
+	return NewQuoteSource(quotes), nil
+}
+
+// productQuoteSymbol returns the symbol for an ETrade product: the symbol
+// itself for equities or the OSI key for options.
+func productQuoteSymbol(product jsonmap.JsonMap) (string, error) {
+	symbol, err := product.GetString("symbol")
+	if err != nil {
+		return "", err
+	}
+	securityType, err := product.GetStringWithDefault("securityType", "")
+	if err != nil {
+		return "", err
+	}
+	if securityType != constants.OrderSecurityTypeOption.String() {
+		return strings.ToUpper(symbol), nil
+	}
+	callPut, err := product.GetString("callPut")
+	if err != nil {
+		return "", err
+	}
+	year, err := product.GetInt("expiryYear")
+	if err != nil {
+		return "", err
+	}
+	month, err := product.GetInt("expiryMonth")
+	if err != nil {
+		return "", err
+	}
+	day, err := product.GetInt("expiryDay")
+	if err != nil {
+		return "", err
+	}
+	strikePrice, err := product.GetFloat("strikePrice")
+	if err != nil {
+		return "", err
+	}
+	contract := etradelib.ETradeOptionContract{
+		Underlying:  strings.ToUpper(symbol),
+		CallPut:     constants.OptionTypeCall,
+		ExpiryDate:  time.Date(int(year), time.Month(month), int(day), 0, 0, 0, 0, time.UTC),
+		StrikePrice: strikePrice,
+	}
+	if callPut == constants.OptionTypePut.String() {
+		contract.CallPut = constants.OptionTypePut
+	}
+	return contract.GetOsiKey(), nil
+}
+
+// normalizeQuoteSymbol upper-cases equity symbols and reformats OSI keys so
+// that keys padded with spaces match keys padded with dashes.
+func normalizeQuoteSymbol(symbol string) string {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if contract, err := etradelib.ParseOsiKey(symbol); err == nil {
+		return contract.GetOsiKey()
+	}
+	return symbol
+}
+
+func (s *quoteSource) GetQuote(symbol string) (Quote, bool) {
+	quote, found := s.quotes[normalizeQuoteSymbol(symbol)]
+	return quote, found
+}
+
+func (s *quoteSource) GetSymbols() []string {
+	symbols := make([]string, 0, len(s.quotes))
+	for symbol := range s.quotes {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
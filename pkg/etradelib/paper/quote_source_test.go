@@ -0,0 +1,147 @@
+package paper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadQuoteSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		testJson    string
+		expectErr   bool
+		expectValue map[string]Quote
+	}{
+		{
+			name: "Loads Price File",
+			testJson: `
+{
+  "abc": 101.5,
+  "ABC   240119C00150000": {"lastTrade": 1.15, "bid": 1.1, "ask": 1.2}
+}`,
+			expectErr: false,
+			expectValue: map[string]Quote{
+				"ABC":                   {LastTrade: 101.5},
+				"ABC---240119C00150000": {LastTrade: 1.15, Bid: 1.1, Ask: 1.2},
+			},
+		},
+		{
+			name: "Loads ETrade Quote Response",
+			testJson: `
+{
+  "QuoteResponse": {
+    "QuoteData": [
+      {
+        "Intraday": {"lastTrade": 101.5, "bid": 101.4, "ask": 101.6},
+        "Product": {"symbol": "ABC", "securityType": "EQ"}
+      },
+      {
+        "All": {"lastTrade": 1.15, "bid": 1.1, "ask": 1.2},
+        "Product": {
+          "symbol": "ABC",
+          "securityType": "OPTN",
+          "callPut": "PUT",
+          "expiryYear": 2024,
+          "expiryMonth": 1,
+          "expiryDay": 19,
+          "strikePrice": 150
+        }
+      }
+    ]
+  }
+}`,
+			expectErr: false,
+			expectValue: map[string]Quote{
+				"ABC":                   {LastTrade: 101.5, Bid: 101.4, Ask: 101.6},
+				"ABC---240119P00150000": {LastTrade: 1.15, Bid: 1.1, Ask: 1.2},
+			},
+		},
+		{
+			name: "Loads Quote Command Output",
+			testJson: `
+{
+  "quotes": [
+    {
+      "intraday": {"lastTrade": 101.5},
+      "product": {"symbol": "ABC", "securityType": "EQ"}
+    }
+  ]
+}`,
+			expectErr: false,
+			expectValue: map[string]Quote{
+				"ABC": {LastTrade: 101.5},
+			},
+		},
+		{
+			name:        "Fails With Invalid Price",
+			testJson:    `{"ABC": "expensive"}`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+		{
+			name:        "Fails With Invalid JSON",
+			testJson:    `{`,
+			expectErr:   true,
+			expectValue: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Method Under Test
+				actualValue, err := LoadQuoteSource([]byte(tt.testJson))
+				if tt.expectErr {
+					assert.Error(t, err)
+					assert.Nil(t, actualValue)
+				} else {
+					assert.Nil(t, err)
+					assert.Equal(t, &quoteSource{quotes: tt.expectValue}, actualValue)
+				}
+			},
+		)
+	}
+}
+
+func TestQuote_Prices(t *testing.T) {
+	tests := []struct {
+		name            string
+		testQuote       Quote
+		expectBuyPrice  float64
+		expectSellPrice float64
+		expectLastPrice float64
+	}{
+		{
+			name:            "Fills At Bid And Ask",
+			testQuote:       Quote{LastTrade: 10, Bid: 9.9, Ask: 10.1},
+			expectBuyPrice:  10.1,
+			expectSellPrice: 9.9,
+			expectLastPrice: 10,
+		},
+		{
+			name:            "Fills At Last Trade Without Bid And Ask",
+			testQuote:       Quote{LastTrade: 10},
+			expectBuyPrice:  10,
+			expectSellPrice: 10,
+			expectLastPrice: 10,
+		},
+		{
+			name:            "Uses Midpoint Without Last Trade",
+			testQuote:       Quote{Bid: 9.5, Ask: 10.5},
+			expectBuyPrice:  10.5,
+			expectSellPrice: 9.5,
+			expectLastPrice: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				// Call the Methods Under Test
+				assert.Equal(t, tt.expectBuyPrice, tt.testQuote.buyPrice())
+				assert.Equal(t, tt.expectSellPrice, tt.testQuote.sellPrice())
+				assert.Equal(t, tt.expectLastPrice, tt.testQuote.lastPrice())
+			},
+		)
+	}
+}
@@ -0,0 +1,393 @@
+package paper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/client/constants"
+	"github.com/jerryryle/etrade-cli/pkg/etradelib/jsonmap"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// state is everything that the paper client keeps in its state file.
+type state struct {
+	NextId   int64      `json:"nextId"`
+	Accounts []*account `json:"accounts"`
+}
+
+type account struct {
+	AccountId    string         `json:"accountId"`
+	AccountIdKey string         `json:"accountIdKey"`
+	AccountName  string         `json:"accountName"`
+	Cash         float64        `json:"cash"`
+	Positions    []*position    `json:"positions"`
+	Orders       []*order       `json:"orders"`
+	Previews     []*preview     `json:"previews"`
+	Transactions []*transaction `json:"transactions"`
+}
+
+type product struct {
+	Symbol       string  `json:"symbol"`
+	SecurityType string  `json:"securityType"`
+	CallPut      string  `json:"callPut,omitempty"`
+	ExpiryYear   int64   `json:"expiryYear,omitempty"`
+	ExpiryMonth  int64   `json:"expiryMonth,omitempty"`
+	ExpiryDay    int64   `json:"expiryDay,omitempty"`
+	StrikePrice  float64 `json:"strikePrice,omitempty"`
+}
+
+type position struct {
+	PositionId int64   `json:"positionId"`
+	Product    product `json:"product"`
+	Lots       []*lot  `json:"lots"`
+}
+
+// lot is a quantity of a position acquired at one time and price. Short lots
+// have negative quantities.
+type lot struct {
+	LotId    int64   `json:"lotId"`
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Acquired int64   `json:"acquired"`
+}
+
+type orderLeg struct {
+	Product               product `json:"product"`
+	OrderAction           string  `json:"orderAction"`
+	Quantity              int64   `json:"quantity"`
+	FilledQuantity        int64   `json:"filledQuantity"`
+	AverageExecutionPrice float64 `json:"averageExecutionPrice"`
+}
+
+type order struct {
+	OrderId       int64       `json:"orderId"`
+	OrderType     string      `json:"orderType"`
+	ClientOrderId string      `json:"clientOrderId"`
+	Status        string      `json:"status"`
+	PlacedTime    int64       `json:"placedTime"`
+	ExecutedTime  int64       `json:"executedTime,omitempty"`
+	PriceType     string      `json:"priceType"`
+	OrderTerm     string      `json:"orderTerm"`
+	MarketSession string      `json:"marketSession"`
+	LimitPrice    float64     `json:"limitPrice,omitempty"`
+	StopPrice     float64     `json:"stopPrice,omitempty"`
+	AllOrNone     bool        `json:"allOrNone"`
+	Legs          []*orderLeg `json:"legs"`
+	Reason        string      `json:"reason,omitempty"`
+}
+
+// preview is an order that has been previewed but not yet placed. OrderId is
+// the ID of the order being changed, or zero for new orders.
+type preview struct {
+	PreviewId int64  `json:"previewId"`
+	OrderId   int64  `json:"orderId,omitempty"`
+	Order     *order `json:"order"`
+}
+
+type transaction struct {
+	TransactionId   int64    `json:"transactionId"`
+	TransactionDate int64    `json:"transactionDate"`
+	TransactionType string   `json:"transactionType"`
+	Description     string   `json:"description"`
+	Amount          float64  `json:"amount"`
+	Product         *product `json:"product,omitempty"`
+	Quantity        float64  `json:"quantity,omitempty"`
+	Price           float64  `json:"price,omitempty"`
+	OrderId         int64    `json:"orderId,omitempty"`
+}
+
+// maxPreviews is the number of unplaced previews kept per account. Older
+// previews are discarded and can no longer be placed.
+const maxPreviews = 100
+
+const (
+	paperAccountId    = "PAPER"
+	paperAccountIdKey = "paper"
+	paperAccountName  = "Paper Trading"
+	optionMultiplier  = 100
+	equityMultiplier  = 1
+)
+
+func newState(startingCash float64, now time.Time) *state {
+	s := state{NextId: 1}
+	a := account{
+		AccountId:    paperAccountId,
+		AccountIdKey: paperAccountIdKey,
+		AccountName:  paperAccountName,
+		Cash:         startingCash,
+		Positions:    []*position{},
+		Orders:       []*order{},
+		Previews:     []*preview{},
+		Transactions: []*transaction{},
+	}
+	a.Transactions = append(
+		a.Transactions, &transaction{
+			TransactionId:   s.newId(),
+			TransactionDate: now.UnixMilli(),
+			TransactionType: "Transfer",
+			Description:     "Paper trading starting cash",
+			Amount:          startingCash,
+		},
+	)
+	s.Accounts = []*account{&a}
+	return &s
+}
+
+// loadState loads the state file or, if it doesn't exist, creates a new
+// state with a single account holding the starting cash. It returns whether
+// the state was newly created.
+func loadState(filename string, startingCash float64, now time.Time) (*state, bool, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return newState(startingCash, now), true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var s state
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, false, fmt.Errorf("paper trading state file %s is invalid (%w)", filename, err)
+	}
+	return &s, false, nil
+}
+
+// saveState writes the state to a temporary file and then renames it over
+// the state file so that an interrupted write can't corrupt it.
+func saveState(filename string, s *state) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempFilename := filename + ".tmp"
+	if err = os.WriteFile(tempFilename, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tempFilename, filename)
+}
+
+func (s *state) newId() int64 {
+	id := s.NextId
+	s.NextId++
+	return id
+}
+
+func (s *state) getAccount(accountIdKey string) (*account, error) {
+	for _, a := range s.Accounts {
+		if a.AccountIdKey == accountIdKey {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("account %s not found", accountIdKey)
+}
+
+func newProduct(productMap jsonmap.JsonMap) (product, error) {
+	var p product
+	var err error
+	if p.Symbol, err = productMap.GetString("symbol"); err != nil {
+		return product{}, err
+	}
+	p.Symbol = strings.ToUpper(p.Symbol)
+	if p.SecurityType, err = productMap.GetString("securityType"); err != nil {
+		return product{}, err
+	}
+	switch p.SecurityType {
+	case constants.OrderSecurityTypeEquity.String():
+		return p, nil
+	case constants.OrderSecurityTypeOption.String():
+		if p.CallPut, err = productMap.GetString("callPut"); err != nil {
+			return product{}, err
+		}
+		if p.ExpiryYear, err = productMap.GetInt("expiryYear"); err != nil {
+			return product{}, err
+		}
+		if p.ExpiryMonth, err = productMap.GetInt("expiryMonth"); err != nil {
+			return product{}, err
+		}
+		if p.ExpiryDay, err = productMap.GetInt("expiryDay"); err != nil {
+			return product{}, err
+		}
+		if p.StrikePrice, err = productMap.GetFloat("strikePrice"); err != nil {
+			return product{}, err
+		}
+		return p, nil
+	default:
+		return product{}, fmt.Errorf("security type %s is not supported in paper trading", p.SecurityType)
+	}
+}
+
+func (p product) isOption() bool {
+	return p.SecurityType == constants.OrderSecurityTypeOption.String()
+}
+
+func (p product) multiplier() float64 {
+	if p.isOption() {
+		return optionMultiplier
+	}
+	return equityMultiplier
+}
+
+func (p product) contract() etradelib.ETradeOptionContract {
+	contract := etradelib.ETradeOptionContract{
+		Underlying:  p.Symbol,
+		CallPut:     constants.OptionTypeCall,
+		ExpiryDate:  time.Date(int(p.ExpiryYear), time.Month(p.ExpiryMonth), int(p.ExpiryDay), 0, 0, 0, 0, time.UTC),
+		StrikePrice: p.StrikePrice,
+	}
+	if p.CallPut == constants.OptionTypePut.String() {
+		contract.CallPut = constants.OptionTypePut
+	}
+	return contract
+}
+
+// quoteSymbol returns the symbol used to look up the product's quote.
+func (p product) quoteSymbol() string {
+	if p.isOption() {
+		return p.contract().GetOsiKey()
+	}
+	return p.Symbol
+}
+
+func (p product) description() string {
+	if p.isOption() {
+		contract := p.contract()
+		callPut := p.CallPut[:1] + strings.ToLower(p.CallPut[1:])
+		return fmt.Sprintf(
+			"%s %s $%g %s", p.Symbol, contract.ExpiryDate.Format("Jan 02 '06"), p.StrikePrice, callPut,
+		)
+	}
+	return p.Symbol
+}
+
+func (p product) asJsonMap() jsonmap.JsonMap {
+	productMap := jsonmap.JsonMap{
+		"symbol":       p.Symbol,
+		"securityType": p.SecurityType,
+	}
+	if p.isOption() {
+		productMap["callPut"] = p.CallPut
+		productMap["expiryYear"] = p.ExpiryYear
+		productMap["expiryMonth"] = p.ExpiryMonth
+		productMap["expiryDay"] = p.ExpiryDay
+		productMap["strikePrice"] = p.StrikePrice
+	}
+	return productMap
+}
+
+func (p *position) quantity() float64 {
+	quantity := 0.0
+	for _, l := range p.Lots {
+		quantity += l.Quantity
+	}
+	return quantity
+}
+
+func (p *position) totalCost() float64 {
+	cost := 0.0
+	for _, l := range p.Lots {
+		cost += l.Quantity * l.Price * p.Product.multiplier()
+	}
+	return cost
+}
+
+func (p *position) dateAcquired() int64 {
+	if len(p.Lots) == 0 {
+		return 0
+	}
+	return p.Lots[0].Acquired
+}
+
+func (a *account) findPosition(p product) *position {
+	for _, pos := range a.Positions {
+		if pos.Product == p {
+			return pos
+		}
+	}
+	return nil
+}
+
+func (a *account) heldQuantity(p product) float64 {
+	if pos := a.findPosition(p); pos != nil {
+		return pos.quantity()
+	}
+	return 0
+}
+
+// addToPosition adds a signed quantity to a position. Quantities that oppose
+// the position close its lots first-in, first-out; any remainder opens a new
+// lot.
+func (a *account) addToPosition(s *state, p product, quantity float64, price float64, now time.Time) {
+	pos := a.findPosition(p)
+	if pos == nil {
+		pos = &position{PositionId: s.newId(), Product: p, Lots: []*lot{}}
+		a.Positions = append(a.Positions, pos)
+	}
+	for len(pos.Lots) > 0 && quantity != 0 && (pos.Lots[0].Quantity > 0) != (quantity > 0) {
+		first := pos.Lots[0]
+		if -quantity == first.Quantity || (quantity > 0) == (first.Quantity+quantity > 0) {
+			// The quantity closes this entire lot.
+			quantity += first.Quantity
+			pos.Lots = pos.Lots[1:]
+		} else {
+			first.Quantity += quantity
+			quantity = 0
+		}
+	}
+	if quantity != 0 {
+		pos.Lots = append(pos.Lots, &lot{LotId: s.newId(), Quantity: quantity, Price: price, Acquired: now.UnixMilli()})
+	}
+	if len(pos.Lots) == 0 {
+		a.removePosition(pos)
+	}
+}
+
+func (a *account) removePosition(pos *position) {
+	for i, p := range a.Positions {
+		if p == pos {
+			a.Positions = append(a.Positions[:i], a.Positions[i+1:]...)
+			return
+		}
+	}
+}
+
+func (a *account) findOrder(orderId int64) *order {
+	for _, o := range a.Orders {
+		if o.OrderId == orderId {
+			return o
+		}
+	}
+	return nil
+}
+
+func (a *account) addPreview(p *preview) {
+	a.Previews = append(a.Previews, p)
+	if len(a.Previews) > maxPreviews {
+		a.Previews = a.Previews[len(a.Previews)-maxPreviews:]
+	}
+}
+
+func (a *account) findPreview(previewId int64, orderId int64) (*preview, error) {
+	for _, p := range a.Previews {
+		if p.PreviewId == previewId && p.OrderId == orderId {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("preview %d not found", previewId)
+}
+
+// removePreview removes a preview once it's placed, since it can only be
+// placed once.
+func (a *account) removePreview(p *preview) {
+	for i := range a.Previews {
+		if a.Previews[i] == p {
+			a.Previews = append(a.Previews[:i], a.Previews[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package paper
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAccount_AddToPosition(t *testing.T) {
+	testNow := time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+	testProduct := product{Symbol: "ABC", SecurityType: "EQ"}
+
+	tests := []struct {
+		name       string
+		testLots   []*lot
+		testAdd    float64
+		expectLots []*lot
+	}{
+		{
+			name:     "Opens New Position",
+			testLots: nil,
+			testAdd:  10,
+			expectLots: []*lot{
+				{LotId: 2, Quantity: 10, Price: 5, Acquired: testNow.UnixMilli()},
+			},
+		},
+		{
+			name: "Adds Lot To Position",
+			testLots: []*lot{
+				{LotId: 100, Quantity: 10, Price: 4},
+			},
+			testAdd: 5,
+			expectLots: []*lot{
+				{LotId: 100, Quantity: 10, Price: 4},
+				{LotId: 1, Quantity: 5, Price: 5, Acquired: testNow.UnixMilli()},
+			},
+		},
+		{
+			name: "Closes Lots First In First Out",
+			testLots: []*lot{
+				{LotId: 100, Quantity: 10, Price: 4},
+				{LotId: 101, Quantity: 10, Price: 6},
+			},
+			testAdd: -15,
+			expectLots: []*lot{
+				{LotId: 101, Quantity: 5, Price: 6},
+			},
+		},
+		{
+			name: "Reverses Position",
+			testLots: []*lot{
+				{LotId: 100, Quantity: 10, Price: 4},
+			},
+			testAdd: -15,
+			expectLots: []*lot{
+				{LotId: 1, Quantity: -5, Price: 5, Acquired: testNow.UnixMilli()},
+			},
+		},
+		{
+			name: "Removes Closed Position",
+			testLots: []*lot{
+				{LotId: 100, Quantity: 10, Price: 4},
+			},
+			testAdd:    -10,
+			expectLots: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				s := state{NextId: 1}
+				a := account{}
+				if tt.testLots != nil {
+					a.Positions = []*position{{PositionId: 99, Product: testProduct, Lots: tt.testLots}}
+				}
+				// Call the Method Under Test
+				a.addToPosition(&s, testProduct, tt.testAdd, 5, testNow)
+				pos := a.findPosition(testProduct)
+				if tt.expectLots == nil {
+					assert.Nil(t, pos)
+				} else {
+					require.NotNil(t, pos)
+					assert.Equal(t, tt.expectLots, pos.Lots)
+				}
+			},
+		)
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	testNow := time.Date(2024, 1, 17, 15, 0, 0, 0, time.UTC)
+	filename := filepath.Join(t.TempDir(), "state", "paper.json")
+
+	// A missing state file creates a new state with the starting cash
+	s, created, err := loadState(filename, 5000, testNow)
+	require.Nil(t, err)
+	assert.True(t, created)
+	require.Len(t, s.Accounts, 1)
+	assert.Equal(t, 5000.0, s.Accounts[0].Cash)
+	assert.Len(t, s.Accounts[0].Transactions, 1)
+
+	// A saved state file loads unchanged
+	s.Accounts[0].Cash = 1234
+	require.Nil(t, saveState(filename, s))
+	loaded, created, err := loadState(filename, 5000, testNow)
+	require.Nil(t, err)
+	assert.False(t, created)
+	assert.Equal(t, s, loaded)
+}